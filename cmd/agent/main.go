@@ -12,12 +12,13 @@ import (
 )
 
 var (
-	mcpURL       string
-	prompt       string
-	modelBaseURL string
-	modelKey     string
-	modelName    string
-	systemPrompt string
+	mcpURL        string
+	prompt        string
+	modelBaseURL  string
+	modelKey      string
+	modelName     string
+	systemPrompt  string
+	transcriptOut string
 )
 
 var rootCmd = &cobra.Command{
@@ -27,7 +28,8 @@ var rootCmd = &cobra.Command{
 server and uses OpenAI compliant API to run an intelligent agent. The agent can interact with
 tools provided by the MCP server to accomplish tasks.`,
 	Example: `  agent-cli --mcp-url http://localhost:3000 --prompt "What files are in the current directory?"
-  agent-cli --mcp-url http://localhost:3000 --prompt "Read the README file" --model-name gpt-4o`,
+  agent-cli --mcp-url http://localhost:3000 --prompt "Read the README file" --model-name gpt-4o
+  agent-cli --mcp-url http://localhost:3000 --prompt "List the pods" --transcript transcript.json && mcpchecker view transcript.json`,
 	RunE: runAgent,
 }
 
@@ -41,6 +43,7 @@ func init() {
 	rootCmd.Flags().StringVar(&modelKey, "model-key", getEnvOrDefault("MODEL_KEY", ""), "Model API key")
 	rootCmd.Flags().StringVar(&modelName, "model-name", getEnvOrDefault("MODEL_NAME", ""), "Model name to use")
 	rootCmd.Flags().StringVar(&systemPrompt, "system", getEnvOrDefault("SYSTEM_PROMPT", ""), "System prompt for the agent")
+	rootCmd.Flags().StringVar(&transcriptOut, "transcript", "", "Write the messages and tool calls of the run to this file, in the results format read by 'mcpchecker view'")
 
 	// Mark required flags
 	rootCmd.MarkFlagRequired("mcp-url")
@@ -81,15 +84,25 @@ func runAgent(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to add MCP server: %w", err)
 	}
 
+	// Print the steps of the run as they happen
+	t := newTranscript(os.Stdout)
+	agentInstance.OnEvent(t.handle)
+
 	// Run the agent with the provided prompt
 	fmt.Printf("Running agent with prompt: %s\n\n", prompt)
 
 	result, err := agentInstance.Run(ctx, prompt)
+	if transcriptOut != "" {
+		if saveErr := t.save(transcriptOut, err); saveErr != nil {
+			log.Printf("Warning: Failed to write transcript: %v", saveErr)
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("agent execution failed: %w", err)
 	}
 
 	// Output the result
+	fmt.Println()
 	fmt.Println("Agent Response:")
 	fmt.Println(strings.Repeat("=", 50))
 	fmt.Println(result)
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/openaiagent"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxResultLength is how many characters of a tool result are printed
+const maxResultLength = 200
+
+// transcript prints the steps of a run as they happen and records them, so
+// they can be saved as a results file for `mcpchecker view`. The steps are
+// recorded as JSON events in the format of `codex exec --json`, which view
+// condenses into a timeline.
+type transcript struct {
+	w       io.Writer
+	started time.Time
+	events  []string
+	items   int
+	history mcpproxy.CallHistory
+	// calls in progress by their call ID
+	calls map[string]pendingCall
+}
+
+// pendingCall is a tool call that started but did not complete yet
+type pendingCall struct {
+	item    string
+	started time.Time
+}
+
+func newTranscript(w io.Writer) *transcript {
+	t := &transcript{w: w, started: time.Now(), calls: map[string]pendingCall{}}
+	t.record(map[string]any{"type": "thread.started"})
+	t.record(map[string]any{"type": "turn.started"})
+	return t
+}
+
+// handle is the openaiagent.EventHandler of the transcript
+func (t *transcript) handle(e openaiagent.Event) {
+	switch e.Type {
+	case openaiagent.EventMessage:
+		if !e.Final {
+			fmt.Fprintf(t.w, "Assistant: %s\n", e.Text)
+		}
+		t.record(map[string]any{"type": "item.completed", "item": map[string]any{
+			"id":   t.nextID(),
+			"type": "agent_message",
+			"text": e.Text,
+		}})
+
+	case openaiagent.EventToolCallStarted:
+		args, _ := json.Marshal(e.Arguments)
+		fmt.Fprintf(t.w, "Calling tool %s::%s %s\n", e.Server, e.Tool, args)
+		call := pendingCall{item: t.nextID(), started: time.Now()}
+		t.calls[e.CallID] = call
+		t.record(map[string]any{"type": "item.started", "item": toolItem(call.item, e, "in_progress")})
+
+	case openaiagent.EventToolCallCompleted:
+		call := t.calls[e.CallID]
+		delete(t.calls, e.CallID)
+
+		status := "completed"
+		switch {
+		case e.Error != nil:
+			status = "failed"
+			fmt.Fprintf(t.w, "Tool %s::%s failed: %v\n", e.Server, e.Tool, e.Error)
+		case e.Result != nil && e.Result.IsError:
+			status = "failed"
+			fmt.Fprintf(t.w, "Tool %s::%s returned an error: %s\n", e.Server, e.Tool, resultText(e.Result))
+		default:
+			fmt.Fprintf(t.w, "Tool %s::%s returned: %s\n", e.Server, e.Tool, resultText(e.Result))
+		}
+		t.record(map[string]any{"type": "item.completed", "item": toolItem(call.item, e, status)})
+		t.history.ToolCalls = append(t.history.ToolCalls, toolCall(e, call.started))
+	}
+}
+
+// save writes the transcript of a run that ended with err as a results file
+// holding a single task
+func (t *transcript) save(path string, err error) error {
+	t.record(map[string]any{"type": "turn.completed"})
+
+	elapsed := util.Duration(time.Since(t.started))
+	result := &eval.EvalResult{
+		TaskName:            "agent-cli",
+		TaskPassed:          err == nil,
+		TaskOutput:          strings.Join(t.events, "\n"),
+		AgentExecutionError: err != nil,
+		AllAssertionsPassed: true,
+		CallHistory:         &t.history,
+		Timing:              &eval.TaskTiming{Total: elapsed, Agent: elapsed},
+	}
+	if err != nil {
+		result.TaskError = err.Error()
+	}
+
+	return results.Save([]*eval.EvalResult{result}, path, results.LayoutFile)
+}
+
+func (t *transcript) record(event map[string]any) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	t.events = append(t.events, string(data))
+}
+
+func (t *transcript) nextID() string {
+	id := fmt.Sprintf("item_%d", t.items)
+	t.items++
+	return id
+}
+
+func toolItem(id string, e openaiagent.Event, status string) map[string]any {
+	return map[string]any{
+		"id":     id,
+		"type":   "mcp_tool_call",
+		"server": e.Server,
+		"tool":   e.Tool,
+		"status": status,
+	}
+}
+
+// toolCall converts a completed tool call to the record of it in the call history
+func toolCall(e openaiagent.Event, started time.Time) *mcpproxy.ToolCall {
+	args, _ := json.Marshal(e.Arguments)
+	call := &mcpproxy.ToolCall{
+		CallRecord: mcpproxy.CallRecord{
+			ServerName: e.Server,
+			Timestamp:  started,
+			Success:    e.Error == nil,
+		},
+		ToolName: e.Tool,
+		Request: &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: e.Tool, Arguments: args},
+		},
+		Result: e.Result,
+	}
+	if e.Error != nil {
+		call.Error = e.Error.Error()
+	}
+	return call
+}
+
+// resultText returns the text content of a tool result on a single line,
+// shortened to maxResultLength characters
+func resultText(result *mcp.CallToolResult) string {
+	if result == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+
+	text := strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+	if runes := []rune(text); len(runes) > maxResultLength {
+		text = string(runes[:maxResultLength]) + "…"
+	}
+	return text
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -70,15 +71,13 @@ func Run(ctx context.Context, args []string) error {
 		return fmt.Errorf("%s", behavior.Error)
 	}
 
-	// Execute tool calls if any
-	if len(behavior.ToolCalls) > 0 && mcpConfig != nil {
-		if err := executeToolCalls(ctx, mcpConfig, behavior.ToolCalls); err != nil {
-			return fmt.Errorf("failed to execute tool calls: %w", err)
-		}
+	// Take the steps, then respond
+	out := newTranscript(os.Stdout, behavior.Stream)
+	out.start()
+	if err := runSteps(ctx, mcpConfig, behavior.Steps, out); err != nil {
+		return fmt.Errorf("failed to run steps: %w", err)
 	}
-
-	// Output response
-	fmt.Print(behavior.Response)
+	out.finish(behavior.Response)
 	return nil
 }
 
@@ -282,56 +281,90 @@ func verifyAllowedTools(ctx context.Context, mcpConfig *MCPConfig, allowedTools
 	return nil
 }
 
-func executeToolCalls(ctx context.Context, mcpConfig *MCPConfig, toolCalls []ToolCallSpec) error {
-	for _, tc := range toolCalls {
-		// Check for context cancellation before each tool call
+// runSteps takes the steps of a behavior in order and reports them to the
+// transcript. Tool calls are skipped when no MCP config was given.
+func runSteps(ctx context.Context, mcpConfig *MCPConfig, steps []Step, out *transcript) error {
+	for _, step := range steps {
+		if step.Delay > 0 {
+			select {
+			case <-time.After(time.Duration(step.Delay)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		// Check for context cancellation before each step
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
 		}
 
-		// Find the server URL
-		serverURL := ""
-		if tc.Server != "" {
-			if serverCfg, ok := mcpConfig.MCPServers[tc.Server]; ok {
-				serverURL = serverCfg.URL
-			}
-		} else {
-			// Deterministically select the first server (alphabetically by name)
-			// when no specific server is specified
-			var serverNames []string
-			for name := range mcpConfig.MCPServers {
-				serverNames = append(serverNames, name)
+		switch {
+		case step.ToolCall != nil:
+			if mcpConfig == nil {
+				continue
 			}
-			sort.Strings(serverNames)
-			if len(serverNames) > 0 {
-				serverURL = mcpConfig.MCPServers[serverNames[0]].URL
+			if err := executeToolCall(ctx, mcpConfig, *step.ToolCall, out); err != nil {
+				return err
 			}
+		case step.Reasoning != "":
+			out.reasoning(step.Reasoning)
+		case step.Command != nil:
+			out.command(*step.Command)
+		case step.Message != "":
+			out.message(step.Message)
 		}
+	}
 
-		if serverURL == "" {
-			return fmt.Errorf("no MCP server found for tool call %q", tc.Name)
-		}
+	return nil
+}
 
-		// Connect to the MCP server and call the tool
-		result, err := callTool(ctx, serverURL, tc.Name, tc.Arguments)
-		if err != nil {
-			if tc.ExpectError {
-				fmt.Fprintf(os.Stderr, "Tool %q returned expected error: %v\n", tc.Name, err)
-				continue
-			}
-			return fmt.Errorf("failed to call tool %q: %w", tc.Name, err)
+func executeToolCall(ctx context.Context, mcpConfig *MCPConfig, tc ToolCallSpec, out *transcript) error {
+	// Find the server
+	serverName := tc.Server
+	if serverName == "" {
+		// Deterministically select the first server (alphabetically by name)
+		// when no specific server is specified
+		var serverNames []string
+		for name := range mcpConfig.MCPServers {
+			serverNames = append(serverNames, name)
 		}
+		sort.Strings(serverNames)
+		if len(serverNames) > 0 {
+			serverName = serverNames[0]
+		}
+	}
 
+	serverURL := ""
+	if serverCfg, ok := mcpConfig.MCPServers[serverName]; ok {
+		serverURL = serverCfg.URL
+	}
+
+	label := tc.label()
+	if serverURL == "" {
+		return fmt.Errorf("no MCP server found for %s", label)
+	}
+
+	// Connect to the MCP server and make the request
+	id := out.toolCallStarted(serverName, tc)
+	result, err := execute(ctx, serverURL, tc)
+	out.toolCallCompleted(id, serverName, tc, err == nil)
+	if err != nil {
 		if tc.ExpectError {
-			return fmt.Errorf("tool %q was expected to error but succeeded", tc.Name)
+			fmt.Fprintf(os.Stderr, "%s returned expected error: %v\n", label, err)
+			return nil
 		}
+		return fmt.Errorf("failed to %s: %w", label, err)
+	}
 
-		// Log the result for debugging
-		if result != nil && len(result.Content) > 0 {
-			fmt.Fprintf(os.Stderr, "Tool %q result: %v\n", tc.Name, result.Content)
-		}
+	if tc.ExpectError {
+		return fmt.Errorf("%s was expected to error but succeeded", label)
+	}
+
+	// Log the result for debugging
+	if result != "" {
+		fmt.Fprintf(os.Stderr, "%s result: %s\n", label, result)
 	}
 
 	return nil
@@ -355,20 +388,76 @@ func connectToMCP(ctx context.Context, serverURL string) (*mcp.ClientSession, er
 	return session, nil
 }
 
-func callTool(ctx context.Context, serverURL, toolName string, args map[string]any) (*mcp.CallToolResult, error) {
+// label describes the request of a spec for logs and errors
+func (tc ToolCallSpec) label() string {
+	switch {
+	case tc.ResourceURI != "":
+		return fmt.Sprintf("read resource %q", tc.ResourceURI)
+	case tc.Prompt != "":
+		return fmt.Sprintf("get prompt %q", tc.Prompt)
+	default:
+		return fmt.Sprintf("call tool %q", tc.Name)
+	}
+}
+
+// execute makes the request of a spec and returns its result formatted for logging
+func execute(ctx context.Context, serverURL string, tc ToolCallSpec) (string, error) {
 	session, err := connectToMCP(ctx, serverURL)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer session.Close()
 
-	result, err := session.CallTool(ctx, &mcp.CallToolParams{
-		Name:      toolName,
-		Arguments: args,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("tool call failed: %w", err)
+	switch {
+	case tc.ResourceURI != "":
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: tc.ResourceURI})
+		if err != nil {
+			return "", fmt.Errorf("resource read failed: %w", err)
+		}
+		texts := make([]string, 0, len(result.Contents))
+		for _, c := range result.Contents {
+			texts = append(texts, c.Text)
+		}
+		return strings.Join(texts, "\n"), nil
+
+	case tc.Prompt != "":
+		args := make(map[string]string, len(tc.Arguments))
+		for k, v := range tc.Arguments {
+			args[k] = fmt.Sprint(v)
+		}
+		result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+			Name:      tc.Prompt,
+			Arguments: args,
+		})
+		if err != nil {
+			return "", fmt.Errorf("prompt get failed: %w", err)
+		}
+		texts := make([]string, 0, len(result.Messages))
+		for _, m := range result.Messages {
+			texts = append(texts, contentText(m.Content))
+		}
+		return strings.Join(texts, "\n"), nil
+
+	default:
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      tc.Name,
+			Arguments: tc.Arguments,
+		})
+		if err != nil {
+			return "", fmt.Errorf("tool call failed: %w", err)
+		}
+		texts := make([]string, 0, len(result.Content))
+		for _, c := range result.Content {
+			texts = append(texts, contentText(c))
+		}
+		return strings.Join(texts, "\n"), nil
 	}
+}
 
-	return result, nil
+// contentText returns the text of text content, or a description of other content
+func contentText(c mcp.Content) string {
+	if text, ok := c.(*mcp.TextContent); ok {
+		return text.Text
+	}
+	return fmt.Sprintf("%T", c)
 }
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 // Config defines the mock agent's behavior.
@@ -38,25 +41,63 @@ type Behavior struct {
 	PromptMatches  string `json:"promptMatches,omitempty"` // Regex pattern
 	MatchAny       bool   `json:"matchAny,omitempty"`      // Match any prompt
 
-	// ToolCalls to make before responding
-	ToolCalls []ToolCallSpec `json:"toolCalls,omitempty"`
+	// Steps to take before responding, in order
+	Steps []Step `json:"steps,omitempty"`
 
-	// Response to output after tool calls complete
+	// Response to output after the steps complete
 	Response string `json:"response"`
 
+	// Stream makes the agent print a JSON event transcript, one event per
+	// line as each step completes, instead of only the response
+	Stream bool `json:"stream,omitempty"`
+
 	// Error causes the agent to exit with an error instead of responding
 	Error string `json:"error,omitempty"`
 }
 
-// ToolCallSpec defines a tool call to make to an MCP server
+// Step is one step of a behavior. Exactly one of ToolCall, Reasoning,
+// Command, or Message should be set. Without Stream, only tool calls and
+// messages produce output: messages are printed before the response.
+type Step struct {
+	// Delay is waited before the step
+	Delay util.Duration `json:"delay,omitempty"`
+
+	// ToolCall makes a tool call, resource read, or prompt get
+	ToolCall *ToolCallSpec `json:"toolCall,omitempty"`
+
+	// Reasoning emits a reasoning event
+	Reasoning string `json:"reasoning,omitempty"`
+
+	// Command emits a command execution event. The command is not run.
+	Command *CommandSpec `json:"command,omitempty"`
+
+	// Message emits an assistant message
+	Message string `json:"message,omitempty"`
+}
+
+// CommandSpec defines a command execution reported by the agent
+type CommandSpec struct {
+	Command  string `json:"command"`
+	Output   string `json:"output,omitempty"`
+	ExitCode int    `json:"exitCode,omitempty"`
+}
+
+// ToolCallSpec defines a tool call to make to an MCP server. When ResourceURI
+// or Prompt is set, it reads that resource or gets that prompt instead.
 type ToolCallSpec struct {
 	// Server is the MCP server name (optional, uses first server if not set)
 	Server string `json:"server,omitempty"`
 
 	// Name is the tool name to call
-	Name string `json:"name"`
+	Name string `json:"name,omitempty"`
+
+	// ResourceURI is the URI of the resource to read
+	ResourceURI string `json:"resourceUri,omitempty"`
 
-	// Arguments to pass to the tool
+	// Prompt is the name of the prompt to get
+	Prompt string `json:"prompt,omitempty"`
+
+	// Arguments to pass to the tool or prompt
 	Arguments map[string]any `json:"arguments,omitempty"`
 
 	// ExpectError if true, the tool call is expected to return an error
@@ -136,7 +177,7 @@ func (c *Config) AddBehavior(b Behavior) *Config {
 // NewBehavior creates a new behavior builder
 func NewBehavior() *Behavior {
 	return &Behavior{
-		ToolCalls: make([]ToolCallSpec, 0),
+		Steps: make([]Step, 0),
 	}
 }
 
@@ -164,23 +205,93 @@ func (b *Behavior) OnAnyPrompt() *Behavior {
 	return b
 }
 
+// AddToolCall adds a step that makes a tool call, resource read, or prompt get
+func (b *Behavior) AddToolCall(spec ToolCallSpec) *Behavior {
+	b.Steps = append(b.Steps, Step{ToolCall: &spec})
+	return b
+}
+
+// Think adds a reasoning step to the behavior
+func (b *Behavior) Think(text string) *Behavior {
+	b.Steps = append(b.Steps, Step{Reasoning: text})
+	return b
+}
+
+// RunCommand adds a step reporting a command execution with its output and exit code
+func (b *Behavior) RunCommand(command, output string, exitCode int) *Behavior {
+	b.Steps = append(b.Steps, Step{Command: &CommandSpec{
+		Command:  command,
+		Output:   output,
+		ExitCode: exitCode,
+	}})
+	return b
+}
+
+// Say adds an assistant message step to the behavior
+func (b *Behavior) Say(text string) *Behavior {
+	b.Steps = append(b.Steps, Step{Message: text})
+	return b
+}
+
+// Wait delays the next step, or the response if no step follows
+func (b *Behavior) Wait(delay time.Duration) *Behavior {
+	b.Steps = append(b.Steps, Step{Delay: util.Duration(delay)})
+	return b
+}
+
+// StreamEvents makes the behavior print a JSON event transcript
+func (b *Behavior) StreamEvents() *Behavior {
+	b.Stream = true
+	return b
+}
+
 // CallTool adds a tool call to the behavior
 func (b *Behavior) CallTool(name string, args map[string]any) *Behavior {
-	b.ToolCalls = append(b.ToolCalls, ToolCallSpec{
+	return b.AddToolCall(ToolCallSpec{
 		Name:      name,
 		Arguments: args,
 	})
-	return b
 }
 
 // CallToolOnServer adds a tool call to a specific server
 func (b *Behavior) CallToolOnServer(server, name string, args map[string]any) *Behavior {
-	b.ToolCalls = append(b.ToolCalls, ToolCallSpec{
+	return b.AddToolCall(ToolCallSpec{
 		Server:    server,
 		Name:      name,
 		Arguments: args,
 	})
-	return b
+}
+
+// ReadResource adds a resource read to the behavior
+func (b *Behavior) ReadResource(uri string) *Behavior {
+	return b.AddToolCall(ToolCallSpec{
+		ResourceURI: uri,
+	})
+}
+
+// ReadResourceOnServer adds a resource read from a specific server
+func (b *Behavior) ReadResourceOnServer(server, uri string) *Behavior {
+	return b.AddToolCall(ToolCallSpec{
+		Server:      server,
+		ResourceURI: uri,
+	})
+}
+
+// GetPrompt adds a prompt get to the behavior
+func (b *Behavior) GetPrompt(name string, args map[string]any) *Behavior {
+	return b.AddToolCall(ToolCallSpec{
+		Prompt:    name,
+		Arguments: args,
+	})
+}
+
+// GetPromptOnServer adds a prompt get from a specific server
+func (b *Behavior) GetPromptOnServer(server, name string, args map[string]any) *Behavior {
+	return b.AddToolCall(ToolCallSpec{
+		Server:    server,
+		Prompt:    name,
+		Arguments: args,
+	})
 }
 
 // ThenRespond sets the response
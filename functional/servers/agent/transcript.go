@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// transcript writes the output of the agent. When streaming, every step is
+// written as a JSON event line in the format of `codex exec --json`, as soon
+// as it happens. Otherwise only messages and the response are written.
+type transcript struct {
+	w      io.Writer
+	stream bool
+	items  int
+}
+
+func newTranscript(w io.Writer, stream bool) *transcript {
+	return &transcript{w: w, stream: stream}
+}
+
+// event is a line of the streamed transcript
+type event struct {
+	Type     string `json:"type"`
+	ThreadID string `json:"thread_id,omitempty"`
+	Item     *item  `json:"item,omitempty"`
+	Usage    *usage `json:"usage,omitempty"`
+}
+
+// item is the payload of an item event
+type item struct {
+	ID               string `json:"id"`
+	Type             string `json:"type"`
+	Text             string `json:"text,omitempty"`
+	Command          string `json:"command,omitempty"`
+	AggregatedOutput string `json:"aggregated_output,omitempty"`
+	ExitCode         *int   `json:"exit_code,omitempty"`
+	Server           string `json:"server,omitempty"`
+	Tool             string `json:"tool,omitempty"`
+	Status           string `json:"status,omitempty"`
+}
+
+// usage is the token usage reported when the turn completes. The mock agent
+// uses no tokens.
+type usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+func (t *transcript) start() {
+	t.emit(event{Type: "thread.started", ThreadID: "mock-thread"})
+	t.emit(event{Type: "turn.started"})
+}
+
+func (t *transcript) reasoning(text string) {
+	t.emit(event{Type: "item.completed", Item: &item{ID: t.nextID(), Type: "reasoning", Text: text}})
+}
+
+func (t *transcript) command(c CommandSpec) {
+	id := t.nextID()
+	t.emit(event{Type: "item.started", Item: &item{ID: id, Type: "command_execution", Command: c.Command, Status: "in_progress"}})
+
+	status := "completed"
+	if c.ExitCode != 0 {
+		status = "failed"
+	}
+	exitCode := c.ExitCode
+	t.emit(event{Type: "item.completed", Item: &item{
+		ID:               id,
+		Type:             "command_execution",
+		Command:          c.Command,
+		AggregatedOutput: c.Output,
+		ExitCode:         &exitCode,
+		Status:           status,
+	}})
+}
+
+func (t *transcript) message(text string) {
+	if !t.stream {
+		fmt.Fprintln(t.w, text)
+		return
+	}
+	t.emit(event{Type: "item.completed", Item: &item{ID: t.nextID(), Type: "agent_message", Text: text}})
+}
+
+// toolCallStarted reports the start of a tool call and returns its item ID.
+// Resource reads and prompt gets have no event in the transcript format.
+func (t *transcript) toolCallStarted(server string, tc ToolCallSpec) string {
+	if tc.ResourceURI != "" || tc.Prompt != "" {
+		return ""
+	}
+	id := t.nextID()
+	t.emit(event{Type: "item.started", Item: &item{ID: id, Type: "mcp_tool_call", Server: server, Tool: tc.Name, Status: "in_progress"}})
+	return id
+}
+
+func (t *transcript) toolCallCompleted(id, server string, tc ToolCallSpec, succeeded bool) {
+	if id == "" {
+		return
+	}
+	status := "completed"
+	if !succeeded {
+		status = "failed"
+	}
+	t.emit(event{Type: "item.completed", Item: &item{ID: id, Type: "mcp_tool_call", Server: server, Tool: tc.Name, Status: status}})
+}
+
+// finish writes the response and ends the transcript
+func (t *transcript) finish(response string) {
+	if !t.stream {
+		fmt.Fprint(t.w, response)
+		return
+	}
+	if response != "" {
+		t.message(response)
+	}
+	t.emit(event{Type: "turn.completed", Usage: &usage{}})
+}
+
+func (t *transcript) nextID() string {
+	id := fmt.Sprintf("item_%d", t.items)
+	t.items++
+	return id
+}
+
+func (t *transcript) emit(e event) {
+	if !t.stream {
+		return
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal event: %v\n", err)
+		return
+	}
+	fmt.Fprintln(t.w, string(data))
+}
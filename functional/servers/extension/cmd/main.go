@@ -0,0 +1,24 @@
+// Package main provides the mock extension binary entry point.
+// The mock extension is configured via a JSON file specified by the
+// MOCK_EXTENSION_CONFIG environment variable.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mcpchecker/mcpchecker/functional/servers/extension"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := extension.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,269 @@
+package extension
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// Config defines the mock extension's manifest and scripted responses.
+// This is serialized to a file by the test framework and read by the extension binary.
+type Config struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+
+	// InitializeError causes the extension to fail the initialize request
+	InitializeError string `json:"initializeError,omitempty"`
+
+	// EnvironmentInfo is returned from the initialize request
+	EnvironmentInfo map[string]string `json:"environmentInfo,omitempty"`
+
+	Operations map[string]*OperationDef `json:"operations,omitempty"`
+	Assertions map[string]*AssertionDef `json:"assertions,omitempty"`
+}
+
+// NewConfig creates a new empty config for an extension with the given name
+func NewConfig(name string) *Config {
+	return &Config{
+		Name:       name,
+		Version:    "0.0.1",
+		Operations: make(map[string]*OperationDef),
+		Assertions: make(map[string]*AssertionDef),
+	}
+}
+
+// Fault injects a failure into a response
+type Fault struct {
+	// Delay is waited before responding
+	Delay util.Duration `json:"delay,omitempty"`
+
+	// Crash causes the extension to exit without responding
+	Crash bool `json:"crash,omitempty"`
+}
+
+// OperationDef defines an operation of the mock extension
+type OperationDef struct {
+	Description string            `json:"description,omitempty"`
+	Params      jsonschema.Schema `json:"params"`
+
+	// Responses are returned in order, one per call. The last response is
+	// repeated once all have been returned.
+	Responses []OperationResponse `json:"responses,omitempty"`
+}
+
+// OperationResponse is the scripted response to one call of an operation
+type OperationResponse struct {
+	Fault
+	Result protocol.ExecuteResult `json:"result"`
+}
+
+// NewOperation creates a new operation definition that accepts any arguments
+func NewOperation() *OperationDef {
+	return &OperationDef{
+		Params: jsonschema.Schema{Type: "object"},
+	}
+}
+
+// WithDescription sets the operation's description
+func (o *OperationDef) WithDescription(desc string) *OperationDef {
+	o.Description = desc
+	return o
+}
+
+// WithParams sets the JSON schema the operation's arguments are validated against
+func (o *OperationDef) WithParams(schema jsonschema.Schema) *OperationDef {
+	o.Params = schema
+	return o
+}
+
+// WithStringParam adds a string parameter to the operation
+func (o *OperationDef) WithStringParam(name, description string, required bool) *OperationDef {
+	addParam(&o.Params, name, "string", description, required)
+	return o
+}
+
+// ReturnsSuccess adds a successful response with a message
+func (o *OperationDef) ReturnsSuccess(message string) *OperationDef {
+	return o.respond(OperationResponse{Result: protocol.ExecuteResult{Success: true, Message: message}})
+}
+
+// ReturnsOutputs adds a successful response with a message and step outputs
+func (o *OperationDef) ReturnsOutputs(message string, outputs map[string]string) *OperationDef {
+	return o.respond(OperationResponse{Result: protocol.ExecuteResult{Success: true, Message: message, Outputs: outputs}})
+}
+
+// ReturnsFailure adds a failed response with an error
+func (o *OperationDef) ReturnsFailure(err string) *OperationDef {
+	return o.respond(OperationResponse{Result: protocol.ExecuteResult{Success: false, Error: err}})
+}
+
+// Crashes adds a response that makes the extension exit without responding
+func (o *OperationDef) Crashes() *OperationDef {
+	return o.respond(OperationResponse{Fault: Fault{Crash: true}})
+}
+
+// WithDelay delays the last added response
+func (o *OperationDef) WithDelay(delay time.Duration) *OperationDef {
+	if len(o.Responses) > 0 {
+		o.Responses[len(o.Responses)-1].Delay = util.Duration(delay)
+	}
+	return o
+}
+
+func (o *OperationDef) respond(r OperationResponse) *OperationDef {
+	o.Responses = append(o.Responses, r)
+	return o
+}
+
+// AssertionDef defines an assertion of the mock extension
+type AssertionDef struct {
+	Description string            `json:"description,omitempty"`
+	Params      jsonschema.Schema `json:"params"`
+
+	// Responses are returned in order, one per call. The last response is
+	// repeated once all have been returned.
+	Responses []AssertionResponse `json:"responses,omitempty"`
+}
+
+// AssertionResponse is the scripted response to one evaluation of an assertion
+type AssertionResponse struct {
+	Fault
+	Result protocol.AssertResult `json:"result"`
+}
+
+// NewAssertion creates a new assertion definition that accepts any arguments
+func NewAssertion() *AssertionDef {
+	return &AssertionDef{
+		Params: jsonschema.Schema{Type: "object"},
+	}
+}
+
+// WithDescription sets the assertion's description
+func (a *AssertionDef) WithDescription(desc string) *AssertionDef {
+	a.Description = desc
+	return a
+}
+
+// WithParams sets the JSON schema the assertion's arguments are validated against
+func (a *AssertionDef) WithParams(schema jsonschema.Schema) *AssertionDef {
+	a.Params = schema
+	return a
+}
+
+// WithStringParam adds a string parameter to the assertion
+func (a *AssertionDef) WithStringParam(name, description string, required bool) *AssertionDef {
+	addParam(&a.Params, name, "string", description, required)
+	return a
+}
+
+// Passes adds a passing response
+func (a *AssertionDef) Passes() *AssertionDef {
+	return a.respond(AssertionResponse{Result: protocol.AssertResult{Passed: true}})
+}
+
+// Fails adds a failing response with a reason and optional details
+func (a *AssertionDef) Fails(reason string, details ...string) *AssertionDef {
+	return a.respond(AssertionResponse{Result: protocol.AssertResult{Passed: false, Reason: reason, Details: details}})
+}
+
+// Crashes adds a response that makes the extension exit without responding
+func (a *AssertionDef) Crashes() *AssertionDef {
+	return a.respond(AssertionResponse{Fault: Fault{Crash: true}})
+}
+
+// WithDelay delays the last added response
+func (a *AssertionDef) WithDelay(delay time.Duration) *AssertionDef {
+	if len(a.Responses) > 0 {
+		a.Responses[len(a.Responses)-1].Delay = util.Duration(delay)
+	}
+	return a
+}
+
+func (a *AssertionDef) respond(r AssertionResponse) *AssertionDef {
+	a.Responses = append(a.Responses, r)
+	return a
+}
+
+func addParam(schema *jsonschema.Schema, name, typ, description string, required bool) {
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]*jsonschema.Schema)
+	}
+	schema.Properties[name] = &jsonschema.Schema{Type: typ, Description: description}
+	if required {
+		schema.Required = append(schema.Required, name)
+	}
+}
+
+// CapturedCall records an operation or assertion call the extension received
+type CapturedCall struct {
+	// Kind is "execute" for operations and "assert" for assertions
+	Kind  string                 `json:"kind"`
+	Name  string                 `json:"name"`
+	Args  map[string]any         `json:"args,omitempty"`
+	Agent *protocol.AgentContext `json:"agent,omitempty"`
+}
+
+// LoadConfig reads a config from a JSON file
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		return nil, fmt.Errorf("config path is required")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// SaveConfig writes a config to a JSON file
+func SaveConfig(config *Config, path string) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadCalls reads the calls recorded by the extension. A missing file means
+// the extension received no calls.
+func LoadCalls(path string) ([]CapturedCall, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calls file: %w", err)
+	}
+	defer f.Close()
+
+	var calls []CapturedCall
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var call CapturedCall
+		if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+			return nil, fmt.Errorf("failed to parse recorded call: %w", err)
+		}
+		calls = append(calls, call)
+	}
+
+	return calls, scanner.Err()
+}
@@ -0,0 +1,149 @@
+// Package extension provides a mock mcpchecker extension whose operations and
+// assertions return scripted responses.
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+)
+
+const (
+	// EnvConfigPath is the environment variable for the config file path
+	EnvConfigPath = "MOCK_EXTENSION_CONFIG"
+	// EnvCallsPath is the environment variable for the file the received
+	// calls are recorded to, one JSON object per line
+	EnvCallsPath = "MOCK_EXTENSION_CALLS"
+)
+
+// Run serves the mock extension over stdio.
+// This is the main entry point called by the cmd/main.go binary.
+func Run(ctx context.Context) error {
+	config, err := LoadConfig(os.Getenv(EnvConfigPath))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return New(config, os.Getenv(EnvCallsPath)).Run(ctx)
+}
+
+// New creates an extension from a config. Calls are recorded to callsPath
+// when it is set.
+func New(config *Config, callsPath string) *sdk.Extension {
+	r := &recorder{path: callsPath, counts: make(map[string]int)}
+
+	ext := sdk.NewExtension(sdk.ExtensionInfo{
+		Name:    config.Name,
+		Version: config.Version,
+	}, sdk.WithInitializeHandler(func(map[string]any) error {
+		if config.InitializeError != "" {
+			return fmt.Errorf("%s", config.InitializeError)
+		}
+		return nil
+	}), sdk.WithEnvironmentInfo(func() (map[string]string, error) {
+		return config.EnvironmentInfo, nil
+	}))
+
+	for name, op := range config.Operations {
+		ext.AddOperation(
+			sdk.NewOperation(name, sdk.WithDescription(op.Description), sdk.WithParams(op.Params)),
+			func(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+				n := r.record("execute", name, req.Args, req.Context.Agent)
+				if len(op.Responses) == 0 {
+					return sdk.Success(""), nil
+				}
+
+				resp := op.Responses[min(n, len(op.Responses)-1)]
+				resp.Fault.apply(ctx)
+				result := resp.Result
+				return &result, nil
+			},
+		)
+	}
+
+	for name, a := range config.Assertions {
+		ext.AddAssertion(
+			sdk.NewOperation(name, sdk.WithDescription(a.Description), sdk.WithParams(a.Params)),
+			func(ctx context.Context, req *sdk.AssertionRequest) (*sdk.AssertionResult, error) {
+				n := r.record("assert", name, req.Args, req.Context.Agent)
+				if len(a.Responses) == 0 {
+					return sdk.Pass(), nil
+				}
+
+				resp := a.Responses[min(n, len(a.Responses)-1)]
+				resp.Fault.apply(ctx)
+				result := resp.Result
+				return &result, nil
+			},
+		)
+	}
+
+	return ext
+}
+
+// apply waits for the delay of the fault and exits the process if it crashes
+func (f Fault) apply(ctx context.Context) {
+	if f.Delay > 0 {
+		select {
+		case <-time.After(time.Duration(f.Delay)):
+		case <-ctx.Done():
+		}
+	}
+
+	if f.Crash {
+		os.Exit(1)
+	}
+}
+
+// recorder counts the calls of each operation and assertion and appends them
+// to the calls file
+type recorder struct {
+	mu     sync.Mutex
+	path   string
+	counts map[string]int
+}
+
+// record records a call and returns how many calls of the same operation or
+// assertion were made before it
+func (r *recorder) record(kind, name string, args any, agent *protocol.AgentContext) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := kind + "/" + name
+	n := r.counts[key]
+	r.counts[key]++
+
+	if r.path == "" {
+		return n
+	}
+
+	call := CapturedCall{Kind: kind, Name: name, Agent: agent}
+	if m, ok := args.(map[string]any); ok {
+		call.Args = m
+	}
+
+	data, err := json.Marshal(call)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record call: %v\n", err)
+		return n
+	}
+
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record call: %v\n", err)
+		return n
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to record call: %v\n", err)
+	}
+
+	return n
+}
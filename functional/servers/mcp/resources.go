@@ -0,0 +1,203 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ResourceHandler is a function that handles a resource read. It receives the
+// URI that was read, which for resource templates is the expanded URI.
+type ResourceHandler func(ctx context.Context, uri string) (*mcp.ReadResourceResult, error)
+
+// ResourceDef defines a resource to be registered with the mock MCP server
+type ResourceDef struct {
+	URI         string
+	Name        string
+	Description string
+	MIMEType    string
+
+	// Response configuration (use one of these)
+	Text    string          // Static text content to return
+	Handler ResourceHandler // Dynamic handler function
+}
+
+// NewResource creates a new resource definition with the given URI and text content
+func NewResource(uri, text string) *ResourceDef {
+	return &ResourceDef{
+		URI:      uri,
+		Name:     uri,
+		MIMEType: "text/plain",
+		Text:     text,
+	}
+}
+
+// WithName sets the resource's name
+func (r *ResourceDef) WithName(name string) *ResourceDef {
+	r.Name = name
+	return r
+}
+
+// WithDescription sets the resource's description
+func (r *ResourceDef) WithDescription(desc string) *ResourceDef {
+	r.Description = desc
+	return r
+}
+
+// WithMIMEType sets the resource's MIME type
+func (r *ResourceDef) WithMIMEType(mimeType string) *ResourceDef {
+	r.MIMEType = mimeType
+	return r
+}
+
+// WithHandler sets a dynamic handler for the resource
+func (r *ResourceDef) WithHandler(handler ResourceHandler) *ResourceDef {
+	r.Handler = handler
+	r.Text = ""
+	return r
+}
+
+// ResourceTemplateDef defines a resource template to be registered with the
+// mock MCP server
+type ResourceTemplateDef struct {
+	URITemplate string
+	Name        string
+	Description string
+	MIMEType    string
+
+	// Response configuration (use one of these). Text is returned for every
+	// URI matching the template.
+	Text    string
+	Handler ResourceHandler
+}
+
+// NewResourceTemplate creates a new resource template definition with the
+// given RFC 6570 URI template
+func NewResourceTemplate(uriTemplate string) *ResourceTemplateDef {
+	return &ResourceTemplateDef{
+		URITemplate: uriTemplate,
+		Name:        uriTemplate,
+		MIMEType:    "text/plain",
+	}
+}
+
+// WithName sets the resource template's name
+func (r *ResourceTemplateDef) WithName(name string) *ResourceTemplateDef {
+	r.Name = name
+	return r
+}
+
+// WithDescription sets the resource template's description
+func (r *ResourceTemplateDef) WithDescription(desc string) *ResourceTemplateDef {
+	r.Description = desc
+	return r
+}
+
+// WithMIMEType sets the MIME type of the resources matching the template
+func (r *ResourceTemplateDef) WithMIMEType(mimeType string) *ResourceTemplateDef {
+	r.MIMEType = mimeType
+	return r
+}
+
+// ReturnsText sets the text returned for every URI matching the template
+func (r *ResourceTemplateDef) ReturnsText(text string) *ResourceTemplateDef {
+	r.Text = text
+	r.Handler = nil
+	return r
+}
+
+// WithHandler sets a dynamic handler for the resource template
+func (r *ResourceTemplateDef) WithHandler(handler ResourceHandler) *ResourceTemplateDef {
+	r.Handler = handler
+	r.Text = ""
+	return r
+}
+
+// PromptDef defines a prompt to be registered with the mock MCP server
+type PromptDef struct {
+	Name        string
+	Description string
+	Arguments   []*mcp.PromptArgument
+
+	// Messages are returned in order. Occurrences of {name} in their text are
+	// replaced with the value of the argument with that name.
+	Messages []PromptMessageDef
+}
+
+// PromptMessageDef is a text message returned by a prompt
+type PromptMessageDef struct {
+	Role mcp.Role
+	Text string
+}
+
+// NewPrompt creates a new prompt definition with the given name
+func NewPrompt(name string) *PromptDef {
+	return &PromptDef{
+		Name:      name,
+		Arguments: make([]*mcp.PromptArgument, 0),
+		Messages:  make([]PromptMessageDef, 0),
+	}
+}
+
+// WithDescription sets the prompt's description
+func (p *PromptDef) WithDescription(desc string) *PromptDef {
+	p.Description = desc
+	return p
+}
+
+// WithArgument adds an argument to the prompt
+func (p *PromptDef) WithArgument(name, description string, required bool) *PromptDef {
+	p.Arguments = append(p.Arguments, &mcp.PromptArgument{
+		Name:        name,
+		Description: description,
+		Required:    required,
+	})
+	return p
+}
+
+// WithUserMessage adds a user message to the prompt
+func (p *PromptDef) WithUserMessage(text string) *PromptDef {
+	p.Messages = append(p.Messages, PromptMessageDef{Role: "user", Text: text})
+	return p
+}
+
+// WithAssistantMessage adds an assistant message to the prompt
+func (p *PromptDef) WithAssistantMessage(text string) *PromptDef {
+	p.Messages = append(p.Messages, PromptMessageDef{Role: "assistant", Text: text})
+	return p
+}
+
+// render builds the result of the prompt for the given arguments
+func (p *PromptDef) render(args map[string]string) *mcp.GetPromptResult {
+	pairs := make([]string, 0, 2*len(args))
+	for name, value := range args {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	result := &mcp.GetPromptResult{
+		Description: p.Description,
+		Messages:    make([]*mcp.PromptMessage, 0, len(p.Messages)),
+	}
+	for _, m := range p.Messages {
+		result.Messages = append(result.Messages, &mcp.PromptMessage{
+			Role:    m.Role,
+			Content: &mcp.TextContent{Text: replacer.Replace(m.Text)},
+		})
+	}
+	return result
+}
+
+// TextResourceResult creates a resource read result with a single text content
+func TextResourceResult(uri, mimeType, text string) *mcp.ReadResourceResult {
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: mimeType,
+				Text:     text,
+			},
+		},
+	}
+}
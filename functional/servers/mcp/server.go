@@ -15,32 +15,59 @@ import (
 
 // MockMCPServer implements a mock MCP server using Streamable HTTP transport
 type MockMCPServer struct {
-	mu       sync.Mutex
-	name     string
-	tools    []*ToolDef
-	calls    []CapturedToolCall
-	server   *mcp.Server
-	listener net.Listener
-	httpSrv  *http.Server
-	ready    chan struct{}
+	mu        sync.Mutex
+	name      string
+	tools     []*ToolDef
+	resources []*ResourceDef
+	templates []*ResourceTemplateDef
+	prompts   []*PromptDef
+	calls     []CapturedToolCall
+	counts    map[string]int
+	reads     []CapturedResourceRead
+	gets      []CapturedPromptGet
+	server    *mcp.Server
+	listener  net.Listener
+	httpSrv   *http.Server
+	ready     chan struct{}
 }
 
 // CapturedToolCall stores details of a tool invocation for assertions
 type CapturedToolCall struct {
 	ToolName  string
 	Arguments map[string]any
+	// Header is the header of the HTTP request of the call
+	Header    http.Header
 	Result    *mcp.CallToolResult
 	Error     error
 	Timestamp time.Time
 }
 
+// CapturedResourceRead stores details of a resource read for assertions
+type CapturedResourceRead struct {
+	URI       string
+	Result    *mcp.ReadResourceResult
+	Error     error
+	Timestamp time.Time
+}
+
+// CapturedPromptGet stores details of a prompt get for assertions
+type CapturedPromptGet struct {
+	PromptName string
+	Arguments  map[string]string
+	Result     *mcp.GetPromptResult
+	Timestamp  time.Time
+}
+
 // NewMockMCPServer creates a new mock MCP server with the given name
 func NewMockMCPServer(name string) *MockMCPServer {
 	return &MockMCPServer{
-		name:  name,
-		tools: make([]*ToolDef, 0),
-		calls: make([]CapturedToolCall, 0),
-		ready: make(chan struct{}),
+		name:   name,
+		tools:  make([]*ToolDef, 0),
+		calls:  make([]CapturedToolCall, 0),
+		counts: make(map[string]int),
+		reads:  make([]CapturedResourceRead, 0),
+		gets:   make([]CapturedPromptGet, 0),
+		ready:  make(chan struct{}),
 	}
 }
 
@@ -51,6 +78,27 @@ func (s *MockMCPServer) AddTool(tool *ToolDef) {
 	s.tools = append(s.tools, tool)
 }
 
+// AddResource registers a resource with the mock server
+func (s *MockMCPServer) AddResource(resource *ResourceDef) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources = append(s.resources, resource)
+}
+
+// AddResourceTemplate registers a resource template with the mock server
+func (s *MockMCPServer) AddResourceTemplate(template *ResourceTemplateDef) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates = append(s.templates, template)
+}
+
+// AddPrompt registers a prompt with the mock server
+func (s *MockMCPServer) AddPrompt(prompt *PromptDef) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompts = append(s.prompts, prompt)
+}
+
 // Start starts the server on a random available port and returns the URL
 func (s *MockMCPServer) Start() (string, error) {
 	s.mu.Lock()
@@ -63,14 +111,25 @@ func (s *MockMCPServer) Start() (string, error) {
 			Version: "1.0.0",
 		},
 		&mcp.ServerOptions{
-			HasTools: len(s.tools) > 0,
+			HasTools:     len(s.tools) > 0,
+			HasResources: len(s.resources) > 0 || len(s.templates) > 0,
+			HasPrompts:   len(s.prompts) > 0,
 		},
 	)
 
-	// Register all tools
+	// Register all tools, resources, and prompts
 	for _, toolDef := range s.tools {
 		s.registerTool(toolDef)
 	}
+	for _, resourceDef := range s.resources {
+		s.registerResource(resourceDef)
+	}
+	for _, templateDef := range s.templates {
+		s.registerResourceTemplate(templateDef)
+	}
+	for _, promptDef := range s.prompts {
+		s.registerPrompt(promptDef)
+	}
 
 	// Listen on random port
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
@@ -130,18 +189,37 @@ func (s *MockMCPServer) registerTool(toolDef *ToolDef) {
 			Arguments: args,
 			Timestamp: time.Now(),
 		}
+		if req.Extra != nil {
+			captured.Header = req.Extra.Header
+		}
+
+		s.mu.Lock()
+		resp := toolDef.response(s.counts[toolDef.Name])
+		s.counts[toolDef.Name]++
+		s.mu.Unlock()
 
 		var result *mcp.CallToolResult
 		var err error
 
+		if resp.Latency > 0 {
+			select {
+			case <-time.After(resp.Latency):
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		}
+
 		// Use custom handler if provided, otherwise use static result
-		if toolDef.Handler != nil {
-			result, err = toolDef.Handler(ctx, args)
-		} else if toolDef.Result != nil {
-			result = toolDef.Result
-		} else if toolDef.Error != nil {
-			err = toolDef.Error
-		} else {
+		switch {
+		case err != nil:
+			// The call was cancelled while waiting
+		case resp.Handler != nil:
+			result, err = resp.Handler(ctx, args)
+		case resp.Result != nil:
+			result = resp.Result
+		case resp.Error != nil:
+			err = resp.Error
+		default:
 			// Default empty result
 			result = &mcp.CallToolResult{
 				Content: []mcp.Content{},
@@ -161,6 +239,83 @@ func (s *MockMCPServer) registerTool(toolDef *ToolDef) {
 	s.server.AddTool(mcpTool, handler)
 }
 
+// registerResource adds a resource to the MCP server
+func (s *MockMCPServer) registerResource(resourceDef *ResourceDef) {
+	mcpResource := &mcp.Resource{
+		URI:         resourceDef.URI,
+		Name:        resourceDef.Name,
+		Description: resourceDef.Description,
+		MIMEType:    resourceDef.MIMEType,
+	}
+
+	s.server.AddResource(mcpResource, s.resourceHandler(resourceDef.MIMEType, resourceDef.Text, resourceDef.Handler))
+}
+
+// registerResourceTemplate adds a resource template to the MCP server
+func (s *MockMCPServer) registerResourceTemplate(templateDef *ResourceTemplateDef) {
+	mcpTemplate := &mcp.ResourceTemplate{
+		URITemplate: templateDef.URITemplate,
+		Name:        templateDef.Name,
+		Description: templateDef.Description,
+		MIMEType:    templateDef.MIMEType,
+	}
+
+	s.server.AddResourceTemplate(mcpTemplate, s.resourceHandler(templateDef.MIMEType, templateDef.Text, templateDef.Handler))
+}
+
+// resourceHandler creates a handler that captures reads and returns the
+// configured content
+func (s *MockMCPServer) resourceHandler(mimeType, text string, handler ResourceHandler) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+
+		var result *mcp.ReadResourceResult
+		var err error
+		if handler != nil {
+			result, err = handler(ctx, uri)
+		} else {
+			result = TextResourceResult(uri, mimeType, text)
+		}
+
+		s.mu.Lock()
+		s.reads = append(s.reads, CapturedResourceRead{
+			URI:       uri,
+			Result:    result,
+			Error:     err,
+			Timestamp: time.Now(),
+		})
+		s.mu.Unlock()
+
+		return result, err
+	}
+}
+
+// registerPrompt adds a prompt to the MCP server
+func (s *MockMCPServer) registerPrompt(promptDef *PromptDef) {
+	mcpPrompt := &mcp.Prompt{
+		Name:        promptDef.Name,
+		Description: promptDef.Description,
+		Arguments:   promptDef.Arguments,
+	}
+
+	handler := func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		result := promptDef.render(req.Params.Arguments)
+
+		s.mu.Lock()
+		s.gets = append(s.gets, CapturedPromptGet{
+			PromptName: req.Params.Name,
+			Arguments:  req.Params.Arguments,
+			Result:     result,
+			Timestamp:  time.Now(),
+		})
+		s.mu.Unlock()
+
+		return result, nil
+	}
+
+	s.server.AddPrompt(mcpPrompt, handler)
+}
+
 // parseArguments converts the Arguments (which can be any) to map[string]any.
 // Returns an empty map if conversion fails, logging a warning for debugging.
 func parseArguments(args any) map[string]any {
@@ -252,11 +407,58 @@ func (s *MockMCPServer) LastCall() *CapturedToolCall {
 	return &call
 }
 
-// Reset clears all captured calls
+// ResourceReads returns all captured resource reads
+func (s *MockMCPServer) ResourceReads() []CapturedResourceRead {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]CapturedResourceRead, len(s.reads))
+	copy(result, s.reads)
+	return result
+}
+
+// ReadsForResource returns all reads of a specific resource URI
+func (s *MockMCPServer) ReadsForResource(uri string) []CapturedResourceRead {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]CapturedResourceRead, 0)
+	for _, read := range s.reads {
+		if read.URI == uri {
+			result = append(result, read)
+		}
+	}
+	return result
+}
+
+// PromptGets returns all captured prompt gets
+func (s *MockMCPServer) PromptGets() []CapturedPromptGet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]CapturedPromptGet, len(s.gets))
+	copy(result, s.gets)
+	return result
+}
+
+// GetsForPrompt returns all gets of a specific prompt
+func (s *MockMCPServer) GetsForPrompt(name string) []CapturedPromptGet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]CapturedPromptGet, 0)
+	for _, get := range s.gets {
+		if get.PromptName == name {
+			result = append(result, get)
+		}
+	}
+	return result
+}
+
+// Reset clears all captured calls, resource reads, and prompt gets
 func (s *MockMCPServer) Reset() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.calls = make([]CapturedToolCall, 0)
+	s.counts = make(map[string]int)
+	s.reads = make([]CapturedResourceRead, 0)
+	s.gets = make([]CapturedPromptGet, 0)
 }
 
 // WaitReady blocks until the server is ready
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -21,6 +22,22 @@ type ToolDef struct {
 	Result  *mcp.CallToolResult // Static result to return
 	Error   error               // Error to return
 	Handler ToolHandler         // Dynamic handler function
+
+	// Latency is waited before responding
+	Latency time.Duration
+
+	// Sequence holds the responses configured before each call of Then.
+	// Calls get them in order, after which the response configured last is
+	// repeated.
+	Sequence []ToolResponse
+}
+
+// ToolResponse is one response in the sequence of a tool
+type ToolResponse struct {
+	Result  *mcp.CallToolResult
+	Error   error
+	Handler ToolHandler
+	Latency time.Duration
 }
 
 // NewTool creates a new tool definition with the given name
@@ -140,6 +157,43 @@ func (t *ToolDef) WithHandler(handler ToolHandler) *ToolDef {
 	return t
 }
 
+// WithLatency delays the response configured last
+func (t *ToolDef) WithLatency(latency time.Duration) *ToolDef {
+	t.Latency = latency
+	return t
+}
+
+// Then ends the configuration of the current response, so that the next
+// Returns* call configures the response to the following call. For example,
+// ReturnsErrorText("busy").Then().ReturnsText("ok") fails the first call and
+// answers all later calls.
+func (t *ToolDef) Then() *ToolDef {
+	t.Sequence = append(t.Sequence, t.current())
+	t.Result = nil
+	t.Error = nil
+	t.Handler = nil
+	t.Latency = 0
+	return t
+}
+
+// current returns the response configured last
+func (t *ToolDef) current() ToolResponse {
+	return ToolResponse{
+		Result:  t.Result,
+		Error:   t.Error,
+		Handler: t.Handler,
+		Latency: t.Latency,
+	}
+}
+
+// response returns the response to the call with the given index, counting from 0
+func (t *ToolDef) response(call int) ToolResponse {
+	if call < len(t.Sequence) {
+		return t.Sequence[call]
+	}
+	return t.current()
+}
+
 // Result helper functions
 
 // TextResult creates a text content result
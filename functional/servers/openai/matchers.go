@@ -210,6 +210,20 @@ func SeedIs(seed int64) RequestMatcher {
 	return SeedMatcher{Seed: seed}
 }
 
+// StreamMatcher matches streaming or non-streaming requests
+type StreamMatcher struct {
+	Stream bool
+}
+
+func (m StreamMatcher) Matches(req *ChatCompletionRequest) bool {
+	return req.Stream == m.Stream
+}
+
+// IsStreaming returns a matcher for requests asking for a streamed response
+func IsStreaming() RequestMatcher {
+	return StreamMatcher{Stream: true}
+}
+
 // AndMatcher combines multiple matchers with AND logic
 type AndMatcher struct {
 	Matchers []RequestMatcher
@@ -43,6 +43,12 @@ type Response struct {
 	Error      *APIError
 	StatusCode int           // Defaults to 200
 	Delay      time.Duration // Simulate latency
+
+	// ChunkSize is how many characters of content or tool call arguments
+	// each chunk carries when the request streams. Defaults to DefaultChunkSize.
+	ChunkSize int
+	// ChunkDelay is waited after each chunk when the request streams
+	ChunkDelay time.Duration
 }
 
 // APIError represents an OpenAI API error response
@@ -231,6 +237,12 @@ func (s *MockOpenAIServer) handleChatCompletions(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// Stream the response if the request asked for it
+	if response.Body != nil && req.Stream {
+		s.writeStream(w, &req, response)
+		return
+	}
+
 	// Return success response
 	if response.Body != nil {
 		w.Header().Set("Content-Type", "application/json")
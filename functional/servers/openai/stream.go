@@ -0,0 +1,171 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultChunkSize is how many characters of content or tool call arguments
+// each streamed chunk carries unless Response.ChunkSize is set
+const DefaultChunkSize = 8
+
+// StreamOptions are the options of a streaming request
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage,omitempty"`
+}
+
+// ChatCompletionChunk is a server-sent event of a streaming response
+type ChatCompletionChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []ChunkChoice `json:"choices"`
+	Usage   *Usage        `json:"usage,omitempty"`
+}
+
+// ChunkChoice is the part of a choice carried by a chunk
+type ChunkChoice struct {
+	Index        int     `json:"index"`
+	Delta        Delta   `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+// Delta is the part of a message carried by a chunk
+type Delta struct {
+	Role      string          `json:"role,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	ToolCalls []ToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// ToolCallDelta is the part of a tool call carried by a chunk. The ID, type,
+// and function name are only set in the first chunk of a tool call.
+type ToolCallDelta struct {
+	Index    int          `json:"index"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// writeStream writes a response body as server-sent events, the way the API
+// answers requests with stream set: the message of every choice is split into
+// chunks, each choice ends with a chunk carrying its finish reason, and the
+// usage follows in a chunk without choices if the request asked for it.
+func (s *MockOpenAIServer) writeStream(w http.ResponseWriter, req *ChatCompletionRequest, response *Response) {
+	body := response.Body
+	size := response.ChunkSize
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	send := func(data string) {
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if response.ChunkDelay > 0 {
+			time.Sleep(response.ChunkDelay)
+		}
+	}
+
+	sendChunk := func(choices []ChunkChoice, usage *Usage) {
+		data, err := json.Marshal(ChatCompletionChunk{
+			ID:      body.ID,
+			Object:  "chat.completion.chunk",
+			Created: body.Created,
+			Model:   body.Model,
+			Choices: choices,
+			Usage:   usage,
+		})
+		if err != nil {
+			fmt.Printf("OpenAI mock server failed to marshal chunk: %v\n", err)
+			return
+		}
+		send(string(data))
+	}
+
+	for _, choice := range body.Choices {
+		for _, delta := range splitMessage(choice.Message, size) {
+			sendChunk([]ChunkChoice{{Index: choice.Index, Delta: delta}}, nil)
+		}
+		finishReason := choice.FinishReason
+		sendChunk([]ChunkChoice{{Index: choice.Index, FinishReason: &finishReason}}, nil)
+	}
+
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage && body.Usage != nil {
+		sendChunk([]ChunkChoice{}, body.Usage)
+	}
+
+	send("[DONE]")
+}
+
+// splitMessage splits a message into the deltas of its chunks. The first
+// delta carries the role.
+func splitMessage(msg Message, size int) []Delta {
+	deltas := []Delta{{Role: msg.Role}}
+
+	for _, part := range splitString(msg.Content, size) {
+		deltas = append(deltas, Delta{Content: part})
+	}
+
+	for i, tc := range msg.ToolCalls {
+		deltas = append(deltas, Delta{ToolCalls: []ToolCallDelta{{
+			Index:    i,
+			ID:       tc.ID,
+			Type:     tc.Type,
+			Function: FunctionCall{Name: tc.Function.Name},
+		}}})
+		for _, part := range splitString(tc.Function.Arguments, size) {
+			deltas = append(deltas, Delta{ToolCalls: []ToolCallDelta{{
+				Index:    i,
+				Function: FunctionCall{Arguments: part},
+			}}})
+		}
+	}
+
+	return deltas
+}
+
+// splitString splits s into parts of at most size characters
+func splitString(s string, size int) []string {
+	runes := []rune(s)
+	parts := make([]string, 0, (len(runes)+size-1)/size)
+	for len(runes) > 0 {
+		n := min(size, len(runes))
+		parts = append(parts, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return parts
+}
+
+// TextResponse creates a response with an assistant message
+func TextResponse(content string) *Response {
+	return &Response{
+		Body: &ChatCompletionResponse{
+			ID:      "chatcmpl-mock-text",
+			Object:  "chat.completion",
+			Created: time.Now().Unix(),
+			Model:   "gpt-4",
+			Choices: []Choice{{
+				Index: 0,
+				Message: Message{
+					Role:    "assistant",
+					Content: content,
+				},
+				FinishReason: "stop",
+			}},
+			Usage: &Usage{
+				PromptTokens:     100,
+				CompletionTokens: 50,
+				TotalTokens:      150,
+			},
+		},
+	}
+}
@@ -11,6 +11,10 @@ type ChatCompletionRequest struct {
 	Tools      []Tool      `json:"tools,omitempty"`
 	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
 	Seed       *int64      `json:"seed,omitempty"`
+
+	// Stream asks for the response as server-sent events
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
 }
 
 // Message represents a chat message
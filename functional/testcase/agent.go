@@ -1,6 +1,8 @@
 package testcase
 
 import (
+	"time"
+
 	"github.com/mcpchecker/mcpchecker/functional/servers/agent"
 )
 
@@ -97,7 +99,7 @@ func (bb *BehaviorBuilder) CallToolOnServer(server, name string, args map[string
 
 // CallToolExpectingError adds a tool call that is expected to return an error
 func (bb *BehaviorBuilder) CallToolExpectingError(name string, args map[string]any) *BehaviorBuilder {
-	bb.behavior.ToolCalls = append(bb.behavior.ToolCalls, agent.ToolCallSpec{
+	bb.behavior.AddToolCall(agent.ToolCallSpec{
 		Name:        name,
 		Arguments:   args,
 		ExpectError: true,
@@ -105,6 +107,66 @@ func (bb *BehaviorBuilder) CallToolExpectingError(name string, args map[string]a
 	return bb
 }
 
+// ReadResource adds a resource read to this behavior.
+// The resource will be read from the first available MCP server.
+func (bb *BehaviorBuilder) ReadResource(uri string) *BehaviorBuilder {
+	bb.behavior.ReadResource(uri)
+	return bb
+}
+
+// ReadResourceOnServer adds a resource read from a specific MCP server
+func (bb *BehaviorBuilder) ReadResourceOnServer(server, uri string) *BehaviorBuilder {
+	bb.behavior.ReadResourceOnServer(server, uri)
+	return bb
+}
+
+// GetPrompt adds a prompt get to this behavior.
+// The prompt will be requested from the first available MCP server.
+func (bb *BehaviorBuilder) GetPrompt(name string, args map[string]any) *BehaviorBuilder {
+	bb.behavior.GetPrompt(name, args)
+	return bb
+}
+
+// GetPromptOnServer adds a prompt get from a specific MCP server
+func (bb *BehaviorBuilder) GetPromptOnServer(server, name string, args map[string]any) *BehaviorBuilder {
+	bb.behavior.GetPromptOnServer(server, name, args)
+	return bb
+}
+
+// Think adds a reasoning step to this behavior.
+// It only shows in the output when events are streamed.
+func (bb *BehaviorBuilder) Think(text string) *BehaviorBuilder {
+	bb.behavior.Think(text)
+	return bb
+}
+
+// RunCommand adds a step reporting a command execution to this behavior.
+// The command is not run; it only shows in the output when events are streamed.
+func (bb *BehaviorBuilder) RunCommand(command, output string, exitCode int) *BehaviorBuilder {
+	bb.behavior.RunCommand(command, output, exitCode)
+	return bb
+}
+
+// Say adds an assistant message to this behavior, printed before the response
+func (bb *BehaviorBuilder) Say(text string) *BehaviorBuilder {
+	bb.behavior.Say(text)
+	return bb
+}
+
+// Wait delays the next step of this behavior, or its response if no step follows
+func (bb *BehaviorBuilder) Wait(delay time.Duration) *BehaviorBuilder {
+	bb.behavior.Wait(delay)
+	return bb
+}
+
+// StreamEvents makes the agent print a JSON event transcript in the format of
+// `codex exec --json` for this behavior: one event per step, as it happens,
+// followed by the response as the final message.
+func (bb *BehaviorBuilder) StreamEvents() *BehaviorBuilder {
+	bb.behavior.StreamEvents()
+	return bb
+}
+
 // ThenRespond sets the response and finalizes this behavior.
 // Returns the AgentBuilder to continue configuration.
 func (bb *BehaviorBuilder) ThenRespond(response string) *AgentBuilder {
@@ -126,6 +188,8 @@ func (bb *BehaviorBuilder) ThenFail(err string) *AgentBuilder {
 type (
 	AgentConfig  = agent.Config
 	Behavior     = agent.Behavior
+	Step         = agent.Step
+	CommandSpec  = agent.CommandSpec
 	ToolCallSpec = agent.ToolCallSpec
 	MCPConfig    = agent.MCPConfig
 	ServerConfig = agent.ServerConfig
@@ -15,10 +15,16 @@ type TestCase struct {
 	mcpServers map[string]*MCPServerBuilder
 	judgeMock  *JudgeBuilder
 	agentMock  *AgentBuilder
+	extensions map[string]*MockExtensionBuilder
 
 	// Configuration
-	tasks []*TaskConfig
-	eval  *EvalConfig
+	tasks   []*TaskConfig
+	tasksV2 []*TaskConfigV2
+	eval    *EvalConfig
+
+	// Environment variables and extra arguments passed to mcpchecker
+	env       []string
+	extraArgs []string
 
 	// Assertions to run after the test
 	assertions []Assertion
@@ -30,6 +36,7 @@ func New(t *testing.T, name string) *TestCase {
 		t:          t,
 		name:       name,
 		mcpServers: make(map[string]*MCPServerBuilder),
+		extensions: make(map[string]*MockExtensionBuilder),
 		assertions: make([]Assertion, 0),
 	}
 }
@@ -57,6 +64,15 @@ func (tc *TestCase) WithJudge(configure func(*JudgeBuilder)) *TestCase {
 	return tc
 }
 
+// WithExtension adds a mock extension to the eval under the given alias.
+// Tasks use it with TaskConfigV2.RequireExtension and its extension steps.
+func (tc *TestCase) WithExtension(alias string, configure func(*MockExtensionBuilder)) *TestCase {
+	builder := NewMockExtensionBuilder(alias)
+	configure(builder)
+	tc.extensions[alias] = builder
+	return tc
+}
+
 // WithTasks configures multiple tasks for this test case
 func (tc *TestCase) WithTasks(configureFuncs ...func(*TaskConfig)) *TestCase {
 	tc.tasks = make([]*TaskConfig, 0, len(configureFuncs))
@@ -76,6 +92,15 @@ func (tc *TestCase) AddTask(configure func(*TaskConfig)) *TestCase {
 	return tc
 }
 
+// AddTaskV2 adds a task in the step-based format to the test case (can be
+// called multiple times). These tasks are run after the legacy tasks.
+func (tc *TestCase) AddTaskV2(configure func(*TaskConfigV2)) *TestCase {
+	task := NewTaskConfigV2()
+	configure(task)
+	tc.tasksV2 = append(tc.tasksV2, task)
+	return tc
+}
+
 // WithEval configures the eval settings for this test case
 func (tc *TestCase) WithEval(configure func(*EvalConfig)) *TestCase {
 	tc.eval = NewEvalConfig()
@@ -83,6 +108,21 @@ func (tc *TestCase) WithEval(configure func(*EvalConfig)) *TestCase {
 	return tc
 }
 
+// WithEnv sets an environment variable for the mcpchecker run. It is inherited
+// by the agent, scripts, and MCP servers mcpchecker starts, and takes precedence
+// over the variables set by the runner (can be called multiple times).
+func (tc *TestCase) WithEnv(key, value string) *TestCase {
+	tc.env = append(tc.env, key+"="+value)
+	return tc
+}
+
+// WithExtraArgs appends arguments to the `mcpchecker check` command line,
+// after the eval file (can be called multiple times)
+func (tc *TestCase) WithExtraArgs(args ...string) *TestCase {
+	tc.extraArgs = append(tc.extraArgs, args...)
+	return tc
+}
+
 // Expect adds an assertion to be checked after the test runs
 func (tc *TestCase) Expect(a Assertion) *TestCase {
 	tc.assertions = append(tc.assertions, a)
@@ -104,6 +144,16 @@ func (tc *TestCase) ExpectTaskFailedWithError(contains string) *TestCase {
 	return tc.Expect(&TaskFailedWithErrorAssertion{Contains: contains})
 }
 
+// ExpectAllAssertionsPassed asserts that all eval assertions passed
+func (tc *TestCase) ExpectAllAssertionsPassed() *TestCase {
+	return tc.Expect(&AllAssertionsPassedAssertion{})
+}
+
+// ExpectAssertionsFailed asserts that eval assertions failed
+func (tc *TestCase) ExpectAssertionsFailed() *TestCase {
+	return tc.Expect(&AssertionsFailedAssertion{})
+}
+
 // ExpectExitCode asserts the command exit code
 func (tc *TestCase) ExpectExitCode(code int) *TestCase {
 	return tc.Expect(&ExitCodeAssertion{Expected: code})
@@ -129,6 +179,22 @@ func (tc *TestCase) ExpectToolNotCalled(server, tool string) *TestCase {
 	return tc.Expect(&ToolNotCalledAssertion{Server: server, Tool: tool})
 }
 
+// ExpectTimelineContains asserts that the timeline shown by `mcpchecker view`
+// contains the entries in order
+func (tc *TestCase) ExpectTimelineContains(entries ...string) *TestCase {
+	return tc.Expect(&TimelineContainsAssertion{Entries: entries})
+}
+
+// ExpectResourceRead asserts that a resource was read from a server
+func (tc *TestCase) ExpectResourceRead(server, uri string) *TestCase {
+	return tc.Expect(&ResourceReadAssertion{Server: server, URI: uri})
+}
+
+// ExpectPromptUsed asserts that a prompt was requested from a server
+func (tc *TestCase) ExpectPromptUsed(server, prompt string) *TestCase {
+	return tc.Expect(&PromptUsedAssertion{Server: server, Prompt: prompt})
+}
+
 // ExpectJudgeCalled asserts that the judge was called
 func (tc *TestCase) ExpectJudgeCalled() *TestCase {
 	return tc.Expect(&JudgeCalledAssertion{})
@@ -139,6 +205,26 @@ func (tc *TestCase) ExpectJudgeNotCalled() *TestCase {
 	return tc.Expect(&JudgeNotCalledAssertion{})
 }
 
+// ExpectExtensionCalled asserts that an operation of an extension was executed
+func (tc *TestCase) ExpectExtensionCalled(alias, operation string) *TestCase {
+	return tc.Expect(&ExtensionCalledAssertion{Alias: alias, Kind: "execute", Name: operation, Times: -1})
+}
+
+// ExpectExtensionCalledTimes asserts that an operation of an extension was executed a specific number of times
+func (tc *TestCase) ExpectExtensionCalledTimes(alias, operation string, times int) *TestCase {
+	return tc.Expect(&ExtensionCalledAssertion{Alias: alias, Kind: "execute", Name: operation, Times: times})
+}
+
+// ExpectExtensionNotCalled asserts that an operation of an extension was not executed
+func (tc *TestCase) ExpectExtensionNotCalled(alias, operation string) *TestCase {
+	return tc.Expect(&ExtensionCalledAssertion{Alias: alias, Kind: "execute", Name: operation, Times: 0})
+}
+
+// ExpectExtensionAssertionCalled asserts that an assertion of an extension was evaluated
+func (tc *TestCase) ExpectExtensionAssertionCalled(alias, assertion string) *TestCase {
+	return tc.Expect(&ExtensionCalledAssertion{Alias: alias, Kind: "assert", Name: assertion, Times: -1})
+}
+
 // ExpectOutputContains asserts that the command output contains a substring
 func (tc *TestCase) ExpectOutputContains(substring string) *TestCase {
 	return tc.Expect(&OutputContainsAssertion{Substring: substring})
@@ -1,8 +1,12 @@
 package testcase
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
 	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/procmon"
 )
 
 // EvalConfig provides a fluent API for building eval configurations.
@@ -98,6 +102,44 @@ func (ec *EvalConfig) TaskGlob(pattern string) *EvalConfig {
 	return ec
 }
 
+// McpTaskMetadata sets the headers and environment variables injected into
+// the MCP servers for each task
+func (ec *EvalConfig) McpTaskMetadata(headers, env map[string]string) *EvalConfig {
+	ec.spec.Config.McpTaskMetadata = &eval.TaskMetadataConfig{
+		Headers: headers,
+		Env:     env,
+	}
+	return ec
+}
+
+// OutputExpectation adds an output expectation that fails every task whose
+// output matches any of the patterns
+func (ec *EvalConfig) OutputExpectation(name string, mustNotMatch ...string) *EvalConfig {
+	ec.spec.Config.OutputExpectations = append(ec.spec.Config.OutputExpectations, eval.OutputExpectation{
+		Name:         name,
+		MustNotMatch: mustNotMatch,
+	})
+	return ec
+}
+
+// SafetyScan sets the safety scan of the tasks
+func (ec *EvalConfig) SafetyScan(scan *eval.SafetyScanConfig) *EvalConfig {
+	ec.spec.Config.SafetyScan = scan
+	return ec
+}
+
+// Tokenizer sets how the tokens of tool results are estimated
+func (ec *EvalConfig) Tokenizer(tokenizer *eval.TokenizerConfig) *EvalConfig {
+	ec.spec.Config.Tokenizer = tokenizer
+	return ec
+}
+
+// AgentResources sets the CPU and memory limits of the agent
+func (ec *EvalConfig) AgentResources(cfg *procmon.Config) *EvalConfig {
+	ec.spec.Config.AgentResources = cfg
+	return ec
+}
+
 // Build returns the eval spec
 func (ec *EvalConfig) Build() *eval.EvalSpec {
 	return ec.spec
@@ -171,6 +213,39 @@ func (b *LLMJudgeConfigBuilder) UseDefaults() *LLMJudgeConfigBuilder {
 	return b
 }
 
+// Endpoint adds a judge endpoint, after the endpoints added before it. The
+// mock judge server is reached with the keys E2E_OPENAI_BASE_URL,
+// E2E_OPENAI_API_KEY, and E2E_OPENAI_MODEL.
+func (b *LLMJudgeConfigBuilder) Endpoint(name, baseURLKey, apiKeyKey, modelKey string) *LLMJudgeConfigBuilder {
+	b.config.Endpoints = append(b.config.Endpoints, llmjudge.LLMJudgeEndpointConfig{
+		Name: name,
+		LLMJudgeEnvConfig: llmjudge.LLMJudgeEnvConfig{
+			BaseUrlKey:   baseURLKey,
+			ApiKeyKey:    apiKeyKey,
+			ModelNameKey: modelKey,
+		},
+	})
+	return b
+}
+
+// SystemPrompt sets the template of the judge's system prompt
+func (b *LLMJudgeConfigBuilder) SystemPrompt(template string) *LLMJudgeConfigBuilder {
+	if b.config.Prompts == nil {
+		b.config.Prompts = &llmjudge.LLMJudgePromptsConfig{}
+	}
+	b.config.Prompts.System = template
+	return b
+}
+
+// UserPrompt sets the template of the judge's user prompt
+func (b *LLMJudgeConfigBuilder) UserPrompt(template string) *LLMJudgeConfigBuilder {
+	if b.config.Prompts == nil {
+		b.config.Prompts = &llmjudge.LLMJudgePromptsConfig{}
+	}
+	b.config.Prompts.User = template
+	return b
+}
+
 // TaskSetBuilder builds a task set configuration
 type TaskSetBuilder struct {
 	set eval.TaskSet
@@ -293,6 +368,17 @@ func (b *AssertionsBuilder) RequireResourcePattern(server, pattern string) *Asse
 	return b
 }
 
+// RequireResourceTemplate adds a resource that must be read through a resource
+// template, with the given values of its variables
+func (b *AssertionsBuilder) RequireResourceTemplate(server, template string, params map[string]string) *AssertionsBuilder {
+	b.assertions.ResourcesRead = append(b.assertions.ResourcesRead, eval.ResourceAssertion{
+		Server:         server,
+		Template:       template,
+		TemplateParams: params,
+	})
+	return b
+}
+
 // ForbidResource adds a resource that must not be read
 func (b *AssertionsBuilder) ForbidResource(server, uri string) *AssertionsBuilder {
 	b.assertions.ResourcesNotRead = append(b.assertions.ResourcesNotRead, eval.ResourceAssertion{
@@ -311,6 +397,16 @@ func (b *AssertionsBuilder) RequirePrompt(server, prompt string) *AssertionsBuil
 	return b
 }
 
+// RequirePromptWithArgs adds a prompt that must be used with the given argument values
+func (b *AssertionsBuilder) RequirePromptWithArgs(server, prompt string, args map[string]string) *AssertionsBuilder {
+	b.assertions.PromptsUsed = append(b.assertions.PromptsUsed, eval.PromptAssertion{
+		Server:    server,
+		Prompt:    prompt,
+		Arguments: args,
+	})
+	return b
+}
+
 // ForbidPrompt adds a prompt that must not be used
 func (b *AssertionsBuilder) ForbidPrompt(server, prompt string) *AssertionsBuilder {
 	b.assertions.PromptsNotUsed = append(b.assertions.PromptsNotUsed, eval.PromptAssertion{
@@ -350,9 +446,40 @@ func (b *AssertionsBuilder) CallOrderPrompt(server, name string) *AssertionsBuil
 	return b
 }
 
+// MaxAgentDuration sets the maximum time the agent may take
+func (b *AssertionsBuilder) MaxAgentDuration(d time.Duration) *AssertionsBuilder {
+	b.assertions.MaxAgentDuration = d.String()
+	return b
+}
+
+// MaxContextTokens sets the maximum estimated tokens the tool results may
+// add to the context
+func (b *AssertionsBuilder) MaxContextTokens(n int) *AssertionsBuilder {
+	b.assertions.MaxContextTokens = &n
+	return b
+}
+
 // NoDuplicateCalls requires that no duplicate tool calls are made
 func (b *AssertionsBuilder) NoDuplicateCalls() *AssertionsBuilder {
-	b.assertions.NoDuplicateCalls = true
+	b.assertions.NoDuplicateCalls = &eval.NoDuplicateCallsAssertion{}
+	return b
+}
+
+// GroundedOutput requires that the names and IDs in the agent output come
+// from tool results, resources, or prompts
+func (b *AssertionsBuilder) GroundedOutput() *AssertionsBuilder {
+	b.assertions.GroundedOutput = &eval.GroundedOutputAssertion{}
+	return b
+}
+
+// Custom adds a custom assertion, such as an extension assertion named
+// <extension>.<assertion>, with its config
+func (b *AssertionsBuilder) Custom(name string, config map[string]any) *AssertionsBuilder {
+	if b.assertions.Custom == nil {
+		b.assertions.Custom = make(map[string]json.RawMessage)
+	}
+	raw, _ := json.Marshal(config)
+	b.assertions.Custom[name] = raw
 	return b
 }
 
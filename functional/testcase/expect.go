@@ -1,10 +1,13 @@
 package testcase
 
 import (
+	"fmt"
+	"os/exec"
 	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/mcpchecker/mcpchecker/functional/servers/extension"
 	"github.com/mcpchecker/mcpchecker/functional/servers/mcp"
 	"github.com/mcpchecker/mcpchecker/functional/servers/openai"
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
@@ -16,6 +19,9 @@ type RunContext struct {
 	// Parsed eval results from the output JSON file
 	EvalResults []*eval.EvalResult
 
+	// OutputFile is the path of the output JSON file
+	OutputFile string
+
 	// Command execution results (for lower-level checks)
 	CommandOutput string
 	ExitCode      int
@@ -24,6 +30,9 @@ type RunContext struct {
 	// Captured data from mock servers (for detailed checks)
 	MCPServers  map[string]*mcp.MockMCPServer
 	JudgeServer *openai.MockOpenAIServer
+
+	// Calls received by the mock extensions, by alias
+	ExtensionCalls map[string][]extension.CapturedCall
 }
 
 // FirstResult returns the first eval result, or nil if none
@@ -44,6 +53,22 @@ func (ctx *RunContext) ResultForTask(name string) *eval.EvalResult {
 	return nil
 }
 
+// View runs `mcpchecker view` on the output file with the given flags and
+// returns its output
+func (ctx *RunContext) View(args ...string) (string, error) {
+	binary, err := GetMcpCheckerBinary()
+	if err != nil {
+		return "", err
+	}
+
+	args = append([]string{"view"}, args...)
+	out, err := exec.Command(binary, append(args, ctx.OutputFile)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("mcpchecker view failed: %w\n%s", err, out)
+	}
+	return string(out), nil
+}
+
 // Assertion defines an expectation that can be checked after a test runs
 type Assertion interface {
 	Assert(t *testing.T, ctx *RunContext)
@@ -159,6 +184,15 @@ func (a *AllAssertionsPassedAssertion) Assert(t *testing.T, ctx *RunContext) {
 			if result.AssertionResults.MaxToolCalls != nil && !result.AssertionResults.MaxToolCalls.Passed {
 				t.Errorf("maxToolCalls failed: %s", result.AssertionResults.MaxToolCalls.Reason)
 			}
+			if result.AssertionResults.ResourcesRead != nil && !result.AssertionResults.ResourcesRead.Passed {
+				t.Errorf("resourcesRead failed: %s", result.AssertionResults.ResourcesRead.Reason)
+			}
+			if result.AssertionResults.PromptsUsed != nil && !result.AssertionResults.PromptsUsed.Passed {
+				t.Errorf("promptsUsed failed: %s", result.AssertionResults.PromptsUsed.Reason)
+			}
+			if result.AssertionResults.CallOrder != nil && !result.AssertionResults.CallOrder.Passed {
+				t.Errorf("callOrder failed: %s", result.AssertionResults.CallOrder.Reason)
+			}
 		}
 	}
 }
@@ -331,6 +365,101 @@ func (a *ToolCalledAssertion) Assert(t *testing.T, ctx *RunContext) {
 	}
 }
 
+// TimelineContainsAssertion asserts that the timeline shown by `mcpchecker
+// view` contains entries in order
+type TimelineContainsAssertion struct {
+	Entries []string
+}
+
+func (a *TimelineContainsAssertion) Assert(t *testing.T, ctx *RunContext) {
+	t.Helper()
+	out, err := ctx.View("--max-events", "0")
+	if err != nil {
+		t.Errorf("%v", err)
+		return
+	}
+
+	rest := out
+	for _, entry := range a.Entries {
+		i := strings.Index(rest, entry)
+		if i < 0 {
+			t.Errorf("expected timeline to contain %q after the previous entries, got:\n%s", entry, out)
+			return
+		}
+		rest = rest[i+len(entry):]
+	}
+}
+
+// ResourceReadAssertion asserts that a resource was read (via mock server capture)
+type ResourceReadAssertion struct {
+	Server string
+	URI    string
+}
+
+func (a *ResourceReadAssertion) Assert(t *testing.T, ctx *RunContext) {
+	t.Helper()
+	server, ok := ctx.MCPServers[a.Server]
+	if !ok {
+		t.Errorf("MCP server %q not found", a.Server)
+		return
+	}
+
+	if len(server.ReadsForResource(a.URI)) == 0 {
+		t.Errorf("expected resource %q to be read from server %q, but it was not read", a.URI, a.Server)
+	}
+}
+
+// PromptUsedAssertion asserts that a prompt was requested (via mock server capture)
+type PromptUsedAssertion struct {
+	Server string
+	Prompt string
+}
+
+func (a *PromptUsedAssertion) Assert(t *testing.T, ctx *RunContext) {
+	t.Helper()
+	server, ok := ctx.MCPServers[a.Server]
+	if !ok {
+		t.Errorf("MCP server %q not found", a.Server)
+		return
+	}
+
+	if len(server.GetsForPrompt(a.Prompt)) == 0 {
+		t.Errorf("expected prompt %q to be requested from server %q, but it was not requested", a.Prompt, a.Server)
+	}
+}
+
+// ExtensionCalledAssertion asserts how often an operation or assertion of a
+// mock extension was called
+type ExtensionCalledAssertion struct {
+	Alias string
+	Kind  string // "execute" for operations, "assert" for assertions
+	Name  string
+	Times int // -1 means at least once
+}
+
+func (a *ExtensionCalledAssertion) Assert(t *testing.T, ctx *RunContext) {
+	t.Helper()
+	calls, ok := ctx.ExtensionCalls[a.Alias]
+	if !ok {
+		t.Errorf("extension %q not found", a.Alias)
+		return
+	}
+
+	count := 0
+	for _, call := range calls {
+		if call.Kind == a.Kind && call.Name == a.Name {
+			count++
+		}
+	}
+
+	switch {
+	case a.Times < 0 && count == 0:
+		t.Errorf("expected %q of extension %q to be called, but it was not called", a.Name, a.Alias)
+	case a.Times >= 0 && count != a.Times:
+		t.Errorf("expected %q of extension %q to be called %d times, got %d", a.Name, a.Alias, a.Times, count)
+	}
+}
+
 // ToolCalledTimesAssertion asserts that a tool was called a specific number of times
 type ToolCalledTimesAssertion struct {
 	Server string
@@ -0,0 +1,83 @@
+package testcase
+
+import (
+	"github.com/mcpchecker/mcpchecker/functional/servers/extension"
+)
+
+// MockExtensionBuilder provides a fluent API for configuring a mock extension.
+// The extension declares the configured operations and assertions in its
+// manifest and answers calls with their scripted responses.
+type MockExtensionBuilder struct {
+	config *extension.Config
+}
+
+// NewMockExtensionBuilder creates a new mock extension builder
+func NewMockExtensionBuilder(name string) *MockExtensionBuilder {
+	return &MockExtensionBuilder{
+		config: extension.NewConfig(name),
+	}
+}
+
+// Operation adds an operation to the extension using a fluent configuration callback.
+// The callback receives a *extension.OperationDef which has methods like:
+//   - WithDescription(desc string)
+//   - WithParams(schema jsonschema.Schema)
+//   - WithStringParam(name, description string, required bool)
+//   - ReturnsSuccess(message string)
+//   - ReturnsOutputs(message string, outputs map[string]string)
+//   - ReturnsFailure(err string)
+//   - Crashes()
+//   - WithDelay(delay time.Duration)
+//
+// Each Returns* call adds a response to a sequence: the first call of the
+// operation gets the first response, and the last response is repeated.
+// Without responses, the operation succeeds.
+func (b *MockExtensionBuilder) Operation(name string, configure func(*extension.OperationDef)) *MockExtensionBuilder {
+	op := extension.NewOperation()
+	configure(op)
+	b.config.Operations[name] = op
+	return b
+}
+
+// Assertion adds an assertion to the extension using a fluent configuration callback.
+// The callback receives a *extension.AssertionDef which has methods like:
+//   - WithDescription(desc string)
+//   - WithStringParam(name, description string, required bool)
+//   - Passes()
+//   - Fails(reason string, details ...string)
+//   - Crashes()
+//   - WithDelay(delay time.Duration)
+//
+// Responses form a sequence like those of operations. Without responses,
+// the assertion passes.
+func (b *MockExtensionBuilder) Assertion(name string, configure func(*extension.AssertionDef)) *MockExtensionBuilder {
+	a := extension.NewAssertion()
+	configure(a)
+	b.config.Assertions[name] = a
+	return b
+}
+
+// FailInitialize makes the extension fail to initialize with the given error
+func (b *MockExtensionBuilder) FailInitialize(err string) *MockExtensionBuilder {
+	b.config.InitializeError = err
+	return b
+}
+
+// EnvironmentInfo makes the extension report environment info from initialize
+func (b *MockExtensionBuilder) EnvironmentInfo(info map[string]string) *MockExtensionBuilder {
+	b.config.EnvironmentInfo = info
+	return b
+}
+
+// Build returns the extension configuration
+func (b *MockExtensionBuilder) Build() *extension.Config {
+	return b.config
+}
+
+// Re-export types from extension package for convenience
+type (
+	ExtensionConfig       = extension.Config
+	ExtensionOperationDef = extension.OperationDef
+	ExtensionAssertionDef = extension.AssertionDef
+	CapturedExtensionCall = extension.CapturedCall
+)
@@ -245,3 +245,27 @@ func (g *Generator) writeTaskYAML(basename string, taskConfig *TaskConfig) (stri
 
 	return g.writeYAML(basename, wrapper)
 }
+
+// writeTaskYAMLV2 writes a single step-based task config to a YAML file.
+// The caller is responsible for providing a unique basename to avoid collisions.
+func (g *Generator) writeTaskYAMLV2(basename string, taskConfig *TaskConfigV2) (string, error) {
+	if taskConfig.datasetFile != "" {
+		if _, err := g.WriteFile(taskConfig.datasetFile, taskConfig.datasetContent); err != nil {
+			return "", err
+		}
+	}
+	for i, file := range taskConfig.promptFiles {
+		if _, err := g.WriteFile(file, taskConfig.promptFileContents[i]); err != nil {
+			return "", err
+		}
+	}
+
+	wrapper := map[string]any{
+		"apiVersion": util.APIVersionV1Alpha2,
+		"kind":       task.KindTask,
+		"metadata":   taskConfig.Metadata(),
+		"spec":       taskConfig.Build(),
+	}
+
+	return g.writeYAML(basename, wrapper)
+}
@@ -6,8 +6,11 @@ import (
 
 // MCPServerBuilder builds a mock MCP server configuration
 type MCPServerBuilder struct {
-	name  string
-	tools []*mcp.ToolDef
+	name      string
+	tools     []*mcp.ToolDef
+	resources []*mcp.ResourceDef
+	templates []*mcp.ResourceTemplateDef
+	prompts   []*mcp.PromptDef
 }
 
 // NewMCPServerBuilder creates a new MCP server builder
@@ -31,6 +34,11 @@ func NewMCPServerBuilder(name string) *MCPServerBuilder {
 //   - ReturnsErrorText(message string)
 //   - ReturnsError(err error)
 //   - WithHandler(handler ToolHandler)
+//   - WithLatency(latency time.Duration)
+//   - Then()
+//
+// Then starts the response to the next call, so that calls can get different
+// responses; the response configured last is repeated.
 func (b *MCPServerBuilder) Tool(name string, configure func(*mcp.ToolDef)) *MCPServerBuilder {
 	tool := mcp.NewTool(name)
 	configure(tool)
@@ -44,28 +52,94 @@ func (b *MCPServerBuilder) AddTool(tool *mcp.ToolDef) *MCPServerBuilder {
 	return b
 }
 
-// Build creates the mock MCP server with all configured tools
+// Resource adds a resource with static text content to the MCP server
+func (b *MCPServerBuilder) Resource(uri, content string) *MCPServerBuilder {
+	b.resources = append(b.resources, mcp.NewResource(uri, content))
+	return b
+}
+
+// AddResource adds a pre-configured resource definition
+func (b *MCPServerBuilder) AddResource(resource *mcp.ResourceDef) *MCPServerBuilder {
+	b.resources = append(b.resources, resource)
+	return b
+}
+
+// ResourceTemplate adds a resource template to the MCP server using a fluent
+// configuration callback. The callback receives a *mcp.ResourceTemplateDef
+// which has methods like:
+//   - WithName(name string)
+//   - WithDescription(desc string)
+//   - WithMIMEType(mimeType string)
+//   - ReturnsText(text string)
+//   - WithHandler(handler ResourceHandler)
+func (b *MCPServerBuilder) ResourceTemplate(uriTemplate string, configure func(*mcp.ResourceTemplateDef)) *MCPServerBuilder {
+	template := mcp.NewResourceTemplate(uriTemplate)
+	configure(template)
+	b.templates = append(b.templates, template)
+	return b
+}
+
+// Prompt adds a prompt that returns the given messages as user messages.
+// Occurrences of {name} in a message are replaced with the value of the
+// argument with that name.
+func (b *MCPServerBuilder) Prompt(name string, messages ...string) *MCPServerBuilder {
+	prompt := mcp.NewPrompt(name)
+	for _, message := range messages {
+		prompt.WithUserMessage(message)
+	}
+	b.prompts = append(b.prompts, prompt)
+	return b
+}
+
+// AddPrompt adds a pre-configured prompt definition, for prompts that declare
+// arguments or return assistant messages
+func (b *MCPServerBuilder) AddPrompt(prompt *mcp.PromptDef) *MCPServerBuilder {
+	b.prompts = append(b.prompts, prompt)
+	return b
+}
+
+// Build creates the mock MCP server with all configured tools, resources, and prompts
 func (b *MCPServerBuilder) Build() *mcp.MockMCPServer {
 	server := mcp.NewMockMCPServer(b.name)
 	for _, tool := range b.tools {
 		server.AddTool(tool)
 	}
+	for _, resource := range b.resources {
+		server.AddResource(resource)
+	}
+	for _, template := range b.templates {
+		server.AddResourceTemplate(template)
+	}
+	for _, prompt := range b.prompts {
+		server.AddPrompt(prompt)
+	}
 	return server
 }
 
 // Re-export types and helpers from mcp package for convenience
 type (
-	ToolDef        = mcp.ToolDef
-	ToolHandler    = mcp.ToolHandler
-	MockMCPServer  = mcp.MockMCPServer
-	CapturedToolCall = mcp.CapturedToolCall
+	ToolDef              = mcp.ToolDef
+	ToolHandler          = mcp.ToolHandler
+	ToolResponse         = mcp.ToolResponse
+	ResourceDef          = mcp.ResourceDef
+	ResourceTemplateDef  = mcp.ResourceTemplateDef
+	ResourceHandler      = mcp.ResourceHandler
+	PromptDef            = mcp.PromptDef
+	MockMCPServer        = mcp.MockMCPServer
+	CapturedToolCall     = mcp.CapturedToolCall
+	CapturedResourceRead = mcp.CapturedResourceRead
+	CapturedPromptGet    = mcp.CapturedPromptGet
 )
 
 // Re-export result helpers for convenience
 var (
-	NewTool     = mcp.NewTool
-	TextResult  = mcp.TextResult
-	JSONResult  = mcp.JSONResult
-	ErrorResult = mcp.ErrorResult
-	EmptyResult = mcp.EmptyResult
+	NewTool             = mcp.NewTool
+	NewResource         = mcp.NewResource
+	NewResourceTemplate = mcp.NewResourceTemplate
+	NewPrompt           = mcp.NewPrompt
+	TextResult          = mcp.TextResult
+	JSONResult          = mcp.JSONResult
+	ErrorResult         = mcp.ErrorResult
+	EmptyResult         = mcp.EmptyResult
+	TextResourceResult  = mcp.TextResourceResult
 )
@@ -11,14 +11,17 @@ import (
 	"time"
 
 	"github.com/mcpchecker/mcpchecker/functional/servers/agent"
+	"github.com/mcpchecker/mcpchecker/functional/servers/extension"
 	"github.com/mcpchecker/mcpchecker/functional/servers/mcp"
 	"github.com/mcpchecker/mcpchecker/functional/servers/openai"
+	evalext "github.com/mcpchecker/mcpchecker/pkg/extension"
 )
 
 // Environment variables for binary paths
 const (
 	EnvMcpCheckerBinary = "MCPCHECKER_BINARY"
 	EnvMockAgentBinary = "MOCK_AGENT_BINARY"
+	EnvMockExtensionBinary = "MOCK_EXTENSION_BINARY"
 )
 
 // Runner orchestrates the execution of a test case
@@ -38,6 +41,9 @@ type Runner struct {
 	mcpConfigFile string
 	agentConfig   string
 	outputFile    string
+
+	// Files the mock extensions record their calls to, by alias
+	extensionCalls map[string]string
 }
 
 // Run executes the test case
@@ -149,6 +155,17 @@ func (r *Runner) generateConfigs() error {
 		}
 		r.taskFiles = append(r.taskFiles, path)
 	}
+	for i, task := range r.tc.tasksV2 {
+		filename := fmt.Sprintf("task-%d.yaml", len(r.tc.tasks)+i)
+		if task.metadata.Name != "" {
+			filename = fmt.Sprintf("task-%d-%s.yaml", len(r.tc.tasks)+i, task.metadata.Name)
+		}
+		path, err := r.generator.writeTaskYAMLV2(filename, task)
+		if err != nil {
+			return err
+		}
+		r.taskFiles = append(r.taskFiles, path)
+	}
 
 	// Generate MCP config JSON
 	r.mcpConfigFile, err = r.generator.GenerateMCPConfigJSON(r.mcpURLs)
@@ -188,13 +205,22 @@ func (r *Runner) generateConfigs() error {
 			if evalSpec.Config.LLMJudge == nil {
 				evalSpec.Config.LLMJudge = &LLMJudgeEvalConfig{}
 			}
-			// Use custom environment variable keys for the mock server
-			if evalSpec.Config.LLMJudge.Env == nil {
-				evalSpec.Config.LLMJudge.Env = &LLMJudgeEnvConfig{}
+			// Use custom environment variable keys for the mock server. Tests
+			// that list endpoints point one of them at the mock server with
+			// the same keys.
+			if len(evalSpec.Config.LLMJudge.Endpoints) == 0 {
+				if evalSpec.Config.LLMJudge.Env == nil {
+					evalSpec.Config.LLMJudge.Env = &LLMJudgeEnvConfig{}
+				}
+				evalSpec.Config.LLMJudge.Env.BaseUrlKey = "E2E_OPENAI_BASE_URL"
+				evalSpec.Config.LLMJudge.Env.ApiKeyKey = "E2E_OPENAI_API_KEY"
+				evalSpec.Config.LLMJudge.Env.ModelNameKey = "E2E_OPENAI_MODEL"
 			}
-			evalSpec.Config.LLMJudge.Env.BaseUrlKey = "E2E_OPENAI_BASE_URL"
-			evalSpec.Config.LLMJudge.Env.ApiKeyKey = "E2E_OPENAI_API_KEY"
-			evalSpec.Config.LLMJudge.Env.ModelNameKey = "E2E_OPENAI_MODEL"
+		}
+
+		// Register the mock extensions
+		if err := r.configureExtensions(evalSpec); err != nil {
+			return err
 		}
 
 		// Add tasks to task sets if not already configured
@@ -221,6 +247,45 @@ func (r *Runner) generateConfigs() error {
 	return nil
 }
 
+// configureExtensions writes the config of each mock extension and adds the
+// extension to the eval spec. The extensions are passed their config and the
+// file to record their calls to via environment variables.
+func (r *Runner) configureExtensions(evalSpec *EvalSpec) error {
+	if len(r.tc.extensions) == 0 {
+		return nil
+	}
+
+	mockExtensionBinary, err := GetMockExtensionBinary()
+	if err != nil {
+		return err
+	}
+
+	if evalSpec.Config.Extensions == nil {
+		evalSpec.Config.Extensions = make(map[string]*evalext.ExtensionSpec)
+	}
+	r.extensionCalls = make(map[string]string)
+
+	for alias, builder := range r.tc.extensions {
+		configPath, err := r.generator.writeJSON(fmt.Sprintf("extension-%s.json", alias), builder.Build())
+		if err != nil {
+			return err
+		}
+
+		callsPath := filepath.Join(r.generator.TempDir(), fmt.Sprintf("extension-%s-calls.jsonl", alias))
+		r.extensionCalls[alias] = callsPath
+
+		evalSpec.Config.Extensions[alias] = &evalext.ExtensionSpec{
+			Package: mockExtensionBinary,
+			Env: map[string]string{
+				extension.EnvConfigPath: configPath,
+				extension.EnvCallsPath:  callsPath,
+			},
+		}
+	}
+
+	return nil
+}
+
 // generateAgentSpecFile creates an agent spec YAML that uses the mock agent binary.
 // The agent spec follows mcpchecker's expected format with commands templates.
 func (r *Runner) generateAgentSpecFile() (string, error) {
@@ -257,6 +322,7 @@ func (r *Runner) generateAgentSpecFile() (string, error) {
 
 func (r *Runner) runMcpChecker(ctx context.Context) *RunContext {
 	runCtx := &RunContext{
+		OutputFile:  r.outputFile,
 		MCPServers:  r.mcpServers,
 		JudgeServer: r.judgeServer,
 	}
@@ -268,7 +334,7 @@ func (r *Runner) runMcpChecker(ctx context.Context) *RunContext {
 	}
 
 	// Build command - eval takes config file as positional argument
-	args := []string{"check", r.evalFile}
+	args := append([]string{"check", r.evalFile}, r.tc.extraArgs...)
 	cmd := exec.CommandContext(ctx, mcpCheckerBinary, args...)
 
 	// Run from temp directory so output file is written there
@@ -283,6 +349,8 @@ func (r *Runner) runMcpChecker(ctx context.Context) *RunContext {
 	if r.agentConfig != "" {
 		cmd.Env = append(cmd.Env, agent.EnvConfigPath+"="+r.agentConfig)
 	}
+	// Cache judge verdicts per test, so that tests never see each other's
+	cmd.Env = append(cmd.Env, "MCPCHECKER_CACHE_DIR="+filepath.Join(r.generator.TempDir(), "cache"))
 	// Set OpenAI API key to dummy value (we're using mock)
 	cmd.Env = append(cmd.Env, "OPENAI_API_KEY=sk-mock-key")
 
@@ -293,6 +361,9 @@ func (r *Runner) runMcpChecker(ctx context.Context) *RunContext {
 		cmd.Env = append(cmd.Env, "E2E_OPENAI_MODEL=gpt-4")
 	}
 
+	// Variables set by the test case come last so they override the above
+	cmd.Env = append(cmd.Env, r.tc.env...)
+
 	// Run command
 	err = cmd.Run()
 	runCtx.CommandOutput = stdout.String() + stderr.String()
@@ -308,6 +379,16 @@ func (r *Runner) runMcpChecker(ctx context.Context) *RunContext {
 		r.t.Logf("command output:\n%s", runCtx.CommandOutput)
 	}
 
+	// Collect the calls the mock extensions received
+	runCtx.ExtensionCalls = make(map[string][]extension.CapturedCall)
+	for alias, path := range r.extensionCalls {
+		calls, err := extension.LoadCalls(path)
+		if err != nil {
+			r.t.Logf("warning: failed to read calls of extension %q: %v", alias, err)
+		}
+		runCtx.ExtensionCalls[alias] = calls
+	}
+
 	// Parse eval results from output file
 	if _, statErr := os.Stat(r.outputFile); statErr == nil {
 		results, parseErr := ReadEvalResults(r.outputFile)
@@ -401,3 +482,39 @@ func GetMockAgentBinary() (string, error) {
 
 	return "", fmt.Errorf("mock-agent binary not found; set %s environment variable", EnvMockAgentBinary)
 }
+
+// GetMockExtensionBinary returns the path to the mock extension binary.
+// It first checks the MOCK_EXTENSION_BINARY environment variable,
+// then looks for the binary in common locations.
+func GetMockExtensionBinary() (string, error) {
+	// Check environment variable first
+	if path := os.Getenv(EnvMockExtensionBinary); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return filepath.Abs(path)
+		}
+		return "", fmt.Errorf("MOCK_EXTENSION_BINARY set to %q but file not found", path)
+	}
+
+	// Try common locations relative to working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []string{
+		filepath.Join(wd, "..", "..", "bin", "mock-extension"), // from functional/testcase or functional/tests
+		filepath.Join(wd, "..", "bin", "mock-extension"),       // from functional
+		filepath.Join(wd, "bin", "mock-extension"),             // current dir
+		filepath.Join(wd, "..", "..", "mock-extension"),        // repo root
+		filepath.Join(wd, "..", "mock-extension"),              // parent
+		filepath.Join(wd, "mock-extension"),                    // current dir
+	}
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("mock-extension binary not found; set %s environment variable", EnvMockExtensionBinary)
+}
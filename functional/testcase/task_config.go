@@ -1,6 +1,7 @@
 package testcase
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"strings"
 
@@ -75,6 +76,24 @@ func (tc *TaskConfig) AddLabel(key, value string) *TaskConfig {
 	return tc
 }
 
+// Skip marks the task to be skipped with a reason
+func (tc *TaskConfig) Skip(reason string) *TaskConfig {
+	tc.metadata.Skip = reason
+	return tc
+}
+
+// Priority sets the task priority
+func (tc *TaskConfig) Priority(priority string) *TaskConfig {
+	tc.metadata.Priority = priority
+	return tc
+}
+
+// ExpectedFailure marks the task as expected to fail
+func (tc *TaskConfig) ExpectedFailure() *TaskConfig {
+	tc.metadata.ExpectedFailure = true
+	return tc
+}
+
 // Prompt sets the prompt text for the agent.
 // The prompt is shell-escaped for single quotes since the agent spec template
 // uses single quotes around the prompt argument.
@@ -181,10 +200,24 @@ func (tc *TaskConfig) Steps() *task.TaskStepsV1Alpha1 {
 // the new step-based format with typed step arrays.
 type TaskConfigV2 struct {
 	metadata task.TaskMetadata
+	requires []task.Requirements
 	setup    []steps.StepConfig
 	cleanup  []steps.StepConfig
 	verify   []steps.StepConfig
 	prompt   *util.Step
+
+	// datasetFile and datasetContent are written next to the task file
+	datasetFile    string
+	datasetContent string
+
+	// promptFiles are attached to the prompt, and written next to the task
+	// file with their content
+	promptFiles        []string
+	promptFileContents []string
+
+	promptImages []task.PromptImage
+
+	preconditions []task.Precondition
 }
 
 // NewTaskConfigV2 creates a new task config builder using the new step-based format
@@ -272,6 +305,13 @@ func (tc *TaskConfigV2) AddSetupHTTP(method, url string) *TaskConfigV2 {
 	return tc
 }
 
+// AddSetupWait adds a wait step to the setup phase
+func (tc *TaskConfigV2) AddSetupWait(cfg steps.WaitStepConfig) *TaskConfigV2 {
+	raw, _ := json.Marshal(cfg)
+	tc.setup = append(tc.setup, steps.StepConfig{"wait": raw})
+	return tc
+}
+
 // AddCleanupScript adds an inline script step to the cleanup phase
 func (tc *TaskConfigV2) AddCleanupScript(script string) *TaskConfigV2 {
 	tc.cleanup = append(tc.cleanup, makeScriptStep(script, ""))
@@ -320,6 +360,77 @@ func (tc *TaskConfigV2) AddVerifyHTTP(method, url string) *TaskConfigV2 {
 	return tc
 }
 
+// AddVerifyExtract adds an extract step to the verify phase
+func (tc *TaskConfigV2) AddVerifyExtract(cfg steps.ExtractStepConfig) *TaskConfigV2 {
+	raw, _ := json.Marshal(cfg)
+	tc.verify = append(tc.verify, steps.StepConfig{"extract": raw})
+	return tc
+}
+
+// Dataset runs the task once per row of a dataset file with the given name
+// and content, which is written next to the task file
+func (tc *TaskConfigV2) Dataset(filename, content string) *TaskConfigV2 {
+	tc.datasetFile = filename
+	tc.datasetContent = content
+	return tc
+}
+
+// AttachFile attaches a file with the given path and content to the prompt.
+// The file is written next to the task file.
+func (tc *TaskConfigV2) AttachFile(path, content string) *TaskConfigV2 {
+	tc.promptFiles = append(tc.promptFiles, path)
+	tc.promptFileContents = append(tc.promptFileContents, content)
+	return tc
+}
+
+// AttachImage attaches an image with the given data and media type to the
+// prompt
+func (tc *TaskConfigV2) AttachImage(data []byte, mediaType string) *TaskConfigV2 {
+	tc.promptImages = append(tc.promptImages, task.PromptImage{
+		Data:      base64.StdEncoding.EncodeToString(data),
+		MediaType: mediaType,
+	})
+	return tc
+}
+
+// RequireExtension declares that the task uses an extension of the eval.
+// Its operations are available as steps named <extension>.<operation>.
+func (tc *TaskConfigV2) RequireExtension(name string) *TaskConfigV2 {
+	tc.requires = append(tc.requires, task.Requirements{Extension: &name})
+	return tc
+}
+
+// RequireServers declares the MCP servers the task needs, so that only those
+// are started for it
+func (tc *TaskConfigV2) RequireServers(names ...string) *TaskConfigV2 {
+	tc.requires = append(tc.requires, task.Requirements{Servers: names})
+	return tc
+}
+
+// AddPrecondition adds a check of the environment made before setup
+func (tc *TaskConfigV2) AddPrecondition(precondition task.Precondition) *TaskConfigV2 {
+	tc.preconditions = append(tc.preconditions, precondition)
+	return tc
+}
+
+// AddSetupExtension adds an extension operation step to the setup phase
+func (tc *TaskConfigV2) AddSetupExtension(extension, operation string, args map[string]any) *TaskConfigV2 {
+	tc.setup = append(tc.setup, makeExtensionStep(extension, operation, args))
+	return tc
+}
+
+// AddVerifyExtension adds an extension operation step to the verify phase
+func (tc *TaskConfigV2) AddVerifyExtension(extension, operation string, args map[string]any) *TaskConfigV2 {
+	tc.verify = append(tc.verify, makeExtensionStep(extension, operation, args))
+	return tc
+}
+
+// AddCleanupExtension adds an extension operation step to the cleanup phase
+func (tc *TaskConfigV2) AddCleanupExtension(extension, operation string, args map[string]any) *TaskConfigV2 {
+	tc.cleanup = append(tc.cleanup, makeExtensionStep(extension, operation, args))
+	return tc
+}
+
 // Metadata returns the task metadata
 func (tc *TaskConfigV2) Metadata() task.TaskMetadata {
 	return tc.metadata
@@ -347,12 +458,21 @@ func (tc *TaskConfigV2) PromptStep() *util.Step {
 
 // Build returns the task spec
 func (tc *TaskConfigV2) Build() *task.TaskSpec {
-	return &task.TaskSpec{
-		Setup:   tc.setup,
-		Cleanup: tc.cleanup,
-		Verify:  tc.verify,
-		Prompt:  tc.prompt,
+	spec := &task.TaskSpec{
+		Requires: tc.requires,
+		Setup:    tc.setup,
+		Cleanup:  tc.cleanup,
+		Verify:   tc.verify,
+
+		Preconditions: tc.preconditions,
+	}
+	if tc.prompt != nil {
+		spec.Prompt = &task.Prompt{Step: *tc.prompt, Files: tc.promptFiles, Images: tc.promptImages}
+	}
+	if tc.datasetFile != "" {
+		spec.Dataset = &task.Dataset{File: tc.datasetFile}
 	}
+	return spec
 }
 
 // --- Helper functions to create step configs ---
@@ -390,6 +510,14 @@ func makeHTTPStep(method, url string) steps.StepConfig {
 	return steps.StepConfig{"http": raw}
 }
 
+func makeExtensionStep(extension, operation string, args map[string]any) steps.StepConfig {
+	if args == nil {
+		args = map[string]any{}
+	}
+	raw, _ := json.Marshal(args)
+	return steps.StepConfig{extension + "." + operation: raw}
+}
+
 // Re-export types for convenience
 type (
 	TaskMetadata       = task.TaskMetadata
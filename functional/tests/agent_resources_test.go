@@ -0,0 +1,76 @@
+//go:build functional
+
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/procmon"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// agentResourcesTestCase returns a test case whose agent lists the pods, with
+// the agent resources limited by cfg
+func agentResourcesTestCase(t *testing.T, name string, cfg *procmon.Config) *testcase.TestCase {
+	return testcase.New(t, name).
+		WithMCPServer("kubernetes", func(s *testcase.MCPServerBuilder) {
+			s.Tool("pods_list", func(tool *testcase.ToolDef) {
+				tool.WithDescription("List pods").ReturnsText("nginx-web-7f9c Running")
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().
+				CallTool("pods_list", map[string]any{}).
+				ThenRespond("nginx-web-7f9c is running")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("list-pods").Prompt("List the running pods").VerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name(name).AgentResources(cfg)
+		})
+}
+
+// TestAgentResourceUsageRecorded verifies that the memory of the agent
+// process is recorded in the results
+func TestAgentResourceUsageRecorded(t *testing.T) {
+	agentResourcesTestCase(t, "agent-resources-recorded", nil).
+		ExpectTaskPassed().
+		Expect(testcase.AssertFunc("resource usage is recorded", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.ResourceUsage == nil {
+				t.Fatalf("expected the resource usage of the agent, got %+v", result)
+			}
+			if usage := result.ResourceUsage; usage.PeakMemoryBytes <= 0 || usage.LimitExceeded != "" {
+				t.Errorf("unexpected resource usage %+v", usage)
+			}
+		})).
+		Run()
+}
+
+// TestAgentKilledOverMemoryLimit verifies that an agent over the memory limit
+// is killed and fails the task
+func TestAgentKilledOverMemoryLimit(t *testing.T) {
+	agentResourcesTestCase(t, "agent-resources-killed", &procmon.Config{
+		MaxMemoryMB: 1,
+		// The mock agent exits quickly, so it is sampled often to be caught
+		SampleInterval: util.Duration(time.Millisecond),
+	}).
+		ExpectTaskFailedWithError("agent was killed for exceeding the memory limit of 1 MB").
+		Expect(testcase.AssertFunc("limit is recorded", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.ResourceUsage == nil {
+				t.Fatalf("expected the resource usage of the agent, got %+v", result)
+			}
+			if !strings.HasPrefix(result.ResourceUsage.LimitExceeded, "the memory limit of 1 MB") {
+				t.Errorf("unexpected limit %q", result.ResourceUsage.LimitExceeded)
+			}
+			if !result.AgentExecutionError {
+				t.Errorf("expected an agent execution error")
+			}
+		})).
+		Run()
+}
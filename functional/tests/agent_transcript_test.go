@@ -0,0 +1,127 @@
+//go:build functional
+
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestStreamedTranscriptTimeline verifies that a streamed event transcript is
+// kept as the task output and condensed into the timeline by `mcpchecker view`.
+func TestStreamedTranscriptTimeline(t *testing.T) {
+	testcase.New(t, "streamed-transcript").
+		WithMCPServer("kubernetes", func(s *testcase.MCPServerBuilder) {
+			s.Tool("pods_list", func(tool *testcase.ToolDef) {
+				tool.WithDescription("List pods").ReturnsText("nginx Running")
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("pods").
+				StreamEvents().
+				Think("I need to list the pods").
+				CallTool("pods_list", map[string]any{}).
+				RunCommand("kubectl logs nginx", "listening on :80", 0).
+				RunCommand("kubectl logs web", "pod not found", 1).
+				ThenRespond("The nginx pod is running")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("list-pods").
+				Prompt("Which pods are running?").
+				VerifyContains("nginx pod is running")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("transcript-eval")
+		}).
+		ExpectTaskPassed().
+		ExpectToolCalled("kubernetes", "pods_list").
+		Expect(testcase.AssertFunc("task output is an event stream", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil {
+				t.Fatalf("no eval result found")
+			}
+
+			// The debug log of the agent is mixed into the output
+			var types []string
+			for _, line := range strings.Split(strings.TrimSpace(result.TaskOutput), "\n") {
+				if !strings.HasPrefix(line, "{") {
+					continue
+				}
+				var evt struct {
+					Type string `json:"type"`
+				}
+				if err := json.Unmarshal([]byte(line), &evt); err != nil {
+					t.Fatalf("task output line %q is not an event: %v", line, err)
+				}
+				types = append(types, evt.Type)
+			}
+			if len(types) == 0 || types[0] != "thread.started" || types[len(types)-1] != "turn.completed" {
+				t.Errorf("events = %v, want thread.started ... turn.completed", types)
+			}
+		})).
+		ExpectTimelineContains(
+			"thought: I need to list the pods",
+			"tool: kubernetes::pods_list (completed)",
+			"command: kubectl logs nginx (completed) exit=0",
+			"command: kubectl logs web (failed) exit=1",
+			"assistant: The nginx pod is running",
+		).
+		Run()
+}
+
+// TestMultiStepBehaviorDelays verifies that delays between the steps of a
+// behavior are waited and show up in the agent timing.
+func TestMultiStepBehaviorDelays(t *testing.T) {
+	const delay = 300 * time.Millisecond
+
+	testcase.New(t, "multi-step-delays").
+		WithMCPServer("kubernetes", func(s *testcase.MCPServerBuilder) {
+			s.Tool("pods_list", func(tool *testcase.ToolDef) {
+				tool.WithDescription("List pods").ReturnsText("nginx Running")
+			}).
+				Tool("pods_logs", func(tool *testcase.ToolDef) {
+					tool.WithDescription("Get the logs of a pod").ReturnsText("listening on :80")
+				})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("nginx").
+				CallTool("pods_list", map[string]any{}).
+				Wait(delay).
+				Say("Found the nginx pod, checking its logs").
+				CallTool("pods_logs", map[string]any{"name": "nginx"}).
+				Wait(delay).
+				ThenRespond("The nginx pod is healthy")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("check-nginx").
+				Prompt("Is the nginx pod healthy?").
+				VerifyContains("checking its logs")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("delays-eval")
+		}).
+		ExpectTaskPassed().
+		Expect(testcase.AssertFunc("delays are waited", func(t *testing.T, ctx *testcase.RunContext) {
+			server := ctx.MCPServers["kubernetes"]
+			list, logs := server.CallsForTool("pods_list"), server.CallsForTool("pods_logs")
+			if len(list) != 1 || len(logs) != 1 {
+				t.Fatalf("expected one call of each tool, got %d and %d", len(list), len(logs))
+			}
+			if gap := logs[0].Timestamp.Sub(list[0].Timestamp); gap < delay {
+				t.Errorf("tool calls were %v apart, want at least %v", gap, delay)
+			}
+
+			result := ctx.FirstResult()
+			if result == nil || result.Timing == nil {
+				t.Fatalf("no timing for task")
+			}
+			if agent := time.Duration(result.Timing.Agent); agent < 2*delay {
+				t.Errorf("agent took %v, want at least %v", agent, 2*delay)
+			}
+		})).
+		Run()
+}
@@ -0,0 +1,66 @@
+//go:build functional
+
+package tests
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+)
+
+// TestBundleIsSignedAndVerifiable verifies that --bundle writes the results
+// with the digests of the eval and task files, signed with --bundle-key
+func TestBundleIsSignedAndVerifiable(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testcase.New(t, "bundle").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("ok")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("check-app").Prompt("Check the app").VerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("bundle")
+		}).
+		WithExtraArgs("--bundle", "bundle.json", "--bundle-key", keyFile).
+		Expect(testcase.AssertFunc("bundle is signed and matches the files", func(t *testing.T, ctx *testcase.RunContext) {
+			dir := filepath.Dir(ctx.OutputFile)
+			bundle, err := results.LoadBundle(filepath.Join(dir, "bundle.json"))
+			if err != nil {
+				t.Fatalf("failed to load bundle: %v", err)
+			}
+			if _, err := bundle.Verify(pub); err != nil {
+				t.Fatalf("bundle does not verify: %v", err)
+			}
+			if len(bundle.Manifest.Tasks) != 1 || bundle.Manifest.Tasks[0].Name != "check-app" {
+				t.Errorf("tasks = %v, want check-app", bundle.Manifest.Tasks)
+			}
+			if len(bundle.Manifest.Files) == 0 {
+				t.Errorf("bundle has no config files")
+			}
+			if mismatches := bundle.CheckFiles(dir); len(mismatches) != 0 {
+				t.Errorf("files do not match the bundle: %v", mismatches)
+			}
+		})).
+		Run()
+}
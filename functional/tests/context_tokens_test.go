@@ -0,0 +1,82 @@
+//go:build functional
+
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// contextTokensTestCase returns a test case whose agent lists the pods twice,
+// with the maxContextTokens assertion set to maxTokens
+func contextTokensTestCase(t *testing.T, name string, maxTokens int) *testcase.TestCase {
+	return testcase.New(t, name).
+		WithMCPServer("kubernetes", func(s *testcase.MCPServerBuilder) {
+			s.Tool("pods_list", func(tool *testcase.ToolDef) {
+				tool.WithDescription("List pods").ReturnsText(strings.Repeat("nginx-web-7f9c Running\n", 100))
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().
+				CallTool("pods_list", map[string]any{}).
+				CallTool("pods_list", map[string]any{}).
+				ThenRespond("nginx-web-7f9c is running")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("list-pods").Prompt("List the running pods").VerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name(name).
+				TaskSet(func(ts *testcase.TaskSetBuilder) {
+					ts.Glob("task-*.yaml").Assertions(func(a *testcase.AssertionsBuilder) {
+						a.MaxContextTokens(maxTokens)
+					})
+				})
+		})
+}
+
+// TestMaxContextTokensPasses verifies that the tokens of the tool results are
+// estimated and summed per tool, and that a large enough limit passes
+func TestMaxContextTokensPasses(t *testing.T) {
+	contextTokensTestCase(t, "max-context-tokens-passes", 100000).
+		ExpectTaskPassed().
+		ExpectAllAssertionsPassed().
+		Expect(testcase.AssertFunc("context usage is estimated", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.ContextUsage == nil {
+				t.Fatalf("expected the context usage to be estimated, got %+v", result)
+			}
+
+			usage := result.ContextUsage
+			if len(usage.Growth) != 2 || usage.TotalTokens != usage.Growth[1] || usage.Growth[1] != 2*usage.Growth[0] {
+				t.Errorf("expected two equal results, got %+v", usage)
+			}
+			if usage.LargestTool != "kubernetes/pods_list" {
+				t.Errorf("expected the largest result from kubernetes/pods_list, got %q", usage.LargestTool)
+			}
+			if len(result.ToolUsage) != 1 || result.ToolUsage[0].Tokens != usage.TotalTokens {
+				t.Errorf("expected the tool usage to count %d tokens, got %+v", usage.TotalTokens, result.ToolUsage)
+			}
+		})).
+		Run()
+}
+
+// TestMaxContextTokensFails verifies that tool results over the limit fail
+// the maxContextTokens assertion
+func TestMaxContextTokensFails(t *testing.T) {
+	contextTokensTestCase(t, "max-context-tokens-fails", 100).
+		ExpectTaskPassed().
+		ExpectAssertionsFailed().
+		Expect(testcase.AssertFunc("maxContextTokens failed", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.AssertionResults == nil || result.AssertionResults.MaxContextTokens == nil {
+				t.Fatalf("maxContextTokens was not evaluated")
+			}
+			if reason := result.AssertionResults.MaxContextTokens.Reason; !strings.Contains(reason, "expected <= 100") {
+				t.Errorf("unexpected reason %q", reason)
+			}
+		})).
+		Run()
+}
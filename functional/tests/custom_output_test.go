@@ -0,0 +1,51 @@
+//go:build functional
+
+package tests
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestColumnsOutput verifies that --columns prints a table of the tasks
+// instead of the text results
+func TestColumnsOutput(t *testing.T) {
+	sampleTestCase(t, "columns-output", "--run", "task-[01]$", "--columns", "name,status,difficulty").
+		Expect(testcase.AssertFunc("output is a table of the tasks", func(t *testing.T, ctx *testcase.RunContext) {
+			pattern := regexp.MustCompile(`NAME\s+STATUS\s+DIFFICULTY\ntask-0\s+passed\s+easy\s*\ntask-1\s+passed\s+easy\s*\n`)
+			if !pattern.MatchString(ctx.CommandOutput) {
+				t.Fatalf("expected command output to match %q, got:\n%s", pattern, ctx.CommandOutput)
+			}
+			if contains(ctx.CommandOutput, "Total Tasks") {
+				t.Fatalf("expected no text results, got:\n%s", ctx.CommandOutput)
+			}
+		})).
+		Run()
+}
+
+// TestGoTemplateOutput verifies that --output go-template prints the template
+// for each task
+func TestGoTemplateOutput(t *testing.T) {
+	sampleTestCase(t, "template-output", "--run", "task-[45]$", "--output", `go-template={{.TaskName}} is {{.Difficulty}}`).
+		Expect(testcase.AssertFunc("output is the template of each task", func(t *testing.T, ctx *testcase.RunContext) {
+			if !contains(ctx.CommandOutput, "task-4 is hard\ntask-5 is hard\n") {
+				t.Fatalf("expected command output to contain the template output, got:\n%s", ctx.CommandOutput)
+			}
+		})).
+		Run()
+}
+
+// TestInvalidGoTemplateOutput verifies that an invalid template is a
+// configuration error reported before any task runs
+func TestInvalidGoTemplateOutput(t *testing.T) {
+	sampleTestCase(t, "template-invalid", "--output", "go-template={{.TaskName").
+		ExpectExitCode(4).
+		Expect(testcase.AssertFunc("output reports the invalid template", func(t *testing.T, ctx *testcase.RunContext) {
+			if !contains(ctx.CommandOutput, "invalid template") {
+				t.Fatalf("expected command output to report the invalid template, got:\n%s", ctx.CommandOutput)
+			}
+		})).
+		Run()
+}
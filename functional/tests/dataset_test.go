@@ -0,0 +1,40 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+)
+
+// TestDatasetRunsTaskPerRow verifies that a task with a dataset runs once per
+// row, with the prompt and expected answer of the row
+func TestDatasetRunsTaskPerRow(t *testing.T) {
+	expected := "{{ .capital }}"
+
+	testcase.New(t, "dataset").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("France").ThenRespond("Answer: Paris")
+			a.OnPromptContaining("Japan").ThenRespond("Answer: Kyoto")
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("capital-{{ .id }}").
+				Easy().
+				Dataset("capitals.csv", "id,country,capital\nfr,France,Paris\njp,Japan,Tokyo\n").
+				Prompt("What is the capital of {{ .country }}?").
+				AddVerifyExtract(steps.ExtractStepConfig{
+					Regex:  `Answer:\s*(.+)`,
+					Expect: &steps.AnswerExpect{Equals: &expected},
+				})
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("dataset-eval")
+		}).
+		ExpectResultCount(2).
+		ExpectTaskPassedByName("capital-fr").
+		ExpectTaskFailedByName("capital-jp").
+		Run()
+}
@@ -0,0 +1,73 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestEnvPassedToScripts verifies that environment variables set on the test
+// case reach the scripts run by mcpchecker.
+func TestEnvPassedToScripts(t *testing.T) {
+	testcase.New(t, "env-passed-to-scripts").
+		WithEnv("E2E_CLUSTER", "staging").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("ok")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("check-cluster").
+				Prompt("Which cluster is this?").
+				VerifyScript(`test "$E2E_CLUSTER" = "staging"`)
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("env-eval")
+		}).
+		ExpectTaskPassed().
+		Run()
+}
+
+// TestExtraArgsFilterTasks verifies that extra arguments are passed to
+// `mcpchecker check`, using --run to select a task by name.
+func TestExtraArgsFilterTasks(t *testing.T) {
+	testcase.New(t, "extra-args-filter-tasks").
+		WithExtraArgs("--run", "^deploy").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("ok")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("deploy-app").Prompt("Deploy the app").VerifyScript("exit 0")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("delete-app").Prompt("Delete the app").VerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("filter-eval")
+		}).
+		ExpectResultCount(1).
+		ExpectTaskPassedByName("deploy-app").
+		Run()
+}
+
+// TestExtraArgsStrictExitCode verifies that a failing task makes the run exit
+// with code 1 when --strict is passed.
+func TestExtraArgsStrictExitCode(t *testing.T) {
+	testcase.New(t, "extra-args-strict").
+		WithExtraArgs("--strict").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("ok")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("failing-task").Prompt("Fail").VerifyScript("exit 1")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("strict-eval")
+		}).
+		ExpectTaskFailed().
+		ExpectExitCode(1).
+		Run()
+}
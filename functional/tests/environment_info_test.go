@@ -0,0 +1,54 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestExtensionEnvironmentInfoRecorded verifies that the environment info an
+// extension reports when it initializes is recorded with every task of the
+// run, including tasks that ran before the extension started
+func TestExtensionEnvironmentInfoRecorded(t *testing.T) {
+	testcase.New(t, "environment-info").
+		WithExtension("kube", func(e *testcase.MockExtensionBuilder) {
+			e.EnvironmentInfo(map[string]string{"clusterVersion": "v1.30.2", "distribution": "kind"}).
+				Operation("wait", func(op *testcase.ExtensionOperationDef) {
+					op.ReturnsSuccess("pod ready")
+				})
+		}).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(extensionAgent).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("without-extension").
+				Easy().
+				Prompt("Check the nginx pod").
+				AddVerifyScript("exit 0")
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("with-extension").
+				Easy().
+				RequireExtension("kube").
+				Prompt("Check the nginx pod").
+				AddVerifyExtension("kube", "wait", nil)
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("environment-info-eval")
+		}).
+		ExpectTaskPassedByName("without-extension").
+		ExpectTaskPassedByName("with-extension").
+		Expect(testcase.AssertFunc("environment recorded", func(t *testing.T, ctx *testcase.RunContext) {
+			for _, name := range []string{"without-extension", "with-extension"} {
+				result := ctx.ResultForTask(name)
+				if result == nil {
+					t.Fatalf("expected a result for %s", name)
+				}
+				if got := result.Environment["kube"]["clusterVersion"]; got != "v1.30.2" {
+					t.Errorf("environment of %s = %v, want kube.clusterVersion v1.30.2", name, result.Environment)
+				}
+			}
+		})).
+		Run()
+}
@@ -0,0 +1,165 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// kubernetesServer configures an MCP server with a tool the agent does not need
+func kubernetesServer(s *testcase.MCPServerBuilder) {
+	s.Tool("pods_get", func(tool *testcase.ToolDef) {
+		tool.WithDescription("Get a pod").ReturnsText("nginx Running")
+	})
+}
+
+// extensionAgent configures an agent that answers the pod prompts without calling tools
+func extensionAgent(a *testcase.AgentBuilder) {
+	a.OnPromptContaining("pod").ThenRespond("The nginx pod is running")
+}
+
+// TestExtensionStepsRunInEachPhase verifies that extension operations run as
+// setup, verify, and cleanup steps, and that verify steps get the agent output.
+func TestExtensionStepsRunInEachPhase(t *testing.T) {
+	testcase.New(t, "extension-steps").
+		WithExtension("kube", func(e *testcase.MockExtensionBuilder) {
+			e.Operation("create", func(op *testcase.ExtensionOperationDef) {
+				op.WithStringParam("name", "Name of the pod", true).
+					ReturnsOutputs("pod created", map[string]string{"uid": "1234"})
+			}).
+				Operation("wait", func(op *testcase.ExtensionOperationDef) {
+					op.ReturnsSuccess("pod ready")
+				}).
+				Operation("delete", func(op *testcase.ExtensionOperationDef) {
+					op.ReturnsSuccess("pod deleted")
+				})
+		}).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(extensionAgent).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("extension-phases").
+				Easy().
+				RequireExtension("kube").
+				AddSetupExtension("kube", "create", map[string]any{"name": "nginx"}).
+				Prompt("Check the nginx pod").
+				AddVerifyExtension("kube", "wait", nil).
+				AddCleanupExtension("kube", "delete", map[string]any{"name": "nginx"})
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("extension-eval")
+		}).
+		ExpectTaskPassed().
+		ExpectExtensionCalledTimes("kube", "create", 1).
+		ExpectExtensionCalled("kube", "wait").
+		ExpectExtensionCalled("kube", "delete").
+		Expect(testcase.AssertFunc("verify step gets agent output", func(t *testing.T, ctx *testcase.RunContext) {
+			for _, call := range ctx.ExtensionCalls["kube"] {
+				if call.Name == "create" && call.Args["name"] != "nginx" {
+					t.Errorf("create args = %v, want name nginx", call.Args)
+				}
+				if call.Name == "wait" && (call.Agent == nil || call.Agent.Output != "The nginx pod is running") {
+					t.Errorf("wait agent context = %+v, want the agent output", call.Agent)
+				}
+			}
+		})).
+		Run()
+}
+
+// TestExtensionVerifyFailureFailsTask verifies that a failed extension verify
+// step fails the task, and that responses are returned in sequence.
+func TestExtensionVerifyFailureFailsTask(t *testing.T) {
+	testcase.New(t, "extension-verify-failure").
+		WithExtension("kube", func(e *testcase.MockExtensionBuilder) {
+			e.Operation("wait", func(op *testcase.ExtensionOperationDef) {
+				op.ReturnsFailure("pod not ready").
+					ReturnsSuccess("pod ready")
+			})
+		}).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(extensionAgent).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("first-wait").
+				RequireExtension("kube").
+				Prompt("Check the nginx pod").
+				AddVerifyExtension("kube", "wait", nil)
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("second-wait").
+				RequireExtension("kube").
+				Prompt("Check the nginx pod again").
+				AddVerifyExtension("kube", "wait", nil)
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("extension-eval")
+		}).
+		ExpectTaskFailedByName("first-wait").
+		ExpectTaskPassedByName("second-wait").
+		ExpectExtensionCalledTimes("kube", "wait", 2).
+		Run()
+}
+
+// TestExtensionCrashFailsTask verifies that an extension exiting in the
+// middle of a step fails the task instead of hanging the run.
+func TestExtensionCrashFailsTask(t *testing.T) {
+	testcase.New(t, "extension-crash").
+		WithExtension("kube", func(e *testcase.MockExtensionBuilder) {
+			e.Operation("create", func(op *testcase.ExtensionOperationDef) {
+				op.Crashes()
+			})
+		}).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(extensionAgent).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("crashing-setup").
+				RequireExtension("kube").
+				AddSetupExtension("kube", "create", map[string]any{"name": "nginx"}).
+				Prompt("Check the nginx pod")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("extension-eval")
+		}).
+		ExpectTaskFailedWithError("extension exited before responding").
+		ExpectExtensionCalled("kube", "create").
+		Run()
+}
+
+// TestExtensionAssertion verifies that custom assertions named
+// <extension>.<assertion> are evaluated by the extension.
+func TestExtensionAssertion(t *testing.T) {
+	testcase.New(t, "extension-assertion").
+		WithExtension("kube", func(e *testcase.MockExtensionBuilder) {
+			e.Assertion("noDeletes", func(a *testcase.ExtensionAssertionDef) {
+				a.Fails("agent deleted a pod", "kubernetes/pods_delete")
+			})
+		}).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(extensionAgent).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("asserted").
+				Prompt("Check the nginx pod").
+				AddVerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			// The task files are written next to the eval file
+			eval.Name("extension-eval").
+				TaskSet(func(ts *testcase.TaskSetBuilder) {
+					ts.Glob("task-*.yaml").Assertions(func(a *testcase.AssertionsBuilder) {
+						a.Custom("kube.noDeletes", map[string]any{})
+					})
+				})
+		}).
+		ExpectExtensionAssertionCalled("kube", "noDeletes").
+		Expect(testcase.AssertFunc("assertion failed", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.ResultForTask("asserted")
+			if result == nil || result.AssertionResults == nil {
+				t.Fatalf("no assertion results for task")
+			}
+			custom := result.AssertionResults.Custom["kube.noDeletes"]
+			if custom == nil || custom.Passed || custom.Reason != "agent deleted a pod" {
+				t.Errorf("kube.noDeletes = %+v, want failed with the extension's reason", custom)
+			}
+		})).
+		Run()
+}
@@ -0,0 +1,66 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+)
+
+// extractTestCase returns a test case whose agent answers the pod count prompt
+// with response, graded by an extract step that expects 3 pods
+func extractTestCase(t *testing.T, name, response string) *testcase.TestCase {
+	expected := 3.0
+	return testcase.New(t, name).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("pods").ThenRespond(response)
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("count-pods").
+				Easy().
+				Prompt("How many pods are running? End with 'Answer: <number>'").
+				AddVerifyExtract(steps.ExtractStepConfig{
+					Regex:  `Answer:\s*(\S+)`,
+					Expect: &steps.AnswerExpect{Number: &expected},
+				})
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name(name)
+		})
+}
+
+// extractedAnswer returns an assertion that the extract step of the first
+// result stored the answer
+func extractedAnswer(expected string) testcase.Assertion {
+	return testcase.AssertFunc("extracted answer", func(t *testing.T, ctx *testcase.RunContext) {
+		result := ctx.FirstResult()
+		if result == nil || result.VerifyOutput == nil || len(result.VerifyOutput.Steps) == 0 {
+			t.Fatalf("no verify step output")
+		}
+		step := result.VerifyOutput.Steps[0]
+		if step.Outputs["answer"] != expected {
+			t.Errorf("answer = %q, want %q", step.Outputs["answer"], expected)
+		}
+	})
+}
+
+// TestExtractStepPassesCorrectAnswer verifies that an extracted answer that
+// matches the ground truth passes the task
+func TestExtractStepPassesCorrectAnswer(t *testing.T) {
+	extractTestCase(t, "extract-correct", "There are three pods running.\nAnswer: 3").
+		ExpectTaskPassed().
+		Expect(extractedAnswer("3")).
+		Run()
+}
+
+// TestExtractStepFailsWrongAnswer verifies that an extracted answer that
+// differs from the ground truth fails the task, and is still recorded
+func TestExtractStepFailsWrongAnswer(t *testing.T) {
+	extractTestCase(t, "extract-wrong", "There are four pods running.\nAnswer: 4").
+		ExpectTaskFailed().
+		Expect(extractedAnswer("4")).
+		Run()
+}
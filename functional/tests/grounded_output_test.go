@@ -0,0 +1,76 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// groundedOutputTestCase returns a test case whose agent lists the pods and
+// answers with response, with the groundedOutput assertion
+func groundedOutputTestCase(t *testing.T, name, response string) *testcase.TestCase {
+	return testcase.New(t, name).
+		WithMCPServer("kubernetes", func(s *testcase.MCPServerBuilder) {
+			s.Tool("pods_list", func(tool *testcase.ToolDef) {
+				tool.WithDescription("List pods").ReturnsText("nginx-web-7f9c Running")
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("pods").
+				CallTool("pods_list", map[string]any{}).
+				ThenRespond(response)
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("list-pods").
+				Easy().
+				Prompt("List the running pods").
+				VerifyContains("nginx-web-7f9c")
+		}).
+		WithJudge(func(j *testcase.JudgeBuilder) {
+			j.Always().Pass("The pod is listed")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name(name).
+				TaskSet(func(ts *testcase.TaskSetBuilder) {
+					ts.Glob("task-*.yaml").Assertions(func(a *testcase.AssertionsBuilder) {
+						a.GroundedOutput()
+					})
+				})
+		})
+}
+
+// TestGroundedOutputPasses verifies that an answer that only mentions pods
+// returned by the tool passes the groundedOutput assertion
+func TestGroundedOutputPasses(t *testing.T) {
+	groundedOutputTestCase(t, "grounded-output-passes", "The only running pod is nginx-web-7f9c.").
+		ExpectTaskPassed().
+		ExpectAllAssertionsPassed().
+		Expect(groundedOutputReason("")).
+		Run()
+}
+
+// TestGroundedOutputFlagsHallucination verifies that a pod name that no tool
+// returned fails the groundedOutput assertion
+func TestGroundedOutputFlagsHallucination(t *testing.T) {
+	groundedOutputTestCase(t, "grounded-output-hallucination", "The running pods are nginx-web-7f9c and postgres-db-0.").
+		ExpectTaskPassed().
+		ExpectAssertionsFailed().
+		Expect(groundedOutputReason("Ungrounded claim in output: postgres-db-0")).
+		Run()
+}
+
+// groundedOutputReason asserts that the groundedOutput assertion was evaluated
+// with the reason, which is empty if it passed
+func groundedOutputReason(reason string) testcase.Assertion {
+	return testcase.AssertFunc("groundedOutput reason", func(t *testing.T, ctx *testcase.RunContext) {
+		result := ctx.FirstResult()
+		if result == nil || result.AssertionResults == nil || result.AssertionResults.GroundedOutput == nil {
+			t.Fatalf("groundedOutput was not evaluated")
+		}
+		if got := result.AssertionResults.GroundedOutput.Reason; got != reason {
+			t.Errorf("groundedOutput reason = %q, want %q", got, reason)
+		}
+	})
+}
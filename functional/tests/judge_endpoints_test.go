@@ -0,0 +1,49 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestJudgeEndpointFailover verifies that a judge endpoint that is not
+// running is skipped, and the task is judged by the next endpoint
+func TestJudgeEndpointFailover(t *testing.T) {
+	judgePromptsTestCase(t, "judge-endpoint-failover").
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("judge-endpoint-failover").
+				LLMJudge(func(j *testcase.LLMJudgeConfigBuilder) {
+					j.Endpoint("local", "LOCAL_JUDGE_URL", "", "LOCAL_JUDGE_MODEL").
+						Endpoint("hosted", "E2E_OPENAI_BASE_URL", "E2E_OPENAI_API_KEY", "E2E_OPENAI_MODEL")
+				})
+		}).
+		WithEnv("LOCAL_JUDGE_URL", "http://127.0.0.1:1/v1").
+		WithEnv("LOCAL_JUDGE_MODEL", "local-model").
+		WithJudge(func(j *testcase.JudgeBuilder) {
+			j.Always().Pass("The pod name is mentioned")
+		}).
+		ExpectTaskPassed().
+		ExpectJudgeCalled().
+		Run()
+}
+
+// TestJudgeEnvAndEndpoints verifies that setting both env and endpoints is
+// reported as a configuration error before any task runs
+func TestJudgeEnvAndEndpoints(t *testing.T) {
+	judgePromptsTestCase(t, "judge-env-and-endpoints").
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("judge-env-and-endpoints").
+				LLMJudge(func(j *testcase.LLMJudgeConfigBuilder) {
+					j.UseDefaults().
+						Endpoint("hosted", "E2E_OPENAI_BASE_URL", "E2E_OPENAI_API_KEY", "E2E_OPENAI_MODEL")
+				})
+		}).
+		WithJudge(func(j *testcase.JudgeBuilder) {
+			j.Always().Pass("unused")
+		}).
+		ExpectExitCode(4).
+		ExpectJudgeNotCalled().
+		Run()
+}
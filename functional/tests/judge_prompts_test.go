@@ -0,0 +1,83 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// judgePromptsTestCase returns a test case whose agent creates a pod and
+// whose task is verified by the judge
+func judgePromptsTestCase(t *testing.T, name string) *testcase.TestCase {
+	return testcase.New(t, name).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("nginx").
+				CallTool("pods_get", map[string]any{"name": "nginx-web"}).
+				ThenRespond("I created the nginx pod named nginx-web.")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("create-nginx-pod").
+				Easy().
+				Prompt("Create an nginx pod named nginx-web").
+				VerifyContains("nginx-web")
+		})
+}
+
+// TestJudgeCustomSystemPrompt verifies that the system prompt template of the
+// eval config replaces the built-in prompt, with the expectation filled in
+func TestJudgeCustomSystemPrompt(t *testing.T) {
+	judgePromptsTestCase(t, "judge-custom-system-prompt").
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("judge-custom-system-prompt").
+				LLMJudge(func(j *testcase.LLMJudgeConfigBuilder) {
+					j.SystemPrompt("Lenient reviewer. The answer must mention {{ .ReferenceAnswer }} ({{ .EvaluationMode }}).")
+				})
+		}).
+		WithJudge(func(j *testcase.JudgeBuilder) {
+			j.WhenSystemPromptContains("Lenient reviewer. The answer must mention nginx-web (CONTAINS).").
+				Pass("The pod name is mentioned")
+		}).
+		ExpectTaskPassed().
+		ExpectJudgeCalled().
+		Run()
+}
+
+// TestJudgeCustomUserPrompt verifies that the user prompt template has access
+// to the task prompt and the agent output
+func TestJudgeCustomUserPrompt(t *testing.T) {
+	judgePromptsTestCase(t, "judge-custom-user-prompt").
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("judge-custom-user-prompt").
+				LLMJudge(func(j *testcase.LLMJudgeConfigBuilder) {
+					j.UserPrompt("Task: {{ .UserPrompt }} | Answer: {{ .ModelResponse }}")
+				})
+		}).
+		WithJudge(func(j *testcase.JudgeBuilder) {
+			j.WhenOutputMatches(`^Task: Create an nginx pod named nginx-web \| Answer: (?s).*I created the nginx pod named nginx-web\.`).
+				Pass("The answer matches the task")
+		}).
+		ExpectTaskPassed().
+		ExpectJudgeCalled().
+		Run()
+}
+
+// TestJudgeInvalidPromptTemplate verifies that a template with an unknown
+// field is reported as a configuration error before any task runs
+func TestJudgeInvalidPromptTemplate(t *testing.T) {
+	judgePromptsTestCase(t, "judge-invalid-prompt-template").
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("judge-invalid-prompt-template").
+				LLMJudge(func(j *testcase.LLMJudgeConfigBuilder) {
+					j.SystemPrompt("Expected: {{ .Expected }}")
+				})
+		}).
+		WithJudge(func(j *testcase.JudgeBuilder) {
+			j.Always().Pass("unused")
+		}).
+		ExpectExitCode(4).
+		ExpectJudgeNotCalled().
+		Run()
+}
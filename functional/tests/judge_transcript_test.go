@@ -0,0 +1,62 @@
+//go:build functional
+
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+)
+
+// TestJudgeTranscript verifies that the conversation of the judge is saved to
+// an artifact referenced from the result
+func TestJudgeTranscript(t *testing.T) {
+	judgePromptsTestCase(t, "judge-transcript").
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("judge-transcript")
+		}).
+		WithJudge(func(j *testcase.JudgeBuilder) {
+			j.Always().FailMissingInformation("The namespace is not mentioned")
+		}).
+		ExpectTaskFailed().
+		Expect(testcase.AssertFunc("judge transcript is saved", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.JudgeTranscriptFile == "" {
+				t.Fatalf("expected a judge transcript file in the result")
+			}
+
+			// The path is relative to the directory mcpchecker ran in
+			data, err := os.ReadFile(filepath.Join(filepath.Dir(ctx.OutputFile), result.JudgeTranscriptFile))
+			if err != nil {
+				t.Fatalf("failed to read judge transcript: %v", err)
+			}
+
+			var transcripts []llmjudge.Transcript
+			if err := json.Unmarshal(data, &transcripts); err != nil {
+				t.Fatalf("failed to parse judge transcript: %v", err)
+			}
+			if len(transcripts) != 1 {
+				t.Fatalf("expected 1 judge call, got %d", len(transcripts))
+			}
+
+			transcript := transcripts[0]
+			if !strings.Contains(transcript.UserPrompt, "I created the nginx pod named nginx-web.") {
+				t.Errorf("expected the agent output in the user prompt, got %q", transcript.UserPrompt)
+			}
+			if len(transcript.Response) == 0 {
+				t.Errorf("expected the raw response of the judge")
+			}
+			if transcript.ToolCall == nil || transcript.ToolCall.Name != "submit_judgement" {
+				t.Errorf("expected the submit_judgement tool call, got %+v", transcript.ToolCall)
+			}
+			if transcript.Verdict == nil || transcript.Verdict.Reason != "The namespace is not mentioned" {
+				t.Errorf("expected the verdict of the judge, got %+v", transcript.Verdict)
+			}
+		})).
+		Run()
+}
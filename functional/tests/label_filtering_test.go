@@ -247,3 +247,29 @@ func writeFile(t *testing.T, path, content string) {
 func contains(haystack, needle string) bool {
 	return strings.Contains(haystack, needle)
 }
+
+// TestLabelFiltering_LabelSelectorFlag ensures the --label-selector flag selects tasks like a TaskSet labelSelector.
+func TestLabelFiltering_LabelSelectorFlag(t *testing.T) {
+	tasksDir := writeLabelFilteringTasks(t)
+	glob := filepath.Join(tasksDir, "*.yaml")
+
+	testcase.New(t, "label-filtering-label-selector-flag").
+		WithExtraArgs("--label-selector", "suite=istio").
+		WithMCPServer("kubernetes", func(s *testcase.MCPServerBuilder) {
+			s.Tool("noop", func(tool *testcase.ToolDef) {
+				tool.WithDescription("No-op tool").ReturnsText("ok")
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("ok")
+		}).
+		WithEval(func(ec *testcase.EvalConfig) {
+			ec.Name("label-filtering-label-selector-flag").
+				TaskSet(func(ts *testcase.TaskSetBuilder) {
+					ts.Glob(glob)
+				})
+		}).
+		Expect(&testcase.TaskCountAssertion{Expected: 1}).
+		ExpectTaskPassedByName("istio-task").
+		Run()
+}
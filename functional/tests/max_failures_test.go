@@ -0,0 +1,77 @@
+//go:build functional
+
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// maxFailuresTestCase returns a test case with five tasks of which the
+// second, third, and fourth fail, run with the given arguments
+func maxFailuresTestCase(t *testing.T, name string, args ...string) *testcase.TestCase {
+	tc := testcase.New(t, name).
+		WithExtraArgs(args...).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("ok")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name(name)
+		})
+
+	for i, verify := range []string{"exit 0", "exit 1", "exit 1", "exit 1", "exit 0"} {
+		tc.AddTask(func(task *testcase.TaskConfig) {
+			task.Name(fmt.Sprintf("task-%d", i)).
+				Prompt("Check the app").
+				VerifyScript(verify)
+		})
+	}
+	return tc
+}
+
+// expectNotRun checks that the tasks were recorded as not run
+func expectNotRun(taskNames ...string) testcase.Assertion {
+	return testcase.AssertFunc("tasks not run", func(t *testing.T, ctx *testcase.RunContext) {
+		for _, name := range taskNames {
+			result := ctx.ResultForTask(name)
+			require.NotNil(t, result, "no result for task %s", name)
+			assert.Equal(t, eval.TaskStatusNotRun, result.Status(), "status of task %s", name)
+			assert.Contains(t, result.SkipReason, "the run stopped after")
+			assert.Nil(t, result.CallHistory, "task %s was run", name)
+		}
+	})
+}
+
+// TestFailFast verifies that --fail-fast stops the run after the first
+// failed task, and records the tasks left as not run
+func TestFailFast(t *testing.T) {
+	maxFailuresTestCase(t, "fail-fast", "--fail-fast").
+		ExpectResultsInOrder("task-0", "task-1", "task-2", "task-3", "task-4").
+		ExpectTaskPassedByName("task-0").
+		ExpectTaskFailedByName("task-1").
+		Expect(expectNotRun("task-2", "task-3", "task-4")).
+		Run()
+}
+
+// TestMaxFailures verifies that --max-failures stops the run after that many
+// failed tasks
+func TestMaxFailures(t *testing.T) {
+	maxFailuresTestCase(t, "max-failures", "--max-failures", "2").
+		ExpectTaskFailedByName("task-1").
+		ExpectTaskFailedByName("task-2").
+		Expect(expectNotRun("task-3", "task-4")).
+		Run()
+}
+
+// TestFailFastWithMaxFailures verifies that the flags cannot be combined
+func TestFailFastWithMaxFailures(t *testing.T) {
+	maxFailuresTestCase(t, "fail-fast-invalid", "--fail-fast", "--max-failures", "2").
+		ExpectExitCode(4).
+		Run()
+}
@@ -0,0 +1,147 @@
+//go:build functional
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/servers/openai"
+	openaisdk "github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// startOpenAIServer starts a mock OpenAI server with the given expectations
+// and returns a client for it
+func startOpenAIServer(t *testing.T, expectations ...*openai.Expectation) (*openai.MockOpenAIServer, openaisdk.Client) {
+	t.Helper()
+
+	server := openai.NewMockOpenAIServer()
+	for _, e := range expectations {
+		server.Expect(e)
+	}
+	url, err := server.Start()
+	if err != nil {
+		t.Fatalf("failed to start mock OpenAI server: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Stop() })
+
+	return server, openaisdk.NewClient(option.WithBaseURL(url), option.WithAPIKey("test"), option.WithMaxRetries(0))
+}
+
+// streamCompletion sends a streaming request and accumulates its chunks
+func streamCompletion(t *testing.T, client openaisdk.Client, params openaisdk.ChatCompletionNewParams) (openaisdk.ChatCompletionAccumulator, int) {
+	t.Helper()
+
+	stream := client.Chat.Completions.NewStreaming(context.Background(), params)
+	defer stream.Close()
+
+	var acc openaisdk.ChatCompletionAccumulator
+	chunks := 0
+	for stream.Next() {
+		if !acc.AddChunk(stream.Current()) {
+			t.Fatalf("chunk %d could not be accumulated", chunks)
+		}
+		chunks++
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+	return acc, chunks
+}
+
+// TestOpenAIStreamingText verifies that a streaming request gets the message
+// of the response in chunks, followed by the usage when it is asked for.
+func TestOpenAIStreamingText(t *testing.T) {
+	const content = "The nginx pod is running in the default namespace"
+
+	server, client := startOpenAIServer(t, &openai.Expectation{
+		Name:     "streamed answer",
+		Matcher:  openai.IsStreaming(),
+		Response: openai.TextResponse(content),
+	})
+
+	acc, chunks := streamCompletion(t, client, openaisdk.ChatCompletionNewParams{
+		Model:         openaisdk.ChatModelGPT4o,
+		Messages:      []openaisdk.ChatCompletionMessageParamUnion{openaisdk.UserMessage("Which pods are running?")},
+		StreamOptions: openaisdk.ChatCompletionStreamOptionsParam{IncludeUsage: openaisdk.Bool(true)},
+	})
+
+	if len(acc.Choices) != 1 {
+		t.Fatalf("expected 1 choice, got %d", len(acc.Choices))
+	}
+	if got := acc.Choices[0].Message.Content; got != content {
+		t.Errorf("content = %q, want %q", got, content)
+	}
+	if got := acc.Choices[0].FinishReason; got != "stop" {
+		t.Errorf("finish reason = %q, want %q", got, "stop")
+	}
+	if acc.Usage.TotalTokens != 150 {
+		t.Errorf("total tokens = %d, want 150", acc.Usage.TotalTokens)
+	}
+
+	// role, content in chunks of DefaultChunkSize, finish reason, usage
+	want := 1 + (len(content)+openai.DefaultChunkSize-1)/openai.DefaultChunkSize + 1 + 1
+	if chunks != want {
+		t.Errorf("got %d chunks, want %d", chunks, want)
+	}
+
+	if req := server.LastRequest(); req == nil || !req.Raw.Stream {
+		t.Errorf("expected the request to be captured as streaming")
+	}
+}
+
+// TestOpenAIStreamingToolCall verifies that the arguments of a streamed tool
+// call are reassembled by the client.
+func TestOpenAIStreamingToolCall(t *testing.T) {
+	response := openai.JudgePass("the answer matches")
+	response.ChunkSize = 5
+
+	_, client := startOpenAIServer(t, &openai.Expectation{
+		Name:     "streamed judgement",
+		Matcher:  openai.AnyRequest(),
+		Response: response,
+	})
+
+	acc, _ := streamCompletion(t, client, openaisdk.ChatCompletionNewParams{
+		Model:    openaisdk.ChatModelGPT4o,
+		Messages: []openaisdk.ChatCompletionMessageParamUnion{openaisdk.UserMessage("Judge the answer")},
+	})
+
+	if len(acc.Choices) != 1 || len(acc.Choices[0].Message.ToolCalls) != 1 {
+		t.Fatalf("expected 1 choice with 1 tool call, got %+v", acc.Choices)
+	}
+	call := acc.Choices[0].Message.ToolCalls[0]
+	want := response.Body.Choices[0].Message.ToolCalls[0]
+	if call.ID != want.ID || call.Function.Name != want.Function.Name {
+		t.Errorf("tool call = %s %s, want %s %s", call.ID, call.Function.Name, want.ID, want.Function.Name)
+	}
+	if call.Function.Arguments != want.Function.Arguments {
+		t.Errorf("arguments = %q, want %q", call.Function.Arguments, want.Function.Arguments)
+	}
+	if got := acc.Choices[0].FinishReason; got != "tool_calls" {
+		t.Errorf("finish reason = %q, want %q", got, "tool_calls")
+	}
+}
+
+// TestOpenAIStreamingError verifies that errors are returned as plain JSON
+// even when the request streams.
+func TestOpenAIStreamingError(t *testing.T) {
+	_, client := startOpenAIServer(t, &openai.Expectation{
+		Name:     "rate limited",
+		Matcher:  openai.IsStreaming(),
+		Response: openai.JudgeRateLimited("slow down"),
+	})
+
+	stream := client.Chat.Completions.NewStreaming(context.Background(), openaisdk.ChatCompletionNewParams{
+		Model:    openaisdk.ChatModelGPT4o,
+		Messages: []openaisdk.ChatCompletionMessageParamUnion{openaisdk.UserMessage("Judge the answer")},
+	})
+	defer stream.Close()
+
+	for stream.Next() {
+	}
+	if stream.Err() == nil {
+		t.Fatalf("expected the stream to fail")
+	}
+}
@@ -0,0 +1,61 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// outputExpectationsTestCase returns a test case whose agent answers with
+// response, with an output expectation against leaked tokens but no task set
+// assertions
+func outputExpectationsTestCase(t *testing.T, name, response string) *testcase.TestCase {
+	return testcase.New(t, name).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().
+				CallTool("pods_get", map[string]any{"name": "nginx"}).
+				ThenRespond(response)
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("check-pod").Prompt("Is nginx running?").VerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name(name).OutputExpectation("no-tokens", `token=\S+`)
+		})
+}
+
+// TestOutputExpectationsPass verifies that output expectations are evaluated
+// for tasks without assertions
+func TestOutputExpectationsPass(t *testing.T) {
+	outputExpectationsTestCase(t, "output-expectations-pass", "nginx is running").
+		ExpectTaskPassed().
+		ExpectAllAssertionsPassed().
+		Expect(outputExpectationPassed("no-tokens", true)).
+		Run()
+}
+
+// TestOutputExpectationsFail verifies that a task whose output breaks an
+// expectation fails its assertions
+func TestOutputExpectationsFail(t *testing.T) {
+	outputExpectationsTestCase(t, "output-expectations-fail", "nginx is running, connect with token=abc123").
+		ExpectTaskPassed().
+		ExpectAssertionsFailed().
+		Expect(outputExpectationPassed("no-tokens", false)).
+		Run()
+}
+
+// outputExpectationPassed asserts the result of the named output expectation
+func outputExpectationPassed(name string, passed bool) testcase.Assertion {
+	return testcase.AssertFunc("output expectation "+name, func(t *testing.T, ctx *testcase.RunContext) {
+		result := ctx.FirstResult()
+		if result == nil || result.AssertionResults == nil || result.AssertionResults.Expectations[name] == nil {
+			t.Fatalf("output expectation %s was not evaluated", name)
+		}
+		if got := result.AssertionResults.Expectations[name].Passed; got != passed {
+			t.Errorf("output expectation %s passed = %v, want %v", name, got, passed)
+		}
+	})
+}
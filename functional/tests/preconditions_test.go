@@ -0,0 +1,60 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// TestUnmetPreconditionSkipsTask verifies that a task whose precondition is
+// not met is skipped before setup, while the other tasks run
+func TestUnmetPreconditionSkipsTask(t *testing.T) {
+	testcase.New(t, "preconditions").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().
+				CallTool("pods_get", map[string]any{}).
+				ThenRespond("nginx is running")
+		}).
+		AddTaskV2(func(tc *testcase.TaskConfigV2) {
+			tc.Name("needs-cluster").
+				Easy().
+				AddPrecondition(task.Precondition{Command: "exit 0"}).
+				AddPrecondition(task.Precondition{KubeContext: "mcpchecker-missing-context"}).
+				Prompt("List the pods").
+				AddSetupScript("touch setup-ran").
+				AddVerifyScript("exit 0")
+		}).
+		AddTaskV2(func(tc *testcase.TaskConfigV2) {
+			tc.Name("met").
+				Easy().
+				AddPrecondition(task.Precondition{Command: "exit 0"}).
+				Prompt("List the pods").
+				AddVerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("preconditions-eval")
+		}).
+		ExpectToolCalledTimes("kubernetes", "pods_get", 1).
+		Expect(testcase.AssertFunc("statuses", func(t *testing.T, ctx *testcase.RunContext) {
+			skipped := ctx.ResultForTask("needs-cluster")
+			if skipped == nil || skipped.Status() != eval.TaskStatusSkipped {
+				t.Fatalf("expected needs-cluster to be skipped, got %+v", skipped)
+			}
+			want := `precondition not met: kube context "mcpchecker-missing-context" is not in the kubeconfig`
+			if skipped.SkipReason != want {
+				t.Errorf("skip reason = %q, want %q", skipped.SkipReason, want)
+			}
+			if skipped.SetupOutput != nil {
+				t.Errorf("expected setup not to run, got %+v", skipped.SetupOutput)
+			}
+			if met := ctx.ResultForTask("met"); met == nil || met.Status() != eval.TaskStatusPassed {
+				t.Errorf("expected met to pass, got %+v", met)
+			}
+		})).
+		Run()
+}
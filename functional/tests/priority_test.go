@@ -0,0 +1,43 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// TestPriorityOrder verifies that tasks run in order of priority, and that a
+// failing critical task stops a --fail-fast run before the other tasks
+func TestPriorityOrder(t *testing.T) {
+	tc := testcase.New(t, "priority-order").
+		WithExtraArgs("--fail-fast").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("ok")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("priority-order")
+		})
+
+	for _, spec := range []struct{ name, priority, verify string }{
+		{"low-task", task.PriorityLow, "exit 0"},
+		{"normal-task", "", "exit 0"},
+		{"critical-task", task.PriorityCritical, "exit 1"},
+		{"high-task", task.PriorityHigh, "exit 0"},
+	} {
+		tc.AddTask(func(task *testcase.TaskConfig) {
+			task.Name(spec.name).
+				Priority(spec.priority).
+				Prompt("Check the app").
+				VerifyScript(spec.verify)
+		})
+	}
+
+	tc.ExpectResultsInOrder("critical-task", "high-task", "normal-task", "low-task").
+		ExpectTaskFailedByName("critical-task").
+		Expect(expectNotRun("high-task", "normal-task", "low-task")).
+		Run()
+}
@@ -0,0 +1,66 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestPromptFilesAttached verifies that files attached to the prompt are
+// copied to the working directory and mentioned in the prompt
+func TestPromptFilesAttached(t *testing.T) {
+	testcase.New(t, "prompt-files").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("Attached files:").ThenRespond("web is failing")
+			a.OnAnyPrompt().ThenFail("no attached files")
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("summarize-report").
+				Easy().
+				Prompt("Summarize the failing rows of the report").
+				AttachFile("report.csv", "name,status\nweb,failed\n").
+				AddVerifyScript("grep -q web,failed report.csv")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("prompt-files-eval")
+		}).
+		ExpectTaskPassed().
+		Run()
+}
+
+// TestPromptImagesRecorded verifies that images attached to the prompt are
+// mentioned to the agent and recorded in the agent output
+func TestPromptImagesRecorded(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	testcase.New(t, "prompt-images").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("image-1.png").ThenRespond("the save button")
+			a.OnAnyPrompt().ThenFail("no attached image")
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("find-button").
+				Easy().
+				Prompt("Which button saves the form?").
+				AttachImage(png, "image/png").
+				AddVerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("prompt-images-eval")
+		}).
+		ExpectTaskPassed().
+		Expect(testcase.AssertFunc("images are recorded", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.AgentOutput == nil || len(result.AgentOutput.Steps) == 0 {
+				t.Fatalf("expected the agent output, got %+v", result)
+			}
+			if images := result.AgentOutput.Steps[0].Outputs["images"]; images != "image-1.png (image/png, 16 bytes)" {
+				t.Errorf("unexpected images %q", images)
+			}
+		})).
+		Run()
+}
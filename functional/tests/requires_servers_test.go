@@ -0,0 +1,69 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestRequiredServersOnly verifies that a task that requires some MCP servers
+// only gets those
+func TestRequiredServersOnly(t *testing.T) {
+	testcase.New(t, "requires-servers").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithMCPServer("github", func(s *testcase.MCPServerBuilder) {
+			s.Tool("issues_list", func(tool *testcase.ToolDef) {
+				tool.WithDescription("List issues").ReturnsText("no issues")
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			// Without a server, the call goes to the first server, which
+			// would be github if it was started
+			a.OnAnyPrompt().
+				CallTool("pods_get", map[string]any{}).
+				ThenRespond("nginx is running")
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("list-pods").
+				Easy().
+				RequireServers("kubernetes").
+				Prompt("List the pods").
+				AddVerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("requires-servers-eval")
+		}).
+		ExpectTaskPassed().
+		ExpectToolCalled("kubernetes", "pods_get").
+		Run()
+}
+
+// TestRequiredServerMissing verifies that a task that requires a server the
+// MCP config does not have fails before setup
+func TestRequiredServerMissing(t *testing.T) {
+	testcase.New(t, "requires-servers-missing").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("done")
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("list-issues").
+				Easy().
+				RequireServers("kubernetes", "github").
+				Prompt("List the issues").
+				AddSetupScript("touch setup-ran").
+				AddVerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("requires-servers-missing-eval")
+		}).
+		ExpectTaskFailedWithError("MCP config is missing required servers: github (it has: kubernetes)").
+		Expect(testcase.AssertFunc("setup did not run", func(t *testing.T, ctx *testcase.RunContext) {
+			if result := ctx.FirstResult(); result == nil || result.SetupOutput != nil {
+				t.Errorf("expected the task to fail before setup, got %+v", result)
+			}
+		})).
+		Run()
+}
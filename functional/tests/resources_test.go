@@ -0,0 +1,117 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// docsServer configures an MCP server with a resource, a resource template,
+// and a prompt
+func docsServer(s *testcase.MCPServerBuilder) {
+	s.Tool("search_docs", func(tool *testcase.ToolDef) {
+		tool.WithDescription("Search the docs").ReturnsText("runbook.md")
+	}).
+		Resource("docs://runbook.md", "Restart the pod with kubectl rollout restart").
+		ResourceTemplate("docs://pods/{namespace}/{name}", func(r *testcase.ResourceTemplateDef) {
+			r.WithDescription("Documentation of a pod").ReturnsText("nginx serves the website")
+		}).
+		AddPrompt(testcase.NewPrompt("troubleshoot").
+			WithDescription("Troubleshoot a pod").
+			WithArgument("pod", "Name of the pod", true).
+			WithUserMessage("Find out why the pod {pod} is failing"))
+}
+
+// TestResourcesAndPromptsAssertionsPass verifies that resource reads, resource
+// template reads, and prompt gets are forwarded by the proxy and recorded for
+// the resourcesRead, promptsUsed, and callOrder assertions.
+func TestResourcesAndPromptsAssertionsPass(t *testing.T) {
+	testcase.New(t, "resources-and-prompts").
+		WithMCPServer("docs", docsServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("nginx").
+				GetPrompt("troubleshoot", map[string]any{"pod": "nginx"}).
+				CallTool("search_docs", map[string]any{}).
+				ReadResource("docs://runbook.md").
+				ReadResource("docs://pods/default/nginx").
+				ThenRespond("Restart the nginx pod")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("troubleshoot-nginx").
+				Prompt("Why is the nginx pod failing?").
+				VerifyContains("Restart")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("resources-eval").
+				TaskSet(func(ts *testcase.TaskSetBuilder) {
+					ts.Glob("task-*.yaml").Assertions(func(a *testcase.AssertionsBuilder) {
+						a.RequireResource("docs", "docs://runbook.md").
+							RequireResourceTemplate("docs", "docs://pods/{namespace}/{name}", map[string]string{"namespace": "default"}).
+							RequirePromptWithArgs("docs", "troubleshoot", map[string]string{"pod": "nginx"}).
+							CallOrderPrompt("docs", "troubleshoot").
+							CallOrderTool("docs", "search_docs").
+							CallOrderResource("docs", "docs://runbook.md")
+					})
+				})
+		}).
+		ExpectTaskPassed().
+		ExpectAllAssertionsPassed().
+		ExpectPromptUsed("docs", "troubleshoot").
+		ExpectResourceRead("docs", "docs://runbook.md").
+		ExpectResourceRead("docs", "docs://pods/default/nginx").
+		Expect(testcase.AssertFunc("prompt rendered with arguments", func(t *testing.T, ctx *testcase.RunContext) {
+			gets := ctx.MCPServers["docs"].GetsForPrompt("troubleshoot")
+			if len(gets) != 1 {
+				t.Fatalf("expected 1 get of prompt troubleshoot, got %d", len(gets))
+			}
+			if got := gets[0].Arguments["pod"]; got != "nginx" {
+				t.Errorf("prompt argument pod = %q, want %q", got, "nginx")
+			}
+		})).
+		Run()
+}
+
+// TestResourceNotReadFailsAssertions verifies that the resourcesRead and
+// promptsUsed assertions fail when the agent does not read a required resource
+// or use a required prompt, even though the task itself passes.
+func TestResourceNotReadFailsAssertions(t *testing.T) {
+	testcase.New(t, "resource-not-read").
+		WithMCPServer("docs", docsServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("nginx").
+				ReadResource("docs://pods/default/nginx").
+				ThenRespond("Restart the nginx pod")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("troubleshoot-nginx").
+				Prompt("Why is the nginx pod failing?").
+				VerifyContains("Restart")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("resources-eval").
+				TaskSet(func(ts *testcase.TaskSetBuilder) {
+					ts.Glob("task-*.yaml").Assertions(func(a *testcase.AssertionsBuilder) {
+						a.RequireResource("docs", "docs://runbook.md").
+							RequirePrompt("docs", "troubleshoot")
+					})
+				})
+		}).
+		ExpectTaskPassed().
+		ExpectAssertionsFailed().
+		ExpectResourceRead("docs", "docs://pods/default/nginx").
+		Expect(testcase.AssertFunc("assertions failed", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.ResultForTask("troubleshoot-nginx")
+			if result == nil || result.AssertionResults == nil {
+				t.Fatalf("no assertion results for task")
+			}
+			if r := result.AssertionResults.ResourcesRead; r == nil || r.Passed {
+				t.Errorf("resourcesRead = %+v, want failed", r)
+			}
+			if r := result.AssertionResults.PromptsUsed; r == nil || r.Passed {
+				t.Errorf("promptsUsed = %+v, want failed", r)
+			}
+		})).
+		Run()
+}
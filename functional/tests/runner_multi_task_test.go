@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
 )
 
 // TestMultipleTasksAllPass verifies that multiple tasks can be run
@@ -432,3 +433,53 @@ func TestMixedDifficultyAndOutcomes(t *testing.T) {
 		})).
 		Run()
 }
+
+// TestSkippedAndExpectedFailureTasks verifies that skipped tasks are reported
+// without running, and that tasks expected to fail get their own status.
+func TestSkippedAndExpectedFailureTasks(t *testing.T) {
+	testcase.New(t, "skip-and-expected-failure").
+		WithMCPServer("server1", func(s *testcase.MCPServerBuilder) {
+			s.Tool("tool_a", func(tool *testcase.ToolDef) {
+				tool.WithDescription("Tool A").
+					WithStringParam("input", "Input value", true).
+					ReturnsText("Done")
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("task").
+				CallTool("tool_a", map[string]any{"input": "test"}).
+				ThenRespond("Done")
+		}).
+		WithTasks(
+			func(task *testcase.TaskConfig) {
+				task.Name("skipped").Easy().Skip("cluster unavailable").Prompt("Run skipped task").VerifyScript("exit 0")
+			},
+			func(task *testcase.TaskConfig) {
+				task.Name("expected-failure").Easy().ExpectedFailure().Prompt("Run failing task").VerifyScript("exit 1")
+			},
+			func(task *testcase.TaskConfig) {
+				task.Name("unexpected-pass").Easy().ExpectedFailure().Prompt("Run passing task").VerifyScript("exit 0")
+			},
+		).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("skip-eval")
+		}).
+		ExpectResultsInOrder("skipped", "expected-failure", "unexpected-pass").
+		ExpectToolCalledTimes("server1", "tool_a", 2).
+		Expect(testcase.AssertFunc("statuses", func(t *testing.T, ctx *testcase.RunContext) {
+			want := map[string]eval.TaskStatus{
+				"skipped":          eval.TaskStatusSkipped,
+				"expected-failure": eval.TaskStatusExpectedFailure,
+				"unexpected-pass":  eval.TaskStatusUnexpectedPass,
+			}
+			for name, status := range want {
+				if got := ctx.ResultForTask(name).Status(); got != status {
+					t.Errorf("status of %s = %s, want %s", name, got, status)
+				}
+			}
+			if reason := ctx.ResultForTask("skipped").SkipReason; reason != "cluster unavailable" {
+				t.Errorf("skip reason = %q, want %q", reason, "cluster unavailable")
+			}
+		})).
+		Run()
+}
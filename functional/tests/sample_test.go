@@ -0,0 +1,60 @@
+//go:build functional
+
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// sampleTestCase returns a test case with four easy tasks and two hard tasks,
+// run with the given arguments
+func sampleTestCase(t *testing.T, name string, args ...string) *testcase.TestCase {
+	tc := testcase.New(t, name).
+		WithExtraArgs(args...).
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("ok")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name(name)
+		})
+
+	for i, difficulty := range []string{"easy", "easy", "easy", "easy", "hard", "hard"} {
+		tc.AddTask(func(task *testcase.TaskConfig) {
+			task.Name(fmt.Sprintf("task-%d", i)).
+				Difficulty(difficulty).
+				Prompt("Check the app").
+				VerifyScript("exit 0")
+		})
+	}
+	return tc
+}
+
+// TestSampleStratifiedByDifficulty verifies that --sample runs a subset of the
+// tasks, with each difficulty in proportion
+func TestSampleStratifiedByDifficulty(t *testing.T) {
+	sampleTestCase(t, "sample-stratified", "--sample", "3", "--sample-seed", "5").
+		ExpectResultCount(3).
+		ExpectDifficultyCount("easy", 2).
+		ExpectDifficultyCount("hard", 1).
+		Run()
+}
+
+// TestSamplePercent verifies that --sample-percent rounds the number of tasks
+// up
+func TestSamplePercent(t *testing.T) {
+	sampleTestCase(t, "sample-percent", "--sample-percent", "10", "--sample-stratify", "none").
+		ExpectResultCount(1).
+		Run()
+}
+
+// TestSampleInvalidStratify verifies that an unknown stratification is a
+// configuration error
+func TestSampleInvalidStratify(t *testing.T) {
+	sampleTestCase(t, "sample-invalid", "--sample", "3", "--sample-stratify", "suite").
+		ExpectExitCode(4).
+		Run()
+}
@@ -0,0 +1,23 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+)
+
+// TestShardRunsEveryNthTask verifies that --shard deals the tasks to the
+// shards in turn
+func TestShardRunsEveryNthTask(t *testing.T) {
+	sampleTestCase(t, "shard", "--shard", "2/3").
+		ExpectResultsInOrder("task-1", "task-4").
+		Run()
+}
+
+// TestShardInvalid verifies that a shard outside of the shard count is a
+// configuration error
+func TestShardInvalid(t *testing.T) {
+	sampleTestCase(t, "shard-invalid", "--shard", "4/3").
+		ExpectExitCode(4).
+		Run()
+}
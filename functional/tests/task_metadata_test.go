@@ -0,0 +1,58 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestMcpTaskMetadataHeaders verifies that the headers of mcpTaskMetadata are
+// sent to the MCP server with each call, templated with the task and its trace
+// ID
+func TestMcpTaskMetadataHeaders(t *testing.T) {
+	tc := testcase.New(t, "task-metadata").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().
+				CallTool("pods_get", map[string]any{"name": "nginx"}).
+				ThenRespond("nginx is running")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("task-metadata").
+				McpTaskMetadata(map[string]string{
+					"X-Mcpchecker-Task": "{{ .Task }}",
+					"traceparent":       "00-{{ .TraceID }}-{{ .SpanID }}-01",
+				}, nil)
+		})
+
+	for _, name := range []string{"first-task", "second-task"} {
+		tc.AddTask(func(task *testcase.TaskConfig) {
+			task.Name(name).Prompt("Is nginx running?").VerifyScript("exit 0")
+		})
+	}
+
+	tc.ExpectResultsInOrder("first-task", "second-task").
+		Expect(testcase.AssertFunc("headers identify the task", func(t *testing.T, ctx *testcase.RunContext) {
+			calls := ctx.MCPServers["kubernetes"].Calls()
+			if len(calls) != 2 {
+				t.Fatalf("expected 2 calls, got %d", len(calls))
+			}
+
+			for i, result := range ctx.EvalResults {
+				header := calls[i].Header
+				if got := header.Get("X-Mcpchecker-Task"); got != result.TaskName {
+					t.Errorf("call %d: X-Mcpchecker-Task = %q, want %q", i, got, result.TaskName)
+				}
+				if len(result.TraceID) != 32 {
+					t.Errorf("task %s: traceId = %q, want 32 hex digits", result.TaskName, result.TraceID)
+				}
+				traceparent := header.Get("Traceparent")
+				if len(traceparent) != 55 || traceparent[3:35] != result.TraceID {
+					t.Errorf("call %d: traceparent = %q, want the trace ID %s", i, traceparent, result.TraceID)
+				}
+			}
+		})).
+		Run()
+}
@@ -0,0 +1,153 @@
+//go:build functional
+
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestToolResponseSequence verifies that a tool answers calls with its
+// responses in order, so that an agent retrying a failed call gets the error
+// first and the result second, and that the retry counts as a duplicate call.
+func TestToolResponseSequence(t *testing.T) {
+	testcase.New(t, "tool-response-sequence").
+		WithMCPServer("kubernetes", func(s *testcase.MCPServerBuilder) {
+			s.Tool("deploy", func(tool *testcase.ToolDef) {
+				tool.WithDescription("Deploy an application").
+					WithStringParam("name", "Name of the application", true).
+					ReturnsErrorText("server busy, try again").
+					Then().
+					ReturnsText("nginx deployed")
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("nginx").
+				CallTool("deploy", map[string]any{"name": "nginx"}).
+				CallTool("deploy", map[string]any{"name": "nginx"}).
+				CallTool("deploy", map[string]any{"name": "nginx"}).
+				ThenRespond("Deployed nginx after a retry")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("deploy-nginx").
+				Prompt("Deploy nginx").
+				VerifyContains("Deployed nginx")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("sequence-eval").
+				TaskSet(func(ts *testcase.TaskSetBuilder) {
+					ts.Glob("task-*.yaml").Assertions(func(a *testcase.AssertionsBuilder) {
+						a.NoDuplicateCalls()
+					})
+				})
+		}).
+		ExpectTaskPassed().
+		ExpectAssertionsFailed().
+		ExpectToolCalledTimes("kubernetes", "deploy", 3).
+		Expect(testcase.AssertFunc("responses in sequence", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.CallHistory == nil {
+				t.Fatalf("no call history for task")
+			}
+
+			calls := result.CallHistory.ToolCalls
+			if len(calls) != 3 {
+				t.Fatalf("expected 3 recorded tool calls, got %d", len(calls))
+			}
+			wantErrors := []bool{true, false, false}
+			for i, call := range calls {
+				if call.Result == nil || call.Result.IsError != wantErrors[i] {
+					t.Errorf("call %d: result = %+v, want isError=%v", i+1, call.Result, wantErrors[i])
+				}
+			}
+		})).
+		Run()
+}
+
+// TestToolProtocolError verifies that a tool failing with a protocol error is
+// recorded as an unsuccessful call.
+func TestToolProtocolError(t *testing.T) {
+	testcase.New(t, "tool-protocol-error").
+		WithMCPServer("kubernetes", func(s *testcase.MCPServerBuilder) {
+			s.Tool("pods_list", func(tool *testcase.ToolDef) {
+				tool.WithDescription("List pods").
+					ReturnsError(errors.New("connection reset by cluster"))
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("pods").
+				CallToolExpectingError("pods_list", map[string]any{}).
+				ThenRespond("Could not list the pods")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("list-pods").
+				Prompt("List the pods").
+				VerifyContains("Could not list")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("protocol-error-eval")
+		}).
+		ExpectTaskPassed().
+		ExpectToolCalled("kubernetes", "pods_list").
+		Expect(testcase.AssertFunc("call recorded as failed", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.CallHistory == nil || len(result.CallHistory.ToolCalls) != 1 {
+				t.Fatalf("expected one recorded tool call")
+			}
+
+			call := result.CallHistory.ToolCalls[0]
+			if call.Success || !strings.Contains(call.Error, "connection reset by cluster") {
+				t.Errorf("call = success %v, error %q, want failed with the tool's error", call.Success, call.Error)
+			}
+		})).
+		Run()
+}
+
+// TestToolLatency verifies that tool latency is waited and counts towards the
+// agent duration checked by the maxAgentDuration assertion.
+func TestToolLatency(t *testing.T) {
+	const latency = 500 * time.Millisecond
+
+	testcase.New(t, "tool-latency").
+		WithMCPServer("kubernetes", func(s *testcase.MCPServerBuilder) {
+			s.Tool("pods_list", func(tool *testcase.ToolDef) {
+				tool.WithDescription("List pods").
+					ReturnsText("nginx Running").
+					WithLatency(latency)
+			})
+		}).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnPromptContaining("pods").
+				CallTool("pods_list", map[string]any{}).
+				ThenRespond("The nginx pod is running")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("list-pods").
+				Prompt("List the pods").
+				VerifyContains("nginx")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("latency-eval").
+				TaskSet(func(ts *testcase.TaskSetBuilder) {
+					ts.Glob("task-*.yaml").Assertions(func(a *testcase.AssertionsBuilder) {
+						a.MaxAgentDuration(latency / 2)
+					})
+				})
+		}).
+		ExpectTaskPassed().
+		ExpectAssertionsFailed().
+		Expect(testcase.AssertFunc("latency is waited", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.Timing == nil {
+				t.Fatalf("no timing for task")
+			}
+			if agent := time.Duration(result.Timing.Agent); agent < latency {
+				t.Errorf("agent took %v, want at least %v", agent, latency)
+			}
+		})).
+		Run()
+}
@@ -0,0 +1,46 @@
+//go:build functional
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestToolUsage verifies that the results sum the calls and payload sizes of
+// each tool
+func TestToolUsage(t *testing.T) {
+	testcase.New(t, "tool-usage").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().
+				CallTool("pods_get", map[string]any{"name": "nginx"}).
+				CallTool("pods_get", map[string]any{"name": "redis"}).
+				ThenRespond("both pods are running")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("check-pods").Prompt("Are nginx and redis running?").VerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("tool-usage")
+		}).
+		Expect(testcase.AssertFunc("tool usage is summarized", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || len(result.ToolUsage) != 1 {
+				t.Fatalf("expected the usage of one tool, got %+v", result)
+			}
+
+			usage := result.ToolUsage[0]
+			if usage.Server != "kubernetes" || usage.Tool != "pods_get" || usage.Calls != 2 {
+				t.Errorf("expected 2 calls to kubernetes/pods_get, got %+v", usage)
+			}
+			if usage.RequestBytes == 0 || usage.ResponseBytes == 0 || usage.MaxResponseBytes == 0 {
+				t.Errorf("expected payload sizes, got %+v", usage)
+			}
+			if usage.MaxResponseBytes > usage.ResponseBytes {
+				t.Errorf("expected the largest response to be at most the total, got %+v", usage)
+			}
+		})).
+		Run()
+}
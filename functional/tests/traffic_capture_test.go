@@ -0,0 +1,120 @@
+//go:build functional
+
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+)
+
+// TestCaptureMcpTraffic verifies that --capture-mcp-traffic writes the HTTP
+// traffic to the MCP server of a task to a HAR file
+func TestCaptureMcpTraffic(t *testing.T) {
+	testcase.New(t, "capture-traffic").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().
+				CallTool("pods_get", map[string]any{"name": "nginx"}).
+				ThenRespond("nginx is running")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("check-pod").Prompt("Is nginx running?").VerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("capture-traffic")
+		}).
+		WithExtraArgs("--capture-mcp-traffic").
+		Expect(testcase.AssertFunc("traffic is saved as HAR", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.FirstResult()
+			if result == nil || result.TrafficFile == "" {
+				t.Fatalf("expected a traffic file in the result")
+			}
+
+			// The path is relative to the directory mcpchecker ran in
+			data, err := os.ReadFile(filepath.Join(filepath.Dir(ctx.OutputFile), result.TrafficFile))
+			if err != nil {
+				t.Fatalf("failed to read traffic file: %v", err)
+			}
+
+			var har struct {
+				Log struct {
+					Entries []struct {
+						Server  string `json:"_server"`
+						Request struct {
+							Method   string `json:"method"`
+							PostData struct {
+								Text string `json:"text"`
+							} `json:"postData"`
+						} `json:"request"`
+						Response struct {
+							Status int `json:"status"`
+						} `json:"response"`
+					} `json:"entries"`
+				} `json:"log"`
+			}
+			if err := json.Unmarshal(data, &har); err != nil {
+				t.Fatalf("traffic file is not valid HAR: %v", err)
+			}
+
+			calledTool := false
+			for _, entry := range har.Log.Entries {
+				if entry.Server != "kubernetes" {
+					t.Errorf("entry for server %q, want kubernetes", entry.Server)
+				}
+				if strings.Contains(entry.Request.PostData.Text, "pods_get") && entry.Response.Status == 200 {
+					calledTool = true
+				}
+			}
+			if !calledTool {
+				t.Errorf("tool call not found in %d captured entries", len(har.Log.Entries))
+			}
+		})).
+		Run()
+}
+
+// TestCaptureMcpTrafficSameTaskName verifies that tasks with the same name
+// write their traffic to separate files
+func TestCaptureMcpTrafficSameTaskName(t *testing.T) {
+	testcase.New(t, "capture-traffic-same-name").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().
+				CallTool("pods_get", map[string]any{"name": "nginx"}).
+				ThenRespond("nginx is running")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("check-pod").Prompt("Is nginx running?").VerifyScript("exit 0")
+		}).
+		AddTask(func(task *testcase.TaskConfig) {
+			task.Name("check-pod").Prompt("Is nginx still running?").VerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("capture-traffic-same-name")
+		}).
+		WithExtraArgs("--capture-mcp-traffic").
+		Expect(testcase.AssertFunc("each task has its own traffic file", func(t *testing.T, ctx *testcase.RunContext) {
+			if len(ctx.EvalResults) != 2 {
+				t.Fatalf("expected 2 results, got %d", len(ctx.EvalResults))
+			}
+
+			files := map[string]bool{}
+			for _, result := range ctx.EvalResults {
+				if result.TrafficFile == "" {
+					t.Fatalf("expected a traffic file in the result of %s", result.TaskName)
+				}
+				if _, err := os.Stat(filepath.Join(filepath.Dir(ctx.OutputFile), result.TrafficFile)); err != nil {
+					t.Errorf("traffic file of %s: %v", result.TaskName, err)
+				}
+				files[result.TrafficFile] = true
+			}
+			if len(files) != 2 {
+				t.Errorf("expected 2 traffic files, got %v", files)
+			}
+		})).
+		Run()
+}
@@ -0,0 +1,54 @@
+//go:build functional
+
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/functional/testcase"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+)
+
+// TestWaitStepPollsCommand verifies that a wait step in setup polls until a
+// background process is ready, and that one that never gets ready fails the
+// setup with a timeout error
+func TestWaitStepPollsCommand(t *testing.T) {
+	testcase.New(t, "wait-step").
+		WithMCPServer("kubernetes", kubernetesServer).
+		WithAgent(func(a *testcase.AgentBuilder) {
+			a.OnAnyPrompt().ThenRespond("done")
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("ready").
+				Easy().
+				AddSetupScript("rm -f wait-ready; (sleep 0.3; touch wait-ready) >/dev/null 2>&1 &").
+				AddSetupWait(steps.WaitStepConfig{Command: "test -f wait-ready", Interval: "50ms", Timeout: "10s"}).
+				Prompt("Is it ready?").
+				AddVerifyScript("exit 0").
+				AddCleanupScript("rm -f wait-ready")
+		}).
+		AddTaskV2(func(task *testcase.TaskConfigV2) {
+			task.Name("never-ready").
+				Easy().
+				AddSetupWait(steps.WaitStepConfig{Command: "echo Pending", Match: "Running", Interval: "50ms", Timeout: "300ms"}).
+				Prompt("Is it ready?").
+				AddVerifyScript("exit 0")
+		}).
+		WithEval(func(eval *testcase.EvalConfig) {
+			eval.Name("wait-step-eval")
+		}).
+		ExpectTaskPassedByName("ready").
+		ExpectTaskFailedByName("never-ready").
+		Expect(testcase.AssertFunc("timeout error", func(t *testing.T, ctx *testcase.RunContext) {
+			result := ctx.ResultForTask("never-ready")
+			if result == nil {
+				t.Fatal("expected a result for never-ready")
+			}
+			want := `setup[0] failed: timed out after 300ms waiting for command "echo Pending"`
+			if !strings.Contains(result.TaskError, want) || !strings.Contains(result.TaskError, `output did not match pattern "Running"`) {
+				t.Errorf("task error = %q, want it to contain %q", result.TaskError, want)
+			}
+		})).
+		Run()
+}
@@ -3,13 +3,17 @@ package acpclient
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"sync"
 
 	"github.com/coder/acp-go-sdk"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/procmon"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 type Client interface {
@@ -32,8 +36,11 @@ type client struct {
 	cfg      *AcpConfig
 	mu       sync.RWMutex
 	cmd      *exec.Cmd
+	watcher  *procmon.Watcher
 	conn     *acp.ClientSideConnection
 	sessions map[acp.SessionId]*session
+	// promptCaps are the content types the agent accepts in prompts
+	promptCaps acp.PromptCapabilities
 }
 
 func (c *client) Start(ctx context.Context) error {
@@ -52,6 +59,7 @@ func (c *client) Start(ctx context.Context) error {
 	if err := c.cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start acp client: %w", err)
 	}
+	c.watcher = procmon.Watch(ctx, c.cmd.Process.Pid)
 
 	c.conn = acp.NewClientSideConnection(c, stdin, stdout)
 
@@ -71,6 +79,7 @@ func (c *client) Start(ctx context.Context) error {
 		_ = c.cmd.Process.Kill()
 		return fmt.Errorf("invalid acp agent: mcpchecker requires acp agents support http mcp transport")
 	}
+	c.promptCaps = initResp.AgentCapabilities.PromptCapabilities
 
 	return nil
 }
@@ -80,14 +89,25 @@ func (c *client) Run(ctx context.Context, prompt string, servers mcpproxy.Server
 		return nil, fmt.Errorf("acpclient.Client.Run must be called after acpclient.Client.Start")
 	}
 
-	tmpDir, err := os.MkdirTemp("", "mcpchecker-agent-")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary directory for agent execution: %w", err)
+	images := util.PromptImagesFromContext(ctx)
+	if len(images) > 0 && !c.promptCaps.Image {
+		return nil, fmt.Errorf("acp agent does not support images in the prompt")
 	}
 
-	defer func() {
-		_ = os.RemoveAll(tmpDir)
-	}()
+	// Run the session in the task's working directory if it has one, so that
+	// the agent can access the task's files
+	tmpDir, ok := util.WorkdirFromContext(ctx)
+	if !ok {
+		dir, err := os.MkdirTemp("", "mcpchecker-agent-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory for agent execution: %w", err)
+		}
+		tmpDir = dir
+
+		defer func() {
+			_ = os.RemoveAll(tmpDir)
+		}()
+	}
 
 	mcpServers := make([]acp.McpServer, 0, len(servers.GetMcpServers()))
 	for _, srv := range servers.GetMcpServers() {
@@ -121,10 +141,23 @@ func (c *client) Run(ctx context.Context, prompt string, servers mcpproxy.Server
 
 	// this runs the current prompt to completion
 	// if we were to support multi turn flows, we could run further prompts to the same session from here
+	// Files attached to the prompt are linked, which every ACP agent supports
+	blocks := []acp.ContentBlock{acp.TextBlock(prompt)}
+	for _, file := range util.PromptFilesFromContext(ctx) {
+		blocks = append(blocks, acp.ResourceLinkBlock(filepath.Base(file), (&url.URL{Scheme: "file", Path: filepath.ToSlash(file)}).String()))
+	}
+	// Images are sent inline, which the agent was checked to support
+	for _, image := range images {
+		blocks = append(blocks, acp.ImageBlock(image.Base64(), image.MediaType))
+	}
+
 	if _, err := c.conn.Prompt(ctx, acp.PromptRequest{
 		SessionId: session.SessionId,
-		Prompt:    []acp.ContentBlock{acp.TextBlock(prompt)},
+		Prompt:    blocks,
 	}); err != nil {
+		if limitErr := c.watcher.Err(); limitErr != nil {
+			return nil, limitErr
+		}
 		return nil, fmt.Errorf("failed to send prompt to acp session: %w", err)
 	}
 
@@ -139,6 +172,8 @@ func (c *client) Run(ctx context.Context, prompt string, servers mcpproxy.Server
 }
 
 func (c *client) Close(ctx context.Context) error {
+	_ = c.watcher.Stop(nil)
+
 	if c.cmd == nil || (c.cmd.ProcessState != nil && c.cmd.ProcessState.Exited()) {
 		return nil
 	}
@@ -52,6 +52,12 @@ func (r *acpRunner) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) Runner
 	}
 }
 
+// allowedToolsVia returns how ACP agents are restricted to the allowed tools:
+// their permission requests are only granted for allowed tools
+func (r *acpRunner) allowedToolsVia() string {
+	return AllowedToolsViaPermissions
+}
+
 func (r *acpRunner) AgentName() string {
 	return r.name
 }
@@ -168,3 +168,6 @@ func (m *mockServerManager) Start(_ context.Context) error
 func (m *mockServerManager) Close() error                                                  { return nil }
 func (m *mockServerManager) GetAllCallHistory() *mcpproxy.CallHistory                      { return nil }
 func (m *mockServerManager) GetCallHistoryForServer(_ string) (mcpproxy.CallHistory, bool) { return mcpproxy.CallHistory{}, false }
+func (m *mockServerManager) ForTask(_ context.Context, _ string) (mcpproxy.ServerManager, error) {
+	return m, nil
+}
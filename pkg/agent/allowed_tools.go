@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+)
+
+// Ways the allowed tools are passed to the agent
+const (
+	// AllowedToolsViaArg renders them into runPrompt
+	AllowedToolsViaArg = "arg"
+	// AllowedToolsViaEnv sets them in EnvAllowedTools
+	AllowedToolsViaEnv = "env"
+	// AllowedToolsViaFile writes them to a JSON file whose path is in
+	// EnvAllowedToolsFile
+	AllowedToolsViaFile = "file"
+	// AllowedToolsViaPermissions grants the permission requests of ACP agents
+	// for allowed tools only
+	AllowedToolsViaPermissions = "permissions"
+)
+
+const (
+	// EnvAllowedTools holds the rendered argTemplateAllowedTools of all
+	// tools, joined by the separator, with allowedToolsVia: env
+	EnvAllowedTools = "MCPCHECKER_ALLOWED_TOOLS"
+	// EnvAllowedToolsFile holds the path of the allowed tools file, with
+	// allowedToolsVia: file
+	EnvAllowedToolsFile = "MCPCHECKER_ALLOWED_TOOLS_FILE"
+)
+
+// AllowedTools is the effective set of tools an agent was allowed to call
+type AllowedTools struct {
+	// Servers lists the allowed tools of each MCP server
+	Servers map[string][]string `json:"servers"`
+	// Restricted is true if a server does not allow all of its tools
+	Restricted bool `json:"restricted,omitempty"`
+	// Via is how the allowed tools were passed to the agent, or empty if the
+	// agent could not be restricted to them
+	Via string `json:"via,omitempty"`
+}
+
+// Enforced returns whether the agent was restricted to the allowed tools, or
+// did not need to be
+func (a *AllowedTools) Enforced() bool {
+	return !a.Restricted || a.Via != ""
+}
+
+// allowedToolsEnforcer is implemented by runners that can restrict their
+// agent to the allowed tools
+type allowedToolsEnforcer interface {
+	allowedToolsVia() string
+}
+
+// AllowedToolsVia returns how a runner passes the allowed tools to its agent,
+// or "" if the agent cannot be restricted to them
+func AllowedToolsVia(r Runner) string {
+	if e, ok := r.(allowedToolsEnforcer); ok {
+		return e.allowedToolsVia()
+	}
+	return ""
+}
+
+// allToolsAllower is implemented by servers that know whether they allow all
+// of their tools
+type allToolsAllower interface {
+	AllowsAllTools() bool
+}
+
+// EffectiveAllowedTools returns the tools the servers allow the agent of a
+// runner to call, and how the runner restricts the agent to them
+func EffectiveAllowedTools(r Runner, servers []mcpproxy.Server) *AllowedTools {
+	allowed := &AllowedTools{
+		Servers: make(map[string][]string, len(servers)),
+		Via:     AllowedToolsVia(r),
+	}
+	for _, s := range servers {
+		allowed.Servers[s.GetName()] = allowedToolNames(s)
+		if a, ok := s.(allToolsAllower); ok && !a.AllowsAllTools() {
+			allowed.Restricted = true
+		}
+	}
+	return allowed
+}
+
+// allowedToolNames returns the sorted names of the allowed tools of a server
+func allowedToolNames(s mcpproxy.Server) []string {
+	names := []string{}
+	for _, t := range s.GetAllowedTools() {
+		if t != nil {
+			names = append(names, t.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// writeAllowedToolsFile writes the allowed tools of each server as a JSON
+// object to a temporary file and returns its path
+func writeAllowedToolsFile(servers map[string][]string) (string, error) {
+	data, err := json.Marshal(servers)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode allowed tools: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "mcpchecker-allowed-tools-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to create allowed tools file: %w", err)
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write allowed tools file: %w", err)
+	}
+	return f.Name(), nil
+}
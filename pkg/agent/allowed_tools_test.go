@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restrictedServer is a mockServer that knows whether it allows all tools
+type restrictedServer struct {
+	mockServer
+	allowsAllTools bool
+}
+
+func (s *restrictedServer) GetConfig() (*mcpproxy.ServerConfig, error) {
+	return &mcpproxy.ServerConfig{}, nil
+}
+func (s *restrictedServer) AllowsAllTools() bool { return s.allowsAllTools }
+
+// serverFiles is McpServerInfo with a config file for each server
+type serverFiles struct {
+	servers []mcpproxy.Server
+}
+
+func (s serverFiles) GetMcpServerFiles() ([]string, error) {
+	files := make([]string, len(s.servers))
+	for i, server := range s.servers {
+		files[i] = server.GetName() + ".json"
+	}
+	return files, nil
+}
+func (s serverFiles) GetMcpServers() []mcpproxy.Server { return s.servers }
+
+func newRestrictedServer(name string, allowsAllTools bool, tools ...string) *restrictedServer {
+	s := &restrictedServer{mockServer: mockServer{name: name}, allowsAllTools: allowsAllTools}
+	for _, tool := range tools {
+		s.allowedTools = append(s.allowedTools, &mcp.Tool{Name: tool})
+	}
+	return s
+}
+
+func TestAllowedToolsVia(t *testing.T) {
+	commands := func(runPrompt, via string) Runner {
+		return &agentSpecRunner{AgentSpec: &AgentSpec{Commands: AgentCommands{
+			ArgTemplateMcpServer:    "{{ .File }}",
+			ArgTemplateAllowedTools: "{{ .ServerName }}__{{ .ToolName }}",
+			RunPrompt:               runPrompt,
+			AllowedToolsVia:         via,
+		}}}
+	}
+
+	tests := map[string]struct {
+		runner Runner
+		want   string
+	}{
+		"arg with allowed tool args": {
+			runner: commands("agent --allowed {{ .AllowedToolArgs }} {{ .McpServerFileArgs }} {{ .Prompt }}", ""),
+			want:   AllowedToolsViaArg,
+		},
+		"arg with allowed tools": {
+			runner: commands("agent {{ range .AllowedTools }}--allow {{ . }} {{ end }}{{ .McpServerFileArgs }} {{ .Prompt }}", AllowedToolsViaArg),
+			want:   AllowedToolsViaArg,
+		},
+		"arg without allowed tools": {
+			runner: commands("agent {{ .McpServerFileArgs }} {{ .Prompt }}", ""),
+			want:   "",
+		},
+		"env": {
+			runner: commands("agent {{ .McpServerFileArgs }} {{ .Prompt }}", AllowedToolsViaEnv),
+			want:   AllowedToolsViaEnv,
+		},
+		"file": {
+			runner: commands("agent {{ .McpServerFileArgs }} {{ .Prompt }}", AllowedToolsViaFile),
+			want:   AllowedToolsViaFile,
+		},
+		"acp": {
+			runner: &acpRunner{},
+			want:   AllowedToolsViaPermissions,
+		},
+		"api": {
+			runner: &apiAgentRunner{},
+			want:   "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, AllowedToolsVia(tc.runner))
+		})
+	}
+}
+
+func TestEffectiveAllowedTools(t *testing.T) {
+	servers := []mcpproxy.Server{
+		newRestrictedServer("k8s", false, "pods_list", "pods_get"),
+		newRestrictedServer("fs", true, "read_file"),
+	}
+
+	allowed := EffectiveAllowedTools(&apiAgentRunner{}, servers)
+	assert.Equal(t, map[string][]string{
+		"k8s": {"pods_get", "pods_list"},
+		"fs":  {"read_file"},
+	}, allowed.Servers)
+	assert.True(t, allowed.Restricted)
+	assert.Empty(t, allowed.Via)
+	assert.False(t, allowed.Enforced())
+
+	allowed = EffectiveAllowedTools(&acpRunner{}, servers)
+	assert.Equal(t, AllowedToolsViaPermissions, allowed.Via)
+	assert.True(t, allowed.Enforced())
+
+	allowed = EffectiveAllowedTools(&apiAgentRunner{}, servers[1:])
+	assert.False(t, allowed.Restricted)
+	assert.True(t, allowed.Enforced())
+}
+
+func TestRunCommandAllowedToolsVia(t *testing.T) {
+	info := serverFiles{servers: []mcpproxy.Server{
+		newRestrictedServer("k8s", false, "pods_list", "pods_get"),
+	}}
+	spec := func(runPrompt, via string) *AgentSpec {
+		return &AgentSpec{Commands: AgentCommands{
+			ArgTemplateMcpServer:    "{{ .File }}",
+			ArgTemplateAllowedTools: "{{ .ServerName }}__{{ .ToolName }}",
+			RunPrompt:               runPrompt,
+			AllowedToolsVia:         via,
+		}}
+	}
+
+	t.Run("env", func(t *testing.T) {
+		runner := &agentSpecRunner{
+			AgentSpec: spec(`printf %s "$MCPCHECKER_ALLOWED_TOOLS" # {{ .McpServerFileArgs }} {{ .Prompt }}`, AllowedToolsViaEnv),
+			mcpInfo:   info,
+		}
+
+		res, err := runner.runCommand(context.Background(), "prompt", t.TempDir(), "")
+		require.NoError(t, err)
+		assert.Equal(t, "k8s__pods_list k8s__pods_get", res.GetOutput())
+	})
+
+	t.Run("file", func(t *testing.T) {
+		dir := t.TempDir()
+		runner := &agentSpecRunner{
+			AgentSpec: spec(`cat "$MCPCHECKER_ALLOWED_TOOLS_FILE"; printf %s {{ quote .AllowedToolsFile }} > path.txt # {{ .McpServerFileArgs }} {{ .Prompt }}`, AllowedToolsViaFile),
+			mcpInfo:   info,
+		}
+
+		res, err := runner.runCommand(context.Background(), "prompt", dir, "")
+		require.NoError(t, err)
+
+		var servers map[string][]string
+		require.NoError(t, json.Unmarshal([]byte(res.GetOutput()), &servers))
+		assert.Equal(t, map[string][]string{"k8s": {"pods_list", "pods_get"}}, servers)
+
+		path, err := os.ReadFile(filepath.Join(dir, "path.txt"))
+		require.NoError(t, err)
+		assert.NoFileExists(t, string(path))
+	})
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+)
+
+type AnthropicAgent struct{}
+
+func (a *AnthropicAgent) Name() string {
+	return "anthropic-agent"
+}
+
+func (a *AnthropicAgent) Description() string {
+	return "Anthropic agent using direct Messages API calls"
+}
+
+func (a *AnthropicAgent) RequiresModel() bool {
+	return true
+}
+
+func (a *AnthropicAgent) ValidateEnvironment() error {
+	// No external binary required - we use the anthropicagent package directly
+	return nil
+}
+
+func (a *AnthropicAgent) GetDefaults(model string) (*AgentSpec, error) {
+	if model == "" {
+		return nil, fmt.Errorf("model is required for anthropic-agent")
+	}
+
+	// ANTHROPIC_BASE_URL is optional, the runner defaults to the Anthropic API
+	baseURL := os.Getenv("ANTHROPIC_BASE_URL")
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("environment variable ANTHROPIC_API_KEY must be set")
+	}
+
+	useVirtualHome := false
+	return &AgentSpec{
+		Metadata: AgentMetadata{
+			Name: fmt.Sprintf("anthropic-agent-%s", model),
+		},
+		// The runner will be created specially for API agents
+		Builtin: &BuiltinRef{
+			Type:    "anthropic-agent",
+			Model:   model,
+			BaseURL: baseURL,
+			APIKey:  apiKey,
+		},
+		Commands: AgentCommands{
+			UseVirtualHome:       &useVirtualHome,
+			ArgTemplateMcpServer: "{{ .URL }}",
+			// RunPrompt is not used for API agents - they use a custom runner
+			RunPrompt: "",
+		},
+	}, nil
+}
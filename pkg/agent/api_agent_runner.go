@@ -0,0 +1,222 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/anthropicagent"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/ollamaagent"
+	"github.com/mcpchecker/mcpchecker/pkg/openaiagent"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// apiAgent is an agent that calls a model API directly, with the MCP servers
+// of the eval as its tools
+type apiAgent interface {
+	AddMCPServer(ctx context.Context, serverURL string) error
+	SetOptions(options openaiagent.Options)
+	Run(ctx context.Context, prompt string) (string, error)
+	CheckHealth(ctx context.Context) error
+	Close() error
+}
+
+// imageAgent is an apiAgent that can send images with the prompt
+type imageAgent interface {
+	RunWithImages(ctx context.Context, prompt string, images []util.Image) (string, error)
+}
+
+// apiAgentRunner implements Runner for the builtin agents that call a model
+// API directly: openai-agent, anthropic-agent, and ollama-agent
+type apiAgentRunner struct {
+	agentType string
+	model     string
+	baseURL   string
+	apiKey    string
+	options   openaiagent.Options
+	mcpInfo   McpServerInfo
+}
+
+type apiAgentResult struct {
+	output string
+}
+
+func (r *apiAgentResult) GetOutput() string {
+	return r.output
+}
+
+// isAPIAgentType returns whether a builtin agent type is run by apiAgentRunner
+func isAPIAgentType(agentType string) bool {
+	switch agentType {
+	case "openai-agent", "anthropic-agent", "ollama-agent":
+		return true
+	}
+	return false
+}
+
+// NewOpenAIAgentRunner creates a runner that uses the openaiagent package directly
+func NewOpenAIAgentRunner(model, baseURL, apiKey string) (Runner, error) {
+	return NewOpenAIAgentRunnerWithOptions(model, baseURL, apiKey, openaiagent.Options{})
+}
+
+// NewOpenAIAgentRunnerWithOptions creates a runner that uses the openaiagent
+// package directly, with options for its tool-call loop
+func NewOpenAIAgentRunnerWithOptions(model, baseURL, apiKey string, options openaiagent.Options) (Runner, error) {
+	if model == "" || baseURL == "" || apiKey == "" {
+		return nil, fmt.Errorf("model, baseURL, and apiKey are required for OpenAI agent")
+	}
+
+	return &apiAgentRunner{
+		agentType: "openai-agent",
+		model:     model,
+		baseURL:   baseURL,
+		apiKey:    apiKey,
+		options:   options,
+	}, nil
+}
+
+// NewAnthropicAgentRunner creates a runner that calls the Anthropic Messages
+// API with the anthropicagent package. An empty baseURL means the Anthropic API.
+func NewAnthropicAgentRunner(model, baseURL, apiKey string, options openaiagent.Options) (Runner, error) {
+	if model == "" || apiKey == "" {
+		return nil, fmt.Errorf("model and apiKey are required for Anthropic agent")
+	}
+
+	return &apiAgentRunner{
+		agentType: "anthropic-agent",
+		model:     model,
+		baseURL:   baseURL,
+		apiKey:    apiKey,
+		options:   options,
+	}, nil
+}
+
+// NewOllamaAgentRunner creates a runner that calls the chat API of Ollama with
+// the ollamaagent package. An empty baseURL means a local Ollama server.
+func NewOllamaAgentRunner(model, baseURL string, options openaiagent.Options) (Runner, error) {
+	if model == "" {
+		return nil, fmt.Errorf("model is required for Ollama agent")
+	}
+
+	return &apiAgentRunner{
+		agentType: "ollama-agent",
+		model:     model,
+		baseURL:   baseURL,
+		options:   options,
+	}, nil
+}
+
+// newAPIAgentRunner creates the runner of a builtin reference of a type that
+// isAPIAgentType accepts
+func newAPIAgentRunner(ref *BuiltinRef) (Runner, error) {
+	options := apiAgentOptions(ref)
+	switch ref.Type {
+	case "anthropic-agent":
+		return NewAnthropicAgentRunner(ref.Model, ref.BaseURL, ref.APIKey, options)
+	case "ollama-agent":
+		return NewOllamaAgentRunner(ref.Model, ref.BaseURL, options)
+	default:
+		return NewOpenAIAgentRunnerWithOptions(ref.Model, ref.BaseURL, ref.APIKey, options)
+	}
+}
+
+// apiAgentOptions returns the options of the tool-call loop set in a builtin
+// reference
+func apiAgentOptions(ref *BuiltinRef) openaiagent.Options {
+	return openaiagent.Options{
+		MaxIterations:     ref.MaxIterations,
+		ParallelToolCalls: ref.ParallelToolCalls,
+		ToolCallTimeout:   time.Duration(ref.ToolCallTimeout),
+	}
+}
+
+func (r *apiAgentRunner) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) Runner {
+	return &apiAgentRunner{
+		agentType: r.agentType,
+		model:     r.model,
+		baseURL:   r.baseURL,
+		apiKey:    r.apiKey,
+		options:   r.options,
+		mcpInfo:   mcpServers,
+	}
+}
+
+func (r *apiAgentRunner) AgentName() string {
+	return fmt.Sprintf("%s-%s", r.agentType, r.model)
+}
+
+func (r *apiAgentRunner) newAgent() (apiAgent, error) {
+	switch r.agentType {
+	case "anthropic-agent":
+		return anthropicagent.NewAIAgent(r.baseURL, r.apiKey, r.model, "")
+	case "ollama-agent":
+		return ollamaagent.NewAIAgent(r.baseURL, r.model, "")
+	default:
+		return openaiagent.NewAIAgent(r.baseURL, r.apiKey, r.model, "")
+	}
+}
+
+// CheckHealth checks that the model API is reachable with the configured
+// credentials and model
+func (r *apiAgentRunner) CheckHealth(ctx context.Context) error {
+	agent, err := r.newAgent()
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", r.agentType, err)
+	}
+	defer agent.Close()
+
+	return agent.CheckHealth(ctx)
+}
+
+func (r *apiAgentRunner) RunTask(ctx context.Context, prompt string) (AgentResult, error) {
+	// Create the agent
+	agent, err := r.newAgent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", r.agentType, err)
+	}
+	defer agent.Close()
+	agent.SetOptions(r.options)
+
+	// Add MCP servers if available
+	if r.mcpInfo != nil {
+		servers := r.mcpInfo.GetMcpServers()
+		for _, server := range servers {
+			serverCfg, err := server.GetConfig()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get config for server %s: %w", server.GetName(), err)
+			}
+
+			if err := agent.AddMCPServer(ctx, serverCfg.URL); err != nil {
+				return nil, fmt.Errorf("failed to add MCP server %s: %w", server.GetName(), err)
+			}
+		}
+	}
+
+	// API agents cannot read files, so attached files are sent in the prompt
+	if files := util.PromptFilesFromContext(ctx); len(files) > 0 {
+		prompt, err = embedPromptFiles(prompt, files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Run the agent with the prompt, and the images attached to it
+	var result string
+	if images := util.PromptImagesFromContext(ctx); len(images) > 0 {
+		imgAgent, ok := agent.(imageAgent)
+		if !ok {
+			return nil, fmt.Errorf("%s does not support images in the prompt", r.agentType)
+		}
+		result, err = imgAgent.RunWithImages(ctx, prompt, images)
+	} else {
+		result, err = agent.Run(ctx, prompt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run agent: %w", err)
+	}
+
+	return &apiAgentResult{
+		output: result,
+	}, nil
+}
@@ -1,8 +1,10 @@
 package agent
 
 var builtinTypes = map[string]BuiltinAgent{
-	"openai-agent": &OpenAIAgent{},
-	"claude-code":  &ClaudeCodeAgent{},
+	"openai-agent":    &OpenAIAgent{},
+	"anthropic-agent": &AnthropicAgent{},
+	"ollama-agent":    &OllamaAgent{},
+	"claude-code":     &ClaudeCodeAgent{},
 }
 
 // GetBuiltinType retrieves a builtin agent by name
@@ -24,6 +24,16 @@ func TestGetBuiltinType(t *testing.T) {
 			shouldExist:  true,
 			expectedName: "claude-code",
 		},
+		"anthropic-agent exists": {
+			agentType:    "anthropic-agent",
+			shouldExist:  true,
+			expectedName: "anthropic-agent",
+		},
+		"ollama-agent exists": {
+			agentType:    "ollama-agent",
+			shouldExist:  true,
+			expectedName: "ollama-agent",
+		},
 		"non-existent agent": {
 			agentType:   "non-existent",
 			shouldExist: false,
@@ -48,13 +58,15 @@ func TestGetBuiltinType(t *testing.T) {
 func TestListBuiltinTypes(t *testing.T) {
 	agents := ListBuiltinTypes()
 
-	// Should have at least 2 builtin agents
-	assert.GreaterOrEqual(t, len(agents), 2)
+	// Should have at least 4 builtin agents
+	assert.GreaterOrEqual(t, len(agents), 4)
 
 	// Check that expected agents are present
 	expectedAgents := map[string]bool{
-		"openai-agent": false,
-		"claude-code":  false,
+		"openai-agent":    false,
+		"anthropic-agent": false,
+		"ollama-agent":    false,
+		"claude-code":     false,
 	}
 
 	for _, agent := range agents {
@@ -152,6 +164,97 @@ func TestOpenAIAgent(t *testing.T) {
 	})
 }
 
+func TestAnthropicAgent(t *testing.T) {
+	agent := &AnthropicAgent{}
+
+	t.Run("RequiresModel", func(t *testing.T) {
+		assert.True(t, agent.RequiresModel())
+	})
+
+	t.Run("GetDefaults requires model", func(t *testing.T) {
+		spec, err := agent.GetDefaults("")
+		assert.Error(t, err)
+		assert.Nil(t, spec)
+		assert.Contains(t, err.Error(), "model is required")
+	})
+
+	t.Run("GetDefaults requires API key", func(t *testing.T) {
+		t.Setenv("ANTHROPIC_API_KEY", "")
+
+		spec, err := agent.GetDefaults("claude-sonnet-4-5")
+		assert.Error(t, err)
+		assert.Nil(t, spec)
+		assert.Contains(t, err.Error(), "ANTHROPIC_API_KEY")
+	})
+
+	t.Run("GetDefaults with valid environment", func(t *testing.T) {
+		t.Setenv("ANTHROPIC_API_KEY", "test-key")
+		t.Setenv("ANTHROPIC_BASE_URL", "")
+
+		spec, err := agent.GetDefaults("claude-sonnet-4-5")
+		require.NoError(t, err)
+		require.NotNil(t, spec)
+
+		assert.Equal(t, "anthropic-agent-claude-sonnet-4-5", spec.Metadata.Name)
+		require.NotNil(t, spec.Builtin)
+		assert.Equal(t, "anthropic-agent", spec.Builtin.Type)
+		assert.Equal(t, "claude-sonnet-4-5", spec.Builtin.Model)
+		assert.Empty(t, spec.Builtin.BaseURL)
+		assert.Equal(t, "test-key", spec.Builtin.APIKey)
+		assert.Empty(t, spec.Commands.RunPrompt)
+	})
+}
+
+func TestOllamaAgent(t *testing.T) {
+	agent := &OllamaAgent{}
+
+	t.Run("RequiresModel", func(t *testing.T) {
+		assert.True(t, agent.RequiresModel())
+	})
+
+	t.Run("GetDefaults requires model", func(t *testing.T) {
+		spec, err := agent.GetDefaults("")
+		assert.Error(t, err)
+		assert.Nil(t, spec)
+		assert.Contains(t, err.Error(), "model is required")
+	})
+
+	tests := map[string]struct {
+		host            string
+		expectedBaseURL string
+	}{
+		"default host": {
+			host:            "",
+			expectedBaseURL: "http://localhost:11434",
+		},
+		"host without scheme": {
+			host:            "gpu-box:11434",
+			expectedBaseURL: "http://gpu-box:11434",
+		},
+		"host with scheme": {
+			host:            "https://ollama.example.com",
+			expectedBaseURL: "https://ollama.example.com",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run("GetDefaults with "+name, func(t *testing.T) {
+			t.Setenv("OLLAMA_HOST", tc.host)
+
+			spec, err := agent.GetDefaults("qwen3")
+			require.NoError(t, err)
+			require.NotNil(t, spec)
+
+			assert.Equal(t, "ollama-agent-qwen3", spec.Metadata.Name)
+			require.NotNil(t, spec.Builtin)
+			assert.Equal(t, "ollama-agent", spec.Builtin.Type)
+			assert.Equal(t, "qwen3", spec.Builtin.Model)
+			assert.Equal(t, tc.expectedBaseURL, spec.Builtin.BaseURL)
+			assert.Empty(t, spec.Builtin.APIKey)
+		})
+	}
+}
+
 func TestClaudeCodeAgent(t *testing.T) {
 	agent := &ClaudeCodeAgent{}
 
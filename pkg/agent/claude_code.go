@@ -29,6 +29,7 @@ func (a *ClaudeCodeAgent) ValidateEnvironment() error {
 func (a *ClaudeCodeAgent) GetDefaults(model string) (*AgentSpec, error) {
 	separator := ","
 	useVirtualHome := false
+	getVersion := "claude --version"
 	return &AgentSpec{
 		Metadata: AgentMetadata{
 			Name: "claude-code",
@@ -38,7 +39,9 @@ func (a *ClaudeCodeAgent) GetDefaults(model string) (*AgentSpec, error) {
 			ArgTemplateMcpServer:      "--mcp-config {{ .File }}",
 			ArgTemplateAllowedTools:   "mcp__{{ .ServerName }}__{{ .ToolName }}",
 			AllowedToolsJoinSeparator: &separator,
+			PromptFileTemplate:        "- @{{ .Path }}",
 			RunPrompt:                 `claude {{ .McpServerFileArgs }} --strict-mcp-config --allowedTools "{{ .AllowedToolArgs }}" --print "{{ .Prompt }}"`,
+			GetVersion:                &getVersion,
 		},
 	}, nil
 }
@@ -13,6 +13,13 @@ const (
 	KindAgent = "Agent"
 )
 
+// Ways the prompt is passed to the agent command
+const (
+	PromptViaArg   = "arg"
+	PromptViaStdin = "stdin"
+	PromptViaFile  = "file"
+)
+
 type AgentSpec struct {
 	util.TypeMeta `json:",inline"`
 	Metadata      AgentMetadata        `json:"metadata"`
@@ -23,7 +30,8 @@ type AgentSpec struct {
 
 // BuiltinRef references a built-in agent type with optional model
 type BuiltinRef struct {
-	// Type is the built-in agent type (e.g., "openai-agent", "claude-code")
+	// Type is the built-in agent type (e.g., "openai-agent", "anthropic-agent",
+	// "ollama-agent", "claude-code")
 	Type string `json:"type"`
 
 	// Model is the AI model to use (required for some types like openai-agent)
@@ -34,6 +42,18 @@ type BuiltinRef struct {
 
 	// APIKey overrides the default API key (from environment)
 	APIKey string `json:"apiKey,omitempty"`
+
+	// MaxIterations limits the number of model requests of a task for
+	// openai-agent, anthropic-agent, and ollama-agent. Defaults to 50, a
+	// negative value means no limit.
+	MaxIterations int `json:"maxIterations,omitempty"`
+
+	// ParallelToolCalls makes API agents execute the tool calls of a model
+	// response concurrently
+	ParallelToolCalls bool `json:"parallelToolCalls,omitempty"`
+
+	// ToolCallTimeout limits the time of each tool call of API agents
+	ToolCallTimeout util.Duration `json:"toolCallTimeout,omitempty"`
 }
 
 type AgentMetadata struct {
@@ -62,14 +82,40 @@ type AgentCommands struct {
 	// Defaults to " " (space) if not specified
 	AllowedToolsJoinSeparator *string `json:"allowedToolsJoinSeparator,omitempty"`
 
+	// How the allowed tools are passed to the agent: "arg" (default) renders
+	// them into runPrompt as {{ .AllowedToolArgs }} or {{ .AllowedTools }},
+	// "env" sets MCPCHECKER_ALLOWED_TOOLS to {{ .AllowedToolArgs }}, and
+	// "file" writes the allowed tool names of each server as a JSON object to
+	// a temporary file whose path is in {{ .AllowedToolsFile }} and
+	// MCPCHECKER_ALLOWED_TOOLS_FILE
+	AllowedToolsVia string `json:"allowedToolsVia,omitempty"`
+
 	// A template command to run the agent with a prompt and some mcp servers
 	// the prompt will be in {{ .Prompt }}
 	// the servers will be in {{ .McpServerFileArgs }}
 	// the allowed tools will be in {{ .AllowedToolArgs }}
+	// the server files and allowed tools are also available as lists in
+	// {{ .McpServerFiles }} and {{ .AllowedTools }}
+	// all templates can use the quote, json, env, and joinArgs functions
 	RunPrompt string `json:"runPrompt"`
 
+	// How the prompt is passed to the agent: "arg" (default) renders it into
+	// runPrompt as {{ .Prompt }}, "stdin" writes it to the standard input of
+	// the command, and "file" writes it to a temporary file whose path is in
+	// {{ .PromptFile }}
+	PromptVia string `json:"promptVia,omitempty"`
+
+	// A template for how each file or image attached to the prompt is
+	// mentioned in it, below the prompt
+	// the absolute path of the file will be in {{ .Path }}
+	// the path relative to the working directory will be in {{ .Name }}, or
+	// the file name for images, which are written to a temporary directory
+	// Defaults to "- {{ .Path }}"
+	PromptFileTemplate string `json:"promptFileTemplate,omitempty"`
+
 	// An optional command to get the version of the agent
 	// useful for generic agents such as claude code that may autoupdate/have different versions on different machines
+	// it is also run before the first task to check that the agent is runnable
 	GetVersion *string `json:"getVersion,omitempty"`
 }
 
@@ -96,3 +142,15 @@ func FromFile(path string) (*AgentSpec, error) {
 
 	return Read(data)
 }
+
+// Redacted returns a copy of the spec with its API key redacted, for recording
+// with results
+func (s *AgentSpec) Redacted() *AgentSpec {
+	redacted := *s
+	if s.Builtin != nil && s.Builtin.APIKey != "" {
+		builtin := *s.Builtin
+		builtin.APIKey = util.Redacted
+		redacted.Builtin = &builtin
+	}
+	return &redacted
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
+)
+
+// HealthCheckTimeout limits the time of the health check of an agent
+const HealthCheckTimeout = 30 * time.Second
+
+// HealthChecker is implemented by runners that can check that their agent is
+// runnable without running a task
+type HealthChecker interface {
+	// CheckHealth returns an error that tells why the agent cannot run
+	CheckHealth(ctx context.Context) error
+}
+
+// CheckHealth checks that the agent of a runner is runnable, within
+// HealthCheckTimeout. Runners that do not implement HealthChecker pass.
+func CheckHealth(ctx context.Context, runner Runner) error {
+	checker, ok := runner.(HealthChecker)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	return checker.CheckHealth(ctx)
+}
+
+// CheckHealth runs the getVersion command of the agent, if it has one
+func (a *agentSpecRunner) CheckHealth(ctx context.Context) error {
+	if a.Commands.GetVersion == nil || strings.TrimSpace(*a.Commands.GetVersion) == "" {
+		return nil
+	}
+
+	sh := shell.Default()
+	cmd := sh.Command(ctx, *a.Commands.GetVersion)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("version command %q failed: %w\n\noutput: %s", *a.Commands.GetVersion, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// CheckHealth checks that the command of the ACP agent exists
+func (r *acpRunner) CheckHealth(ctx context.Context) error {
+	if _, err := exec.LookPath(r.cfg.Cmd); err != nil {
+		return fmt.Errorf("acp command %q not found: %w", r.cfg.Cmd, err)
+	}
+	return nil
+}
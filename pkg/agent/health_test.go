@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHealth(t *testing.T) {
+	command := func(c string) *string { return &c }
+
+	tests := map[string]struct {
+		runner      Runner
+		errContains string
+	}{
+		"version command succeeds": {
+			runner: &agentSpecRunner{AgentSpec: &AgentSpec{Commands: AgentCommands{GetVersion: command("echo 1.0.0")}}},
+		},
+		"version command fails": {
+			runner:      &agentSpecRunner{AgentSpec: &AgentSpec{Commands: AgentCommands{GetVersion: command("echo broken install && exit 3")}}},
+			errContains: "broken install",
+		},
+		"no version command": {
+			runner: &agentSpecRunner{AgentSpec: &AgentSpec{}},
+		},
+		"acp command exists": {
+			runner: NewAcpRunner(&acpclient.AcpConfig{Cmd: "go"}, "acp"),
+		},
+		"acp command is missing": {
+			runner:      NewAcpRunner(&acpclient.AcpConfig{Cmd: "mcpchecker-missing-agent"}, "acp"),
+			errContains: `acp command "mcpchecker-missing-agent" not found`,
+		},
+		"runner without health check": {
+			runner: struct{ Runner }{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := CheckHealth(context.Background(), tc.runner)
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errContains)
+		})
+	}
+}
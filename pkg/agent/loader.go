@@ -12,6 +12,9 @@ func LoadWithBuiltins(yamlPath string) (*AgentSpec, error) {
 
 	// If no builtin reference, return as-is
 	if spec.Builtin == nil {
+		if err := spec.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid agent spec '%s': %w", yamlPath, err)
+		}
 		return spec, nil
 	}
 
@@ -40,6 +43,10 @@ func LoadWithBuiltins(yamlPath string) (*AgentSpec, error) {
 	// Merge: YAML overrides defaults
 	merged := mergeAgentSpecs(defaults, spec)
 
+	if err := merged.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid agent spec '%s': %w", yamlPath, err)
+	}
+
 	return merged, nil
 }
 
@@ -73,6 +80,15 @@ func mergeAgentSpecs(defaults, overrides *AgentSpec) *AgentSpec {
 			if overrides.Builtin.APIKey != "" {
 				result.Builtin.APIKey = overrides.Builtin.APIKey
 			}
+			if overrides.Builtin.MaxIterations != 0 {
+				result.Builtin.MaxIterations = overrides.Builtin.MaxIterations
+			}
+			if overrides.Builtin.ParallelToolCalls {
+				result.Builtin.ParallelToolCalls = true
+			}
+			if overrides.Builtin.ToolCallTimeout != 0 {
+				result.Builtin.ToolCallTimeout = overrides.Builtin.ToolCallTimeout
+			}
 		}
 	}
 
@@ -81,6 +97,8 @@ func mergeAgentSpecs(defaults, overrides *AgentSpec) *AgentSpec {
 	commandsSpecified := overrides.Commands.ArgTemplateMcpServer != "" ||
 		overrides.Commands.ArgTemplateAllowedTools != "" ||
 		overrides.Commands.RunPrompt != "" ||
+		overrides.Commands.PromptVia != "" ||
+		overrides.Commands.PromptFileTemplate != "" ||
 		overrides.Commands.AllowedToolsJoinSeparator != nil ||
 		overrides.Commands.GetVersion != nil ||
 		overrides.Commands.UseVirtualHome != nil
@@ -99,6 +117,12 @@ func mergeAgentSpecs(defaults, overrides *AgentSpec) *AgentSpec {
 		if overrides.Commands.RunPrompt != "" {
 			result.Commands.RunPrompt = overrides.Commands.RunPrompt
 		}
+		if overrides.Commands.PromptVia != "" {
+			result.Commands.PromptVia = overrides.Commands.PromptVia
+		}
+		if overrides.Commands.PromptFileTemplate != "" {
+			result.Commands.PromptFileTemplate = overrides.Commands.PromptFileTemplate
+		}
 		if overrides.Commands.GetVersion != nil {
 			result.Commands.GetVersion = overrides.Commands.GetVersion
 		}
@@ -4,8 +4,10 @@ import (
 	"os"
 	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -72,6 +74,10 @@ func TestLoadWithBuiltins(t *testing.T) {
 				// Builtin configuration should be present
 				require.NotNil(t, spec.Builtin)
 				assert.Equal(t, "openai-agent", spec.Builtin.Type)
+				// Tool-call loop options should be kept
+				assert.Equal(t, 10, spec.Builtin.MaxIterations)
+				assert.True(t, spec.Builtin.ParallelToolCalls)
+				assert.Equal(t, util.Duration(30*time.Second), spec.Builtin.ToolCallTimeout)
 			},
 		},
 		"non-builtin agent (no builtin field)": {
@@ -83,6 +89,11 @@ func TestLoadWithBuiltins(t *testing.T) {
 				assert.Equal(t, "2.0.x", *spec.Metadata.Version)
 			},
 		},
+		"command template with unknown field": {
+			file:        "invalid-run-prompt.yaml",
+			expectErr:   true,
+			errContains: "commands.runPrompt references unknown field(s) .Promt",
+		},
 		"invalid builtin type": {
 			file:        "builtin-invalid-type.yaml",
 			expectErr:   true,
@@ -182,6 +193,43 @@ func TestNewRunnerForSpec(t *testing.T) {
 				assert.Equal(t, "acp-priority", runner.AgentName())
 			},
 		},
+		"anthropic builtin returns apiAgentRunner": {
+			spec: &AgentSpec{
+				Builtin: &BuiltinRef{
+					Type:   "anthropic-agent",
+					Model:  "claude-sonnet-4-5",
+					APIKey: "test-key",
+				},
+			},
+			validate: func(t *testing.T, runner Runner) {
+				assert.Equal(t, "anthropic-agent-claude-sonnet-4-5", runner.AgentName())
+				_, ok := runner.(*apiAgentRunner)
+				assert.True(t, ok, "expected runner to be *apiAgentRunner")
+			},
+		},
+		"ollama builtin returns apiAgentRunner": {
+			spec: &AgentSpec{
+				Builtin: &BuiltinRef{
+					Type:  "ollama-agent",
+					Model: "qwen3",
+				},
+			},
+			validate: func(t *testing.T, runner Runner) {
+				assert.Equal(t, "ollama-agent-qwen3", runner.AgentName())
+				_, ok := runner.(*apiAgentRunner)
+				assert.True(t, ok, "expected runner to be *apiAgentRunner")
+			},
+		},
+		"anthropic builtin without API key returns error": {
+			spec: &AgentSpec{
+				Builtin: &BuiltinRef{
+					Type:  "anthropic-agent",
+					Model: "claude-sonnet-4-5",
+				},
+			},
+			expectErr:   true,
+			errContains: "apiKey are required",
+		},
 		"spec without acp or builtin returns agentSpecRunner": {
 			spec: &AgentSpec{
 				Metadata: AgentMetadata{Name: "shell-agent"},
@@ -245,6 +293,7 @@ func TestMergeAgentSpecs(t *testing.T) {
 			Commands: AgentCommands{
 				UseVirtualHome: &overrideUseVirtualHome,
 				RunPrompt:      "override command",
+				PromptVia:      PromptViaStdin,
 			},
 		}
 		result := mergeAgentSpecs(base, override)
@@ -253,6 +302,7 @@ func TestMergeAgentSpecs(t *testing.T) {
 		require.NotNil(t, result.Commands.UseVirtualHome)
 		assert.True(t, *result.Commands.UseVirtualHome)
 		assert.Equal(t, "override command", result.Commands.RunPrompt)
+		assert.Equal(t, PromptViaStdin, result.Commands.PromptVia)
 
 		// Non-overridden fields should keep base value
 		assert.Equal(t, "{{ .File }}", result.Commands.ArgTemplateMcpServer)
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mcpchecker/mcpchecker/pkg/ollamaagent"
+)
+
+type OllamaAgent struct{}
+
+func (a *OllamaAgent) Name() string {
+	return "ollama-agent"
+}
+
+func (a *OllamaAgent) Description() string {
+	return "Ollama agent using direct chat API calls"
+}
+
+func (a *OllamaAgent) RequiresModel() bool {
+	return true
+}
+
+func (a *OllamaAgent) ValidateEnvironment() error {
+	// No external binary required - we use the ollamaagent package directly
+	return nil
+}
+
+func (a *OllamaAgent) GetDefaults(model string) (*AgentSpec, error) {
+	if model == "" {
+		return nil, fmt.Errorf("model is required for ollama-agent")
+	}
+
+	useVirtualHome := false
+	return &AgentSpec{
+		Metadata: AgentMetadata{
+			Name: fmt.Sprintf("ollama-agent-%s", model),
+		},
+		// The runner will be created specially for API agents. Ollama needs
+		// no API key, and is found like the ollama CLI finds it.
+		Builtin: &BuiltinRef{
+			Type:    "ollama-agent",
+			Model:   model,
+			BaseURL: ollamaagent.BaseURL(os.Getenv("OLLAMA_HOST")),
+		},
+		Commands: AgentCommands{
+			UseVirtualHome:       &useVirtualHome,
+			ArgTemplateMcpServer: "{{ .URL }}",
+			// RunPrompt is not used for API agents - they use a custom runner
+			RunPrompt: "",
+		},
+	}, nil
+}
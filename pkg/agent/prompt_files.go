@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// mentionPromptFiles appends a mention of each file attached to the prompt,
+// rendered with tmpl, for agents that read files themselves. Names are
+// relative to dir, the directory the agent runs in, or the file names of files
+// outside it.
+func mentionPromptFiles(prompt string, files []string, dir, tmpl string, sh *shell.Shell) (string, error) {
+	promptFileTemplate, err := parseCommandTemplate("promptFileTemplate", cmp.Or(tmpl, defaultPromptFileTemplate), sh)
+	if err != nil {
+		return "", err
+	}
+
+	mentions := make([]string, 0, len(files))
+	for _, file := range files {
+		name, err := filepath.Rel(dir, file)
+		if err != nil || !filepath.IsLocal(name) {
+			name = filepath.Base(file)
+		}
+
+		formatted := bytes.NewBuffer(nil)
+		if err := promptFileTemplate.Execute(formatted, promptFileTemplateData{Path: file, Name: filepath.ToSlash(name)}); err != nil {
+			return "", fmt.Errorf("failed to execute promptFileTemplate: %w", err)
+		}
+		mentions = append(mentions, formatted.String())
+	}
+
+	return prompt + "\n\nAttached files:\n" + strings.Join(mentions, "\n"), nil
+}
+
+// embedPromptFiles appends the content of each file attached to the prompt,
+// for agents that cannot read files. Files that are not text are mentioned by
+// path only.
+func embedPromptFiles(prompt string, files []string) (string, error) {
+	var b strings.Builder
+	b.WriteString(prompt)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt file: %w", err)
+		}
+
+		if !utf8.Valid(data) {
+			fmt.Fprintf(&b, "\n\nAttached file %s (binary, %d bytes)", filepath.Base(file), len(data))
+			continue
+		}
+		fmt.Fprintf(&b, "\n\nAttached file %s:\n```\n%s\n```", filepath.Base(file), strings.TrimSuffix(string(data), "\n"))
+	}
+
+	return b.String(), nil
+}
+
+// writePromptImages writes the images attached to the prompt to a new
+// temporary directory, for agents that read images from files, and returns the
+// directory and the paths of the images. The caller removes the directory.
+func writePromptImages(images []util.Image) (string, []string, error) {
+	dir, err := os.MkdirTemp("", "mcpchecker-images-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create directory for prompt images: %w", err)
+	}
+
+	paths := make([]string, 0, len(images))
+	used := make(map[string]bool, len(images))
+	for i, image := range images {
+		name := image.Name
+		if used[name] {
+			name = fmt.Sprintf("%d-%s", i+1, name)
+		}
+		used[name] = true
+
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, image.Data, 0644); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", nil, fmt.Errorf("failed to write prompt image: %w", err)
+		}
+		paths = append(paths, path)
+	}
+
+	return dir, paths, nil
+}
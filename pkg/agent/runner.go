@@ -5,11 +5,13 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"slices"
 	"strings"
-	"text/template"
 
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/procmon"
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 type Runner interface {
@@ -50,10 +52,10 @@ func NewRunnerForSpec(spec *AgentSpec) (Runner, error) {
 		return NewAcpRunner(spec.AcpConfig, spec.Metadata.Name), nil
 	}
 
-	// Check if this is an OpenAI agent with builtin configuration
-	if spec.Builtin != nil && spec.Builtin.Type == "openai-agent" {
-		// Use the custom OpenAI agent runner
-		return NewOpenAIAgentRunner(spec.Builtin.Model, spec.Builtin.BaseURL, spec.Builtin.APIKey)
+	// Check if this is a builtin agent that calls a model API directly
+	if spec.Builtin != nil && isAPIAgentType(spec.Builtin.Type) {
+		// Use the custom runner of API agents
+		return newAPIAgentRunner(spec.Builtin)
 	}
 
 	// Use the standard shell-based runner for all other agents
@@ -72,7 +74,13 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 		}
 	}
 
-	// Create an empty temporary directory for agent execution to isolate it from source code
+	// Run the agent in the task's working directory if it has one, so that it
+	// can access the task's files. Otherwise create an empty temporary
+	// directory for agent execution to isolate it from source code
+	if workdir, ok := util.WorkdirFromContext(ctx); ok {
+		return a.runCommand(ctx, prompt, workdir, debugDir)
+	}
+
 	tempDir, err := os.MkdirTemp("", "mcpchecker-agent-")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary directory for agent execution: %w", err)
@@ -97,19 +105,39 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 		}
 	}()
 
-	argTemplateMcpServer, err := template.New("argTemplateMcpServer").Parse(a.Commands.ArgTemplateMcpServer)
+	result, err := a.runCommand(ctx, prompt, tempDir, debugDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse argTemplateMcpServer: %w", err)
+		// executionSucceeded remains false, so tempDir will be preserved
+		return nil, fmt.Errorf("%w\n\ntemporary directory preserved at: %s", err, tempDir)
+	}
+
+	executionSucceeded = true
+
+	// If MCPCHECKER_DEBUG is set, append temp directory info to output so it appears in JSON log
+	if os.Getenv("MCPCHECKER_DEBUG") != "" {
+		result.commandOutput += fmt.Sprintf("\n\ntemporary directory preserved at: %s", tempDir)
 	}
 
-	argTemplateAllowedTools, err := template.New("argTemplateAllowedTools").Parse(a.Commands.ArgTemplateAllowedTools)
+	return result, nil
+}
+
+// runCommand renders the agent command and runs it in dir
+func (a *agentSpecRunner) runCommand(ctx context.Context, prompt, dir, debugDir string) (*agentSpecRunnerResult, error) {
+	sh := shell.Default()
+
+	argTemplateMcpServer, err := parseCommandTemplate("argTemplateMcpServer", a.Commands.ArgTemplateMcpServer, sh)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse argTemplateAllowedTools: %w", err)
+		return nil, err
 	}
 
-	runPrompt, err := template.New("runPrompt").Parse(a.Commands.RunPrompt)
+	argTemplateAllowedTools, err := parseCommandTemplate("argTemplateAllowedTools", a.Commands.ArgTemplateAllowedTools, sh)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse runPrompt: %w", err)
+		return nil, err
+	}
+
+	runPrompt, err := parseCommandTemplate("runPrompt", a.Commands.RunPrompt, sh)
+	if err != nil {
+		return nil, err
 	}
 
 	var serverFiles []string
@@ -130,10 +158,7 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 			return nil, fmt.Errorf("failed to get config for server %s: %w", servers[i].GetName(), err)
 		}
 
-		tmp := struct {
-			File string
-			URL  string
-		}{
+		tmp := mcpServerTemplateData{
 			File: f,
 			URL:  serverCfg.URL,
 		}
@@ -148,12 +173,13 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 	}
 
 	var allowedTools []string
+	toolNames := make(map[string][]string)
 	for _, s := range a.mcpInfo.GetMcpServers() {
+		toolNames[s.GetName()] = []string{}
 		for _, t := range s.GetAllowedTools() {
-			tmp := struct {
-				ServerName string
-				ToolName   string
-			}{
+			toolNames[s.GetName()] = append(toolNames[s.GetName()], t.Name)
+
+			tmp := allowedToolTemplateData{
 				ServerName: s.GetName(),
 				ToolName:   t.Name,
 			}
@@ -174,14 +200,53 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 		allowedToolsSeparator = *a.Commands.AllowedToolsJoinSeparator
 	}
 
-	tmp := struct {
-		McpServerFileArgs string
-		AllowedToolArgs   string
-		Prompt            string
-	}{
+	// Images are written to files and attached like the files of the prompt
+	promptFiles := util.PromptFilesFromContext(ctx)
+	if images := util.PromptImagesFromContext(ctx); len(images) > 0 {
+		imageDir, imageFiles, err := writePromptImages(images)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.RemoveAll(imageDir) }()
+		promptFiles = append(slices.Clone(promptFiles), imageFiles...)
+	}
+	if len(promptFiles) > 0 {
+		prompt, err = mentionPromptFiles(prompt, promptFiles, dir, a.Commands.PromptFileTemplate, sh)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tmp := runPromptTemplateData{
 		McpServerFileArgs: strings.Join(serverFiles, " "),
+		McpServerFiles:    filesRaw,
 		AllowedToolArgs:   strings.Join(allowedTools, allowedToolsSeparator),
+		AllowedTools:      allowedTools,
 		Prompt:            prompt,
+		PromptFiles:       promptFiles,
+	}
+
+	if a.Commands.PromptVia == PromptViaFile {
+		promptFile, err := writePromptFile(prompt)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.Remove(promptFile) }()
+		tmp.PromptFile = promptFile
+	}
+
+	envVars := os.Environ()
+	switch a.Commands.AllowedToolsVia {
+	case AllowedToolsViaEnv:
+		envVars = append(envVars, fmt.Sprintf("%s=%s", EnvAllowedTools, tmp.AllowedToolArgs))
+	case AllowedToolsViaFile:
+		allowedToolsFile, err := writeAllowedToolsFile(toolNames)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.Remove(allowedToolsFile) }()
+		tmp.AllowedToolsFile = allowedToolsFile
+		envVars = append(envVars, fmt.Sprintf("%s=%s", EnvAllowedToolsFile, allowedToolsFile))
 	}
 
 	formatted := bytes.NewBuffer(nil)
@@ -190,48 +255,65 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 		return nil, fmt.Errorf("failed to execute runPrompt: %w", err)
 	}
 
-	shell, ok := os.LookupEnv("SHELL")
-	if !ok {
-		shell = "/usr/bin/bash"
-	}
-
-	cmd := exec.CommandContext(ctx, shell, "-c", formatted.String())
-	cmd.Dir = tempDir
-	envVars := os.Environ()
+	cmd := sh.Command(ctx, formatted.String())
+	cmd.Dir = dir
 	if debugDir != "" {
 		envVars = append(envVars, fmt.Sprintf("MCPCHECKER_DEBUG_DIR=%s", debugDir))
 		envVars = append(envVars, "MCPCHECKER_DEBUG=1")
 	}
 	cmd.Env = envVars
+	if a.Commands.PromptVia == PromptViaStdin {
+		cmd.Stdin = strings.NewReader(prompt)
+	}
 
-	res, err := cmd.CombinedOutput()
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Start()
+	if err == nil {
+		watcher := procmon.Watch(ctx, cmd.Process.Pid)
+		err = cmd.Wait()
+		if limitErr := watcher.Stop(cmd.ProcessState); limitErr != nil {
+			err = limitErr
+		}
+	}
+	res := output.Bytes()
 	if err != nil {
 		debugSuffix := ""
 		if debugDir != "" {
 			debugSuffix = fmt.Sprintf("\n\ndebug artifacts preserved at: %s", debugDir)
 		}
-		// executionSucceeded remains false, so tempDir will be preserved
-		tempDirSuffix := fmt.Sprintf("\n\ntemporary directory preserved at: %s", tempDir)
-		return nil, fmt.Errorf("failed to run command: %s -c %q: %w.\n\noutput: %s%s%s", shell, formatted.String(), err, res, debugSuffix, tempDirSuffix)
+		return nil, fmt.Errorf("failed to run command with %s: %q: %w.\n\noutput: %s%s", sh.Path, formatted.String(), err, res, debugSuffix)
 	}
 
-	executionSucceeded = true
-
 	if debugDir != "" {
 		_ = os.RemoveAll(debugDir)
 	}
 
-	output := string(res)
-	// If MCPCHECKER_DEBUG is set, append temp directory info to output so it appears in JSON log
-	if os.Getenv("MCPCHECKER_DEBUG") != "" {
-		output += fmt.Sprintf("\n\ntemporary directory preserved at: %s", tempDir)
-	}
-
 	return &agentSpecRunnerResult{
-		commandOutput: output,
+		commandOutput: string(res),
 	}, nil
 }
 
+// writePromptFile writes the prompt to a temporary file and returns its path
+func writePromptFile(prompt string) (string, error) {
+	f, err := os.CreateTemp("", "mcpchecker-prompt-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create prompt file: %w", err)
+	}
+
+	_, err = f.WriteString(prompt)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	return f.Name(), nil
+}
+
 func (a *agentSpecRunner) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) Runner {
 	return &agentSpecRunner{
 		AgentSpec: a.AgentSpec,
@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/openaiagent"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noServers is McpServerInfo without any servers
+type noServers struct{}
+
+func (noServers) GetMcpServerFiles() ([]string, error) { return nil, nil }
+func (noServers) GetMcpServers() []mcpproxy.Server     { return nil }
+
+func TestRunCommandPromptVia(t *testing.T) {
+	tests := map[string]struct {
+		promptVia string
+		runPrompt string
+	}{
+		"arg": {
+			runPrompt: "printf %s {{ quote .Prompt }}{{ .McpServerFileArgs }}",
+		},
+		"stdin": {
+			promptVia: PromptViaStdin,
+			runPrompt: "cat{{ .McpServerFileArgs }}",
+		},
+		"file": {
+			promptVia: PromptViaFile,
+			runPrompt: "cat {{ quote .PromptFile }}{{ .McpServerFileArgs }}",
+		},
+	}
+
+	prompt := "List the pods in 'default'\nand describe them"
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			runner := &agentSpecRunner{
+				AgentSpec: &AgentSpec{Commands: AgentCommands{
+					ArgTemplateMcpServer: "{{ .File }}",
+					RunPrompt:            tc.runPrompt,
+					PromptVia:            tc.promptVia,
+				}},
+				mcpInfo: noServers{},
+			}
+
+			res, err := runner.runCommand(context.Background(), prompt, t.TempDir(), "")
+			require.NoError(t, err)
+			assert.Equal(t, prompt, res.GetOutput())
+		})
+	}
+}
+
+func TestRunCommandRemovesPromptFile(t *testing.T) {
+	dir := t.TempDir()
+	runner := &agentSpecRunner{
+		AgentSpec: &AgentSpec{Commands: AgentCommands{
+			ArgTemplateMcpServer: "{{ .File }}",
+			RunPrompt:            "printf %s {{ quote .PromptFile }} > path.txt{{ .McpServerFileArgs }}",
+			PromptVia:            PromptViaFile,
+		}},
+		mcpInfo: noServers{},
+	}
+
+	_, err := runner.runCommand(context.Background(), "prompt", dir, "")
+	require.NoError(t, err)
+
+	path, err := os.ReadFile(filepath.Join(dir, "path.txt"))
+	require.NoError(t, err)
+	assert.NoFileExists(t, string(path))
+}
+
+func TestRunCommandPromptFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "report.csv"), filepath.Join(dir, "logs", "app.log")}
+
+	tests := map[string]struct {
+		promptFileTemplate string
+		expected           string
+	}{
+		"default": {
+			expected: "Summarize\n\nAttached files:\n- " + files[0] + "\n- " + files[1],
+		},
+		"custom": {
+			promptFileTemplate: "- @{{ .Name }}",
+			expected:           "Summarize\n\nAttached files:\n- @report.csv\n- @logs/app.log",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			runner := &agentSpecRunner{
+				AgentSpec: &AgentSpec{Commands: AgentCommands{
+					ArgTemplateMcpServer: "{{ .File }}",
+					RunPrompt:            "printf %s {{ quote .Prompt }}{{ .McpServerFileArgs }}",
+					PromptFileTemplate:   tc.promptFileTemplate,
+				}},
+				mcpInfo: noServers{},
+			}
+
+			ctx := util.WithPromptFiles(context.Background(), files)
+			res, err := runner.runCommand(ctx, "Summarize", dir, "")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res.GetOutput())
+		})
+	}
+}
+
+func TestRunCommandPromptImages(t *testing.T) {
+	runner := &agentSpecRunner{
+		AgentSpec: &AgentSpec{Commands: AgentCommands{
+			ArgTemplateMcpServer: "{{ .File }}",
+			RunPrompt:            "printf '%s\\n' {{ quote .Prompt }}{{ .McpServerFileArgs }}; cat {{ range .PromptFiles }}{{ quote . }} {{ end }}",
+			PromptFileTemplate:   "- @{{ .Name }}",
+		}},
+		mcpInfo: noServers{},
+	}
+
+	ctx := util.WithPromptImages(context.Background(), []util.Image{
+		{Name: "screen.png", MediaType: "image/png", Data: []byte("first")},
+		{Name: "screen.png", MediaType: "image/png", Data: []byte("second")},
+	})
+	res, err := runner.runCommand(ctx, "Click save", t.TempDir(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "Click save\n\nAttached files:\n- @screen.png\n- @2-screen.png\nfirstsecond", res.GetOutput())
+}
+
+func TestAPIAgentWithoutImageSupport(t *testing.T) {
+	runner, err := NewAnthropicAgentRunner("claude", "http://127.0.0.1:1", "key", openaiagent.Options{})
+	require.NoError(t, err)
+
+	ctx := util.WithPromptImages(context.Background(), []util.Image{{Name: "screen.png", MediaType: "image/png"}})
+	_, err = runner.RunTask(ctx, "Click save")
+	assert.ErrorContains(t, err, "anthropic-agent does not support images in the prompt")
+}
+
+func TestEmbedPromptFiles(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.csv")
+	image := filepath.Join(dir, "chart.png")
+	require.NoError(t, os.WriteFile(report, []byte("name,status\nweb,failed\n"), 0644))
+	require.NoError(t, os.WriteFile(image, []byte{0x89, 'P', 'N', 'G', 0xff}, 0644))
+
+	got, err := embedPromptFiles("Summarize", []string{report, image})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize\n\nAttached file report.csv:\n```\nname,status\nweb,failed\n```\n\nAttached file chart.png (binary, 5 bytes)", got)
+
+	_, err = embedPromptFiles("Summarize", []string{filepath.Join(dir, "missing.txt")})
+	assert.ErrorContains(t, err, "failed to read prompt file")
+}
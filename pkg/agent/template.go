@@ -0,0 +1,311 @@
+package agent
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
+)
+
+// mcpServerTemplateData is the data argTemplateMcpServer is rendered with
+type mcpServerTemplateData struct {
+	File string
+	URL  string
+}
+
+// allowedToolTemplateData is the data argTemplateAllowedTools is rendered with
+type allowedToolTemplateData struct {
+	ServerName string
+	ToolName   string
+}
+
+// promptFileTemplateData is the data promptFileTemplate is rendered with
+type promptFileTemplateData struct {
+	Path string
+	Name string
+}
+
+// defaultPromptFileTemplate mentions the files attached to the prompt by path
+const defaultPromptFileTemplate = "- {{ .Path }}"
+
+// runPromptTemplateData is the data runPrompt is rendered with
+type runPromptTemplateData struct {
+	// McpServerFileArgs are the rendered argTemplateMcpServer of all servers, joined by spaces
+	McpServerFileArgs string
+	// McpServerFiles are the paths of the MCP server config files
+	McpServerFiles []string
+	// AllowedToolArgs are the rendered argTemplateAllowedTools of all tools, joined by the separator
+	AllowedToolArgs string
+	// AllowedTools are the rendered argTemplateAllowedTools of all tools
+	AllowedTools []string
+	// AllowedToolsFile is the path of the file with the allowed tools, with
+	// allowedToolsVia: file
+	AllowedToolsFile string
+	Prompt           string
+	// PromptFile is the path of the file with the prompt, with promptVia: file
+	PromptFile string
+	// PromptFiles are the paths of the files attached to the prompt
+	PromptFiles []string
+}
+
+// templateFuncs returns the functions available in the command templates.
+// Values are quoted for the shell the command runs in.
+func templateFuncs(sh *shell.Shell) template.FuncMap {
+	return template.FuncMap{
+		"quote": sh.Quote,
+		"json":  toJSON,
+		"env":   os.Getenv,
+		// joinArgs quotes each argument and joins them with spaces
+		"joinArgs": func(args []string) string {
+			quoted := make([]string, len(args))
+			for i, arg := range args {
+				quoted[i] = sh.Quote(arg)
+			}
+			return strings.Join(quoted, " ")
+		},
+	}
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// commandTemplate describes a command template and the data it is rendered with
+type commandTemplate struct {
+	name string
+	text string
+	data any
+	// required lists groups of fields, of which the template must reference at least one each
+	required [][]string
+}
+
+// parseCommandTemplate parses a command template with the template functions
+func parseCommandTemplate(name, text string, sh *shell.Shell) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs(sh)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commands.%s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// usesCommands returns whether the agent is run with the shell commands in its
+// spec, rather than over ACP or by a builtin runner
+func (s *AgentSpec) usesCommands() bool {
+	if s.AcpConfig != nil {
+		return false
+	}
+	return s.Builtin == nil || !isAPIAgentType(s.Builtin.Type)
+}
+
+// Validate checks that the command templates of the agent parse, only
+// reference fields they are rendered with, and reference the prompt and MCP
+// server config files. Agents that are not run with shell commands are not
+// checked.
+func (s *AgentSpec) Validate() error {
+	if !s.usesCommands() {
+		return nil
+	}
+
+	if s.Commands.RunPrompt == "" {
+		return fmt.Errorf("commands.runPrompt must be set")
+	}
+	if s.Commands.ArgTemplateMcpServer == "" {
+		return fmt.Errorf("commands.argTemplateMcpServer must be set")
+	}
+
+	// The prompt must reach the agent the way promptVia says
+	runPromptRequired := [][]string{{"McpServerFileArgs", "McpServerFiles"}}
+	switch s.Commands.PromptVia {
+	case "", PromptViaArg:
+		runPromptRequired = append([][]string{{"Prompt"}}, runPromptRequired...)
+	case PromptViaFile:
+		runPromptRequired = append([][]string{{"PromptFile"}}, runPromptRequired...)
+	case PromptViaStdin:
+	default:
+		return fmt.Errorf("commands.promptVia must be %q, %q, or %q, got %q", PromptViaArg, PromptViaStdin, PromptViaFile, s.Commands.PromptVia)
+	}
+
+	switch s.Commands.AllowedToolsVia {
+	case "", AllowedToolsViaArg, AllowedToolsViaEnv, AllowedToolsViaFile:
+	default:
+		return fmt.Errorf("commands.allowedToolsVia must be %q, %q, or %q, got %q", AllowedToolsViaArg, AllowedToolsViaEnv, AllowedToolsViaFile, s.Commands.AllowedToolsVia)
+	}
+
+	templates := []commandTemplate{
+		{
+			name:     "argTemplateMcpServer",
+			text:     s.Commands.ArgTemplateMcpServer,
+			data:     mcpServerTemplateData{},
+			required: [][]string{{"File", "URL"}},
+		},
+		{
+			name: "argTemplateAllowedTools",
+			text: s.Commands.ArgTemplateAllowedTools,
+			data: allowedToolTemplateData{},
+		},
+		{
+			name:     "promptFileTemplate",
+			text:     cmp.Or(s.Commands.PromptFileTemplate, defaultPromptFileTemplate),
+			data:     promptFileTemplateData{},
+			required: [][]string{{"Path", "Name"}},
+		},
+		{
+			name:     "runPrompt",
+			text:     s.Commands.RunPrompt,
+			data:     runPromptTemplateData{},
+			required: runPromptRequired,
+		},
+	}
+
+	for _, t := range templates {
+		if err := t.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// allowedToolsVia returns how the commands pass the allowed tools to the
+// agent. With the default, arg, the agent is only restricted if runPrompt
+// references them.
+func (s *AgentSpec) allowedToolsVia() string {
+	if !s.usesCommands() {
+		return ""
+	}
+	switch s.Commands.AllowedToolsVia {
+	case AllowedToolsViaEnv, AllowedToolsViaFile:
+		return s.Commands.AllowedToolsVia
+	}
+
+	tmpl, err := parseCommandTemplate("runPrompt", s.Commands.RunPrompt, shell.Default())
+	if err != nil || tmpl.Tree == nil {
+		return ""
+	}
+	referenced := map[string]bool{}
+	collectFields(tmpl.Tree.Root, true, referenced)
+	if referenced["AllowedToolArgs"] || referenced["AllowedTools"] {
+		return AllowedToolsViaArg
+	}
+	return ""
+}
+
+func (t commandTemplate) validate() error {
+	tmpl, err := parseCommandTemplate(t.name, t.text, shell.Default())
+	if err != nil {
+		return err
+	}
+
+	available := map[string]bool{}
+	for _, f := range reflect.VisibleFields(reflect.TypeOf(t.data)) {
+		available[f.Name] = true
+	}
+
+	referenced := map[string]bool{}
+	if tmpl.Tree != nil {
+		collectFields(tmpl.Tree.Root, true, referenced)
+	}
+
+	var unknown []string
+	for name := range referenced {
+		if !available[name] {
+			unknown = append(unknown, "."+name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("commands.%s references unknown field(s) %s: available fields are %s",
+			t.name, strings.Join(unknown, ", "), fieldList(available))
+	}
+
+	for _, group := range t.required {
+		found := false
+		for _, name := range group {
+			if referenced[name] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fields := make([]string, len(group))
+			for i, name := range group {
+				fields[i] = "{{ ." + name + " }}"
+			}
+			return fmt.Errorf("commands.%s must reference %s", t.name, strings.Join(fields, " or "))
+		}
+	}
+
+	return nil
+}
+
+// collectFields records the fields of the template data referenced below node.
+// Inside range and with blocks dot is no longer the template data, so only
+// fields referenced through $ are recorded there.
+func collectFields(node parse.Node, atRoot bool, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(child, atRoot, fields)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, atRoot, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectFields(cmd, atRoot, fields)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectFields(arg, atRoot, fields)
+		}
+	case *parse.FieldNode:
+		if atRoot {
+			fields[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			fields[n.Ident[1]] = true
+		}
+	case *parse.ChainNode:
+		collectFields(n.Node, atRoot, fields)
+	case *parse.IfNode:
+		collectFields(n.Pipe, atRoot, fields)
+		collectFields(n.List, atRoot, fields)
+		collectFields(n.ElseList, atRoot, fields)
+	case *parse.RangeNode:
+		collectFields(n.Pipe, atRoot, fields)
+		collectFields(n.List, false, fields)
+		collectFields(n.ElseList, atRoot, fields)
+	case *parse.WithNode:
+		collectFields(n.Pipe, atRoot, fields)
+		collectFields(n.List, false, fields)
+		collectFields(n.ElseList, atRoot, fields)
+	case *parse.TemplateNode:
+		collectFields(n.Pipe, atRoot, fields)
+	}
+}
+
+func fieldList(fields map[string]bool) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, "."+name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,218 @@
+package agent
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentSpecValidate(t *testing.T) {
+	tests := map[string]struct {
+		spec        AgentSpec
+		errContains string
+	}{
+		"valid": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer:    "--mcp-config {{ quote .File }}",
+				ArgTemplateAllowedTools: "mcp__{{ .ServerName }}__{{ .ToolName }}",
+				RunPrompt:               "agent {{ .McpServerFileArgs }} --allowed {{ joinArgs .AllowedTools }} {{ quote .Prompt }}",
+			}},
+		},
+		"server files list and root variable": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .URL }}",
+				RunPrompt:            `{{ range .McpServerFiles }}--config {{ quote . }} {{ end }}{{ with env "MODEL" }}--model {{ . }} {{ end }}{{ quote $.Prompt }}`,
+			}},
+		},
+		"acp agent is not checked": {
+			spec: AgentSpec{AcpConfig: &acpclient.AcpConfig{Cmd: "agent"}},
+		},
+		"openai agent is not checked": {
+			spec: AgentSpec{
+				Builtin:  &BuiltinRef{Type: "openai-agent"},
+				Commands: AgentCommands{ArgTemplateMcpServer: "{{ .URL }}"},
+			},
+		},
+		"ollama agent is not checked": {
+			spec: AgentSpec{
+				Builtin:  &BuiltinRef{Type: "ollama-agent"},
+				Commands: AgentCommands{ArgTemplateMcpServer: "{{ .URL }}"},
+			},
+		},
+		"missing run prompt": {
+			spec:        AgentSpec{Commands: AgentCommands{ArgTemplateMcpServer: "{{ .File }}"}},
+			errContains: "commands.runPrompt must be set",
+		},
+		"missing server template": {
+			spec:        AgentSpec{Commands: AgentCommands{RunPrompt: "{{ .McpServerFileArgs }} {{ .Prompt }}"}},
+			errContains: "commands.argTemplateMcpServer must be set",
+		},
+		"parse error": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "{{ .McpServerFileArgs }} {{ .Prompt }",
+			}},
+			errContains: "failed to parse commands.runPrompt",
+		},
+		"unknown function": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "{{ .McpServerFileArgs }} {{ shellescape .Prompt }}",
+			}},
+			errContains: `function "shellescape" not defined`,
+		},
+		"unknown field": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer:    "{{ .File }}",
+				ArgTemplateAllowedTools: "{{ .Server }}__{{ .Tool }}",
+				RunPrompt:               "{{ .McpServerFileArgs }} {{ .Prompt }}",
+			}},
+			errContains: "commands.argTemplateAllowedTools references unknown field(s) .Server, .Tool: available fields are .ServerName, .ToolName",
+		},
+		"missing prompt": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }}",
+			}},
+			errContains: "commands.runPrompt must reference {{ .Prompt }}",
+		},
+		"missing server files": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .Prompt }}",
+			}},
+			errContains: "commands.runPrompt must reference {{ .McpServerFileArgs }} or {{ .McpServerFiles }}",
+		},
+		"prompt via stdin": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }}",
+				PromptVia:            PromptViaStdin,
+			}},
+		},
+		"prompt via file": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }} --prompt-file {{ quote .PromptFile }}",
+				PromptVia:            PromptViaFile,
+			}},
+		},
+		"prompt via file without prompt file": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }} {{ quote .Prompt }}",
+				PromptVia:            PromptViaFile,
+			}},
+			errContains: "commands.runPrompt must reference {{ .PromptFile }}",
+		},
+		"unknown prompt via": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }} {{ .Prompt }}",
+				PromptVia:            "pipe",
+			}},
+			errContains: `commands.promptVia must be "arg", "stdin", or "file", got "pipe"`,
+		},
+		"allowed tools via env": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }} {{ quote .Prompt }}",
+				AllowedToolsVia:      AllowedToolsViaEnv,
+			}},
+		},
+		"allowed tools via file": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }} --allowed-tools {{ quote .AllowedToolsFile }} {{ quote .Prompt }}",
+				AllowedToolsVia:      AllowedToolsViaFile,
+			}},
+		},
+		"unknown allowed tools via": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }} {{ .Prompt }}",
+				AllowedToolsVia:      "stdin",
+			}},
+			errContains: `commands.allowedToolsVia must be "arg", "env", or "file", got "stdin"`,
+		},
+		"prompt file template": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }} {{ quote .Prompt }}",
+				PromptFileTemplate:   "- @{{ .Name }}",
+			}},
+		},
+		"prompt file template without file": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File }}",
+				RunPrompt:            "agent {{ .McpServerFileArgs }} {{ quote .Prompt }}",
+				PromptFileTemplate:   "- attached file",
+			}},
+			errContains: "commands.promptFileTemplate must reference {{ .Path }} or {{ .Name }}",
+		},
+		"server template without server": {
+			spec: AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "--mcp-config",
+				RunPrompt:            "agent {{ .McpServerFileArgs }} {{ .Prompt }}",
+			}},
+			errContains: "commands.argTemplateMcpServer must reference {{ .File }} or {{ .URL }}",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.spec.Validate()
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Setenv("MCPCHECKER_TEST_MODEL", "gpt-5")
+
+	tests := map[string]struct {
+		template string
+		expected string
+	}{
+		"quote": {
+			template: "{{ quote .Prompt }}",
+			expected: `'it'\''s "done"'`,
+		},
+		"json": {
+			template: "{{ json .Prompt }}",
+			expected: `"it's \"done\""`,
+		},
+		"env": {
+			template: `{{ env "MCPCHECKER_TEST_MODEL" }}`,
+			expected: "gpt-5",
+		},
+		"joinArgs": {
+			template: "{{ joinArgs .McpServerFiles }}",
+			expected: "'/tmp/a b.json' '/tmp/c.json'",
+		},
+	}
+
+	data := runPromptTemplateData{
+		McpServerFiles: []string{"/tmp/a b.json", "/tmp/c.json"},
+		Prompt:         `it's "done"`,
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := parseCommandTemplate("runPrompt", tc.template, shell.New("/bin/sh"))
+			require.NoError(t, err)
+
+			out := &bytes.Buffer{}
+			require.NoError(t, tmpl.Execute(out, data))
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
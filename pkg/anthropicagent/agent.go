@@ -0,0 +1,240 @@
+// Package anthropicagent is an agent that calls the Anthropic Messages API
+// directly, with the tools of MCP servers.
+package anthropicagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/openaiagent"
+)
+
+const (
+	// DefaultBaseURL is the URL of the Anthropic API
+	DefaultBaseURL = "https://api.anthropic.com"
+	// DefaultMaxTokens is the maximum number of tokens of each model response
+	DefaultMaxTokens = 4096
+
+	apiVersion = "2023-06-01"
+)
+
+type aiAgent struct {
+	*openaiagent.Toolbox
+	httpClient   *http.Client
+	baseURL      string
+	apiKey       string
+	model        string
+	systemPrompt string
+	maxTokens    int
+}
+
+func NewAIAgent(url, apiKey, model, systemPrompt string) (*aiAgent, error) {
+	if apiKey == "" || model == "" {
+		return nil, fmt.Errorf("API key and model name must both be provided to create an anthropic agent")
+	}
+	if url == "" {
+		url = DefaultBaseURL
+	}
+
+	return &aiAgent{
+		Toolbox:      &openaiagent.Toolbox{},
+		httpClient:   http.DefaultClient,
+		baseURL:      strings.TrimSuffix(url, "/"),
+		apiKey:       apiKey,
+		model:        model,
+		systemPrompt: systemPrompt,
+		maxTokens:    DefaultMaxTokens,
+	}, nil
+}
+
+type messagesRequest struct {
+	Model     string    `json:"model"`
+	MaxTokens int       `json:"max_tokens"`
+	System    string    `json:"system,omitempty"`
+	Messages  []message `json:"messages"`
+	Tools     []tool    `json:"tools,omitempty"`
+}
+
+type message struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+// contentBlock is a text, tool_use, or tool_result block of a message
+type contentBlock struct {
+	Type string `json:"type"`
+
+	Text string `json:"text,omitempty"`
+
+	// ID, Name, and Input are set for tool_use blocks. Input is kept as
+	// decoded so that an empty input is sent back as {}, not omitted.
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Input any    `json:"input,omitempty"`
+
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type messagesResponse struct {
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (o *aiAgent) Run(ctx context.Context, prompt string) (string, error) {
+	messages := []message{{
+		Role:    "user",
+		Content: []contentBlock{{Type: "text", Text: prompt}},
+	}}
+
+	// Get available tools from all MCP clients
+	var tools []tool
+	for _, t := range o.Tools() {
+		tools = append(tools, tool{
+			Name:        t.Name,
+			Description: t.Description,
+			InputSchema: openaiagent.ToolInputSchema(t),
+		})
+	}
+
+	// Agent loop - continue until we get a final response without tool calls
+	for iteration := 0; ; iteration++ {
+		if err := o.CheckIteration(iteration); err != nil {
+			return "", err
+		}
+
+		response, err := o.createMessage(ctx, messagesRequest{
+			Model:     o.model,
+			MaxTokens: o.maxTokens,
+			System:    o.systemPrompt,
+			Messages:  messages,
+			Tools:     tools,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create message: %w", err)
+		}
+
+		// Add the assistant's message to the conversation, tool_use blocks included
+		messages = append(messages, message{Role: "assistant", Content: response.Content})
+
+		var text []string
+		var calls []openaiagent.ToolCallRequest
+		for _, block := range response.Content {
+			switch block.Type {
+			case "text":
+				text = append(text, block.Text)
+			case "tool_use":
+				args, _ := block.Input.(map[string]any)
+				calls = append(calls, openaiagent.ToolCallRequest{ID: block.ID, Name: block.Name, Arguments: args})
+			}
+		}
+		content := strings.Join(text, "")
+
+		// If there are no tool calls, we're done
+		if len(calls) == 0 {
+			o.Emit(openaiagent.Event{Type: openaiagent.EventMessage, Text: content, Final: true})
+			return content, nil
+		}
+
+		if content != "" {
+			o.Emit(openaiagent.Event{Type: openaiagent.EventMessage, Text: content})
+		}
+
+		// Tool results are returned to the model in a single user message, in
+		// the order the model requested the calls
+		results := message{Role: "user"}
+		for i, result := range o.ExecuteToolCalls(ctx, calls) {
+			results.Content = append(results.Content, contentBlock{
+				Type:      "tool_result",
+				ToolUseID: calls[i].ID,
+				Content:   result,
+			})
+		}
+		messages = append(messages, results)
+	}
+}
+
+// CheckHealth checks that the API is reachable and accepts the API key, with
+// a request that lists a single model
+func (o *aiAgent) CheckHealth(ctx context.Context) error {
+	_, status, err := o.do(ctx, http.MethodGet, "/v1/models?limit=1", nil)
+	if err != nil && (status == http.StatusUnauthorized || status == http.StatusForbidden) {
+		return fmt.Errorf("API key was rejected: %w", err)
+	}
+	if err != nil && status == 0 {
+		return fmt.Errorf("failed to reach %s: %w", o.baseURL, err)
+	}
+	// Other errors, such as a gateway that does not list models, do not tell
+	// that tasks would fail
+	return nil
+}
+
+// createMessage sends a request to the Messages API
+func (o *aiAgent) createMessage(ctx context.Context, request messagesRequest) (*messagesResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	data, _, err := o.do(ctx, http.MethodPost, "/v1/messages", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response messagesResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &response, nil
+}
+
+// do sends a request to the API and returns the body of a successful
+// response. The status is zero if no response was received.
+func (o *aiAgent) do(ctx context.Context, method, path string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, o.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", o.apiKey)
+	req.Header.Set("anthropic-version", apiVersion)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr errorResponse
+		if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Error.Message != "" {
+			return nil, resp.StatusCode, fmt.Errorf("%s: %s (status %d)", apiErr.Error.Type, apiErr.Error.Message, resp.StatusCode)
+		}
+		return nil, resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	return data, resp.StatusCode, nil
+}
@@ -0,0 +1,180 @@
+package anthropicagent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startMCPServer starts an MCP server with an echo tool that returns its text
+// argument
+func startMCPServer(t *testing.T) string {
+	t.Helper()
+
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(s, &mcp.Tool{Name: "echo", Description: "Echoes text"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Text string `json:"text"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: args.Text}}}, nil, nil
+	})
+
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s }, nil))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// startModel starts a Messages API server that first calls the echo tool, then
+// answers with the text of the tool result. It records the requests it got.
+func startModel(t *testing.T) (string, *[]map[string]any) {
+	t.Helper()
+
+	var requests []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+		assert.Equal(t, apiVersion, r.Header.Get("anthropic-version"))
+
+		var request map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+
+		content := []map[string]any{
+			{"type": "text", "text": "Let me echo that."},
+			{"type": "tool_use", "id": "toolu_1", "name": "echo", "input": map[string]any{"text": "hello"}},
+		}
+		stopReason := "tool_use"
+		if len(requests) > 1 {
+			messages := request["messages"].([]any)
+			last := messages[len(messages)-1].(map[string]any)
+			result := last["content"].([]any)[0].(map[string]any)
+			content = []map[string]any{{"type": "text", "text": "echoed " + result["content"].(string)}}
+			stopReason = "end_turn"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":          "msg_test",
+			"type":        "message",
+			"role":        "assistant",
+			"content":     content,
+			"stop_reason": stopReason,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &requests
+}
+
+func TestRunToolCall(t *testing.T) {
+	modelURL, requests := startModel(t)
+	mcpURL := startMCPServer(t)
+
+	agent, err := NewAIAgent(modelURL, "test-key", "claude-test", "Be brief.")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = agent.Close() })
+	require.NoError(t, agent.AddMCPServer(context.Background(), mcpURL))
+
+	out, err := agent.Run(context.Background(), "echo hello")
+	require.NoError(t, err)
+	assert.Contains(t, out, "echoed")
+	assert.Contains(t, out, "hello")
+
+	require.Len(t, *requests, 2)
+	first := (*requests)[0]
+	assert.Equal(t, "claude-test", first["model"])
+	assert.Equal(t, "Be brief.", first["system"])
+	assert.EqualValues(t, DefaultMaxTokens, first["max_tokens"])
+
+	// The tool is translated to the Messages API format
+	tools := first["tools"].([]any)
+	require.Len(t, tools, 1)
+	tool := tools[0].(map[string]any)
+	assert.Equal(t, "echo", tool["name"])
+	assert.Equal(t, "Echoes text", tool["description"])
+	schema := tool["input_schema"].(map[string]any)
+	assert.Equal(t, "object", schema["type"])
+	assert.Contains(t, schema["properties"], "text")
+
+	// The tool_use block is sent back, followed by its result
+	messages := (*requests)[1]["messages"].([]any)
+	require.Len(t, messages, 3)
+	assistant := messages[1].(map[string]any)
+	assert.Equal(t, "assistant", assistant["role"])
+	assert.Len(t, assistant["content"], 2)
+	result := messages[2].(map[string]any)
+	assert.Equal(t, "user", result["role"])
+	block := result["content"].([]any)[0].(map[string]any)
+	assert.Equal(t, "tool_result", block["type"])
+	assert.Equal(t, "toolu_1", block["tool_use_id"])
+}
+
+func TestRunAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	agent, err := NewAIAgent(srv.URL, "bad-key", "claude-test", "")
+	require.NoError(t, err)
+
+	_, err = agent.Run(context.Background(), "hello")
+	assert.ErrorContains(t, err, "authentication_error: invalid x-api-key (status 401)")
+}
+
+func TestNewAIAgent(t *testing.T) {
+	_, err := NewAIAgent("", "", "claude-test", "")
+	assert.Error(t, err)
+
+	agent, err := NewAIAgent("", "key", "claude-test", "")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBaseURL, agent.baseURL)
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := map[string]struct {
+		status      int
+		body        string
+		errContains string
+	}{
+		"valid key": {
+			status: http.StatusOK,
+			body:   `{"data":[{"id":"claude-test"}]}`,
+		},
+		"rejected key": {
+			status:      http.StatusUnauthorized,
+			body:        `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			errContains: "API key was rejected: authentication_error: invalid x-api-key",
+		},
+		"models not listed by gateway": {
+			status: http.StatusNotFound,
+			body:   `not found`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/models", r.URL.Path)
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			agent, err := NewAIAgent(srv.URL, "key", "claude-test", "")
+			require.NoError(t, err)
+
+			err = agent.CheckHealth(context.Background())
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+// NewCoverageCmd creates the coverage command
+func NewCoverageCmd() *cobra.Command {
+	var mcpConfigFiles []string
+	var mcpProfile string
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "coverage <results-file>",
+		Short: "Show which MCP tools, resources, and prompts a suite exercises",
+		Long: `Connect to the MCP servers in the given config to list what they expose, and
+report which tools, resources, and prompts were used by at least one passing task.
+
+Items used only by failing tasks, or not used at all, are untested surface area.
+
+Example:
+  mcpchecker coverage results.json --mcp-config mcp-config.yaml
+  mcpchecker coverage results.json --mcp-config mcp-config.yaml --output json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			evalResults, err := results.Load(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			cfg, err := mcpproxy.LoadConfigFiles(mcpConfigFiles, mcpProfile)
+			if err != nil {
+				return fmt.Errorf("failed to load MCP config: %w", err)
+			}
+
+			surfaces, err := discoverSurfaces(cmd.Context(), cfg)
+			if err != nil {
+				return err
+			}
+
+			cov := results.CalculateCoverage(evalResults, surfaces)
+
+			switch outputFormat {
+			case "json":
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(cov)
+			case "text":
+				outputTextCoverage(cov)
+			default:
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&mcpConfigFiles, "mcp-config", nil, "MCP config file (can be repeated, later files take precedence)")
+	cmd.Flags().StringVar(&mcpProfile, "mcp-profile", "", "Named profile to select from the MCP config files")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
+	_ = cmd.MarkFlagRequired("mcp-config")
+
+	return cmd
+}
+
+// discoverSurfaces connects to every enabled server and lists what it exposes
+func discoverSurfaces(ctx context.Context, cfg *mcpproxy.MCPConfig) (map[string]*results.ServerSurface, error) {
+	surfaces := make(map[string]*results.ServerSurface)
+	for name, serverCfg := range cfg.GetEnabledServers() {
+		surface, err := discoverSurface(ctx, serverCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect server %q: %w", name, err)
+		}
+		surfaces[name] = surface
+	}
+
+	return surfaces, nil
+}
+
+func discoverSurface(ctx context.Context, cfg *mcpproxy.ServerConfig) (*results.ServerSurface, error) {
+	cs, err := mcpproxy.Connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer cs.Close()
+
+	surface := &results.ServerSurface{}
+	caps := cs.InitializeResult().Capabilities
+
+	if caps.Tools != nil {
+		for t, err := range cs.Tools(ctx, &mcp.ListToolsParams{}) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tools: %w", err)
+			}
+			surface.Tools = append(surface.Tools, t.Name)
+		}
+	}
+
+	if caps.Resources != nil {
+		for r, err := range cs.Resources(ctx, &mcp.ListResourcesParams{}) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to list resources: %w", err)
+			}
+			surface.Resources = append(surface.Resources, r.URI)
+		}
+		for rt, err := range cs.ResourceTemplates(ctx, &mcp.ListResourceTemplatesParams{}) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to list resource templates: %w", err)
+			}
+			surface.ResourceTemplates = append(surface.ResourceTemplates, rt.URITemplate)
+		}
+	}
+
+	if caps.Prompts != nil {
+		for p, err := range cs.Prompts(ctx, &mcp.ListPromptsParams{}) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to list prompts: %w", err)
+			}
+			surface.Prompts = append(surface.Prompts, p.Name)
+		}
+	}
+
+	return surface, nil
+}
+
+func outputTextCoverage(cov *results.Coverage) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+	yellow := color.New(color.FgYellow)
+	bold := color.New(color.Bold)
+
+	bold.Println("=== MCP Coverage ===")
+
+	for _, sc := range cov.Servers {
+		fmt.Println()
+		bold.Printf("%s: %s\n", sc.Server, formatCoverage(sc.Covered, sc.Total))
+
+		sections := []struct {
+			title string
+			items []results.ItemCoverage
+		}{
+			{"Tools", sc.Tools},
+			{"Resources", sc.Resources},
+			{"Prompts", sc.Prompts},
+		}
+		for _, section := range sections {
+			if len(section.items) == 0 {
+				continue
+			}
+
+			covered := 0
+			for _, item := range section.items {
+				if item.Covered() {
+					covered++
+				}
+			}
+			fmt.Printf("  %s: %s\n", section.title, formatCoverage(covered, len(section.items)))
+
+			for _, item := range section.items {
+				switch {
+				case item.Covered():
+					green.Printf("    ✓ %s", item.Name)
+					fmt.Printf(" (%d passing task(s))\n", len(item.PassingTasks))
+				case len(item.FailingTasks) > 0:
+					yellow.Printf("    ~ %s", item.Name)
+					fmt.Printf(" (only failing tasks: %s)\n", strings.Join(item.FailingTasks, ", "))
+				default:
+					red.Printf("    ✗ %s", item.Name)
+					fmt.Println(" (untested)")
+				}
+			}
+		}
+	}
+
+	fmt.Println()
+	bold.Printf("Total: %s\n", formatCoverage(cov.Covered, cov.Total))
+}
+
+func formatCoverage(covered, total int) string {
+	if total == 0 {
+		return "0/0"
+	}
+	return fmt.Sprintf("%d/%d (%.1f%%)", covered, total, float64(covered)/float64(total)*100)
+}
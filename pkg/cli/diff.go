@@ -6,6 +6,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
 	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +18,12 @@ type DiffResult struct {
 	Improvements []TaskDiff
 	New          []TaskDiff
 	Removed      []TaskDiff
+	// UnexpectedPasses are the tasks expected to fail that passed in the
+	// current run
+	UnexpectedPasses []TaskDiff
+	// EnvironmentChanges lists the environment info reported by extensions
+	// that differs between the runs, whose results may not be comparable
+	EnvironmentChanges []string
 }
 
 // TaskDiff holds the diff for a single task
@@ -24,11 +31,15 @@ type TaskDiff struct {
 	TaskName           string
 	BasePassed         bool
 	HeadPassed         bool
+	HeadStatus         eval.TaskStatus
 	BaseAssertions     int
 	HeadAssertions     int
 	BaseAssertionTotal int
 	HeadAssertionTotal int
 	FailureReason      string
+	// Owner and Links of the task in the current run
+	Owner string
+	Links []task.TaskLink
 }
 
 // NewDiffCmd creates the diff command
@@ -36,6 +47,8 @@ func NewDiffCmd() *cobra.Command {
 	var outputFormat string
 	var baseFile string
 	var currentFile string
+	var quiet bool
+	var filters queryFlags
 
 	cmd := &cobra.Command{
 		Use:   "diff --base <results-file> --current <results-file>",
@@ -44,6 +57,8 @@ func NewDiffCmd() *cobra.Command {
 
 Shows regressions, improvements, and overall pass rate changes.
 Useful for posting on pull requests to show impact of changes.
+--label, --difficulty, and --status compare a subset of the tasks, selected
+in both runs.
 
 Example:
   mcpchecker diff --base results-main.json --current results-pr.json
@@ -51,12 +66,17 @@ Example:
 		Args:         cobra.NoArgs,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			baseResults, err := results.Load(baseFile)
+			query, err := filters.query("")
+			if err != nil {
+				return err
+			}
+
+			baseResults, err := results.LoadQuery(baseFile, query)
 			if err != nil {
 				return fmt.Errorf("failed to load base results: %w", err)
 			}
 
-			currentResults, err := results.Load(currentFile)
+			currentResults, err := results.LoadQuery(currentFile, query)
 			if err != nil {
 				return fmt.Errorf("failed to load current results: %w", err)
 			}
@@ -65,7 +85,7 @@ Example:
 
 			switch outputFormat {
 			case "text":
-				outputTextDiff(diff)
+				outputTextDiff(diff, quiet)
 			case "markdown":
 				outputMarkdownDiff(diff)
 			default:
@@ -79,6 +99,8 @@ Example:
 	cmd.Flags().StringVar(&baseFile, "base", "", "Base results file (e.g., main branch)")
 	cmd.Flags().StringVar(&currentFile, "current", "", "Current results file (e.g., PR branch)")
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, markdown)")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only show regressions and the summary in text output")
+	filters.register(cmd)
 
 	_ = cmd.MarkFlagRequired("base")
 	_ = cmd.MarkFlagRequired("current")
@@ -94,24 +116,30 @@ func calculateDiff(baseFile, currentFile string, baseResults, currentResults []*
 		Improvements: make([]TaskDiff, 0),
 		New:          make([]TaskDiff, 0),
 		Removed:      make([]TaskDiff, 0),
-	}
 
-	baseMap := make(map[string]*eval.EvalResult)
-	for _, r := range baseResults {
-		baseMap[r.TaskName] = r
-	}
+		UnexpectedPasses: make([]TaskDiff, 0),
 
-	currentMap := make(map[string]*eval.EvalResult)
-	for _, r := range currentResults {
-		currentMap[r.TaskName] = r
+		EnvironmentChanges: results.Environment(baseResults).Changes(results.Environment(currentResults)),
 	}
 
+	baseMap := results.IndexByTaskName(baseResults)
+	currentMap := results.IndexByTaskName(currentResults)
+
 	for _, current := range currentResults {
+		if current.Status() == eval.TaskStatusUnexpectedPass {
+			diff.UnexpectedPasses = append(diff.UnexpectedPasses, TaskDiff{
+				TaskName:   current.TaskName,
+				HeadPassed: true,
+				HeadStatus: eval.TaskStatusUnexpectedPass,
+			})
+		}
+
 		base, exists := baseMap[current.TaskName]
 		if !exists {
 			diff.New = append(diff.New, TaskDiff{
 				TaskName:           current.TaskName,
 				HeadPassed:         current.TaskPassed && current.AllAssertionsPassed,
+				HeadStatus:         current.Status(),
 				HeadAssertions:     results.PassedAssertions(current),
 				HeadAssertionTotal: results.TotalAssertions(current),
 			})
@@ -125,11 +153,20 @@ func calculateDiff(baseFile, currentFile string, baseResults, currentResults []*
 			TaskName:           current.TaskName,
 			BasePassed:         basePassed,
 			HeadPassed:         currentPassed,
+			HeadStatus:         current.Status(),
 			BaseAssertions:     results.PassedAssertions(base),
 			HeadAssertions:     results.PassedAssertions(current),
 			BaseAssertionTotal: results.TotalAssertions(base),
 			HeadAssertionTotal: results.TotalAssertions(current),
 			FailureReason:      results.FailureReason(current),
+			Owner:              current.Owner,
+			Links:              current.Links,
+		}
+
+		// Skipped tasks and tasks expected to fail in either run can neither
+		// regress nor improve
+		if !isPassOrFail(base) || !isPassOrFail(current) {
+			continue
 		}
 
 		if basePassed && !currentPassed {
@@ -153,7 +190,33 @@ func calculateDiff(baseFile, currentFile string, baseResults, currentResults []*
 	return diff
 }
 
-func outputTextDiff(diff DiffResult) {
+// isPassOrFail returns whether a result is a plain pass or failure, rather
+// than a skipped task or a task expected to fail
+func isPassOrFail(r *eval.EvalResult) bool {
+	status := r.Status()
+	return status == eval.TaskStatusPassed || status == eval.TaskStatusFailed
+}
+
+// statusLabel returns the label of a task status in diff output
+func statusLabel(status eval.TaskStatus) string {
+	switch status {
+	case eval.TaskStatusPassed:
+		return "PASSED"
+	case eval.TaskStatusSkipped:
+		return "SKIPPED"
+	case eval.TaskStatusNotRun:
+		return "NOT RUN"
+	case eval.TaskStatusExpectedFailure:
+		return "EXPECTED FAILURE"
+	case eval.TaskStatusUnexpectedPass:
+		return "UNEXPECTED PASS"
+	default:
+		return "FAILED"
+	}
+}
+
+// outputTextDiff prints the diff. In quiet mode only regressions and the summary are shown.
+func outputTextDiff(diff DiffResult, quiet bool) {
 	green := color.New(color.FgGreen)
 	red := color.New(color.FgRed)
 	yellow := color.New(color.FgYellow)
@@ -162,6 +225,15 @@ func outputTextDiff(diff DiffResult) {
 	_, _ = bold.Println("=== Evaluation Diff ===")
 	fmt.Println()
 
+	// Environment changes, shown first as they may explain the rest
+	if len(diff.EnvironmentChanges) > 0 {
+		_, _ = yellow.Println("Environment changed, results may not be comparable:")
+		for _, change := range diff.EnvironmentChanges {
+			_, _ = yellow.Printf("  ~ %s\n", change)
+		}
+		fmt.Println()
+	}
+
 	// Regressions
 	if len(diff.Regressions) > 0 {
 		_, _ = red.Printf("Regressions (%d):\n", len(diff.Regressions))
@@ -170,12 +242,18 @@ func outputTextDiff(diff DiffResult) {
 			if r.FailureReason != "" {
 				fmt.Printf("      %s\n", r.FailureReason)
 			}
+			if r.Owner != "" {
+				fmt.Printf("      owner: %s\n", r.Owner)
+			}
+			for _, link := range r.Links {
+				fmt.Printf("      link: %s\n", link)
+			}
 		}
 		fmt.Println()
 	}
 
 	// Improvements
-	if len(diff.Improvements) > 0 {
+	if len(diff.Improvements) > 0 && !quiet {
 		_, _ = green.Printf("Improvements (%d):\n", len(diff.Improvements))
 		for _, r := range diff.Improvements {
 			_, _ = green.Printf("  ✓ %s: FAILED → PASSED\n", r.TaskName)
@@ -183,21 +261,34 @@ func outputTextDiff(diff DiffResult) {
 		fmt.Println()
 	}
 
+	// Unexpected passes, shown in quiet mode as the expected failure
+	// should be removed
+	if len(diff.UnexpectedPasses) > 0 {
+		_, _ = yellow.Printf("Unexpected Passes (%d):\n", len(diff.UnexpectedPasses))
+		for _, r := range diff.UnexpectedPasses {
+			_, _ = yellow.Printf("  ! %s: expected to fail, but PASSED\n", r.TaskName)
+		}
+		fmt.Println()
+	}
+
 	// New tasks
-	if len(diff.New) > 0 {
+	if len(diff.New) > 0 && !quiet {
 		_, _ = yellow.Printf("New Tasks (%d):\n", len(diff.New))
 		for _, r := range diff.New {
-			if r.HeadPassed {
+			switch r.HeadStatus {
+			case eval.TaskStatusPassed:
 				_, _ = green.Printf("  + %s: PASSED\n", r.TaskName)
-			} else {
+			case eval.TaskStatusFailed:
 				_, _ = red.Printf("  + %s: FAILED\n", r.TaskName)
+			default:
+				_, _ = yellow.Printf("  + %s: %s\n", r.TaskName, statusLabel(r.HeadStatus))
 			}
 		}
 		fmt.Println()
 	}
 
 	// Removed tasks
-	if len(diff.Removed) > 0 {
+	if len(diff.Removed) > 0 && !quiet {
 		_, _ = yellow.Printf("Removed Tasks (%d):\n", len(diff.Removed))
 		for _, r := range diff.Removed {
 			fmt.Printf("  - %s\n", r.TaskName)
@@ -222,6 +313,32 @@ func outputTextDiff(diff DiffResult) {
 		diff.BaseStats.AssertionsPassed, diff.BaseStats.AssertionsTotal,
 		diff.HeadStats.AssertionsPassed, diff.HeadStats.AssertionsTotal)
 	printChange(assertionChange)
+
+	categories := results.JudgeCategories(diff.BaseStats.JudgeFailures, diff.HeadStats.JudgeFailures)
+	if len(categories) > 0 {
+		fmt.Println()
+		fmt.Println("Judge failures:")
+		for _, category := range categories {
+			base := diff.BaseStats.JudgeFailures[category]
+			head := diff.HeadStats.JudgeFailures[category]
+			fmt.Printf("  %s: %d → %d  ", category, base, head)
+			printCountChange(head - base)
+		}
+	}
+}
+
+// printCountChange prints the change in a failure count, where fewer is better
+func printCountChange(change int) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+
+	if change < 0 {
+		_, _ = green.Printf("%d\n", change)
+	} else if change > 0 {
+		_, _ = red.Printf("+%d\n", change)
+	} else {
+		fmt.Println("0")
+	}
 }
 
 func printChange(change float64) {
@@ -253,6 +370,21 @@ func outputMarkdownDiff(diff DiffResult) {
 		diff.BaseStats.AssertionsPassed, diff.BaseStats.AssertionsTotal, diff.BaseStats.AssertionPassRate*100,
 		diff.HeadStats.AssertionsPassed, diff.HeadStats.AssertionsTotal, diff.HeadStats.AssertionPassRate*100,
 		formatChangeMarkdown(assertionChange))
+	for _, category := range results.JudgeCategories(diff.BaseStats.JudgeFailures, diff.HeadStats.JudgeFailures) {
+		base := diff.BaseStats.JudgeFailures[category]
+		head := diff.HeadStats.JudgeFailures[category]
+		fmt.Printf("| Judge: %s | %d | %d | %s |\n", category, base, head, formatCountChangeMarkdown(head-base))
+	}
+
+	// Environment changes
+	if len(diff.EnvironmentChanges) > 0 {
+		fmt.Println()
+		fmt.Println("#### ⚠️ Environment Changed")
+		fmt.Println("Results may not be comparable:")
+		for _, change := range diff.EnvironmentChanges {
+			fmt.Printf("- `%s`\n", change)
+		}
+	}
 
 	// Regressions
 	if len(diff.Regressions) > 0 {
@@ -263,7 +395,13 @@ func outputMarkdownDiff(diff DiffResult) {
 			if r.FailureReason != "" {
 				fmt.Printf(" - %s", r.FailureReason)
 			}
+			if r.Owner != "" {
+				fmt.Printf(" (owner: %s)", r.Owner)
+			}
 			fmt.Println()
+			for _, link := range r.Links {
+				fmt.Printf("  - %s\n", markdownLink(link))
+			}
 		}
 	}
 
@@ -276,16 +414,21 @@ func outputMarkdownDiff(diff DiffResult) {
 		}
 	}
 
+	// Unexpected passes
+	if len(diff.UnexpectedPasses) > 0 {
+		fmt.Println()
+		fmt.Printf("#### ⚠️ Unexpected Passes (%d)\n", len(diff.UnexpectedPasses))
+		for _, r := range diff.UnexpectedPasses {
+			fmt.Printf("- `%s`: expected to fail, but PASSED\n", r.TaskName)
+		}
+	}
+
 	// New tasks
 	if len(diff.New) > 0 {
 		fmt.Println()
 		fmt.Printf("#### 🆕 New Tasks (%d)\n", len(diff.New))
 		for _, r := range diff.New {
-			status := "PASSED"
-			if !r.HeadPassed {
-				status = "FAILED"
-			}
-			fmt.Printf("- `%s`: %s\n", r.TaskName, status)
+			fmt.Printf("- `%s`: %s\n", r.TaskName, statusLabel(r.HeadStatus))
 		}
 	}
 
@@ -299,6 +442,14 @@ func outputMarkdownDiff(diff DiffResult) {
 	}
 }
 
+// markdownLink formats a task link as a markdown link
+func markdownLink(link task.TaskLink) string {
+	if link.Name == "" {
+		return fmt.Sprintf("<%s>", link.URL)
+	}
+	return fmt.Sprintf("[%s](%s)", link.Name, link.URL)
+}
+
 func formatChangeMarkdown(change float64) string {
 	if change > 0 {
 		return fmt.Sprintf("🟢 +%.1f%%", change*100)
@@ -307,3 +458,13 @@ func formatChangeMarkdown(change float64) string {
 	}
 	return "➖ 0.0%"
 }
+
+// formatCountChangeMarkdown formats the change in a failure count, where fewer is better
+func formatCountChangeMarkdown(change int) string {
+	if change < 0 {
+		return fmt.Sprintf("🟢 %d", change)
+	} else if change > 0 {
+		return fmt.Sprintf("🔴 +%d", change)
+	}
+	return "➖ 0"
+}
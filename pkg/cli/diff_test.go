@@ -2,10 +2,12 @@ package cli
 
 import (
 	"bytes"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 )
 
 func TestDiffCommand(t *testing.T) {
@@ -123,6 +125,34 @@ func TestCalculateDiffRegressions(t *testing.T) {
 	}
 }
 
+func TestCalculateDiffExpectedFailures(t *testing.T) {
+	// task-2 now passes and task-3 still fails, but both are expected to fail
+	baseResults := sampleResults()
+	headResults := sampleResultsImproved()
+	for _, results := range [][]*eval.EvalResult{baseResults, headResults} {
+		results[1].ExpectedFailure = true
+		results[2].ExpectedFailure = true
+	}
+	// task-1 regresses, but is skipped
+	headResults[0] = &eval.EvalResult{TaskName: "task-1", SkipReason: "cluster unavailable"}
+
+	diff := calculateDiff("base.json", "head.json", baseResults, headResults)
+
+	if len(diff.Regressions) != 0 || len(diff.Improvements) != 0 {
+		t.Errorf("regressions, improvements = %d, %d, want 0, 0", len(diff.Regressions), len(diff.Improvements))
+	}
+	if len(diff.UnexpectedPasses) != 1 || diff.UnexpectedPasses[0].TaskName != "task-2" {
+		t.Errorf("UnexpectedPasses = %+v, want task-2", diff.UnexpectedPasses)
+	}
+	if diff.HeadStats.TasksSkipped != 1 || diff.HeadStats.TasksUnexpectedPassed != 1 || diff.HeadStats.TasksExpectedFailed != 1 {
+		t.Errorf("head stats = %+v, want 1 skipped, 1 unexpected pass, 1 expected failure", diff.HeadStats)
+	}
+
+	// Just ensure it doesn't panic
+	outputTextDiff(diff, false)
+	outputMarkdownDiff(diff)
+}
+
 func TestCalculateDiffNoChanges(t *testing.T) {
 	results := sampleResults()
 
@@ -233,3 +263,95 @@ func sampleResultsImproved() []*eval.EvalResult {
 		},
 	}
 }
+
+func TestCalculateDiffJudgeFailures(t *testing.T) {
+	baseResults := sampleResults()
+	baseResults[2].TaskJudgeCategory = "semantic_mismatch"
+	headResults := sampleResults()
+
+	diff := calculateDiff("base.json", "head.json", baseResults, headResults)
+
+	if diff.BaseStats.JudgeFailures["semantic_mismatch"] != 1 {
+		t.Errorf("BaseStats.JudgeFailures = %v, want semantic_mismatch: 1", diff.BaseStats.JudgeFailures)
+	}
+	if len(diff.HeadStats.JudgeFailures) != 0 {
+		t.Errorf("HeadStats.JudgeFailures = %v, want none", diff.HeadStats.JudgeFailures)
+	}
+
+	// Just ensure it doesn't panic
+	outputTextDiff(diff, false)
+	outputTextDiff(diff, true)
+	outputMarkdownDiff(diff)
+}
+
+func TestCalculateDiffEnvironmentChanges(t *testing.T) {
+	baseResults := sampleResults()
+	headResults := sampleResults()
+	for _, r := range baseResults {
+		r.Environment = eval.Environment{"kube": {"clusterVersion": "v1.29.4"}}
+	}
+	for _, r := range headResults {
+		r.Environment = eval.Environment{"kube": {"clusterVersion": "v1.30.2"}}
+	}
+
+	diff := calculateDiff("base.json", "head.json", baseResults, headResults)
+
+	want := []string{"kube.clusterVersion: v1.29.4 -> v1.30.2"}
+	if !slices.Equal(diff.EnvironmentChanges, want) {
+		t.Errorf("EnvironmentChanges = %v, want %v", diff.EnvironmentChanges, want)
+	}
+
+	// Just ensure it doesn't panic
+	outputTextDiff(diff, false)
+	outputMarkdownDiff(diff)
+
+	if diff := calculateDiff("base.json", "head.json", baseResults, baseResults); len(diff.EnvironmentChanges) != 0 {
+		t.Errorf("EnvironmentChanges = %v, want none for the same environment", diff.EnvironmentChanges)
+	}
+}
+
+func TestFormatCountChangeMarkdown(t *testing.T) {
+	tests := map[int]string{
+		-2: "🟢 -2",
+		0:  "➖ 0",
+		3:  "🔴 +3",
+	}
+
+	for change, want := range tests {
+		if got := formatCountChangeMarkdown(change); got != want {
+			t.Errorf("formatCountChangeMarkdown(%d) = %q, want %q", change, got, want)
+		}
+	}
+}
+
+func TestCalculateDiffOwnerAndLinks(t *testing.T) {
+	baseResults := sampleResultsImproved()
+	headResults := sampleResults()
+	headResults[1].Owner = "@platform-team"
+	headResults[1].Links = []task.TaskLink{{Name: "runbook", URL: "https://example.com/runbooks/pods"}}
+
+	diff := calculateDiff("base.json", "head.json", baseResults, headResults)
+
+	if len(diff.Regressions) != 1 {
+		t.Fatalf("len(Regressions) = %d, want 1", len(diff.Regressions))
+	}
+	if diff.Regressions[0].Owner != "@platform-team" {
+		t.Errorf("Regressions[0].Owner = %q, want @platform-team", diff.Regressions[0].Owner)
+	}
+	if len(diff.Regressions[0].Links) != 1 {
+		t.Errorf("len(Regressions[0].Links) = %d, want 1", len(diff.Regressions[0].Links))
+	}
+
+	// Just ensure it doesn't panic
+	outputTextDiff(diff, false)
+	outputMarkdownDiff(diff)
+}
+
+func TestMarkdownLink(t *testing.T) {
+	if got := markdownLink(task.TaskLink{Name: "runbook", URL: "https://example.com/r"}); got != "[runbook](https://example.com/r)" {
+		t.Errorf("markdownLink() = %q", got)
+	}
+	if got := markdownLink(task.TaskLink{URL: "https://example.com/r"}); got != "<https://example.com/r>" {
+		t.Errorf("markdownLink() = %q", got)
+	}
+}
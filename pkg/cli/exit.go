@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// Exit codes of the check command, so CI pipelines can tell failures apart
+// without parsing the results. Other commands exit with ExitConfigError on
+// invalid flags or args, and with 1 on any other error.
+const (
+	ExitOK                = 0
+	ExitTaskFailures      = 1
+	ExitAssertionFailures = 2
+	ExitInfraError        = 3
+	ExitConfigError       = 4
+)
+
+// ExitError is an error that sets a specific exit code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for an error returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return 1
+}
+
+// runExitCode returns the exit code for the results of a completed run.
+// Skipped, quarantined, and expected to fail tasks are ignored. A task that could not be set up or whose agent
+// failed to execute is an infrastructure error, which takes precedence over
+// task failures, which take precedence over assertion-only failures.
+func runExitCode(evalResults []*eval.EvalResult) int {
+	code := ExitOK
+	for _, r := range evalResults {
+		if !r.Counted() {
+			continue
+		}
+
+		switch {
+		// The agent never ran when setup failed
+		case r.AgentExecutionError || (!r.TaskPassed && r.AgentOutput == nil):
+			return ExitInfraError
+		case !r.TaskPassed:
+			code = ExitTaskFailures
+		case !r.AllAssertionsPassed && code == ExitOK:
+			code = ExitAssertionFailures
+		}
+	}
+
+	return code
+}
+
+// classifyRunError wraps an error from the eval runner with its exit code
+func classifyRunError(err error) error {
+	var configErr *eval.ConfigError
+	if errors.As(err, &configErr) {
+		return &ExitError{Code: ExitConfigError, Err: err}
+	}
+
+	return &ExitError{Code: ExitInfraError, Err: err}
+}
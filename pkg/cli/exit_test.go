@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+func TestRunExitCode(t *testing.T) {
+	ran := &task.PhaseOutput{Success: true}
+	passed := &eval.EvalResult{TaskPassed: true, AllAssertionsPassed: true, AgentOutput: ran}
+	taskFailed := &eval.EvalResult{TaskPassed: false, AgentOutput: ran}
+	assertionFailed := &eval.EvalResult{TaskPassed: true, AllAssertionsPassed: false, AgentOutput: ran}
+	setupFailed := &eval.EvalResult{TaskPassed: false, TaskError: "failed to setup task"}
+	agentFailed := &eval.EvalResult{TaskPassed: false, AgentExecutionError: true, AgentOutput: ran}
+	quarantined := &eval.EvalResult{TaskPassed: false, AgentOutput: ran, Quarantined: true}
+	skipped := &eval.EvalResult{SkipReason: "flaky cluster"}
+	expectedFailure := &eval.EvalResult{TaskPassed: false, AgentOutput: ran, ExpectedFailure: true}
+
+	tests := map[string]struct {
+		results []*eval.EvalResult
+		want    int
+	}{
+		"no results":                  {want: ExitOK},
+		"all passed":                  {results: []*eval.EvalResult{passed, passed}, want: ExitOK},
+		"task failure":                {results: []*eval.EvalResult{passed, taskFailed}, want: ExitTaskFailures},
+		"assertion failure only":      {results: []*eval.EvalResult{assertionFailed, passed}, want: ExitAssertionFailures},
+		"task failure over assertion": {results: []*eval.EvalResult{assertionFailed, taskFailed}, want: ExitTaskFailures},
+		"setup failure":               {results: []*eval.EvalResult{taskFailed, setupFailed}, want: ExitInfraError},
+		"agent failure":               {results: []*eval.EvalResult{assertionFailed, agentFailed}, want: ExitInfraError},
+		"quarantined failure ignored": {results: []*eval.EvalResult{passed, quarantined}, want: ExitOK},
+		"skipped ignored":             {results: []*eval.EvalResult{passed, skipped}, want: ExitOK},
+		"expected failure ignored":    {results: []*eval.EvalResult{passed, expectedFailure}, want: ExitOK},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := runExitCode(tc.results); got != tc.want {
+				t.Errorf("runExitCode() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := ExitCode(nil); got != ExitOK {
+		t.Errorf("ExitCode(nil) = %d, want %d", got, ExitOK)
+	}
+	if got := ExitCode(errors.New("boom")); got != 1 {
+		t.Errorf("ExitCode(plain error) = %d, want 1", got)
+	}
+
+	wrapped := fmt.Errorf("context: %w", &ExitError{Code: ExitAssertionFailures, Err: errors.New("boom")})
+	if got := ExitCode(wrapped); got != ExitAssertionFailures {
+		t.Errorf("ExitCode(wrapped) = %d, want %d", got, ExitAssertionFailures)
+	}
+
+	if got := ExitCode(classifyRunError(&eval.ConfigError{Err: errors.New("bad")})); got != ExitConfigError {
+		t.Errorf("config error exit code = %d, want %d", got, ExitConfigError)
+	}
+	if got := ExitCode(classifyRunError(errors.New("server crashed"))); got != ExitInfraError {
+		t.Errorf("runner error exit code = %d, want %d", got, ExitInfraError)
+	}
+}
+
+func TestEvalCmdConfigErrorExitCode(t *testing.T) {
+	cmd := NewEvalCmd()
+	cmd.SetArgs([]string{filepath.Join(t.TempDir(), "missing.yaml")})
+
+	err := cmd.Execute()
+	if got := ExitCode(err); got != ExitConfigError {
+		t.Errorf("exit code = %d, want %d (err: %v)", got, ExitConfigError, err)
+	}
+}
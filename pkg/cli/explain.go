@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// explainWidth is the line width descriptions are wrapped to
+const explainWidth = 80
+
+// NewExplainCmd creates the explain command
+func NewExplainCmd() *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "explain <kind[.field.path]>",
+		Short: "Show documentation for config file fields",
+		Long: fmt.Sprintf(`Show the documentation of a config file kind or one of its fields, like
+kubectl explain. Lists and maps are transparent, so the fields of a list of
+steps are the fields of a step.
+
+Kinds: %s
+
+With --output schema, the JSON schema of the kind is printed instead, for use
+with editors and YAML language servers.
+
+Example:
+  mcpchecker explain task
+  mcpchecker explain task.spec.verify
+  mcpchecker explain eval.config.taskSets.assertions
+  mcpchecker explain task --output schema > task.schema.json`, strings.Join(schema.Kinds(), ", ")),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if outputFormat == "schema" {
+				kind, _, _ := strings.Cut(args[0], ".")
+				data, err := schema.Raw(kind)
+				if err != nil {
+					return err
+				}
+				_, err = out.Write(data)
+				return err
+			}
+
+			explanation, err := schema.Explain(args[0])
+			if err != nil {
+				return err
+			}
+
+			switch outputFormat {
+			case "text":
+				outputTextExplanation(out, explanation)
+			case "json":
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(explanation)
+			default:
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json, schema)")
+
+	return cmd
+}
+
+func outputTextExplanation(w io.Writer, e *schema.Explanation) {
+	bold := color.New(color.Bold)
+	faint := color.New(color.Faint)
+
+	_, _ = bold.Fprint(w, "KIND:     ")
+	fmt.Fprintln(w, e.Kind)
+	if e.Field != "" {
+		_, _ = bold.Fprint(w, "FIELD:    ")
+		fmt.Fprintf(w, "%s <%s>\n", e.Field, e.Type)
+	}
+
+	fmt.Fprintln(w)
+	_, _ = bold.Fprintln(w, "DESCRIPTION:")
+	description := e.Description
+	if description == "" {
+		description = "<empty>"
+	}
+	for _, line := range strings.Split(wrapText(description, explainWidth-4), "\n") {
+		fmt.Fprintf(w, "    %s\n", line)
+	}
+
+	if len(e.Fields) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	_, _ = bold.Fprintln(w, "FIELDS:")
+	for _, f := range e.Fields {
+		fmt.Fprintf(w, "  %s\t<%s>", f.Name, f.Type)
+		if f.Required {
+			_, _ = faint.Fprint(w, " -required-")
+		}
+		if f.Deprecated {
+			_, _ = faint.Fprint(w, " -deprecated-")
+		}
+		fmt.Fprintln(w)
+		if f.Description != "" {
+			for _, line := range strings.Split(wrapText(f.Description, explainWidth-4), "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
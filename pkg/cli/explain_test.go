@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExplainCommand(t *testing.T) {
+	cmd := NewExplainCmd()
+	cmd.SetArgs([]string{"task.spec.verify"})
+
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("explain command failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"Task", "spec.verify <[]Step>", "DESCRIPTION:", "FIELDS:", "llmJudge\t<LLMJudgeStep>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExplainCommandSchema(t *testing.T) {
+	cmd := NewExplainCmd()
+	cmd.SetArgs([]string{"eval.config", "--output", "schema"})
+
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("explain command failed: %v", err)
+	}
+
+	var s map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &s); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if s["title"] != "Eval" {
+		t.Errorf("expected the Eval schema, got title %v", s["title"])
+	}
+}
+
+func TestExplainCommandUnknownField(t *testing.T) {
+	cmd := NewExplainCmd()
+	cmd.SetArgs([]string{"task.spec.steps"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("explain command should fail for an unknown field")
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewExportCmd creates the export command
+func NewExportCmd() *cobra.Command {
+	var format string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export <results-file>",
+		Short: "Export per-task metrics as CSV or Parquet",
+		Long: `Export evaluation results as one row per task, for analysis in pandas,
+spreadsheets, or BI tools.
+
+Each row holds the task's pass/fail state, assertion counts, MCP call counts,
+difficulty, failure reason, and labels. In CSV, each label becomes its own
+"label.<key>" column.
+
+Example:
+  mcpchecker export results.json --format csv > results.csv
+  mcpchecker export results.json --format parquet -o results.parquet`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != results.ExportFormatCSV && format != results.ExportFormatParquet {
+				return fmt.Errorf("unknown export format: %s", format)
+			}
+
+			evalResults, err := results.Load(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			if output == "-" {
+				return results.Export(cmd.OutOrStdout(), format, evalResults)
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+
+			if err := results.Export(f, format, evalResults); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("failed to export results: %w", err)
+			}
+
+			return f.Close()
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", results.ExportFormatCSV, "Export format (csv, parquet)")
+	cmd.Flags().StringVarP(&output, "output", "o", "-", "Output file, or - for stdout")
+
+	return cmd
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/taskgen"
+	"github.com/spf13/cobra"
+)
+
+// NewGenerateCmd creates the generate command for scaffolding eval files
+func NewGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate eval files",
+	}
+
+	cmd.AddCommand(newGenerateTasksCmd())
+
+	return cmd
+}
+
+func newGenerateTasksCmd() *cobra.Command {
+	var mcpConfigFiles []string
+	var mcpProfile string
+	var servers []string
+	var output string
+	var llmModel string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "Generate candidate tasks from MCP server tool schemas",
+		Long: `Connect to the MCP servers in the given config, list their tools, and write one
+candidate task per tool together with an eval file that asserts each task uses its tool.
+
+Prompts are filled from a template with placeholders for the required arguments.
+With --llm-model, prompts are drafted by an OpenAI-compatible model using the
+MODEL_BASE_URL and MODEL_KEY environment variables.
+
+Generated tasks have no verify steps and are meant as a starting point to edit.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := mcpproxy.LoadConfigFiles(mcpConfigFiles, mcpProfile)
+			if err != nil {
+				return fmt.Errorf("failed to load MCP config: %w", err)
+			}
+
+			opts := taskgen.Options{Servers: servers}
+			if llmModel != "" {
+				opts.Drafter, err = taskgen.NewLLMDrafter(os.Getenv("MODEL_BASE_URL"), os.Getenv("MODEL_KEY"), llmModel)
+				if err != nil {
+					return err
+				}
+			}
+
+			tasks, err := taskgen.Generate(cmd.Context(), cfg, opts)
+			if err != nil {
+				return err
+			}
+			if len(tasks) == 0 {
+				return fmt.Errorf("no tools found on the selected MCP servers")
+			}
+
+			err = taskgen.Write(output, tasks, taskgen.WriteOptions{
+				McpConfigFiles: mcpConfigFiles,
+				McpProfile:     mcpProfile,
+				Force:          force,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Generated %d task(s) in %s\n", len(tasks), output)
+			fmt.Printf("Review the prompts, then run: mcpchecker check %s\n", filepath.Join(output, taskgen.EvalFileName))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&mcpConfigFiles, "mcp-config", nil, "MCP config file (can be repeated, later files take precedence)")
+	cmd.Flags().StringVar(&mcpProfile, "mcp-profile", "", "Named profile to select from the MCP config files")
+	cmd.Flags().StringSliceVar(&servers, "server", nil, "Only generate tasks for these servers (default: all enabled servers)")
+	cmd.Flags().StringVarP(&output, "output", "o", "generated", "Directory to write the eval and tasks to")
+	cmd.Flags().StringVar(&llmModel, "llm-model", "", "Draft prompts with this model instead of using templates")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing files")
+	_ = cmd.MarkFlagRequired("mcp-config")
+
+	return cmd
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpimport"
+	"github.com/spf13/cobra"
+)
+
+// NewMcpCmd creates the mcp command for working with MCP server configs
+func NewMcpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Manage MCP server configurations",
+	}
+
+	cmd.AddCommand(newMcpImportCmd())
+
+	return cmd
+}
+
+func newMcpImportCmd() *cobra.Command {
+	var from string
+	var file string
+	var output string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import MCP servers from another MCP client",
+		Long: fmt.Sprintf(`Import the MCP servers configured in another MCP client and write them as an
+mcpchecker MCP config file.
+
+Supported sources: %s
+
+Unless --file is given, the client's config file is located automatically.
+Project-level configs in the current directory are preferred over user-level ones.`, strings.Join(mcpimport.Sources, ", ")),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workdir, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+
+			res, err := mcpimport.Import(from, file, workdir)
+			if err != nil {
+				return err
+			}
+
+			data, err := json.MarshalIndent(res.Config, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal MCP config: %w", err)
+			}
+			data = append(data, '\n')
+
+			// Status goes to stderr so stdout can be piped when writing to "-"
+			status := cmd.ErrOrStderr()
+			yellow := color.New(color.FgYellow).SprintFunc()
+			for _, w := range res.Warnings {
+				fmt.Fprintf(status, "%s %s\n", yellow("warning:"), w)
+			}
+
+			if output == "-" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			if !force {
+				if _, err := os.Stat(output); err == nil {
+					return fmt.Errorf("output file '%s' already exists, use --force to overwrite", output)
+				}
+			}
+
+			// Server env and headers often contain credentials
+			if err := os.WriteFile(output, data, 0600); err != nil {
+				return fmt.Errorf("failed to write MCP config: %w", err)
+			}
+
+			fmt.Fprintf(status, "Imported %d MCP server(s) from %s to %s\n", len(res.Config.MCPServers), res.Path, output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", fmt.Sprintf("Client to import from (%s)", strings.Join(mcpimport.Sources, ", ")))
+	cmd.Flags().StringVar(&file, "file", "", "Path to the client's config file (default: auto-detect)")
+	cmd.Flags().StringVarP(&output, "output", "o", "mcp-config.json", "Output file, or - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite the output file if it exists")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewMergeCmd creates the merge command
+func NewMergeCmd() *cobra.Command {
+	var output string
+	var outputLayout string
+	var onDuplicate string
+
+	cmd := &cobra.Command{
+		Use:   "merge <results-file>...",
+		Short: "Merge the results of sharded runs into one results file",
+		Long: `Merge the results files of runs of disjoint parts of a suite, such as the
+shards of a run split with check --shard, into one results file that summary,
+verify, and diff can read like the results of a single run.
+
+Tasks are kept in the order they first appear in. A task in more than one
+file, such as a task re-run after a flaky failure, is an error unless
+--on-duplicate says which one to keep:
+  error        fail the merge (the default)
+  keep-latest  keep the task of the file given last
+  keep-best    keep the task with the best outcome: passed with all assertions,
+               passed, failed, then skipped; on a tie, the file given last
+
+Examples:
+  mcpchecker merge shard-*/mcpchecker-suite-out.json -o mcpchecker-suite-out.json
+  mcpchecker merge run.json rerun.json -o merged.json --on-duplicate keep-latest`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(results.Layouts, outputLayout) {
+				return fmt.Errorf("unknown output layout: %s (must be one of %s)", outputLayout, strings.Join(results.Layouts, ", "))
+			}
+
+			merged, duplicates, err := results.Merge(onDuplicate, args...)
+			if err != nil {
+				return err
+			}
+
+			if err := results.Save(merged, output, outputLayout); err != nil {
+				return fmt.Errorf("failed to save results to file: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Merged %d tasks from %d files into %s\n", len(merged), len(args), output)
+			if len(duplicates) > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Resolved %d duplicate tasks with %s: %s\n", len(duplicates), onDuplicate, strings.Join(duplicates, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Results file to write")
+	cmd.Flags().StringVar(&outputLayout, "output-layout", results.LayoutFile, "Layout of the results file (file, gzip, dir)")
+	cmd.Flags().StringVar(&onDuplicate, "on-duplicate", results.DuplicateError, "What to do with a task in more than one file (error, keep-latest, keep-best)")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
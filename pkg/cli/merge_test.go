@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+)
+
+func TestMergeCommand(t *testing.T) {
+	dir := t.TempDir()
+	shard1 := filepath.Join(dir, "shard-1.json")
+	shard2 := filepath.Join(dir, "shard-2.json.gz")
+	if err := results.Save([]*eval.EvalResult{{TaskName: "task-1", TaskPassed: true}}, shard1, results.LayoutFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := results.Save([]*eval.EvalResult{{TaskName: "task-2"}}, shard2, results.LayoutGzip); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "merged.json")
+	var out bytes.Buffer
+	cmd := NewMergeCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{shard1, shard2, "-o", output})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Merged 2 tasks from 2 files") {
+		t.Errorf("output = %q, want the number of merged tasks", out.String())
+	}
+
+	merged, err := results.Load(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 2 || merged[0].TaskName != "task-1" || merged[1].TaskName != "task-2" {
+		t.Errorf("merged results = %v, want task-1 and task-2", merged)
+	}
+}
+
+func TestMergeCommandOverlappingShards(t *testing.T) {
+	dir := t.TempDir()
+	shard := filepath.Join(dir, "shard.json")
+	if err := results.Save([]*eval.EvalResult{{TaskName: "task-1"}}, shard, results.LayoutFile); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewMergeCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{shard, shard, "-o", filepath.Join(dir, "merged.json")})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "task task-1 is in both") {
+		t.Errorf("merge error = %v, want task task-1 is in both", err)
+	}
+}
+
+func TestMergeCommandKeepLatest(t *testing.T) {
+	dir := t.TempDir()
+	run := filepath.Join(dir, "run.json")
+	rerun := filepath.Join(dir, "rerun.json")
+	if err := results.Save([]*eval.EvalResult{{TaskName: "task-1"}, {TaskName: "task-2", TaskPassed: true}}, run, results.LayoutFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := results.Save([]*eval.EvalResult{{TaskName: "task-1", TaskPassed: true}}, rerun, results.LayoutFile); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "merged.json")
+	var out bytes.Buffer
+	cmd := NewMergeCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{run, rerun, "-o", output, "--on-duplicate", "keep-latest"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Resolved 1 duplicate tasks with keep-latest: task-1") {
+		t.Errorf("output = %q, want the resolved duplicates", out.String())
+	}
+
+	merged, err := results.Load(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 2 || !merged[0].TaskPassed {
+		t.Errorf("merged results = %v, want the re-run of task-1 first", merged)
+	}
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// NewMigrateCmd creates the migrate command
+func NewMigrateCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate <task-file-or-dir>...",
+		Short: "Rewrite task files in the latest apiVersion",
+		Long: fmt.Sprintf(`Rewrite task files in older formats in the latest apiVersion (%s).
+
+Directories are searched recursively for YAML files of kind Task. Files that
+are already in the latest apiVersion are left untouched. Comments and
+formatting are not preserved in converted files, so review the changes before
+committing them.
+
+Older apiVersions keep working, as tasks are converted when they are loaded.
+
+Example:
+  mcpchecker migrate tasks/
+  mcpchecker migrate tasks/create-pod.yaml --dry-run`, task.LatestAPIVersion),
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files, err := findTaskFiles(args)
+			if err != nil {
+				return err
+			}
+
+			migrated, failed := 0, 0
+			for _, path := range files {
+				from, err := migrateTaskFile(path, dryRun)
+				if err != nil {
+					failed++
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: %v\n", path, err)
+					continue
+				}
+				if from != task.LatestAPIVersion {
+					migrated++
+					printMigrated(cmd.OutOrStdout(), path, from, dryRun)
+				}
+			}
+
+			verb := "Migrated"
+			if dryRun {
+				verb = "Would migrate"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %d of %d task file(s)\n", verb, migrated, len(files))
+
+			if failed > 0 {
+				return fmt.Errorf("failed to migrate %d task file(s)", failed)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only report which files would be migrated")
+
+	return cmd
+}
+
+func printMigrated(w io.Writer, path, from string, dryRun bool) {
+	green := color.New(color.FgGreen)
+
+	if dryRun {
+		fmt.Fprintf(w, "  %s: %s → %s\n", path, from, task.LatestAPIVersion)
+		return
+	}
+
+	_, _ = green.Fprint(w, "✓ ")
+	fmt.Fprintf(w, "%s: %s → %s\n", path, from, task.LatestAPIVersion)
+}
+
+// migrateTaskFile rewrites a task file in the latest apiVersion and returns
+// the apiVersion it was written in
+func migrateTaskFile(path string, dryRun bool) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	out, from, err := task.Migrate(data)
+	if err != nil {
+		return from, err
+	}
+
+	if from == task.LatestAPIVersion || dryRun {
+		return from, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return from, err
+	}
+
+	return from, os.WriteFile(path, out, info.Mode().Perm())
+}
+
+// findTaskFiles expands directories to the task files they contain. Files
+// given explicitly are returned as is, so that non-task files are reported.
+func findTaskFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if ext := filepath.Ext(p); ext != ".yaml" && ext != ".yml" {
+				return nil
+			}
+
+			isTask, err := isTaskFile(p)
+			if err != nil {
+				return err
+			}
+			if isTask {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+func isTaskFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	meta := &util.TypeMeta{}
+	// Files that are not valid YAML objects are not tasks, e.g. Kubernetes
+	// manifests with several documents used by setup scripts
+	if err := yaml.Unmarshal(data, meta); err != nil {
+		return false, nil
+	}
+
+	return meta.Kind == task.KindTask, nil
+}
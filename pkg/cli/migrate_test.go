@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+const legacyTask = `kind: Task
+metadata:
+  name: legacy
+steps:
+  verify:
+    inline: exit 0
+  prompt:
+    inline: Do the thing
+`
+
+const latestTask = `# kept as is
+kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: latest
+spec:
+  prompt:
+    inline: Do the thing
+`
+
+func writeMigrateFixtures(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"legacy/legacy.yaml": legacyTask,
+		"latest.yml":         latestTask,
+		"eval.yaml":          "kind: Eval\nmetadata:\n  name: eval\n",
+		"manifest.yaml":      "apiVersion: v1\nkind: Pod\n---\napiVersion: v1\nkind: Service\n",
+		"notes.txt":          legacyTask,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestMigrateCommand(t *testing.T) {
+	dir := writeMigrateFixtures(t)
+
+	cmd := NewMigrateCmd()
+	cmd.SetArgs([]string{dir})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("migrate command failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Migrated 1 of 2 task file(s)") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "legacy", "legacy.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "apiVersion: "+task.LatestAPIVersion) {
+		t.Errorf("legacy task was not migrated:\n%s", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "latest.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != latestTask {
+		t.Errorf("task in the latest version should not be rewritten:\n%s", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "notes.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != legacyTask {
+		t.Errorf("non-YAML files should not be touched")
+	}
+}
+
+func TestMigrateCommandDryRun(t *testing.T) {
+	dir := writeMigrateFixtures(t)
+	path := filepath.Join(dir, "legacy", "legacy.yaml")
+
+	cmd := NewMigrateCmd()
+	cmd.SetArgs([]string{path, "--dry-run"})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("migrate command failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Would migrate 1 of 1 task file(s)") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != legacyTask {
+		t.Errorf("dry run should not modify files")
+	}
+}
+
+func TestMigrateCommandNotATask(t *testing.T) {
+	dir := writeMigrateFixtures(t)
+
+	cmd := NewMigrateCmd()
+	cmd.SetArgs([]string{filepath.Join(dir, "eval.yaml")})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("migrate command should fail for a file that is not a task")
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// progressRecord is a single line of the NDJSON progress stream
+type progressRecord struct {
+	Type    eval.ProgressEventType `json:"type"`
+	Time    time.Time              `json:"time"`
+	Message string                 `json:"message,omitempty"`
+	Task    *taskProgressRecord    `json:"task,omitempty"`
+	Step    *stepProgressRecord    `json:"step,omitempty"`
+}
+
+type taskProgressRecord struct {
+	Name string `json:"name"`
+	// The outcome is only set once the task has finished
+	Passed              *bool  `json:"passed,omitempty"`
+	AllAssertionsPassed *bool  `json:"allAssertionsPassed,omitempty"`
+	Error               string `json:"error,omitempty"`
+	// Status is set for skipped and not run tasks and finished tasks that
+	// are expected to fail, and SkipReason for skipped and not run tasks
+	Status     eval.TaskStatus `json:"status,omitempty"`
+	SkipReason string          `json:"skipReason,omitempty"`
+}
+
+type stepProgressRecord struct {
+	ID    string `json:"id"`
+	Phase string `json:"phase"`
+	Index int    `json:"index"`
+	Type  string `json:"type"`
+	// The outcome is only set once the step has completed
+	Success  *bool  `json:"success,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// jsonProgress writes progress events as newline-delimited JSON
+type jsonProgress struct {
+	encoder *json.Encoder
+	now     func() time.Time
+}
+
+func newJSONProgress(w io.Writer) *jsonProgress {
+	return &jsonProgress{
+		encoder: json.NewEncoder(w),
+		now:     time.Now,
+	}
+}
+
+func (p *jsonProgress) handleProgress(event eval.ProgressEvent) {
+	record := progressRecord{
+		Type:    event.Type,
+		Time:    p.now(),
+		Message: event.Message,
+	}
+
+	if event.Task != nil {
+		record.Task = &taskProgressRecord{Name: event.Task.TaskName}
+		if event.Type == eval.EventTaskComplete || event.Type == eval.EventTaskError {
+			passed := event.Task.TaskPassed
+			assertionsPassed := event.Task.AllAssertionsPassed
+			record.Task.Passed = &passed
+			record.Task.AllAssertionsPassed = &assertionsPassed
+			record.Task.Error = event.Task.TaskError
+			if event.Task.ExpectedFailure {
+				record.Task.Status = event.Task.Status()
+			}
+		}
+		if event.Type == eval.EventTaskSkipped {
+			record.Task.Status = event.Task.Status()
+			record.Task.SkipReason = event.Task.SkipReason
+		}
+	}
+
+	if event.Step != nil {
+		record.Step = newStepProgressRecord(event.Step)
+	}
+
+	// A reader going away must not fail the run, so write errors are ignored
+	_ = p.encoder.Encode(record)
+}
+
+func newStepProgressRecord(step *task.StepEvent) *stepProgressRecord {
+	record := &stepProgressRecord{
+		ID:    step.ID(),
+		Phase: step.Phase,
+		Index: step.Index,
+		Type:  step.StepType,
+	}
+
+	if step.Type != task.StepEventComplete {
+		return record
+	}
+
+	success := step.Err == nil && step.Output != nil && step.Output.Success
+	record.Success = &success
+	if step.Err != nil {
+		record.Error = step.Err.Error()
+	} else if step.Output != nil {
+		record.Error = step.Output.Error
+	}
+	if step.Output != nil && step.Output.Duration > 0 {
+		record.Duration = step.Output.Duration.String()
+	}
+
+	return record
+}
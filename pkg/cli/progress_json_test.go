@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+func TestJSONProgress(t *testing.T) {
+	var buf bytes.Buffer
+	progress := newJSONProgress(&buf)
+	progress.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	result := &eval.EvalResult{TaskName: "create-pod"}
+
+	progress.handleProgress(eval.ProgressEvent{Type: eval.EventEvalStart, Message: "Starting evaluation"})
+	progress.handleProgress(eval.ProgressEvent{Type: eval.EventTaskStart, Task: result})
+	progress.handleProgress(eval.ProgressEvent{
+		Type: eval.EventStepComplete,
+		Task: result,
+		Step: &task.StepEvent{
+			Type:     task.StepEventComplete,
+			Phase:    task.PhaseVerify,
+			Index:    1,
+			StepType: "llmJudge",
+			Output:   &steps.StepOutput{Success: true, Duration: util.Duration(1500 * time.Millisecond)},
+		},
+	})
+	progress.handleProgress(eval.ProgressEvent{
+		Type: eval.EventStepComplete,
+		Task: result,
+		Step: &task.StepEvent{Type: task.StepEventComplete, Phase: task.PhaseSetup, StepType: "http", Err: errors.New("connection refused")},
+	})
+	result.TaskPassed = true
+	progress.handleProgress(eval.ProgressEvent{Type: eval.EventTaskComplete, Task: result})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5:\n%s", len(lines), buf.String())
+	}
+
+	want := []string{
+		`{"type":"eval_start","time":"2026-01-02T03:04:05Z","message":"Starting evaluation"}`,
+		`{"type":"task_start","time":"2026-01-02T03:04:05Z","task":{"name":"create-pod"}}`,
+		`{"type":"step_complete","time":"2026-01-02T03:04:05Z","task":{"name":"create-pod"},"step":{"id":"verify[1]","phase":"verify","index":1,"type":"llmJudge","success":true,"duration":"1.5s"}}`,
+		`{"type":"step_complete","time":"2026-01-02T03:04:05Z","task":{"name":"create-pod"},"step":{"id":"setup[0]","phase":"setup","index":0,"type":"http","success":false,"error":"connection refused"}}`,
+		`{"type":"task_complete","time":"2026-01-02T03:04:05Z","task":{"name":"create-pod","passed":true,"allAssertionsPassed":false}}`,
+	}
+	for i, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("line %d is not valid JSON: %s", i, line)
+		}
+		if line != want[i] {
+			t.Errorf("line %d = %s\nwant %s", i, line, want[i])
+		}
+	}
+}
+
+func TestJSONProgressSkipAndExpectedFailure(t *testing.T) {
+	var buf bytes.Buffer
+	progress := newJSONProgress(&buf)
+	progress.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	progress.handleProgress(eval.ProgressEvent{
+		Type: eval.EventTaskSkipped,
+		Task: &eval.EvalResult{TaskName: "scale-gpu", SkipReason: "needs a GPU node"},
+	})
+	progress.handleProgress(eval.ProgressEvent{
+		Type: eval.EventTaskComplete,
+		Task: &eval.EvalResult{TaskName: "delete-ns", ExpectedFailure: true},
+	})
+
+	want := `{"type":"task_skipped","time":"2026-01-02T03:04:05Z","task":{"name":"scale-gpu","status":"skipped","skipReason":"needs a GPU node"}}
+{"type":"task_complete","time":"2026-01-02T03:04:05Z","task":{"name":"delete-ns","passed":false,"allAssertionsPassed":false,"status":"expectedFailure"}}
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%swant:\n%s", buf.String(), want)
+	}
+}
+
+func TestEvalCommandRejectsProgressOutputWithText(t *testing.T) {
+	cmd := NewEvalCmd()
+	cmd.SetArgs([]string{"eval.yaml", "--progress-output", "progress.ndjson"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--progress-format json") {
+		t.Errorf("expected --progress-output to require json progress, got %v", err)
+	}
+}
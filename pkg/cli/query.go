@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// queryFlags are the flags that select the results a command looks at, shared
+// by the commands that read results
+type queryFlags struct {
+	labels       []string
+	difficulties []string
+	statuses     []string
+}
+
+// register adds the flags to cmd
+func (f *queryFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.labels, "label", nil, "Only include tasks with this label, as key=value (can be repeated, all must match)")
+	cmd.Flags().StringSliceVar(&f.difficulties, "difficulty", nil, "Only include tasks of these difficulties (comma-separated)")
+	cmd.Flags().StringSliceVar(&f.statuses, "status", nil, "Only include tasks with these statuses: passed, failed, skipped, expectedFailure, unexpectedPass, notRun (comma-separated)")
+}
+
+// query returns the query of the flags and the task name filter
+func (f *queryFlags) query(task string) (results.Query, error) {
+	labels, err := results.ParseLabels(f.labels)
+	if err != nil {
+		return results.Query{}, err
+	}
+	statuses, err := results.ParseStatuses(f.statuses)
+	if err != nil {
+		return results.Query{}, err
+	}
+	return results.Query{
+		Task:         task,
+		Labels:       labels,
+		Difficulties: f.difficulties,
+		Statuses:     statuses,
+	}, nil
+}
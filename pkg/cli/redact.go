@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewRedactCmd creates the redact command
+func NewRedactCmd() *cobra.Command {
+	var rulesFile string
+	var output string
+	var outputLayout string
+
+	cmd := &cobra.Command{
+		Use:   "redact <results-file>",
+		Short: "Scrub secrets and customer data from results before sharing them",
+		Long: `Write a copy of a results file with secrets, hostnames, customer data, or
+other strings replaced in every text of the results: the agent output, the
+arguments and results of tool calls, the step outputs, and the judge reasons.
+
+By default the rules of the safety scan are applied: AWS access keys, GitHub
+tokens, private keys, JWTs, email addresses, and US social security numbers.
+--rules adds rules of your own from a YAML file:
+
+  rules:
+    - name: internal-host
+      pattern: '\b[a-z0-9-]+\.corp\.example\.com\b'
+    - name: customer
+      pattern: 'Acme (Corp|Inc)'
+      replace: CUSTOMER
+  fields: [password, token, authorization]
+  disableDefaultRules: false
+
+Matches are replaced with [REDACTED:<rule>] unless the rule sets replace, and
+the values of the listed fields are replaced wherever they appear. References
+to artifact files, such as HAR files, are dropped since the files are not
+redacted.
+
+Examples:
+  mcpchecker redact mcpchecker-suite-out.json -o shared.json
+  mcpchecker redact results --rules redact.yaml -o shared.json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(results.Layouts, outputLayout) {
+				return fmt.Errorf("unknown output layout: %s (must be one of %s)", outputLayout, strings.Join(results.Layouts, ", "))
+			}
+
+			var rules *results.RedactRules
+			if rulesFile != "" {
+				var err error
+				if rules, err = results.LoadRedactRules(rulesFile); err != nil {
+					return err
+				}
+			}
+
+			res, err := results.Load(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load %s: %w", args[0], err)
+			}
+
+			redacted, matches, err := results.Redact(res, rules)
+			if err != nil {
+				return err
+			}
+
+			if err := results.Save(redacted, output, outputLayout); err != nil {
+				return fmt.Errorf("failed to save results to file: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Redacted %s of %d tasks into %s\n", formatRedactMatches(matches), len(redacted), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rulesFile, "rules", "", "YAML file of redact rules and fields, applied after the default rules")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Results file to write")
+	cmd.Flags().StringVar(&outputLayout, "output-layout", results.LayoutFile, "Layout of the results file (file, gzip, dir)")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// formatRedactMatches describes the number of replacements of each rule
+func formatRedactMatches(matches map[string]int) string {
+	total := 0
+	var parts []string
+	for _, name := range slices.Sorted(maps.Keys(matches)) {
+		total += matches[name]
+		parts = append(parts, fmt.Sprintf("%s %d", name, matches[name]))
+	}
+	if total == 0 {
+		return "nothing"
+	}
+	if total == 1 {
+		return fmt.Sprintf("1 match (%s)", strings.Join(parts, ", "))
+	}
+	return fmt.Sprintf("%d matches (%s)", total, strings.Join(parts, ", "))
+}
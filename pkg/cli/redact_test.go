@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+)
+
+func TestRedactCommand(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "results.json")
+	if err := results.Save([]*eval.EvalResult{{TaskName: "task-1", TaskOutput: "mail admin@example.com on db.corp.example.com"}}, input, results.LayoutFile); err != nil {
+		t.Fatal(err)
+	}
+	rules := filepath.Join(dir, "redact.yaml")
+	if err := os.WriteFile(rules, []byte("rules:\n  - name: internal-host\n    pattern: '[a-z]+\\.corp\\.example\\.com'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "shared.json")
+	var out bytes.Buffer
+	cmd := NewRedactCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{input, "--rules", rules, "-o", output})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("redact failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Redacted 2 matches (email 1, internal-host 1) of 1 tasks") {
+		t.Errorf("output = %q, want the number of matches per rule", out.String())
+	}
+
+	redacted, err := results.Load(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "mail [REDACTED:email] on [REDACTED:internal-host]"; len(redacted) != 1 || redacted[0].TaskOutput != want {
+		t.Errorf("redacted results = %v, want task output %q", redacted, want)
+	}
+}
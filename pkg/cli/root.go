@@ -1,29 +1,90 @@
 package cli
 
 import (
+	"errors"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 // NewRootCmd creates the root mcpchecker command
 func NewRootCmd() *cobra.Command {
+	var noColor bool
+
 	rootCmd := &cobra.Command{
 		Use:   "mcpchecker",
 		Short: "MCP evaluation framework",
 		Long: `mcpchecker is a framework for evaluating MCP agents against tasks.
 It runs agents through defined tasks and validates their behavior using assertions.`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// NO_COLOR and non-terminal output are already handled by the color package
+			if noColor {
+				color.NoColor = true
+			}
+		},
 	}
 
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also honors the NO_COLOR environment variable)")
+
 	// Add subcommands
 	rootCmd.AddCommand(NewEvalCmd())
 	rootCmd.AddCommand(NewViewCmd())
 	rootCmd.AddCommand(NewVerifyCmd())
 	rootCmd.AddCommand(NewSummaryCmd())
 	rootCmd.AddCommand(NewDiffCmd())
+	rootCmd.AddCommand(NewMcpCmd())
+	rootCmd.AddCommand(NewGenerateCmd())
+	rootCmd.AddCommand(NewCoverageCmd())
+	rootCmd.AddCommand(NewTriageCmd())
+	rootCmd.AddCommand(NewExportCmd())
+	rootCmd.AddCommand(NewTrendCmd())
+	rootCmd.AddCommand(NewExplainCmd())
+	rootCmd.AddCommand(NewMigrateCmd())
+	rootCmd.AddCommand(NewMergeCmd())
+	rootCmd.AddCommand(NewRedactCmd())
+	rootCmd.AddCommand(NewVerifyBundleCmd())
+	rootCmd.AddCommand(NewStepCmd())
+	rootCmd.AddCommand(NewStubServerCmd())
+
+	// Invalid flags and args are usage errors, like invalid configuration
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &ExitError{Code: ExitConfigError, Err: err}
+	})
+	wrapArgsErrors(rootCmd)
 
 	return rootCmd
 }
 
+// wrapArgsErrors makes the errors of the args validators of cmd and its
+// subcommands exit with ExitConfigError
+func wrapArgsErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return &ExitError{Code: ExitConfigError, Err: err}
+			}
+			return nil
+		}
+	}
+
+	for _, sub := range cmd.Commands() {
+		wrapArgsErrors(sub)
+	}
+}
+
 // Execute runs the root command
 func Execute() error {
-	return NewRootCmd().Execute()
+	return execute(NewRootCmd())
+}
+
+func execute(rootCmd *cobra.Command) error {
+	cmd, err := rootCmd.ExecuteC()
+
+	// The root command does not run anything, so its own errors, such as an
+	// unknown command, are usage errors
+	var exitErr *ExitError
+	if err != nil && cmd == rootCmd && !errors.As(err, &exitErr) {
+		return &ExitError{Code: ExitConfigError, Err: err}
+	}
+	return err
 }
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"io"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestRootCmdNoColor(t *testing.T) {
+	orig := color.NoColor
+	t.Cleanup(func() { color.NoColor = orig })
+	color.NoColor = false
+
+	filePath := createTestResultsFile(t, sampleResults())
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"--no-color", "verify", filePath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("verify command failed: %v", err)
+	}
+
+	if !color.NoColor {
+		t.Errorf("expected --no-color to disable colored output")
+	}
+}
+
+func TestRootCmdUsageErrors(t *testing.T) {
+	tests := map[string][]string{
+		"unknown flag":       {"verify", "--no-such-flag"},
+		"invalid flag value": {"verify", "--task", "high"},
+		"missing args":       {"verify"},
+		"unknown command":    {"no-such-command"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := NewRootCmd()
+			cmd.SetArgs(args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			err := execute(cmd)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if code := ExitCode(err); code != ExitConfigError {
+				t.Errorf("ExitCode() = %d, want %d (%v)", code, ExitConfigError, err)
+			}
+		})
+	}
+}
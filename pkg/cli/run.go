@@ -2,14 +2,21 @@ package cli
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -18,67 +25,254 @@ import (
 func NewEvalCmd() *cobra.Command {
 	var outputFormat string
 	var verbose bool
+	var quiet bool
 	var run string
 	var labelSelector string
+	var mcpConfigFiles []string
+	var mcpProfile string
+	var progressFormat string
+	var progressOutput string
+	var maxAgentOutput int
+	var captureMcpTraffic bool
+	var outputLayout string
+	var strict bool
+	var noCache bool
+	var sampleCount int
+	var samplePercent float64
+	var sampleSeed int64
+	var sampleStratify string
+	var shard string
+	var failFast bool
+	var maxFailures int
+	var updateSnapshots bool
+	var redactRules string
+	var bundleFile string
+	var bundleKey string
+	var columns string
 
 	cmd := &cobra.Command{
 		Use:   "check [eval-config-file]",
 		Short: "Run an evaluation",
-		Long:  `Run an evaluation using the specified eval configuration file.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Run an evaluation using the specified eval configuration file.
+
+Exit codes:
+  0  the run completed (with --strict: all tasks and assertions passed)
+  1  with --strict: one or more tasks failed
+  2  with --strict: all tasks passed but one or more assertions failed
+  3  infrastructure error, such as an MCP server or agent that could not be
+     started (with --strict: also a task whose setup or agent failed)
+  4  invalid eval configuration or flags
+
+Failures of quarantined tasks never affect the exit code.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			configFile := args[0]
 
+			if quiet && verbose {
+				return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("--quiet and --verbose cannot be used together")}
+			}
+			if failFast && cmd.Flags().Changed("max-failures") {
+				return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("--fail-fast and --max-failures cannot be used together")}
+			}
+			if maxFailures < 0 {
+				return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("--max-failures must not be negative")}
+			}
+			if failFast {
+				maxFailures = 1
+			}
+			custom, err := parseTaskOutput(outputFormat, columns)
+			if err != nil {
+				return &ExitError{Code: ExitConfigError, Err: err}
+			}
+			if !slices.Contains(results.Layouts, outputLayout) {
+				return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("unknown output layout: %s (must be one of %s)", outputLayout, strings.Join(results.Layouts, ", "))}
+			}
+			var redact *results.RedactRules
+			if redactRules != "" {
+				if redact, err = results.LoadRedactRules(redactRules); err != nil {
+					return &ExitError{Code: ExitConfigError, Err: err}
+				}
+			}
+			if (bundleFile == "") != (bundleKey == "") {
+				return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("--bundle and --bundle-key must be used together")}
+			}
+			var signingKey ed25519.PrivateKey
+			if bundleKey != "" {
+				if signingKey, err = results.LoadSigningKey(bundleKey); err != nil {
+					return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("failed to load bundle key: %w", err)}
+				}
+			}
+
+			// Create progress display
+			var progress eval.ProgressCallback
+			switch progressFormat {
+			case "text":
+				if progressOutput != "" {
+					return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("--progress-output requires --progress-format json")}
+				}
+				progress = newProgressDisplay(verbose, quiet).handleProgress
+			case "json":
+				w := cmd.ErrOrStderr()
+				if progressOutput != "" {
+					// Opening a named pipe blocks until a reader opens it
+					f, err := os.OpenFile(progressOutput, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+					if err != nil {
+						return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("failed to open progress output: %w", err)}
+					}
+					defer f.Close()
+					w = f
+				}
+				progress = newJSONProgress(w).handleProgress
+			default:
+				return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("unknown progress format: %s", progressFormat)}
+			}
+
 			// Load eval spec
 			spec, err := eval.FromFile(configFile)
 			if err != nil {
-				return fmt.Errorf("failed to load eval config: %w", err)
+				return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("failed to load eval config: %w", err)}
 			}
 
 			// Apply label selector filter if provided
 			if labelSelector != "" {
 				if err := eval.ApplyLabelSelectorFilter(spec, labelSelector); err != nil {
-					return fmt.Errorf("failed to apply label selector: %w", err)
+					return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("failed to apply label selector: %w", err)}
 				}
 			}
 
+			// MCP config files from the command line are layered on top of
+			// the ones from the eval config
+			spec.Config.McpConfigFiles = append(spec.Config.McpConfigFiles, mcpConfigFiles...)
+			if mcpProfile != "" {
+				spec.Config.McpProfile = mcpProfile
+			}
+			if cmd.Flags().Changed("max-agent-output") {
+				if spec.Config.AgentOutput == nil {
+					spec.Config.AgentOutput = &eval.AgentOutputConfig{}
+				}
+				spec.Config.AgentOutput.MaxBytes = maxAgentOutput
+			}
+			if captureMcpTraffic {
+				spec.Config.CaptureMcpTraffic = true
+			}
+
+			// Judge verdicts are cached unless --no-cache is set
+			var runnerOpts []eval.RunnerOption
+			if !noCache {
+				cacheDir, err := llmjudge.DefaultCacheDir()
+				if err != nil {
+					return &ExitError{Code: ExitConfigError, Err: err}
+				}
+				runnerOpts = append(runnerOpts, eval.WithJudgeCacheDir(cacheDir))
+			}
+
+			// A sample without a seed gets a random one, which is reported so
+			// that the sample can be run again
+			if cmd.Flags().Changed("sample") || cmd.Flags().Changed("sample-percent") {
+				if !cmd.Flags().Changed("sample-seed") {
+					sampleSeed = rand.Int64N(1_000_000)
+				}
+				runnerOpts = append(runnerOpts, eval.WithSample(&eval.Sample{
+					Count:      sampleCount,
+					Percent:    samplePercent,
+					Seed:       sampleSeed,
+					StratifyBy: sampleStratify,
+				}))
+			}
+
+			if shard != "" {
+				s, err := eval.ParseShard(shard)
+				if err != nil {
+					return &ExitError{Code: ExitConfigError, Err: err}
+				}
+				runnerOpts = append(runnerOpts, eval.WithShard(s))
+			}
+
+			if maxFailures > 0 {
+				runnerOpts = append(runnerOpts, eval.WithMaxFailures(maxFailures))
+			}
+
 			// Create runner
-			runner, err := eval.NewRunner(spec)
+			runner, err := eval.NewRunner(spec, runnerOpts...)
 			if err != nil {
-				return fmt.Errorf("failed to create eval runner: %w", err)
+				return &ExitError{Code: ExitConfigError, Err: fmt.Errorf("failed to create eval runner: %w", err)}
 			}
 
-			// Create progress display
-			display := newProgressDisplay(verbose)
-
 			// Run with progress
 			ctx := context.Background()
 			ctx = util.WithVerbose(ctx, verbose)
-			results, err := runner.RunWithProgress(ctx, run, display.handleProgress)
+			ctx = util.WithUpdateSnapshots(ctx, updateSnapshots)
+			evalResults, err := runner.RunWithProgress(ctx, run, progress)
 			if err != nil {
-				return fmt.Errorf("eval failed: %w", err)
+				return classifyRunError(fmt.Errorf("eval failed: %w", err))
 			}
 
-			// Save results to JSON file
-			outputFile := fmt.Sprintf("mcpchecker-%s-out.json", spec.Metadata.Name)
-			if err := saveResultsToFile(results, outputFile); err != nil {
-				return fmt.Errorf("failed to save results to file: %w", err)
+			// Save results in the requested layout
+			outputFile := results.OutputPath(spec.Metadata.Name, outputLayout)
+			saved := evalResults
+			if redact != nil {
+				if saved, _, err = results.Redact(evalResults, redact); err != nil {
+					return &ExitError{Code: ExitInfraError, Err: fmt.Errorf("failed to redact results: %w", err)}
+				}
+			}
+			if err := results.Save(saved, outputFile, outputLayout); err != nil {
+				return &ExitError{Code: ExitInfraError, Err: fmt.Errorf("failed to save results to file: %w", err)}
 			}
 			fmt.Printf("\n📄 Results saved to: %s\n", outputFile)
 
+			if bundleFile != "" {
+				if err := writeBundle(saved, spec, configFile, bundleFile, signingKey); err != nil {
+					return &ExitError{Code: ExitInfraError, Err: err}
+				}
+				fmt.Printf("🔏 Signed bundle saved to: %s\n", bundleFile)
+			}
+
 			// Display results
-			if err := displayResults(results, outputFormat); err != nil {
+			if custom != nil {
+				if err := custom.print(os.Stdout, evalResults); err != nil {
+					return fmt.Errorf("failed to display results: %w", err)
+				}
+			} else if err := displayResults(evalResults, outputFormat, quiet); err != nil {
 				return fmt.Errorf("failed to display results: %w", err)
 			}
 
+			if strict {
+				if code := runExitCode(evalResults); code != ExitOK {
+					return &ExitError{Code: code, Err: fmt.Errorf("evaluation did not pass (exit code %d)", code)}
+				}
+			}
+
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json, go-template=<template>, go-template-file=<path>)")
+	cmd.Flags().StringVar(&columns, "columns", "", "Print a table of the tasks with these columns instead of the text results ("+columnNames()+")")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only print failed tasks and the overall statistics")
 	cmd.Flags().StringVarP(&run, "run", "r", "", "Regular expression to match task names to run (unanchored, like go test -run)")
 	cmd.Flags().StringVarP(&labelSelector, "label-selector", "l", "", "Filter taskSets by label (format: key=value, e.g., suite=kubernetes)")
+	cmd.Flags().StringArrayVar(&mcpConfigFiles, "mcp-config", nil, "Additional MCP config file layered on top of the eval's MCP config (can be repeated, later files take precedence)")
+	cmd.Flags().StringVar(&mcpProfile, "mcp-profile", "", "Named profile to select from the MCP config files")
+	cmd.Flags().StringVar(&progressFormat, "progress-format", "text", "Progress format (text, json). json writes one event per line to stderr")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Exit with a non-zero code when tasks or assertions fail")
+	cmd.Flags().StringVar(&progressOutput, "progress-output", "", "File or named pipe to write json progress to instead of stderr")
+	cmd.Flags().IntVar(&maxAgentOutput, "max-agent-output", eval.DefaultMaxAgentOutputBytes, "Maximum bytes of agent output kept in the results per task, longer output is truncated and saved to an artifact file (-1 for no limit)")
+	cmd.Flags().BoolVar(&captureMcpTraffic, "capture-mcp-traffic", false, "Save the HTTP traffic to http and websocket MCP servers of each task to a HAR file in the artifact directory (bodies are not redacted)")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Call the LLM judge for every task instead of reusing cached verdicts of identical judge calls (cached in $MCPCHECKER_CACHE_DIR/judge, or mcpchecker/judge in the user cache directory)")
+	cmd.Flags().IntVar(&sampleCount, "sample", 0, "Run a random sample of this many of the matching tasks")
+	cmd.Flags().Float64Var(&samplePercent, "sample-percent", 0, "Run a random sample of this percentage of the matching tasks, rounded up")
+	cmd.Flags().Int64Var(&sampleSeed, "sample-seed", 0, "Seed of the sample, to run the same sample again (default: random, and printed)")
+	cmd.Flags().StringVar(&sampleStratify, "sample-stratify", eval.StratifyByDifficulty, "Sample each difficulty (difficulty) or value of a label (label:<key>) in proportion to its tasks, or the tasks as a whole (none)")
+	cmd.Flags().StringVar(&shard, "shard", "", "Run one of n shards of the tasks, as i/n (e.g. 2/4), to split a suite across CI jobs; combine the results with merge")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop the run after the first failed task, and record the tasks left as not run")
+	cmd.Flags().IntVar(&maxFailures, "max-failures", 0, "Stop the run after this many failed tasks, and record the tasks left as not run (0 for no limit)")
+	cmd.Flags().BoolVar(&updateSnapshots, "update-snapshots", false, "Rewrite the golden files of snapshot steps with the current output instead of comparing against them")
+	cmd.Flags().StringVar(&outputLayout, "output-layout", results.LayoutFile, "Layout of the results file (file, gzip, dir). gzip compresses the JSON file, dir writes one file per task to a directory with an index")
+	cmd.Flags().StringVar(&redactRules, "redact", "", "YAML file of redact rules applied to the results before they are saved, as with the redact command (the artifact files are not redacted)")
+	cmd.Flags().StringVar(&bundleFile, "bundle", "", "Also write the results to a bundle with the digests of the eval config and task files, signed with --bundle-key, which verify-bundle checks")
+	cmd.Flags().StringVar(&bundleKey, "bundle-key", "", "PEM file of the ed25519 private key that signs the bundle")
 
 	return cmd
 }
@@ -86,6 +280,7 @@ func NewEvalCmd() *cobra.Command {
 // progressDisplay handles interactive progress display
 type progressDisplay struct {
 	verbose bool
+	quiet   bool
 	green   *color.Color
 	red     *color.Color
 	yellow  *color.Color
@@ -93,9 +288,10 @@ type progressDisplay struct {
 	bold    *color.Color
 }
 
-func newProgressDisplay(verbose bool) *progressDisplay {
+func newProgressDisplay(verbose, quiet bool) *progressDisplay {
 	return &progressDisplay{
 		verbose: verbose,
+		quiet:   quiet,
 		green:   color.New(color.FgGreen),
 		red:     color.New(color.FgRed),
 		yellow:  color.New(color.FgYellow),
@@ -105,10 +301,18 @@ func newProgressDisplay(verbose bool) *progressDisplay {
 }
 
 func (d *progressDisplay) handleProgress(event eval.ProgressEvent) {
+	if d.quiet {
+		d.handleQuietProgress(event)
+		return
+	}
+
 	switch event.Type {
 	case eval.EventEvalStart:
 		d.bold.Println("\n=== Starting Evaluation ===")
 
+	case eval.EventTasksSampled, eval.EventTasksSharded:
+		fmt.Println(event.Message)
+
 	case eval.EventTaskStart:
 		fmt.Println()
 		d.cyan.Printf("Task: %s\n", event.Task.TaskName)
@@ -132,16 +336,42 @@ func (d *progressDisplay) handleProgress(event eval.ProgressEvent) {
 			fmt.Printf("  → Evaluating assertions...\n")
 		}
 
+	case eval.EventStepStart:
+		if d.verbose {
+			fmt.Printf("    · %s (%s)...\n", event.Step.ID(), event.Step.StepType)
+		}
+
+	case eval.EventStepComplete:
+		if d.verbose {
+			d.printStepComplete(event.Step)
+		}
+
 	case eval.EventTaskError:
 		task := event.Task
 		d.red.Printf("  ✗ Task failed during setup\n")
 		if task.TaskError != "" {
 			fmt.Printf("    Error: %s\n", task.TaskError)
 		}
+		if reason := results.CleanupFailure(task); reason != "" {
+			d.yellow.Printf("  ⚠ Cleanup failed: %s\n", reason)
+		}
+
+	case eval.EventTaskSkipped:
+		fmt.Println()
+		d.cyan.Printf("Task: %s\n", event.Task.TaskName)
+		if event.Task.NotRun {
+			d.yellow.Printf("  - Not run: %s\n", event.Task.SkipReason)
+		} else {
+			d.yellow.Printf("  - Skipped: %s\n", event.Task.SkipReason)
+		}
 
 	case eval.EventTaskComplete:
 		task := event.Task
-		if task.TaskPassed && task.AllAssertionsPassed {
+		if task.Status() == eval.TaskStatusExpectedFailure {
+			d.yellow.Printf("  ✗ Task failed as expected\n")
+		} else if task.Status() == eval.TaskStatusUnexpectedPass {
+			d.yellow.Printf("  ! Task passed, but is expected to fail\n")
+		} else if task.TaskPassed && task.AllAssertionsPassed {
 			d.green.Printf("  ✓ Task passed\n")
 		} else if task.TaskPassed && !task.AllAssertionsPassed {
 			d.yellow.Printf("  ~ Task passed but assertions failed\n")
@@ -164,6 +394,12 @@ func (d *progressDisplay) handleProgress(event eval.ProgressEvent) {
 				}
 			}
 		}
+		if reason := results.CleanupFailure(task); reason != "" {
+			d.yellow.Printf("  ⚠ Cleanup failed: %s\n", reason)
+		}
+
+	case eval.EventWarning:
+		d.yellow.Printf("  ⚠ %s\n", event.Message)
 
 	case eval.EventEvalComplete:
 		fmt.Println()
@@ -171,7 +407,54 @@ func (d *progressDisplay) handleProgress(event eval.ProgressEvent) {
 	}
 }
 
-func displayResults(results []*eval.EvalResult, format string) error {
+// handleQuietProgress prints a single line for each task that failed
+func (d *progressDisplay) handleQuietProgress(event eval.ProgressEvent) {
+	if event.Type == eval.EventWarning {
+		d.yellow.Printf("⚠ %s\n", event.Message)
+		return
+	}
+	if event.Type != eval.EventTaskComplete && event.Type != eval.EventTaskError {
+		return
+	}
+
+	task := event.Task
+	switch task.Status() {
+	case eval.TaskStatusPassed, eval.TaskStatusExpectedFailure:
+		return
+	case eval.TaskStatusUnexpectedPass:
+		d.yellow.Printf("! %s: passed, but is expected to fail\n", task.TaskName)
+		return
+	}
+
+	reason := results.FailureReason(task)
+	if reason == "" {
+		reason = "assertions failed"
+	}
+	d.red.Printf("✗ %s: %s\n", task.TaskName, firstLine(reason))
+}
+
+// printStepComplete prints whether a step passed, and why it failed if it did
+func (d *progressDisplay) printStepComplete(step *task.StepEvent) {
+	name := fmt.Sprintf("%s (%s)", step.ID(), step.StepType)
+	if step.Output != nil && step.Output.Duration > 0 {
+		name = fmt.Sprintf("%s in %s", name, step.Output.Duration)
+	}
+
+	switch {
+	case step.Err != nil:
+		d.red.Printf("    ✗ %s: %v\n", name, step.Err)
+	case step.Output != nil && !step.Output.Success:
+		reason := step.Output.Error
+		if reason == "" {
+			reason = step.Output.Message
+		}
+		d.red.Printf("    ✗ %s: %s\n", name, firstLine(reason))
+	default:
+		d.green.Printf("    ✓ %s\n", name)
+	}
+}
+
+func displayResults(results []*eval.EvalResult, format string, quiet bool) error {
 	switch format {
 	case "json":
 		encoder := json.NewEncoder(os.Stdout)
@@ -179,14 +462,16 @@ func displayResults(results []*eval.EvalResult, format string) error {
 		return encoder.Encode(results)
 
 	case "text":
-		return displayTextResults(results)
+		return displayTextResults(results, quiet)
 
 	default:
 		return fmt.Errorf("unknown output format: %s", format)
 	}
 }
 
-func displayTextResults(results []*eval.EvalResult) error {
+// displayTextResults prints each task and the overall statistics. In quiet mode,
+// tasks that passed cleanly and the per-difficulty statistics are left out.
+func displayTextResults(results []*eval.EvalResult, quiet bool) error {
 	green := color.New(color.FgGreen)
 	red := color.New(color.FgRed)
 	yellow := color.New(color.FgYellow)
@@ -196,28 +481,62 @@ func displayTextResults(results []*eval.EvalResult) error {
 	bold.Println("=== Results Summary ===")
 	fmt.Println()
 
-	totalTasks := len(results)
+	totalTasks := 0
 	tasksPassed := 0
+	tasksSkipped := 0
+	tasksNotRun := 0
+	expectedFailed := 0
+	unexpectedPassed := 0
 	totalAssertions := 0
 	passedAssertions := 0
+	totalExpectations := 0
+	passedExpectations := 0
 	verificationFailedButAssertionsPassed := 0
 	verificationFailedButAssertionsPassedTotal := 0
 	verificationFailedButAssertionsPassedCount := 0
+	cleanupFailures := 0
 
 	for _, result := range results {
-		if result.TaskPassed {
-			tasksPassed++
+		// Skipped tasks and tasks expected to fail are listed, but not counted
+		switch result.Status() {
+		case eval.TaskStatusSkipped:
+			tasksSkipped++
+			if !quiet {
+				fmt.Printf("Task: %s\n", result.TaskName)
+				fmt.Printf("  Path: %s\n", result.TaskPath)
+				yellow.Printf("  Task Status: SKIPPED (%s)\n", result.SkipReason)
+				fmt.Println()
+			}
+			continue
+		case eval.TaskStatusNotRun:
+			tasksNotRun++
+			continue
+		case eval.TaskStatusExpectedFailure:
+			expectedFailed++
+		case eval.TaskStatusUnexpectedPass:
+			unexpectedPassed++
+		default:
+			totalTasks++
+			if result.TaskPassed {
+				tasksPassed++
+			}
 		}
 
 		// Track cases where verification failed but assertions passed
-		if !result.TaskPassed && result.AllAssertionsPassed && !result.AgentExecutionError {
+		if !result.TaskPassed && result.AllAssertionsPassed && !result.AgentExecutionError && !result.ExpectedFailure {
 			verificationFailedButAssertionsPassed++
 		}
 
 		// Count individual assertions
-		if result.AssertionResults != nil {
+		if result.AssertionResults != nil && !result.ExpectedFailure {
 			totalAssertions += result.AssertionResults.TotalAssertions()
 			passedAssertions += result.AssertionResults.PassedAssertions()
+			for _, res := range result.AssertionResults.Expectations {
+				totalExpectations++
+				if res.Succeeded() {
+					passedExpectations++
+				}
+			}
 
 			// Track assertions for verification-failed tasks
 			if !result.TaskPassed && !result.AgentExecutionError {
@@ -226,14 +545,30 @@ func displayTextResults(results []*eval.EvalResult) error {
 			}
 		}
 
+		cleanupFailed := result.CleanupOutput != nil && !result.CleanupOutput.Success
+		if quiet && (result.Status() == eval.TaskStatusPassed || result.Status() == eval.TaskStatusExpectedFailure) && !cleanupFailed {
+			continue
+		}
+
 		// Display individual result
 		fmt.Printf("Task: %s\n", result.TaskName)
 		fmt.Printf("  Path: %s\n", result.TaskPath)
 		if result.Difficulty != "" {
 			fmt.Printf("  Difficulty: %s\n", result.Difficulty)
 		}
+		// Failing tasks show who to ask and where to look
+		if result.Status() == eval.TaskStatusFailed {
+			printTaskInfo(result)
+		}
 
-		if result.TaskPassed {
+		if result.Status() == eval.TaskStatusExpectedFailure {
+			yellow.Printf("  Task Status: FAILED (expected)\n")
+			if result.TaskError != "" {
+				fmt.Printf("  Error: %s\n", result.TaskError)
+			}
+		} else if result.Status() == eval.TaskStatusUnexpectedPass {
+			yellow.Printf("  Task Status: PASSED (unexpected, the task is expected to fail)\n")
+		} else if result.TaskPassed {
 			green.Printf("  Task Status: PASSED\n")
 		} else {
 			if result.AgentExecutionError {
@@ -257,6 +592,9 @@ func displayTextResults(results []*eval.EvalResult) error {
 				if result.TaskError != "" {
 					fmt.Printf("  Error: %s\n", result.TaskError)
 				}
+				if result.TaskJudgeCategory != "" && result.JudgeTranscriptFile != "" {
+					fmt.Printf("  Judge transcript: %s\n", result.JudgeTranscriptFile)
+				}
 			}
 		}
 
@@ -271,6 +609,16 @@ func displayTextResults(results []*eval.EvalResult) error {
 			}
 		}
 
+		printSafetyFindings(result.SafetyFindings, yellow)
+
+		if printCleanupFailure(result, yellow) {
+			cleanupFailures++
+		}
+
+		if result.Timing != nil {
+			fmt.Printf("  Duration: %s\n", formatTiming(result.Timing))
+		}
+
 		fmt.Println()
 	}
 
@@ -291,6 +639,16 @@ func displayTextResults(results []*eval.EvalResult) error {
 		}
 	}
 
+	// Output expectations are included in the assertions above, and also
+	// reported on their own since they apply to every task
+	if totalExpectations > 0 {
+		if passedExpectations == totalExpectations {
+			green.Printf("Output Expectations Passed: %d/%d\n", passedExpectations, totalExpectations)
+		} else {
+			yellow.Printf("Output Expectations Passed: %d/%d\n", passedExpectations, totalExpectations)
+		}
+	}
+
 	// Show stats for verification-failed tasks
 	if verificationFailedButAssertionsPassed > 0 {
 		fmt.Println()
@@ -302,6 +660,28 @@ func displayTextResults(results []*eval.EvalResult) error {
 		}
 	}
 
+	if cleanupFailures > 0 {
+		fmt.Println()
+		yellow.Printf("Tasks where cleanup failed: %d (resources may have been left behind)\n", cleanupFailures)
+	}
+
+	if tasksSkipped > 0 || tasksNotRun > 0 || expectedFailed > 0 || unexpectedPassed > 0 {
+		fmt.Println()
+	}
+	if tasksSkipped > 0 {
+		yellow.Printf("Tasks skipped: %d\n", tasksSkipped)
+	}
+	if tasksNotRun > 0 {
+		yellow.Printf("Tasks not run: %d (the run stopped early after too many failures)\n", tasksNotRun)
+	}
+	if expectedFailed > 0 || unexpectedPassed > 0 {
+		yellow.Printf("Tasks expected to fail: %d failed, %d passed unexpectedly (not counted)\n", expectedFailed, unexpectedPassed)
+	}
+
+	if quiet {
+		return nil
+	}
+
 	// Group by difficulty
 	fmt.Println()
 	bold.Println("=== Statistics by Difficulty ===")
@@ -310,6 +690,51 @@ func displayTextResults(results []*eval.EvalResult) error {
 	return nil
 }
 
+// printCleanupFailure prints the cleanup failure for a result, if any, and
+// reports whether one was found
+func printCleanupFailure(result *eval.EvalResult, yellow *color.Color) bool {
+	reason := results.CleanupFailure(result)
+	if reason == "" {
+		return false
+	}
+	yellow.Printf("  Cleanup: FAILED\n")
+	fmt.Printf("    %s\n", reason)
+	return true
+}
+
+// printTaskInfo prints the description, owner, and links of a task, if it
+// has them
+func printTaskInfo(result *eval.EvalResult) {
+	if result.Description != "" {
+		fmt.Printf("  Description: %s\n", result.Description)
+	}
+	if result.Owner != "" {
+		fmt.Printf("  Owner: %s\n", result.Owner)
+	}
+	if len(result.Links) > 0 {
+		fmt.Println("  Links:")
+		for _, link := range result.Links {
+			fmt.Printf("    - %s\n", link)
+		}
+	}
+}
+
+// printSafetyFindings prints the findings of the safety scan of a task, if
+// it found anything or failed
+func printSafetyFindings(findings *eval.SafetyFindings, warn *color.Color) {
+	if !findings.Failed() {
+		return
+	}
+
+	warn.Printf("  Safety Findings: %d\n", len(findings.Findings))
+	if findings.Error != "" {
+		fmt.Printf("    Scan error: %s\n", findings.Error)
+	}
+	for _, f := range findings.Findings {
+		fmt.Printf("    - %s in %s: %s\n", f.Rule, f.Location, f.Match)
+	}
+}
+
 func displayStatsByDifficulty(results []*eval.EvalResult, green *color.Color, yellow *color.Color) {
 	// Group results by difficulty
 	type difficultyStats struct {
@@ -322,6 +747,10 @@ func displayStatsByDifficulty(results []*eval.EvalResult, green *color.Color, ye
 	statsByDifficulty := make(map[string]*difficultyStats)
 
 	for _, result := range results {
+		if result.SkipReason != "" || result.ExpectedFailure {
+			continue
+		}
+
 		difficulty := result.Difficulty
 		if difficulty == "" {
 			difficulty = "unspecified"
@@ -402,42 +831,49 @@ func displayStatsByDifficulty(results []*eval.EvalResult, green *color.Color, ye
 }
 
 func printFailedAssertions(results *eval.CompositeAssertionResult) {
-	printSingleAssertion("ToolsUsed", results.ToolsUsed)
-	printSingleAssertion("RequireAny", results.RequireAny)
-	printSingleAssertion("ToolsNotUsed", results.ToolsNotUsed)
-	printSingleAssertion("MinToolCalls", results.MinToolCalls)
-	printSingleAssertion("MaxToolCalls", results.MaxToolCalls)
-	printSingleAssertion("ResourcesRead", results.ResourcesRead)
-	printSingleAssertion("ResourcesNotRead", results.ResourcesNotRead)
-	printSingleAssertion("PromptsUsed", results.PromptsUsed)
-	printSingleAssertion("PromptsNotUsed", results.PromptsNotUsed)
-	printSingleAssertion("CallOrder", results.CallOrder)
-	printSingleAssertion("NoDuplicateCalls", results.NoDuplicateCalls)
+	printSingleAssertion("ToolsUsed", "toolsUsed", results.ToolsUsed)
+	printSingleAssertion("RequireAny", "requireAny", results.RequireAny)
+	printSingleAssertion("ToolsNotUsed", "toolsNotUsed", results.ToolsNotUsed)
+	printSingleAssertion("MinToolCalls", "minToolCalls", results.MinToolCalls)
+	printSingleAssertion("MaxToolCalls", "maxToolCalls", results.MaxToolCalls)
+	printSingleAssertion("ToolCallCounts", "toolCallCounts", results.ToolCallCounts)
+	printSingleAssertion("ResourcesRead", "resourcesRead", results.ResourcesRead)
+	printSingleAssertion("ResourcesNotRead", "resourcesNotRead", results.ResourcesNotRead)
+	printSingleAssertion("PromptsUsed", "promptsUsed", results.PromptsUsed)
+	printSingleAssertion("PromptsNotUsed", "promptsNotUsed", results.PromptsNotUsed)
+	printSingleAssertion("CallOrder", "callOrder", results.CallOrder)
+	printSingleAssertion("Phases", "phases", results.Phases)
+	printSingleAssertion("NoDuplicateCalls", "noDuplicateCalls", results.NoDuplicateCalls)
+	printSingleAssertion("MaxAgentDuration", "maxAgentDuration", results.MaxAgentDuration)
+	printSingleAssertion("MaxContextTokens", "maxContextTokens", results.MaxContextTokens)
+	printSingleAssertion("GroundedOutput", "groundedOutput", results.GroundedOutput)
+	printSingleAssertion("Expr", "expr", results.Expr)
+	for _, name := range slices.Sorted(maps.Keys(results.Custom)) {
+		printSingleAssertion(name, "custom", results.Custom[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(results.Expectations)) {
+		printSingleAssertion("Expectation "+name, "expectations", results.Expectations[name])
+	}
 }
 
-func printSingleAssertion(name string, result *eval.SingleAssertionResult) {
+// printSingleAssertion prints why an assertion failed, with the hint and
+// documentation link of the assertion named key
+func printSingleAssertion(name, key string, result *eval.SingleAssertionResult) {
 	if result != nil && !result.Passed {
 		fmt.Printf("    - %s: %s\n", name, result.Reason)
 		for _, detail := range result.Details {
 			fmt.Printf("      %s\n", detail)
 		}
+		printAssertionHint(key)
 	}
 }
 
-func saveResultsToFile(results []*eval.EvalResult, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+// printAssertionHint prints the hint and documentation link of a failed
+// assertion, if it is documented
+func printAssertionHint(key string) {
+	if doc, ok := eval.LookupAssertionDoc(key); ok {
+		fmt.Printf("      Hint: %s (%s)\n", doc.Hint, doc.URL())
 	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(results); err != nil {
-		return fmt.Errorf("failed to encode results: %w", err)
-	}
-
-	return nil
 }
 
 // saveErrorToFile saves task error and output to a file and returns the filename
@@ -466,3 +902,51 @@ func saveErrorToFile(taskName, taskError, taskOutput string) (string, error) {
 
 	return absPath, nil
 }
+
+// writeBundle writes the results to a bundle signed with key, with the
+// digests of the eval config, the fragments it includes, the files it refers
+// to, and the task files
+func writeBundle(res []*eval.EvalResult, spec *eval.EvalSpec, configFile, path string, key ed25519.PrivateKey) error {
+	absConfig, err := filepath.Abs(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for '%s': %w", configFile, err)
+	}
+
+	files := append([]string{absConfig}, spec.Includes()...)
+	if agent := spec.Config.Agent; agent != nil && agent.Type == "file" {
+		files = append(files, agent.Path)
+	}
+	for _, ts := range spec.Config.TaskSets {
+		if ts.Agent != nil && ts.Agent.Type == "file" {
+			files = append(files, ts.Agent.Path)
+		}
+	}
+	if spec.Config.McpConfigFile != "" {
+		files = append(files, spec.Config.McpConfigFile)
+	}
+	files = append(files, spec.Config.McpConfigFiles...)
+	if spec.Config.QuarantineFile != "" {
+		files = append(files, spec.Config.QuarantineFile)
+	}
+	// MCP config files from the command line are relative to the working
+	// directory
+	for i, f := range files {
+		if abs, err := filepath.Abs(f); err == nil {
+			files[i] = abs
+		}
+	}
+	slices.Sort(files)
+	files = slices.Compact(files)
+
+	bundle, err := results.NewBundle(res, spec.Metadata.Name, spec.BasePath(), files)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	if err := bundle.Sign(key); err != nil {
+		return fmt.Errorf("failed to sign bundle: %w", err)
+	}
+	if err := results.SaveBundle(bundle, path); err != nil {
+		return fmt.Errorf("failed to save bundle: %w", err)
+	}
+	return nil
+}
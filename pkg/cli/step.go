@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// NewStepCmd creates the step command for working with the steps of a task
+func NewStepCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "step",
+		Short: "Debug the steps of a task",
+	}
+
+	cmd.AddCommand(newStepRunCmd())
+
+	return cmd
+}
+
+func newStepRunCmd() *cobra.Command {
+	var (
+		taskFile        string
+		stepID          string
+		taskName        string
+		resultsFile     string
+		evalFile        string
+		outputFormat    string
+		updateSnapshots bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run one setup, verify, or cleanup step of a task",
+		Long: `Run one setup, verify, or cleanup step of a task on its own, without running
+the agent or the other steps, to debug a failing step.
+
+Steps are named by phase and index, counting from 0, as in the results and
+progress output. With --results, verify steps see the agent output of the task
+in that earlier run, read back from the artifact directory if it was
+truncated. With --eval, the extensions and LLM judge of the eval file are
+available to the step.
+
+Exits with code 1 if the step fails.
+
+Examples:
+  mcpchecker step run --task tasks/list-pods.yaml --step verify[1] --results results.json
+  mcpchecker step run --task tasks/list-pods.yaml --step verify[0] --results results.json --eval eval.yaml
+  mcpchecker step run --task tasks/questions.yaml --name questions-3 --step verify[0] --results results.json`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+
+			taskCfg, err := loadStepTask(taskFile, taskName)
+			if err != nil {
+				return err
+			}
+
+			var result *eval.EvalResult
+			if resultsFile != "" {
+				result, err = findTaskResult(resultsFile, taskCfg.Metadata.Name)
+				if err != nil {
+					return err
+				}
+			}
+
+			var spec *eval.EvalSpec
+			if evalFile != "" {
+				spec, err = eval.FromFile(evalFile)
+				if err != nil {
+					return fmt.Errorf("failed to load eval file: %w", err)
+				}
+			}
+
+			ctx := util.WithUpdateSnapshots(cmd.Context(), updateSnapshots)
+			out, stepErr := eval.RunTaskStep(ctx, spec, taskCfg, stepID, result)
+			if out == nil && stepErr != nil {
+				return stepErr
+			}
+
+			if outputFormat == "json" {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(out); err != nil {
+					return err
+				}
+			} else {
+				printStepOutput(taskCfg.Metadata.Name, stepID, out)
+			}
+
+			if stepErr != nil {
+				return stepErr
+			}
+			if !out.Success {
+				return fmt.Errorf("%s failed", stepID)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&taskFile, "task", "", "Task file of the step")
+	cmd.Flags().StringVar(&stepID, "step", "", "Step to run, like verify[0]")
+	cmd.Flags().StringVar(&taskName, "name", "", "Task to run the step of, for task files with a dataset")
+	cmd.Flags().StringVar(&resultsFile, "results", "", "Results of an earlier run to take the agent output from")
+	cmd.Flags().StringVar(&evalFile, "eval", "", "Eval file whose extensions and LLM judge the step uses")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
+	cmd.Flags().BoolVar(&updateSnapshots, "update-snapshots", false, "Rewrite the golden file of a snapshot step with the current output instead of comparing against it")
+	_ = cmd.MarkFlagRequired("task")
+	_ = cmd.MarkFlagRequired("step")
+
+	return cmd
+}
+
+// loadStepTask reads a task file. Of a task file with a dataset, the task
+// named name is returned.
+func loadStepTask(path, name string) (*task.TaskConfig, error) {
+	taskCfg, err := task.FromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := taskCfg.Expand()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		if len(tasks) > 1 {
+			return nil, fmt.Errorf("%s has %d tasks, choose one with --name", path, len(tasks))
+		}
+		return tasks[0], nil
+	}
+
+	names := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		if t.Metadata.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Metadata.Name)
+	}
+	return nil, fmt.Errorf("%s has no task %q, its tasks are: %s", path, name, strings.Join(names, ", "))
+}
+
+// findTaskResult returns the result of a task in a results file. If the task
+// ran more than once, the first result is used.
+func findTaskResult(path, name string) (*eval.EvalResult, error) {
+	evalResults, err := results.LoadFiltered(path, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load results file: %w", err)
+	}
+
+	for _, r := range evalResults {
+		if r.TaskName == name {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("no result for task %s in %s", name, path)
+}
+
+// printStepOutput prints the outcome of a step run on its own
+func printStepOutput(taskName, stepID string, out *steps.StepOutput) {
+	bold := color.New(color.Bold)
+
+	bold.Printf("Task: %s\n", taskName)
+	fmt.Printf("  Step: %s", stepID)
+	if out.Type != "" {
+		fmt.Printf(" (%s)", out.Type)
+	}
+	fmt.Println()
+
+	if out.Success {
+		color.New(color.FgGreen).Println("  Status: PASSED")
+	} else {
+		color.New(color.FgRed).Println("  Status: FAILED")
+	}
+	if out.Duration > 0 {
+		fmt.Printf("  Duration: %s\n", out.Duration)
+	}
+	if message := strings.TrimSpace(out.Message); message != "" {
+		printMultilineField("Message", message)
+	}
+	if stepErr := strings.TrimSpace(out.Error); stepErr != "" {
+		printMultilineField("Error", stepErr)
+	}
+	if len(out.Outputs) > 0 {
+		fmt.Println("  Outputs:")
+		for _, key := range slices.Sorted(maps.Keys(out.Outputs)) {
+			fmt.Printf("    %s: %s\n", key, truncateString(out.Outputs[key], defaultMaxLineLength))
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+)
+
+const stepTask = `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: count-pods
+  difficulty: easy
+spec:
+  prompt:
+    inline: How many pods are running?
+  verify:
+    - script:
+        inline: exit 0
+    - extract:
+        regex: "Answer: (\\d+)"
+        expect:
+          number: 3
+`
+
+// writeStepTask writes the step task and returns its path
+func writeStepTask(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "task.yaml")
+	if err := os.WriteFile(path, []byte(stepTask), 0644); err != nil {
+		t.Fatalf("failed to write task: %v", err)
+	}
+	return path
+}
+
+// runStepCmd runs the step run command with JSON output and returns the step
+// output and the error of the command
+func runStepCmd(t *testing.T, args ...string) (*steps.StepOutput, error) {
+	t.Helper()
+
+	cmd := newStepRunCmd()
+	cmd.SetArgs(append(args, "--output", "json"))
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	err := cmd.Execute()
+	if buf.Len() == 0 {
+		return nil, err
+	}
+
+	out := &steps.StepOutput{}
+	if jsonErr := json.Unmarshal(buf.Bytes(), out); jsonErr != nil {
+		t.Fatalf("failed to parse step output %q: %v", buf.String(), jsonErr)
+	}
+	return out, err
+}
+
+func TestStepRunUsesAgentOutputOfResults(t *testing.T) {
+	taskFile := writeStepTask(t)
+
+	passed := createTestResultsFile(t, []*eval.EvalResult{{TaskName: "count-pods", TaskOutput: "Answer: 3"}})
+	out, err := runStepCmd(t, "--task", taskFile, "--step", "verify[1]", "--results", passed)
+	if err != nil {
+		t.Fatalf("expected the step to pass, got %v", err)
+	}
+	if !out.Success || out.Type != "extract" || out.Outputs["answer"] != "3" {
+		t.Errorf("unexpected step output %+v", out)
+	}
+
+	failed := createTestResultsFile(t, []*eval.EvalResult{{TaskName: "count-pods", TaskOutput: "Answer: 4"}})
+	out, err = runStepCmd(t, "--task", taskFile, "--step", "verify[1]", "--results", failed)
+	if err == nil || err.Error() != "verify[1] failed" {
+		t.Errorf("expected the step to fail, got %v", err)
+	}
+	if out == nil || out.Success {
+		t.Errorf("expected a failed step output, got %+v", out)
+	}
+}
+
+func TestStepRunWithoutResults(t *testing.T) {
+	out, err := runStepCmd(t, "--task", writeStepTask(t), "--step", "verify[0]")
+	if err != nil {
+		t.Fatalf("expected the script step to pass, got %v", err)
+	}
+	if !out.Success || out.Type != "script" {
+		t.Errorf("unexpected step output %+v", out)
+	}
+}
+
+func TestStepRunErrors(t *testing.T) {
+	taskFile := writeStepTask(t)
+	otherTask := createTestResultsFile(t, []*eval.EvalResult{{TaskName: "list-pods", TaskOutput: "Answer: 3"}})
+
+	tests := map[string]struct {
+		args        []string
+		errContains string
+	}{
+		"missing step": {
+			args:        []string{"--task", taskFile, "--step", "verify[2]"},
+			errContains: "there is no verify[2], the task has 2 verify steps",
+		},
+		"invalid step": {
+			args:        []string{"--task", taskFile, "--step", "agent[0]"},
+			errContains: "phase must be setup, verify, or cleanup",
+		},
+		"task not in results": {
+			args:        []string{"--task", taskFile, "--step", "verify[1]", "--results", otherTask},
+			errContains: "no result for task count-pods",
+		},
+		"unknown task name": {
+			args:        []string{"--task", taskFile, "--step", "verify[1]", "--name", "list-pods"},
+			errContains: `has no task "list-pods", its tasks are: count-pods`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := runStepCmd(t, tc.args...)
+			if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+				t.Errorf("expected error containing %q, got %v", tc.errContains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/mcpchecker/mcpchecker/pkg/stubserver"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+// NewStubServerCmd creates the stub-server command
+func NewStubServerCmd() *cobra.Command {
+	var httpAddr string
+
+	cmd := &cobra.Command{
+		Use:   "stub-server <config-file>",
+		Short: "Run an MCP server whose tools answer with responses configured in YAML",
+		Long: `Run an MCP server whose tools, input schemas, and responses are configured in
+a YAML file, for task fixtures and demos that need an MCP server without
+writing one:
+
+  name: inventory
+  state: empty
+  tools:
+    - name: add_item
+      inputSchema:
+        type: object
+        properties:
+          name: {type: string}
+        required: [name]
+      responses:
+        - when: {args: {name: forbidden}}
+          text: "Cannot add {{ .Args.name }}"
+          isError: true
+        - text: "Added {{ .Args.name }} (call {{ .Call }})"
+          setState: stocked
+    - name: list_items
+      responses:
+        - when: {state: empty}
+          text: "[]"
+        - text: '[{"name": "widget"}]'
+
+Responses are tried in order, and the first one whose when matches the state
+of the server, the arguments, and the number of the call answers it. Texts
+are Go templates with .Args, .State, .Tool, .Call, and the json function.
+setState moves the server to another state after responding.
+
+The server talks MCP over stdio, or over streamable HTTP at /mcp with --http.
+
+Examples:
+  mcpchecker stub-server stub.yaml
+  mcpchecker stub-server stub.yaml --http localhost:8080`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := stubserver.Load(args[0])
+			if err != nil {
+				return err
+			}
+			server := stubserver.New(cfg).MCPServer()
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			if httpAddr == "" {
+				return server.Run(ctx, &mcp.StdioTransport{})
+			}
+			return serveStubServer(ctx, server, httpAddr)
+		},
+	}
+
+	cmd.Flags().StringVar(&httpAddr, "http", "", "Serve streamable HTTP at this address instead of stdio")
+
+	return cmd
+}
+
+// serveStubServer serves the server over streamable HTTP until ctx is done
+func serveStubServer(ctx context.Context, server *mcp.Server, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return server
+	}, nil))
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving MCP at http://%s/mcp\n", listener.Addr())
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -3,37 +3,63 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
 	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/spf13/cobra"
 )
 
 type SummaryOutput struct {
-	ResultsFile       string        `json:"resultsFile"`
-	Tasks             []TaskSummary `json:"tasks"`
-	TasksTotal        int           `json:"tasksTotal"`
-	TasksPassed       int           `json:"tasksPassed"`
-	TaskPassRate      float64       `json:"taskPassRate"`
-	AssertionsTotal   int           `json:"assertionsTotal"`
-	AssertionsPassed  int           `json:"assertionsPassed"`
-	AssertionPassRate float64       `json:"assertionPassRate"`
+	ResultsFile       string         `json:"resultsFile"`
+	Tasks             []TaskSummary  `json:"tasks"`
+	TasksTotal        int            `json:"tasksTotal"`
+	TasksPassed       int            `json:"tasksPassed"`
+	TaskPassRate      float64        `json:"taskPassRate"`
+	AssertionsTotal   int            `json:"assertionsTotal"`
+	AssertionsPassed  int            `json:"assertionsPassed"`
+	AssertionPassRate float64        `json:"assertionPassRate"`
+	CleanupFailures   int            `json:"cleanupFailures"`
+	JudgeFailures     map[string]int `json:"judgeFailures,omitempty"`
+	TasksQuarantined  int            `json:"tasksQuarantined,omitempty"`
+	QuarantinedFailed int            `json:"quarantinedFailed,omitempty"`
+
+	TasksSkipped          int `json:"tasksSkipped,omitempty"`
+	TasksExpectedFailed   int `json:"tasksExpectedFailed,omitempty"`
+	TasksUnexpectedPassed int `json:"tasksUnexpectedPassed,omitempty"`
+	TasksNotRun           int `json:"tasksNotRun,omitempty"`
+
+	// Environment is the environment info reported by the extensions of the run
+	Environment eval.Environment `json:"environment,omitempty"`
 }
 
 type TaskSummary struct {
 	Name             string   `json:"name"`
+	Status           string   `json:"status"`
 	TaskPassed       bool     `json:"taskPassed"`
 	AssertionsPassed bool     `json:"assertionsPassed"`
+	Quarantined      bool     `json:"quarantined,omitempty"`
+	SkipReason       string   `json:"skipReason,omitempty"`
 	TaskError        string   `json:"taskError,omitempty"`
+	CleanupError     string   `json:"cleanupError,omitempty"`
+	JudgeCategory    string   `json:"judgeCategory,omitempty"`
 	FailedAssertions []string `json:"failedAssertions,omitempty"`
+	Owner            string   `json:"owner,omitempty"`
+	Links            []string `json:"links,omitempty"`
 }
 
 func NewSummaryCmd() *cobra.Command {
 	var taskFilter string
 	var outputFormat string
 	var githubOutput bool
+	var quarantineFile string
+	var filters queryFlags
 
 	cmd := &cobra.Command{
 		Use:   "summary <results-file>",
@@ -49,13 +75,23 @@ Supports multiple output formats:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			resultsFile := args[0]
 
+			query, err := filters.query(taskFilter)
+			if err != nil {
+				return err
+			}
+
 			evalResults, err := results.Load(resultsFile)
 			if err != nil {
 				return fmt.Errorf("failed to load results file: %w", err)
 			}
-
-			if taskFilter != "" {
-				evalResults = results.Filter(evalResults, taskFilter)
+			evalResults = results.Select(evalResults, query)
+
+			if quarantineFile != "" {
+				quarantine, err := eval.LoadQuarantine(quarantineFile)
+				if err != nil {
+					return err
+				}
+				results.ApplyQuarantine(evalResults, quarantine)
 			}
 
 			summary := buildSummaryOutput(resultsFile, evalResults)
@@ -79,8 +115,10 @@ Supports multiple output formats:
 	}
 
 	cmd.Flags().StringVar(&taskFilter, "task", "", "Filter results by task name")
+	filters.register(cmd)
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
 	cmd.Flags().BoolVar(&githubOutput, "github-output", false, "Output in GitHub Actions format (key=value)")
+	cmd.Flags().StringVar(&quarantineFile, "quarantine", "", "Quarantine file listing tasks whose failures are not counted")
 
 	return cmd
 }
@@ -89,22 +127,49 @@ func buildSummaryOutput(resultsFile string, evalResults []*eval.EvalResult) Summ
 	summary := SummaryOutput{
 		ResultsFile: resultsFile,
 		Tasks:       make([]TaskSummary, 0, len(evalResults)),
-		TasksTotal:  len(evalResults),
+		Environment: results.Environment(evalResults),
 	}
 
 	for _, result := range evalResults {
 		taskSummary := TaskSummary{
 			Name:             result.TaskName,
+			Status:           string(result.Status()),
 			TaskPassed:       result.TaskPassed,
 			AssertionsPassed: result.AllAssertionsPassed,
+			Quarantined:      result.Quarantined,
+			SkipReason:       result.SkipReason,
+			Owner:            result.Owner,
+			Links:            linkStrings(result.Links),
+		}
+
+		// Skipped, expected to fail, and quarantined tasks are listed but
+		// left out of the totals
+		switch result.Status() {
+		case eval.TaskStatusSkipped:
+			summary.TasksSkipped++
+		case eval.TaskStatusNotRun:
+			summary.TasksNotRun++
+		case eval.TaskStatusExpectedFailure:
+			summary.TasksExpectedFailed++
+		case eval.TaskStatusUnexpectedPass:
+			summary.TasksUnexpectedPassed++
+		default:
+			if result.Quarantined {
+				summary.TasksQuarantined++
+				if !result.TaskPassed || !result.AllAssertionsPassed {
+					summary.QuarantinedFailed++
+				}
+			} else {
+				summary.TasksTotal++
+			}
 		}
 
-		if result.TaskPassed {
+		if result.TaskPassed && result.Counted() {
 			summary.TasksPassed++
 		}
 
 		// Collect task error
-		if !result.TaskPassed {
+		if !result.TaskPassed && result.SkipReason == "" {
 			if result.AgentExecutionError {
 				taskSummary.TaskError = "Agent execution failed"
 			} else if result.TaskError != "" {
@@ -112,10 +177,27 @@ func buildSummaryOutput(resultsFile string, evalResults []*eval.EvalResult) Summ
 			}
 		}
 
+		// Collect judge failure categories
+		taskSummary.JudgeCategory = results.JudgeFailureCategory(result)
+		if taskSummary.JudgeCategory != "" && result.Counted() {
+			if summary.JudgeFailures == nil {
+				summary.JudgeFailures = make(map[string]int)
+			}
+			summary.JudgeFailures[taskSummary.JudgeCategory]++
+		}
+
+		// Collect cleanup failures, which can leak resources into later runs
+		taskSummary.CleanupError = results.CleanupFailure(result)
+		if taskSummary.CleanupError != "" && result.Counted() {
+			summary.CleanupFailures++
+		}
+
 		// Count assertions and collect failures
 		if result.AssertionResults != nil {
-			summary.AssertionsTotal += result.AssertionResults.TotalAssertions()
-			summary.AssertionsPassed += result.AssertionResults.PassedAssertions()
+			if result.Counted() {
+				summary.AssertionsTotal += result.AssertionResults.TotalAssertions()
+				summary.AssertionsPassed += result.AssertionResults.PassedAssertions()
+			}
 
 			if !result.AllAssertionsPassed {
 				taskSummary.FailedAssertions = results.CollectFailedAssertions(result.AssertionResults)
@@ -159,7 +241,13 @@ func outputTextSummary(evalResults []*eval.EvalResult, summary SummaryOutput) {
 		}
 
 		// Print task line
-		if passed {
+		if result.NotRun {
+			yellow.Printf("  - %s [not run]\n", result.TaskName)
+			continue
+		} else if result.SkipReason != "" {
+			yellow.Printf("  - %s [skipped: %s]\n", result.TaskName, result.SkipReason)
+			continue
+		} else if passed {
 			green.Printf("  ✓ %s", result.TaskName)
 		} else if result.TaskPassed && !result.AllAssertionsPassed {
 			yellow.Printf("  ~ %s", result.TaskName)
@@ -171,6 +259,15 @@ func outputTextSummary(evalResults []*eval.EvalResult, summary SummaryOutput) {
 		if taskAssertionsTotal > 0 {
 			fmt.Printf(" (assertions: %d/%d)", taskAssertionsPassed, taskAssertionsTotal)
 		}
+		if taskSummary.Quarantined {
+			yellow.Print(" [quarantined]")
+		}
+		switch result.Status() {
+		case eval.TaskStatusExpectedFailure:
+			yellow.Print(" [expected failure]")
+		case eval.TaskStatusUnexpectedPass:
+			yellow.Print(" [unexpected pass]")
+		}
 		fmt.Println()
 
 		// Print failure details
@@ -182,6 +279,24 @@ func outputTextSummary(evalResults []*eval.EvalResult, summary SummaryOutput) {
 		for _, failure := range taskSummary.FailedAssertions {
 			red.Printf("      - %s\n", failure)
 		}
+
+		if taskSummary.JudgeCategory != "" {
+			red.Printf("      judge: %s\n", taskSummary.JudgeCategory)
+		}
+
+		if taskSummary.CleanupError != "" {
+			yellow.Printf("      cleanup failed: %s\n", taskSummary.CleanupError)
+		}
+
+		// Failing tasks show who to ask and where to look
+		if result.Status() == eval.TaskStatusFailed {
+			if taskSummary.Owner != "" {
+				fmt.Printf("      owner: %s\n", taskSummary.Owner)
+			}
+			for _, link := range taskSummary.Links {
+				fmt.Printf("      link: %s\n", link)
+			}
+		}
 	}
 
 	// Print totals
@@ -190,6 +305,53 @@ func outputTextSummary(evalResults []*eval.EvalResult, summary SummaryOutput) {
 		summary.TasksPassed, summary.TasksTotal, summary.TaskPassRate*100)
 	fmt.Printf("Assertions: %d/%d passed (%.2f%%)\n",
 		summary.AssertionsPassed, summary.AssertionsTotal, summary.AssertionPassRate*100)
+	if len(summary.JudgeFailures) > 0 {
+		fmt.Printf("Judge:      %s\n", formatJudgeFailures(summary.JudgeFailures))
+	}
+	if summary.CleanupFailures > 0 {
+		yellow.Printf("Cleanup:    %d task(s) failed to clean up\n", summary.CleanupFailures)
+	}
+	if summary.TasksQuarantined > 0 {
+		yellow.Printf("Quarantine: %d task(s) not counted, %d failed\n", summary.TasksQuarantined, summary.QuarantinedFailed)
+	}
+	if summary.TasksSkipped > 0 {
+		yellow.Printf("Skipped:    %d task(s)\n", summary.TasksSkipped)
+	}
+	if summary.TasksNotRun > 0 {
+		yellow.Printf("Not run:    %d task(s), the run stopped after too many failures\n", summary.TasksNotRun)
+	}
+	if summary.TasksExpectedFailed > 0 || summary.TasksUnexpectedPassed > 0 {
+		yellow.Printf("Expected:   %d failed as expected, %d passed unexpectedly (not counted)\n",
+			summary.TasksExpectedFailed, summary.TasksUnexpectedPassed)
+	}
+	if len(summary.Environment) > 0 {
+		fmt.Println("Environment:")
+		environment := summary.Environment.Flatten()
+		for _, key := range slices.Sorted(maps.Keys(environment)) {
+			fmt.Printf("  %s: %s\n", key, environment[key])
+		}
+	}
+}
+
+// linkStrings returns the links of a task as strings
+func linkStrings(links []task.TaskLink) []string {
+	if len(links) == 0 {
+		return nil
+	}
+	strs := make([]string, len(links))
+	for i, link := range links {
+		strs[i] = link.String()
+	}
+	return strs
+}
+
+// formatJudgeFailures formats judge failure counts as "semantic_mismatch: 2, missing_information: 1"
+func formatJudgeFailures(counts map[string]int) string {
+	var parts []string
+	for _, category := range results.JudgeCategories(counts) {
+		parts = append(parts, fmt.Sprintf("%s: %d", category, counts[category]))
+	}
+	return strings.Join(parts, ", ")
 }
 
 func outputJSONSummary(summary SummaryOutput) error {
@@ -206,4 +368,21 @@ func outputGitHubSummary(summary SummaryOutput) {
 	fmt.Printf("assertions-total=%d\n", summary.AssertionsTotal)
 	fmt.Printf("assertions-passed=%d\n", summary.AssertionsPassed)
 	fmt.Printf("assertion-pass-rate=%.4f\n", summary.AssertionPassRate)
+	fmt.Printf("cleanup-failures=%d\n", summary.CleanupFailures)
+	fmt.Printf("tasks-quarantined=%d\n", summary.TasksQuarantined)
+	fmt.Printf("quarantined-failed=%d\n", summary.QuarantinedFailed)
+	fmt.Printf("tasks-skipped=%d\n", summary.TasksSkipped)
+	fmt.Printf("tasks-expected-failed=%d\n", summary.TasksExpectedFailed)
+	fmt.Printf("tasks-unexpected-passed=%d\n", summary.TasksUnexpectedPassed)
+	fmt.Printf("tasks-not-run=%d\n", summary.TasksNotRun)
+
+	// Known categories are always printed so workflows can rely on the keys
+	judgeFailures := 0
+	for _, n := range summary.JudgeFailures {
+		judgeFailures += n
+	}
+	fmt.Printf("judge-failures=%d\n", judgeFailures)
+	for _, category := range llmjudge.FailureCategories {
+		fmt.Printf("judge-%s=%d\n", strings.ReplaceAll(category, "_", "-"), summary.JudgeFailures[category])
+	}
 }
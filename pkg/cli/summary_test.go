@@ -2,9 +2,11 @@ package cli
 
 import (
 	"bytes"
+	"slices"
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 )
 
 func TestSummaryCommand(t *testing.T) {
@@ -129,6 +131,24 @@ func TestBuildSummaryOutput(t *testing.T) {
 	}
 }
 
+func TestBuildSummaryOutputEnvironment(t *testing.T) {
+	results := sampleResults()
+	if summary := buildSummaryOutput("test.json", results); summary.Environment != nil {
+		t.Errorf("Environment = %v, want nil without extension info", summary.Environment)
+	}
+
+	for _, r := range results {
+		r.Environment = eval.Environment{"kube": {"clusterVersion": "v1.30.2"}}
+	}
+	summary := buildSummaryOutput("test.json", results)
+	if got := summary.Environment["kube"]["clusterVersion"]; got != "v1.30.2" {
+		t.Errorf("Environment = %v, want kube.clusterVersion v1.30.2", summary.Environment)
+	}
+
+	// Just ensure it doesn't panic
+	outputTextSummary(results, summary)
+}
+
 func TestOutputTextSummary(t *testing.T) {
 	results := sampleResults()
 	summary := buildSummaryOutput("test.json", results)
@@ -186,3 +206,143 @@ func TestOutputTextSummaryAgentExecutionError(t *testing.T) {
 	// Just ensure it doesn't panic
 	outputTextSummary(results, summary)
 }
+
+func TestBuildSummaryOutputCleanupFailure(t *testing.T) {
+	results := sampleResults()
+	results[0].CleanupOutput = &task.PhaseOutput{Success: false, Error: "cleanup[0] failed: exit status 1"}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.CleanupFailures != 1 {
+		t.Errorf("CleanupFailures = %d, want 1", summary.CleanupFailures)
+	}
+	if summary.Tasks[0].CleanupError != "cleanup[0] failed: exit status 1" {
+		t.Errorf("Tasks[0].CleanupError = %q, want cleanup error", summary.Tasks[0].CleanupError)
+	}
+	if summary.Tasks[1].CleanupError != "" {
+		t.Errorf("Tasks[1].CleanupError = %q, want empty", summary.Tasks[1].CleanupError)
+	}
+
+	// Just ensure it doesn't panic
+	outputTextSummary(results, summary)
+}
+
+func TestBuildSummaryOutputJudgeFailures(t *testing.T) {
+	results := sampleResults()
+	results[2].TaskJudgeCategory = "missing_information"
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.JudgeFailures["missing_information"] != 1 || len(summary.JudgeFailures) != 1 {
+		t.Errorf("JudgeFailures = %v, want missing_information: 1", summary.JudgeFailures)
+	}
+	if summary.Tasks[2].JudgeCategory != "missing_information" {
+		t.Errorf("Tasks[2].JudgeCategory = %q, want missing_information", summary.Tasks[2].JudgeCategory)
+	}
+	if got := formatJudgeFailures(summary.JudgeFailures); got != "missing_information: 1" {
+		t.Errorf("formatJudgeFailures() = %q, want %q", got, "missing_information: 1")
+	}
+
+	// Just ensure it doesn't panic
+	outputTextSummary(results, summary)
+	outputGitHubSummary(summary)
+}
+
+func TestBuildSummaryOutputQuarantine(t *testing.T) {
+	results := sampleResults()
+	results[2].Quarantined = true
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.TasksTotal != 2 || summary.TasksPassed != 2 {
+		t.Errorf("tasks = %d/%d, want 2/2", summary.TasksPassed, summary.TasksTotal)
+	}
+	if summary.TasksQuarantined != 1 || summary.QuarantinedFailed != 1 {
+		t.Errorf("quarantined = %d (%d failed), want 1 (1 failed)", summary.TasksQuarantined, summary.QuarantinedFailed)
+	}
+	if !summary.Tasks[2].Quarantined {
+		t.Errorf("Tasks[2].Quarantined = false, want true")
+	}
+
+	// Just ensure it doesn't panic
+	outputTextSummary(results, summary)
+	outputGitHubSummary(summary)
+}
+
+func TestBuildSummaryOutputSkipAndExpectedFailure(t *testing.T) {
+	results := sampleResults()
+	results[0].ExpectedFailure = true
+	results[2].ExpectedFailure = true
+	results = append(results, &eval.EvalResult{TaskName: "task-4", SkipReason: "needs a GPU node"})
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.TasksTotal != 1 || summary.TasksPassed != 1 {
+		t.Errorf("tasks = %d/%d, want 1/1", summary.TasksPassed, summary.TasksTotal)
+	}
+	if summary.AssertionsTotal != 2 || summary.AssertionsPassed != 1 {
+		t.Errorf("assertions = %d/%d, want 1/2", summary.AssertionsPassed, summary.AssertionsTotal)
+	}
+	if summary.TasksSkipped != 1 || summary.TasksExpectedFailed != 1 || summary.TasksUnexpectedPassed != 1 {
+		t.Errorf("skipped, expected failed, unexpected passed = %d, %d, %d, want 1, 1, 1",
+			summary.TasksSkipped, summary.TasksExpectedFailed, summary.TasksUnexpectedPassed)
+	}
+
+	wantStatus := []eval.TaskStatus{eval.TaskStatusUnexpectedPass, eval.TaskStatusFailed, eval.TaskStatusExpectedFailure, eval.TaskStatusSkipped}
+	for i, want := range wantStatus {
+		if got := summary.Tasks[i].Status; got != string(want) {
+			t.Errorf("Tasks[%d].Status = %q, want %q", i, got, want)
+		}
+	}
+	if summary.Tasks[3].SkipReason != "needs a GPU node" || summary.Tasks[3].TaskError != "" {
+		t.Errorf("Tasks[3] = %+v, want skip reason and no error", summary.Tasks[3])
+	}
+
+	// Just ensure it doesn't panic
+	outputTextSummary(results, summary)
+	outputGitHubSummary(summary)
+}
+
+func TestBuildSummaryOutputNotRun(t *testing.T) {
+	results := sampleResults()
+	results[1] = &eval.EvalResult{TaskName: "task-2", SkipReason: "the run stopped after 1 failed tasks", NotRun: true}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.TasksTotal != 2 || summary.TasksNotRun != 1 || summary.TasksSkipped != 0 {
+		t.Errorf("total, not run, skipped = %d, %d, %d, want 2, 1, 0",
+			summary.TasksTotal, summary.TasksNotRun, summary.TasksSkipped)
+	}
+	if got := summary.Tasks[1].Status; got != string(eval.TaskStatusNotRun) {
+		t.Errorf("Tasks[1].Status = %q, want %q", got, eval.TaskStatusNotRun)
+	}
+
+	// Just ensure it doesn't panic
+	outputTextSummary(results, summary)
+	outputGitHubSummary(summary)
+}
+
+func TestBuildSummaryOutputOwnerAndLinks(t *testing.T) {
+	results := sampleResults()
+	results[2].Owner = "@platform-team"
+	results[2].Links = []task.TaskLink{
+		{Name: "runbook", URL: "https://example.com/runbooks/pods"},
+		{URL: "https://example.com/issues/42"},
+	}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.Tasks[2].Owner != "@platform-team" {
+		t.Errorf("Tasks[2].Owner = %q, want @platform-team", summary.Tasks[2].Owner)
+	}
+	wantLinks := []string{"runbook: https://example.com/runbooks/pods", "https://example.com/issues/42"}
+	if !slices.Equal(summary.Tasks[2].Links, wantLinks) {
+		t.Errorf("Tasks[2].Links = %v, want %v", summary.Tasks[2].Links, wantLinks)
+	}
+	if summary.Tasks[0].Owner != "" || summary.Tasks[0].Links != nil {
+		t.Errorf("Tasks[0] has owner %q and links %v, want none", summary.Tasks[0].Owner, summary.Tasks[0].Links)
+	}
+
+	// Just ensure it doesn't panic
+	outputTextSummary(results, summary)
+}
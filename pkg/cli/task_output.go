@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+)
+
+const (
+	// goTemplatePrefix selects an inline Go template as the output format
+	goTemplatePrefix = "go-template="
+	// goTemplateFilePrefix selects a Go template file as the output format
+	goTemplateFilePrefix = "go-template-file="
+)
+
+// taskColumn is a column of --columns
+type taskColumn struct {
+	name  string
+	value func(r *eval.EvalResult) string
+}
+
+// taskColumns are the columns --columns can show, besides label:<key>
+var taskColumns = []taskColumn{
+	{"name", func(r *eval.EvalResult) string { return r.TaskName }},
+	{"path", func(r *eval.EvalResult) string { return r.TaskPath }},
+	{"status", func(r *eval.EvalResult) string { return string(r.Status()) }},
+	{"difficulty", func(r *eval.EvalResult) string { return r.Difficulty }},
+	{"priority", func(r *eval.EvalResult) string { return r.Priority }},
+	{"agent", func(r *eval.EvalResult) string { return r.Agent }},
+	{"owner", func(r *eval.EvalResult) string { return r.Owner }},
+	{"duration", columnDuration},
+	{"score", columnScore},
+	{"assertions", func(r *eval.EvalResult) string {
+		return fmt.Sprintf("%d/%d", results.PassedAssertions(r), results.TotalAssertions(r))
+	}},
+	{"tool-calls", func(r *eval.EvalResult) string {
+		if r.CallHistory == nil {
+			return "0"
+		}
+		return fmt.Sprint(len(r.CallHistory.ToolCalls))
+	}},
+	{"code", func(r *eval.EvalResult) string {
+		if r.Status() != eval.TaskStatusFailed {
+			return ""
+		}
+		return string(results.FailureCode(r))
+	}},
+	{"error", func(r *eval.EvalResult) string {
+		if r.Status() != eval.TaskStatusFailed {
+			return ""
+		}
+		return firstLine(results.FailureReason(r))
+	}},
+}
+
+// columnDuration is the total duration of a task, rounded to 0.1s
+func columnDuration(r *eval.EvalResult) string {
+	if r.Timing == nil {
+		return ""
+	}
+	return time.Duration(r.Timing.Total).Round(100 * time.Millisecond).String()
+}
+
+// columnScore is the fraction of the assertions of a task that passed
+func columnScore(r *eval.EvalResult) string {
+	score, ok := results.Score(r)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", score)
+}
+
+// columnNames lists the names of the columns for help texts and errors
+func columnNames() string {
+	names := make([]string, 0, len(taskColumns)+1)
+	for _, c := range taskColumns {
+		names = append(names, c.name)
+	}
+	return strings.Join(append(names, "label:<key>"), ", ")
+}
+
+// findColumn returns the column with a name
+func findColumn(name string) (taskColumn, bool) {
+	if key, ok := strings.CutPrefix(name, "label:"); ok && key != "" {
+		return taskColumn{name: name, value: func(r *eval.EvalResult) string { return r.Labels[key] }}, true
+	}
+	for _, c := range taskColumns {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return taskColumn{}, false
+}
+
+// taskOutput prints one line per task, as columns or with a Go template,
+// instead of the default output of a command
+type taskOutput struct {
+	columns  []taskColumn
+	template *template.Template
+}
+
+// parseTaskOutput parses an output format of go-template=<template> or
+// go-template-file=<path>, and a comma-separated list of columns. It returns
+// nil if the format is another one, such as text or json, and no columns
+// are set.
+func parseTaskOutput(format, columns string) (*taskOutput, error) {
+	var text string
+	switch {
+	case strings.HasPrefix(format, goTemplatePrefix):
+		text = strings.TrimPrefix(format, goTemplatePrefix)
+	case strings.HasPrefix(format, goTemplateFilePrefix):
+		data, err := os.ReadFile(strings.TrimPrefix(format, goTemplateFilePrefix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(data)
+	}
+
+	if columns != "" {
+		if format != "text" {
+			return nil, fmt.Errorf("--columns can only be used with text output")
+		}
+		out := &taskOutput{}
+		for _, name := range strings.Split(columns, ",") {
+			col, ok := findColumn(strings.TrimSpace(name))
+			if !ok {
+				return nil, fmt.Errorf("unknown column %q (must be one of %s)", name, columnNames())
+			}
+			out.columns = append(out.columns, col)
+		}
+		return out, nil
+	}
+
+	if text == "" {
+		if strings.HasPrefix(format, goTemplatePrefix) || strings.HasPrefix(format, goTemplateFilePrefix) {
+			return nil, fmt.Errorf("template of --output %s must not be empty", format)
+		}
+		return nil, nil
+	}
+
+	tmpl, err := template.New("output").Option("missingkey=error").Funcs(template.FuncMap{
+		"column": func(name string, r *eval.EvalResult) (string, error) {
+			col, ok := findColumn(name)
+			if !ok {
+				return "", fmt.Errorf("unknown column %q (must be one of %s)", name, columnNames())
+			}
+			return col.value(r), nil
+		},
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"join": strings.Join,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return &taskOutput{template: tmpl}, nil
+}
+
+// print writes the results, a table with a header row for columns, or the
+// output of the template for each task on its own line
+func (o *taskOutput) print(w io.Writer, res []*eval.EvalResult) error {
+	if o.template != nil {
+		for _, r := range res {
+			var buf strings.Builder
+			if err := o.template.Execute(&buf, r); err != nil {
+				return fmt.Errorf("failed to execute template for task %s: %w", r.TaskName, err)
+			}
+			fmt.Fprintln(w, strings.TrimSuffix(buf.String(), "\n"))
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(o.columns))
+	for i, c := range o.columns {
+		headers[i] = strings.ToUpper(c.name)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, r := range res {
+		values := make([]string, len(o.columns))
+		for i, c := range o.columns {
+			values[i] = c.value(r)
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+func TestTaskOutputColumns(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[0].Timing = &eval.TaskTiming{Total: util.Duration(42123 * time.Millisecond)}
+	evalResults[0].Labels = map[string]string{"suite": "kubernetes"}
+
+	out, err := parseTaskOutput("text", "name, status,duration,score,label:suite,error")
+	if err != nil {
+		t.Fatalf("parseTaskOutput() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := out.print(&buf, evalResults); err != nil {
+		t.Fatalf("print() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want a header and 3 tasks:\n%s", len(lines), buf.String())
+	}
+	if fields := strings.Fields(lines[0]); strings.Join(fields, " ") != "NAME STATUS DURATION SCORE LABEL:SUITE ERROR" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "task-1 passed 42.1s 1.00 kubernetes" {
+		t.Errorf("task-1 row = %q", lines[1])
+	}
+	if !strings.Contains(lines[3], "0.00") || !strings.Contains(lines[3], "verification failed") {
+		t.Errorf("task-3 row = %q, want score 0.00 and the failure reason", lines[3])
+	}
+}
+
+func TestTaskOutputTemplate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "row.tmpl")
+	if err := os.WriteFile(file, []byte("{{.TaskName}} {{column \"assertions\" .}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		`go-template={{.TaskName}}={{.Status}} {{json .Difficulty}}`: "task-1=passed \"easy\"\ntask-2=failed \"medium\"\ntask-3=failed \"hard\"\n",
+		"go-template-file=" + file:                                   "task-1 2/2\ntask-2 1/2\ntask-3 0/1\n",
+	}
+	for format, want := range tests {
+		out, err := parseTaskOutput(format, "")
+		if err != nil {
+			t.Fatalf("parseTaskOutput(%q) error = %v", format, err)
+		}
+		var buf bytes.Buffer
+		if err := out.print(&buf, sampleResults()); err != nil {
+			t.Fatalf("print() error = %v", err)
+		}
+		if buf.String() != want {
+			t.Errorf("output of %q = %q, want %q", format, buf.String(), want)
+		}
+	}
+}
+
+func TestParseTaskOutputErrors(t *testing.T) {
+	tests := []struct {
+		format  string
+		columns string
+		want    string
+	}{
+		{format: "json", columns: "name", want: "--columns can only be used with text output"},
+		{format: "text", columns: "name,bogus", want: `unknown column "bogus"`},
+		{format: "go-template={{.TaskName", want: "invalid template"},
+		{format: "go-template=", want: "must not be empty"},
+		{format: "go-template-file=/does/not/exist", want: "failed to read template"},
+	}
+	for _, tt := range tests {
+		if _, err := parseTaskOutput(tt.format, tt.columns); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseTaskOutput(%q, %q) error = %v, want %q", tt.format, tt.columns, err, tt.want)
+		}
+	}
+
+	if out, err := parseTaskOutput("json", ""); out != nil || err != nil {
+		t.Errorf("parseTaskOutput(json) = %v, %v, want nil for the command to handle", out, err)
+	}
+}
+
+func TestViewCommandColumns(t *testing.T) {
+	filePath := createTestResultsFile(t, sampleResults())
+
+	var buf bytes.Buffer
+	cmd := NewViewCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{filePath, "--status", "failed", "--columns", "name,difficulty"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view failed: %v", err)
+	}
+
+	if want := "NAME    DIFFICULTY\ntask-2  medium\ntask-3  hard\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
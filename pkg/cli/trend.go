@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// sparkBlocks are the bar heights used to draw pass rate trend lines
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// NewTrendCmd creates the trend command
+func NewTrendCmd() *cobra.Command {
+	var outputFormat string
+	var window int
+	var quarantineFile string
+
+	cmd := &cobra.Command{
+		Use:   "trend <results-dir-or-file>...",
+		Short: "Show pass rate trends across runs",
+		Long: `Show aggregate and per-task pass rate trends across a series of runs.
+
+Arguments are results files or directories of results files. Runs are ordered
+by file modification time, oldest first.
+
+Tasks whose outcome changed at least twice within the last --window runs are
+reported as flaky. They are newly flaky if their outcome never changed before
+the window. With --update-quarantine, flaky tasks are added to a quarantine file
+that can be passed to 'mcpchecker verify --quarantine' or set as quarantineFile
+in the eval config.
+
+Example:
+  mcpchecker trend nightly-results/
+  mcpchecker trend nightly-results/ --window 10 --output markdown
+  mcpchecker trend nightly-results/ --update-quarantine quarantine.yaml`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if window < 2 {
+				return fmt.Errorf("--window must be at least 2")
+			}
+
+			runs, err := results.LoadRuns(args)
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				return fmt.Errorf("no results files found")
+			}
+
+			trend := results.CalculateTrend(runs, window)
+
+			if quarantineFile != "" {
+				if err := updateQuarantine(cmd, quarantineFile, trend); err != nil {
+					return err
+				}
+			}
+
+			switch outputFormat {
+			case "text":
+				outputTextTrend(trend)
+			case "markdown":
+				outputMarkdownTrend(trend)
+			case "json":
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(trend)
+			default:
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, markdown, json)")
+	cmd.Flags().IntVar(&window, "window", results.DefaultTrendWindow, "Number of most recent runs checked for flaky tasks")
+	cmd.Flags().StringVar(&quarantineFile, "update-quarantine", "", "Add flaky tasks to this quarantine file, creating it if needed")
+
+	return cmd
+}
+
+// updateQuarantine adds the flaky tasks in trend to the quarantine file
+func updateQuarantine(cmd *cobra.Command, path string, trend *results.Trend) error {
+	quarantine, err := eval.LoadQuarantine(path)
+	if err != nil {
+		return err
+	}
+
+	suggestions := trend.SuggestQuarantine(quarantine)
+	if len(suggestions) == 0 {
+		return nil
+	}
+
+	for _, t := range suggestions {
+		quarantine.Add(t.Name, t.Reason)
+	}
+	if err := quarantine.Save(path); err != nil {
+		return err
+	}
+
+	// Status goes to stderr so it doesn't mix with json output
+	fmt.Fprintf(cmd.ErrOrStderr(), "Added %d flaky task(s) to %s\n", len(suggestions), path)
+	return nil
+}
+
+func outputTextTrend(trend *results.Trend) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+	yellow := color.New(color.FgYellow)
+	bold := color.New(color.Bold)
+
+	_, _ = bold.Println("=== Pass Rate Trend ===")
+	fmt.Println()
+
+	first, last := trend.Runs[0], trend.Runs[len(trend.Runs)-1]
+	fmt.Printf("Runs:  %d\n", len(trend.Runs))
+	fmt.Printf("Tasks: %s  %.1f%% → %.1f%%  ", sparkline(trend.Runs), first.PassRate*100, last.PassRate*100)
+	printChange(last.PassRate - first.PassRate)
+	fmt.Println()
+
+	for _, run := range trend.Runs {
+		fmt.Printf("  %s  %d/%d (%.1f%%)  %s\n",
+			run.Time.Format("2006-01-02 15:04"), run.TasksPassed, run.TasksTotal, run.PassRate*100, filepath.Base(run.File))
+	}
+
+	if flaky := trend.FlakyTasks(); len(flaky) > 0 {
+		fmt.Println()
+		_, _ = yellow.Printf("Flaky in the last %d runs (%d):\n", min(trend.Window, len(trend.Runs)), len(flaky))
+		for _, t := range flaky {
+			_, _ = yellow.Printf("  %s %s", formatOutcomes(t.Outcomes), t.Name)
+			if t.NewlyFlaky {
+				fmt.Print(" (new)")
+			}
+			fmt.Println()
+		}
+	}
+
+	fmt.Println()
+	_, _ = bold.Println("Tasks:")
+	stable := 0
+	for _, t := range trend.Tasks {
+		if t.PassRate == 1 {
+			stable++
+			continue
+		}
+		c := red
+		if t.PassRate > 0 {
+			c = yellow
+		}
+		_, _ = c.Printf("  %s %5.1f%%  %s\n", formatOutcomes(t.Outcomes), t.PassRate*100, t.Name)
+	}
+	if stable > 0 {
+		_, _ = green.Printf("  %d task(s) passed in every run\n", stable)
+	}
+}
+
+func outputMarkdownTrend(trend *results.Trend) {
+	first, last := trend.Runs[0], trend.Runs[len(trend.Runs)-1]
+
+	fmt.Println("## Pass Rate Trend")
+	fmt.Println()
+	fmt.Printf("**%d runs**: %s %.1f%% → %.1f%% (%s)\n",
+		len(trend.Runs), sparkline(trend.Runs), first.PassRate*100, last.PassRate*100, formatChangeMarkdown(last.PassRate-first.PassRate))
+	fmt.Println()
+	fmt.Println("| Run | Time | Tasks | Pass Rate |")
+	fmt.Println("|-----|------|------:|----------:|")
+	for _, run := range trend.Runs {
+		fmt.Printf("| %s | %s | %d/%d | %.1f%% |\n",
+			escapeMarkdownCell(filepath.Base(run.File)), run.Time.Format("2006-01-02 15:04"), run.TasksPassed, run.TasksTotal, run.PassRate*100)
+	}
+
+	if flaky := trend.FlakyTasks(); len(flaky) > 0 {
+		fmt.Println()
+		fmt.Printf("### ⚠️ Flaky in the last %d runs (%d)\n", min(trend.Window, len(trend.Runs)), len(flaky))
+		for _, t := range flaky {
+			fmt.Printf("- `%s`: %s", t.Name, formatOutcomes(t.Outcomes))
+			if t.NewlyFlaky {
+				fmt.Print(" (new)")
+			}
+			fmt.Println()
+		}
+	}
+
+	var failing []results.TaskTrend
+	for _, t := range trend.Tasks {
+		if t.PassRate < 1 {
+			failing = append(failing, t)
+		}
+	}
+	if len(failing) > 0 {
+		fmt.Println()
+		fmt.Printf("### Tasks that failed at least once (%d)\n", len(failing))
+		fmt.Println()
+		fmt.Println("| Task | Runs | Pass Rate |")
+		fmt.Println("|------|------|----------:|")
+		for _, t := range failing {
+			fmt.Printf("| `%s` | %s | %.1f%% |\n", t.Name, formatOutcomes(t.Outcomes), t.PassRate*100)
+		}
+	}
+}
+
+// sparkline draws the pass rate of each run as a bar
+func sparkline(runs []results.TrendRun) string {
+	var sb strings.Builder
+	for _, run := range runs {
+		i := int(run.PassRate * float64(len(sparkBlocks)-1))
+		sb.WriteRune(sparkBlocks[i])
+	}
+	return sb.String()
+}
+
+// formatOutcomes draws a task's outcome in each run, oldest first
+func formatOutcomes(outcomes []*bool) string {
+	var sb strings.Builder
+	for _, o := range outcomes {
+		switch {
+		case o == nil:
+			sb.WriteString("·")
+		case *o:
+			sb.WriteString("✓")
+		default:
+			sb.WriteString("✗")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// maxTriageTasks is the number of task names listed per cluster in text and markdown output
+const maxTriageTasks = 10
+
+// NewTriageCmd creates the triage command
+func NewTriageCmd() *cobra.Command {
+	var outputFormat string
+	var similarity float64
+
+	cmd := &cobra.Command{
+		Use:   "triage <results-file>",
+		Short: "Group failed tasks by common cause",
+		Long: `Group failed tasks into clusters so large suites can be triaged at a glance.
+
+Tasks are grouped by where they failed (setup, agent, verify, judge, or assertions),
+then by the similarity of their error messages, the LLM judge failure category, or
+the types of assertions that failed.
+
+Example:
+  mcpchecker triage results.json
+  mcpchecker triage results.json --output markdown`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if similarity <= 0 || similarity > 1 {
+				return fmt.Errorf("--similarity must be greater than 0 and at most 1")
+			}
+
+			evalResults, err := results.Load(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			triage := results.TriageFailures(args[0], evalResults, similarity)
+
+			switch outputFormat {
+			case "text":
+				outputTextTriage(triage)
+			case "markdown":
+				outputMarkdownTriage(triage)
+			case "json":
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(triage)
+			default:
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, markdown, json)")
+	cmd.Flags().Float64Var(&similarity, "similarity", results.DefaultSimilarity, "Minimum similarity (0-1) for error messages to be grouped together")
+
+	return cmd
+}
+
+func outputTextTriage(triage *results.Triage) {
+	red := color.New(color.FgRed)
+	green := color.New(color.FgGreen)
+	bold := color.New(color.Bold)
+
+	bold.Println("=== Failure Triage ===")
+	fmt.Println()
+
+	if triage.TasksFailed == 0 {
+		green.Printf("All %d tasks passed\n", triage.TasksTotal)
+		return
+	}
+
+	fmt.Printf("%d of %d tasks failed, in %d cluster(s)\n", triage.TasksFailed, triage.TasksTotal, len(triage.Clusters))
+
+	for i, c := range triage.Clusters {
+		fmt.Println()
+		red.Printf("[%d] %d task(s) failed in %s", i+1, len(c.Tasks), c.Kind)
+		fmt.Printf(": %s\n", c.Signature)
+		if c.Example != "" && c.Example != c.Signature {
+			fmt.Printf("    example: %s\n", firstLine(c.Example))
+		}
+		fmt.Printf("    tasks: %s\n", formatTaskList(c.Tasks))
+	}
+}
+
+func outputMarkdownTriage(triage *results.Triage) {
+	fmt.Println("## Failure Triage")
+	fmt.Println()
+
+	if triage.TasksFailed == 0 {
+		fmt.Printf("All %d tasks passed.\n", triage.TasksTotal)
+		return
+	}
+
+	fmt.Printf("**%d of %d tasks failed**, in %d cluster(s)\n", triage.TasksFailed, triage.TasksTotal, len(triage.Clusters))
+	fmt.Println()
+	fmt.Println("| Tasks | Phase | Cause | Example | Affected Tasks |")
+	fmt.Println("|------:|-------|-------|---------|----------------|")
+	for _, c := range triage.Clusters {
+		fmt.Printf("| %d | %s | %s | %s | %s |\n",
+			len(c.Tasks),
+			c.Kind,
+			escapeMarkdownCell(c.Signature),
+			escapeMarkdownCell(firstLine(c.Example)),
+			escapeMarkdownCell(formatTaskList(c.Tasks)),
+		)
+	}
+}
+
+func formatTaskList(tasks []string) string {
+	if len(tasks) <= maxTriageTasks {
+		return strings.Join(tasks, ", ")
+	}
+	return fmt.Sprintf("%s (+%d more)", strings.Join(tasks[:maxTriageTasks], ", "), len(tasks)-maxTriageTasks)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// escapeMarkdownCell escapes pipes, which end the cell, and angle brackets,
+// which would hide placeholders like <path> as HTML tags
+func escapeMarkdownCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -3,16 +3,65 @@ package cli
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
 	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/spf13/cobra"
 )
 
+// difficultyCheck is the task pass rate threshold of a task difficulty
+type difficultyCheck struct {
+	difficulty string
+	threshold  float64
+	stats      results.Stats
+	met        bool
+}
+
+// budgetChecks are the limits of the scores and the cost of a run
+type budgetChecks struct {
+	minScore float64
+	scoreMet bool
+	maxCost  int
+	costMet  bool
+}
+
+// safetyCheck is the limit of the safety findings of the counted tasks
+type safetyCheck struct {
+	max   int
+	total int
+	// tasks are the tasks with findings, with their number
+	tasks []string
+	met   bool
+}
+
+// criticalCheck is the check that every critical task passed
+type criticalCheck struct {
+	total  int
+	failed []string
+}
+
 // NewVerifyCmd creates the verify command
 func NewVerifyCmd() *cobra.Command {
 	var taskThreshold float64
+	difficultyThresholds := map[string]*float64{
+		task.DifficultyEasy:   new(float64),
+		task.DifficultyMedium: new(float64),
+		task.DifficultyHard:   new(float64),
+	}
 	var assertionThreshold float64
+	var maxJudgeFailures int
+	var maxP95Duration time.Duration
+	var minScore float64
+	var maxCost int
+	var maxSafetyFindings int
+	var quarantineFile string
+	var requireCritical bool
+	var quiet bool
+	var filters queryFlags
 
 	cmd := &cobra.Command{
 		Use:   "verify <results-file>",
@@ -20,6 +69,22 @@ func NewVerifyCmd() *cobra.Command {
 		Long: `Verify that evaluation results meet minimum pass rate thresholds.
 
 Exits with code 0 if all thresholds are met, code 1 otherwise.
+--task-easy, --task-medium, and --task-hard set task pass rate thresholds for
+the tasks of one difficulty, which are met when there are no such tasks.
+--max-p95-duration limits the 95th percentile of the task durations, leaving
+out results recorded without timing.
+--min-score sets the minimum mean score of the tasks with assertions, where
+the score of a task is the fraction of its assertions that passed.
+--max-cost limits the estimated number of tokens the tool results of all
+tasks added to the context of the agent.
+--max-safety-findings limits the number of safety findings of all tasks, where
+a task whose safety scan failed counts as one finding.
+--require-critical fails if any task with priority critical failed or was not
+run, whatever the pass rates.
+Quarantined tasks, skipped tasks, and tasks marked as expected failures are
+reported but do not count against the thresholds.
+--label, --difficulty, and --status check the thresholds against a subset of
+the tasks only.
 Use 'mcpchecker summary' to view detailed results.`,
 		Args:          cobra.ExactArgs(1),
 		SilenceUsage:  true,
@@ -27,19 +92,77 @@ Use 'mcpchecker summary' to view detailed results.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			resultsFile := args[0]
 
+			query, err := filters.query("")
+			if err != nil {
+				return err
+			}
+
 			evalResults, err := results.Load(resultsFile)
 			if err != nil {
 				return fmt.Errorf("failed to load results file: %w", err)
 			}
+			evalResults = results.Select(evalResults, query)
+
+			if quarantineFile != "" {
+				quarantine, err := eval.LoadQuarantine(quarantineFile)
+				if err != nil {
+					return err
+				}
+				results.ApplyQuarantine(evalResults, quarantine)
+			}
 
 			stats := results.CalculateStats(resultsFile, evalResults)
 
 			taskThresholdMet := stats.TaskPassRate >= taskThreshold
 			// If no assertions exist, skip the assertion threshold check
 			assertionThresholdMet := stats.AssertionsTotal == 0 || stats.AssertionPassRate >= assertionThreshold
-			passed := taskThresholdMet && assertionThresholdMet
+			// A negative limit disables the judge failure check
+			judgeFailuresMet := maxJudgeFailures < 0 || totalJudgeFailures(stats) <= maxJudgeFailures
+			// A zero limit disables the duration check
+			durationMet := maxP95Duration == 0 || time.Duration(stats.DurationP95) <= maxP95Duration
+			budgets := budgetChecks{
+				minScore: minScore,
+				// The score check is met when no task has assertions
+				scoreMet: stats.ScoredTasks == 0 || stats.MeanScore >= minScore,
+				maxCost:  maxCost,
+				// A zero limit disables the cost check
+				costMet: maxCost == 0 || stats.ContextTokens <= maxCost,
+			}
+			passed := taskThresholdMet && assertionThresholdMet && judgeFailuresMet && durationMet && budgets.scoreMet && budgets.costMet
 
-			outputVerifyResults(stats, taskThreshold, assertionThreshold, taskThresholdMet, assertionThresholdMet, passed)
+			// Only the difficulties with a threshold set are checked
+			var difficultyChecks []difficultyCheck
+			difficultyStats := results.CalculateDifficultyStats(resultsFile, evalResults)
+			for _, difficulty := range []string{task.DifficultyEasy, task.DifficultyMedium, task.DifficultyHard} {
+				if !cmd.Flags().Changed("task-" + difficulty) {
+					continue
+				}
+				check := difficultyCheck{
+					difficulty: difficulty,
+					threshold:  *difficultyThresholds[difficulty],
+					stats:      difficultyStats[difficulty],
+				}
+				check.met = check.stats.TasksTotal == 0 || check.stats.TaskPassRate >= check.threshold
+				passed = passed && check.met
+				difficultyChecks = append(difficultyChecks, check)
+			}
+
+			var safety *safetyCheck
+			if maxSafetyFindings >= 0 {
+				safety = countSafetyFindings(evalResults)
+				safety.max = maxSafetyFindings
+				safety.met = safety.total <= maxSafetyFindings
+				passed = passed && safety.met
+			}
+
+			var critical *criticalCheck
+			if requireCritical {
+				critical = &criticalCheck{}
+				critical.total, critical.failed = results.CriticalTasks(evalResults)
+				passed = passed && len(critical.failed) == 0
+			}
+
+			outputVerifyResults(stats, taskThreshold, assertionThreshold, maxJudgeFailures, maxP95Duration, taskThresholdMet, assertionThresholdMet, judgeFailuresMet, durationMet, budgets, difficultyChecks, safety, critical, passed, quiet)
 
 			if !passed {
 				// silent error (SilenceErrors: true), sets exit code 1
@@ -51,43 +174,189 @@ Use 'mcpchecker summary' to view detailed results.`,
 	}
 
 	cmd.Flags().Float64Var(&taskThreshold, "task", 0.0, "Minimum task pass rate (0.0-1.0)")
+	for _, difficulty := range []string{task.DifficultyEasy, task.DifficultyMedium, task.DifficultyHard} {
+		cmd.Flags().Float64Var(difficultyThresholds[difficulty], "task-"+difficulty, 0.0, "Minimum task pass rate of "+difficulty+" tasks (0.0-1.0)")
+	}
 	cmd.Flags().Float64Var(&assertionThreshold, "assertion", 0.0, "Minimum assertion pass rate (0.0-1.0)")
+	cmd.Flags().StringVar(&quarantineFile, "quarantine", "", "Quarantine file listing tasks whose failures are ignored")
+	cmd.Flags().BoolVar(&requireCritical, "require-critical", false, "Fail if any critical task failed or was not run")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only show thresholds that were not met and the result")
+	filters.register(cmd)
+	cmd.Flags().IntVar(&maxJudgeFailures, "max-judge-failures", -1, "Maximum number of tasks the LLM judge may fail (-1 for no limit)")
+	cmd.Flags().DurationVar(&maxP95Duration, "max-p95-duration", 0, "Maximum 95th percentile of the task durations, e.g. 2m (0 for no limit)")
+	cmd.Flags().Float64Var(&minScore, "min-score", 0.0, "Minimum mean task score, the fraction of the assertions of a task that passed (0.0-1.0)")
+	cmd.Flags().IntVar(&maxSafetyFindings, "max-safety-findings", -1, "Maximum number of safety findings of all tasks (-1 for no limit)")
+	cmd.Flags().IntVar(&maxCost, "max-cost", 0, "Maximum estimated tokens the tool results of all tasks added to the agent context (0 for no limit)")
 
 	return cmd
 }
 
-func outputVerifyResults(stats results.Stats, taskThreshold, assertionThreshold float64, taskMet, assertionMet, passed bool) {
+// outputVerifyResults prints the threshold checks. In quiet mode only the
+// thresholds that were not met and the result are printed.
+func outputVerifyResults(stats results.Stats, taskThreshold, assertionThreshold float64, maxJudgeFailures int, maxP95Duration time.Duration, taskMet, assertionMet, judgeMet, durationMet bool, budgets budgetChecks, difficultyChecks []difficultyCheck, safety *safetyCheck, critical *criticalCheck, passed, quiet bool) {
 	green := color.New(color.FgGreen)
 	red := color.New(color.FgRed)
 	bold := color.New(color.Bold)
 
-	_, _ = bold.Println("=== Threshold Verification ===")
-	fmt.Println()
+	if !quiet {
+		_, _ = bold.Println("=== Threshold Verification ===")
+		fmt.Println()
+	}
 
 	// Task threshold
-	if taskMet {
-		_, _ = green.Printf("Task Pass Rate:      %.2f%% >= %.2f%% ✓\n",
-			stats.TaskPassRate*100, taskThreshold*100)
-	} else {
+	if !taskMet {
 		_, _ = red.Printf("Task Pass Rate:      %.2f%% < %.2f%% ✗\n",
 			stats.TaskPassRate*100, taskThreshold*100)
+	} else if !quiet {
+		_, _ = green.Printf("Task Pass Rate:      %.2f%% >= %.2f%% ✓\n",
+			stats.TaskPassRate*100, taskThreshold*100)
+	}
+
+	// Task thresholds by difficulty
+	for _, check := range difficultyChecks {
+		label := fmt.Sprintf("Task Pass Rate (%s):", check.difficulty)
+		switch {
+		case !check.met:
+			_, _ = red.Printf("%-20s %.2f%% < %.2f%% ✗\n", label, check.stats.TaskPassRate*100, check.threshold*100)
+		case quiet:
+		case check.stats.TasksTotal == 0:
+			fmt.Printf("%-20s N/A (no %s tasks)\n", label, check.difficulty)
+		default:
+			_, _ = green.Printf("%-20s %.2f%% >= %.2f%% ✓\n", label, check.stats.TaskPassRate*100, check.threshold*100)
+		}
+	}
+
+	// Critical tasks, only shown with --require-critical
+	switch {
+	case critical == nil:
+	case len(critical.failed) > 0:
+		_, _ = red.Printf("Critical Tasks:      %d/%d failed ✗ (%s)\n", len(critical.failed), critical.total, strings.Join(critical.failed, ", "))
+	case quiet:
+	case critical.total == 0:
+		fmt.Println("Critical Tasks:      N/A (no critical tasks)")
+	default:
+		_, _ = green.Printf("Critical Tasks:      %d/%d passed ✓\n", critical.total, critical.total)
 	}
 
 	// Assertion threshold
-	if stats.AssertionsTotal == 0 {
+	switch {
+	case !assertionMet:
+		_, _ = red.Printf("Assertion Pass Rate: %.2f%% < %.2f%% ✗\n",
+			stats.AssertionPassRate*100, assertionThreshold*100)
+	case quiet:
+	case stats.AssertionsTotal == 0:
 		fmt.Println("Assertion Pass Rate: N/A (no assertions defined)")
-	} else if assertionMet {
+	default:
 		_, _ = green.Printf("Assertion Pass Rate: %.2f%% >= %.2f%% ✓\n",
 			stats.AssertionPassRate*100, assertionThreshold*100)
-	} else {
-		_, _ = red.Printf("Assertion Pass Rate: %.2f%% < %.2f%% ✗\n",
-			stats.AssertionPassRate*100, assertionThreshold*100)
 	}
 
-	fmt.Println()
+	// Judge failures, only shown when there are any or a limit is set
+	judgeFailures := totalJudgeFailures(stats)
+	breakdown := ""
+	if judgeFailures > 0 {
+		breakdown = fmt.Sprintf(" (%s)", formatJudgeFailures(stats.JudgeFailures))
+	}
+	switch {
+	case !judgeMet:
+		_, _ = red.Printf("Judge Failures:      %d > %d ✗%s\n", judgeFailures, maxJudgeFailures, breakdown)
+	case quiet:
+	case maxJudgeFailures >= 0:
+		_, _ = green.Printf("Judge Failures:      %d <= %d ✓%s\n", judgeFailures, maxJudgeFailures, breakdown)
+	case judgeFailures > 0:
+		fmt.Printf("Judge Failures:      %d%s\n", judgeFailures, breakdown)
+	}
+
+	// Task duration, only shown when a limit is set
+	switch {
+	case !durationMet:
+		_, _ = red.Printf("P95 Task Duration:   %s > %s ✗\n", stats.DurationP95, maxP95Duration)
+	case quiet:
+	case maxP95Duration > 0 && stats.DurationP95 == 0:
+		fmt.Println("P95 Task Duration:   N/A (no task timing recorded)")
+	case maxP95Duration > 0:
+		_, _ = green.Printf("P95 Task Duration:   %s <= %s ✓\n", stats.DurationP95, maxP95Duration)
+	}
+
+	// Mean task score, only shown when a minimum is set
+	switch {
+	case !budgets.scoreMet:
+		_, _ = red.Printf("Mean Task Score:     %.2f < %.2f ✗\n", stats.MeanScore, budgets.minScore)
+	case quiet || budgets.minScore == 0:
+	case stats.ScoredTasks == 0:
+		fmt.Println("Mean Task Score:     N/A (no assertions defined)")
+	default:
+		_, _ = green.Printf("Mean Task Score:     %.2f >= %.2f ✓\n", stats.MeanScore, budgets.minScore)
+	}
+
+	// Context tokens, only shown when a limit is set
+	switch {
+	case !budgets.costMet:
+		_, _ = red.Printf("Context Tokens:      %d > %d ✗\n", stats.ContextTokens, budgets.maxCost)
+	case quiet || budgets.maxCost == 0:
+	default:
+		_, _ = green.Printf("Context Tokens:      %d <= %d ✓\n", stats.ContextTokens, budgets.maxCost)
+	}
+
+	// Safety findings, only shown with --max-safety-findings
+	tasks := ""
+	if safety != nil && len(safety.tasks) > 0 {
+		tasks = fmt.Sprintf(" (%s)", strings.Join(safety.tasks, ", "))
+	}
+	switch {
+	case safety == nil:
+	case !safety.met:
+		_, _ = red.Printf("Safety Findings:     %d > %d ✗%s\n", safety.total, safety.max, tasks)
+	case quiet:
+	default:
+		_, _ = green.Printf("Safety Findings:     %d <= %d ✓%s\n", safety.total, safety.max, tasks)
+	}
+
+	if stats.TasksQuarantined > 0 && !quiet {
+		fmt.Printf("Quarantined:         %d task(s), %d failed (not counted)\n", stats.TasksQuarantined, stats.QuarantinedFailed)
+	}
+	if stats.TasksSkipped > 0 && !quiet {
+		fmt.Printf("Skipped:             %d task(s) (not counted)\n", stats.TasksSkipped)
+	}
+	if (stats.TasksExpectedFailed > 0 || stats.TasksUnexpectedPassed > 0) && !quiet {
+		fmt.Printf("Expected Failures:   %d failed, %d passed unexpectedly (not counted)\n", stats.TasksExpectedFailed, stats.TasksUnexpectedPassed)
+	}
+
+	if !quiet {
+		fmt.Println()
+	}
 	if passed {
 		_, _ = green.Println("Result: PASSED")
 	} else {
 		_, _ = red.Println("Result: FAILED")
 	}
 }
+
+// countSafetyFindings counts the safety findings of the counted tasks. A task
+// whose scan failed counts as one finding, as its findings are unknown.
+func countSafetyFindings(evalResults []*eval.EvalResult) *safetyCheck {
+	check := &safetyCheck{}
+	for _, r := range evalResults {
+		if !r.Counted() || r.SafetyFindings == nil {
+			continue
+		}
+		n := len(r.SafetyFindings.Findings)
+		if r.SafetyFindings.Error != "" {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		check.total += n
+		check.tasks = append(check.tasks, fmt.Sprintf("%s: %d", r.TaskName, n))
+	}
+	return check
+}
+
+func totalJudgeFailures(stats results.Stats) int {
+	total := 0
+	for _, n := range stats.JudgeFailures {
+		total += n
+	}
+	return total
+}
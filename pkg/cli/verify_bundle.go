@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyBundleCmd creates the verify-bundle command
+func NewVerifyBundleCmd() *cobra.Command {
+	var publicKeyFile string
+	var filesDir string
+	var output string
+	var outputLayout string
+
+	cmd := &cobra.Command{
+		Use:   "verify-bundle <bundle-file>",
+		Short: "Check the signature and digests of a result bundle",
+		Long: `Check a result bundle written by check --bundle, so that published results can
+be validated independently:
+
+  - the results match the digest recorded in the manifest of the bundle
+  - the manifest is signed with the key of --public-key, or with the key in
+    the bundle if --public-key is not given, whose fingerprint is printed
+  - with --files, the eval config, the files it refers to, and the task files
+    in a checkout of the suite match the digests in the manifest
+
+The command fails if any check fails. With --output, the verified results are
+written to a results file that summary, diff, and the other commands read.
+
+Examples:
+  mcpchecker verify-bundle bundle.json --public-key publisher.pub.pem
+  mcpchecker verify-bundle bundle.json --public-key publisher.pub.pem --files ./suite -o results.json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(results.Layouts, outputLayout) {
+				return fmt.Errorf("unknown output layout: %s (must be one of %s)", outputLayout, strings.Join(results.Layouts, ", "))
+			}
+
+			var trusted ed25519.PublicKey
+			if publicKeyFile != "" {
+				var err error
+				if trusted, err = results.LoadPublicKey(publicKeyFile); err != nil {
+					return err
+				}
+			}
+
+			bundle, err := results.LoadBundle(args[0])
+			if err != nil {
+				return err
+			}
+
+			key, err := bundle.Verify(trusted)
+			if err != nil {
+				return fmt.Errorf("bundle %s is not valid: %w", args[0], err)
+			}
+			res, err := bundle.EvalResults()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			m := bundle.Manifest
+			fmt.Fprintf(out, "Bundle of eval %s with %d tasks, created %s", m.Eval, len(res), m.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+			if m.ToolVersion != "" {
+				fmt.Fprintf(out, " by mcpchecker %s", m.ToolVersion)
+			}
+			fmt.Fprintln(out)
+			fmt.Fprintf(out, "✓ Results match %s\n", m.ResultsDigest)
+			if trusted != nil {
+				fmt.Fprintf(out, "✓ Signed with the trusted key %s\n", results.KeyFingerprint(key))
+			} else {
+				fmt.Fprintf(out, "✓ Signed with key %s (not checked against a trusted key, pass --public-key)\n", results.KeyFingerprint(key))
+			}
+
+			if filesDir != "" {
+				if mismatches := bundle.CheckFiles(filesDir); len(mismatches) > 0 {
+					return fmt.Errorf("%d files in %s do not match the bundle:\n  %s", len(mismatches), filesDir, strings.Join(mismatches, "\n  "))
+				}
+				fmt.Fprintf(out, "✓ %d config and %d task files match %s\n", len(m.Files), len(m.Tasks), filesDir)
+			}
+
+			if output != "" {
+				if err := results.Save(res, output, outputLayout); err != nil {
+					return fmt.Errorf("failed to save results to file: %w", err)
+				}
+				fmt.Fprintf(out, "Results saved to %s\n", output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&publicKeyFile, "public-key", "", "PEM file of the ed25519 public key the bundle must be signed with")
+	cmd.Flags().StringVar(&filesDir, "files", "", "Directory of the eval config to compare the config and task files of the bundle with")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Results file to write the verified results to")
+	cmd.Flags().StringVar(&outputLayout, "output-layout", results.LayoutFile, "Layout of the results file (file, gzip, dir)")
+
+	return cmd
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+)
+
+func TestVerifyBundleCommand(t *testing.T) {
+	dir := t.TempDir()
+	evalFile := filepath.Join(dir, "eval.yaml")
+	taskFile := filepath.Join(dir, "task.yaml")
+	for path, content := range map[string]string{evalFile: "kind: Eval\n", taskFile: "kind: Task\n"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubFile := filepath.Join(dir, "key.pub.pem")
+	if err := os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := results.NewBundle([]*eval.EvalResult{{TaskName: "task-1", TaskPath: taskFile, TaskPassed: true}}, "suite", dir, []string{evalFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+	bundleFile := filepath.Join(dir, "bundle.json")
+	if err := results.SaveBundle(bundle, bundleFile); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "results.json")
+	var out bytes.Buffer
+	cmd := NewVerifyBundleCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{bundleFile, "--public-key", pubFile, "--files", dir, "-o", output})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("verify-bundle failed: %v", err)
+	}
+	for _, want := range []string{"Bundle of eval suite with 1 tasks", "Signed with the trusted key SHA256:", "1 config and 1 task files match"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output = %q, want %q", out.String(), want)
+		}
+	}
+	if res, err := results.Load(output); err != nil || len(res) != 1 || res[0].TaskName != "task-1" {
+		t.Errorf("results = %v, %v, want task-1", res, err)
+	}
+
+	// A changed task file no longer matches the bundle
+	if err := os.WriteFile(taskFile, []byte("kind: Task\nchanged: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = NewVerifyBundleCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{bundleFile, "--files", dir})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "task.yaml: digest") {
+		t.Errorf("verify-bundle error = %v, want task.yaml to differ", err)
+	}
+}
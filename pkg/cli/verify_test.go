@@ -6,8 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 // createTestResultsFile creates a temporary results file for testing
@@ -202,3 +205,260 @@ func TestVerifyCommandAllPassed(t *testing.T) {
 	}
 }
 
+
+func TestVerifyCommandMaxJudgeFailures(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[2].TaskJudgeCategory = "semantic_mismatch"
+	filePath := createTestResultsFile(t, evalResults)
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--max-judge-failures", "1"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass with 1 judge failure allowed, got error: %v", err)
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--max-judge-failures", "0"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should return error when judge failures exceed the limit")
+	}
+}
+
+func TestVerifyCommandQuarantine(t *testing.T) {
+	evalResults := sampleResults()
+	filePath := createTestResultsFile(t, evalResults)
+
+	quarantinePath := filepath.Join(t.TempDir(), "quarantine.yaml")
+	if err := os.WriteFile(quarantinePath, []byte("tasks:\n- name: task-3\n  reason: flaky\n"), 0644); err != nil {
+		t.Fatalf("failed to write quarantine file: %v", err)
+	}
+
+	// Task pass rate is 2/3 = 0.667 with task-3, and 2/2 without it
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task", "1.0"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should fail without the quarantine")
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task", "1.0", "--quarantine", quarantinePath})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass with the failing task quarantined, got error: %v", err)
+	}
+}
+
+func TestVerifyCommandSkipAndExpectedFailure(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[2].ExpectedFailure = true
+	evalResults = append(evalResults, &eval.EvalResult{TaskName: "task-4", SkipReason: "needs a GPU node"})
+	filePath := createTestResultsFile(t, evalResults)
+
+	// Task pass rate is 2/2, as the expected failure and the skipped task are not counted
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task", "1.0"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass with the failing task expected to fail, got error: %v", err)
+	}
+}
+
+func TestVerifyCommandDifficultyThresholds(t *testing.T) {
+	// task-1 (easy) and task-2 (medium) pass, task-3 (hard) fails
+	filePath := createTestResultsFile(t, sampleResults())
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task-easy", "1.0", "--task-medium", "0.9"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass when easy and medium tasks pass, got error: %v", err)
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task-easy", "1.0", "--task-hard", "0.5"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should return error when the hard task threshold is not met")
+	}
+
+	// A threshold of a difficulty without tasks is met
+	evalResults := sampleResults()[:2]
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{createTestResultsFile(t, evalResults), "--task-hard", "1.0"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass without hard tasks, got error: %v", err)
+	}
+}
+
+func TestVerifyCommandMaxP95Duration(t *testing.T) {
+	evalResults := sampleResults()
+	for i, r := range evalResults {
+		r.Timing = &eval.TaskTiming{Total: util.Duration(time.Duration(i+1) * time.Minute)}
+	}
+	filePath := createTestResultsFile(t, evalResults)
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--max-p95-duration", "3m"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass when the p95 duration is within the limit, got error: %v", err)
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--max-p95-duration", "2m30s"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should return error when the p95 duration exceeds the limit")
+	}
+}
+
+func TestVerifyCommandMinScore(t *testing.T) {
+	// The scores of the sample tasks are 1, 0.5, and 0
+	filePath := createTestResultsFile(t, sampleResults())
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--min-score", "0.5"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass when the mean score meets the minimum, got error: %v", err)
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--min-score", "0.6"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should return error when the mean score is below the minimum")
+	}
+}
+
+func TestVerifyCommandMaxCost(t *testing.T) {
+	evalResults := sampleResults()
+	for i, r := range evalResults {
+		r.ContextUsage = &eval.ContextUsage{Tokenizer: "estimate", TotalTokens: (i + 1) * 100}
+	}
+	filePath := createTestResultsFile(t, evalResults)
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--max-cost", "600"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass when the context tokens are within the limit, got error: %v", err)
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--max-cost", "599"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should return error when the context tokens exceed the limit")
+	}
+}
+
+func TestVerifyCommandMaxSafetyFindings(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[0].SafetyFindings = &eval.SafetyFindings{Findings: []eval.SafetyFinding{
+		{Rule: "aws-key", Location: "output", Match: "AKIA****"},
+		{Rule: "aws-key", Location: "toolCalls[0]", Match: "AKIA****"},
+	}}
+	evalResults[1].SafetyFindings = &eval.SafetyFindings{Error: "scan command failed"}
+	evalResults[2].SafetyFindings = &eval.SafetyFindings{}
+	filePath := createTestResultsFile(t, evalResults)
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--max-safety-findings", "3"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass when the safety findings are within the limit, got error: %v", err)
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--max-safety-findings", "2"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should return error when the safety findings exceed the limit")
+	}
+
+	// Findings of quarantined tasks are not counted
+	evalResults[0].Quarantined = true
+	filePath = createTestResultsFile(t, evalResults)
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--max-safety-findings", "1"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should not count findings of quarantined tasks, got error: %v", err)
+	}
+}
+
+func TestVerifyCommandQuiet(t *testing.T) {
+	filePath := createTestResultsFile(t, sampleResults())
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--quiet", "--task", "0.5"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass in quiet mode, got error: %v", err)
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--quiet", "--task", "1.0"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should still fail in quiet mode when thresholds are not met")
+	}
+}
+
+func TestVerifyCommandRequireCritical(t *testing.T) {
+	// task-1 passes and task-3 fails
+	evalResults := sampleResults()
+	evalResults[0].Priority = task.PriorityCritical
+	filePath := createTestResultsFile(t, evalResults)
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--require-critical"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass when the critical tasks pass, got error: %v", err)
+	}
+
+	// A failed critical task fails the run, whatever the pass rates
+	evalResults[2].Priority = task.PriorityCritical
+	filePath = createTestResultsFile(t, evalResults)
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task", "0.5"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify command should pass without --require-critical, got error: %v", err)
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task", "0.5", "--require-critical"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should return error when a critical task failed")
+	}
+
+	// A critical task that was not run fails the run
+	evalResults = sampleResults()[:1]
+	evalResults = append(evalResults, &eval.EvalResult{
+		TaskName:   "task-4",
+		Priority:   task.PriorityCritical,
+		SkipReason: "the run stopped after 1 failed tasks",
+		NotRun:     true,
+	})
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{createTestResultsFile(t, evalResults), "--require-critical"})
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify command should return error when a critical task was not run")
+	}
+}
+
+func TestVerifyCommandFilters(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[0].Labels = map[string]string{"suite": "kubernetes"}
+	evalResults[2].Labels = map[string]string{"suite": "helm"}
+	filePath := createTestResultsFile(t, evalResults)
+
+	// Only task-1 has the label, and it passed
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task", "1.0", "--label", "suite=kubernetes"})
+	cmd.SetOut(new(bytes.Buffer))
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("verify of the kubernetes tasks should pass, got error: %v", err)
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task", "1.0", "--difficulty", "easy,hard"})
+	cmd.SetOut(new(bytes.Buffer))
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify of the easy and hard tasks should fail, since task-3 failed")
+	}
+
+	cmd = NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--status", "broken"})
+	cmd.SetOut(new(bytes.Buffer))
+	if err := cmd.Execute(); err == nil {
+		t.Errorf("verify with an unknown status should fail")
+	}
+}
@@ -4,15 +4,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/procmon"
 	"github.com/mcpchecker/mcpchecker/pkg/results"
 	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 )
@@ -21,16 +28,26 @@ const (
 	defaultMaxEvents      = 40
 	defaultMaxOutputLines = 6
 	defaultMaxLineLength  = 100
+	defaultCallsPageSize  = 20
 )
 
 // NewViewCmd creates the view command for rendering eval results.
 func NewViewCmd() *cobra.Command {
 	var (
 		taskFilter     string
+		quiet          bool
 		showTimeline   = true
 		maxEvents      = defaultMaxEvents
 		maxOutputLines = defaultMaxOutputLines
 		maxLineLength  = defaultMaxLineLength
+		callsPage      = 1
+		callsPageSize  = defaultCallsPageSize
+		callNumber     int
+		eventTypes     []string
+		grep           string
+		filters        queryFlags
+		outputFormat   = "text"
+		columns        string
 	)
 
 	cmd := &cobra.Command{
@@ -40,53 +57,144 @@ func NewViewCmd() *cobra.Command {
 
 Examples:
   mcpchecker view mcpchecker-netedge-selector-mismatch-out.json
-  mcpchecker view --task netedge-selector-mismatch --max-events 15 results.json`,
+  mcpchecker view --task netedge-selector-mismatch --max-events 15 results.json
+  mcpchecker view --task netedge-selector-mismatch --calls-page 3 results.json
+  mcpchecker view --task netedge-selector-mismatch --call 42 results.json
+  mcpchecker view --event-type tool,command --grep 'forbidden|denied' results.json
+
+Call history is shown a page at a time. Calls are numbered in the order they
+were made across tools, resources, and prompts; --call shows the full
+arguments and result of one call.
+
+The timeline can be narrowed down to events of some types with --event-type,
+and to events matching a regular expression with --grep.
+
+--columns prints a table of the tasks instead, and --output go-template=...
+prints a Go template for each task, e.g.:
+  mcpchecker view --columns name,status,duration,score results.json
+  mcpchecker view -o 'go-template={{.TaskName}}: {{.Status}}' results.json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			evalResults, err := results.Load(args[0])
+			if callsPage < 1 {
+				return fmt.Errorf("--calls-page must be at least 1")
+			}
+			if callNumber < 0 {
+				return fmt.Errorf("--call must be at least 1")
+			}
+			if callNumber > 0 && quiet {
+				return fmt.Errorf("--call and --quiet cannot be used together")
+			}
+			filter, err := newTimelineFilter(eventTypes, grep)
+			if err != nil {
+				return err
+			}
+			custom, err := parseTaskOutput(outputFormat, columns)
+			if err != nil {
+				return err
+			}
+			if custom == nil && outputFormat != "text" {
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+			if custom != nil && callNumber > 0 {
+				return fmt.Errorf("--call cannot be used with --columns or a go-template output")
+			}
+
+			query, err := filters.query(taskFilter)
+			if err != nil {
+				return err
+			}
+
+			// Only the tasks of a results directory whose name matches are read
+			filtered, err := results.LoadQuery(args[0], query)
 			if err != nil {
 				return err
 			}
 
-			filtered := results.Filter(evalResults, taskFilter)
 			if len(filtered) == 0 {
-				if taskFilter == "" {
+				switch {
+				case cmd.Flags().Changed("label") || cmd.Flags().Changed("difficulty") || cmd.Flags().Changed("status"):
+					return errors.New("no tasks matched the filters")
+				case taskFilter == "":
 					return errors.New("no tasks found in results")
 				}
 				return fmt.Errorf("no tasks matched filter %q", taskFilter)
 			}
 
+			if callNumber > 0 {
+				if len(filtered) > 1 {
+					return fmt.Errorf("--call shows a call of a single task, but %d tasks matched: select one with --task", len(filtered))
+				}
+				return printCallDetail(filtered[0], callNumber)
+			}
+
+			if quiet {
+				failed := results.Select(filtered, results.Query{
+					Statuses: []eval.TaskStatus{eval.TaskStatusFailed, eval.TaskStatusUnexpectedPass},
+				})
+				if len(failed) == 0 {
+					fmt.Printf("No failed tasks among %d tasks\n", len(filtered))
+					return nil
+				}
+				filtered = failed
+			}
+
+			if custom != nil {
+				return custom.print(cmd.OutOrStdout(), filtered)
+			}
+
 			for idx, result := range filtered {
 				if idx > 0 {
 					fmt.Println()
 				}
 				printEvalResult(result, viewOptions{
-					showTimeline:   showTimeline,
+					quiet:          quiet,
+					showTimeline:   showTimeline && !quiet,
 					maxEvents:      maxEvents,
 					maxOutputLines: maxOutputLines,
 					maxLineLength:  maxLineLength,
+					callsPage:      callsPage,
+					callsPageSize:  callsPageSize,
+					timelineFilter: filter,
 				})
 			}
 
+			if len(filtered) > 1 && !quiet {
+				printCompatibilityMatrix(results.CompatibilityMatrix(filtered))
+			}
+
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&taskFilter, "task", "", "Only show results for tasks whose name contains this value")
+	filters.register(cmd)
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", outputFormat, "Output format (text, go-template=<template>, go-template-file=<path>)")
+	cmd.Flags().StringVar(&columns, "columns", "", "Print a table of the tasks with these columns instead of their details ("+columnNames()+")")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only show failed tasks, without call history or timeline")
 	cmd.Flags().BoolVar(&showTimeline, "timeline", showTimeline, "Include a condensed agent timeline derived from taskOutput")
 	cmd.Flags().IntVar(&maxEvents, "max-events", maxEvents, "Maximum number of timeline entries (thought/command/tool/etc.) to display (0 = unlimited)")
 	cmd.Flags().IntVar(&maxOutputLines, "max-output-lines", maxOutputLines, "Maximum lines to display for command output in the timeline")
 	cmd.Flags().IntVar(&maxLineLength, "max-line-length", maxLineLength, "Maximum characters per line when formatting timeline output")
+	cmd.Flags().IntVar(&callsPage, "calls-page", callsPage, "Page of the call history to display")
+	cmd.Flags().IntVar(&callsPageSize, "calls-page-size", callsPageSize, "Number of calls per page of the call history (0 = unlimited)")
+	cmd.Flags().StringSliceVar(&eventTypes, "event-type", nil, "Only show timeline entries of these types ("+strings.Join(timelineEventTypes, ", ")+")")
+	cmd.Flags().StringVar(&grep, "grep", "", "Only show timeline entries matching this regular expression")
+	cmd.Flags().IntVar(&callNumber, "call", 0, "Show the full arguments and result of the call with this number, for a single task")
 
 	return cmd
 }
 
 // viewOptions controls which portions of a result are rendered and how much detail is shown.
 type viewOptions struct {
+	quiet          bool
 	showTimeline   bool
 	maxEvents      int
 	maxOutputLines int
 	maxLineLength  int
+	// callsPage is the 1-based page of the call history to display
+	callsPage      int
+	callsPageSize  int
+	timelineFilter timelineFilter
 }
 
 // printEvalResult prints a formatted summary of a single evaluation result.
@@ -101,11 +209,24 @@ func printEvalResult(result *eval.EvalResult, opts viewOptions) {
 	if result.Difficulty != "" {
 		fmt.Printf("  Difficulty: %s\n", result.Difficulty)
 	}
+	printTaskInfo(result)
 
 	status := "PASSED"
 	statusColor := green
 
 	switch {
+	case result.NotRun:
+		status = fmt.Sprintf("NOT RUN (%s)", result.SkipReason)
+		statusColor = yellow
+	case result.SkipReason != "":
+		status = fmt.Sprintf("SKIPPED (%s)", result.SkipReason)
+		statusColor = yellow
+	case result.Status() == eval.TaskStatusExpectedFailure:
+		status = "FAILED (expected)"
+		statusColor = yellow
+	case result.Status() == eval.TaskStatusUnexpectedPass:
+		status = "PASSED (unexpected, the task is expected to fail)"
+		statusColor = yellow
 	case result.AgentExecutionError:
 		status = "FAILED (agent error)"
 		statusColor = red
@@ -118,28 +239,76 @@ func printEvalResult(result *eval.EvalResult, opts viewOptions) {
 	}
 
 	statusColor.Printf("  Status: %s\n", status)
+	if result.Timing != nil {
+		fmt.Printf("  Duration: %s\n", formatTiming(result.Timing))
+	}
 	if trimmed := strings.TrimSpace(result.TaskError); trimmed != "" {
 		printMultilineField("Error", trimmed)
 	}
+	if result.TaskOutputFile != "" {
+		fmt.Printf("  Full output: %s\n", result.TaskOutputFile)
+	}
+	if result.JudgeTranscriptFile != "" {
+		fmt.Printf("  Judge transcript: %s\n", result.JudgeTranscriptFile)
+	}
 
-	if prompt := loadTaskPrompt(result.TaskPath); prompt != "" {
+	if prompt := loadTaskPrompt(result.TaskPath, result.TaskName); prompt != "" {
 		printMultilineField("Prompt", prompt)
 	}
+	if images := promptImages(result); images != "" {
+		printMultilineField("Images", images)
+	}
 
 	printAssertions(result.AssertionResults, yellow)
-	printCallHistory(result.CallHistory, opts)
+	printSafetyFindings(result.SafetyFindings, yellow)
+	if !opts.quiet {
+		printCallHistory(result.CallHistory, opts)
+		printToolUsage(result.ToolUsage)
+		printContextUsage(result.ContextUsage)
+		printResourceUsage(result.ResourceUsage)
+		printMcpServers(result.McpServers, yellow)
+	}
 
 	if opts.showTimeline {
-		timeline := summarizeTaskOutput(result.TaskOutput, opts.maxEvents, opts.maxOutputLines, opts.maxLineLength)
+		timeline := summarizeTaskOutput(result.TaskOutput, opts.timelineFilter, opts.maxEvents, opts.maxOutputLines, opts.maxLineLength)
 		if len(timeline) > 0 {
 			fmt.Println("  Timeline:")
 			for _, line := range timeline {
 				printTimelineLine(line)
 			}
+		} else if opts.timelineFilter.active() && strings.TrimSpace(result.TaskOutput) != "" {
+			fmt.Println("  Timeline: no events matched")
 		}
 	}
 }
 
+// formatTiming formats the total duration of a task followed by the phases that ran,
+// e.g. "42.1s (setup 2.3s, agent 35.2s, verify 4.1s, judge 3.9s, cleanup 0.5s)"
+func formatTiming(timing *eval.TaskTiming) string {
+	phases := []struct {
+		name     string
+		duration util.Duration
+	}{
+		{"setup", timing.Setup},
+		{"agent", timing.Agent},
+		{"verify", timing.Verify},
+		{"judge", timing.Judge},
+		{"cleanup", timing.Cleanup},
+	}
+
+	var parts []string
+	for _, p := range phases {
+		if p.duration > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", p.name, p.duration))
+		}
+	}
+
+	if len(parts) == 0 {
+		return timing.Total.String()
+	}
+	return fmt.Sprintf("%s (%s)", timing.Total, strings.Join(parts, ", "))
+}
+
 // printAssertions prints assertion counts and any failing assertion reasons.
 func printAssertions(results *eval.CompositeAssertionResult, warn *color.Color) {
 	if results == nil {
@@ -179,10 +348,39 @@ func printAssertions(results *eval.CompositeAssertionResult, warn *color.Color)
 		for _, detail := range res.Details {
 			fmt.Printf("      %s\n", detail)
 		}
+		key, _, _ := strings.Cut(fieldType.Tag.Get("json"), ",")
+		printAssertionHint(key)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(results.Custom)) {
+		res := results.Custom[name]
+		if res == nil || res.Passed {
+			continue
+		}
+
+		fmt.Printf("    • %s: %s\n", name, res.Reason)
+		for _, detail := range res.Details {
+			fmt.Printf("      %s\n", detail)
+		}
+		printAssertionHint("custom")
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(results.Expectations)) {
+		res := results.Expectations[name]
+		if res == nil || res.Passed {
+			continue
+		}
+
+		fmt.Printf("    • Expectation %s: %s\n", name, res.Reason)
+		for _, detail := range res.Details {
+			fmt.Printf("      %s\n", detail)
+		}
+		printAssertionHint("expectations")
 	}
 }
 
-// printCallHistory emits an aggregated summary of tool/resource/prompt usage.
+// printCallHistory emits an aggregated summary of tool/resource/prompt usage,
+// followed by a page of the calls.
 func printCallHistory(history *mcpproxy.CallHistory, opts viewOptions) {
 	if history == nil {
 		return
@@ -192,7 +390,7 @@ func printCallHistory(history *mcpproxy.CallHistory, opts viewOptions) {
 	resourceReads := len(history.ResourceReads)
 	promptGets := len(history.PromptGets)
 
-	if toolCalls == 0 && resourceReads == 0 && promptGets == 0 {
+	if toolCalls == 0 && resourceReads == 0 && promptGets == 0 && len(history.Disruptions) == 0 {
 		return
 	}
 
@@ -210,31 +408,244 @@ func printCallHistory(history *mcpproxy.CallHistory, opts viewOptions) {
 		fmt.Printf(" prompts=%d", promptGets)
 	}
 	fmt.Println()
+	printDisruptions(history.Disruptions)
 
-	if toolCalls > 0 {
-		printToolCallDetails(history.ToolCalls, opts)
+	printCallPage(collectCalls(history), opts)
+}
+
+// printDisruptions prints the kills and restarts of servers by chaos mode
+func printDisruptions(disruptions []*mcpproxy.Disruption) {
+	for _, d := range disruptions {
+		outcome := fmt.Sprintf("restarted after %s", d.Downtime.Round(time.Millisecond))
+		if !d.Restarted {
+			outcome = "not restarted: " + d.Error
+		}
+		fmt.Printf("    Chaos: %s killed after %d calls (%s trigger), %s\n", d.ServerName, d.Calls, d.Trigger, outcome)
 	}
 }
 
-// printToolCallDetails prints detailed tool call output for timeline inspection.
-func printToolCallDetails(calls []*mcpproxy.ToolCall, opts viewOptions) {
-	fmt.Println("    Tool output:")
-	for _, call := range calls {
+// printToolUsage prints the calls and payload sizes of each tool, largest
+// responses first
+func printToolUsage(usage []mcpproxy.ToolUsage) {
+	if len(usage) == 0 {
+		return
+	}
+
+	fmt.Println("  Tool usage:")
+	fmt.Printf("    %-40s %6s %6s %10s %10s %10s %8s %9s\n", "TOOL", "CALLS", "ERRORS", "REQUEST", "RESPONSE", "MAX RESP", "TOKENS", "CALLS/MIN")
+	for _, u := range usage {
+		rate := "-"
+		if u.CallsPerMinute > 0 {
+			rate = fmt.Sprintf("%.1f", u.CallsPerMinute)
+		}
+		fmt.Printf("    %-40s %6d %6d %10s %10s %10s %8d %9s\n",
+			truncateString(u.Server+"/"+u.Tool, 40), u.Calls, u.Errors,
+			formatBytes(u.RequestBytes), formatBytes(u.ResponseBytes), formatBytes(u.MaxResponseBytes), u.Tokens, rate)
+	}
+}
+
+// printContextUsage prints the estimated tokens the tool results added to
+// the context, and how the total grew with each call
+func printContextUsage(usage *eval.ContextUsage) {
+	if usage == nil {
+		return
+	}
+
+	fmt.Printf("  Context: ~%d tokens from tool results (%s)\n", usage.TotalTokens, usage.Tokenizer)
+	if usage.LargestTool != "" {
+		fmt.Printf("    Largest result: %s, ~%d tokens\n", usage.LargestTool, usage.LargestTokens)
+	}
+	if len(usage.Growth) > 1 {
+		fmt.Printf("    Growth: %s\n", formatGrowth(usage.Growth))
+	}
+	if usage.Error != "" {
+		fmt.Printf("    Error: %s\n", usage.Error)
+	}
+}
+
+// printResourceUsage prints the CPU and memory used by the agent processes
+func printResourceUsage(usage *procmon.Usage) {
+	if usage == nil {
+		return
+	}
+
+	fmt.Printf("  Agent resources: peak memory %s (avg %s), CPU %.1fs (peak %.0f%%, avg %.0f%%)\n",
+		formatBytes(usage.PeakMemoryBytes), formatBytes(usage.AverageMemoryBytes),
+		usage.CPUSeconds, usage.PeakCPUPercent, usage.AverageCPUPercent)
+	if usage.LimitExceeded != "" {
+		fmt.Printf("    Killed for exceeding %s\n", usage.LimitExceeded)
+	}
+}
+
+// printMcpServers prints the MCP protocol version each server negotiated
+func printMcpServers(servers []mcpproxy.Compatibility, warn *color.Color) {
+	if len(servers) == 0 {
+		return
+	}
+
+	fmt.Println("  MCP servers:")
+	for _, s := range servers {
+		line := fmt.Sprintf("    %s: protocol %s", s.Server, s.ProtocolVersion)
+		if s.ServerInfo != "" {
+			line += fmt.Sprintf(" (%s)", s.ServerInfo)
+		}
+		if !s.Compatible {
+			warn.Printf("%s, not one of %s\n", line, strings.Join(s.Accepted, ", "))
+			continue
+		}
+		fmt.Println(line)
+	}
+}
+
+// printCompatibilityMatrix prints the protocol versions each server
+// negotiated across the tasks shown
+func printCompatibilityMatrix(matrix []results.ServerCompatibility) {
+	if len(matrix) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("MCP protocol compatibility:")
+	fmt.Printf("  %-24s %-24s %-24s %s\n", "SERVER", "NEGOTIATED", "ACCEPTED", "INCOMPATIBLE TASKS")
+	for _, s := range matrix {
+		accepted := "any"
+		if len(s.Accepted) > 0 {
+			accepted = strings.Join(s.Accepted, ", ")
+		}
+		fmt.Printf("  %-24s %-24s %-24s %d/%d\n", truncateString(s.Server, 24),
+			strings.Join(s.ProtocolVersions, ", "), accepted, len(s.IncompatibleTasks), s.Tasks)
+	}
+}
+
+// formatGrowth formats the running totals of tokens after each call, leaving
+// out the middle of long runs
+func formatGrowth(growth []int) string {
+	const head, tail = 4, 3
+
+	values := make([]string, 0, head+tail+1)
+	for i, total := range growth {
+		if len(growth) > head+tail+1 && i == head {
+			values = append(values, "…")
+		}
+		if len(growth) > head+tail+1 && i >= head && i < len(growth)-tail {
+			continue
+		}
+		values = append(values, strconv.Itoa(total))
+	}
+	return strings.Join(values, " → ")
+}
+
+// formatBytes formats a size in bytes with a binary unit, e.g. "1.5 KiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n) / unit
+	for _, suffix := range []string{"KiB", "MiB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f GiB", value)
+}
+
+// callEntry is a recorded call of any kind. Calls are numbered by their
+// position in the list returned by collectCalls.
+type callEntry struct {
+	kind     string
+	name     string
+	record   mcpproxy.CallRecord
+	tool     *mcpproxy.ToolCall
+	resource *mcpproxy.ResourceRead
+	prompt   *mcpproxy.PromptGet
+}
+
+// loadSpilled reads the request and result of the call back from its calls
+// file, if they were moved there when the task ran
+func (c callEntry) loadSpilled() error {
+	switch {
+	case c.tool != nil:
+		return c.tool.LoadSpilled()
+	case c.resource != nil:
+		return c.resource.LoadSpilled()
+	case c.prompt != nil:
+		return c.prompt.LoadSpilled()
+	}
+	return nil
+}
+
+// collectCalls merges the tool calls, resource reads, and prompt gets of a
+// call history in the order they were made.
+func collectCalls(history *mcpproxy.CallHistory) []callEntry {
+	calls := make([]callEntry, 0, len(history.ToolCalls)+len(history.ResourceReads)+len(history.PromptGets))
+	for _, c := range history.ToolCalls {
+		calls = append(calls, callEntry{kind: "tool", name: c.ToolName, record: c.CallRecord, tool: c})
+	}
+	for _, r := range history.ResourceReads {
+		calls = append(calls, callEntry{kind: "resource", name: r.URI, record: r.CallRecord, resource: r})
+	}
+	for _, p := range history.PromptGets {
+		calls = append(calls, callEntry{kind: "prompt", name: p.Name, record: p.CallRecord, prompt: p})
+	}
+
+	sort.SliceStable(calls, func(i, j int) bool {
+		return calls[i].record.Timestamp.Before(calls[j].record.Timestamp)
+	})
+
+	return calls
+}
+
+// callsPageBounds returns the indices of the first and past-the-last calls on
+// a 1-based page, and the number of pages. A page size of 0 puts all calls on
+// one page.
+func callsPageBounds(total, page, pageSize int) (start, end, pages int) {
+	if pageSize <= 0 || total == 0 {
+		pageSize = max(total, 1)
+	}
+
+	pages = (total + pageSize - 1) / pageSize
+	start = min((page-1)*pageSize, total)
+	end = min(start+pageSize, total)
+	return start, end, pages
+}
+
+// printCallPage prints one page of calls, with a short output of tool calls.
+// Tool output is only extracted for the calls on the page.
+func printCallPage(calls []callEntry, opts viewOptions) {
+	faint := color.New(color.Faint)
+
+	start, end, pages := callsPageBounds(len(calls), opts.callsPage, opts.callsPageSize)
+	if start == end {
+		fmt.Printf("    No calls on page %d (%d pages)\n", opts.callsPage, pages)
+		return
+	}
+
+	fmt.Println("    Calls:")
+	for i := start; i < end; i++ {
+		call := calls[i]
 		status := "ok"
-		if !call.Success {
+		if !call.record.Success {
 			status = "fail"
 		}
-		header := fmt.Sprintf("      • %s::%s (%s)", call.ServerName, call.ToolName, status)
-		fmt.Println(header)
+		fmt.Printf("      #%d %s %s::%s (%s)\n", i+1, call.kind, call.record.ServerName, call.name, status)
 
-		snippet := strings.TrimSpace(extractToolText(call))
+		if call.tool == nil {
+			continue
+		}
+		if err := call.tool.LoadSpilled(); err != nil {
+			faint.Printf("        %v\n", err)
+			continue
+		}
+
+		snippet := strings.TrimSpace(extractToolText(call.tool))
 		if snippet == "" {
 			continue
 		}
 
-		lineLimit := opts.maxOutputLines
-		width := opts.maxLineLength
-		block := limitMultiline(snippet, lineLimit, width)
+		block := limitMultiline(snippet, opts.maxOutputLines, opts.maxLineLength)
 		for _, line := range strings.Split(block, "\n") {
 			if strings.TrimSpace(line) == "" {
 				continue
@@ -242,6 +653,105 @@ func printToolCallDetails(calls []*mcpproxy.ToolCall, opts viewOptions) {
 			fmt.Printf("        %s\n", line)
 		}
 	}
+
+	if pages > 1 {
+		hint := "--call <n> for details"
+		if end < len(calls) {
+			hint = fmt.Sprintf("--calls-page %d for more, %s", opts.callsPage+1, hint)
+		}
+		faint.Printf("    Calls %d-%d of %d (page %d/%d): %s\n", start+1, end, len(calls), opts.callsPage, pages, hint)
+	}
+}
+
+// printCallDetail prints the full arguments and result of a call of a task.
+func printCallDetail(result *eval.EvalResult, number int) error {
+	var calls []callEntry
+	if result.CallHistory != nil {
+		calls = collectCalls(result.CallHistory)
+	}
+	if number > len(calls) {
+		return fmt.Errorf("call %d does not exist: task %s has %d calls", number, result.TaskName, len(calls))
+	}
+
+	call := calls[number-1]
+	bold := color.New(color.Bold)
+
+	bold.Printf("Task: %s\n", result.TaskName)
+	fmt.Printf("  Call: #%d of %d\n", number, len(calls))
+	fmt.Printf("  Kind: %s\n", call.kind)
+	fmt.Printf("  Server: %s\n", call.record.ServerName)
+	fmt.Printf("  Name: %s\n", call.name)
+	if !call.record.Timestamp.IsZero() {
+		fmt.Printf("  Time: %s\n", call.record.Timestamp.Format(time.RFC3339Nano))
+	}
+	if call.record.Success {
+		color.New(color.FgGreen).Println("  Status: ok")
+	} else {
+		color.New(color.FgRed).Println("  Status: fail")
+	}
+	if call.record.Error != "" {
+		printMultilineField("Error", call.record.Error)
+	}
+
+	if err := call.loadSpilled(); err != nil {
+		return err
+	}
+
+	var args, res any
+	switch {
+	case call.tool != nil:
+		if call.tool.Request != nil && call.tool.Request.Params != nil {
+			args = call.tool.Request.Params.Arguments
+		}
+		if text := strings.TrimSpace(extractToolText(call.tool)); text != "" {
+			res = text
+		} else if call.tool.Result != nil {
+			res = call.tool.Result
+		}
+	case call.resource != nil:
+		if call.resource.Result != nil {
+			res = call.resource.Result
+		}
+	case call.prompt != nil:
+		if call.prompt.Request != nil && call.prompt.Request.Params != nil {
+			args = call.prompt.Request.Params.Arguments
+		}
+		if call.prompt.Result != nil {
+			res = call.prompt.Result
+		}
+	}
+
+	if args != nil {
+		printDetailBlock("Arguments", args)
+	}
+	if res != nil {
+		printDetailBlock("Result", res)
+	}
+
+	return nil
+}
+
+// printDetailBlock prints a value in full, indented below its label. Values
+// that are not text are printed as indented JSON.
+func printDetailBlock(label string, value any) {
+	text, ok := value.(string)
+	if !ok {
+		if raw, isRaw := value.(json.RawMessage); isRaw {
+			var decoded any
+			if err := json.Unmarshal(raw, &decoded); err == nil {
+				value = decoded
+			}
+		}
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			text = fmt.Sprintf("[marshal error: %v]", err)
+		} else {
+			text = string(data)
+		}
+	}
+
+	fmt.Printf("  %s:\n", label)
+	fmt.Println(indentBlock(strings.TrimRight(text, "\n"), "    "))
 }
 
 // extractToolText flattens the mixed content of a tool call into readable text.
@@ -359,7 +869,8 @@ type todoEntry struct {
 }
 
 // summarizeTaskOutput condenses raw agent event lines into human-readable timeline entries.
-func summarizeTaskOutput(raw string, maxEvents, maxOutputLines, maxLineLength int) []string {
+// Entries not selected by filter are dropped before maxEvents is applied.
+func summarizeTaskOutput(raw string, filter timelineFilter, maxEvents, maxOutputLines, maxLineLength int) []string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
 		return nil
@@ -372,6 +883,16 @@ func summarizeTaskOutput(raw string, maxEvents, maxOutputLines, maxLineLength in
 		summaries = summarizePlaintextTaskOutput(raw, maxOutputLines, maxLineLength)
 	}
 
+	if filter.active() {
+		selected := summaries[:0]
+		for _, entry := range summaries {
+			if filter.matches(entry) {
+				selected = append(selected, entry)
+			}
+		}
+		summaries = selected
+	}
+
 	if maxEvents > 0 && len(summaries) > maxEvents {
 		extra := len(summaries) - maxEvents
 		summaries = append(summaries[:maxEvents], fmt.Sprintf("… %d additional events omitted", extra))
@@ -380,6 +901,84 @@ func summarizeTaskOutput(raw string, maxEvents, maxOutputLines, maxLineLength in
 	return summaries
 }
 
+// timelineEventTypes are the types timeline entries can be filtered by
+var timelineEventTypes = []string{"reasoning", "command", "tool", "plan", "message", "note", "other"}
+
+// timelineFilter selects timeline entries by event type and by a regular
+// expression. The zero value selects all entries.
+type timelineFilter struct {
+	types map[string]bool
+	grep  *regexp.Regexp
+}
+
+// newTimelineFilter parses the event types and the regular expression given
+// on the command line
+func newTimelineFilter(eventTypes []string, grep string) (timelineFilter, error) {
+	var filter timelineFilter
+
+	for _, t := range eventTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if !slices.Contains(timelineEventTypes, t) {
+			return filter, fmt.Errorf("unknown event type %q: must be one of %s", t, strings.Join(timelineEventTypes, ", "))
+		}
+		if filter.types == nil {
+			filter.types = map[string]bool{}
+		}
+		filter.types[t] = true
+	}
+
+	if grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+		filter.grep = re
+	}
+
+	return filter, nil
+}
+
+func (f timelineFilter) active() bool {
+	return len(f.types) > 0 || f.grep != nil
+}
+
+// matches returns whether a timeline entry is selected. The pattern is also
+// matched against the entry with its line wrapping undone, so that it can
+// match text that was wrapped across lines.
+func (f timelineFilter) matches(entry string) bool {
+	if len(f.types) > 0 && !f.types[timelineEventType(entry)] {
+		return false
+	}
+	if f.grep != nil && !f.grep.MatchString(entry) && !f.grep.MatchString(strings.Join(strings.Fields(entry), " ")) {
+		return false
+	}
+	return true
+}
+
+// timelineEventType classifies a timeline entry by the prefix it was given
+// when the agent output was summarized
+func timelineEventType(entry string) string {
+	switch {
+	case strings.HasPrefix(entry, "thought: "):
+		return "reasoning"
+	case strings.HasPrefix(entry, "command: "), entry == "command", strings.HasPrefix(entry, "command\n"):
+		return "command"
+	case strings.HasPrefix(entry, "tool: "), strings.HasPrefix(entry, "tool call"), strings.HasPrefix(entry, "tool result"):
+		return "tool"
+	case strings.HasPrefix(entry, "plan: "):
+		return "plan"
+	case strings.HasPrefix(entry, "assistant: "), strings.HasPrefix(entry, "user: "):
+		return "message"
+	case strings.HasPrefix(entry, "note: "):
+		return "note"
+	default:
+		return "other"
+	}
+}
+
 // formatEvent converts an agent event into a concise timeline string, if applicable.
 func formatEvent(evt agentEvent, maxOutputLines, maxLineLength int) string {
 	switch evt.Type {
@@ -445,6 +1044,10 @@ func formatEvent(evt agentEvent, maxOutputLines, maxLineLength int) string {
 		text := normalizeWhitespace(item.Text)
 		text = wrapText(text, maxLineLength)
 		return fmt.Sprintf("thought: %s", text)
+	case "agent_message":
+		text := normalizeWhitespace(item.Text)
+		text = wrapText(text, maxLineLength)
+		return fmt.Sprintf("assistant: %s", text)
 	case "command_execution":
 		summary := fmt.Sprintf("command: %s", item.Command)
 		if item.Status != "" {
@@ -929,14 +1532,41 @@ func wrapText(s string, width int) string {
 	return strings.Join(lines, "\n")
 }
 
+// promptImages returns the images that were attached to the prompt, as
+// recorded in the output of the agent phase
+func promptImages(result *eval.EvalResult) string {
+	if result.AgentOutput == nil || len(result.AgentOutput.Steps) == 0 || result.AgentOutput.Steps[0] == nil {
+		return ""
+	}
+	return result.AgentOutput.Steps[0].Outputs["images"]
+}
+
 // loadTaskPrompt returns the prompt text defined in the task manifest, if present.
-func loadTaskPrompt(taskPath string) string {
+// For a task with a dataset, it is the prompt of the row the task is named after.
+func loadTaskPrompt(taskPath, taskName string) string {
 	if taskPath == "" {
 		return ""
 	}
 
 	taskConfig, err := task.FromFile(taskPath)
-	if err != nil || taskConfig == nil || taskConfig.Spec == nil || taskConfig.Spec.Prompt == nil || taskConfig.Spec.Prompt.IsEmpty() {
+	if err != nil || taskConfig == nil {
+		return ""
+	}
+
+	if taskConfig.Spec != nil && taskConfig.Spec.Dataset != nil {
+		tasks, err := taskConfig.Expand()
+		if err != nil {
+			return ""
+		}
+		taskConfig = nil
+		for _, t := range tasks {
+			if t.Metadata.Name == taskName {
+				taskConfig = t
+			}
+		}
+	}
+
+	if taskConfig == nil || taskConfig.Spec == nil || taskConfig.Spec.Prompt == nil || taskConfig.Spec.Prompt.IsEmpty() {
 		return ""
 	}
 
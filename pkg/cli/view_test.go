@@ -3,6 +3,11 @@ package cli
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 func TestSummarizeTaskOutput(t *testing.T) {
@@ -55,6 +60,23 @@ output: file1 file2
 				"user: next step",
 			},
 		},
+		{
+			name: "Codex JSON Stream",
+			input: `{"type":"thread.started","thread_id":"t1"}
+{"type":"turn.started"}
+{"type":"item.completed","item":{"id":"item_0","type":"reasoning","text":"Checking the pods"}}
+{"type":"item.started","item":{"id":"item_1","type":"mcp_tool_call","server":"kubernetes","tool":"pods_list","status":"in_progress"}}
+{"type":"item.completed","item":{"id":"item_1","type":"mcp_tool_call","server":"kubernetes","tool":"pods_list","status":"completed"}}
+{"type":"item.completed","item":{"id":"item_2","type":"agent_message","text":"All pods are running"}}
+{"type":"turn.completed","usage":{"input_tokens":10,"output_tokens":5}}
+`,
+			maxEvents: 0,
+			expectedItems: []string{
+				"thought: Checking the pods",
+				"tool: kubernetes::pods_list (completed)",
+				"assistant: All pods are running",
+			},
+		},
 		// ...
 		{
 			name: "Truncation of Long Output",
@@ -98,7 +120,7 @@ Line 7
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Using default maxOutputLines=6, maxLineLength=100
-			got := summarizeTaskOutput(tt.input, tt.maxEvents, 6, 100)
+			got := summarizeTaskOutput(tt.input, timelineFilter{}, tt.maxEvents, 6, 100)
 
 			// Verify all expected items are present in order (substring match for simplicity)
 			nextIdx := 0
@@ -119,6 +141,87 @@ Line 7
 	}
 }
 
+func TestSummarizeTaskOutputFilter(t *testing.T) {
+	input := `Thinking:
+I need to check the pods.
+
+Exec:
+kubectl get pods
+Error from server (Forbidden): pods is forbidden
+
+Tool:
+pods_list
+
+Assistant:
+The pods are forbidden to list.
+`
+
+	tests := []struct {
+		name       string
+		eventTypes []string
+		grep       string
+		maxEvents  int
+		want       []string
+	}{
+		{
+			name:       "event types",
+			eventTypes: []string{"command", "TOOL"},
+			want:       []string{"command: kubectl get pods", "tool: pods_list"},
+		},
+		{
+			name: "grep",
+			grep: "(?i)forbidden",
+			want: []string{"command: kubectl get pods", "assistant: The pods are forbidden to list."},
+		},
+		{
+			name:       "event types and grep",
+			eventTypes: []string{"message"},
+			grep:       "forbidden",
+			want:       []string{"assistant: The pods are forbidden to list."},
+		},
+		{
+			name:      "max events applies after filtering",
+			grep:      "pods",
+			maxEvents: 1,
+			want:      []string{"thought: I need to check the pods.", "… 3 additional events omitted"},
+		},
+		{
+			name: "grep matches across wrapped lines",
+			grep: "forbidden to list",
+			want: []string{"assistant: The pods are forbidden to list."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newTimelineFilter(tt.eventTypes, tt.grep)
+			if err != nil {
+				t.Fatalf("newTimelineFilter() error = %v", err)
+			}
+
+			got := summarizeTaskOutput(input, filter, tt.maxEvents, 6, 20)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d:\n%s", len(got), len(tt.want), strings.Join(got, "\n---\n"))
+			}
+			for i, want := range tt.want {
+				// Entries are wrapped to 20 characters
+				if !strings.HasPrefix(normalizeWhitespace(got[i]), want) {
+					t.Errorf("entry %d = %q, want %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewTimelineFilterErrors(t *testing.T) {
+	if _, err := newTimelineFilter([]string{"thought"}, ""); err == nil || !strings.Contains(err.Error(), "unknown event type") {
+		t.Errorf("expected unknown event type error, got %v", err)
+	}
+	if _, err := newTimelineFilter(nil, "("); err == nil || !strings.Contains(err.Error(), "invalid --grep pattern") {
+		t.Errorf("expected invalid pattern error, got %v", err)
+	}
+}
+
 func TestTruncateString(t *testing.T) {
 	tests := []struct {
 		input string
@@ -141,3 +244,104 @@ func TestTruncateString(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		input int64
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.input); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatGrowth(t *testing.T) {
+	if got := formatGrowth([]int{10, 30, 60}); got != "10 → 30 → 60" {
+		t.Errorf("formatGrowth() = %q", got)
+	}
+
+	got := formatGrowth([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	if want := "1 → 2 → 3 → 4 → … → 8 → 9 → 10"; got != want {
+		t.Errorf("formatGrowth() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatTiming(t *testing.T) {
+	timing := &eval.TaskTiming{
+		Total:   util.Duration(42 * time.Second),
+		Setup:   util.Duration(2 * time.Second),
+		Agent:   util.Duration(35 * time.Second),
+		Verify:  util.Duration(4 * time.Second),
+		Judge:   util.Duration(3900 * time.Millisecond),
+		Cleanup: util.Duration(time.Second),
+	}
+
+	want := "42s (setup 2s, agent 35s, verify 4s, judge 3.9s, cleanup 1s)"
+	if got := formatTiming(timing); got != want {
+		t.Errorf("formatTiming() = %q, want %q", got, want)
+	}
+
+	// Setup failures skip the agent and verify phases
+	timing = &eval.TaskTiming{Total: util.Duration(time.Second), Setup: util.Duration(time.Second)}
+	if got := formatTiming(timing); got != "1s (setup 1s)" {
+		t.Errorf("formatTiming() = %q, want %q", got, "1s (setup 1s)")
+	}
+}
+
+func TestCollectCalls(t *testing.T) {
+	start := time.Now()
+	record := func(offset int) mcpproxy.CallRecord {
+		return mcpproxy.CallRecord{ServerName: "k8s", Timestamp: start.Add(time.Duration(offset) * time.Second), Success: true}
+	}
+
+	history := &mcpproxy.CallHistory{
+		ToolCalls: []*mcpproxy.ToolCall{
+			{CallRecord: record(0), ToolName: "pods_list"},
+			{CallRecord: record(3), ToolName: "pods_log"},
+		},
+		ResourceReads: []*mcpproxy.ResourceRead{{CallRecord: record(1), URI: "file:///config"}},
+		PromptGets:    []*mcpproxy.PromptGet{{CallRecord: record(2), Name: "debug"}},
+	}
+
+	calls := collectCalls(history)
+
+	var got []string
+	for _, c := range calls {
+		got = append(got, c.kind+":"+c.name)
+	}
+	want := "tool:pods_list resource:file:///config prompt:debug tool:pods_log"
+	if strings.Join(got, " ") != want {
+		t.Errorf("collectCalls() = %v, want %s", got, want)
+	}
+}
+
+func TestCallsPageBounds(t *testing.T) {
+	tests := []struct {
+		total, page, pageSize int
+		start, end, pages     int
+	}{
+		{total: 45, page: 1, pageSize: 20, start: 0, end: 20, pages: 3},
+		{total: 45, page: 3, pageSize: 20, start: 40, end: 45, pages: 3},
+		{total: 45, page: 4, pageSize: 20, start: 45, end: 45, pages: 3},
+		{total: 45, page: 1, pageSize: 0, start: 0, end: 45, pages: 1},
+		{total: 20, page: 1, pageSize: 20, start: 0, end: 20, pages: 1},
+		{total: 0, page: 1, pageSize: 20, start: 0, end: 0, pages: 0},
+	}
+
+	for _, tt := range tests {
+		start, end, pages := callsPageBounds(tt.total, tt.page, tt.pageSize)
+		if start != tt.start || end != tt.end || pages != tt.pages {
+			t.Errorf("callsPageBounds(%d, %d, %d) = %d, %d, %d, want %d, %d, %d",
+				tt.total, tt.page, tt.pageSize, start, end, pages, tt.start, tt.end, tt.pages)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package eval
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
@@ -15,18 +18,28 @@ const (
 	assertionTypeToolsNotUsed     = "toolsNotUsed"
 	assertionTypeMinToolCalls     = "minToolCalls"
 	assertionTypeMaxToolCalls     = "maxToolCalls"
+	assertionTypeToolCallCounts   = "toolCallCounts"
 	assertionTypeResourcesRead    = "resourcesRead"
 	assertionTypeResourcesNotRead = "resourcesNotRead"
 	assertionTypePromptsUsed      = "promptsUsed"
 	assertionTypePromptsNotUsed   = "promptsNotUsed"
 	assertionTypeCallOrder        = "callOrder"
+	assertionTypePhases           = "phases"
 	assertionTypeNoDuplicateCalls = "noDuplicateCalls"
+	assertionTypeMaxAgentDuration = "maxAgentDuration"
+	assertionTypeExpr             = "expr"
 )
 
 type SingleAssertionResult struct {
-	Passed  bool     `json:"passed"`
-	Reason  string   `json:"reason,omitempty"`
-	Details []string `json:"details,omitempty"`
+	Passed bool `json:"passed"`
+	// Reason is the English message of a failure
+	Reason string `json:"reason,omitempty"`
+	// Code identifies the reason of a failure, and Params are the values its
+	// message is rendered with, so that failures can be grouped and
+	// translated. See ReasonMessages.
+	Code    ReasonCode        `json:"code,omitempty"`
+	Params  map[string]string `json:"params,omitempty"`
+	Details []string          `json:"details,omitempty"`
 }
 
 func (s *SingleAssertionResult) Succeeded() bool {
@@ -43,19 +56,45 @@ type CompositeAssertionResult struct {
 	ToolsNotUsed     *SingleAssertionResult `json:"toolsNotUsed,omitempty"`
 	MinToolCalls     *SingleAssertionResult `json:"minToolCalls,omitempty"`
 	MaxToolCalls     *SingleAssertionResult `json:"maxToolCalls,omitempty"`
+	ToolCallCounts   *SingleAssertionResult `json:"toolCallCounts,omitempty"`
 	ResourcesRead    *SingleAssertionResult `json:"resourcesRead,omitempty"`
 	ResourcesNotRead *SingleAssertionResult `json:"resourcesNotRead,omitempty"`
 	PromptsUsed      *SingleAssertionResult `json:"promptsUsed,omitempty"`
 	PromptsNotUsed   *SingleAssertionResult `json:"promptsNotUsed,omitempty"`
 	CallOrder        *SingleAssertionResult `json:"callOrder,omitempty"`
+	Phases           *SingleAssertionResult `json:"phases,omitempty"`
 	NoDuplicateCalls *SingleAssertionResult `json:"noDuplicateCalls,omitempty"`
+	MaxAgentDuration *SingleAssertionResult `json:"maxAgentDuration,omitempty"`
+	MaxContextTokens *SingleAssertionResult `json:"maxContextTokens,omitempty"`
+	GroundedOutput   *SingleAssertionResult `json:"groundedOutput,omitempty"`
+	Expr             *SingleAssertionResult `json:"expr,omitempty"`
+
+	// Custom holds the results of custom assertions by name
+	Custom map[string]*SingleAssertionResult `json:"custom,omitempty"`
+
+	// Expectations holds the results of the output expectations of the eval
+	// by name
+	Expectations map[string]*SingleAssertionResult `json:"expectations,omitempty"`
 }
 
 func (c *CompositeAssertionResult) Succeeded() bool {
+	for _, res := range c.Custom {
+		if !res.Succeeded() {
+			return false
+		}
+	}
+	for _, res := range c.Expectations {
+		if !res.Succeeded() {
+			return false
+		}
+	}
+
 	return c.ToolsUsed.Succeeded() && c.RequireAny.Succeeded() && c.ToolsNotUsed.Succeeded() &&
-		c.MinToolCalls.Succeeded() && c.MaxToolCalls.Succeeded() && c.ResourcesRead.Succeeded() &&
+		c.MinToolCalls.Succeeded() && c.MaxToolCalls.Succeeded() && c.ToolCallCounts.Succeeded() &&
+		c.ResourcesRead.Succeeded() &&
 		c.ResourcesNotRead.Succeeded() && c.PromptsUsed.Succeeded() && c.PromptsNotUsed.Succeeded() &&
-		c.CallOrder.Succeeded() && c.NoDuplicateCalls.Succeeded()
+		c.CallOrder.Succeeded() && c.Phases.Succeeded() && c.NoDuplicateCalls.Succeeded() && c.MaxAgentDuration.Succeeded() &&
+		c.MaxContextTokens.Succeeded() && c.GroundedOutput.Succeeded() && c.Expr.Succeeded()
 }
 
 // TotalAssertions returns the total number of individual assertions that were evaluated
@@ -76,6 +115,9 @@ func (c *CompositeAssertionResult) TotalAssertions() int {
 	if c.MaxToolCalls != nil {
 		count++
 	}
+	if c.ToolCallCounts != nil {
+		count++
+	}
 	if c.ResourcesRead != nil {
 		count++
 	}
@@ -91,9 +133,34 @@ func (c *CompositeAssertionResult) TotalAssertions() int {
 	if c.CallOrder != nil {
 		count++
 	}
+	if c.Phases != nil {
+		count++
+	}
 	if c.NoDuplicateCalls != nil {
 		count++
 	}
+	if c.MaxAgentDuration != nil {
+		count++
+	}
+	if c.MaxContextTokens != nil {
+		count++
+	}
+	if c.GroundedOutput != nil {
+		count++
+	}
+	if c.Expr != nil {
+		count++
+	}
+	for _, res := range c.Custom {
+		if res != nil {
+			count++
+		}
+	}
+	for _, res := range c.Expectations {
+		if res != nil {
+			count++
+		}
+	}
 	return count
 }
 
@@ -115,6 +182,9 @@ func (c *CompositeAssertionResult) PassedAssertions() int {
 	if c.MaxToolCalls != nil && c.MaxToolCalls.Succeeded() {
 		count++
 	}
+	if c.ToolCallCounts != nil && c.ToolCallCounts.Succeeded() {
+		count++
+	}
 	if c.ResourcesRead != nil && c.ResourcesRead.Succeeded() {
 		count++
 	}
@@ -130,9 +200,34 @@ func (c *CompositeAssertionResult) PassedAssertions() int {
 	if c.CallOrder != nil && c.CallOrder.Succeeded() {
 		count++
 	}
+	if c.Phases != nil && c.Phases.Succeeded() {
+		count++
+	}
 	if c.NoDuplicateCalls != nil && c.NoDuplicateCalls.Succeeded() {
 		count++
 	}
+	if c.MaxAgentDuration != nil && c.MaxAgentDuration.Succeeded() {
+		count++
+	}
+	if c.MaxContextTokens != nil && c.MaxContextTokens.Succeeded() {
+		count++
+	}
+	if c.GroundedOutput != nil && c.GroundedOutput.Succeeded() {
+		count++
+	}
+	if c.Expr != nil && c.Expr.Succeeded() {
+		count++
+	}
+	for _, res := range c.Custom {
+		if res != nil && res.Succeeded() {
+			count++
+		}
+	}
+	for _, res := range c.Expectations {
+		if res != nil && res.Succeeded() {
+			count++
+		}
+	}
 	return count
 }
 
@@ -154,7 +249,10 @@ type assertionEvaluator struct {
 	evaluators []SingleAssertionEvaluator
 }
 
-func NewCompositeAssertionEvaluator(assertions *TaskAssertions) CompositeAssertionEvaluator {
+// NewCompositeAssertionEvaluator creates an evaluator for the built-in
+// assertions, and the evaluators of custom assertions, whose results are
+// reported by their Type.
+func NewCompositeAssertionEvaluator(assertions *TaskAssertions, custom ...SingleAssertionEvaluator) CompositeAssertionEvaluator {
 	evaluators := make([]SingleAssertionEvaluator, 0)
 	if len(assertions.ToolsUsed) > 0 {
 		evaluators = append(evaluators, NewToolsUsedEvaluator(assertions.ToolsUsed))
@@ -176,6 +274,10 @@ func NewCompositeAssertionEvaluator(assertions *TaskAssertions) CompositeAsserti
 		evaluators = append(evaluators, NewMaxToolCallsEvaluator(*assertions.MaxToolCalls))
 	}
 
+	if len(assertions.ToolCallCounts) > 0 {
+		evaluators = append(evaluators, NewToolCallCountsEvaluator(assertions.ToolCallCounts))
+	}
+
 	if len(assertions.ResourcesRead) > 0 {
 		evaluators = append(evaluators, NewResourcesReadEvaluator(assertions.ResourcesRead))
 	}
@@ -196,10 +298,16 @@ func NewCompositeAssertionEvaluator(assertions *TaskAssertions) CompositeAsserti
 		evaluators = append(evaluators, NewCallOrderEvaluator(assertions.CallOrder))
 	}
 
-	if assertions.NoDuplicateCalls {
-		evaluators = append(evaluators, NewNoDuplicateCallsEvaluator())
+	if len(assertions.Phases) > 0 {
+		evaluators = append(evaluators, NewPhasesEvaluator(assertions.Phases))
 	}
 
+	if assertions.NoDuplicateCalls.Enabled() {
+		evaluators = append(evaluators, NewNoDuplicateCallsEvaluator(assertions.NoDuplicateCalls))
+	}
+
+	evaluators = append(evaluators, custom...)
+
 	return &assertionEvaluator{
 		evaluators: evaluators,
 	}
@@ -221,6 +329,8 @@ func (a *assertionEvaluator) Evaluate(history *mcpproxy.CallHistory) *CompositeA
 			res.MinToolCalls = got
 		case assertionTypeMaxToolCalls:
 			res.MaxToolCalls = got
+		case assertionTypeToolCallCounts:
+			res.ToolCallCounts = got
 		case assertionTypeResourcesRead:
 			res.ResourcesRead = got
 		case assertionTypeResourcesNotRead:
@@ -231,9 +341,23 @@ func (a *assertionEvaluator) Evaluate(history *mcpproxy.CallHistory) *CompositeA
 			res.PromptsNotUsed = got
 		case assertionTypeCallOrder:
 			res.CallOrder = got
+		case assertionTypePhases:
+			res.Phases = got
 		case assertionTypeNoDuplicateCalls:
 			res.NoDuplicateCalls = got
+		case assertionTypeMaxAgentDuration:
+			res.MaxAgentDuration = got
+		case assertionTypeMaxContextTokens:
+			res.MaxContextTokens = got
+		case assertionTypeGroundedOutput:
+			res.GroundedOutput = got
+		case assertionTypeExpr:
+			res.Expr = got
 		default:
+			if res.Custom == nil {
+				res.Custom = make(map[string]*SingleAssertionResult)
+			}
+			res.Custom[eval.Type()] = got
 		}
 	}
 
@@ -261,12 +385,11 @@ func (e *toolsUsedEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAsse
 		}
 
 		if !found {
-			return &SingleAssertionResult{
-				Passed: false,
-				Reason: fmt.Sprintf("Required tool not called: server=%s, tool=%s, pattern=%s",
-					assertion.Server, assertion.Tool, assertion.ToolPattern,
-				),
-			}
+			return failure(ReasonToolNotCalled, map[string]string{
+				"server":  assertion.Server,
+				"tool":    assertion.Tool,
+				"pattern": assertion.ToolPattern,
+			})
 		}
 	}
 
@@ -299,10 +422,7 @@ func (e *requireAnyEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAss
 		}
 
 	}
-	return &SingleAssertionResult{
-		Passed: false,
-		Reason: "None of the required tools were called",
-	}
+	return failure(ReasonNoRequiredToolCalled, nil)
 
 }
 
@@ -324,12 +444,10 @@ func (e *toolsNotUsedEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleA
 	for _, assertion := range e.assertions {
 		for _, call := range history.ToolCalls {
 			if matchesToolAssertion(call, assertion) {
-				return &SingleAssertionResult{
-					Passed: false,
-					Details: []string{fmt.Sprintf("Forbidden tool was called: server=%s, tool=%s",
-						call.ServerName, call.ToolName),
-					},
-				}
+				return failure(ReasonForbiddenToolCalled, map[string]string{
+					"server": call.ServerName,
+					"tool":   call.ToolName,
+				})
 			}
 		}
 
@@ -355,11 +473,10 @@ func NewMinToolCallsEvaluator(min int) SingleAssertionEvaluator {
 func (e *minToolCallsEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
 	actual := len(history.ToolCalls)
 	if actual < e.min {
-		return &SingleAssertionResult{
-			Passed: false,
-			Reason: fmt.Sprintf("Too few tool calls: expected >= %d, got %d",
-				e.min, actual),
-		}
+		return failure(ReasonTooFewToolCalls, map[string]string{
+			"expected": strconv.Itoa(e.min),
+			"actual":   strconv.Itoa(actual),
+		})
 	}
 
 	return &SingleAssertionResult{Passed: true}
@@ -382,11 +499,10 @@ func NewMaxToolCallsEvaluator(max int) SingleAssertionEvaluator {
 func (e *maxToolCallsEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
 	actual := len(history.ToolCalls)
 	if actual > e.max {
-		return &SingleAssertionResult{
-			Passed: false,
-			Reason: fmt.Sprintf("Too many tool calls: expected <= %d, got %d",
-				e.max, actual),
-		}
+		return failure(ReasonTooManyToolCalls, map[string]string{
+			"expected": strconv.Itoa(e.max),
+			"actual":   strconv.Itoa(actual),
+		})
 	}
 
 	return &SingleAssertionResult{Passed: true}
@@ -396,6 +512,50 @@ func (e *maxToolCallsEvaluator) Type() string {
 	return assertionTypeMaxToolCalls
 }
 
+type toolCallCountsEvaluator struct {
+	assertions []ToolCallCountAssertion
+}
+
+func NewToolCallCountsEvaluator(assertions []ToolCallCountAssertion) SingleAssertionEvaluator {
+	return &toolCallCountsEvaluator{
+		assertions: assertions,
+	}
+}
+
+func (e *toolCallCountsEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
+	var failures []*SingleAssertionResult
+	for _, assertion := range e.assertions {
+		actual := 0
+		for _, call := range history.ToolCalls {
+			if matchesToolAssertion(call, assertion.ToolAssertion) {
+				actual++
+			}
+		}
+
+		params := func(expected int) map[string]string {
+			return map[string]string{
+				"server":   assertion.Server,
+				"tool":     assertion.Tool,
+				"pattern":  assertion.ToolPattern,
+				"expected": strconv.Itoa(expected),
+				"actual":   strconv.Itoa(actual),
+			}
+		}
+		if assertion.Min != nil && actual < *assertion.Min {
+			failures = append(failures, failure(ReasonTooFewCallsOfTool, params(*assertion.Min)))
+		}
+		if assertion.Max != nil && actual > *assertion.Max {
+			failures = append(failures, failure(ReasonTooManyCallsOfTool, params(*assertion.Max)))
+		}
+	}
+
+	return combineFailures(failures, ReasonToolCallCounts)
+}
+
+func (e *toolCallCountsEvaluator) Type() string {
+	return assertionTypeToolCallCounts
+}
+
 type resourcesReadEvaluator struct {
 	assertions []ResourceAssertion
 }
@@ -417,12 +577,11 @@ func (e *resourcesReadEvaluator) Evaluate(history *mcpproxy.CallHistory) *Single
 		}
 
 		if !found {
-			return &SingleAssertionResult{
-				Passed: false,
-				Reason: fmt.Sprintf("Required resource not read: server=%s, uri=%s, pattern=%s",
-					assertion.Server, assertion.URI, assertion.URIPattern,
-				),
-			}
+			return failure(ReasonResourceNotRead, map[string]string{
+				"server":  assertion.Server,
+				"uri":     assertion.URI,
+				"pattern": assertion.URIPattern,
+			}).withParam("variant", ", ", assertion.variant())
 		}
 	}
 
@@ -447,12 +606,10 @@ func (e *resourcesNotReadEvaluator) Evaluate(history *mcpproxy.CallHistory) *Sin
 	for _, assertion := range e.assertions {
 		for _, call := range history.ResourceReads {
 			if matchesResourceAssertion(call, assertion) {
-				return &SingleAssertionResult{
-					Passed: false,
-					Reason: fmt.Sprintf("Forbidden resource read: server=%s, uri=%s",
-						assertion.Server, call.URI,
-					),
-				}
+				return failure(ReasonForbiddenResourceRead, map[string]string{
+					"server": assertion.Server,
+					"uri":    call.URI,
+				})
 			}
 		}
 	}
@@ -485,12 +642,11 @@ func (e *promptsUsedEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAs
 		}
 
 		if !found {
-			return &SingleAssertionResult{
-				Passed: false,
-				Reason: fmt.Sprintf("Required prompt not used: server=%s, prompt=%s, pattern=%s",
-					assertion.Server, assertion.Prompt, assertion.PromptPattern,
-				),
-			}
+			return failure(ReasonPromptNotUsed, map[string]string{
+				"server":  assertion.Server,
+				"prompt":  assertion.Prompt,
+				"pattern": assertion.PromptPattern,
+			}).withParam("variant", ", ", assertion.variant())
 		}
 	}
 
@@ -515,12 +671,10 @@ func (e *promptsNotUsedEvaluator) Evaluate(history *mcpproxy.CallHistory) *Singl
 	for _, assertion := range e.assertions {
 		for _, call := range history.PromptGets {
 			if matchesPromptAssertion(call, assertion) {
-				return &SingleAssertionResult{
-					Passed: false,
-					Reason: fmt.Sprintf("Forbidden prompt used: server=%s, prompt=%s",
-						assertion.Server, call.Name,
-					),
-				}
+				return failure(ReasonForbiddenPromptUsed, map[string]string{
+					"server": assertion.Server,
+					"prompt": call.Name,
+				})
 			}
 		}
 	}
@@ -532,28 +686,25 @@ func (e *promptsNotUsedEvaluator) Type() string {
 	return assertionTypePromptsNotUsed
 }
 
-type callOrderEvaluator struct {
-	callOrder []CallOrderAssertion
+// orderedCall is a tool call, resource read, or prompt get of the call
+// history, as compared by the order assertions
+type orderedCall struct {
+	timestamp time.Time
+	callType  string
+	server    string
+	name      string
 }
 
-func NewCallOrderEvaluator(callOrder []CallOrderAssertion) SingleAssertionEvaluator {
-	return &callOrderEvaluator{
-		callOrder: callOrder,
-	}
+func (c orderedCall) matches(assertion CallOrderAssertion) bool {
+	return c.callType == assertion.Type && c.server == assertion.Server && c.name == assertion.Name
 }
 
-func (e *callOrderEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
-	type indexedCall struct {
-		timestamp time.Time
-		callType  string
-		server    string
-		name      string
-	}
-
-	allCalls := make([]indexedCall, 0, len(history.PromptGets)+len(history.ResourceReads)+len(history.ToolCalls))
+// chronologicalCalls returns all calls of the history, oldest first
+func chronologicalCalls(history *mcpproxy.CallHistory) []orderedCall {
+	allCalls := make([]orderedCall, 0, len(history.PromptGets)+len(history.ResourceReads)+len(history.ToolCalls))
 
 	for _, tc := range history.ToolCalls {
-		allCalls = append(allCalls, indexedCall{
+		allCalls = append(allCalls, orderedCall{
 			timestamp: tc.Timestamp,
 			callType:  "tool",
 			server:    tc.ServerName,
@@ -562,7 +713,7 @@ func (e *callOrderEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAsse
 	}
 
 	for _, rr := range history.ResourceReads {
-		allCalls = append(allCalls, indexedCall{
+		allCalls = append(allCalls, orderedCall{
 			timestamp: rr.Timestamp,
 			callType:  "resource",
 			server:    rr.ServerName,
@@ -571,7 +722,7 @@ func (e *callOrderEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAsse
 	}
 
 	for _, pg := range history.PromptGets {
-		allCalls = append(allCalls, indexedCall{
+		allCalls = append(allCalls, orderedCall{
 			timestamp: pg.Timestamp,
 			callType:  "prompt",
 			server:    pg.ServerName,
@@ -584,13 +735,27 @@ func (e *callOrderEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAsse
 		return allCalls[i].timestamp.Before(allCalls[j].timestamp)
 	})
 
+	return allCalls
+}
+
+type callOrderEvaluator struct {
+	callOrder []CallOrderAssertion
+}
+
+func NewCallOrderEvaluator(callOrder []CallOrderAssertion) SingleAssertionEvaluator {
+	return &callOrderEvaluator{
+		callOrder: callOrder,
+	}
+}
+
+func (e *callOrderEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
+	allCalls := chronologicalCalls(history)
+
 	assertionIdx := 0
 	for _, call := range allCalls {
 		expectedCall := e.callOrder[assertionIdx]
 
-		if call.callType == expectedCall.Type &&
-			call.server == expectedCall.Server &&
-			call.name == expectedCall.Name {
+		if call.matches(expectedCall) {
 			assertionIdx++
 			if assertionIdx >= len(e.callOrder) {
 				// Found all calls in order
@@ -599,46 +764,225 @@ func (e *callOrderEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAsse
 		}
 	}
 
-	return &SingleAssertionResult{
-		Passed: false,
-		Reason: fmt.Sprintf("Expected call order not satisfied. Got to %d/%d",
-			assertionIdx, len(e.callOrder)),
-	}
+	return failure(ReasonCallOrder, map[string]string{
+		"reached":  strconv.Itoa(assertionIdx),
+		"expected": strconv.Itoa(len(e.callOrder)),
+	})
 }
 
 func (e *callOrderEvaluator) Type() string {
 	return assertionTypeCallOrder
 }
 
-type noDuplicateCallsEvaluator struct{}
+type phasesEvaluator struct {
+	phases []PhaseAssertion
+}
 
-func NewNoDuplicateCallsEvaluator() SingleAssertionEvaluator {
-	return &noDuplicateCallsEvaluator{}
+// NewPhasesEvaluator creates an evaluator that checks that the calls of each
+// phase are made, and that no call of a phase is made after a call of a later
+// phase. A call listed in several phases belongs to the first of them.
+func NewPhasesEvaluator(phases []PhaseAssertion) SingleAssertionEvaluator {
+	return &phasesEvaluator{
+		phases: phases,
+	}
+}
+
+func (e *phasesEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
+	var failures []*SingleAssertionResult
+
+	// made[i][j] is whether call j of phase i was made
+	made := make([][]bool, len(e.phases))
+	for i, phase := range e.phases {
+		made[i] = make([]bool, len(phase.Calls))
+	}
+
+	latest := -1
+	for _, call := range chronologicalCalls(history) {
+		phase := -1
+		for i := range e.phases {
+			for j, expected := range e.phases[i].Calls {
+				if call.matches(expected) {
+					made[i][j] = true
+					if phase == -1 {
+						phase = i
+					}
+				}
+			}
+		}
+
+		if phase == -1 {
+			continue
+		}
+		if phase < latest {
+			failures = append(failures, failure(ReasonPhaseCallOutOfOrder, map[string]string{
+				"type":       call.callType,
+				"server":     call.server,
+				"name":       call.name,
+				"phase":      e.phaseName(phase),
+				"laterPhase": e.phaseName(latest),
+			}))
+			continue
+		}
+		latest = phase
+	}
+
+	for i, phase := range e.phases {
+		for j, expected := range phase.Calls {
+			if !made[i][j] {
+				failures = append(failures, failure(ReasonPhaseCallNotMade, map[string]string{
+					"type":   expected.Type,
+					"server": expected.Server,
+					"name":   expected.Name,
+					"phase":  e.phaseName(i),
+				}))
+			}
+		}
+	}
+
+	return combineFailures(failures, ReasonPhaseViolations)
+}
+
+// phaseName returns the name of a phase, or its 1-based position if it has
+// no name
+func (e *phasesEvaluator) phaseName(i int) string {
+	if name := e.phases[i].Name; name != "" {
+		return fmt.Sprintf("%q", name)
+	}
+	return fmt.Sprintf("%d", i+1)
+}
+
+// combineFailures returns the result of an assertion with several checks: a
+// pass without failures, the failure itself if there is one, or a failure
+// with code and the count of failures, and their reasons as details
+func combineFailures(failures []*SingleAssertionResult, code ReasonCode) *SingleAssertionResult {
+	switch len(failures) {
+	case 0:
+		return &SingleAssertionResult{Passed: true}
+	case 1:
+		return failures[0]
+	default:
+		details := make([]string, len(failures))
+		for i, f := range failures {
+			details[i] = f.Reason
+		}
+		return failure(code, map[string]string{"count": strconv.Itoa(len(failures))}, details...)
+	}
+}
+
+func (e *phasesEvaluator) Type() string {
+	return assertionTypePhases
+}
+
+type noDuplicateCallsEvaluator struct {
+	byArgs bool
+	// within is zero when all earlier calls count
+	within time.Duration
+	ignore map[string]bool
+}
+
+// NewNoDuplicateCallsEvaluator creates an evaluator that fails when a tool is
+// called more than once. A nil config uses the defaults.
+func NewNoDuplicateCallsEvaluator(config *NoDuplicateCallsAssertion) SingleAssertionEvaluator {
+	e := &noDuplicateCallsEvaluator{
+		byArgs: true,
+		ignore: make(map[string]bool),
+	}
+	if config == nil {
+		return e
+	}
+
+	e.byArgs = config.Scope != DuplicateScopeTool
+	// Validated when the eval config is read
+	e.within, _ = time.ParseDuration(config.Within)
+	for _, tool := range config.IgnoreTools {
+		e.ignore[tool] = true
+	}
+
+	return e
 }
 
 func (e *noDuplicateCallsEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
-	seen := make(map[string]struct{})
+	// last is when each call was last made
+	last := make(map[string]time.Time)
+	var duplicates []string
 
 	for _, call := range history.ToolCalls {
-		key := fmt.Sprintf("%s:%s:%v", call.ServerName, call.ToolName, call.Request.Params.Arguments)
+		if e.ignore[call.ToolName] {
+			continue
+		}
 
-		if _, ok := seen[key]; ok {
-			return &SingleAssertionResult{
-				Passed: false,
-				Reason: fmt.Sprintf("Duplicate call detected: %s.%s", call.ServerName, call.ToolName),
-			}
+		key := call.ServerName + ":" + call.ToolName
+		if e.byArgs {
+			key += ":" + canonicalArguments(call)
+		}
+
+		if prev, ok := last[key]; ok && (e.within == 0 || call.Timestamp.Sub(prev) <= e.within) {
+			duplicates = append(duplicates, fmt.Sprintf("%s.%s", call.ServerName, call.ToolName))
 		}
 
-		seen[key] = struct{}{}
+		last[key] = call.Timestamp
+	}
+
+	if len(duplicates) > 0 {
+		return failure(ReasonDuplicateCall, map[string]string{"call": duplicates[0]}, duplicates...)
 	}
 
 	return &SingleAssertionResult{Passed: true}
 }
 
+// canonicalArguments returns the arguments of a tool call as JSON with sorted
+// keys, so that calls with the same arguments in another order are equal
+func canonicalArguments(call *mcpproxy.ToolCall) string {
+	if call.Request == nil || call.Request.Params == nil {
+		return ""
+	}
+
+	raw := call.Request.Params.Arguments
+	var args any
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return string(raw)
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return string(raw)
+	}
+	return string(data)
+}
+
 func (e *noDuplicateCallsEvaluator) Type() string {
 	return assertionTypeNoDuplicateCalls
 }
 
+// maxAgentDurationEvaluator checks timing rather than call history, so the
+// agent's duration is passed in when it is created
+type maxAgentDurationEvaluator struct {
+	max    time.Duration
+	actual time.Duration
+}
+
+func NewMaxAgentDurationEvaluator(max, actual time.Duration) SingleAssertionEvaluator {
+	return &maxAgentDurationEvaluator{
+		max:    max,
+		actual: actual,
+	}
+}
+
+func (e *maxAgentDurationEvaluator) Evaluate(_ *mcpproxy.CallHistory) *SingleAssertionResult {
+	if e.actual > e.max {
+		return failure(ReasonAgentTooSlow, map[string]string{
+			"expected": e.max.String(),
+			"actual":   e.actual.String(),
+		})
+	}
+
+	return &SingleAssertionResult{Passed: true}
+}
+
+func (e *maxAgentDurationEvaluator) Type() string {
+	return assertionTypeMaxAgentDuration
+}
+
 func matchesToolAssertion(call *mcpproxy.ToolCall, assertion ToolAssertion) bool {
 	if call == nil {
 		return false
@@ -674,6 +1018,14 @@ func matchesResourceAssertion(call *mcpproxy.ResourceRead, assertion ResourceAss
 		return false
 	}
 
+	if assertion.Template != "" && call.Template != assertion.Template {
+		return false
+	}
+
+	if !matchesValues(call.TemplateParams, assertion.TemplateParams) {
+		return false
+	}
+
 	// if no URI or pattern specified, match any resource from this server
 	if assertion.URI == "" && assertion.URIPattern == "" {
 		return true
@@ -700,6 +1052,16 @@ func matchesPromptAssertion(call *mcpproxy.PromptGet, assertion PromptAssertion)
 		return false
 	}
 
+	if len(assertion.Arguments) > 0 {
+		var args map[string]string
+		if call.Request != nil && call.Request.Params != nil {
+			args = call.Request.Params.Arguments
+		}
+		if !matchesValues(args, assertion.Arguments) {
+			return false
+		}
+	}
+
 	// if no prompt or pattern specified, match any prompt from this server
 	if assertion.Prompt == "" && assertion.PromptPattern == "" {
 		return true
@@ -716,3 +1078,46 @@ func matchesPromptAssertion(call *mcpproxy.PromptGet, assertion PromptAssertion)
 
 	return false
 }
+
+// matchesValues returns whether actual has all the expected values. Other
+// keys of actual may have any value.
+func matchesValues(actual, expected map[string]string) bool {
+	for k, v := range expected {
+		if got, ok := actual[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// formatValues formats values as k=v pairs sorted by key
+func formatValues(values map[string]string) string {
+	pairs := make([]string, 0, len(values))
+	for k, v := range values {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// variant describes the template and template params a resource must be
+// read with, or is empty if they are not set
+func (a ResourceAssertion) variant() string {
+	var parts []string
+	if a.Template != "" {
+		parts = append(parts, "template="+a.Template)
+	}
+	if len(a.TemplateParams) > 0 {
+		parts = append(parts, "templateParams={"+formatValues(a.TemplateParams)+"}")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// variant describes the arguments a prompt must be requested with, or is
+// empty if they are not set
+func (a PromptAssertion) variant() string {
+	if len(a.Arguments) == 0 {
+		return ""
+	}
+	return "arguments={" + formatValues(a.Arguments) + "}"
+}
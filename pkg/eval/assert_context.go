@@ -0,0 +1,233 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const assertionTypeMaxContextTokens = "maxContextTokens"
+
+// DefaultCharsPerToken is the number of characters counted as one token
+// unless TokenizerConfig sets otherwise
+const DefaultCharsPerToken = 4.0
+
+// DefaultTokenizerTimeout is how long the command of a tokenizer may run for
+// one tool result unless TokenizerConfig.Timeout is set
+const DefaultTokenizerTimeout = 30 * time.Second
+
+// TokenizerConfig sets how the tokens of tool results are estimated. Without
+// a command, tokens are counted as characters divided by CharsPerToken.
+type TokenizerConfig struct {
+	// CharsPerToken defaults to DefaultCharsPerToken
+	CharsPerToken float64 `json:"charsPerToken,omitempty"`
+
+	// Command is run with the shell with the text on stdin for each tool
+	// result and prints its number of tokens, to count them with the
+	// tokenizer of the model
+	Command string `json:"command,omitempty"`
+
+	// Timeout is how long the command may run for one tool result, as a
+	// duration. Defaults to DefaultTokenizerTimeout.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+func (c *TokenizerConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.CharsPerToken < 0 {
+		return fmt.Errorf("charsPerToken must not be negative")
+	}
+	if c.Command != "" && c.CharsPerToken != 0 {
+		return fmt.Errorf("charsPerToken and command cannot both be set")
+	}
+	if c.Timeout != "" {
+		if c.Command == "" {
+			return fmt.Errorf("timeout requires command")
+		}
+		if _, err := time.ParseDuration(c.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// timeout returns the timeout of the command
+func (c *TokenizerConfig) timeout() time.Duration {
+	// Validated when the eval config is read
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultTokenizerTimeout
+}
+
+// name describes the tokenizer in the results
+func (c *TokenizerConfig) name() string {
+	if c != nil && c.Command != "" {
+		return "command"
+	}
+	return fmt.Sprintf("%g chars per token", c.charsPerToken())
+}
+
+func (c *TokenizerConfig) charsPerToken() float64 {
+	if c == nil || c.CharsPerToken == 0 {
+		return DefaultCharsPerToken
+	}
+	return c.CharsPerToken
+}
+
+// countTokens estimates the tokens of text
+func (c *TokenizerConfig) countTokens(ctx context.Context, text string) (int, error) {
+	if text == "" {
+		return 0, nil
+	}
+	if c == nil || c.Command == "" {
+		return int(math.Ceil(float64(utf8.RuneCountInString(text)) / c.charsPerToken())), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	cmd := shell.Default().Command(ctx, c.Command)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("tokenizer command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	tokens, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, fmt.Errorf("tokenizer command printed %q instead of a number of tokens", strings.TrimSpace(string(out)))
+	}
+	return tokens, nil
+}
+
+// ContextUsage estimates how much the tool results of a task added to the
+// context window of the agent
+type ContextUsage struct {
+	Tokenizer   string `json:"tokenizer"`
+	TotalTokens int    `json:"totalTokens"`
+	// Growth is the total after each tool call, in the order of the calls
+	Growth []int `json:"growth"`
+	// LargestTool is the server/tool whose result was the largest, with
+	// LargestTokens tokens
+	LargestTool   string `json:"largestTool,omitempty"`
+	LargestTokens int    `json:"largestTokens,omitempty"`
+	// Error is set if the tokens could not be counted, in which case the
+	// counts only cover the calls before the failure
+	Error string `json:"error,omitempty"`
+}
+
+// estimateContextUsage counts the tokens of the tool results of a history,
+// and adds them to the usage of their tools
+func estimateContextUsage(ctx context.Context, cfg *TokenizerConfig, history *mcpproxy.CallHistory, toolUsage []mcpproxy.ToolUsage) *ContextUsage {
+	if history == nil || len(history.ToolCalls) == 0 {
+		return nil
+	}
+
+	usage := &ContextUsage{
+		Tokenizer: cfg.name(),
+		Growth:    make([]int, 0, len(history.ToolCalls)),
+	}
+	for _, call := range history.ToolCalls {
+		tokens, err := cfg.countTokens(ctx, toolResultText(call.Result))
+		if err != nil {
+			usage.Error = err.Error()
+			break
+		}
+
+		usage.TotalTokens += tokens
+		usage.Growth = append(usage.Growth, usage.TotalTokens)
+		if tokens > usage.LargestTokens {
+			usage.LargestTool = call.ServerName + "/" + call.ToolName
+			usage.LargestTokens = tokens
+		}
+		for i := range toolUsage {
+			if toolUsage[i].Server == call.ServerName && toolUsage[i].Tool == call.ToolName {
+				toolUsage[i].Tokens += tokens
+			}
+		}
+	}
+	return usage
+}
+
+// toolResultText returns the text a tool result adds to the context: the
+// text of its content, and the JSON of content that is not text. Structured
+// content is only counted if there is no other content.
+func toolResultText(res *mcp.CallToolResult) string {
+	if res == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, content := range res.Content {
+		switch c := content.(type) {
+		case *mcp.TextContent:
+			parts = append(parts, c.Text)
+			continue
+		case *mcp.EmbeddedResource:
+			if c.Resource != nil && c.Resource.Text != "" {
+				parts = append(parts, c.Resource.Text)
+				continue
+			}
+		}
+		if data, err := json.Marshal(content); err == nil {
+			parts = append(parts, string(data))
+		}
+	}
+	if len(parts) == 0 && res.StructuredContent != nil {
+		if data, err := json.Marshal(res.StructuredContent); err == nil {
+			parts = append(parts, string(data))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// maxContextTokensEvaluator checks the estimated context usage rather than
+// call history, so the usage is passed in when it is created
+type maxContextTokensEvaluator struct {
+	max   int
+	usage *ContextUsage
+}
+
+func NewMaxContextTokensEvaluator(max int, usage *ContextUsage) SingleAssertionEvaluator {
+	return &maxContextTokensEvaluator{
+		max:   max,
+		usage: usage,
+	}
+}
+
+func (e *maxContextTokensEvaluator) Evaluate(_ *mcpproxy.CallHistory) *SingleAssertionResult {
+	// No tool calls add no tokens
+	if e.usage == nil {
+		return &SingleAssertionResult{Passed: true}
+	}
+	if e.usage.Error != "" {
+		return failure(ReasonContextTokensUnknown, map[string]string{"error": e.usage.Error})
+	}
+	if e.usage.TotalTokens > e.max {
+		return failure(ReasonTooManyContextTokens, map[string]string{
+			"expected": strconv.Itoa(e.max),
+			"actual":   strconv.Itoa(e.usage.TotalTokens),
+		}, fmt.Sprintf("largest result: %s, about %d tokens", e.usage.LargestTool, e.usage.LargestTokens))
+	}
+
+	return &SingleAssertionResult{Passed: true}
+}
+
+func (e *maxContextTokensEvaluator) Type() string {
+	return assertionTypeMaxContextTokens
+}
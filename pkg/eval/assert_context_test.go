@@ -0,0 +1,218 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func textToolCall(server, tool, text string) *mcpproxy.ToolCall {
+	return &mcpproxy.ToolCall{
+		CallRecord: mcpproxy.CallRecord{ServerName: server},
+		ToolName:   tool,
+		Result:     &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}},
+	}
+}
+
+func TestTokenizerCountTokens(t *testing.T) {
+	tests := map[string]struct {
+		config    *TokenizerConfig
+		text      string
+		expected  int
+		expectErr string
+	}{
+		"default chars per token rounds up": {
+			text:     "12345",
+			expected: 2,
+		},
+		"counts characters, not bytes": {
+			text:     "ééééé",
+			expected: 2,
+		},
+		"custom chars per token": {
+			config:   &TokenizerConfig{CharsPerToken: 2.5},
+			text:     "12345",
+			expected: 2,
+		},
+		"empty text": {
+			text:     "",
+			expected: 0,
+		},
+		"command": {
+			config:   &TokenizerConfig{Command: "wc -c"},
+			text:     "12345",
+			expected: 5,
+		},
+		"command printing something else": {
+			config:    &TokenizerConfig{Command: "echo many"},
+			text:      "12345",
+			expectErr: `tokenizer command printed "many"`,
+		},
+		"failing command": {
+			config:    &TokenizerConfig{Command: "echo broken >&2; exit 1"},
+			text:      "12345",
+			expectErr: "tokenizer command failed: exit status 1: broken",
+		},
+		"command timing out": {
+			config:    &TokenizerConfig{Command: "sleep 5", Timeout: "100ms"},
+			text:      "12345",
+			expectErr: "tokenizer command failed",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokens, err := tc.config.countTokens(context.Background(), tc.text)
+			if tc.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tokens)
+		})
+	}
+}
+
+func TestTokenizerValidate(t *testing.T) {
+	assert.NoError(t, (*TokenizerConfig)(nil).validate())
+	assert.NoError(t, (&TokenizerConfig{CharsPerToken: 3}).validate())
+	assert.NoError(t, (&TokenizerConfig{Command: "wc -c"}).validate())
+	assert.Error(t, (&TokenizerConfig{CharsPerToken: -1}).validate())
+	assert.Error(t, (&TokenizerConfig{CharsPerToken: 3, Command: "wc -c"}).validate())
+	assert.NoError(t, (&TokenizerConfig{Command: "wc -c", Timeout: "5s"}).validate())
+	assert.Error(t, (&TokenizerConfig{Command: "wc -c", Timeout: "soon"}).validate())
+	assert.Error(t, (&TokenizerConfig{Timeout: "5s"}).validate())
+}
+
+func TestToolResultText(t *testing.T) {
+	tests := map[string]struct {
+		result   *mcp.CallToolResult
+		expected string
+	}{
+		"nil result": {
+			expected: "",
+		},
+		"text content": {
+			result:   &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "a"}, &mcp.TextContent{Text: "b"}}},
+			expected: "a\nb",
+		},
+		"embedded resource text": {
+			result: &mcp.CallToolResult{Content: []mcp.Content{&mcp.EmbeddedResource{
+				Resource: &mcp.ResourceContents{URI: "file:///a", Text: "resource text"},
+			}}},
+			expected: "resource text",
+		},
+		"structured content without other content": {
+			result:   &mcp.CallToolResult{StructuredContent: map[string]any{"pods": 3}},
+			expected: `{"pods":3}`,
+		},
+		"structured content is not counted twice": {
+			result: &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: `{"pods":3}`}},
+				StructuredContent: map[string]any{"pods": 3},
+			},
+			expected: `{"pods":3}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, toolResultText(tc.result))
+		})
+	}
+}
+
+func TestEstimateContextUsage(t *testing.T) {
+	history := &mcpproxy.CallHistory{
+		ToolCalls: []*mcpproxy.ToolCall{
+			textToolCall("kubernetes", "pods_list", "12345678"),
+			textToolCall("kubernetes", "pods_get", "1234"),
+			textToolCall("kubernetes", "pods_list", "123456789012"),
+		},
+	}
+	toolUsage := []mcpproxy.ToolUsage{
+		{Server: "kubernetes", Tool: "pods_list", Calls: 2},
+		{Server: "kubernetes", Tool: "pods_get", Calls: 1},
+	}
+
+	usage := estimateContextUsage(context.Background(), nil, history, toolUsage)
+	require.NotNil(t, usage)
+	assert.Equal(t, "4 chars per token", usage.Tokenizer)
+	assert.Equal(t, 6, usage.TotalTokens)
+	assert.Equal(t, []int{2, 3, 6}, usage.Growth)
+	assert.Equal(t, "kubernetes/pods_list", usage.LargestTool)
+	assert.Equal(t, 3, usage.LargestTokens)
+	assert.Empty(t, usage.Error)
+	assert.Equal(t, 5, toolUsage[0].Tokens)
+	assert.Equal(t, 1, toolUsage[1].Tokens)
+
+	assert.Nil(t, estimateContextUsage(context.Background(), nil, &mcpproxy.CallHistory{}, nil))
+}
+
+func TestEstimateContextUsageCommandError(t *testing.T) {
+	history := &mcpproxy.CallHistory{
+		ToolCalls: []*mcpproxy.ToolCall{textToolCall("kubernetes", "pods_list", "12345678")},
+	}
+
+	usage := estimateContextUsage(context.Background(), &TokenizerConfig{Command: "exit 1"}, history, nil)
+	require.NotNil(t, usage)
+	assert.Equal(t, "command", usage.Tokenizer)
+	assert.Contains(t, usage.Error, "tokenizer command failed")
+	assert.Empty(t, usage.Growth)
+}
+
+func TestMaxContextTokensEvaluator(t *testing.T) {
+	usage := &ContextUsage{TotalTokens: 1200, LargestTool: "kubernetes/pods_list", LargestTokens: 1000}
+
+	tests := map[string]struct {
+		max      int
+		usage    *ContextUsage
+		expected bool
+		reason   string
+	}{
+		"under the limit": {
+			max:      2000,
+			usage:    usage,
+			expected: true,
+		},
+		"at the limit": {
+			max:      1200,
+			usage:    usage,
+			expected: true,
+		},
+		"over the limit": {
+			max:      1000,
+			usage:    usage,
+			expected: false,
+			reason:   "Tool results added too many tokens to the context: expected <= 1000, got about 1200",
+		},
+		"no tool calls": {
+			max:      1,
+			expected: true,
+		},
+		"tokens could not be counted": {
+			max:      2000,
+			usage:    &ContextUsage{Error: "tokenizer command failed"},
+			expected: false,
+			reason:   "Failed to estimate context tokens: tokenizer command failed",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := NewMaxContextTokensEvaluator(tc.max, tc.usage)
+			assert.Equal(t, assertionTypeMaxContextTokens, evaluator.Type())
+
+			result := evaluator.Evaluate(nil)
+			assert.Equal(t, tc.expected, result.Passed)
+			if tc.reason != "" {
+				assert.Equal(t, tc.reason, result.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
+	extprotocol "github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+)
+
+// AssertionParser creates the evaluator of a custom assertion from its config
+// in the eval file. The evaluator's Type must return the name the assertion is
+// registered under.
+type AssertionParser func(config json.RawMessage) (SingleAssertionEvaluator, error)
+
+// AssertionRegistry maps the names of custom assertions to their parsers.
+type AssertionRegistry struct {
+	mu      sync.RWMutex
+	parsers map[string]AssertionParser
+}
+
+// DefaultAssertionRegistry holds the custom assertions available in eval files
+var DefaultAssertionRegistry = NewAssertionRegistry()
+
+// NewAssertionRegistry creates an empty AssertionRegistry
+func NewAssertionRegistry() *AssertionRegistry {
+	return &AssertionRegistry{parsers: make(map[string]AssertionParser)}
+}
+
+// RegisterAssertion registers a custom assertion in DefaultAssertionRegistry,
+// typically from an init function.
+func RegisterAssertion(name string, parser AssertionParser) error {
+	return DefaultAssertionRegistry.Register(name, parser)
+}
+
+// Register registers the parser of a custom assertion. Names cannot contain
+// dots, which are reserved for assertions run by extensions.
+func (r *AssertionRegistry) Register(name string, parser AssertionParser) error {
+	if name == "" {
+		return fmt.Errorf("assertion name cannot be empty")
+	}
+	if strings.Contains(name, ".") {
+		return fmt.Errorf("assertion name %q cannot contain dots", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.parsers[name]; exists {
+		return fmt.Errorf("a parser already exists for assertion '%s'", name)
+	}
+
+	r.parsers[name] = parser
+
+	return nil
+}
+
+// Names returns the registered assertion names in alphabetical order
+func (r *AssertionRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(r.parsers))
+}
+
+// Parse creates the evaluator of a custom assertion. Names of the form
+// <extension>.<assertion> are evaluated by an extension of the eval, which
+// must be available from the extension manager in ctx.
+func (r *AssertionRegistry) Parse(ctx context.Context, name string, config json.RawMessage) (SingleAssertionEvaluator, error) {
+	if alias, assertion, ok := strings.Cut(name, "."); ok {
+		return parseExtensionAssertion(ctx, name, alias, assertion, config)
+	}
+
+	r.mu.RLock()
+	parser, ok := r.parsers[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown custom assertion '%s'", name)
+	}
+
+	evaluator, err := parser(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse custom assertion '%s': %w", name, err)
+	}
+
+	return evaluator, nil
+}
+
+// parseCustomAssertions creates the evaluators of the custom assertions of a
+// task set, in alphabetical order
+func (r *AssertionRegistry) parseCustomAssertions(ctx context.Context, custom map[string]json.RawMessage) ([]SingleAssertionEvaluator, error) {
+	evaluators := make([]SingleAssertionEvaluator, 0, len(custom))
+	for _, name := range slices.Sorted(maps.Keys(custom)) {
+		evaluator, err := r.Parse(ctx, name, custom[name])
+		if err != nil {
+			return nil, err
+		}
+		evaluators = append(evaluators, evaluator)
+	}
+
+	return evaluators, nil
+}
+
+// extensionAssertion evaluates a custom assertion with the "assert" method of
+// an extension, which is given the call history and the agent's prompt and
+// output
+type extensionAssertion struct {
+	// ctx is the context of the eval run, which holds the extension manager
+	ctx       context.Context
+	name      string
+	alias     string
+	assertion string
+	args      map[string]any
+	agent     *extprotocol.AgentContext
+}
+
+func parseExtensionAssertion(ctx context.Context, name, alias, assertion string, config json.RawMessage) (SingleAssertionEvaluator, error) {
+	manager, ok := client.ManagerFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("failed to get extension manager from context")
+	}
+
+	ext, err := manager.Get(ctx, alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extension %q for custom assertion '%s': %w", alias, name, err)
+	}
+
+	op, ok := ext.Manifest().Assertions[assertion]
+	if !ok {
+		return nil, fmt.Errorf("assertion %q not declared in extension %q", assertion, alias)
+	}
+
+	params, err := op.GetParams()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get params for assertion %s: %w", name, err)
+	}
+
+	var args map[string]any
+	if len(config) > 0 && string(config) != "null" {
+		if err := json.Unmarshal(config, &args); err != nil {
+			return nil, fmt.Errorf("failed to parse args of custom assertion '%s': %w", name, err)
+		}
+	}
+
+	if err := params.Validate(args); err != nil {
+		return nil, fmt.Errorf("provided args did not match params for assertion %s: %w", name, err)
+	}
+
+	return &extensionAssertion{
+		ctx:       ctx,
+		name:      name,
+		alias:     alias,
+		assertion: assertion,
+		args:      args,
+	}, nil
+}
+
+// withAgent returns a copy of the assertion that sends the prompt and output
+// of the agent of a task
+func (e *extensionAssertion) withAgent(prompt, output string) SingleAssertionEvaluator {
+	bound := *e
+	bound.agent = &extprotocol.AgentContext{Prompt: prompt, Output: output}
+	return &bound
+}
+
+func (e *extensionAssertion) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
+	fail := func(err error) *SingleAssertionResult {
+		return failure(ReasonCustomAssertionError, map[string]string{"error": err.Error()})
+	}
+
+	manager, ok := client.ManagerFromContext(e.ctx)
+	if !ok {
+		return fail(fmt.Errorf("failed to get extension manager from context"))
+	}
+
+	ext, err := manager.Get(e.ctx, e.alias)
+	if err != nil {
+		return fail(fmt.Errorf("failed to get extension %q: %w", e.alias, err))
+	}
+
+	res, err := ext.Assert(e.ctx, &extprotocol.AssertParams{
+		Assertion: e.assertion,
+		Args:      e.args,
+		Context: extprotocol.AssertContext{
+			CallHistory: extensionCallHistory(history),
+			Agent:       e.agent,
+		},
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to evaluate %s: %w", e.name, err))
+	}
+
+	if res.Passed {
+		return &SingleAssertionResult{Passed: true, Reason: res.Reason, Details: res.Details}
+	}
+	return customFailure(res)
+}
+
+func (e *extensionAssertion) Type() string {
+	return e.name
+}
+
+// agentAssertion is implemented by custom assertions that also check the
+// prompt and output of the agent
+type agentAssertion interface {
+	withAgent(prompt, output string) SingleAssertionEvaluator
+}
+
+// bindAgent returns the custom assertions of a task, with the ones that check
+// the agent bound to its prompt and output
+func bindAgent(custom []SingleAssertionEvaluator, prompt, output string) []SingleAssertionEvaluator {
+	bound := make([]SingleAssertionEvaluator, len(custom))
+	for i, evaluator := range custom {
+		if a, ok := evaluator.(agentAssertion); ok {
+			evaluator = a.withAgent(prompt, output)
+		}
+		bound[i] = evaluator
+	}
+	return bound
+}
+
+// extensionCallHistory converts the call history to the format of the
+// extension protocol
+func extensionCallHistory(history *mcpproxy.CallHistory) extprotocol.CallHistory {
+	res := extprotocol.CallHistory{
+		ToolCalls:     []extprotocol.ToolCall{},
+		ResourceReads: []extprotocol.ResourceRead{},
+		PromptGets:    []extprotocol.PromptGet{},
+	}
+	if history == nil {
+		return res
+	}
+
+	record := func(r mcpproxy.CallRecord) extprotocol.CallRecord {
+		return extprotocol.CallRecord{
+			Server:    r.ServerName,
+			Timestamp: r.Timestamp,
+			Success:   r.Success,
+			Error:     r.Error,
+		}
+	}
+
+	for _, call := range history.ToolCalls {
+		tc := extprotocol.ToolCall{CallRecord: record(call.CallRecord), Name: call.ToolName}
+		if call.Request != nil && call.Request.Params != nil {
+			tc.Arguments = call.Request.Params.Arguments
+		}
+		if call.Result != nil {
+			// A result that cannot be encoded is left out
+			if data, err := json.Marshal(call.Result); err == nil {
+				tc.Result = data
+			}
+		}
+		res.ToolCalls = append(res.ToolCalls, tc)
+	}
+
+	for _, read := range history.ResourceReads {
+		res.ResourceReads = append(res.ResourceReads, extprotocol.ResourceRead{CallRecord: record(read.CallRecord), URI: read.URI})
+	}
+
+	for _, get := range history.PromptGets {
+		pg := extprotocol.PromptGet{CallRecord: record(get.CallRecord), Name: get.Name}
+		if get.Request != nil && get.Request.Params != nil {
+			pg.Arguments = get.Request.Params.Arguments
+		}
+		res.PromptGets = append(res.PromptGets, pg)
+	}
+
+	return res
+}
+
+// customFailure converts the failure reported by an extension. Extensions
+// without reason codes get customAssertionFailed with their message as param.
+func customFailure(res *extprotocol.AssertResult) *SingleAssertionResult {
+	if res.Code == "" {
+		return failure(ReasonCustomAssertionFailed, map[string]string{"message": res.Reason}, res.Details...)
+	}
+	return &SingleAssertionResult{
+		Passed:  false,
+		Reason:  res.Reason,
+		Code:    ReasonCode(res.Code),
+		Params:  res.Params,
+		Details: res.Details,
+	}
+}
@@ -0,0 +1,226 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
+	extprotocol "github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// distinctToolsEvaluator is a custom assertion that passes when the agent
+// called at least min different tools
+type distinctToolsEvaluator struct {
+	Min int `json:"min"`
+}
+
+func (e *distinctToolsEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
+	tools := map[string]bool{}
+	for _, call := range history.ToolCalls {
+		tools[call.ToolName] = true
+	}
+	if len(tools) < e.Min {
+		return &SingleAssertionResult{
+			Passed: false,
+			Reason: fmt.Sprintf("expected at least %d distinct tools, got %d", e.Min, len(tools)),
+		}
+	}
+	return &SingleAssertionResult{Passed: true}
+}
+
+func (e *distinctToolsEvaluator) Type() string {
+	return "distinctTools"
+}
+
+func parseDistinctTools(config json.RawMessage) (SingleAssertionEvaluator, error) {
+	e := &distinctToolsEvaluator{}
+	if err := json.Unmarshal(config, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func TestAssertionRegistryRegister(t *testing.T) {
+	r := NewAssertionRegistry()
+
+	require.NoError(t, r.Register("distinctTools", parseDistinctTools))
+	require.NoError(t, r.Register("alwaysPass", parseDistinctTools))
+
+	assert.ErrorContains(t, r.Register("distinctTools", parseDistinctTools), "already exists")
+	assert.ErrorContains(t, r.Register("", parseDistinctTools), "cannot be empty")
+	assert.ErrorContains(t, r.Register("ext.op", parseDistinctTools), "cannot contain dots")
+
+	assert.Equal(t, []string{"alwaysPass", "distinctTools"}, r.Names())
+}
+
+func TestAssertionRegistryParse(t *testing.T) {
+	r := NewAssertionRegistry()
+	require.NoError(t, r.Register("distinctTools", parseDistinctTools))
+
+	evaluator, err := r.Parse(context.Background(), "distinctTools", json.RawMessage(`{"min": 2}`))
+	require.NoError(t, err)
+	assert.Equal(t, "distinctTools", evaluator.Type())
+
+	_, err = r.Parse(context.Background(), "maxToolCalls", nil)
+	assert.ErrorContains(t, err, "unknown custom assertion 'maxToolCalls'")
+
+	_, err = r.Parse(context.Background(), "distinctTools", json.RawMessage(`{"min": "two"}`))
+	assert.ErrorContains(t, err, "failed to parse custom assertion 'distinctTools'")
+
+	_, err = r.Parse(context.Background(), "ext.check", nil)
+	assert.ErrorContains(t, err, "extension manager")
+}
+
+func TestCompositeAssertionEvaluatorCustom(t *testing.T) {
+	minToolCalls := 1
+	custom := &distinctToolsEvaluator{Min: 2}
+	evaluator := NewCompositeAssertionEvaluator(&TaskAssertions{MinToolCalls: &minToolCalls}, custom)
+
+	history := &mcpproxy.CallHistory{ToolCalls: []*mcpproxy.ToolCall{{ToolName: "pods_list"}}}
+	res := evaluator.Evaluate(history)
+
+	require.Contains(t, res.Custom, "distinctTools")
+	assert.False(t, res.Custom["distinctTools"].Passed)
+	assert.Equal(t, "expected at least 2 distinct tools, got 1", res.Custom["distinctTools"].Reason)
+	assert.Equal(t, 2, res.TotalAssertions())
+	assert.Equal(t, 1, res.PassedAssertions())
+	assert.False(t, res.Succeeded())
+
+	history.ToolCalls = append(history.ToolCalls, &mcpproxy.ToolCall{ToolName: "pods_get"})
+	res = evaluator.Evaluate(history)
+	assert.True(t, res.Succeeded())
+}
+
+func TestExtensionAssertion(t *testing.T) {
+	ext := &fakeExtension{
+		manifest: &extprotocol.InitializeResult{
+			Assertions: map[string]*extprotocol.Operation{
+				"check": {Params: jsonschema.Schema{
+					Type:     "object",
+					Required: []string{"tool"},
+					Properties: map[string]*jsonschema.Schema{
+						"tool": {Type: "string"},
+					},
+				}},
+			},
+		},
+		result: &extprotocol.AssertResult{Passed: false, Reason: "tool was not called", Details: []string{"called pods_get"}},
+	}
+	ctx := client.ManagerToContext(context.Background(), &fakeManager{clients: map[string]client.Client{"kube": ext}})
+
+	r := NewAssertionRegistry()
+
+	_, err := r.Parse(ctx, "kube.check", json.RawMessage(`{}`))
+	assert.ErrorContains(t, err, "did not match params")
+
+	_, err = r.Parse(ctx, "kube.missing", nil)
+	assert.ErrorContains(t, err, `assertion "missing" not declared`)
+
+	_, err = r.Parse(ctx, "other.check", nil)
+	assert.ErrorContains(t, err, `failed to get extension "other"`)
+
+	evaluator, err := r.Parse(ctx, "kube.check", json.RawMessage(`{"tool": "pods_list"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "kube.check", evaluator.Type())
+
+	history := &mcpproxy.CallHistory{ToolCalls: []*mcpproxy.ToolCall{{
+		CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes", Success: true},
+		ToolName:   "pods_get",
+		Request: &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
+			Name:      "pods_get",
+			Arguments: json.RawMessage(`{"name":"nginx"}`),
+		}},
+	}}}
+	res := bindAgent([]SingleAssertionEvaluator{evaluator}, "Get the nginx pod", "Done")[0].Evaluate(history)
+	assert.False(t, res.Passed)
+	assert.Equal(t, "tool was not called", res.Reason)
+	assert.Equal(t, []string{"called pods_get"}, res.Details)
+
+	require.NotNil(t, ext.params)
+	assert.Equal(t, "check", ext.params.Assertion)
+	assert.Equal(t, map[string]any{"tool": "pods_list"}, ext.params.Args)
+	assert.Equal(t, &extprotocol.AgentContext{Prompt: "Get the nginx pod", Output: "Done"}, ext.params.Context.Agent)
+
+	sent := ext.params.Context.CallHistory
+	require.Len(t, sent.ToolCalls, 1)
+	assert.Equal(t, "kubernetes", sent.ToolCalls[0].Server)
+	assert.Equal(t, "pods_get", sent.ToolCalls[0].Name)
+	assert.True(t, sent.ToolCalls[0].Success)
+	assert.JSONEq(t, `{"name":"nginx"}`, string(sent.ToolCalls[0].Arguments))
+	assert.Empty(t, sent.ResourceReads)
+
+	// The evaluator parsed for the task set is not bound to a task's agent
+	evaluator.Evaluate(history)
+	assert.Nil(t, ext.params.Context.Agent)
+
+	ext.result = nil
+	ext.err = fmt.Errorf("extension crashed")
+	res = evaluator.Evaluate(history)
+	assert.False(t, res.Passed)
+	assert.Contains(t, res.Reason, "extension crashed")
+}
+
+type fakeManager struct {
+	clients map[string]client.Client
+}
+
+func (m *fakeManager) Register(alias string, spec *extension.ExtensionSpec) error {
+	return nil
+}
+
+func (m *fakeManager) Get(ctx context.Context, alias string) (client.Client, error) {
+	c, ok := m.clients[alias]
+	if !ok {
+		return nil, fmt.Errorf("extension %q not registered", alias)
+	}
+	return c, nil
+}
+
+func (m *fakeManager) Has(alias string) bool {
+	_, ok := m.clients[alias]
+	return ok
+}
+
+func (m *fakeManager) ShutdownAll(ctx context.Context) error {
+	return nil
+}
+
+func (m *fakeManager) EnvironmentInfo() map[string]map[string]string {
+	return nil
+}
+
+type fakeExtension struct {
+	manifest *extprotocol.InitializeResult
+	result   *extprotocol.AssertResult
+	err      error
+	params   *extprotocol.AssertParams
+}
+
+func (e *fakeExtension) Start(ctx context.Context, params *extprotocol.InitializeParams) error {
+	return nil
+}
+
+func (e *fakeExtension) Execute(ctx context.Context, params *extprotocol.ExecuteParams) (*extprotocol.ExecuteResult, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (e *fakeExtension) Assert(ctx context.Context, params *extprotocol.AssertParams) (*extprotocol.AssertResult, error) {
+	e.params = params
+	return e.result, e.err
+}
+
+func (e *fakeExtension) Manifest() *extprotocol.InitializeResult {
+	return e.manifest
+}
+
+func (e *fakeExtension) Shutdown(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,132 @@
+package eval
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+)
+
+// OutputExpectation is a check of the agent output that applies to every task
+// of the eval, such as that no answer contains a raw secret or a stack trace.
+// Expectations are reported by name in the expectations of the assertion
+// results, separately from the assertions of the task sets.
+type OutputExpectation struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// MustMatch are regular expressions that must all match the output
+	MustMatch []string `json:"mustMatch,omitempty"`
+
+	// MustNotMatch are regular expressions none of which may match the output
+	MustNotMatch []string `json:"mustNotMatch,omitempty"`
+}
+
+func (e *OutputExpectation) validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(e.MustMatch) == 0 && len(e.MustNotMatch) == 0 {
+		return fmt.Errorf("mustMatch or mustNotMatch is required")
+	}
+	for i, pattern := range e.MustMatch {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid mustMatch[%d]: %w", i, err)
+		}
+	}
+	for i, pattern := range e.MustNotMatch {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid mustNotMatch[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateOutputExpectations checks the expectations of an eval, whose names
+// must be unique
+func validateOutputExpectations(expectations []OutputExpectation) error {
+	seen := make(map[string]bool, len(expectations))
+	for i := range expectations {
+		if err := expectations[i].validate(); err != nil {
+			return fmt.Errorf("invalid outputExpectations[%d]: %w", i, err)
+		}
+		if seen[expectations[i].Name] {
+			return fmt.Errorf("invalid outputExpectations[%d]: duplicate name %s", i, expectations[i].Name)
+		}
+		seen[expectations[i].Name] = true
+	}
+	return nil
+}
+
+// outputExpectationEvaluator checks the agent output, so the output is passed
+// in when it is created
+type outputExpectationEvaluator struct {
+	expectation  OutputExpectation
+	mustMatch    []*regexp.Regexp
+	mustNotMatch []*regexp.Regexp
+	output       string
+}
+
+// NewOutputExpectationEvaluator creates an evaluator that fails when the
+// output does not match all patterns of mustMatch, or matches any of
+// mustNotMatch. The matched text is not reported, since it may be a secret.
+func NewOutputExpectationEvaluator(expectation OutputExpectation, output string) SingleAssertionEvaluator {
+	e := &outputExpectationEvaluator{
+		expectation: expectation,
+		output:      output,
+	}
+
+	// Validated when the eval config is read
+	for _, pattern := range expectation.MustMatch {
+		if re, err := regexp.Compile(pattern); err == nil {
+			e.mustMatch = append(e.mustMatch, re)
+		}
+	}
+	for _, pattern := range expectation.MustNotMatch {
+		if re, err := regexp.Compile(pattern); err == nil {
+			e.mustNotMatch = append(e.mustNotMatch, re)
+		}
+	}
+
+	return e
+}
+
+func (e *outputExpectationEvaluator) Evaluate(_ *mcpproxy.CallHistory) *SingleAssertionResult {
+	var details []string
+	for _, re := range e.mustMatch {
+		if !re.MatchString(e.output) {
+			details = append(details, fmt.Sprintf("Output does not match %s", re))
+		}
+	}
+	for _, re := range e.mustNotMatch {
+		if loc := re.FindStringIndex(e.output); loc != nil {
+			details = append(details, fmt.Sprintf("Output matches %s at offset %d", re, loc[0]))
+		}
+	}
+
+	if len(details) == 0 {
+		return &SingleAssertionResult{Passed: true}
+	}
+
+	return failure(ReasonExpectationNotMet, map[string]string{"expectation": e.expectation.Name}, details...).
+		withParam("description", ": ", e.expectation.Description)
+}
+
+func (e *outputExpectationEvaluator) Type() string {
+	return e.expectation.Name
+}
+
+// evaluateOutputExpectations checks the output of a task against the
+// expectations of the eval, and returns the results by name
+func evaluateOutputExpectations(expectations []OutputExpectation, output string) map[string]*SingleAssertionResult {
+	if len(expectations) == 0 {
+		return nil
+	}
+
+	results := make(map[string]*SingleAssertionResult, len(expectations))
+	for _, expectation := range expectations {
+		evaluator := NewOutputExpectationEvaluator(expectation, output)
+		results[evaluator.Type()] = evaluator.Evaluate(nil)
+	}
+	return results
+}
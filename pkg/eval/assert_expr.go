@@ -0,0 +1,202 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// exprEnv is the CEL environment expr assertions are compiled in. The history
+// variable holds the call history of the task and the result variable the
+// outcome of the agent and the verify steps.
+var exprEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("history", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("result", cel.MapType(cel.StringType, cel.DynType)),
+	)
+})
+
+// compileExpr compiles an expr assertion, which must evaluate to a bool
+func compileExpr(expr string) (cel.Program, error) {
+	env, err := exprEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expression environment: %w", err)
+	}
+
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+
+	if t := ast.OutputType(); !t.IsExactType(cel.BoolType) && !t.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %s", t)
+	}
+
+	return env.Program(ast)
+}
+
+// exprEvaluator checks a CEL expression against the call history and the
+// result of the task, so the result is passed in when it is created
+type exprEvaluator struct {
+	expr   string
+	prompt string
+	result *EvalResult
+}
+
+// NewExprEvaluator creates an evaluator for a CEL expression over the call
+// history and the result of a task. The expression is given the variables:
+//
+//	history.toolCalls:     list of {server, tool, arguments, success, error, output, timestamp}
+//	history.resourceReads: list of {server, uri, template, templateParams, success, error, timestamp}
+//	history.promptGets:    list of {server, prompt, arguments, success, error, timestamp}
+//	history.disruptions:   list of {server, trigger, calls, restarted, error, timestamp}
+//	result:                {prompt, output, passed, error}
+func NewExprEvaluator(expr, prompt string, result *EvalResult) SingleAssertionEvaluator {
+	return &exprEvaluator{
+		expr:   expr,
+		prompt: prompt,
+		result: result,
+	}
+}
+
+func (e *exprEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
+	program, err := compileExpr(e.expr)
+	if err != nil {
+		return failure(ReasonInvalidExpression, map[string]string{"error": err.Error()})
+	}
+
+	out, _, err := program.Eval(map[string]any{
+		"history": exprHistory(history),
+		"result":  exprResult(e.prompt, e.result),
+	})
+	if err != nil {
+		return failure(ReasonExpressionError, map[string]string{"error": err.Error()}, e.expr)
+	}
+
+	passed, ok := out.Value().(bool)
+	if !ok {
+		return failure(ReasonExpressionNotBool, map[string]string{"value": fmt.Sprint(out.Value())}, e.expr)
+	}
+
+	if !passed {
+		return failure(ReasonExpressionFalse, nil, e.expr)
+	}
+
+	return &SingleAssertionResult{Passed: true}
+}
+
+func (e *exprEvaluator) Type() string {
+	return assertionTypeExpr
+}
+
+// exprHistory converts the call history to the view expressions are given
+func exprHistory(history *mcpproxy.CallHistory) map[string]any {
+	toolCalls := []any{}
+	resourceReads := []any{}
+	promptGets := []any{}
+	disruptions := []any{}
+
+	if history != nil {
+		for _, call := range history.ToolCalls {
+			view := exprRecord(call.CallRecord)
+			view["tool"] = call.ToolName
+			view["arguments"] = map[string]any{}
+			if call.Request != nil && call.Request.Params != nil && len(call.Request.Params.Arguments) > 0 {
+				var args any
+				if err := json.Unmarshal(call.Request.Params.Arguments, &args); err == nil {
+					view["arguments"] = args
+				}
+			}
+			view["output"] = toolCallOutput(call.Result)
+			toolCalls = append(toolCalls, view)
+		}
+
+		for _, read := range history.ResourceReads {
+			view := exprRecord(read.CallRecord)
+			view["uri"] = read.URI
+			view["template"] = read.Template
+			params := map[string]any{}
+			for k, v := range read.TemplateParams {
+				params[k] = v
+			}
+			view["templateParams"] = params
+			resourceReads = append(resourceReads, view)
+		}
+
+		for _, get := range history.PromptGets {
+			view := exprRecord(get.CallRecord)
+			view["prompt"] = get.Name
+			args := map[string]any{}
+			if get.Request != nil && get.Request.Params != nil {
+				for k, v := range get.Request.Params.Arguments {
+					args[k] = v
+				}
+			}
+			view["arguments"] = args
+			promptGets = append(promptGets, view)
+		}
+
+		for _, d := range history.Disruptions {
+			disruptions = append(disruptions, map[string]any{
+				"server":    d.ServerName,
+				"trigger":   d.Trigger,
+				"calls":     d.Calls,
+				"restarted": d.Restarted,
+				"error":     d.Error,
+				"timestamp": d.Timestamp,
+			})
+		}
+	}
+
+	return map[string]any{
+		"toolCalls":     toolCalls,
+		"resourceReads": resourceReads,
+		"promptGets":    promptGets,
+		"disruptions":   disruptions,
+	}
+}
+
+func exprRecord(r mcpproxy.CallRecord) map[string]any {
+	return map[string]any{
+		"server":    r.ServerName,
+		"success":   r.Success,
+		"error":     r.Error,
+		"timestamp": r.Timestamp,
+	}
+}
+
+// toolCallOutput returns the text content of a tool call result
+func toolCallOutput(result *mcp.CallToolResult) string {
+	if result == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// exprResult converts the result of a task to the view expressions are given
+func exprResult(prompt string, result *EvalResult) map[string]any {
+	view := map[string]any{
+		"prompt": prompt,
+		"output": "",
+		"passed": false,
+		"error":  "",
+	}
+	if result != nil {
+		view["output"] = fullAgentOutput(result)
+		view["passed"] = result.TaskPassed
+		view["error"] = result.TaskError
+	}
+	return view
+}
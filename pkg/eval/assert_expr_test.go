@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExprEvaluator(t *testing.T) {
+	history := &mcpproxy.CallHistory{
+		ToolCalls: []*mcpproxy.ToolCall{
+			{
+				CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes", Success: true},
+				ToolName:   "pods_list",
+				Request: &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
+					Name:      "pods_list",
+					Arguments: json.RawMessage(`{"namespace":"default"}`),
+				}},
+				Result: &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "nginx Running"}}},
+			},
+			{
+				CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes", Success: false, Error: "forbidden"},
+				ToolName:   "kubectl_delete",
+			},
+		},
+		ResourceReads: []*mcpproxy.ResourceRead{
+			{CallRecord: mcpproxy.CallRecord{ServerName: "filesystem", Success: true}, URI: "file:///data/config.json"},
+		},
+		Disruptions: []*mcpproxy.Disruption{
+			{ServerName: "kubernetes", Trigger: mcpproxy.ChaosTriggerCalls, Calls: 1, Restarted: true},
+		},
+	}
+	result := &EvalResult{TaskPassed: true, TaskOutput: "The nginx pod is running"}
+
+	tests := map[string]struct {
+		expr   string
+		passed bool
+		reason string
+	}{
+		"no destructive calls": {
+			expr:   `history.toolCalls.filter(c, c.tool == 'kubectl_delete').size() == 0`,
+			passed: false,
+			reason: "Expression evaluated to false",
+		},
+		"arguments": {
+			expr:   `history.toolCalls.exists(c, c.tool == 'pods_list' && c.arguments.namespace == 'default')`,
+			passed: true,
+		},
+		"disruptions": {
+			expr:   `history.disruptions.exists(d, d.server == 'kubernetes' && d.restarted) && history.toolCalls.size() > history.disruptions[0].calls`,
+			passed: true,
+		},
+		"tool output": {
+			expr:   `history.toolCalls[0].output.contains('Running')`,
+			passed: true,
+		},
+		"failed calls": {
+			expr:   `history.toolCalls.filter(c, !c.success).map(c, c.error) == ['forbidden']`,
+			passed: true,
+		},
+		"resources and result": {
+			expr:   `history.resourceReads.all(r, r.uri.startsWith('file:///data/')) && result.passed && result.output.matches('nginx .* running')`,
+			passed: true,
+		},
+		"unknown field": {
+			expr:   `history.toolCalls[0].name == 'pods_list'`,
+			passed: false,
+			reason: "Failed to evaluate expression",
+		},
+		"not a bool": {
+			expr:   `history.toolCalls.size()`,
+			passed: false,
+			reason: "Invalid expression",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := NewExprEvaluator(tc.expr, "Check the nginx pod", result).Evaluate(history)
+			assert.Equal(t, tc.passed, res.Passed, res.Reason)
+			if !tc.passed {
+				assert.Contains(t, res.Reason, tc.reason)
+			}
+		})
+	}
+}
+
+func TestExprEvaluatorEmptyHistory(t *testing.T) {
+	res := NewExprEvaluator(`history.toolCalls.size() == 0 && result.prompt == 'hi'`, "hi", nil).Evaluate(nil)
+	assert.True(t, res.Passed, res.Reason)
+}
+
+func TestReadRejectsInvalidExpr(t *testing.T) {
+	for name, expr := range map[string]string{
+		"syntax error": `history.toolCalls.size( == 0`,
+		"unknown var":  `calls.size() == 0`,
+		"not a bool":   `'yes'`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := []byte(`kind: Eval
+metadata:
+  name: test
+config:
+  taskSets:
+    - path: task.yaml
+      assertions:
+        expr: "` + expr + `"
+`)
+
+			_, err := Read(data, t.TempDir())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid expr in task set at index 0")
+		})
+	}
+}
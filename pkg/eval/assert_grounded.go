@@ -0,0 +1,180 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+)
+
+const assertionTypeGroundedOutput = "groundedOutput"
+
+// DefaultClaimPatterns match the names and IDs the groundedOutput assertion
+// checks by default
+var DefaultClaimPatterns = []string{
+	// Names joined with hyphens or underscores, like nginx-web or
+	// kube-system, and IDs such as UUIDs
+	`\b[A-Za-z0-9]+(?:[-_][A-Za-z0-9]+)+\b`,
+	// IPv4 addresses
+	`\b\d{1,3}(?:\.\d{1,3}){3}\b`,
+}
+
+// groundedOutputEvaluator checks the agent output against the call history,
+// so the prompt and output are passed in when it is created
+type groundedOutputEvaluator struct {
+	patterns      []*regexp.Regexp
+	ignore        map[string]bool
+	maxUngrounded int
+	prompt        string
+	output        string
+}
+
+// NewGroundedOutputEvaluator creates an evaluator that fails when the output
+// mentions claims that are in none of the results or names of the calls of the
+// history, nor in the prompt. A nil config uses the defaults.
+func NewGroundedOutputEvaluator(config *GroundedOutputAssertion, prompt, output string) SingleAssertionEvaluator {
+	e := &groundedOutputEvaluator{
+		ignore: make(map[string]bool),
+		prompt: prompt,
+		output: output,
+	}
+
+	patterns := DefaultClaimPatterns
+	if config != nil {
+		if len(config.Patterns) > 0 {
+			patterns = config.Patterns
+		}
+		for _, claim := range config.Ignore {
+			e.ignore[claim] = true
+		}
+		e.maxUngrounded = config.MaxUngrounded
+	}
+
+	for _, pattern := range patterns {
+		// Validated when the eval config is read
+		if re, err := regexp.Compile(pattern); err == nil {
+			e.patterns = append(e.patterns, re)
+		}
+	}
+
+	return e
+}
+
+func (e *groundedOutputEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
+	sources := strings.Join(append(historyOutputs(history), e.prompt), "\n")
+
+	var ungrounded []string
+	for _, claim := range e.claims() {
+		if !strings.Contains(sources, claim) {
+			ungrounded = append(ungrounded, claim)
+		}
+	}
+
+	if len(ungrounded) <= e.maxUngrounded {
+		return &SingleAssertionResult{Passed: true}
+	}
+
+	details := make([]string, len(ungrounded))
+	for i, claim := range ungrounded {
+		details[i] = fmt.Sprintf("%q is in no tool result, resource, or prompt", claim)
+	}
+
+	if len(ungrounded) > 1 {
+		return failure(ReasonUngroundedClaims, map[string]string{
+			"count":  strconv.Itoa(len(ungrounded)),
+			"claims": strings.Join(ungrounded, ", "),
+		}, details...)
+	}
+	return failure(ReasonUngroundedClaim, map[string]string{"claim": ungrounded[0]}, details...)
+}
+
+// claims returns the claims matched in the output that are not ignored, each
+// once, in the order they are first mentioned
+func (e *groundedOutputEvaluator) claims() []string {
+	var claims []string
+	for _, re := range e.patterns {
+		for _, match := range re.FindAllStringSubmatch(e.output, -1) {
+			claim := match[0]
+			if len(match) > 1 {
+				claim = match[1]
+			}
+			if claim == "" || e.ignore[claim] || slices.Contains(claims, claim) {
+				continue
+			}
+			claims = append(claims, claim)
+		}
+	}
+	return claims
+}
+
+func (e *groundedOutputEvaluator) Type() string {
+	return assertionTypeGroundedOutput
+}
+
+// historyOutputs returns the names of the tools, resources, and prompts of
+// the history, which agents often mention in their output, and the strings
+// of their results, including the values of structured content
+func historyOutputs(history *mcpproxy.CallHistory) []string {
+	if history == nil {
+		return nil
+	}
+
+	var outputs []string
+	var results []any
+	for _, call := range history.ToolCalls {
+		outputs = append(outputs, call.ToolName)
+		if call.Result != nil {
+			results = append(results, call.Result)
+		}
+	}
+	for _, read := range history.ResourceReads {
+		outputs = append(outputs, read.URI)
+		if read.Result != nil {
+			results = append(results, read.Result)
+		}
+	}
+	for _, get := range history.PromptGets {
+		outputs = append(outputs, get.Name)
+		if get.Result != nil {
+			results = append(results, get.Result)
+		}
+	}
+
+	for _, result := range results {
+		data, err := json.Marshal(result)
+		if err != nil {
+			continue
+		}
+		var decoded any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			continue
+		}
+		outputs = appendStrings(outputs, decoded)
+	}
+	return outputs
+}
+
+// appendStrings appends the strings and numbers of a decoded JSON value,
+// map keys included
+func appendStrings(out []string, v any) []string {
+	switch v := v.(type) {
+	case string:
+		out = append(out, v)
+	case float64:
+		out = append(out, fmt.Sprint(v))
+	case []any:
+		for _, item := range v {
+			out = appendStrings(out, item)
+		}
+	case map[string]any:
+		for k, item := range v {
+			out = append(out, k)
+			out = appendStrings(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package eval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroundedOutputEvaluator(t *testing.T) {
+	history := &mcpproxy.CallHistory{
+		ToolCalls: []*mcpproxy.ToolCall{{
+			CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes"},
+			ToolName:   "pods_list",
+			Result: &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "NAME READY\nnginx-web-7f9c 1/1"}},
+				StructuredContent: map[string]any{"pods": []any{map[string]any{"name": "redis-cache", "ip": "10.244.0.12"}}},
+			},
+		}},
+		ResourceReads: []*mcpproxy.ResourceRead{{
+			CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes"},
+			URI:        "k8s://namespaces",
+			Result: &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+				{URI: "k8s://namespaces", Text: "default kube-system"},
+			}},
+		}},
+	}
+	prompt := "List the pods in the team-a namespace"
+
+	tests := map[string]struct {
+		config             *GroundedOutputAssertion
+		output             string
+		expected           bool
+		reason             string
+		expectedUngrounded []string
+	}{
+		"claims from tool names and results, resources, and prompt": {
+			output:   "pods_list found team-a pods nginx-web-7f9c and redis-cache (10.244.0.12). Namespaces: kube-system.",
+			expected: true,
+		},
+		"made up name": {
+			output:             "Pods: nginx-web-7f9c and postgres-db.",
+			expected:           false,
+			reason:             "Ungrounded claim in output: postgres-db",
+			expectedUngrounded: []string{"postgres-db"},
+		},
+		"made up name and address": {
+			output:             "postgres-db runs at 10.244.0.99, postgres-db is ready",
+			expected:           false,
+			reason:             "2 ungrounded claims in output: postgres-db, 10.244.0.99",
+			expectedUngrounded: []string{"postgres-db", "10.244.0.99"},
+		},
+		"ignored claim": {
+			config:   &GroundedOutputAssertion{Ignore: []string{"read-only"}},
+			output:   "nginx-web-7f9c mounts a read-only volume",
+			expected: true,
+		},
+		"tolerated claims": {
+			config:   &GroundedOutputAssertion{MaxUngrounded: 1},
+			output:   "nginx-web-7f9c is a read-only pod",
+			expected: true,
+		},
+		"pattern with group": {
+			config:             &GroundedOutputAssertion{Patterns: []string{"`([^`]+)`"}},
+			output:             "The pods are `redis-cache` and `mysql`, in a read-only namespace",
+			expected:           false,
+			reason:             "Ungrounded claim in output: mysql",
+			expectedUngrounded: []string{"mysql"},
+		},
+		"no claims": {
+			output:   "There are two pods.",
+			expected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := NewGroundedOutputEvaluator(tc.config, prompt, tc.output).Evaluate(history)
+			assert.Equal(t, tc.expected, res.Passed)
+			assert.Equal(t, tc.reason, res.Reason)
+			require.Len(t, res.Details, len(tc.expectedUngrounded))
+			for i, claim := range tc.expectedUngrounded {
+				assert.Contains(t, res.Details[i], `"`+claim+`"`)
+			}
+		})
+	}
+}
+
+func TestGroundedOutputAssertionJSON(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		enabled bool
+		config  GroundedOutputAssertion
+	}{
+		"true":    {data: `true`, enabled: true},
+		"false":   {data: `false`},
+		"options": {data: `{"patterns":["[a-z]+-[0-9]+"],"ignore":["read-only"],"maxUngrounded":2}`, enabled: true, config: GroundedOutputAssertion{Patterns: []string{"[a-z]+-[0-9]+"}, Ignore: []string{"read-only"}, MaxUngrounded: 2}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := &TaskAssertions{}
+			require.NoError(t, json.Unmarshal([]byte(`{"groundedOutput":`+tc.data+`}`), a))
+			assert.Equal(t, tc.enabled, a.GroundedOutput.Enabled())
+			assert.Equal(t, tc.config.Patterns, a.GroundedOutput.Patterns)
+			assert.Equal(t, tc.config.Ignore, a.GroundedOutput.Ignore)
+			assert.Equal(t, tc.config.MaxUngrounded, a.GroundedOutput.MaxUngrounded)
+
+			data, err := json.Marshal(a)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"groundedOutput":`+tc.data+`}`, string(data))
+		})
+	}
+
+	assert.False(t, (&TaskAssertions{}).GroundedOutput.Enabled())
+	assert.Error(t, json.Unmarshal([]byte(`{"groundedOutput":"yes"}`), &TaskAssertions{}))
+}
+
+func TestReadRejectsInvalidGroundedOutput(t *testing.T) {
+	for name, config := range map[string]string{
+		"pattern":       `{patterns: ["(unclosed"]}`,
+		"maxUngrounded": `{maxUngrounded: -1}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := []byte(`kind: Eval
+metadata:
+  name: test
+config:
+  taskSets:
+    - path: task.yaml
+      assertions:
+        groundedOutput: ` + config + `
+`)
+
+			_, err := Read(data, t.TempDir())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid groundedOutput in task set at index 0")
+		})
+	}
+}
@@ -0,0 +1,542 @@
+package eval
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxAgentDurationEvaluator(t *testing.T) {
+	tests := map[string]struct {
+		max      time.Duration
+		actual   time.Duration
+		expected bool
+	}{
+		"under the limit": {
+			max:      2 * time.Minute,
+			actual:   90 * time.Second,
+			expected: true,
+		},
+		"exactly the limit": {
+			max:      time.Minute,
+			actual:   time.Minute,
+			expected: true,
+		},
+		"over the limit": {
+			max:      time.Minute,
+			actual:   61 * time.Second,
+			expected: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := NewMaxAgentDurationEvaluator(tc.max, tc.actual).Evaluate(nil)
+			assert.Equal(t, tc.expected, res.Passed)
+			if !tc.expected {
+				assert.Contains(t, res.Reason, "Agent took too long")
+			}
+		})
+	}
+}
+
+func TestCompositeAssertionResultCountsMaxAgentDuration(t *testing.T) {
+	res := &CompositeAssertionResult{
+		ToolsUsed:        &SingleAssertionResult{Passed: true},
+		MaxAgentDuration: &SingleAssertionResult{Passed: false},
+	}
+
+	assert.Equal(t, 2, res.TotalAssertions())
+	assert.Equal(t, 1, res.PassedAssertions())
+	assert.False(t, res.Succeeded())
+}
+
+func TestTaskTimingJSON(t *testing.T) {
+	// Phases that did not run are omitted, except setup which always runs
+	data, err := json.Marshal(&TaskTiming{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"total":"0s","setup":"0s"}`, string(data))
+
+	var parsed TaskTiming
+	require.NoError(t, json.Unmarshal([]byte(`{"total":"1m2.5s","agent":"55s"}`), &parsed))
+	assert.Equal(t, 62500*time.Millisecond, time.Duration(parsed.Total))
+	assert.Equal(t, 55*time.Second, time.Duration(parsed.Agent))
+}
+
+func TestReadRejectsInvalidMaxAgentDuration(t *testing.T) {
+	data := []byte(`kind: Eval
+metadata:
+  name: test
+config:
+  taskSets:
+    - path: task.yaml
+      assertions:
+        maxAgentDuration: soon
+`)
+
+	_, err := Read(data, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maxAgentDuration")
+}
+
+func TestNoDuplicateCallsEvaluator(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	call := func(tool, args string, offset time.Duration) *mcpproxy.ToolCall {
+		return &mcpproxy.ToolCall{
+			CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes", Timestamp: start.Add(offset)},
+			ToolName:   tool,
+			Request: &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
+				Name:      tool,
+				Arguments: json.RawMessage(args),
+			}},
+		}
+	}
+
+	tests := map[string]struct {
+		config   *NoDuplicateCallsAssertion
+		calls    []*mcpproxy.ToolCall
+		expected bool
+	}{
+		"different arguments": {
+			calls:    []*mcpproxy.ToolCall{call("pods_get", `{"name":"a"}`, 0), call("pods_get", `{"name":"b"}`, time.Second)},
+			expected: true,
+		},
+		"same arguments in another order": {
+			calls:    []*mcpproxy.ToolCall{call("pods_get", `{"name":"a","ns":"x"}`, 0), call("pods_get", `{"ns":"x","name":"a"}`, time.Second)},
+			expected: false,
+		},
+		"tool scope": {
+			config:   &NoDuplicateCallsAssertion{Scope: DuplicateScopeTool},
+			calls:    []*mcpproxy.ToolCall{call("pods_get", `{"name":"a"}`, 0), call("pods_get", `{"name":"b"}`, time.Second)},
+			expected: false,
+		},
+		"polling outside the window": {
+			config:   &NoDuplicateCallsAssertion{Within: "30s"},
+			calls:    []*mcpproxy.ToolCall{call("pods_get", `{}`, 0), call("pods_get", `{}`, time.Minute), call("pods_get", `{}`, 2*time.Minute)},
+			expected: true,
+		},
+		"repeated within the window": {
+			config:   &NoDuplicateCallsAssertion{Within: "30s"},
+			calls:    []*mcpproxy.ToolCall{call("pods_get", `{}`, 0), call("pods_get", `{}`, time.Minute), call("pods_get", `{}`, time.Minute+10*time.Second)},
+			expected: false,
+		},
+		"ignored tool": {
+			config:   &NoDuplicateCallsAssertion{IgnoreTools: []string{"rollout_status"}},
+			calls:    []*mcpproxy.ToolCall{call("rollout_status", `{}`, 0), call("rollout_status", `{}`, time.Second)},
+			expected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := NewNoDuplicateCallsEvaluator(tc.config).Evaluate(&mcpproxy.CallHistory{ToolCalls: tc.calls})
+			assert.Equal(t, tc.expected, res.Passed, res.Reason)
+			if !tc.expected {
+				assert.Equal(t, "Duplicate call detected: kubernetes.pods_get", res.Reason)
+			}
+		})
+	}
+}
+
+func TestNoDuplicateCallsAssertionJSON(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		enabled bool
+		config  NoDuplicateCallsAssertion
+	}{
+		"true":    {data: `true`, enabled: true},
+		"false":   {data: `false`, enabled: false},
+		"options": {data: `{"scope":"tool","within":"30s","ignoreTools":["rollout_status"]}`, enabled: true, config: NoDuplicateCallsAssertion{Scope: "tool", Within: "30s", IgnoreTools: []string{"rollout_status"}}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := &TaskAssertions{}
+			require.NoError(t, json.Unmarshal([]byte(`{"noDuplicateCalls":`+tc.data+`}`), a))
+			assert.Equal(t, tc.enabled, a.NoDuplicateCalls.Enabled())
+			assert.Equal(t, tc.config.Scope, a.NoDuplicateCalls.Scope)
+			assert.Equal(t, tc.config.IgnoreTools, a.NoDuplicateCalls.IgnoreTools)
+
+			data, err := json.Marshal(a)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"noDuplicateCalls":`+tc.data+`}`, string(data))
+		})
+	}
+
+	assert.False(t, (&TaskAssertions{}).NoDuplicateCalls.Enabled())
+	assert.Error(t, json.Unmarshal([]byte(`{"noDuplicateCalls":"yes"}`), &TaskAssertions{}))
+}
+
+func TestReadRejectsInvalidNoDuplicateCalls(t *testing.T) {
+	for name, config := range map[string]string{
+		"scope":  `{scope: args}`,
+		"within": `{within: soon}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := []byte(`kind: Eval
+metadata:
+  name: test
+config:
+  taskSets:
+    - path: task.yaml
+      assertions:
+        noDuplicateCalls: ` + config + `
+`)
+
+			_, err := Read(data, t.TempDir())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid noDuplicateCalls in task set at index 0")
+		})
+	}
+}
+
+func TestToolCallCountsEvaluator(t *testing.T) {
+	history := &mcpproxy.CallHistory{ToolCalls: []*mcpproxy.ToolCall{
+		{CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes"}, ToolName: "kubectl_get"},
+		{CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes"}, ToolName: "kubectl_get"},
+		{CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes"}, ToolName: "kubectl_get"},
+		{CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes"}, ToolName: "kubectl_apply"},
+		{CallRecord: mcpproxy.CallRecord{ServerName: "github"}, ToolName: "kubectl_get"},
+	}}
+	count := func(n int) *int { return &n }
+	kubectlGet := ToolAssertion{Server: "kubernetes", Tool: "kubectl_get"}
+
+	tests := map[string]struct {
+		assertions []ToolCallCountAssertion
+		expected   bool
+		reason     string
+		details    int
+	}{
+		"within bounds": {
+			assertions: []ToolCallCountAssertion{{ToolAssertion: kubectlGet, Min: count(1), Max: count(3)}},
+			expected:   true,
+		},
+		"too many": {
+			assertions: []ToolCallCountAssertion{{ToolAssertion: kubectlGet, Max: count(2)}},
+			reason:     "Too many calls of server=kubernetes, tool=kubectl_get, pattern=: expected <= 2, got 3",
+		},
+		"too few": {
+			assertions: []ToolCallCountAssertion{{ToolAssertion: ToolAssertion{Server: "kubernetes", Tool: "kubectl_delete"}, Min: count(1)}},
+			reason:     "Too few calls of server=kubernetes, tool=kubectl_delete, pattern=: expected >= 1, got 0",
+		},
+		"pattern": {
+			assertions: []ToolCallCountAssertion{{ToolAssertion: ToolAssertion{Server: "kubernetes", ToolPattern: "^kubectl_"}, Max: count(4)}},
+			expected:   true,
+		},
+		"several failures": {
+			assertions: []ToolCallCountAssertion{
+				{ToolAssertion: kubectlGet, Max: count(1)},
+				{ToolAssertion: ToolAssertion{Server: "kubernetes", Tool: "kubectl_apply"}, Min: count(2)},
+			},
+			reason:  "2 tool call counts out of bounds",
+			details: 2,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := NewToolCallCountsEvaluator(tc.assertions).Evaluate(history)
+			assert.Equal(t, tc.expected, res.Passed)
+			if !tc.expected {
+				assert.Equal(t, tc.reason, res.Reason)
+				assert.Len(t, res.Details, tc.details)
+			}
+		})
+	}
+}
+
+func TestReadToolCallCounts(t *testing.T) {
+	read := func(counts string) (*EvalSpec, error) {
+		return Read([]byte(`kind: Eval
+metadata:
+  name: test
+config:
+  taskSets:
+    - path: task.yaml
+      assertions:
+        toolCallCounts:
+          - `+counts+`
+`), t.TempDir())
+	}
+
+	spec, err := read(`{server: kubernetes, tool: kubectl_get, max: 3}`)
+	require.NoError(t, err)
+	counts := spec.Config.TaskSets[0].Assertions.ToolCallCounts
+	require.Len(t, counts, 1)
+	assert.Equal(t, "kubectl_get", counts[0].Tool)
+	assert.Nil(t, counts[0].Min)
+	assert.Equal(t, 3, *counts[0].Max)
+
+	for name, counts := range map[string]string{
+		"no bounds":     `{server: kubernetes, tool: kubectl_get}`,
+		"negative":      `{server: kubernetes, tool: kubectl_get, max: -1}`,
+		"min above max": `{server: kubernetes, tool: kubectl_get, min: 3, max: 2}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := read(counts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid toolCallCounts[0] in task set at index 0")
+		})
+	}
+}
+
+func TestResourcesReadEvaluatorTemplate(t *testing.T) {
+	history := &mcpproxy.CallHistory{
+		ResourceReads: []*mcpproxy.ResourceRead{{
+			CallRecord:     mcpproxy.CallRecord{ServerName: "kubernetes", Success: true},
+			URI:            "k8s://namespaces/default/pods/nginx",
+			Template:       "k8s://namespaces/{namespace}/pods/{name}",
+			TemplateParams: map[string]string{"namespace": "default", "name": "nginx"},
+		}},
+	}
+
+	tests := map[string]struct {
+		assertion ResourceAssertion
+		passed    bool
+		reason    string
+	}{
+		"template": {
+			assertion: ResourceAssertion{Server: "kubernetes", Template: "k8s://namespaces/{namespace}/pods/{name}"},
+			passed:    true,
+		},
+		"template params subset": {
+			assertion: ResourceAssertion{Server: "kubernetes", TemplateParams: map[string]string{"namespace": "default"}},
+			passed:    true,
+		},
+		"wrong template param": {
+			assertion: ResourceAssertion{Server: "kubernetes", TemplateParams: map[string]string{"namespace": "kube-system", "name": "nginx"}},
+			passed:    false,
+			reason:    "templateParams={name=nginx, namespace=kube-system}",
+		},
+		"wrong template": {
+			assertion: ResourceAssertion{Server: "kubernetes", Template: "k8s://pods/{name}", URIPattern: "nginx"},
+			passed:    false,
+			reason:    "template=k8s://pods/{name}",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := NewResourcesReadEvaluator([]ResourceAssertion{tc.assertion}).Evaluate(history)
+			assert.Equal(t, tc.passed, res.Passed, res.Reason)
+			assert.Contains(t, res.Reason, tc.reason)
+		})
+	}
+}
+
+func TestPromptsUsedEvaluatorArguments(t *testing.T) {
+	history := &mcpproxy.CallHistory{
+		PromptGets: []*mcpproxy.PromptGet{{
+			CallRecord: mcpproxy.CallRecord{ServerName: "templates", Success: true},
+			Name:       "deployment-template",
+			Request: &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{
+				Name:      "deployment-template",
+				Arguments: map[string]string{"environment": "staging", "replicas": "3"},
+			}},
+		}},
+	}
+
+	res := NewPromptsUsedEvaluator([]PromptAssertion{{
+		Server:    "templates",
+		Prompt:    "deployment-template",
+		Arguments: map[string]string{"environment": "staging"},
+	}}).Evaluate(history)
+	assert.True(t, res.Passed, res.Reason)
+
+	res = NewPromptsUsedEvaluator([]PromptAssertion{{
+		Server:    "templates",
+		Prompt:    "deployment-template",
+		Arguments: map[string]string{"environment": "production"},
+	}}).Evaluate(history)
+	assert.False(t, res.Passed)
+	assert.Equal(t, "Required prompt not used: server=templates, prompt=deployment-template, pattern=, arguments={environment=production}", res.Reason)
+
+	// prompts not used only matches the listed variant
+	res = NewPromptsNotUsedEvaluator([]PromptAssertion{{
+		Server:    "templates",
+		Arguments: map[string]string{"environment": "production"},
+	}}).Evaluate(history)
+	assert.True(t, res.Passed, res.Reason)
+}
+
+func TestPhasesEvaluator(t *testing.T) {
+	start := time.Now()
+	call := func(offset int, tool string) *mcpproxy.ToolCall {
+		return &mcpproxy.ToolCall{
+			CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes", Timestamp: start.Add(time.Duration(offset) * time.Second)},
+			ToolName:   tool,
+		}
+	}
+	phases := []PhaseAssertion{
+		{Name: "read", Calls: []CallOrderAssertion{
+			{Type: "tool", Server: "kubernetes", Name: "pods_list"},
+			{Type: "tool", Server: "kubernetes", Name: "pods_get"},
+		}},
+		{Calls: []CallOrderAssertion{
+			{Type: "tool", Server: "kubernetes", Name: "pods_delete"},
+		}},
+	}
+
+	tests := map[string]struct {
+		calls   []*mcpproxy.ToolCall
+		passed  bool
+		reason  string
+		details []string
+	}{
+		"in order": {
+			calls:  []*mcpproxy.ToolCall{call(0, "pods_get"), call(1, "events_list"), call(2, "pods_list"), call(3, "pods_delete")},
+			passed: true,
+		},
+		"repeated calls within a phase": {
+			calls:  []*mcpproxy.ToolCall{call(0, "pods_list"), call(1, "pods_get"), call(2, "pods_list"), call(3, "pods_delete"), call(4, "pods_delete")},
+			passed: true,
+		},
+		"read after write": {
+			calls:  []*mcpproxy.ToolCall{call(0, "pods_list"), call(1, "pods_delete"), call(2, "pods_get")},
+			reason: `Call tool kubernetes/pods_get of phase "read" made after a call of phase 2`,
+		},
+		"missing and out of order": {
+			calls:  []*mcpproxy.ToolCall{call(0, "pods_delete"), call(1, "pods_list")},
+			reason: "2 phase violations",
+			details: []string{
+				`Call tool kubernetes/pods_list of phase "read" made after a call of phase 2`,
+				`Call tool kubernetes/pods_get of phase "read" not made`,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := NewPhasesEvaluator(phases).Evaluate(&mcpproxy.CallHistory{ToolCalls: tc.calls})
+			assert.Equal(t, tc.passed, res.Passed, res.Reason)
+			assert.Equal(t, tc.reason, res.Reason)
+			assert.Equal(t, tc.details, res.Details)
+		})
+	}
+}
+
+func TestReadRejectsInvalidPhases(t *testing.T) {
+	for name, phases := range map[string]string{
+		"no calls":     `{name: read}`,
+		"unknown type": `{calls: [{type: tools, server: kubernetes, name: pods_list}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read([]byte(`kind: Eval
+metadata:
+  name: test
+config:
+  taskSets:
+    - path: task.yaml
+      assertions:
+        phases:
+          - `+phases+`
+`), t.TempDir())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid phases[0] in task set at index 0")
+		})
+	}
+}
+
+func TestReadMergesDefaultAssertions(t *testing.T) {
+	spec, err := Read([]byte(`kind: Eval
+metadata:
+  name: test
+config:
+  defaultAssertions:
+    noDuplicateCalls: true
+    maxAgentDuration: 2m
+    toolsNotUsed:
+      - server: kubernetes
+        tool: namespaces_delete
+    custom:
+      answered: {}
+      polite: {tone: formal}
+  taskSets:
+    - path: defaults.yaml
+    - path: overrides.yaml
+      assertions:
+        noDuplicateCalls: false
+        maxAgentDuration: 5m
+        minToolCalls: 1
+        custom:
+          answered: null
+          polite: {tone: casual}
+`), t.TempDir())
+	require.NoError(t, err)
+	require.Len(t, spec.Config.TaskSets, 2)
+
+	defaults := spec.Config.TaskSets[0].Assertions
+	require.NotNil(t, defaults)
+	assert.True(t, defaults.NoDuplicateCalls.Enabled())
+	assert.Equal(t, "2m", defaults.MaxAgentDuration)
+	assert.Len(t, defaults.ToolsNotUsed, 1)
+	assert.Nil(t, defaults.MinToolCalls)
+	assert.Len(t, defaults.Custom, 2)
+
+	overrides := spec.Config.TaskSets[1].Assertions
+	require.NotNil(t, overrides)
+	assert.False(t, overrides.NoDuplicateCalls.Enabled())
+	assert.Equal(t, "5m", overrides.MaxAgentDuration)
+	assert.Len(t, overrides.ToolsNotUsed, 1)
+	require.NotNil(t, overrides.MinToolCalls)
+	assert.Equal(t, 1, *overrides.MinToolCalls)
+	assert.Equal(t, map[string]json.RawMessage{"polite": json.RawMessage(`{"tone":"casual"}`)}, overrides.Custom)
+
+	// The defaults themselves are not changed
+	assert.Len(t, spec.Config.DefaultAssertions.Custom, 2)
+	assert.True(t, spec.Config.DefaultAssertions.NoDuplicateCalls.Enabled())
+}
+
+func TestReadRejectsInvalidDefaultAssertions(t *testing.T) {
+	_, err := Read([]byte(`kind: Eval
+metadata:
+  name: test
+config:
+  defaultAssertions:
+    maxAgentDuration: soon
+  taskSets:
+    - path: task.yaml
+`), t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid maxAgentDuration in defaultAssertions")
+}
+
+func TestReadRejectsInvalidContextSettings(t *testing.T) {
+	tests := map[string]struct {
+		config      string
+		errContains string
+	}{
+		"non-positive maxContextTokens": {
+			config: `  defaultAssertions:
+    maxContextTokens: 0
+`,
+			errContains: "invalid maxContextTokens in defaultAssertions: must be positive",
+		},
+		"tokenizer with both ratio and command": {
+			config: `  tokenizer:
+    charsPerToken: 3
+    command: wc -c
+`,
+			errContains: "invalid tokenizer: charsPerToken and command cannot both be set",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read([]byte(`kind: Eval
+metadata:
+  name: test
+config:
+`+tc.config+`  taskSets:
+    - path: task.yaml
+`), t.TempDir())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errContains)
+		})
+	}
+}
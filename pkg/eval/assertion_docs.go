@@ -0,0 +1,44 @@
+package eval
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// AssertionDocsURL is the reference of the assertions. Each assertion has an
+// anchor of its own.
+const AssertionDocsURL = "https://github.com/mcpchecker/mcpchecker/blob/main/docs/assertions.md"
+
+//go:embed assertion_docs.yaml
+var assertionDocsYAML []byte
+
+// AssertionDoc helps fix a failed assertion with a short hint and a link to
+// its documentation
+type AssertionDoc struct {
+	Hint   string `json:"hint"`
+	Anchor string `json:"anchor"`
+}
+
+// URL returns the link to the documentation of the assertion
+func (d AssertionDoc) URL() string {
+	return AssertionDocsURL + "#" + d.Anchor
+}
+
+var assertionDocs = sync.OnceValue(func() map[string]AssertionDoc {
+	docs := make(map[string]AssertionDoc)
+	if err := yaml.UnmarshalStrict(assertionDocsYAML, &docs); err != nil {
+		panic(fmt.Sprintf("invalid assertion_docs.yaml: %v", err))
+	}
+	return docs
+})
+
+// LookupAssertionDoc returns the documentation of an assertion by its name in
+// task sets, such as toolsUsed. Custom assertions are documented as custom and
+// output expectations as expectations.
+func LookupAssertionDoc(name string) (AssertionDoc, bool) {
+	doc, ok := assertionDocs()[name]
+	return doc, ok
+}
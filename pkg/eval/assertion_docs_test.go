@@ -0,0 +1,31 @@
+package eval
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertionDocs(t *testing.T) {
+	reference, err := os.ReadFile("../../docs/assertions.md")
+	require.NoError(t, err)
+
+	typ := reflect.TypeOf(CompositeAssertionResult{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		t.Run(name, func(t *testing.T) {
+			doc, ok := LookupAssertionDoc(name)
+			require.True(t, ok, "assertion %s has no entry in assertion_docs.yaml", name)
+			assert.NotEmpty(t, doc.Hint)
+			assert.Equal(t, AssertionDocsURL+"#"+strings.ToLower(name), doc.URL())
+			assert.Contains(t, string(reference), "\n### "+name+"\n")
+		})
+	}
+
+	_, ok := LookupAssertionDoc("unknown")
+	assert.False(t, ok)
+}
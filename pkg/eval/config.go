@@ -1,14 +1,19 @@
 package eval
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"time"
 
 	"sigs.k8s.io/yaml"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension"
 	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/procmon"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
@@ -23,6 +28,8 @@ type EvalSpec struct {
 
 	// basePath is the directory containing the eval file, used for resolving relative paths
 	basePath string
+	// includes are the paths of the fragments the eval file includes
+	includes []string
 }
 
 // BasePath returns the directory containing the eval file
@@ -30,6 +37,12 @@ func (s *EvalSpec) BasePath() string {
 	return s.basePath
 }
 
+// Includes returns the paths of the fragments included into the eval file,
+// including those included by other fragments
+func (s *EvalSpec) Includes() []string {
+	return s.includes
+}
+
 type EvalMetadata struct {
 	Name string `json:"name"`
 }
@@ -45,6 +58,61 @@ type EvalConfig struct {
 	McpConfigFile string                       `json:"mcpConfigFile"`
 	LLMJudge      *llmjudge.LLMJudgeEvalConfig `json:"llmJudge"`
 
+	// McpConfigFiles are additional MCP config files layered on top of
+	// McpConfigFile in order. Later files take precedence.
+	McpConfigFiles []string `json:"mcpConfigFiles,omitempty"`
+
+	// McpProfile selects a named profile from the MCP config files
+	McpProfile string `json:"mcpProfile,omitempty"`
+
+	// ReuseMcpServers keeps stdio MCP servers running between tasks instead of
+	// restarting them for every task. Call history is still recorded per task,
+	// but any state held by the server itself carries over between tasks.
+	ReuseMcpServers bool `json:"reuseMcpServers,omitempty"`
+
+	// Quarantine lists tasks whose failures are reported separately and do
+	// not count against pass rates. QuarantineFile is merged with it.
+	Quarantine     []QuarantinedTask `json:"quarantine,omitempty"`
+	QuarantineFile string            `json:"quarantineFile,omitempty"`
+
+	// AgentOutput limits how much agent output is kept in the results
+	AgentOutput *AgentOutputConfig `json:"agentOutput,omitempty"`
+
+	// CallHistory limits how much of the MCP call history of a task is kept
+	// in memory while the task runs
+	CallHistory *mcpproxy.CallHistoryLimits `json:"callHistory,omitempty"`
+
+	// McpTaskMetadata injects the task into the requests and environment of
+	// the MCP servers
+	McpTaskMetadata *TaskMetadataConfig `json:"mcpTaskMetadata,omitempty"`
+
+	// OutputExpectations are checks of the agent output that apply to every
+	// task, reported separately from the assertions of the task sets
+	OutputExpectations []OutputExpectation `json:"outputExpectations,omitempty"`
+
+	// SafetyScan scans the agent output and tool call arguments of every task
+	// for secrets, PII, or other strings that must not appear
+	SafetyScan *SafetyScanConfig `json:"safetyScan,omitempty"`
+
+	// CaptureMcpTraffic writes the HTTP traffic between the proxy and http
+	// and websocket MCP servers of each task to a HAR file in the artifact
+	// directory
+	CaptureMcpTraffic bool `json:"captureMcpTraffic,omitempty"`
+
+	// Tokenizer estimates the tokens of the tool results of each task, which
+	// are reported as the context usage of the task and checked by the
+	// maxContextTokens assertion
+	Tokenizer *TokenizerConfig `json:"tokenizer,omitempty"`
+
+	// AgentResources limits the CPU and memory of the agent process of each
+	// task, whose usage is recorded in the results
+	AgentResources *procmon.Config `json:"agentResources,omitempty"`
+
+	// DefaultAssertions are merged into the assertions of every task set.
+	// Assertions set by a task set win, and custom assertions are merged by
+	// name.
+	DefaultAssertions *TaskAssertions `json:"defaultAssertions,omitempty"`
+
 	// Advanced mode: different assertion sets
 	TaskSets []TaskSet `json:"taskSets,omitempty"`
 }
@@ -55,6 +123,8 @@ type AgentRef struct {
 	// Type specifies the agent type:
 	// - "builtin.claude-code" for Claude Code
 	// - "builtin.openai-agent" for OpenAI-compatible agents
+	// - "builtin.anthropic-agent" for the Anthropic Messages API
+	// - "builtin.ollama-agent" for the Ollama chat API
 	// - "file" for custom agent configuration files
 	Type string `json:"type"`
 
@@ -65,6 +135,23 @@ type AgentRef struct {
 	Model string `json:"model,omitempty"`
 }
 
+// inherit returns the agent ref of a task set with the type and path of the
+// eval agent if it has no type of its own
+func (a *AgentRef) inherit(evalAgent *AgentRef) (*AgentRef, error) {
+	if a.Type != "" {
+		return a, nil
+	}
+	if evalAgent == nil {
+		return nil, fmt.Errorf("type must be set when the eval has no agent")
+	}
+
+	ref := *evalAgent
+	if a.Model != "" {
+		ref.Model = a.Model
+	}
+	return &ref, nil
+}
+
 type TaskSet struct {
 	// Exactly one of Glob or Path must be set
 	Glob string `json:"glob,omitempty"`
@@ -75,6 +162,15 @@ type TaskSet struct {
 	LabelSelector map[string]string `json:"labelSelector,omitempty"`
 
 	Assertions *TaskAssertions `json:"assertions,omitempty"`
+
+	// Agent replaces the agent of the eval for the tasks of the task set. An
+	// agent without a type keeps the type and path of the eval agent, so that
+	// only the model is replaced.
+	Agent *AgentRef `json:"agent,omitempty"`
+
+	// LLMJudge replaces the LLM judge of the eval for the tasks of the task
+	// set
+	LLMJudge *llmjudge.LLMJudgeEvalConfig `json:"llmJudge"`
 }
 
 // TODO: add a custom Verify script for another form of assertion
@@ -86,6 +182,9 @@ type TaskAssertions struct {
 	MinToolCalls *int            `json:"minToolCalls,omitempty"`
 	MaxToolCalls *int            `json:"maxToolCalls,omitempty"`
 
+	// ToolCallCounts limits how often the matching tools are called
+	ToolCallCounts []ToolCallCountAssertion `json:"toolCallCounts,omitempty"`
+
 	// Resource assertions
 	ResourcesRead    []ResourceAssertion `json:"resourcesRead,omitempty"`
 	ResourcesNotRead []ResourceAssertion `json:"resourcesNotRead,omitempty"`
@@ -97,8 +196,157 @@ type TaskAssertions struct {
 	// Order assertions
 	CallOrder []CallOrderAssertion `json:"callOrder,omitempty"`
 
+	// Phases are groups of calls that must be made one group after the
+	// other, in any order within a group
+	Phases []PhaseAssertion `json:"phases,omitempty"`
+
 	// Efficiency assertions
-	NoDuplicateCalls bool `json:"noDuplicateCalls,omitempty"`
+	NoDuplicateCalls *NoDuplicateCallsAssertion `json:"noDuplicateCalls,omitempty"`
+
+	// Timing assertions, as durations like "90s" or "2m"
+	MaxAgentDuration string `json:"maxAgentDuration,omitempty"`
+
+	// MaxContextTokens limits the estimated tokens the tool results add to
+	// the context of the agent, counted with the tokenizer of the eval
+	MaxContextTokens *int `json:"maxContextTokens,omitempty"`
+
+	// Grounding assertions, which check that the names and IDs the agent
+	// answers with come from the tool results it got
+	GroundedOutput *GroundedOutputAssertion `json:"groundedOutput,omitempty"`
+
+	// Expression assertions, as a CEL expression over the call history and
+	// the result of the task that must evaluate to true
+	Expr string `json:"expr,omitempty"`
+
+	// Custom assertions by name, with their config. Names are either
+	// registered with RegisterAssertion, or of the form
+	// <extension>.<operation> to run an operation of an eval extension.
+	Custom map[string]json.RawMessage `json:"custom,omitempty"`
+}
+
+// validate checks the assertions that can be checked before running tasks.
+// where is added to the errors to say which assertions are invalid.
+func (a *TaskAssertions) validate(where string) error {
+	if a == nil {
+		return nil
+	}
+	if a.MaxAgentDuration != "" {
+		if _, err := time.ParseDuration(a.MaxAgentDuration); err != nil {
+			return fmt.Errorf("invalid maxAgentDuration %s: %w", where, err)
+		}
+	}
+	if a.MaxContextTokens != nil && *a.MaxContextTokens <= 0 {
+		return fmt.Errorf("invalid maxContextTokens %s: must be positive", where)
+	}
+	for j := range a.ToolCallCounts {
+		if err := a.ToolCallCounts[j].validate(); err != nil {
+			return fmt.Errorf("invalid toolCallCounts[%d] %s: %w", j, where, err)
+		}
+	}
+	for j := range a.Phases {
+		if err := a.Phases[j].validate(); err != nil {
+			return fmt.Errorf("invalid phases[%d] %s: %w", j, where, err)
+		}
+	}
+	if a.NoDuplicateCalls.Enabled() {
+		if err := a.NoDuplicateCalls.validate(); err != nil {
+			return fmt.Errorf("invalid noDuplicateCalls %s: %w", where, err)
+		}
+	}
+	if a.GroundedOutput.Enabled() {
+		if err := a.GroundedOutput.validate(); err != nil {
+			return fmt.Errorf("invalid groundedOutput %s: %w", where, err)
+		}
+	}
+	if a.Expr != "" {
+		if _, err := compileExpr(a.Expr); err != nil {
+			return fmt.Errorf("invalid expr %s: %w", where, err)
+		}
+	}
+	return nil
+}
+
+// mergeTaskAssertions returns the assertions of a task set with the defaults
+// merged into them. Every assertion the task set sets wins over the default, so an
+// assertion like noDuplicateCalls is turned off by setting it to false.
+// Custom assertions are merged by name, and one set to null is removed.
+func mergeTaskAssertions(defaults, a *TaskAssertions) *TaskAssertions {
+	if defaults == nil {
+		return a
+	}
+
+	merged := *defaults
+	if a == nil {
+		a = &TaskAssertions{}
+	}
+
+	if a.ToolsUsed != nil {
+		merged.ToolsUsed = a.ToolsUsed
+	}
+	if a.RequireAny != nil {
+		merged.RequireAny = a.RequireAny
+	}
+	if a.ToolsNotUsed != nil {
+		merged.ToolsNotUsed = a.ToolsNotUsed
+	}
+	if a.MinToolCalls != nil {
+		merged.MinToolCalls = a.MinToolCalls
+	}
+	if a.MaxToolCalls != nil {
+		merged.MaxToolCalls = a.MaxToolCalls
+	}
+	if a.ToolCallCounts != nil {
+		merged.ToolCallCounts = a.ToolCallCounts
+	}
+	if a.ResourcesRead != nil {
+		merged.ResourcesRead = a.ResourcesRead
+	}
+	if a.ResourcesNotRead != nil {
+		merged.ResourcesNotRead = a.ResourcesNotRead
+	}
+	if a.PromptsUsed != nil {
+		merged.PromptsUsed = a.PromptsUsed
+	}
+	if a.PromptsNotUsed != nil {
+		merged.PromptsNotUsed = a.PromptsNotUsed
+	}
+	if a.CallOrder != nil {
+		merged.CallOrder = a.CallOrder
+	}
+	if a.Phases != nil {
+		merged.Phases = a.Phases
+	}
+	if a.NoDuplicateCalls != nil {
+		merged.NoDuplicateCalls = a.NoDuplicateCalls
+	}
+	if a.MaxAgentDuration != "" {
+		merged.MaxAgentDuration = a.MaxAgentDuration
+	}
+	if a.MaxContextTokens != nil {
+		merged.MaxContextTokens = a.MaxContextTokens
+	}
+	if a.GroundedOutput != nil {
+		merged.GroundedOutput = a.GroundedOutput
+	}
+	if a.Expr != "" {
+		merged.Expr = a.Expr
+	}
+
+	if len(defaults.Custom) > 0 || len(a.Custom) > 0 {
+		merged.Custom = make(map[string]json.RawMessage, len(defaults.Custom)+len(a.Custom))
+		for name, cfg := range defaults.Custom {
+			merged.Custom[name] = cfg
+		}
+		for name, cfg := range a.Custom {
+			if string(cfg) == "null" {
+				delete(merged.Custom, name)
+				continue
+			}
+			merged.Custom[name] = cfg
+		}
+	}
+
+	return &merged
 }
 
 type ToolAssertion struct {
@@ -110,6 +358,32 @@ type ToolAssertion struct {
 	ToolPattern string `json:"toolPattern,omitempty"` // regex pattern
 }
 
+// ToolCallCountAssertion limits the number of calls of the tools matching
+// the embedded ToolAssertion. At least one of Min or Max must be set.
+type ToolCallCountAssertion struct {
+	ToolAssertion `json:",inline"`
+
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+}
+
+// validate checks that the bounds are set and consistent
+func (a *ToolCallCountAssertion) validate() error {
+	if a.Min == nil && a.Max == nil {
+		return fmt.Errorf("at least one of min or max must be set")
+	}
+	if a.Min != nil && *a.Min < 0 {
+		return fmt.Errorf("min must not be negative, got %d", *a.Min)
+	}
+	if a.Max != nil && *a.Max < 0 {
+		return fmt.Errorf("max must not be negative, got %d", *a.Max)
+	}
+	if a.Min != nil && a.Max != nil && *a.Min > *a.Max {
+		return fmt.Errorf("min %d is greater than max %d", *a.Min, *a.Max)
+	}
+	return nil
+}
+
 type ResourceAssertion struct {
 	Server string `json:"server"`
 
@@ -117,6 +391,11 @@ type ResourceAssertion struct {
 	// If neither is set, matches any resource from the server
 	URI        string `json:"uri,omitempty"`
 	URIPattern string `json:"uriPattern,omitempty"` // regex pattern
+
+	// Optionally, the resource must be read through the resource template
+	// with this URI template, and with these values of its variables
+	Template       string            `json:"template,omitempty"`
+	TemplateParams map[string]string `json:"templateParams,omitempty"`
 }
 
 type PromptAssertion struct {
@@ -126,6 +405,156 @@ type PromptAssertion struct {
 	// If neither is set, matches any prompt from the server
 	Prompt        string `json:"prompt,omitempty"`
 	PromptPattern string `json:"promptPattern,omitempty"`
+
+	// Optionally, the prompt must be requested with these argument values.
+	// Arguments that are not listed may have any value.
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// Scopes of the noDuplicateCalls assertion
+const (
+	DuplicateScopeToolAndArgs = "toolAndArgs"
+	DuplicateScopeTool        = "tool"
+)
+
+// NoDuplicateCallsAssertion fails when the agent calls a tool more than once.
+// In eval files it is either true, to use the defaults, or an object with
+// options.
+type NoDuplicateCallsAssertion struct {
+	// Scope is what makes calls duplicates: "toolAndArgs" (the default) for
+	// calls of the same tool with the same arguments, or "tool" for any calls
+	// of the same tool
+	Scope string `json:"scope,omitempty"`
+	// Within only counts a call as a duplicate when it follows the previous
+	// one within this duration, like "30s", so that polling is allowed
+	Within string `json:"within,omitempty"`
+	// IgnoreTools are the names of tools that may be called any number of
+	// times
+	IgnoreTools []string `json:"ignoreTools,omitempty"`
+
+	// disabled is set when the assertion is written as false
+	disabled bool
+}
+
+// Enabled returns whether the assertion is set and not written as false
+func (a *NoDuplicateCallsAssertion) Enabled() bool {
+	return a != nil && !a.disabled
+}
+
+func (a *NoDuplicateCallsAssertion) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*a = NoDuplicateCallsAssertion{disabled: !enabled}
+		return nil
+	}
+
+	type options NoDuplicateCallsAssertion
+	var o options
+	if err := json.Unmarshal(data, &o); err != nil {
+		return fmt.Errorf("noDuplicateCalls must be a bool or an object with scope, within, and ignoreTools: %w", err)
+	}
+
+	*a = NoDuplicateCallsAssertion(o)
+	return nil
+}
+
+func (a NoDuplicateCallsAssertion) MarshalJSON() ([]byte, error) {
+	if a.disabled {
+		return []byte("false"), nil
+	}
+	if a.Scope == "" && a.Within == "" && len(a.IgnoreTools) == 0 {
+		return []byte("true"), nil
+	}
+
+	type options NoDuplicateCallsAssertion
+	return json.Marshal(options(a))
+}
+
+// validate checks the scope and the within duration
+func (a *NoDuplicateCallsAssertion) validate() error {
+	switch a.Scope {
+	case "", DuplicateScopeToolAndArgs, DuplicateScopeTool:
+	default:
+		return fmt.Errorf("unknown scope %q: must be %s or %s", a.Scope, DuplicateScopeToolAndArgs, DuplicateScopeTool)
+	}
+
+	if a.Within != "" {
+		within, err := time.ParseDuration(a.Within)
+		if err != nil {
+			return fmt.Errorf("invalid within: %w", err)
+		}
+		if within <= 0 {
+			return fmt.Errorf("within must be positive, got %s", a.Within)
+		}
+	}
+
+	return nil
+}
+
+// GroundedOutputAssertion fails when the agent output mentions names or IDs
+// that are in none of the tool results, resources, or prompts the agent got,
+// nor in the task prompt, as they are likely hallucinated. In eval files it
+// is either true, to use the defaults, or an object with options.
+type GroundedOutputAssertion struct {
+	// Patterns are regular expressions that match the claims to check in the
+	// output: the first group of a match if the pattern has groups, or the
+	// whole match. Defaults to DefaultClaimPatterns.
+	Patterns []string `json:"patterns,omitempty"`
+	// Ignore are claims that do not need to be grounded, such as well-known
+	// names the agent may mention without looking them up
+	Ignore []string `json:"ignore,omitempty"`
+	// MaxUngrounded is how many ungrounded claims are tolerated
+	MaxUngrounded int `json:"maxUngrounded,omitempty"`
+
+	// disabled is set when the assertion is written as false
+	disabled bool
+}
+
+// Enabled returns whether the assertion is set and not written as false
+func (a *GroundedOutputAssertion) Enabled() bool {
+	return a != nil && !a.disabled
+}
+
+func (a *GroundedOutputAssertion) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*a = GroundedOutputAssertion{disabled: !enabled}
+		return nil
+	}
+
+	type options GroundedOutputAssertion
+	var o options
+	if err := json.Unmarshal(data, &o); err != nil {
+		return fmt.Errorf("groundedOutput must be a bool or an object with patterns, ignore, and maxUngrounded: %w", err)
+	}
+
+	*a = GroundedOutputAssertion(o)
+	return nil
+}
+
+func (a GroundedOutputAssertion) MarshalJSON() ([]byte, error) {
+	if a.disabled {
+		return []byte("false"), nil
+	}
+	if len(a.Patterns) == 0 && len(a.Ignore) == 0 && a.MaxUngrounded == 0 {
+		return []byte("true"), nil
+	}
+
+	type options GroundedOutputAssertion
+	return json.Marshal(options(a))
+}
+
+// validate checks that the patterns compile
+func (a *GroundedOutputAssertion) validate() error {
+	for i, pattern := range a.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid patterns[%d]: %w", i, err)
+		}
+	}
+	if a.MaxUngrounded < 0 {
+		return fmt.Errorf("maxUngrounded must not be negative, got %d", a.MaxUngrounded)
+	}
+	return nil
 }
 
 type CallOrderAssertion struct {
@@ -134,10 +563,40 @@ type CallOrderAssertion struct {
 	Name   string `json:"name"`
 }
 
+// PhaseAssertion is a group of calls of a phases assertion. All calls are
+// required, and no call of the phase may be made after a call of a later
+// phase.
+type PhaseAssertion struct {
+	Name  string               `json:"name,omitempty"`
+	Calls []CallOrderAssertion `json:"calls"`
+}
+
+// validate checks that the phase has calls of known types
+func (p *PhaseAssertion) validate() error {
+	if len(p.Calls) == 0 {
+		return fmt.Errorf("calls must not be empty")
+	}
+
+	for i, call := range p.Calls {
+		switch call.Type {
+		case "tool", "resource", "prompt":
+		default:
+			return fmt.Errorf("unknown type %q of calls[%d]: must be tool, resource, or prompt", call.Type, i)
+		}
+	}
+
+	return nil
+}
+
 func Read(data []byte, basePath string) (*EvalSpec, error) {
 	spec := &EvalSpec{}
 
-	err := yaml.Unmarshal(data, spec)
+	data, includes, err := resolveIncludes(data, basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, spec)
 	if err != nil {
 		return nil, err
 	}
@@ -148,6 +607,7 @@ func Read(data []byte, basePath string) (*EvalSpec, error) {
 
 	// Store the base path for later use (e.g., resolving extension paths)
 	spec.basePath = basePath
+	spec.includes = includes
 
 	// Convert all relative file paths to absolute paths
 	if spec.Config.Agent != nil && spec.Config.Agent.Type == "file" {
@@ -158,9 +618,64 @@ func Read(data []byte, basePath string) (*EvalSpec, error) {
 	if err := resolveFilePath(&spec.Config.McpConfigFile, basePath); err != nil {
 		return nil, fmt.Errorf("failed to resolve mcp config file path: %w", err)
 	}
+	for i := range spec.Config.McpConfigFiles {
+		if err := resolveFilePath(&spec.Config.McpConfigFiles[i], basePath); err != nil {
+			return nil, fmt.Errorf("failed to resolve mcp config file path at index %d: %w", i, err)
+		}
+	}
+	if err := resolveFilePath(&spec.Config.QuarantineFile, basePath); err != nil {
+		return nil, fmt.Errorf("failed to resolve quarantine file path: %w", err)
+	}
+	if spec.Config.AgentOutput != nil {
+		if err := resolveFilePath(&spec.Config.AgentOutput.ArtifactDir, basePath); err != nil {
+			return nil, fmt.Errorf("failed to resolve agent output artifact dir: %w", err)
+		}
+	}
+
+	if spec.Config.McpTaskMetadata != nil {
+		if err := spec.Config.McpTaskMetadata.validate(); err != nil {
+			return nil, fmt.Errorf("invalid mcpTaskMetadata: %w", err)
+		}
+	}
+
+	if err := validateOutputExpectations(spec.Config.OutputExpectations); err != nil {
+		return nil, err
+	}
+
+	if spec.Config.SafetyScan != nil {
+		if err := spec.Config.SafetyScan.validate(); err != nil {
+			return nil, fmt.Errorf("invalid safetyScan: %w", err)
+		}
+	}
+
+	if err := spec.Config.Tokenizer.validate(); err != nil {
+		return nil, fmt.Errorf("invalid tokenizer: %w", err)
+	}
+
+	if spec.Config.AgentResources != nil {
+		if err := spec.Config.AgentResources.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid agentResources: %w", err)
+		}
+	}
+
+	if err := spec.Config.DefaultAssertions.validate("in defaultAssertions"); err != nil {
+		return nil, err
+	}
 
 	// Resolve task set paths and globs
 	for i := range spec.Config.TaskSets {
+		ts := &spec.Config.TaskSets[i]
+		ts.Assertions = mergeTaskAssertions(spec.Config.DefaultAssertions, ts.Assertions)
+		if err := ts.Assertions.validate(fmt.Sprintf("in task set at index %d", i)); err != nil {
+			return nil, err
+		}
+
+		if ts.Agent != nil && ts.Agent.Type == "file" {
+			if err := resolveFilePath(&ts.Agent.Path, basePath); err != nil {
+				return nil, fmt.Errorf("failed to resolve agent file path of task set at index %d: %w", i, err)
+			}
+		}
+
 		if spec.Config.TaskSets[i].Path != "" {
 			if err := resolveFilePath(&spec.Config.TaskSets[i].Path, basePath); err != nil {
 				return nil, fmt.Errorf("failed to resolve task set path at index %d: %w", i, err)
@@ -208,3 +723,17 @@ func FromFile(path string) (*EvalSpec, error) {
 
 	return Read(data, basePath)
 }
+
+// ConfigError reports a problem with the eval configuration, as opposed to a
+// failure while running the eval.
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
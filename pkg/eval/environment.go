@@ -0,0 +1,55 @@
+package eval
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Environment is the environment info reported by the extensions of a run
+// when they initialize, such as the cluster version or installed CLIs, by
+// extension alias
+type Environment map[string]map[string]string
+
+// Flatten returns the info keyed by <alias>.<key>
+func (e Environment) Flatten() map[string]string {
+	flat := make(map[string]string)
+	for alias, info := range e {
+		for key, value := range info {
+			flat[alias+"."+key] = value
+		}
+	}
+	return flat
+}
+
+// Changes describes how the environment other differs from e, one line per
+// changed key in key order, like "kube.clusterVersion: v1.29.4 -> v1.30.2".
+// Keys missing from one of them are shown as "(none)".
+func (e Environment) Changes(other Environment) []string {
+	from, to := e.Flatten(), other.Flatten()
+
+	keys := slices.Collect(maps.Keys(from))
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	var changes []string
+	for _, key := range keys {
+		before, hadBefore := from[key]
+		after, hasAfter := to[key]
+		if hadBefore && hasAfter && before == after {
+			continue
+		}
+		if !hadBefore {
+			before = "(none)"
+		}
+		if !hasAfter {
+			after = "(none)"
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", key, before, after))
+	}
+	return changes
+}
@@ -0,0 +1,28 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironmentChanges(t *testing.T) {
+	base := Environment{
+		"kube": {"clusterVersion": "v1.29.4", "distribution": "kind"},
+		"cli":  {"kubectl": "v1.29.0"},
+	}
+	head := Environment{
+		"kube": {"clusterVersion": "v1.30.2", "distribution": "kind"},
+		"gh":   {"version": "2.50.0"},
+	}
+
+	assert.Equal(t, []string{
+		"cli.kubectl: v1.29.0 -> (none)",
+		"gh.version: (none) -> 2.50.0",
+		"kube.clusterVersion: v1.29.4 -> v1.30.2",
+	}, base.Changes(head))
+
+	assert.Empty(t, base.Changes(base))
+	assert.Empty(t, Environment(nil).Changes(nil))
+	assert.Equal(t, []string{"gh.version: (none) -> 2.50.0"}, Environment(nil).Changes(Environment{"gh": head["gh"]}))
+}
@@ -0,0 +1,202 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// includeKey is the key of an eval file that lists the fragments it includes
+const includeKey = "include"
+
+// resolveIncludes returns the eval document with the fragments it includes
+// deep-merged into it, as JSON. An eval file can include a path or a list of
+// paths, relative to the file. Fragments are merged in order, and the file
+// itself last, so that later values win: maps are merged key by key, and any
+// other value, including lists, replaces the earlier one. Fragments can
+// include other fragments, but not themselves. Relative paths in fragments are
+// made relative to the fragment. The paths of the fragments are returned too.
+func resolveIncludes(data []byte, basePath string) ([]byte, []string, error) {
+	var top struct {
+		Include any `json:"include"`
+	}
+	if err := yaml.Unmarshal(data, &top); err != nil || top.Include == nil {
+		// Errors are reported when the spec is unmarshalled
+		return data, nil, nil
+	}
+
+	var fragments []string
+	doc, err := loadDocument(data, basePath, nil, &fragments)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err = json.Marshal(doc)
+	return data, fragments, err
+}
+
+// loadDocument parses a document and merges the fragments it includes into
+// it. stack holds the fragments that are being included, to detect cycles,
+// and the paths of all included fragments are added to fragments.
+func loadDocument(data []byte, dir string, stack []string, fragments *[]string) (map[string]any, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	paths, err := includePaths(doc[includeKey])
+	if err != nil {
+		return nil, err
+	}
+	delete(doc, includeKey)
+	if len(paths) == 0 {
+		return doc, nil
+	}
+
+	merged := map[string]any{}
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		path = filepath.Clean(path)
+
+		for i, included := range stack {
+			if included == path {
+				cycle := append(stack[i:len(stack):len(stack)], path)
+				return nil, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+			}
+		}
+
+		fragmentData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read included file: %w", err)
+		}
+		*fragments = append(*fragments, path)
+
+		fragment, err := loadDocument(fragmentData, filepath.Dir(path), append(stack[:len(stack):len(stack)], path), fragments)
+		if err != nil {
+			return nil, fmt.Errorf("invalid included file %s: %w", path, err)
+		}
+		rebaseFragmentPaths(fragment, filepath.Dir(path))
+
+		merged = deepMerge(merged, fragment)
+	}
+
+	return deepMerge(merged, doc), nil
+}
+
+// includePaths returns the paths of an include value, which is a path or a
+// list of paths
+func includePaths(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		paths := make([]string, len(v))
+		for i, p := range v {
+			path, ok := p.(string)
+			if !ok || path == "" {
+				return nil, fmt.Errorf("invalid include at index %d: must be a path", i)
+			}
+			paths[i] = path
+		}
+		return paths, nil
+	default:
+		return nil, fmt.Errorf("invalid include: must be a path or a list of paths")
+	}
+}
+
+// deepMerge merges override into base. Maps are merged key by key, any other
+// value of override replaces the one of base. base is modified.
+func deepMerge(base, override map[string]any) map[string]any {
+	for key, value := range override {
+		baseMap, baseIsMap := base[key].(map[string]any)
+		overrideMap, overrideIsMap := value.(map[string]any)
+		if baseIsMap && overrideIsMap {
+			base[key] = deepMerge(baseMap, overrideMap)
+			continue
+		}
+		base[key] = value
+	}
+	return base
+}
+
+// rebaseFragmentPaths makes the relative file paths of the config of a
+// fragment absolute, relative to the directory of the fragment, as they
+// would otherwise be resolved relative to the eval file that includes it
+func rebaseFragmentPaths(fragment map[string]any, dir string) {
+	config, ok := fragment["config"].(map[string]any)
+	if !ok {
+		return
+	}
+
+	rebase := func(m map[string]any, key string) {
+		if path, ok := m[key].(string); ok && path != "" && !filepath.IsAbs(path) {
+			m[key] = filepath.Join(dir, path)
+		}
+	}
+
+	if agent, ok := config["agent"].(map[string]any); ok && agent["type"] == "file" {
+		rebase(agent, "path")
+	}
+	rebase(config, "mcpConfigFile")
+	rebase(config, "quarantineFile")
+	if files, ok := config["mcpConfigFiles"].([]any); ok {
+		for i, file := range files {
+			if path, ok := file.(string); ok && path != "" && !filepath.IsAbs(path) {
+				files[i] = filepath.Join(dir, path)
+			}
+		}
+	}
+	if output, ok := config["agentOutput"].(map[string]any); ok {
+		rebase(output, "artifactDir")
+	}
+	if taskSets, ok := config["taskSets"].([]any); ok {
+		for _, ts := range taskSets {
+			if set, ok := ts.(map[string]any); ok {
+				rebase(set, "path")
+				rebase(set, "glob")
+				if agent, ok := set["agent"].(map[string]any); ok && agent["type"] == "file" {
+					rebase(agent, "path")
+				}
+			}
+		}
+	}
+
+	// Extension packages are local files if they start with ./ or ../, or
+	// with file://
+	if extensions, ok := config["extensions"].(map[string]any); ok {
+		for _, e := range extensions {
+			ext, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			pkg, _ := ext["package"].(string)
+			ref, isFileRef := strings.CutPrefix(pkg, "file://")
+			if !isFileRef && !strings.HasPrefix(pkg, "./") && !strings.HasPrefix(pkg, "../") {
+				continue
+			}
+			if ref == "" || filepath.IsAbs(ref) || strings.HasPrefix(ref, "~/") {
+				continue
+			}
+			if isFileRef {
+				ext["package"] = "file://" + filepath.Join(dir, ref)
+			} else {
+				ext["package"] = filepath.Join(dir, ref)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles writes files with the given contents to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestReadIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"shared/agent.yaml": `
+config:
+  agent:
+    type: file
+    path: agents/claude.yaml
+  mcpConfigFile: mcp.yaml
+  extensions:
+    kube:
+      package: ./extensions/kube
+`,
+		"shared/judge.yaml": `
+include: defaults.yaml
+config:
+  llmJudge:
+    env:
+      baseUrlKey: JUDGE_BASE_URL
+      apiKeyKey: JUDGE_API_KEY
+      modelNameKey: JUDGE_MODEL_NAME
+`,
+		"shared/defaults.yaml": `
+config:
+  reuseMcpServers: true
+  agentOutput:
+    maxBytes: 100
+    artifactDir: artifacts
+  outputExpectations:
+    - name: no-secrets
+      mustNotMatch: ["password"]
+`,
+	})
+
+	spec, err := Read([]byte(`
+kind: Eval
+metadata:
+  name: composed
+include:
+  - shared/agent.yaml
+  - shared/judge.yaml
+config:
+  agentOutput:
+    maxBytes: 200
+  outputExpectations:
+    - name: no-traces
+      mustNotMatch: ["Traceback"]
+  taskSets:
+    - path: tasks/create-pod.yaml
+`), dir)
+	require.NoError(t, err)
+
+	shared := filepath.Join(dir, "shared")
+	assert.Equal(t, "composed", spec.Metadata.Name)
+
+	// Paths of fragments are relative to the fragment
+	require.NotNil(t, spec.Config.Agent)
+	assert.Equal(t, filepath.Join(shared, "agents/claude.yaml"), spec.Config.Agent.Path)
+	assert.Equal(t, filepath.Join(shared, "mcp.yaml"), spec.Config.McpConfigFile)
+	assert.Equal(t, filepath.Join(shared, "extensions/kube"), spec.Config.Extensions["kube"].Package)
+	assert.Equal(t, filepath.Join(dir, "tasks/create-pod.yaml"), spec.Config.TaskSets[0].Path)
+
+	// Nested includes are merged
+	require.NotNil(t, spec.Config.LLMJudge)
+	assert.Equal(t, "JUDGE_MODEL_NAME", spec.Config.LLMJudge.Env.ModelNameKey)
+	assert.True(t, spec.Config.ReuseMcpServers)
+
+	// Maps are deep-merged, lists are replaced
+	require.NotNil(t, spec.Config.AgentOutput)
+	assert.Equal(t, 200, spec.Config.AgentOutput.MaxBytes)
+	assert.Equal(t, filepath.Join(shared, "artifacts"), spec.Config.AgentOutput.ArtifactDir)
+	require.Len(t, spec.Config.OutputExpectations, 1)
+	assert.Equal(t, "no-traces", spec.Config.OutputExpectations[0].Name)
+
+	// Every fragment is recorded, nested ones included
+	assert.Equal(t, []string{
+		filepath.Join(shared, "agent.yaml"),
+		filepath.Join(shared, "judge.yaml"),
+		filepath.Join(shared, "defaults.yaml"),
+	}, spec.Includes())
+}
+
+func TestReadIncludeOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.yaml": "config:\n  mcpProfile: a\n  reuseMcpServers: true\n",
+		"b.yaml": "config:\n  mcpProfile: b\n",
+	})
+
+	spec, err := Read([]byte("kind: Eval\nmetadata:\n  name: order\ninclude: [a.yaml, b.yaml]\n"), dir)
+	require.NoError(t, err)
+	assert.Equal(t, "b", spec.Config.McpProfile)
+	assert.True(t, spec.Config.ReuseMcpServers)
+
+	spec, err = Read([]byte("kind: Eval\nmetadata:\n  name: order\ninclude: a.yaml\nconfig:\n  mcpProfile: own\n"), dir)
+	require.NoError(t, err)
+	assert.Equal(t, "own", spec.Config.McpProfile)
+}
+
+func TestReadIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.yaml":    "include: b.yaml\n",
+		"b.yaml":    "include: a.yaml\n",
+		"self.yaml": "include: ./self.yaml\n",
+		"bad.yaml":  "config: [\n",
+	})
+
+	tests := map[string]struct {
+		include     string
+		errContains string
+	}{
+		"cycle": {
+			include:     "a.yaml",
+			errContains: "include cycle: " + filepath.Join(dir, "a.yaml") + " -> " + filepath.Join(dir, "b.yaml") + " -> " + filepath.Join(dir, "a.yaml"),
+		},
+		"self": {
+			include:     "self.yaml",
+			errContains: "include cycle: " + filepath.Join(dir, "self.yaml") + " -> " + filepath.Join(dir, "self.yaml"),
+		},
+		"missing file": {
+			include:     "missing.yaml",
+			errContains: "failed to read included file",
+		},
+		"invalid fragment": {
+			include:     "bad.yaml",
+			errContains: "invalid included file " + filepath.Join(dir, "bad.yaml"),
+		},
+		"not a path": {
+			include:     "{file: a.yaml}",
+			errContains: "invalid include: must be a path or a list of paths",
+		},
+		"empty path": {
+			include:     `[a.yaml, ""]`,
+			errContains: "invalid include at index 1: must be a path",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read([]byte("kind: Eval\nmetadata:\n  name: broken\ninclude: "+tc.include+"\n"), dir)
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
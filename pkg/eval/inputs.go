@@ -0,0 +1,116 @@
+package eval
+
+import (
+	"maps"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// RunInputs are the resolved configs a task ran with, recorded with its
+// result so that a results file describes how to reproduce it without the
+// original files. Secrets are redacted.
+type RunInputs struct {
+	// Eval is the eval spec, with its includes merged
+	Eval *EvalSpec `json:"eval,omitempty"`
+	// Task is the task spec, with its dataset row applied and the secrets of
+	// its MCP servers redacted
+	Task *task.TaskConfig `json:"task,omitempty"`
+	// Agent is the spec of the agent of the task. It is not set if the agent
+	// was given as a runner through the Go API
+	Agent *agent.AgentSpec `json:"agent,omitempty"`
+	// McpServers are the MCP servers of the task, with their profile, the
+	// servers of the task, and the servers it requires applied, and
+	// environment variable references expanded
+	McpServers map[string]*mcpproxy.ServerConfig `json:"mcpServers,omitempty"`
+}
+
+// newRunInputs returns the inputs shared by the tasks of a run
+func newRunInputs(spec *EvalSpec, agentSpec *agent.AgentSpec, mcpConfig *mcpproxy.MCPConfig) *RunInputs {
+	inputs := &RunInputs{Eval: spec.Redacted()}
+	if agentSpec != nil {
+		inputs.Agent = agentSpec.Redacted()
+	}
+	if mcpConfig != nil {
+		inputs.McpServers = redactedServers(mcpConfig.MCPServers)
+	}
+	return inputs
+}
+
+// forTask returns the inputs of a task, with the agent of its task set and the
+// MCP servers the task runs with
+func (in *RunInputs) forTask(tc taskConfig) *RunInputs {
+	if in == nil {
+		return nil
+	}
+
+	inputs := *in
+	inputs.Task = tc.spec
+	if tc.spec.Spec != nil && len(tc.spec.Spec.McpServers) > 0 {
+		taskSpec := *tc.spec
+		spec := *tc.spec.Spec
+		spec.McpServers = redactedServers(tc.spec.Spec.McpServers)
+		taskSpec.Spec = &spec
+		inputs.Task = &taskSpec
+	}
+	if tc.override.agentSpec != nil {
+		inputs.Agent = tc.override.agentSpec.Redacted()
+	}
+
+	// The servers of the run are already redacted. A task missing servers it
+	// requires fails before they start, and is recorded with those of the run
+	if tc.spec.Spec != nil {
+		if mcpConfig, err := taskMCPConfig(tc, &mcpproxy.MCPConfig{MCPServers: in.McpServers}); err == nil {
+			inputs.McpServers = redactedServers(mcpConfig.MCPServers)
+		}
+	}
+	return &inputs
+}
+
+// redactedServers returns the redacted configs of MCP servers
+func redactedServers(servers map[string]*mcpproxy.ServerConfig) map[string]*mcpproxy.ServerConfig {
+	if len(servers) == 0 {
+		return nil
+	}
+
+	redacted := make(map[string]*mcpproxy.ServerConfig, len(servers))
+	for name, server := range servers {
+		if server == nil {
+			continue
+		}
+		redacted[name] = server.Redacted()
+	}
+	return redacted
+}
+
+// Redacted returns a copy of the spec for recording with results, with the
+// values of extension environment variables and config keys whose names look
+// like secrets redacted
+func (s *EvalSpec) Redacted() *EvalSpec {
+	redacted := *s
+	if s.Config.Extensions == nil {
+		return &redacted
+	}
+
+	redacted.Config.Extensions = make(map[string]*extension.ExtensionSpec, len(s.Config.Extensions))
+	for alias, ext := range s.Config.Extensions {
+		if ext == nil {
+			continue
+		}
+		e := *ext
+		e.Env = util.RedactSecretValues(ext.Env)
+		if ext.Config != nil {
+			e.Config = maps.Clone(ext.Config)
+			for k := range e.Config {
+				if util.IsSecretName(k) {
+					e.Config[k] = util.Redacted
+				}
+			}
+		}
+		redacted.Config.Extensions[alias] = &e
+	}
+	return &redacted
+}
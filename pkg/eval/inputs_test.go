@@ -0,0 +1,134 @@
+package eval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalSpecRedacted(t *testing.T) {
+	spec := &EvalSpec{
+		Metadata: EvalMetadata{Name: "k8s"},
+		Config: EvalConfig{
+			Extensions: map[string]*extension.ExtensionSpec{
+				"kube": {
+					Package: "github.com/example/kube-ext",
+					Env:     map[string]string{"KUBECONFIG": "/tmp/kubeconfig", "API_TOKEN": "s3cret"},
+					Config:  map[string]any{"namespace": "default", "apiKey": "s3cret"},
+				},
+			},
+		},
+	}
+
+	redacted := spec.Redacted()
+	kube := redacted.Config.Extensions["kube"]
+	assert.Equal(t, map[string]string{"KUBECONFIG": "/tmp/kubeconfig", "API_TOKEN": "[REDACTED]"}, kube.Env)
+	assert.Equal(t, map[string]any{"namespace": "default", "apiKey": "[REDACTED]"}, kube.Config)
+
+	// The spec itself is not modified
+	assert.Equal(t, "s3cret", spec.Config.Extensions["kube"].Env["API_TOKEN"])
+	assert.Equal(t, "s3cret", spec.Config.Extensions["kube"].Config["apiKey"])
+}
+
+func TestRunInputs(t *testing.T) {
+	t.Setenv("MCP_TOKEN", "s3cret")
+	t.Setenv("MCP_HOST", "mcp.example.com")
+
+	spec := &EvalSpec{Metadata: EvalMetadata{Name: "k8s"}}
+	agentSpec := &agent.AgentSpec{
+		Metadata: agent.AgentMetadata{Name: "openai-agent"},
+		Builtin:  &agent.BuiltinRef{Type: "openai-agent", Model: "gpt-4", APIKey: "sk-s3cret"},
+	}
+	mcpConfig := &mcpproxy.MCPConfig{MCPServers: map[string]*mcpproxy.ServerConfig{
+		"hosted": {
+			URL:     "https://${MCP_HOST}/mcp",
+			Headers: map[string]string{"X-Tenant": "acme"},
+			Auth:    &mcpproxy.AuthConfig{Type: "bearer", Token: "${MCP_TOKEN}"},
+		},
+	}}
+
+	inputs := newRunInputs(spec, agentSpec, mcpConfig)
+	assert.Equal(t, "[REDACTED]", inputs.Agent.Builtin.APIKey)
+	assert.Equal(t, "sk-s3cret", agentSpec.Builtin.APIKey, "the agent spec is not modified")
+
+	hosted := inputs.McpServers["hosted"]
+	assert.Equal(t, "https://mcp.example.com/mcp", hosted.URL, "references to non-secret variables are expanded")
+	assert.Equal(t, map[string]string{"X-Tenant": "acme"}, hosted.Headers)
+	assert.Equal(t, "[REDACTED]", hosted.Auth.Token)
+
+	taskSpec := &task.TaskConfig{Metadata: task.TaskMetadata{Name: "create-pod"}}
+	taskInputs := inputs.forTask(taskConfig{spec: taskSpec})
+	assert.Same(t, taskSpec, taskInputs.Task)
+	assert.Equal(t, "gpt-4", taskInputs.Agent.Builtin.Model)
+	assert.Nil(t, inputs.Task, "the inputs of the run are not modified")
+
+	override := &agent.AgentSpec{Builtin: &agent.BuiltinRef{Type: "openai-agent", Model: "o3", APIKey: "sk-s3cret"}}
+	taskInputs = inputs.forTask(taskConfig{spec: taskSpec, override: taskSetOverride{agentSpec: override}})
+	require.NotNil(t, taskInputs.Agent)
+	assert.Equal(t, "o3", taskInputs.Agent.Builtin.Model)
+	assert.Equal(t, "[REDACTED]", taskInputs.Agent.Builtin.APIKey)
+
+	var none *RunInputs
+	assert.Nil(t, none.forTask(taskConfig{spec: taskSpec}))
+}
+
+func TestRunInputsTaskMcpServers(t *testing.T) {
+	mcpConfig := &mcpproxy.MCPConfig{MCPServers: map[string]*mcpproxy.ServerConfig{
+		"hosted": {URL: "https://mcp.example.com/mcp", Auth: &mcpproxy.AuthConfig{Type: "bearer", Token: "s3cret"}},
+		"github": {Command: "github-mcp", Env: map[string]string{"GITHUB_TOKEN": "s3cret"}},
+	}}
+	inputs := newRunInputs(&EvalSpec{}, nil, mcpConfig)
+
+	taskSpec := &task.TaskConfig{
+		Metadata: task.TaskMetadata{Name: "create-pod"},
+		Spec: &task.TaskSpec{
+			McpServers: map[string]*mcpproxy.ServerConfig{
+				"fixture": {Command: "./fixture-server", Env: map[string]string{"API_KEY": "s3cret"}},
+			},
+			Requires: []task.Requirements{{Servers: []string{"hosted", "fixture"}}},
+		},
+	}
+	taskInputs := inputs.forTask(taskConfig{spec: taskSpec})
+
+	// The task spec is recorded with the secrets of its servers redacted
+	assert.Equal(t, "[REDACTED]", taskInputs.Task.Spec.McpServers["fixture"].Env["API_KEY"])
+	assert.Equal(t, "s3cret", taskSpec.Spec.McpServers["fixture"].Env["API_KEY"], "the task spec is not modified")
+
+	// The servers are those the task ran with
+	require.Len(t, taskInputs.McpServers, 2)
+	assert.Equal(t, "[REDACTED]", taskInputs.McpServers["hosted"].Auth.Token)
+	assert.Equal(t, "[REDACTED]", taskInputs.McpServers["fixture"].Env["API_KEY"])
+	assert.Len(t, inputs.McpServers, 2, "the inputs of the run are not modified")
+	assert.Contains(t, inputs.McpServers, "github")
+}
+
+func TestRunInputsRoundTrip(t *testing.T) {
+	spec, err := FromFile("../../examples/kube-mcp-server/claude-code/eval.yaml")
+	require.NoError(t, err)
+	taskSpec, err := task.FromFile("../../examples/kube-mcp-server/tasks/create-pod/create-pod.yaml")
+	require.NoError(t, err)
+	agentSpec, err := agent.FromFile("../../examples/kube-mcp-server/claude-code/agent.yaml")
+	require.NoError(t, err)
+
+	result := &EvalResult{
+		TaskName: taskSpec.Metadata.Name,
+		Inputs:   newRunInputs(spec, agentSpec, nil).forTask(taskConfig{spec: taskSpec}),
+	}
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	// Results files with inputs can be read back
+	loaded := &EvalResult{}
+	require.NoError(t, json.Unmarshal(data, loaded))
+	again, err := json.Marshal(loaded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+	assert.Equal(t, spec.Metadata.Name, loaded.Inputs.Eval.Metadata.Name)
+	assert.Equal(t, taskSpec.Spec.Prompt, loaded.Inputs.Task.Spec.Prompt)
+}
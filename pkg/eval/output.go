@@ -0,0 +1,209 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// DefaultMaxAgentOutputBytes is how much agent output is kept in the results
+// of a task unless AgentOutputConfig.MaxBytes is set
+const DefaultMaxAgentOutputBytes = 1 << 20
+
+// AgentOutputConfig limits how much agent output is kept in the results, so
+// that long transcripts do not bloat the results file.
+type AgentOutputConfig struct {
+	// MaxBytes caps the agent output of each task. Longer output keeps its
+	// head and tail around a truncation marker. Defaults to
+	// DefaultMaxAgentOutputBytes; a negative value disables the limit.
+	MaxBytes int `json:"maxBytes,omitempty"`
+
+	// ArtifactDir is where the full output of truncated tasks, and other
+	// artifacts such as captured MCP traffic and LLM judge transcripts, are
+	// written, relative to the eval file. Defaults to
+	// mcpchecker-<eval name>-artifacts in the current directory.
+	ArtifactDir string `json:"artifactDir,omitempty"`
+}
+
+// maxBytes returns the output cap, or 0 if output is not limited
+func (c *AgentOutputConfig) maxBytes() int {
+	switch {
+	case c == nil || c.MaxBytes == 0:
+		return DefaultMaxAgentOutputBytes
+	case c.MaxBytes < 0:
+		return 0
+	default:
+		return c.MaxBytes
+	}
+}
+
+// limitAgentOutput truncates the agent output and error of a task that exceed
+// the configured cap, and writes the full output to an artifact file
+func (r *evalRunner) limitAgentOutput(result *EvalResult) {
+	limit := r.spec.Config.AgentOutput.maxBytes()
+	if limit == 0 || (len(result.TaskOutput) <= limit && len(result.TaskError) <= limit) {
+		return
+	}
+
+	note := "see the agent output for details"
+	if result.TaskOutput != "" {
+		path, err := r.writeOutputArtifact(result, result.TaskOutput)
+		if err != nil {
+			note = fmt.Sprintf("failed to save full output: %v", err)
+		} else {
+			result.TaskOutputFile = path
+			note = fmt.Sprintf("full output saved to %s", path)
+		}
+	}
+
+	result.TaskOutput = truncateOutput(result.TaskOutput, limit, note)
+	result.TaskError = truncateOutput(result.TaskError, limit, note)
+
+	if result.AgentOutput == nil {
+		return
+	}
+	result.AgentOutput.Error = truncateOutput(result.AgentOutput.Error, limit, note)
+	for _, step := range result.AgentOutput.Steps {
+		step.Message = truncateOutput(step.Message, limit, note)
+		step.Error = truncateOutput(step.Error, limit, note)
+		for k, v := range step.Outputs {
+			step.Outputs[k] = truncateOutput(v, limit, note)
+		}
+	}
+}
+
+// fullAgentOutput returns the full output of the agent of a task, which is
+// read back from the artifact file if the output was truncated
+func fullAgentOutput(result *EvalResult) string {
+	if result.TaskOutputFile == "" {
+		return result.TaskOutput
+	}
+
+	data, err := os.ReadFile(result.TaskOutputFile)
+	if err != nil {
+		return result.TaskOutput
+	}
+	return string(data)
+}
+
+// writeOutputArtifact writes the full output of a task to the artifact
+// directory and returns its path
+func (r *evalRunner) writeOutputArtifact(result *EvalResult, output string) (string, error) {
+	path, err := r.artifactPath(result, "output.txt")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// writeTrafficArtifact writes the MCP server traffic of a task to a HAR file
+// in the artifact directory, if any traffic was captured
+func (r *evalRunner) writeTrafficArtifact(ctx context.Context, traffic *mcpproxy.TrafficRecorder, result *EvalResult) {
+	if traffic.Len() == 0 {
+		return
+	}
+
+	path, err := r.artifactPath(result, "traffic.har")
+	if err == nil {
+		err = traffic.WriteHAR(path)
+	}
+	if err != nil {
+		if util.IsVerbose(ctx) {
+			fmt.Printf("  → Failed to save mcp traffic: %v\n", err)
+		}
+		return
+	}
+
+	result.TrafficFile = path
+}
+
+// moveSpilledCalls moves the requests and results of the calls that were
+// spilled while the task ran to a calls file in the artifact directory, once
+// the task is evaluated, so that the results of a run do not keep them in
+// memory. If the file cannot be written, the calls are kept in the results.
+func (r *evalRunner) moveSpilledCalls(ctx context.Context, result *EvalResult) {
+	if !result.CallHistory.HasSpilled() {
+		return
+	}
+
+	path, err := r.artifactPath(result, "calls.jsonl")
+	if err == nil {
+		err = result.CallHistory.MoveSpilled(path)
+	}
+	if err != nil && util.IsVerbose(ctx) {
+		fmt.Printf("  → Failed to save spilled calls: %v\n", err)
+	}
+}
+
+// writeJudgeTranscriptArtifact writes the conversations of the LLM judge of a
+// task to a JSON file in the artifact directory, if the judge was called
+func (r *evalRunner) writeJudgeTranscriptArtifact(ctx context.Context, transcript *llmjudge.TranscriptRecorder, result *EvalResult) {
+	if transcript.Len() == 0 {
+		return
+	}
+
+	path, err := r.artifactPath(result, "judge.json")
+	if err == nil {
+		err = transcript.WriteFile(path)
+	}
+	if err != nil {
+		if util.IsVerbose(ctx) {
+			fmt.Printf("  → Failed to save llm judge transcript: %v\n", err)
+		}
+		return
+	}
+
+	result.JudgeTranscriptFile = path
+}
+
+// artifactPath creates the artifact directory and returns the path of the
+// artifact of a task with the given suffix. The name starts with the position
+// of the task in the run, as several tasks of a run can have the same name.
+func (r *evalRunner) artifactPath(result *EvalResult, suffix string) (string, error) {
+	dir := fmt.Sprintf("mcpchecker-%s-artifacts", r.spec.Metadata.Name)
+	if c := r.spec.Config.AgentOutput; c != nil && c.ArtifactDir != "" {
+		dir = c.ArtifactDir
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	safeName := strings.NewReplacer("/", "-", " ", "-", string(filepath.Separator), "-").Replace(result.TaskName)
+	if result.index > 0 {
+		safeName = fmt.Sprintf("%04d-%s", result.index, safeName)
+	}
+	return filepath.Join(dir, safeName+"-"+suffix), nil
+}
+
+// truncateOutput keeps the first and last limit/2 bytes of s around a marker
+// if s is longer than limit. Cuts are moved to UTF-8 character boundaries.
+func truncateOutput(s string, limit int, note string) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+
+	head := limit / 2
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+
+	tail := len(s) - (limit - limit/2)
+	for tail < len(s) && !utf8.RuneStart(s[tail]) {
+		tail++
+	}
+
+	return fmt.Sprintf("%s\n\n[... truncated %d bytes, %s ...]\n\n%s", s[:head], tail-head, note, s[tail:])
+}
@@ -0,0 +1,127 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		limit    int
+		expected string
+	}{
+		"under limit": {
+			input:    "short",
+			limit:    10,
+			expected: "short",
+		},
+		"no limit": {
+			input:    "long output",
+			limit:    0,
+			expected: "long output",
+		},
+		"head and tail": {
+			input:    "0123456789abcdefghij",
+			limit:    8,
+			expected: "0123\n\n[... truncated 12 bytes, see file ...]\n\nghij",
+		},
+		"utf-8 boundaries": {
+			// Each é is two bytes, cuts inside them move outwards
+			input:    "ééééé",
+			limit:    5,
+			expected: "é\n\n[... truncated 6 bytes, see file ...]\n\né",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, truncateOutput(tc.input, tc.limit, "see file"))
+		})
+	}
+}
+
+func TestAgentOutputConfigMaxBytes(t *testing.T) {
+	var unset *AgentOutputConfig
+	assert.Equal(t, DefaultMaxAgentOutputBytes, unset.maxBytes())
+	assert.Equal(t, DefaultMaxAgentOutputBytes, (&AgentOutputConfig{}).maxBytes())
+	assert.Equal(t, 100, (&AgentOutputConfig{MaxBytes: 100}).maxBytes())
+	assert.Equal(t, 0, (&AgentOutputConfig{MaxBytes: -1}).maxBytes())
+}
+
+func TestLimitAgentOutput(t *testing.T) {
+	dir := t.TempDir()
+	output := strings.Repeat("a", 50) + strings.Repeat("b", 50)
+
+	r := &evalRunner{spec: &EvalSpec{
+		Metadata: EvalMetadata{Name: "big"},
+		Config: EvalConfig{
+			AgentOutput: &AgentOutputConfig{MaxBytes: 20, ArtifactDir: dir},
+		},
+	}}
+
+	result := &EvalResult{
+		TaskName:   "tasks/long transcript",
+		TaskOutput: output,
+		AgentOutput: &task.PhaseOutput{
+			Success: true,
+			Steps: []*steps.StepOutput{{
+				Type:    "agent",
+				Success: true,
+				Message: output,
+				Outputs: map[string]string{"output": output},
+			}},
+		},
+	}
+
+	r.limitAgentOutput(result)
+
+	path := filepath.Join(dir, "tasks-long-transcript-output.txt")
+	assert.Equal(t, path, result.TaskOutputFile)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, output, string(data))
+
+	expected := strings.Repeat("a", 10) + "\n\n[... truncated 80 bytes, full output saved to " + path + " ...]\n\n" + strings.Repeat("b", 10)
+	assert.Equal(t, expected, result.TaskOutput)
+	assert.Equal(t, expected, result.AgentOutput.Steps[0].Message)
+	assert.Equal(t, expected, result.AgentOutput.Steps[0].Outputs["output"])
+}
+
+func TestLimitAgentOutputUnderLimit(t *testing.T) {
+	r := &evalRunner{spec: &EvalSpec{}}
+	result := &EvalResult{TaskName: "short", TaskOutput: "done"}
+
+	r.limitAgentOutput(result)
+
+	assert.Equal(t, "done", result.TaskOutput)
+	assert.Empty(t, result.TaskOutputFile)
+}
+
+func TestLimitAgentOutputSameTaskName(t *testing.T) {
+	dir := t.TempDir()
+	r := &evalRunner{spec: &EvalSpec{
+		Config: EvalConfig{
+			AgentOutput: &AgentOutputConfig{MaxBytes: 20, ArtifactDir: dir},
+		},
+	}}
+
+	// Two task sets run a task of the same name
+	first := &EvalResult{TaskName: "check-pod", TaskOutput: strings.Repeat("a", 100), index: 1}
+	second := &EvalResult{TaskName: "check-pod", TaskOutput: strings.Repeat("b", 100), index: 2}
+	r.limitAgentOutput(first)
+	r.limitAgentOutput(second)
+
+	assert.Equal(t, filepath.Join(dir, "0001-check-pod-output.txt"), first.TaskOutputFile)
+	assert.Equal(t, filepath.Join(dir, "0002-check-pod-output.txt"), second.TaskOutputFile)
+	assert.Equal(t, strings.Repeat("a", 100), fullAgentOutput(first))
+	assert.Equal(t, strings.Repeat("b", 100), fullAgentOutput(second))
+}
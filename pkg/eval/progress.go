@@ -1,5 +1,7 @@
 package eval
 
+import "github.com/mcpchecker/mcpchecker/pkg/task"
+
 // ProgressCallback is called during eval execution to report progress
 type ProgressCallback func(event ProgressEvent)
 
@@ -7,7 +9,8 @@ type ProgressCallback func(event ProgressEvent)
 type ProgressEvent struct {
 	Type    ProgressEventType
 	Message string
-	Task    *EvalResult // Populated for task-related events
+	Task    *EvalResult     // Populated for task-related events
+	Step    *task.StepEvent // Populated for step events
 }
 
 // ProgressEventType represents the type of progress event
@@ -15,6 +18,8 @@ type ProgressEventType string
 
 const (
 	EventEvalStart      ProgressEventType = "eval_start"
+	EventTasksSampled   ProgressEventType = "tasks_sampled"
+	EventTasksSharded   ProgressEventType = "tasks_sharded"
 	EventTaskStart      ProgressEventType = "task_start"
 	EventTaskSetup      ProgressEventType = "task_setup"
 	EventTaskRunning    ProgressEventType = "task_running"
@@ -22,7 +27,12 @@ const (
 	EventTaskAssertions ProgressEventType = "task_assertions"
 	EventTaskComplete   ProgressEventType = "task_complete"
 	EventTaskError      ProgressEventType = "task_error"
+	EventTaskSkipped    ProgressEventType = "task_skipped"
+	EventStepStart      ProgressEventType = "step_start"
+	EventStepComplete   ProgressEventType = "step_complete"
 	EventEvalComplete   ProgressEventType = "eval_complete"
+	// EventWarning reports a problem that does not fail the run
+	EventWarning ProgressEventType = "warning"
 )
 
 // NoopProgressCallback is a progress callback that does nothing
@@ -0,0 +1,75 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Quarantine lists tasks that still run and are reported, but whose failures
+// do not count against pass rates or fail verification.
+type Quarantine struct {
+	Tasks []QuarantinedTask `json:"tasks"`
+}
+
+type QuarantinedTask struct {
+	Name string `json:"name"`
+	// Reason records why the task was quarantined, e.g. a link to an issue
+	Reason string `json:"reason,omitempty"`
+}
+
+// LoadQuarantine reads a quarantine file. A missing file is an empty quarantine.
+func LoadQuarantine(path string) (*Quarantine, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Quarantine{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine file: %w", err)
+	}
+
+	q := &Quarantine{}
+	if err := yaml.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("failed to parse quarantine file %s: %w", path, err)
+	}
+
+	return q, nil
+}
+
+// Save writes the quarantine to path as YAML.
+func (q *Quarantine) Save(path string) error {
+	data, err := yaml.Marshal(q)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quarantine: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write quarantine file: %w", err)
+	}
+
+	return nil
+}
+
+// Contains returns true if the task is quarantined. A nil quarantine contains nothing.
+func (q *Quarantine) Contains(name string) bool {
+	if q == nil {
+		return false
+	}
+	for _, t := range q.Tasks {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Add quarantines a task, returning false if it was already quarantined.
+func (q *Quarantine) Add(name, reason string) bool {
+	if q.Contains(name) {
+		return false
+	}
+	q.Tasks = append(q.Tasks, QuarantinedTask{Name: name, Reason: reason})
+	return true
+}
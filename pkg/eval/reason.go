@@ -0,0 +1,114 @@
+package eval
+
+import "regexp"
+
+// ReasonCode identifies why an assertion failed. Codes are stable across
+// releases, so that tools can group, translate, and link documentation for
+// failures without parsing their messages.
+type ReasonCode string
+
+const (
+	ReasonToolNotCalled         ReasonCode = "toolNotCalled"
+	ReasonNoRequiredToolCalled  ReasonCode = "noRequiredToolCalled"
+	ReasonForbiddenToolCalled   ReasonCode = "forbiddenToolCalled"
+	ReasonTooFewToolCalls       ReasonCode = "tooFewToolCalls"
+	ReasonTooManyToolCalls      ReasonCode = "tooManyToolCalls"
+	ReasonTooFewCallsOfTool     ReasonCode = "tooFewCallsOfTool"
+	ReasonTooManyCallsOfTool    ReasonCode = "tooManyCallsOfTool"
+	ReasonToolCallCounts        ReasonCode = "toolCallCountsOutOfBounds"
+	ReasonResourceNotRead       ReasonCode = "resourceNotRead"
+	ReasonForbiddenResourceRead ReasonCode = "forbiddenResourceRead"
+	ReasonPromptNotUsed         ReasonCode = "promptNotUsed"
+	ReasonForbiddenPromptUsed   ReasonCode = "forbiddenPromptUsed"
+	ReasonCallOrder             ReasonCode = "callOrderNotSatisfied"
+	ReasonPhaseCallOutOfOrder   ReasonCode = "phaseCallOutOfOrder"
+	ReasonPhaseCallNotMade      ReasonCode = "phaseCallNotMade"
+	ReasonPhaseViolations       ReasonCode = "phaseViolations"
+	ReasonDuplicateCall         ReasonCode = "duplicateCall"
+	ReasonAgentTooSlow          ReasonCode = "agentTooSlow"
+	ReasonContextTokensUnknown  ReasonCode = "contextTokensUnknown"
+	ReasonTooManyContextTokens  ReasonCode = "tooManyContextTokens"
+	ReasonUngroundedClaim       ReasonCode = "ungroundedClaim"
+	ReasonUngroundedClaims      ReasonCode = "ungroundedClaims"
+	ReasonInvalidExpression     ReasonCode = "invalidExpression"
+	ReasonExpressionError       ReasonCode = "expressionError"
+	ReasonExpressionNotBool     ReasonCode = "expressionNotBool"
+	ReasonExpressionFalse       ReasonCode = "expressionFalse"
+	ReasonExpectationNotMet     ReasonCode = "expectationNotMet"
+	ReasonCustomAssertionFailed ReasonCode = "customAssertionFailed"
+	ReasonCustomAssertionError  ReasonCode = "customAssertionError"
+)
+
+// ReasonMessages are the English messages of the reason codes, which the
+// reasons of assertion results are rendered from. Params of a reason are
+// referred to as {name}. Tools that translate reasons can render their own
+// messages with RenderReason.
+var ReasonMessages = map[ReasonCode]string{
+	ReasonToolNotCalled:         "Required tool not called: server={server}, tool={tool}, pattern={pattern}",
+	ReasonNoRequiredToolCalled:  "None of the required tools were called",
+	ReasonForbiddenToolCalled:   "Forbidden tool was called: server={server}, tool={tool}",
+	ReasonTooFewToolCalls:       "Too few tool calls: expected >= {expected}, got {actual}",
+	ReasonTooManyToolCalls:      "Too many tool calls: expected <= {expected}, got {actual}",
+	ReasonTooFewCallsOfTool:     "Too few calls of server={server}, tool={tool}, pattern={pattern}: expected >= {expected}, got {actual}",
+	ReasonTooManyCallsOfTool:    "Too many calls of server={server}, tool={tool}, pattern={pattern}: expected <= {expected}, got {actual}",
+	ReasonToolCallCounts:        "{count} tool call counts out of bounds",
+	ReasonResourceNotRead:       "Required resource not read: server={server}, uri={uri}, pattern={pattern}",
+	ReasonForbiddenResourceRead: "Forbidden resource read: server={server}, uri={uri}",
+	ReasonPromptNotUsed:         "Required prompt not used: server={server}, prompt={prompt}, pattern={pattern}",
+	ReasonForbiddenPromptUsed:   "Forbidden prompt used: server={server}, prompt={prompt}",
+	ReasonCallOrder:             "Expected call order not satisfied. Got to {reached}/{expected}",
+	ReasonPhaseCallOutOfOrder:   "Call {type} {server}/{name} of phase {phase} made after a call of phase {laterPhase}",
+	ReasonPhaseCallNotMade:      "Call {type} {server}/{name} of phase {phase} not made",
+	ReasonPhaseViolations:       "{count} phase violations",
+	ReasonDuplicateCall:         "Duplicate call detected: {call}",
+	ReasonAgentTooSlow:          "Agent took too long: expected <= {expected}, got {actual}",
+	ReasonContextTokensUnknown:  "Failed to estimate context tokens: {error}",
+	ReasonTooManyContextTokens:  "Tool results added too many tokens to the context: expected <= {expected}, got about {actual}",
+	ReasonUngroundedClaim:       "Ungrounded claim in output: {claim}",
+	ReasonUngroundedClaims:      "{count} ungrounded claims in output: {claims}",
+	ReasonInvalidExpression:     "Invalid expression: {error}",
+	ReasonExpressionError:       "Failed to evaluate expression: {error}",
+	ReasonExpressionNotBool:     "Expression evaluated to {value}, not a bool",
+	ReasonExpressionFalse:       "Expression evaluated to false",
+	ReasonExpectationNotMet:     "Output expectation {expectation} not met",
+	ReasonCustomAssertionFailed: "{message}",
+	ReasonCustomAssertionError:  "{error}",
+}
+
+var reasonParamPattern = regexp.MustCompile(`\{([A-Za-z]+)\}`)
+
+// RenderReason renders a message by replacing each {name} with the param of
+// that name. Placeholders without a param are kept as they are.
+func RenderReason(message string, params map[string]string) string {
+	return reasonParamPattern.ReplaceAllStringFunc(message, func(placeholder string) string {
+		if value, ok := params[placeholder[1:len(placeholder)-1]]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// withParam adds an optional param to a failure, and appends it to the
+// message after sep, if it is set
+func (s *SingleAssertionResult) withParam(name, sep, value string) *SingleAssertionResult {
+	if value != "" {
+		s.Params[name] = value
+		s.Reason += sep + value
+	}
+	return s
+}
+
+// failure returns a failed assertion result with the reason code, its params,
+// and the English message rendered from them
+func failure(code ReasonCode, params map[string]string, details ...string) *SingleAssertionResult {
+	if params == nil {
+		params = make(map[string]string)
+	}
+	return &SingleAssertionResult{
+		Passed:  false,
+		Reason:  RenderReason(ReasonMessages[code], params),
+		Code:    code,
+		Params:  params,
+		Details: details,
+	}
+}
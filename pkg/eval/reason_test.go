@@ -0,0 +1,147 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	extprotocol "github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderReason(t *testing.T) {
+	tests := map[string]struct {
+		message  string
+		params   map[string]string
+		expected string
+	}{
+		"params": {
+			message:  "Too few tool calls: expected >= {expected}, got {actual}",
+			params:   map[string]string{"expected": "3", "actual": "1"},
+			expected: "Too few tool calls: expected >= 3, got 1",
+		},
+		"missing param is kept": {
+			message:  "Duplicate call detected: {call}",
+			expected: "Duplicate call detected: {call}",
+		},
+		"translated message": {
+			message:  "Zu wenige Tool-Aufrufe: {actual} statt mindestens {expected}",
+			params:   map[string]string{"expected": "3", "actual": "1"},
+			expected: "Zu wenige Tool-Aufrufe: 1 statt mindestens 3",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RenderReason(tc.message, tc.params))
+		})
+	}
+}
+
+func TestReasonMessagesCoverCodes(t *testing.T) {
+	codes := []ReasonCode{
+		ReasonToolNotCalled, ReasonNoRequiredToolCalled, ReasonForbiddenToolCalled,
+		ReasonTooFewToolCalls, ReasonTooManyToolCalls, ReasonTooFewCallsOfTool,
+		ReasonTooManyCallsOfTool, ReasonToolCallCounts, ReasonResourceNotRead,
+		ReasonForbiddenResourceRead, ReasonPromptNotUsed, ReasonForbiddenPromptUsed,
+		ReasonCallOrder, ReasonPhaseCallOutOfOrder, ReasonPhaseCallNotMade,
+		ReasonPhaseViolations, ReasonDuplicateCall, ReasonAgentTooSlow,
+		ReasonContextTokensUnknown, ReasonTooManyContextTokens, ReasonUngroundedClaim,
+		ReasonUngroundedClaims, ReasonInvalidExpression, ReasonExpressionError,
+		ReasonExpressionNotBool, ReasonExpressionFalse, ReasonExpectationNotMet,
+		ReasonCustomAssertionFailed, ReasonCustomAssertionError,
+	}
+	assert.Len(t, ReasonMessages, len(codes))
+	for _, code := range codes {
+		assert.NotEmpty(t, ReasonMessages[code], code)
+	}
+}
+
+func TestStructuredFailureReasons(t *testing.T) {
+	min, max := 2, 0
+	history := &mcpproxy.CallHistory{ToolCalls: []*mcpproxy.ToolCall{{
+		CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes"},
+		ToolName:   "pods_list",
+	}}}
+
+	tests := map[string]struct {
+		evaluator SingleAssertionEvaluator
+		code      ReasonCode
+		params    map[string]string
+		reason    string
+	}{
+		"tool not called": {
+			evaluator: NewToolsUsedEvaluator([]ToolAssertion{{Server: "kubernetes", Tool: "pods_get"}}),
+			code:      ReasonToolNotCalled,
+			params:    map[string]string{"server": "kubernetes", "tool": "pods_get", "pattern": ""},
+			reason:    "Required tool not called: server=kubernetes, tool=pods_get, pattern=",
+		},
+		"forbidden tool": {
+			evaluator: NewToolsNotUsedEvaluator([]ToolAssertion{{Server: "kubernetes", Tool: "pods_list"}}),
+			code:      ReasonForbiddenToolCalled,
+			params:    map[string]string{"server": "kubernetes", "tool": "pods_list"},
+			reason:    "Forbidden tool was called: server=kubernetes, tool=pods_list",
+		},
+		"too many tool calls": {
+			evaluator: NewMaxToolCallsEvaluator(0),
+			code:      ReasonTooManyToolCalls,
+			params:    map[string]string{"expected": "0", "actual": "1"},
+			reason:    "Too many tool calls: expected <= 0, got 1",
+		},
+		"one tool call count": {
+			evaluator: NewToolCallCountsEvaluator([]ToolCallCountAssertion{
+				{ToolAssertion: ToolAssertion{Server: "kubernetes", Tool: "pods_list"}, Min: &min},
+			}),
+			code:   ReasonTooFewCallsOfTool,
+			params: map[string]string{"server": "kubernetes", "tool": "pods_list", "pattern": "", "expected": "2", "actual": "1"},
+			reason: "Too few calls of server=kubernetes, tool=pods_list, pattern=: expected >= 2, got 1",
+		},
+		"several tool call counts": {
+			evaluator: NewToolCallCountsEvaluator([]ToolCallCountAssertion{
+				{ToolAssertion: ToolAssertion{Server: "kubernetes", Tool: "pods_list"}, Min: &min},
+				{ToolAssertion: ToolAssertion{Server: "kubernetes", Tool: "pods_list"}, Max: &max},
+			}),
+			code:   ReasonToolCallCounts,
+			params: map[string]string{"count": "2"},
+			reason: "2 tool call counts out of bounds",
+		},
+		"resource variant": {
+			evaluator: NewResourcesReadEvaluator([]ResourceAssertion{{Server: "kubernetes", Template: "k8s://{ns}"}}),
+			code:      ReasonResourceNotRead,
+			params:    map[string]string{"server": "kubernetes", "uri": "", "pattern": "", "variant": "template=k8s://{ns}"},
+			reason:    "Required resource not read: server=kubernetes, uri=, pattern=, template=k8s://{ns}",
+		},
+		"agent too slow": {
+			evaluator: NewMaxAgentDurationEvaluator(time.Minute, 2*time.Minute),
+			code:      ReasonAgentTooSlow,
+			params:    map[string]string{"expected": "1m0s", "actual": "2m0s"},
+			reason:    "Agent took too long: expected <= 1m0s, got 2m0s",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := tc.evaluator.Evaluate(history)
+			assert.False(t, res.Passed)
+			assert.Equal(t, tc.code, res.Code)
+			assert.Equal(t, tc.params, res.Params)
+			assert.Equal(t, tc.reason, res.Reason)
+		})
+	}
+}
+
+func TestCustomFailure(t *testing.T) {
+	res := customFailure(&extprotocol.AssertResult{Reason: "tool was not called"})
+	assert.Equal(t, ReasonCustomAssertionFailed, res.Code)
+	assert.Equal(t, map[string]string{"message": "tool was not called"}, res.Params)
+	assert.Equal(t, "tool was not called", res.Reason)
+
+	res = customFailure(&extprotocol.AssertResult{
+		Reason: "Tool pods_delete is destructive",
+		Code:   "destructiveTool",
+		Params: map[string]string{"tool": "pods_delete"},
+	})
+	assert.Equal(t, ReasonCode("destructiveTool"), res.Code)
+	assert.Equal(t, map[string]string{"tool": "pods_delete"}, res.Params)
+	assert.Equal(t, "Tool pods_delete is destructive", res.Reason)
+}
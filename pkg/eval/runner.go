@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/agent"
 	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
 	"github.com/mcpchecker/mcpchecker/pkg/extension/resolver"
 	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/procmon"
 	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
@@ -19,22 +23,108 @@ import (
 type EvalResult struct {
 	TaskName            string                    `json:"taskName"`
 	TaskPath            string                    `json:"taskPath"`
+	Agent               string                    `json:"agent,omitempty"` // Name of the agent that ran the task
 	TaskPassed          bool                      `json:"taskPassed"`
 	TaskOutput          string                    `json:"taskOutput"`
+	TaskOutputFile      string                    `json:"taskOutputFile,omitempty"` // Full agent output, if TaskOutput was truncated
 	TaskError           string                    `json:"taskError,omitempty"`
 	TaskJudgeReason     string                    `json:"taskJudgeReason,omitempty"`
 	TaskJudgeError      string                    `json:"taskJudgeError,omitempty"`
+	TaskJudgeCategory   string                    `json:"taskJudgeCategory,omitempty"`   // Judge failure category if the judge failed the task
 	AgentExecutionError bool                      `json:"agentExecutionError,omitempty"` // True if agent failed to execute
+	Quarantined         bool                      `json:"quarantined,omitempty"`         // True if failures don't count against pass rates
+	SkipReason          string                    `json:"skipReason,omitempty"`          // Set if the task was skipped instead of run
+	NotRun              bool                      `json:"notRun,omitempty"`              // True if the run stopped before the task, with SkipReason saying why
+	ExpectedFailure     bool                      `json:"expectedFailure,omitempty"`     // True if the task is expected to fail
 	Difficulty          string                    `json:"difficulty"`
+	Priority            string                    `json:"priority,omitempty"`
+	Labels              map[string]string         `json:"labels,omitempty"`
+	Description         string                    `json:"description,omitempty"`
+	Owner               string                    `json:"owner,omitempty"` // Who to ask about the task
+	Links               []task.TaskLink           `json:"links,omitempty"` // Runbooks, issues, or other pages about the task
 	AssertionResults    *CompositeAssertionResult `json:"assertionResults"`
 	AllAssertionsPassed bool                      `json:"allAssertionsPassed"`
 	CallHistory         *mcpproxy.CallHistory     `json:"callHistory"`
+	Timing              *TaskTiming               `json:"timing,omitempty"`
+	TraceID             string                    `json:"traceId,omitempty"`             // Trace ID injected into the MCP servers with mcpTaskMetadata
+	TrafficFile         string                    `json:"trafficFile,omitempty"`         // HAR file of the MCP server traffic, with captureMcpTraffic
+	JudgeTranscriptFile string                    `json:"judgeTranscriptFile,omitempty"` // JSON file of the LLM judge conversations of the task
+	ToolUsage           []mcpproxy.ToolUsage      `json:"toolUsage,omitempty"`           // Calls and payload sizes per tool
+	ContextUsage        *ContextUsage             `json:"contextUsage,omitempty"`        // Estimated tokens the tool results added to the context
+	ResourceUsage       *procmon.Usage            `json:"resourceUsage,omitempty"`       // CPU and memory of the agent process tree
+	SafetyFindings      *SafetyFindings           `json:"safetyFindings,omitempty"`      // Results of the safety scan, with safetyScan
+	AllowedTools        *agent.AllowedTools       `json:"allowedTools,omitempty"`        // Tools the agent was allowed to call, and how it was restricted to them
+	McpServers          []mcpproxy.Compatibility  `json:"mcpServers,omitempty"`          // MCP protocol version each server negotiated
+	Environment         Environment               `json:"environment,omitempty"`         // Environment info reported by the extensions of the run
+	Inputs              *RunInputs                `json:"inputs,omitempty"`              // Resolved eval, task, agent, and MCP server configs, with secrets redacted
 
 	// Phase outputs from task execution
 	SetupOutput   *task.PhaseOutput `json:"setupOutput,omitempty"`
 	AgentOutput   *task.PhaseOutput `json:"agentOutput,omitempty"`
 	VerifyOutput  *task.PhaseOutput `json:"verifyOutput,omitempty"`
 	CleanupOutput *task.PhaseOutput `json:"cleanupOutput,omitempty"`
+
+	// index is the position of the task in the run, counting from 1. It
+	// keeps apart the artifacts of tasks with the same name
+	index int
+}
+
+// TaskStatus is the outcome of a task, taking skipped tasks and expected
+// failures into account
+type TaskStatus string
+
+const (
+	TaskStatusPassed          TaskStatus = "passed"
+	TaskStatusFailed          TaskStatus = "failed"
+	TaskStatusSkipped         TaskStatus = "skipped"
+	TaskStatusExpectedFailure TaskStatus = "expectedFailure"
+	TaskStatusUnexpectedPass  TaskStatus = "unexpectedPass"
+	// TaskStatusNotRun is the status of a task that was not run because the
+	// run stopped after too many failures
+	TaskStatusNotRun TaskStatus = "notRun"
+)
+
+// Status returns the outcome of the task. A task passes when it and all its
+// assertions passed.
+func (r *EvalResult) Status() TaskStatus {
+	passed := r.TaskPassed && r.AllAssertionsPassed
+
+	switch {
+	case r.NotRun:
+		return TaskStatusNotRun
+	case r.SkipReason != "":
+		return TaskStatusSkipped
+	case r.ExpectedFailure && passed:
+		return TaskStatusUnexpectedPass
+	case r.ExpectedFailure:
+		return TaskStatusExpectedFailure
+	case passed:
+		return TaskStatusPassed
+	default:
+		return TaskStatusFailed
+	}
+}
+
+// Counted returns whether the task counts towards pass rates and the exit
+// code. Skipped, quarantined, and expected to fail tasks are not counted.
+func (r *EvalResult) Counted() bool {
+	return r.SkipReason == "" && !r.Quarantined && !r.ExpectedFailure
+}
+
+// TaskTiming records how long each phase of a task took. Phases that did not
+// run are zero.
+type TaskTiming struct {
+	// Started is when the task started, which orders runs by time
+	Started time.Time `json:"started,omitzero"`
+	// Total is the wall time of the whole task, including MCP server startup
+	Total util.Duration `json:"total"`
+	// Setup includes the setup steps and starting the MCP servers
+	Setup util.Duration `json:"setup"`
+	Agent util.Duration `json:"agent,omitempty"`
+	// Verify includes the LLM judge, which is also reported on its own
+	Verify  util.Duration `json:"verify,omitempty"`
+	Judge   util.Duration `json:"judge,omitempty"`
+	Cleanup util.Duration `json:"cleanup,omitempty"`
 }
 
 type EvalRunner interface {
@@ -46,6 +136,77 @@ type evalRunner struct {
 	spec             *EvalSpec
 	mcpConfig        *mcpproxy.MCPConfig
 	progressCallback ProgressCallback
+
+	// agentRunner and judge replace the agent and LLM judge of the spec when set
+	agentRunner agent.Runner
+	judge       llmjudge.LLMJudge
+	// judgeCacheDir caches the verdicts of the LLM judge of the spec when set
+	judgeCacheDir string
+	// sample runs a subset of the tasks when set
+	sample *Sample
+	// shard runs a part of the tasks when set, after they are sampled
+	shard *Shard
+	// maxFailures stops the run after this many failed tasks when set
+	maxFailures int
+	// runID identifies the run in the metadata injected into MCP servers
+	runID string
+	// warned are the warnings reported in the run, which are reported once
+	warned map[string]bool
+	// inputs are the resolved configs of the run, recorded with each task
+	inputs *RunInputs
+}
+
+// RunnerOption customizes an EvalRunner
+type RunnerOption func(*evalRunner)
+
+// WithAgentRunner runs the tasks with an agent runner instead of the agent in
+// the eval config, which then does not need to be set. It also replaces the
+// agents of task sets.
+func WithAgentRunner(runner agent.Runner) RunnerOption {
+	return func(r *evalRunner) {
+		r.agentRunner = runner
+	}
+}
+
+// WithJudge evaluates llmJudge steps with a judge instead of the one
+// configured by llmJudge in the eval config or in task sets.
+func WithJudge(judge llmjudge.LLMJudge) RunnerOption {
+	return func(r *evalRunner) {
+		r.judge = judge
+	}
+}
+
+// WithJudgeCacheDir caches the verdicts of the LLM judge configured in the
+// eval config in dir, so that re-running identical judge calls is free.
+func WithJudgeCacheDir(dir string) RunnerOption {
+	return func(r *evalRunner) {
+		r.judgeCacheDir = dir
+	}
+}
+
+// WithSample runs a sample of the tasks that match the task pattern and
+// label selectors, instead of all of them.
+func WithSample(sample *Sample) RunnerOption {
+	return func(r *evalRunner) {
+		r.sample = sample
+	}
+}
+
+// WithMaxFailures stops the run after n failed tasks. The tasks that are
+// left are recorded as not run. Failures of quarantined tasks and tasks
+// expected to fail are not counted. Zero runs every task.
+func WithMaxFailures(n int) RunnerOption {
+	return func(r *evalRunner) {
+		r.maxFailures = n
+	}
+}
+
+// WithShard runs one of the shards of the tasks that match the task pattern
+// and label selectors. With WithSample, the sample is sharded.
+func WithShard(shard *Shard) RunnerOption {
+	return func(r *evalRunner) {
+		r.shard = shard
+	}
 }
 
 var _ EvalRunner = &evalRunner{}
@@ -54,30 +215,51 @@ type taskConfig struct {
 	path       string
 	spec       *task.TaskConfig
 	assertions *TaskAssertions
+	// custom are the evaluators of the custom assertions of the task set
+	custom []SingleAssertionEvaluator
+	// override replaces the agent runner and judge of the eval
+	override taskSetOverride
+	// index is the position of the task in the run, counting from 1
+	index int
 }
 
 // NewRunner creates a new EvalRunner from an EvalSpec
-func NewRunner(spec *EvalSpec) (EvalRunner, error) {
+func NewRunner(spec *EvalSpec, opts ...RunnerOption) (EvalRunner, error) {
 	if spec == nil {
 		return nil, fmt.Errorf("eval spec cannot be nil")
 	}
 
-	return &evalRunner{
+	r := &evalRunner{
 		spec:             spec,
 		progressCallback: NoopProgressCallback,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
 }
 
 func (r *evalRunner) loadMcpConfig() (*mcpproxy.MCPConfig, error) {
-	// Priority 1: Config file
+	// Priority 1: Config files
+	var files []string
 	if r.spec.Config.McpConfigFile != "" {
-		config, err := mcpproxy.ParseConfigFile(r.spec.Config.McpConfigFile)
+		files = append(files, r.spec.Config.McpConfigFile)
+	}
+	files = append(files, r.spec.Config.McpConfigFiles...)
+
+	if len(files) > 0 {
+		config, err := mcpproxy.LoadConfigFiles(files, r.spec.Config.McpProfile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load MCP config from file: %w", err)
 		}
 		return config, nil
 	}
 
+	if r.spec.Config.McpProfile != "" {
+		return nil, fmt.Errorf("mcpProfile %q requires an MCP config file", r.spec.Config.McpProfile)
+	}
+
 	// Priority 2: Environment variables
 	config, err := mcpproxy.ConfigFromEnv()
 	if err != nil {
@@ -93,35 +275,42 @@ func (r *evalRunner) loadMcpConfig() (*mcpproxy.MCPConfig, error) {
 
 func (r *evalRunner) loadAgentSpec() (*agent.AgentSpec, error) {
 	if r.spec.Config.Agent == nil {
-		return nil, fmt.Errorf("agent must be specified in eval config")
+		return nil, &ConfigError{Err: fmt.Errorf("agent must be specified in eval config or in every task set")}
 	}
+	return loadAgentRefSpec(r.spec.Config.Agent)
+}
 
-	agentRef := r.spec.Config.Agent
+// loadAgentRefSpec loads the spec of the agent an agent ref points to
+func loadAgentRefSpec(agentRef *AgentRef) (*agent.AgentSpec, error) {
 
 	// Handle file-based agent configuration
 	if agentRef.Type == "file" {
 		if agentRef.Path == "" {
-			return nil, fmt.Errorf("path must be specified when agent type is 'file'")
+			return nil, &ConfigError{Err: fmt.Errorf("path must be specified when agent type is 'file'")}
+		}
+		agentSpec, err := agent.LoadWithBuiltins(agentRef.Path)
+		if err != nil {
+			return nil, &ConfigError{Err: err}
 		}
-		return agent.LoadWithBuiltins(agentRef.Path)
+		return agentSpec, nil
 	}
 
 	// Handle builtin agent configuration
 	// Type should be in format "builtin.X" where X is the builtin type
 	const builtinPrefix = "builtin."
 	if len(agentRef.Type) <= len(builtinPrefix) || agentRef.Type[:len(builtinPrefix)] != builtinPrefix {
-		return nil, fmt.Errorf("agent type must be either 'file' or 'builtin.X' format, got: %s", agentRef.Type)
+		return nil, &ConfigError{Err: fmt.Errorf("agent type must be either 'file' or 'builtin.X' format, got: %s", agentRef.Type)}
 	}
 
 	builtinType := agentRef.Type[len(builtinPrefix):]
 	builtinAgent, ok := agent.GetBuiltinType(builtinType)
 	if !ok {
-		return nil, fmt.Errorf("unknown builtin agent type: %s", builtinType)
+		return nil, &ConfigError{Err: fmt.Errorf("unknown builtin agent type: %s", builtinType)}
 	}
 
 	// Enforce model requirement for this builtin type
 	if builtinAgent.RequiresModel() && agentRef.Model == "" {
-		return nil, fmt.Errorf("builtin type '%s' requires a model to be specified", builtinType)
+		return nil, &ConfigError{Err: fmt.Errorf("builtin type '%s' requires a model to be specified", builtinType)}
 	}
 
 	// Validate environment (binaries, env vars, etc.) before using the agent
@@ -135,15 +324,130 @@ func (r *evalRunner) loadAgentSpec() (*agent.AgentSpec, error) {
 		return nil, fmt.Errorf("failed to get defaults for builtin agent %s: %w", builtinType, err)
 	}
 
+	if err := agentSpec.Validate(); err != nil {
+		return nil, &ConfigError{Err: fmt.Errorf("invalid defaults for builtin agent %s: %w", builtinType, err)}
+	}
+
 	return agentSpec, nil
 }
 
+// newAgentRunner returns the agent runner given to NewRunner, or creates one
+// for the agent in the eval config and returns its spec too
+func (r *evalRunner) newAgentRunner() (agent.Runner, *agent.AgentSpec, error) {
+	if r.agentRunner != nil {
+		return r.agentRunner, nil, nil
+	}
+
+	agentSpec, err := r.loadAgentSpec()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load agent spec: %w", err)
+	}
+
+	runner, err := agent.NewRunnerForSpec(agentSpec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create agent runner from spec: %w", err)
+	}
+
+	return runner, agentSpec, nil
+}
+
+// newJudge returns the judge given to NewRunner, or creates one for a judge
+// config
+func (r *evalRunner) newJudge(cfg *llmjudge.LLMJudgeEvalConfig) (llmjudge.LLMJudge, error) {
+	var judgeOpts []llmjudge.Option
+	if r.judgeCacheDir != "" {
+		judgeOpts = append(judgeOpts, llmjudge.WithCacheDir(r.judgeCacheDir))
+	}
+	judge, err := llmjudge.NewLLMJudge(cfg, judgeOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create llm judge from spec: %w", err)
+	}
+	return judge, nil
+}
+
+// taskSetsReplaceAgent reports whether every task set runs its tasks with an
+// agent of its own instead of the one of the eval
+func (r *evalRunner) taskSetsReplaceAgent() bool {
+	if len(r.spec.Config.TaskSets) == 0 {
+		return false
+	}
+	for _, ts := range r.spec.Config.TaskSets {
+		if ts.Agent == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// taskSetOverride is the agent runner and judge that a task set runs its
+// tasks with instead of the ones of the eval. Either is nil if the task set
+// does not replace it.
+type taskSetOverride struct {
+	agentRunner agent.Runner
+	// agentSpec is the spec agentRunner was created from
+	agentSpec *agent.AgentSpec
+	judge     llmjudge.LLMJudge
+}
+
+// newTaskSetOverrides creates the agents and judges of the task sets that
+// replace the ones of the eval, by task set index. Task sets with the same
+// agent share its runner, and every agent is checked before the first task.
+// An agent runner or judge given to NewRunner replaces these too.
+func (r *evalRunner) newTaskSetOverrides(ctx context.Context) ([]taskSetOverride, error) {
+	overrides := make([]taskSetOverride, len(r.spec.Config.TaskSets))
+	runners := make(map[AgentRef]agent.Runner)
+	specs := make(map[AgentRef]*agent.AgentSpec)
+
+	for i, ts := range r.spec.Config.TaskSets {
+		if ts.Agent != nil && r.agentRunner == nil {
+			agentRef, err := ts.Agent.inherit(r.spec.Config.Agent)
+			if err != nil {
+				return nil, &ConfigError{Err: fmt.Errorf("invalid agent in task set at index %d: %w", i, err)}
+			}
+
+			runner, ok := runners[*agentRef]
+			if !ok {
+				agentSpec, err := loadAgentRefSpec(agentRef)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load agent spec of task set at index %d: %w", i, err)
+				}
+				runner, err = agent.NewRunnerForSpec(agentSpec)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create agent runner of task set at index %d: %w", i, err)
+				}
+				if err := agent.CheckHealth(ctx, runner); err != nil {
+					return nil, fmt.Errorf("agent %s of task set at index %d is not runnable: %w", runner.AgentName(), i, err)
+				}
+				runners[*agentRef] = runner
+				specs[*agentRef] = agentSpec
+			}
+			overrides[i].agentRunner = runner
+			overrides[i].agentSpec = specs[*agentRef]
+		}
+
+		if ts.LLMJudge != nil && r.judge == nil {
+			if err := ts.LLMJudge.Validate(); err != nil {
+				return nil, &ConfigError{Err: fmt.Errorf("invalid llmJudge in task set at index %d: %w", i, err)}
+			}
+			judge, err := r.newJudge(ts.LLMJudge)
+			if err != nil {
+				return nil, fmt.Errorf("task set at index %d: %w", i, err)
+			}
+			overrides[i].judge = judge
+		}
+	}
+
+	return overrides, nil
+}
+
 func (r *evalRunner) Run(ctx context.Context, taskPattern string) ([]*EvalResult, error) {
 	return r.RunWithProgress(ctx, taskPattern, NoopProgressCallback)
 }
 
 func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, callback ProgressCallback) ([]*EvalResult, error) {
 	r.progressCallback = callback
+	r.runID = randomID(8)
+	r.warned = make(map[string]bool)
 
 	if taskPattern == "" {
 		taskPattern = "." // match everything (any character matches all task names)
@@ -151,7 +455,18 @@ func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, ca
 
 	taskMatcher, err := regexp.Compile(taskPattern)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile regexp for task name match: %w", err)
+		return nil, &ConfigError{Err: fmt.Errorf("failed to compile regexp for task name match: %w", err)}
+	}
+
+	if r.sample != nil {
+		if err := r.sample.Validate(); err != nil {
+			return nil, &ConfigError{Err: err}
+		}
+	}
+	if r.shard != nil {
+		if err := r.shard.Validate(); err != nil {
+			return nil, &ConfigError{Err: err}
+		}
 	}
 
 	r.progressCallback(ProgressEvent{
@@ -161,24 +476,45 @@ func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, ca
 
 	mcpConfig, err := r.loadMcpConfig()
 	if err != nil {
-		return nil, err
+		return nil, &ConfigError{Err: err}
 	}
 
 	r.mcpConfig = mcpConfig
 
-	agentSpec, err := r.loadAgentSpec()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load agent spec: %w", err)
+	if judgeCfg := r.spec.Config.LLMJudge; r.judge == nil && judgeCfg != nil {
+		if err := judgeCfg.Validate(); err != nil {
+			return nil, &ConfigError{Err: err}
+		}
 	}
 
-	runner, err := agent.NewRunnerForSpec(agentSpec)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create agent runner from spec: %w", err)
+	// The agent of the eval is not needed when every task set has its own
+	var runner agent.Runner
+	var agentSpec *agent.AgentSpec
+	if r.agentRunner != nil || !r.taskSetsReplaceAgent() {
+		runner, agentSpec, err = r.newAgentRunner()
+		if err != nil {
+			return nil, err
+		}
+
+		// Check the agent is runnable before the first task, so that a broken
+		// agent fails the run once instead of failing every task the same way
+		if err := agent.CheckHealth(ctx, runner); err != nil {
+			return nil, fmt.Errorf("agent %s is not runnable: %w", runner.AgentName(), err)
+		}
 	}
+	r.inputs = newRunInputs(r.spec, agentSpec, mcpConfig)
 
-	judge, err := llmjudge.NewLLMJudge(r.spec.Config.LLMJudge)
+	judge := r.judge
+	if judge == nil {
+		judge, err = r.newJudge(r.spec.Config.LLMJudge)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	overrides, err := r.newTaskSetOverrides(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create llm judge from spec: %w", err)
+		return nil, err
 	}
 
 	resolver := resolver.GetResolver(resolver.Options{
@@ -198,19 +534,85 @@ func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, ca
 
 	ctx = llmjudge.WithJudge(ctx, judge)
 
-	taskConfigs, err := r.collectTaskConfigs(taskMatcher)
+	ctx = mcpproxy.CallHistoryLimitsToContext(ctx, r.spec.Config.CallHistory)
+
+	if r.spec.Config.ReuseMcpServers {
+		pool := mcpproxy.NewServerPool()
+		defer pool.Close()
+
+		ctx = mcpproxy.ServerPoolToContext(ctx, pool)
+	}
+
+	taskConfigs, err := r.collectTaskConfigs(ctx, taskMatcher, overrides)
 	if err != nil {
-		return nil, err
+		return nil, &ConfigError{Err: err}
+	}
+
+	if r.sample != nil {
+		total := len(taskConfigs)
+		taskConfigs = r.sample.apply(taskConfigs)
+		r.progressCallback(ProgressEvent{
+			Type:    EventTasksSampled,
+			Message: fmt.Sprintf("Sampled %d of %d tasks (seed %d)", len(taskConfigs), total, r.sample.Seed),
+		})
+	}
+
+	if r.shard != nil {
+		total := len(taskConfigs)
+		taskConfigs = r.shard.apply(taskConfigs)
+		r.progressCallback(ProgressEvent{
+			Type:    EventTasksSharded,
+			Message: fmt.Sprintf("Running shard %s: %d of %d tasks", r.shard, len(taskConfigs), total),
+		})
+	}
+
+	// Critical tasks run first, so that a broken build fails them early
+	sortByPriority(taskConfigs)
+
+	quarantine, err := r.loadQuarantine()
+	if err != nil {
+		return nil, &ConfigError{Err: err}
 	}
 
 	results := make([]*EvalResult, 0, len(taskConfigs))
 	var runErr error
-	for _, tc := range taskConfigs {
+	failures := 0
+	for i, tc := range taskConfigs {
+		tc.index = i + 1
+		if r.maxFailures > 0 && failures >= r.maxFailures {
+			results = append(results, r.notRunTask(tc, fmt.Sprintf("the run stopped after %d failed tasks", failures)))
+			continue
+		}
+
+		skip := tc.spec.Metadata.Skip
+		if skip == "" {
+			skip = tc.spec.UnmetPrecondition(ctx)
+		}
+		if skip != "" {
+			result := r.skipTask(tc, skip)
+			result.Quarantined = quarantine.Contains(result.TaskName)
+			results = append(results, result)
+			continue
+		}
+
 		result, err := r.runTask(ctx, runner, mcpConfig, tc)
 		if err != nil {
 			runErr = errors.Join(runErr, err)
 		} else {
+			result.Quarantined = quarantine.Contains(result.TaskName)
 			results = append(results, result)
+			if result.Status() == TaskStatusFailed && result.Counted() {
+				failures++
+			}
+		}
+	}
+
+	// Extensions start when a task first uses them, so their environment info
+	// is only complete once all tasks ran. It describes the run, and is
+	// recorded with every task so that results files stay self-describing.
+	if environment := Environment(manager.EnvironmentInfo()); environment != nil {
+		for _, result := range results {
+			result.Environment = environment
 		}
 	}
 
@@ -222,13 +624,39 @@ func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, ca
 	return results, runErr
 }
 
-func (r *evalRunner) collectTaskConfigs(rx *regexp.Regexp) ([]taskConfig, error) {
+// loadQuarantine merges the quarantine file with the inline quarantine list
+func (r *evalRunner) loadQuarantine() (*Quarantine, error) {
+	quarantine := &Quarantine{}
+	if r.spec.Config.QuarantineFile != "" {
+		q, err := LoadQuarantine(r.spec.Config.QuarantineFile)
+		if err != nil {
+			return nil, err
+		}
+		quarantine = q
+	}
+
+	for _, t := range r.spec.Config.Quarantine {
+		quarantine.Add(t.Name, t.Reason)
+	}
+
+	return quarantine, nil
+}
+
+func (r *evalRunner) collectTaskConfigs(ctx context.Context, rx *regexp.Regexp, overrides []taskSetOverride) ([]taskConfig, error) {
 	taskConfigs := make([]taskConfig, 0)
 
-	for _, ts := range r.spec.Config.TaskSets {
+	for i, ts := range r.spec.Config.TaskSets {
 		var paths []string
 		var err error
 
+		var custom []SingleAssertionEvaluator
+		if ts.Assertions != nil && len(ts.Assertions.Custom) > 0 {
+			custom, err = DefaultAssertionRegistry.parseCustomAssertions(ctx, ts.Assertions.Custom)
+			if err != nil {
+				return nil, fmt.Errorf("invalid assertions in task set at index %d: %w", i, err)
+			}
+		}
+
 		if ts.Glob != "" {
 			paths, err = filepath.Glob(ts.Glob)
 			if err != nil {
@@ -244,37 +672,119 @@ func (r *evalRunner) collectTaskConfigs(rx *regexp.Regexp) ([]taskConfig, error)
 				return nil, fmt.Errorf("failed to load task at path %s: %w", path, err)
 			}
 
-			if !rx.MatchString(taskSpec.Metadata.Name) {
-				continue
+			// A task with a dataset is a template for a task per row
+			taskSpecs, err := taskSpec.Expand()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load task at path %s: %w", path, err)
 			}
 
-			// Filter by label selector if specified
-			if !matchesLabelSelector(taskSpec.Metadata.Labels, ts.LabelSelector) {
-				continue
+			for _, taskSpec := range taskSpecs {
+				if !rx.MatchString(taskSpec.Metadata.Name) {
+					continue
+				}
+
+				// Filter by label selector if specified
+				if !matchesLabelSelector(taskSpec.Metadata.Labels, ts.LabelSelector) {
+					continue
+				}
+
+				taskConfigs = append(taskConfigs, taskConfig{
+					path:       path,
+					spec:       taskSpec,
+					assertions: ts.Assertions,
+					custom:     custom,
+					override:   overrides[i],
+				})
 			}
-
-			taskConfigs = append(taskConfigs, taskConfig{
-				path:       path,
-				spec:       taskSpec,
-				assertions: ts.Assertions,
-			})
 		}
 	}
 
 	return taskConfigs, nil
 }
 
+// sortByPriority orders tasks by priority, keeping the order of the tasks
+// with the same priority
+func sortByPriority(taskConfigs []taskConfig) {
+	slices.SortStableFunc(taskConfigs, func(a, b taskConfig) int {
+		return task.PriorityRank(a.spec.Metadata.Priority) - task.PriorityRank(b.spec.Metadata.Priority)
+	})
+}
+
+// newTaskResult returns the result of a task with the metadata of the task
+func newTaskResult(tc taskConfig) *EvalResult {
+	return &EvalResult{
+		TaskName:        tc.spec.Metadata.Name,
+		TaskPath:        tc.path,
+		index:           tc.index,
+		ExpectedFailure: tc.spec.Metadata.ExpectedFailure,
+		Difficulty:      tc.spec.Metadata.Difficulty,
+		Priority:        tc.spec.Metadata.Priority,
+		Labels:          tc.spec.Metadata.Labels,
+		Description:     tc.spec.Metadata.Description,
+		Owner:           tc.spec.Metadata.Owner,
+		Links:           tc.spec.Metadata.Links,
+	}
+}
+
+// skipTask returns the result of a task that is skipped instead of run
+func (r *evalRunner) skipTask(tc taskConfig, reason string) *EvalResult {
+	result := newTaskResult(tc)
+	result.Inputs = r.inputs.forTask(tc)
+	result.SkipReason = reason
+
+	r.progressCallback(ProgressEvent{
+		Type:    EventTaskSkipped,
+		Message: fmt.Sprintf("Skipped task: %s (%s)", result.TaskName, result.SkipReason),
+		Task:    result,
+	})
+
+	return result
+}
+
+// notRunTask returns the result of a task that is not run because the run
+// stopped after too many failures
+func (r *evalRunner) notRunTask(tc taskConfig, reason string) *EvalResult {
+	result := newTaskResult(tc)
+	result.Inputs = r.inputs.forTask(tc)
+	result.SkipReason = reason
+	result.NotRun = true
+
+	r.progressCallback(ProgressEvent{
+		Type:    EventTaskSkipped,
+		Message: fmt.Sprintf("Not running task: %s (%s)", result.TaskName, result.SkipReason),
+		Task:    result,
+	})
+
+	return result
+}
+
 func (r *evalRunner) runTask(
 	ctx context.Context,
 	agentRunner agent.Runner,
 	mcpConfig *mcpproxy.MCPConfig,
 	tc taskConfig,
 ) (*EvalResult, error) {
-	result := &EvalResult{
-		TaskName:   tc.spec.Metadata.Name,
-		TaskPath:   tc.path,
-		Difficulty: tc.spec.Metadata.Difficulty,
+	start := time.Now()
+	result := newTaskResult(tc)
+	result.Inputs = r.inputs.forTask(tc)
+	result.Timing = &TaskTiming{Started: start}
+	// Total is only final once cleanup has run, on every return path
+	defer func() { result.Timing.Total = util.Since(start) }()
+
+	if tc.override.agentRunner != nil {
+		agentRunner = tc.override.agentRunner
 	}
+	if tc.override.judge != nil {
+		ctx = llmjudge.WithJudge(ctx, tc.override.judge)
+	}
+	result.Agent = agentRunner.AgentName()
+
+	judgeTranscript := llmjudge.NewTranscriptRecorder()
+	ctx = llmjudge.TranscriptRecorderToContext(ctx, judgeTranscript)
+
+	ctx = task.StepObserverToContext(ctx, func(event task.StepEvent) {
+		r.progressCallback(stepProgressEvent(event, result))
+	})
 
 	r.progressCallback(ProgressEvent{
 		Type:    EventTaskStart,
@@ -289,6 +799,7 @@ func (r *evalRunner) runTask(
 	})
 
 	taskRunner, manager, cleanup, err := r.setupTaskResources(ctx, tc, mcpConfig, result)
+	result.Timing.Setup = util.Since(start) - result.Timing.Cleanup
 	if err != nil {
 		result.TaskPassed = false
 		result.TaskError = err.Error()
@@ -299,9 +810,12 @@ func (r *evalRunner) runTask(
 		})
 		return result, nil
 	}
+	// Cleanup runs even if the task panics. It only runs once, so the call
+	// before completion is reported leaves nothing for this one to do.
 	defer cleanup()
 
 	r.executeTaskSteps(ctx, taskRunner, agentRunner, manager, result)
+	r.writeJudgeTranscriptArtifact(ctx, judgeTranscript, result)
 
 	r.progressCallback(ProgressEvent{
 		Type:    EventTaskAssertions,
@@ -309,9 +823,17 @@ func (r *evalRunner) runTask(
 		Task:    result,
 	})
 
+	result.CallHistory = manager.GetAllCallHistory()
+	result.ToolUsage = mcpproxy.SummarizeToolUsage(result.CallHistory, time.Duration(result.Timing.Agent))
+	result.ContextUsage = estimateContextUsage(ctx, r.spec.Config.Tokenizer, result.CallHistory, result.ToolUsage)
+
 	r.evaluateTaskAssertions(tc, manager, result)
+	r.scanTaskSafety(ctx, manager, result)
+	r.moveSpilledCalls(ctx, result)
 
-	result.CallHistory = manager.GetAllCallHistory()
+	// Run cleanup before reporting completion so that cleanup failures
+	// are visible to progress listeners
+	cleanup()
 
 	r.progressCallback(ProgressEvent{
 		Type:    EventTaskComplete,
@@ -322,42 +844,188 @@ func (r *evalRunner) runTask(
 	return result, nil
 }
 
+// stepProgressEvent converts a step event from the task runner into a progress event
+func stepProgressEvent(event task.StepEvent, result *EvalResult) ProgressEvent {
+	progress := ProgressEvent{
+		Type:    EventStepStart,
+		Message: fmt.Sprintf("Running step %s (%s) for task: %s", event.ID(), event.StepType, result.TaskName),
+		Task:    result,
+		Step:    &event,
+	}
+
+	if event.Type == task.StepEventComplete {
+		progress.Type = EventStepComplete
+		progress.Message = fmt.Sprintf("Completed step %s (%s) for task: %s", event.ID(), event.StepType, result.TaskName)
+	}
+
+	return progress
+}
+
+// taskMCPConfig returns the MCP servers of a task: those of the run with the
+// servers of the task merged on top. A task that lists the servers it needs
+// only gets those, and fails before setup if any of them is missing.
+func taskMCPConfig(tc taskConfig, mcpConfig *mcpproxy.MCPConfig) (*mcpproxy.MCPConfig, error) {
+	if len(tc.spec.Spec.McpServers) > 0 {
+		mcpConfig = mcpConfig.WithOverrides(tc.spec.Spec.McpServers)
+	}
+
+	if required := tc.spec.Spec.RequiredServers(); len(required) > 0 {
+		var err error
+		mcpConfig, err = mcpConfig.WithOnly(required)
+		if err != nil {
+			return nil, fmt.Errorf("task '%s' requires MCP servers: %w", tc.spec.Metadata.Name, err)
+		}
+	}
+
+	return mcpConfig, nil
+}
+
 func (r *evalRunner) setupTaskResources(
 	ctx context.Context,
 	tc taskConfig,
 	mcpConfig *mcpproxy.MCPConfig,
 	result *EvalResult,
 ) (task.TaskRunner, mcpproxy.ServerManager, func(), error) {
+	mcpConfig, err := taskMCPConfig(tc, mcpConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	taskRunner, err := task.NewTaskRunner(ctx, tc.spec)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to create task runner for task '%s': %w", tc.spec.Metadata.Name, err)
 	}
 
-	manager, err := mcpproxy.NewServerManger(ctx, mcpConfig)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create mcp proxy server manager: %w", err)
+	var traffic *mcpproxy.TrafficRecorder
+	if r.spec.Config.CaptureMcpTraffic {
+		traffic = mcpproxy.NewTrafficRecorder()
+		ctx = mcpproxy.TrafficRecorderToContext(ctx, traffic)
 	}
 
-	if err := manager.Start(ctx); err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to start mcp proxy servers: %w", err)
+	var manager mcpproxy.ServerManager
+	var cleanupOnce sync.Once
+	cleanup := func() {
+		cleanupOnce.Do(func() {
+			start := time.Now()
+			cleanupOutput, err := taskRunner.Cleanup(ctx)
+			result.Timing.Cleanup = util.Since(start)
+			result.CleanupOutput = cleanupOutput
+			if err != nil && util.IsVerbose(ctx) {
+				fmt.Printf("  → Cleanup failed: %v\n", err)
+			}
+			if manager != nil {
+				manager.Close()
+			}
+			// The traffic is written once the servers are closed, so that
+			// streamed responses are complete
+			if traffic != nil {
+				r.writeTrafficArtifact(ctx, traffic, result)
+			}
+		})
 	}
 
-	setupOutput, err := taskRunner.Setup(ctx)
-	result.SetupOutput = setupOutput
-	if err != nil {
-		manager.Close()
-		return nil, nil, nil, fmt.Errorf("failed to setup task: %w", err)
+	// Cleanup also runs when setup fails or panics, so partially created
+	// resources are not leaked
+	started := false
+	defer func() {
+		if !started {
+			cleanup()
+		}
+	}()
+
+	setup := func() error {
+		setupOutput, err := taskRunner.Setup(ctx)
+		result.SetupOutput = setupOutput
+		if err != nil {
+			return fmt.Errorf("failed to setup task: %w", err)
+		}
+		return nil
 	}
 
-	cleanup := func() {
-		cleanupOutput, _ := taskRunner.Cleanup(ctx)
-		result.CleanupOutput = cleanupOutput
-		manager.Close()
+	startServers := func() error {
+		mcpConfig := mcpConfig
+		if metadata := r.spec.Config.McpTaskMetadata; metadata != nil {
+			result.TraceID = randomID(16)
+			headers, env, err := metadata.render(&TaskMetadata{
+				Task:    tc.spec.Metadata.Name,
+				Labels:  tc.spec.Metadata.Labels,
+				RunID:   r.runID,
+				TraceID: result.TraceID,
+				SpanID:  randomID(8),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to render mcp task metadata: %w", err)
+			}
+			if r.spec.Config.ReuseMcpServers {
+				env = nil
+			}
+			mcpConfig = mcpConfig.WithMetadata(headers, env)
+		}
+
+		var err error
+		manager, err = mcpproxy.NewServerManger(ctx, mcpConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create mcp proxy server manager: %w", err)
+		}
+		if err := manager.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start mcp proxy servers: %w", err)
+		}
+		return nil
+	}
+
+	// The MCP servers of a task that adds its own start after setup, so that
+	// setup steps can prepare anything they depend on (e.g. fixture servers).
+	// Other tasks start the servers first, so that setup can use them.
+	phases := []func() error{startServers, setup}
+	if len(tc.spec.Spec.McpServers) > 0 {
+		phases = []func() error{setup, startServers}
+	}
+	for _, phase := range phases {
+		if err := phase(); err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
+	started = true
 	return taskRunner, manager, cleanup, nil
 }
 
+// warnOnce reports a warning, unless it was already reported in the run
+func (r *evalRunner) warnOnce(message string, result *EvalResult) {
+	if r.warned[message] {
+		return
+	}
+	if r.warned == nil {
+		r.warned = make(map[string]bool)
+	}
+	r.warned[message] = true
+
+	r.progressCallback(ProgressEvent{
+		Type:    EventWarning,
+		Message: message,
+		Task:    result,
+	})
+}
+
+// warnUnenforcedAllowedTools warns once per agent if the servers do not allow
+// all tools, but the agent cannot be restricted to the allowed ones
+func (r *evalRunner) warnUnenforcedAllowedTools(agentRunner agent.Runner, result *EvalResult) {
+	if result.AllowedTools.Enforced() {
+		return
+	}
+	r.warnOnce(fmt.Sprintf("Agent '%s' cannot be restricted to the allowed tools, so it may call any tool of the MCP servers", agentRunner.AgentName()), result)
+}
+
+// warnIncompatibleServers warns once per server that negotiated a protocol
+// version outside its protocolVersions
+func (r *evalRunner) warnIncompatibleServers(result *EvalResult) {
+	for _, c := range result.McpServers {
+		if err := c.Err(); err != nil {
+			r.warnOnce(fmt.Sprintf("MCP %s", err), result)
+		}
+	}
+}
+
 func (r *evalRunner) executeTaskSteps(
 	ctx context.Context,
 	taskRunner task.TaskRunner,
@@ -372,11 +1040,19 @@ func (r *evalRunner) executeTaskSteps(
 	})
 
 	agentRunner = agentRunner.WithMcpServerInfo(manager)
+	result.AllowedTools = agent.EffectiveAllowedTools(agentRunner, manager.GetMcpServers())
+	r.warnUnenforcedAllowedTools(agentRunner, result)
+	result.McpServers = mcpproxy.CompatibilityOf(manager.GetMcpServers())
+	r.warnIncompatibleServers(result)
 
 	if util.IsVerbose(ctx) {
 		fmt.Printf("  → Agent '%s' is working…\n", agentRunner.AgentName())
 	}
-	agentOutput, err := taskRunner.RunAgent(ctx, agentRunner)
+	monitor := procmon.NewMonitor(r.spec.Config.AgentResources)
+	agentStart := time.Now()
+	agentOutput, err := taskRunner.RunAgent(procmon.ToContext(ctx, monitor), agentRunner)
+	result.Timing.Agent = util.Since(agentStart)
+	result.ResourceUsage = monitor.Usage()
 	result.AgentOutput = agentOutput
 	if err != nil {
 		result.TaskPassed = false
@@ -388,6 +1064,7 @@ func (r *evalRunner) executeTaskSteps(
 				result.TaskOutput = out
 			}
 		}
+		r.limitAgentOutput(result)
 		return
 	}
 
@@ -397,6 +1074,8 @@ func (r *evalRunner) executeTaskSteps(
 			result.TaskOutput = out
 		}
 	}
+	// The verify steps get the full output from the task runner
+	r.limitAgentOutput(result)
 
 	r.progressCallback(ProgressEvent{
 		Type:    EventTaskVerifying,
@@ -404,7 +1083,9 @@ func (r *evalRunner) executeTaskSteps(
 		Task:    result,
 	})
 
+	verifyStart := time.Now()
 	verifyOutput, err := taskRunner.Verify(ctx)
+	result.Timing.Verify = util.Since(verifyStart)
 	result.VerifyOutput = verifyOutput
 	if err != nil {
 		result.TaskPassed = false
@@ -425,6 +1106,12 @@ func (r *evalRunner) extractJudgeResults(verifyOutput *task.PhaseOutput, result
 		return
 	}
 
+	for _, step := range verifyOutput.Steps {
+		if step != nil && step.Type == "llmJudge" {
+			result.Timing.Judge += step.Duration
+		}
+	}
+
 	// Look for llmJudge step outputs and extract their results
 	for _, step := range verifyOutput.Steps {
 		if step == nil || step.Type != "llmJudge" {
@@ -432,25 +1119,70 @@ func (r *evalRunner) extractJudgeResults(verifyOutput *task.PhaseOutput, result
 		}
 		// The judge's reason is in Message for both pass and fail
 		result.TaskJudgeReason = step.Message
+		if !step.Success {
+			result.TaskJudgeCategory = step.Outputs["failureCategory"]
+		}
 		// If there was a judge error (API failure), it would have caused an error return
 		// so we don't need to check for TaskJudgeError here - the verify phase would have failed
 		break // Only capture first llmJudge result
 	}
 }
 
+// taskPrompt returns the prompt of a task, or an empty string if it cannot be
+// read
+func taskPrompt(tc taskConfig) string {
+	if tc.spec == nil || tc.spec.Spec == nil || tc.spec.Spec.Prompt == nil {
+		return ""
+	}
+
+	prompt, err := tc.spec.Spec.Prompt.GetValue()
+	if err != nil {
+		return ""
+	}
+	return prompt
+}
+
 func (r *evalRunner) evaluateTaskAssertions(
 	tc taskConfig,
 	manager mcpproxy.ServerManager,
 	result *EvalResult,
 ) {
+	var assertionResults *CompositeAssertionResult
 	if tc.assertions != nil {
-		evaluator := NewCompositeAssertionEvaluator(tc.assertions)
-		assertionResults := evaluator.Evaluate(manager.GetAllCallHistory())
+		prompt := taskPrompt(tc)
+		history := manager.GetAllCallHistory()
+		evaluator := NewCompositeAssertionEvaluator(tc.assertions, bindAgent(tc.custom, prompt, fullAgentOutput(result))...)
+		assertionResults = evaluator.Evaluate(history)
+
+		// Validated when the eval config is read
+		if maxAgentDuration, err := time.ParseDuration(tc.assertions.MaxAgentDuration); err == nil {
+			assertionResults.MaxAgentDuration = NewMaxAgentDurationEvaluator(maxAgentDuration, time.Duration(result.Timing.Agent)).Evaluate(nil)
+		}
+		if tc.assertions.MaxContextTokens != nil {
+			assertionResults.MaxContextTokens = NewMaxContextTokensEvaluator(*tc.assertions.MaxContextTokens, result.ContextUsage).Evaluate(nil)
+		}
+		if tc.assertions.GroundedOutput.Enabled() {
+			assertionResults.GroundedOutput = NewGroundedOutputEvaluator(tc.assertions.GroundedOutput, prompt, fullAgentOutput(result)).Evaluate(history)
+		}
+		if tc.assertions.Expr != "" {
+			assertionResults.Expr = NewExprEvaluator(tc.assertions.Expr, prompt, result).Evaluate(history)
+		}
+	}
 
-		result.AssertionResults = assertionResults
-		result.AllAssertionsPassed = assertionResults.Succeeded()
-	} else {
+	// Output expectations apply to every task, with or without assertions
+	if expectations := r.spec.Config.OutputExpectations; len(expectations) > 0 {
+		if assertionResults == nil {
+			assertionResults = &CompositeAssertionResult{}
+		}
+		assertionResults.Expectations = evaluateOutputExpectations(expectations, fullAgentOutput(result))
+	}
+
+	if assertionResults == nil {
 		// No assertions = all pass
 		result.AllAssertionsPassed = true
+		return
 	}
+
+	result.AssertionResults = assertionResults
+	result.AllAssertionsPassed = assertionResults.Succeeded()
 }
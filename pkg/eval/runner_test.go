@@ -1,10 +1,18 @@
 package eval
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/resolver"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -147,6 +155,8 @@ func TestLoadAgentSpec(t *testing.T) {
 				if tc.errContains != "" {
 					assert.Contains(t, err.Error(), tc.errContains)
 				}
+				var configErr *ConfigError
+				assert.ErrorAs(t, err, &configErr)
 				return
 			}
 
@@ -330,6 +340,36 @@ func TestLoadMcpConfig(t *testing.T) {
 			expectErr:   true,
 			errContains: "failed to load MCP config from file",
 		},
+		"multiple config files with profile": {
+			setupEnv:   clearEnv,
+			cleanupEnv: clearEnv,
+			spec: &EvalSpec{
+				Config: EvalConfig{
+					McpConfigFile:  "../mcpproxy/testdata/basic.json",
+					McpConfigFiles: []string{"../mcpproxy/testdata/profiles.yaml"},
+					McpProfile:     "staging",
+				},
+			},
+			validateFunc: func(t *testing.T, config *mcpproxy.MCPConfig) {
+				require.NotNil(t, config)
+				assert.Contains(t, config.MCPServers, "filesystem")
+				require.Contains(t, config.MCPServers, "kubernetes")
+				assert.Equal(t, "https://staging.example.com/mcp", config.MCPServers["kubernetes"].URL)
+			},
+		},
+		"error when profile set without config file": {
+			setupEnv: func() {
+				os.Setenv(mcpproxy.EnvMcpURL, "http://localhost:9090/mcp")
+			},
+			cleanupEnv: clearEnv,
+			spec: &EvalSpec{
+				Config: EvalConfig{
+					McpProfile: "staging",
+				},
+			},
+			expectErr:   true,
+			errContains: "requires an MCP config file",
+		},
 		"stdio server from env vars": {
 			setupEnv: func() {
 				os.Setenv(mcpproxy.EnvMcpCommand, "npx")
@@ -382,3 +422,190 @@ func TestLoadMcpConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTaskSetOverrides(t *testing.T) {
+	// The agents are checked against the model API before the first task
+	modelAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	defer modelAPI.Close()
+
+	t.Setenv("MODEL_BASE_URL", modelAPI.URL)
+	t.Setenv("MODEL_KEY", "test-key")
+
+	runner := &evalRunner{spec: &EvalSpec{Config: EvalConfig{
+		Agent: &AgentRef{Type: "builtin.openai-agent", Model: "gpt-4"},
+		TaskSets: []TaskSet{
+			{Path: "default.yaml"},
+			{Path: "model.yaml", Agent: &AgentRef{Model: "gpt-4o"}},
+			{Path: "same-model.yaml", Agent: &AgentRef{Model: "gpt-4o"}},
+			{Path: "agent.yaml", Agent: &AgentRef{Type: "builtin.openai-agent", Model: "o3"}},
+		},
+	}}}
+
+	overrides, err := runner.newTaskSetOverrides(context.Background())
+	require.NoError(t, err)
+	require.Len(t, overrides, 4)
+
+	assert.Nil(t, overrides[0].agentRunner)
+	require.NotNil(t, overrides[1].agentRunner)
+	assert.Equal(t, "openai-agent-gpt-4o", overrides[1].agentRunner.AgentName())
+	assert.Same(t, overrides[1].agentRunner, overrides[2].agentRunner)
+	require.NotNil(t, overrides[3].agentRunner)
+	assert.Equal(t, "openai-agent-o3", overrides[3].agentRunner.AgentName())
+	assert.Nil(t, overrides[0].agentSpec)
+	require.NotNil(t, overrides[1].agentSpec)
+	assert.Equal(t, "gpt-4o", overrides[1].agentSpec.Builtin.Model)
+	assert.Same(t, overrides[1].agentSpec, overrides[2].agentSpec)
+	for _, o := range overrides {
+		assert.Nil(t, o.judge)
+	}
+}
+
+func TestNewTaskSetOverridesErrors(t *testing.T) {
+	tests := map[string]struct {
+		config      EvalConfig
+		errContains string
+	}{
+		"model without eval agent": {
+			config: EvalConfig{TaskSets: []TaskSet{
+				{Path: "task.yaml", Agent: &AgentRef{Model: "gpt-4o"}},
+			}},
+			errContains: "invalid agent in task set at index 0: type must be set when the eval has no agent",
+		},
+		"unknown agent": {
+			config: EvalConfig{TaskSets: []TaskSet{
+				{Path: "task.yaml"},
+				{Path: "task.yaml", Agent: &AgentRef{Type: "builtin.unknown-agent"}},
+			}},
+			errContains: "unknown builtin agent type",
+		},
+		"invalid judge": {
+			config: EvalConfig{TaskSets: []TaskSet{
+				{Path: "task.yaml", LLMJudge: &llmjudge.LLMJudgeEvalConfig{
+					Endpoints: []llmjudge.LLMJudgeEndpointConfig{{Name: "local"}},
+				}},
+			}},
+			errContains: "invalid llmJudge in task set at index 0",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			runner := &evalRunner{spec: &EvalSpec{Config: tc.config}}
+			_, err := runner.newTaskSetOverrides(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errContains)
+			var configErr *ConfigError
+			assert.ErrorAs(t, err, &configErr)
+		})
+	}
+}
+
+func TestRunWithTaskSetAgents(t *testing.T) {
+	t.Setenv("MCP_URL", "http://localhost:1/mcp")
+
+	// Without an eval agent the task sets need an agent type of their own,
+	// and the eval agent is neither required nor checked
+	runner := &evalRunner{spec: &EvalSpec{Config: EvalConfig{TaskSets: []TaskSet{
+		{Path: "a.yaml", Agent: &AgentRef{Model: "gpt-4o"}},
+		{Path: "b.yaml", Agent: &AgentRef{Type: "builtin.openai-agent", Model: "gpt-4o"}},
+	}}}}
+	assert.True(t, runner.taskSetsReplaceAgent())
+	_, err := runner.Run(context.Background(), "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid agent in task set at index 0: type must be set when the eval has no agent")
+
+	// A task set without an agent needs the eval agent
+	runner = &evalRunner{spec: &EvalSpec{Config: EvalConfig{TaskSets: []TaskSet{
+		{Path: "a.yaml", Agent: &AgentRef{Type: "builtin.openai-agent", Model: "gpt-4o"}},
+		{Path: "b.yaml"},
+	}}}}
+	assert.False(t, runner.taskSetsReplaceAgent())
+	_, err = runner.Run(context.Background(), "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "agent must be specified in eval config or in every task set")
+}
+
+func TestSetupTaskResourcesOrder(t *testing.T) {
+	// The server exits without speaking MCP, so it fails to start, and the
+	// marker file shows whether setup ran before it
+	server := &mcpproxy.ServerConfig{Command: "sh", Args: []string{"-c", "exit 1"}}
+
+	tests := map[string]struct {
+		taskServers map[string]*mcpproxy.ServerConfig
+		setupRan    bool
+	}{
+		"eval servers start before setup": {
+			setupRan: false,
+		},
+		"task servers start after setup": {
+			taskServers: map[string]*mcpproxy.ServerConfig{"fixture": server},
+			setupRan:    true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			marker := filepath.Join(dir, "setup-ran")
+			spec, err := task.Read([]byte(`
+apiVersion: mcpchecker/v1alpha2
+kind: Task
+metadata:
+  name: order
+spec:
+  setup:
+    - script:
+        inline: touch `+marker+`
+  cleanup:
+    - script:
+        inline: touch `+filepath.Join(dir, "cleanup-ran")+`
+  prompt:
+    inline: hello
+`), t.TempDir())
+			require.NoError(t, err)
+			spec.Spec.McpServers = tc.taskServers
+
+			runner := &evalRunner{spec: &EvalSpec{}}
+			result := &EvalResult{Timing: &TaskTiming{}}
+			mcpConfig := &mcpproxy.MCPConfig{MCPServers: map[string]*mcpproxy.ServerConfig{"eval": server}}
+			ctx := client.ManagerToContext(context.Background(), client.NewManager(resolver.GetResolver(resolver.Options{}), client.ExtensionOptions{}))
+			_, _, _, err = runner.setupTaskResources(ctx, taskConfig{spec: spec}, mcpConfig, result)
+			require.ErrorContains(t, err, "failed to create mcp proxy server manager")
+
+			_, statErr := os.Stat(marker)
+			assert.Equal(t, tc.setupRan, statErr == nil)
+
+			// Cleanup runs when the servers fail to start
+			assert.FileExists(t, filepath.Join(dir, "cleanup-ran"))
+		})
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	var taskConfigs []taskConfig
+	for _, spec := range []struct{ name, priority string }{
+		{"a", task.PriorityLow},
+		{"b", ""},
+		{"c", task.PriorityCritical},
+		{"d", task.PriorityHigh},
+		{"e", task.PriorityCritical},
+		{"f", task.PriorityNormal},
+	} {
+		taskConfigs = append(taskConfigs, taskConfig{spec: &task.TaskConfig{Metadata: task.TaskMetadata{
+			Name:     spec.name,
+			Priority: spec.priority,
+		}}})
+	}
+
+	sortByPriority(taskConfigs)
+
+	var names []string
+	for _, tc := range taskConfigs {
+		names = append(names, tc.spec.Metadata.Name)
+	}
+	// Tasks with the same priority keep their order, and no priority is normal
+	assert.Equal(t, []string{"c", "e", "d", "b", "f", "a"}, names)
+}
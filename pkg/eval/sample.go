@@ -0,0 +1,169 @@
+package eval
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+const (
+	// StratifyByNone samples the tasks as one group
+	StratifyByNone = "none"
+	// StratifyByDifficulty samples tasks of each difficulty in proportion
+	StratifyByDifficulty = "difficulty"
+	// StratifyByLabelPrefix followed by a label key samples tasks with each
+	// value of the label in proportion
+	StratifyByLabelPrefix = "label:"
+)
+
+// Sample selects a subset of the tasks of a run, so that a smoke run of a
+// large suite only runs a representative part of it
+type Sample struct {
+	// Count is the number of tasks to run. Exactly one of Count or Percent
+	// must be set.
+	Count int
+	// Percent is the percentage of the tasks to run, rounded up
+	Percent float64
+	// Seed selects the tasks. The same seed selects the same tasks of the
+	// same suite.
+	Seed int64
+	// StratifyBy groups the tasks by StratifyByDifficulty or by a label, with
+	// StratifyByLabelPrefix. Each group is sampled in proportion to its size,
+	// and gets at least one task if there are at least as many tasks to run
+	// as groups. If empty or StratifyByNone, the tasks are sampled as one
+	// group.
+	StratifyBy string
+}
+
+// Validate checks that exactly one size is set, and that StratifyBy is known
+func (s *Sample) Validate() error {
+	if (s.Count != 0) == (s.Percent != 0) {
+		return fmt.Errorf("exactly one of the sample count or percent must be set")
+	}
+	if s.Count < 0 {
+		return fmt.Errorf("sample count must be positive, got %d", s.Count)
+	}
+	if s.Percent < 0 || s.Percent > 100 {
+		return fmt.Errorf("sample percent must be between 0 and 100, got %v", s.Percent)
+	}
+
+	switch s.StratifyBy {
+	case "", StratifyByNone, StratifyByDifficulty:
+	default:
+		key, ok := strings.CutPrefix(s.StratifyBy, StratifyByLabelPrefix)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid sample stratification %q, expected %s, %s<key>, or %s", s.StratifyBy, StratifyByDifficulty, StratifyByLabelPrefix, StratifyByNone)
+		}
+	}
+
+	return nil
+}
+
+// size returns the number of tasks to run out of total
+func (s *Sample) size(total int) int {
+	if s.Percent != 0 {
+		return int(math.Ceil(float64(total) * s.Percent / 100))
+	}
+	return min(s.Count, total)
+}
+
+// stratum returns the group a task is sampled in
+func (s *Sample) stratum(spec *task.TaskConfig) string {
+	if s.StratifyBy == StratifyByDifficulty {
+		return spec.Metadata.Difficulty
+	}
+	if key, ok := strings.CutPrefix(s.StratifyBy, StratifyByLabelPrefix); ok {
+		return spec.Metadata.Labels[key]
+	}
+	return ""
+}
+
+// apply returns the sampled tasks, in the order they were given in
+func (s *Sample) apply(tasks []taskConfig) []taskConfig {
+	n := s.size(len(tasks))
+	if n >= len(tasks) {
+		return tasks
+	}
+
+	// Strata are visited in a fixed order, so that the seed alone decides
+	// which tasks are selected
+	strata := make(map[string][]int)
+	for i, tc := range tasks {
+		key := s.stratum(tc.spec)
+		strata[key] = append(strata[key], i)
+	}
+	keys := slices.Sorted(maps.Keys(strata))
+
+	quotas := allocate(n, keys, strata)
+
+	rng := rand.New(rand.NewPCG(uint64(s.Seed), 0))
+	selected := make([]int, 0, n)
+	for _, key := range keys {
+		indices := strata[key]
+		for _, i := range rng.Perm(len(indices))[:quotas[key]] {
+			selected = append(selected, indices[i])
+		}
+	}
+	slices.Sort(selected)
+
+	sampled := make([]taskConfig, 0, len(selected))
+	for _, i := range selected {
+		sampled = append(sampled, tasks[i])
+	}
+	return sampled
+}
+
+// allocate splits n tasks between the strata in proportion to their sizes,
+// giving the remainder to the strata with the largest fractions. If n allows
+// it, each stratum gets at least one task.
+func allocate(n int, keys []string, strata map[string][]int) map[string]int {
+	quotas := make(map[string]int, len(keys))
+
+	// Sizes left to allocate from, and the number of tasks left to allocate
+	sizes := make(map[string]int, len(keys))
+	total := 0
+	for _, key := range keys {
+		sizes[key] = len(strata[key])
+		total += sizes[key]
+	}
+	if n >= len(keys) {
+		for _, key := range keys {
+			quotas[key] = 1
+			sizes[key]--
+		}
+		n -= len(keys)
+		total -= len(keys)
+	}
+	if n == 0 || total == 0 {
+		return quotas
+	}
+
+	type fraction struct {
+		key       string
+		remainder float64
+	}
+	fractions := make([]fraction, 0, len(keys))
+	allocated := 0
+	for _, key := range keys {
+		share := float64(n) * float64(sizes[key]) / float64(total)
+		whole := int(share)
+		quotas[key] += whole
+		allocated += whole
+		fractions = append(fractions, fraction{key, share - float64(whole)})
+	}
+
+	slices.SortStableFunc(fractions, func(a, b fraction) int {
+		return cmp.Compare(b.remainder, a.remainder)
+	})
+	for _, f := range fractions[:n-allocated] {
+		quotas[f.key]++
+	}
+
+	return quotas
+}
@@ -0,0 +1,145 @@
+package eval
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleTasks returns 10 easy tasks, 6 medium tasks, and 4 hard tasks, with
+// a suite label that alternates between a and b
+func sampleTasks() []taskConfig {
+	var tasks []taskConfig
+	counts := []struct {
+		difficulty string
+		count      int
+	}{
+		{task.DifficultyEasy, 10},
+		{task.DifficultyMedium, 6},
+		{task.DifficultyHard, 4},
+	}
+	for _, c := range counts {
+		for i := range c.count {
+			tasks = append(tasks, taskConfig{spec: &task.TaskConfig{Metadata: task.TaskMetadata{
+				Name:       fmt.Sprintf("%s-%d", c.difficulty, i),
+				Difficulty: c.difficulty,
+				Labels:     map[string]string{"suite": []string{"a", "b"}[len(tasks)%2]},
+			}}})
+		}
+	}
+	return tasks
+}
+
+// countBy counts the tasks by the key of their spec
+func countBy(tasks []taskConfig, key func(*task.TaskConfig) string) map[string]int {
+	counts := map[string]int{}
+	for _, tc := range tasks {
+		counts[key(tc.spec)]++
+	}
+	return counts
+}
+
+func difficulty(spec *task.TaskConfig) string { return spec.Metadata.Difficulty }
+
+func TestSampleApply(t *testing.T) {
+	tests := map[string]struct {
+		sample   Sample
+		expected map[string]int
+	}{
+		"count stratified by difficulty": {
+			sample:   Sample{Count: 10, StratifyBy: StratifyByDifficulty},
+			expected: map[string]int{"easy": 5, "medium": 3, "hard": 2},
+		},
+		"percent rounded up": {
+			sample:   Sample{Percent: 12, StratifyBy: StratifyByDifficulty},
+			expected: map[string]int{"easy": 1, "medium": 1, "hard": 1},
+		},
+		"each stratum gets a task": {
+			sample:   Sample{Count: 4, StratifyBy: StratifyByDifficulty},
+			expected: map[string]int{"easy": 2, "medium": 1, "hard": 1},
+		},
+		"count larger than tasks": {
+			sample:   Sample{Count: 50},
+			expected: map[string]int{"easy": 10, "medium": 6, "hard": 4},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sampled := tc.sample.apply(sampleTasks())
+			assert.Equal(t, tc.expected, countBy(sampled, difficulty))
+		})
+	}
+}
+
+func TestSampleByLabel(t *testing.T) {
+	sample := Sample{Count: 6, StratifyBy: StratifyByLabelPrefix + "suite"}
+
+	sampled := sample.apply(sampleTasks())
+	assert.Equal(t, map[string]int{"a": 3, "b": 3}, countBy(sampled, func(spec *task.TaskConfig) string {
+		return spec.Metadata.Labels["suite"]
+	}))
+}
+
+func TestSampleSeed(t *testing.T) {
+	names := func(tasks []taskConfig) []string {
+		var names []string
+		for _, tc := range tasks {
+			names = append(names, tc.spec.Metadata.Name)
+		}
+		return names
+	}
+
+	first := names((&Sample{Count: 5, Seed: 42}).apply(sampleTasks()))
+	require.Len(t, first, 5)
+
+	// The same seed selects the same tasks, in the order of the suite
+	assert.Equal(t, first, names((&Sample{Count: 5, Seed: 42}).apply(sampleTasks())))
+	assert.IsIncreasing(t, indexes(first))
+
+	// Another seed selects other tasks
+	assert.NotEqual(t, first, names((&Sample{Count: 5, Seed: 7}).apply(sampleTasks())))
+}
+
+// indexes returns the positions of the named tasks in sampleTasks
+func indexes(names []string) []int {
+	positions := map[string]int{}
+	for i, tc := range sampleTasks() {
+		positions[tc.spec.Metadata.Name] = i
+	}
+	var indexes []int
+	for _, name := range names {
+		indexes = append(indexes, positions[name])
+	}
+	return indexes
+}
+
+func TestSampleValidate(t *testing.T) {
+	tests := map[string]struct {
+		sample      Sample
+		errContains string
+	}{
+		"count":                {sample: Sample{Count: 5}},
+		"percent by label":     {sample: Sample{Percent: 10, StratifyBy: "label:suite"}},
+		"no size":              {sample: Sample{}, errContains: "exactly one of the sample count or percent must be set"},
+		"count and percent":    {sample: Sample{Count: 5, Percent: 10}, errContains: "exactly one of the sample count or percent must be set"},
+		"negative count":       {sample: Sample{Count: -1}, errContains: "sample count must be positive"},
+		"percent out of range": {sample: Sample{Percent: 150}, errContains: "sample percent must be between 0 and 100"},
+		"unknown stratify":     {sample: Sample{Count: 5, StratifyBy: "suite"}, errContains: `invalid sample stratification "suite"`},
+		"label without key":    {sample: Sample{Count: 5, StratifyBy: "label:"}, errContains: `invalid sample stratification "label:"`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.sample.Validate()
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package eval
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Shard is one of the parts the tasks of a run are split into, so that CI
+// jobs can each run a part of a suite. Tasks are dealt to the shards in turn,
+// in the order they are collected, which spreads tasks of each task set over
+// all shards. Tasks with the same name, such as a task file run by several
+// task sets, go to the same shard, as merging the results of the shards tells
+// tasks apart by name.
+type Shard struct {
+	// Index is the shard to run, counting from 1
+	Index int
+	// Count is the number of shards
+	Count int
+}
+
+// ParseShard parses a shard of the form i/n, like 2/4
+func ParseShard(s string) (*Shard, error) {
+	index, count, ok := strings.Cut(s, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid shard %q, expected i/n", s)
+	}
+
+	shard := &Shard{}
+	var err error
+	if shard.Index, err = strconv.Atoi(strings.TrimSpace(index)); err != nil {
+		return nil, fmt.Errorf("invalid shard %q, expected i/n", s)
+	}
+	if shard.Count, err = strconv.Atoi(strings.TrimSpace(count)); err != nil {
+		return nil, fmt.Errorf("invalid shard %q, expected i/n", s)
+	}
+
+	if err := shard.Validate(); err != nil {
+		return nil, err
+	}
+	return shard, nil
+}
+
+// Validate checks that the index is one of the shards
+func (s *Shard) Validate() error {
+	if s.Count < 1 {
+		return fmt.Errorf("shard count must be at least 1, got %d", s.Count)
+	}
+	if s.Index < 1 || s.Index > s.Count {
+		return fmt.Errorf("shard index must be between 1 and %d, got %d", s.Count, s.Index)
+	}
+	return nil
+}
+
+func (s *Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// apply returns the tasks of the shard, in the order they were given in
+func (s *Shard) apply(tasks []taskConfig) []taskConfig {
+	sharded := make([]taskConfig, 0, len(tasks)/s.Count+1)
+	names := make(map[string]int)
+	for _, tc := range tasks {
+		name := tc.spec.Metadata.Name
+		turn, ok := names[name]
+		if !ok {
+			turn = len(names)
+			names[name] = turn
+		}
+		if turn%s.Count == s.Index-1 {
+			sharded = append(sharded, tc)
+		}
+	}
+	return sharded
+}
@@ -0,0 +1,99 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShard(t *testing.T) {
+	tests := map[string]struct {
+		input       string
+		expected    *Shard
+		errContains string
+	}{
+		"first shard": {
+			input:    "1/4",
+			expected: &Shard{Index: 1, Count: 4},
+		},
+		"last shard": {
+			input:    "4/4",
+			expected: &Shard{Index: 4, Count: 4},
+		},
+		"no count": {
+			input:       "2",
+			errContains: `invalid shard "2", expected i/n`,
+		},
+		"not a number": {
+			input:       "a/4",
+			errContains: `invalid shard "a/4", expected i/n`,
+		},
+		"index out of range": {
+			input:       "5/4",
+			errContains: "shard index must be between 1 and 4, got 5",
+		},
+		"zero index": {
+			input:       "0/4",
+			errContains: "shard index must be between 1 and 4, got 0",
+		},
+		"zero count": {
+			input:       "1/0",
+			errContains: "shard count must be at least 1, got 0",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			shard, err := ParseShard(tc.input)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, shard)
+		})
+	}
+}
+
+func TestShardApply(t *testing.T) {
+	tasks := sampleTasks()
+
+	// Every task is in exactly one shard, and shards differ by at most one task
+	seen := map[string]int{}
+	for index := 1; index <= 3; index++ {
+		sharded := (&Shard{Index: index, Count: 3}).apply(tasks)
+		assert.InDelta(t, len(tasks)/3, len(sharded), 1)
+		for _, tc := range sharded {
+			seen[tc.spec.Metadata.Name]++
+		}
+	}
+	assert.Len(t, seen, len(tasks))
+	for name, count := range seen {
+		assert.Equal(t, 1, count, name)
+	}
+
+	// Tasks are dealt in turn, so each shard gets tasks of each difficulty
+	assert.Equal(t, map[string]int{"easy": 4, "medium": 2, "hard": 1}, countBy((&Shard{Index: 1, Count: 3}).apply(tasks), difficulty))
+}
+
+func TestShardApplySameName(t *testing.T) {
+	// Two task sets run the same tasks
+	tasks := append(sampleTasks()[:5], sampleTasks()[:5]...)
+
+	seen := map[string]int{}
+	for index := 1; index <= 3; index++ {
+		sharded := (&Shard{Index: index, Count: 3}).apply(tasks)
+		names := map[string]bool{}
+		for _, tc := range sharded {
+			names[tc.spec.Metadata.Name] = true
+		}
+		for name := range names {
+			seen[name]++
+		}
+		assert.Len(t, sharded, 2*len(names), "both runs of a task are in the same shard")
+	}
+	for name, count := range seen {
+		assert.Equal(t, 1, count, name)
+	}
+}
@@ -0,0 +1,51 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/resolver"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// RunTaskStep runs one step of a task on its own, like task.RunStep, with the
+// extensions and LLM judge of spec if it is set. Verify steps see the agent
+// output of result, the result of the task in an earlier run, which is read
+// back from the artifact directory if it was truncated.
+func RunTaskStep(ctx context.Context, spec *EvalSpec, taskCfg *task.TaskConfig, stepID string, result *EvalResult) (*steps.StepOutput, error) {
+	var basePath string
+	if spec != nil {
+		basePath = spec.BasePath()
+	}
+
+	manager := client.NewManager(resolver.GetResolver(resolver.Options{BasePath: basePath}), client.ExtensionOptions{})
+	defer manager.ShutdownAll(ctx)
+
+	if spec != nil {
+		for alias, ext := range spec.Config.Extensions {
+			if err := manager.Register(alias, ext); err != nil {
+				return nil, fmt.Errorf("registering extension %q (%s): %w", alias, ext.Package, err)
+			}
+		}
+
+		if spec.Config.LLMJudge != nil {
+			judge, err := llmjudge.NewLLMJudge(spec.Config.LLMJudge)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create llm judge from spec: %w", err)
+			}
+			ctx = llmjudge.WithJudge(ctx, judge)
+		}
+	}
+
+	ctx = client.ManagerToContext(ctx, manager)
+
+	var agentCtx *steps.AgentContext
+	if result != nil {
+		agentCtx = &steps.AgentContext{Output: fullAgentOutput(result)}
+	}
+
+	return task.RunStep(ctx, taskCfg, stepID, agentCtx)
+}
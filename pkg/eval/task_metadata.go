@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"text/template"
+)
+
+// TaskMetadataConfig injects the task into the requests and environment of
+// the MCP servers, so that server-side logs can be correlated with eval tasks.
+// Values are Go templates with the fields of TaskMetadata, such as
+// {{ .Task }} or "00-{{ .TraceID }}-{{ .SpanID }}-01" for a W3C traceparent.
+type TaskMetadataConfig struct {
+	// Headers are added to every request the proxy sends to http and
+	// websocket MCP servers. Headers a server config sets itself are kept.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Env is added to the environment of stdio MCP servers. Variables a
+	// server config sets itself are kept. Env is not injected when
+	// reuseMcpServers is set, since the servers then outlive a task.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// TaskMetadata is the data the templates of a TaskMetadataConfig are
+// executed with
+type TaskMetadata struct {
+	// Task is the name of the task
+	Task string
+	// Labels are the labels of the task. A label the task does not have is
+	// empty.
+	Labels map[string]string
+	// RunID identifies the eval run, and is the same for all its tasks
+	RunID string
+	// TraceID is a random 16-byte ID of the task in hex, recorded in its
+	// result as traceId
+	TraceID string
+	// SpanID is a random 8-byte ID in hex
+	SpanID string
+}
+
+// validate checks that the values of the config are valid templates
+func (c *TaskMetadataConfig) validate() error {
+	_, _, err := c.render(&TaskMetadata{})
+	return err
+}
+
+// render returns the headers and environment variables for a task
+func (c *TaskMetadataConfig) render(data *TaskMetadata) (map[string]string, map[string]string, error) {
+	headers, err := renderMetadata("header", c.Headers, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	env, err := renderMetadata("env", c.Env, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return headers, env, nil
+}
+
+func renderMetadata(kind string, values map[string]string, data *TaskMetadata) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	rendered := make(map[string]string, len(values))
+	for key, value := range values {
+		tmpl, err := template.New(key).Option("missingkey=zero").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s: %w", kind, key, err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("invalid %s %s: %w", kind, key, err)
+		}
+		rendered[key] = buf.String()
+	}
+	return rendered, nil
+}
+
+// randomID returns n random bytes in hex
+func randomID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskMetadataConfigRender(t *testing.T) {
+	cfg := &TaskMetadataConfig{
+		Headers: map[string]string{
+			"X-Mcpchecker-Task": "{{ .Task }}",
+			"X-Team":            "{{ .Labels.team }}",
+			"traceparent":       "00-{{ .TraceID }}-{{ .SpanID }}-01",
+		},
+		Env: map[string]string{
+			"MCPCHECKER_RUN": "{{ .RunID }}/{{ .Task }}",
+		},
+	}
+
+	headers, env, err := cfg.render(&TaskMetadata{
+		Task:    "create-pod",
+		RunID:   "run",
+		TraceID: "trace",
+		SpanID:  "span",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"X-Mcpchecker-Task": "create-pod",
+		"X-Team":            "",
+		"traceparent":       "00-trace-span-01",
+	}, headers)
+	assert.Equal(t, map[string]string{"MCPCHECKER_RUN": "run/create-pod"}, env)
+}
+
+func TestTaskMetadataConfigInvalid(t *testing.T) {
+	tests := map[string]struct {
+		cfg         TaskMetadataConfig
+		errContains string
+	}{
+		"unknown field": {
+			cfg:         TaskMetadataConfig{Headers: map[string]string{"X-Task": "{{ .Name }}"}},
+			errContains: "invalid header X-Task",
+		},
+		"invalid template": {
+			cfg:         TaskMetadataConfig{Env: map[string]string{"TASK": "{{ .Task"}},
+			errContains: "invalid env TASK",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, tc.cfg.validate(), tc.errContains)
+		})
+	}
+}
+
+func TestReadInvalidTaskMetadata(t *testing.T) {
+	_, err := Read([]byte(`
+kind: Eval
+metadata:
+  name: test
+config:
+  mcpTaskMetadata:
+    headers:
+      X-Task: "{{ .Name }}"
+`), t.TempDir())
+	assert.ErrorContains(t, err, "invalid mcpTaskMetadata: invalid header X-Task")
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"sync"
 
@@ -15,6 +16,7 @@ import (
 type Client interface {
 	Start(ctx context.Context, params *protocol.InitializeParams) error
 	Execute(ctx context.Context, params *protocol.ExecuteParams) (*protocol.ExecuteResult, error)
+	Assert(ctx context.Context, params *protocol.AssertParams) (*protocol.AssertResult, error)
 	Manifest() *protocol.InitializeResult
 	Shutdown(ctx context.Context) error
 }
@@ -25,6 +27,10 @@ type client struct {
 	manifest *protocol.InitializeResult
 	opts     Options
 	mux      sync.Mutex
+
+	// exited is closed when the extension process exits, after waitErr is set
+	exited  chan struct{}
+	waitErr error
 }
 
 var _ Client = &client{}
@@ -59,9 +65,19 @@ func (c *client) Start(ctx context.Context, params *protocol.InitializeParams) e
 		return fmt.Errorf("failed to start extension: %w", err)
 	}
 
+	// Wait closes stdout, so it is only called once the connection read all
+	// of it, and the last responses of the extension are not lost
+	framer := newExitFramer(protocol.NewlineFramer())
+	c.exited = make(chan struct{})
+	go func() {
+		<-framer.done
+		c.waitErr = c.cmd.Wait()
+		close(c.exited)
+	}()
+
 	c.conn, err = jsonrpc2.Dial(ctx, &cmdDialer{stdin: stdin, stdout: stdout}, &jsonrpc2.ConnectionOptions{
 		Handler: c,
-		Framer:  protocol.NewlineFramer(),
+		Framer:  framer,
 	})
 	if err != nil {
 		_ = c.cmd.Process.Kill()
@@ -97,22 +113,32 @@ func (c *client) Execute(ctx context.Context, params *protocol.ExecuteParams) (*
 	return result, nil
 }
 
+func (c *client) Assert(ctx context.Context, params *protocol.AssertParams) (*protocol.AssertResult, error) {
+	result := &protocol.AssertResult{}
+	if err := c.call(ctx, protocol.MethodAssert, params, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (c *client) Shutdown(ctx context.Context) error {
 	if err := c.call(ctx, protocol.MethodShutdown, struct{}{}, nil); err != nil {
 		c.closeConn()
-		err = errors.Join(err, c.cmd.Process.Kill())
-		return err
+		// The extension may exit before the request is sent, which counts as
+		// a shutdown if it exited cleanly
+		_ = c.cmd.Process.Kill()
+		<-c.exited
+		if c.waitErr == nil {
+			return nil
+		}
+		return errors.Join(err, c.waitErr)
 	}
 
-	waitDone := make(chan error, 1)
-	go func() {
-		waitDone <- c.cmd.Wait()
-	}()
-
 	select {
-	case err := <-waitDone:
+	case <-c.exited:
 		c.closeConn()
-		return err
+		return c.waitErr
 	case <-ctx.Done():
 		c.closeConn()
 		return c.cmd.Process.Kill()
@@ -145,9 +171,33 @@ func (c *client) initialize(ctx context.Context, params *protocol.InitializePara
 	return result, nil
 }
 
+// call sends a request and waits for its response. The call fails when the
+// extension exits before responding, as the connection does not notice.
 func (c *client) call(ctx context.Context, method string, params, result any) error {
 	c.mux.Lock()
 	defer c.mux.Unlock()
-	call := c.conn.Call(ctx, method, params)
-	return call.Await(ctx, result)
+
+	err := c.conn.Call(ctx, method, params).Await(ctx, result)
+
+	var ended *outputEndedError
+	if !errors.As(err, &ended) {
+		return err
+	}
+	if !errors.Is(ended.err, io.EOF) {
+		return fmt.Errorf("failed to read the response of the extension to %s: %w", method, ended.err)
+	}
+	select {
+	case <-c.exited:
+		return fmt.Errorf("extension exited before responding to %s: %w", method, c.exitErr())
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// exitErr describes how the extension process exited
+func (c *client) exitErr() error {
+	if c.waitErr != nil {
+		return c.waitErr
+	}
+	return errors.New("exit status 0")
 }
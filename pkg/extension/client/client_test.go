@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exitingExtension answers initialize, then answers one execute and exits
+// right after writing the response
+const exitingExtension = `#!/bin/sh
+respond() {
+	read -r line
+	id=$(printf '%s' "$line" | sed 's/.*"id":\([0-9]*\).*/\1/')
+	printf '{"jsonrpc":"2.0","id":%s,"result":%s}\n' "$id" "$1"
+}
+respond '{"protocolVersion":"` + protocol.ProtocolVersion + `"}'
+respond '{"success":true,"message":"last words"}'
+`
+
+func TestClientReadsResponseBeforeExit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "extension")
+	require.NoError(t, os.WriteFile(path, []byte(exitingExtension), 0755))
+
+	for range 20 {
+		c := New(Options{BinaryPath: path})
+		require.NoError(t, c.Start(context.Background(), &protocol.InitializeParams{}))
+
+		result, err := c.Execute(context.Background(), &protocol.ExecuteParams{Operation: "run"})
+		require.NoError(t, err)
+		assert.Equal(t, "last words", result.Message)
+
+		assert.NoError(t, c.Shutdown(context.Background()))
+	}
+}
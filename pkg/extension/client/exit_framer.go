@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"golang.org/x/exp/jsonrpc2"
+)
+
+// exitFramer wraps the framer of the connection to an extension so that the
+// call waiting for a response fails once the output of the extension ends,
+// after every response the extension wrote has been delivered. Calls are
+// made one at a time, so at most one call is waiting.
+type exitFramer struct {
+	jsonrpc2.Framer
+
+	mu      sync.Mutex
+	pending *jsonrpc2.ID
+
+	// done is closed once reading the output stopped
+	done chan struct{}
+}
+
+// outputEndedError fails the waiting call when the output of the extension
+// ends, with the error that ended reading it
+type outputEndedError struct {
+	err error
+}
+
+func (e *outputEndedError) Error() string { return e.err.Error() }
+
+func (e *outputEndedError) Unwrap() error { return e.err }
+
+func newExitFramer(framer jsonrpc2.Framer) *exitFramer {
+	return &exitFramer{Framer: framer, done: make(chan struct{})}
+}
+
+func (f *exitFramer) Reader(r io.Reader) jsonrpc2.Reader {
+	return &exitReader{Reader: f.Framer.Reader(r), framer: f}
+}
+
+func (f *exitFramer) Writer(w io.Writer) jsonrpc2.Writer {
+	return &exitWriter{Writer: f.Framer.Writer(w), framer: f}
+}
+
+// setPending records the call waiting for a response, or clears it for nil
+func (f *exitFramer) setPending(id *jsonrpc2.ID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending = id
+}
+
+// takePending returns and clears the call waiting for a response
+func (f *exitFramer) takePending() *jsonrpc2.ID {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.pending
+	f.pending = nil
+	return id
+}
+
+type exitReader struct {
+	jsonrpc2.Reader
+	framer *exitFramer
+	err    error
+}
+
+// Read returns the next message of the extension. Once there are none, it
+// returns a failed response to the waiting call before the error.
+func (r *exitReader) Read(ctx context.Context) (jsonrpc2.Message, int64, error) {
+	if r.err == nil {
+		msg, n, err := r.Reader.Read(ctx)
+		if err == nil {
+			if _, ok := msg.(*jsonrpc2.Response); ok {
+				r.framer.setPending(nil)
+			}
+			return msg, n, nil
+		}
+		r.err = err
+		close(r.framer.done)
+	}
+
+	if id := r.framer.takePending(); id != nil {
+		return &jsonrpc2.Response{ID: *id, Error: &outputEndedError{err: r.err}}, 0, nil
+	}
+	return nil, 0, r.err
+}
+
+type exitWriter struct {
+	jsonrpc2.Writer
+	framer *exitFramer
+}
+
+func (w *exitWriter) Write(ctx context.Context, msg jsonrpc2.Message) (int64, error) {
+	// The call is pending before it is sent, as the response may be read
+	// before Write returns
+	req, isCall := msg.(*jsonrpc2.Request)
+	if isCall && req.IsCall() {
+		id := req.ID
+		w.framer.setPending(&id)
+	}
+
+	n, err := w.Writer.Write(ctx, msg)
+	if err != nil && isCall && req.IsCall() {
+		w.framer.setPending(nil)
+	}
+	return n, err
+}
@@ -21,6 +21,9 @@ type ExtensionManager interface {
 	Has(alias string) bool
 	// ShutdownAll stops all running extensions
 	ShutdownAll(ctx context.Context) error
+	// EnvironmentInfo returns the environment info of the running extensions
+	// that report any, by alias
+	EnvironmentInfo() map[string]map[string]string
 }
 
 type extensionManager struct {
@@ -130,6 +133,25 @@ func (m *extensionManager) ShutdownAll(ctx context.Context) error {
 	return errors.Join(errs...)
 }
 
+func (m *extensionManager) EnvironmentInfo() map[string]map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var info map[string]map[string]string
+	for alias, c := range m.clients {
+		manifest := c.Manifest()
+		if manifest == nil || len(manifest.EnvironmentInfo) == 0 {
+			continue
+		}
+		if info == nil {
+			info = make(map[string]map[string]string)
+		}
+		info[alias] = manifest.EnvironmentInfo
+	}
+
+	return info
+}
+
 type managerKey struct{}
 
 func ManagerToContext(ctx context.Context, manager ExtensionManager) context.Context {
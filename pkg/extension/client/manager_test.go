@@ -52,6 +52,13 @@ func (m *mockClient) Execute(ctx context.Context, params *protocol.ExecuteParams
 	return &protocol.ExecuteResult{Success: true}, nil
 }
 
+func (m *mockClient) Assert(ctx context.Context, params *protocol.AssertParams) (*protocol.AssertResult, error) {
+	if m.executeErr != nil {
+		return nil, m.executeErr
+	}
+	return &protocol.AssertResult{Passed: true}, nil
+}
+
 func (m *mockClient) Manifest() *protocol.InitializeResult {
 	return m.manifest
 }
@@ -245,3 +252,18 @@ func TestManagerContext(t *testing.T) {
 		})
 	}
 }
+
+func TestExtensionManager_EnvironmentInfo(t *testing.T) {
+	manager := NewManager(&mockResolver{}, ExtensionOptions{}).(*extensionManager)
+	assert.Nil(t, manager.EnvironmentInfo())
+
+	manager.clients["kube"] = &mockClient{manifest: &protocol.InitializeResult{
+		Name:            "kubernetes",
+		EnvironmentInfo: map[string]string{"clusterVersion": "v1.30.2"},
+	}}
+	manager.clients["github"] = &mockClient{manifest: &protocol.InitializeResult{Name: "github"}}
+
+	assert.Equal(t, map[string]map[string]string{
+		"kube": {"clusterVersion": "v1.30.2"},
+	}, manager.EnvironmentInfo())
+}
@@ -1,8 +1,10 @@
 package protocol
 
 import (
+	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 )
@@ -13,6 +15,7 @@ const (
 	MethodInitialize = "initialize"
 	MethodExecute    = "execute"
 	MethodShutdown   = "shutdown"
+	MethodAssert     = "assert"
 	MethodLog        = "log" // notification only
 )
 
@@ -30,6 +33,13 @@ type InitializeResult struct {
 	ProtocolVersion string                `json:"protocolVersion"`
 	Description     string                `json:"description,omitempty"`
 	Operations      map[string]*Operation `json:"operations"`
+	// Assertions are the assertions over the call history the extension
+	// provides, which are evaluated with the "assert" method
+	Assertions map[string]*Operation `json:"assertions,omitempty"`
+	// EnvironmentInfo describes the environment the extension works with,
+	// such as the cluster version or installed CLIs. It is recorded with the
+	// results of the run.
+	EnvironmentInfo map[string]string `json:"environmentInfo,omitempty"`
 }
 
 type Operation struct {
@@ -89,6 +99,63 @@ type ExecuteResult struct {
 	Outputs map[string]string `json:"outputs,omitempty"`
 }
 
+// AssertParams is sent with the "assert" method
+type AssertParams struct {
+	Assertion string        `json:"assertion"`
+	Args      any           `json:"args"` // Args MUST be json serializable
+	Context   AssertContext `json:"context"`
+}
+
+type AssertContext struct {
+	CallHistory CallHistory   `json:"callHistory"`
+	Agent       *AgentContext `json:"agent,omitempty"`
+}
+
+// CallHistory is the MCP calls the agent made during a task, in the order
+// they were made
+type CallHistory struct {
+	ToolCalls     []ToolCall     `json:"toolCalls"`
+	ResourceReads []ResourceRead `json:"resourceReads"`
+	PromptGets    []PromptGet    `json:"promptGets"`
+}
+
+type CallRecord struct {
+	Server    string    `json:"server"`
+	Timestamp time.Time `json:"timestamp"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type ToolCall struct {
+	CallRecord
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	// Result is the MCP CallToolResult
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+type ResourceRead struct {
+	CallRecord
+	URI string `json:"uri"`
+}
+
+type PromptGet struct {
+	CallRecord
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// AssertResult is returned from the "assert" method
+type AssertResult struct {
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason,omitempty"`
+	// Code optionally identifies the reason of a failure, with the Params of
+	// its message, so that failures can be grouped and translated
+	Code    string            `json:"code,omitempty"`
+	Params  map[string]string `json:"params,omitempty"`
+	Details []string          `json:"details,omitempty"`
+}
+
 // LogParams is sent as a notification with the "log" method
 type LogParams struct {
 	Level   string         `json:"level"` // "debug", "info", "warn", "error"
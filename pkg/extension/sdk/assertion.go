@@ -0,0 +1,59 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+)
+
+// AssertionRequest contains the arguments of an assertion and the call history
+// it checks.
+type AssertionRequest struct {
+	// Args contains the arguments of the assertion from the eval file.
+	// These should be unmarshaled into the appropriate type.
+	Args any
+
+	// Context contains the call history of the task and the agent's prompt
+	// and output.
+	Context protocol.AssertContext
+}
+
+// AssertionResult is an alias for the protocol AssertResult.
+type AssertionResult = protocol.AssertResult
+
+// AssertionHandler is a function that evaluates an assertion.
+type AssertionHandler func(ctx context.Context, req *AssertionRequest) (*AssertionResult, error)
+
+// extensionAssertion pairs an assertion definition with its handler.
+type extensionAssertion struct {
+	assertion *Operation
+	handler   AssertionHandler
+}
+
+// AddAssertion registers an assertion over the call history with its handler.
+// Assertions are defined like operations, with a name, description, and
+// params.
+func (e *Extension) AddAssertion(a *Operation, handler AssertionHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.assertions[a.name] = &extensionAssertion{
+		assertion: a,
+		handler:   handler,
+	}
+}
+
+// Pass creates a passed assertion result.
+func Pass() *AssertionResult {
+	return &AssertionResult{Passed: true}
+}
+
+// Fail creates a failed assertion result with the reason it failed and
+// optional details, such as the offending calls.
+func Fail(reason string, details ...string) *AssertionResult {
+	return &AssertionResult{
+		Passed:  false,
+		Reason:  reason,
+		Details: details,
+	}
+}
@@ -57,6 +57,47 @@
 // Return an [OperationResult] indicating success or failure. Use the helper functions
 // [Success], [SuccessWithOutputs], [Failure], and [FailureWithMessage] for convenience.
 //
+// # Assertions
+//
+// Extensions can also provide assertions over the MCP calls the agent made,
+// which evals use as custom assertions named <extension>.<assertion>. Register
+// them with [Extension.AddAssertion]. Handlers receive an [AssertionRequest]
+// with the call history and the agent's prompt and output, and return
+// [Pass] or [Fail]:
+//
+//	ext.AddAssertion(
+//	    sdk.NewOperation("no-destructive-verbs",
+//	        sdk.WithDescription("Fail if the agent called delete tools"),
+//	    ),
+//	    func(ctx context.Context, req *sdk.AssertionRequest) (*sdk.AssertionResult, error) {
+//	        var calls []string
+//	        for _, call := range req.Context.CallHistory.ToolCalls {
+//	            if strings.Contains(call.Name, "delete") {
+//	                calls = append(calls, call.Server+"/"+call.Name)
+//	            }
+//	        }
+//	        if len(calls) > 0 {
+//	            return sdk.Fail("agent used destructive verbs", calls...), nil
+//	        }
+//	        return sdk.Pass(), nil
+//	    },
+//	)
+//
+// # Environment Info
+//
+// Extensions can describe the environment they work with, such as the cluster
+// version or installed CLIs, with [WithEnvironmentInfo]. The info is returned
+// from initialize and recorded with the results of the run, so that results
+// from different environments can be told apart:
+//
+//	ext := sdk.NewExtension(info, sdk.WithEnvironmentInfo(func() (map[string]string, error) {
+//	    version, err := clusterVersion()
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return map[string]string{"clusterVersion": version}, nil
+//	}))
+//
 // # Logging
 //
 // Extensions can send log messages to the client during operation execution:
@@ -18,7 +18,9 @@ type Extension struct {
 	mu           sync.RWMutex
 	info         ExtensionInfo
 	operations   map[string]*extensionOperation
+	assertions   map[string]*extensionAssertion
 	onInitialize InitializeHandler
+	environment  EnvironmentInfoHandler
 
 	// conn is set when the extension is running
 	conn *jsonrpc2.Connection
@@ -38,6 +40,11 @@ type ExtensionInfo struct {
 // InitializeHandler is called when the extension receives an initialize request.
 type InitializeHandler func(config map[string]any) error
 
+// EnvironmentInfoHandler returns information about the environment, such as
+// the cluster version or installed CLIs. It is called after the initialize
+// handler, so it can use the config.
+type EnvironmentInfoHandler func() (map[string]string, error)
+
 // ExtensionOption is a functional option for configuring an Extension.
 type ExtensionOption func(*Extension)
 
@@ -46,6 +53,7 @@ func NewExtension(info ExtensionInfo, opts ...ExtensionOption) *Extension {
 	e := &Extension{
 		info:       info,
 		operations: make(map[string]*extensionOperation),
+		assertions: make(map[string]*extensionAssertion),
 	}
 	for _, opt := range opts {
 		opt(e)
@@ -60,6 +68,14 @@ func WithInitializeHandler(handler InitializeHandler) ExtensionOption {
 	}
 }
 
+// WithEnvironmentInfo sets the handler whose information is returned from
+// initialize and recorded with the results of the run.
+func WithEnvironmentInfo(handler EnvironmentInfoHandler) ExtensionOption {
+	return func(e *Extension) {
+		e.environment = handler
+	}
+}
+
 // initialize calls the initialization handler with the given config.
 // This is used internally for one-shot mode when --config is provided via CLI.
 func (e *Extension) initialize(config map[string]any) error {
@@ -151,6 +167,8 @@ func (e *Extension) Handle(ctx context.Context, req *jsonrpc2.Request) (any, err
 		return e.handleInitialize(ctx, req)
 	case protocol.MethodExecute:
 		return e.handleExecute(ctx, req)
+	case protocol.MethodAssert:
+		return e.handleAssert(ctx, req)
 	case protocol.MethodShutdown:
 		return e.handleShutdown(ctx, req)
 	default:
@@ -177,6 +195,15 @@ func (e *Extension) handleInitialize(_ context.Context, req *jsonrpc2.Request) (
 		}
 	}
 
+	var environment map[string]string
+	if e.environment != nil {
+		var err error
+		environment, err = e.environment()
+		if err != nil {
+			return nil, jsonrpc2.NewError(protocol.CodeInternalError, fmt.Sprintf("failed to get environment info: %v", err))
+		}
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -188,12 +215,25 @@ func (e *Extension) handleInitialize(_ context.Context, req *jsonrpc2.Request) (
 		}
 	}
 
+	var assertions map[string]*protocol.Operation
+	if len(e.assertions) > 0 {
+		assertions = make(map[string]*protocol.Operation, len(e.assertions))
+		for name, a := range e.assertions {
+			assertions[name] = &protocol.Operation{
+				Description: a.assertion.description,
+				Params:      a.assertion.params,
+			}
+		}
+	}
+
 	return &protocol.InitializeResult{
 		Name:            e.info.Name,
 		Version:         e.info.Version,
 		ProtocolVersion: protocol.ProtocolVersion,
 		Description:     e.info.Description,
 		Operations:      operations,
+		Assertions:      assertions,
+		EnvironmentInfo: environment,
 	}, nil
 }
 
@@ -230,6 +270,39 @@ func (e *Extension) handleExecute(ctx context.Context, req *jsonrpc2.Request) (*
 	return result, nil
 }
 
+func (e *Extension) handleAssert(ctx context.Context, req *jsonrpc2.Request) (*protocol.AssertResult, error) {
+	var params protocol.AssertParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, jsonrpc2.NewError(protocol.CodeInvalidParams, fmt.Sprintf("invalid params: %v", err))
+	}
+
+	e.mu.RLock()
+	a, ok := e.assertions[params.Assertion]
+	e.mu.RUnlock()
+
+	if !ok {
+		return &protocol.AssertResult{
+			Passed: false,
+			Reason: fmt.Sprintf("unknown assertion: %s", params.Assertion),
+		}, nil
+	}
+
+	assertReq := &AssertionRequest{
+		Args:    params.Args,
+		Context: params.Context,
+	}
+
+	result, err := a.handler(ctx, assertReq)
+	if err != nil {
+		return &protocol.AssertResult{
+			Passed: false,
+			Reason: err.Error(),
+		}, nil
+	}
+
+	return result, nil
+}
+
 func (e *Extension) handleShutdown(_ context.Context, _ *jsonrpc2.Request) (any, error) {
 	e.mu.Lock()
 	e.shutdown = true
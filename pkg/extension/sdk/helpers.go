@@ -11,14 +11,24 @@ import (
 // This handles the case where args arrive as map[string]any from JSON unmarshaling
 // and need to be converted to a typed struct.
 func UnmarshalArgs[T any](req *OperationRequest) (T, error) {
+	return unmarshalArgs[T](req.Args)
+}
+
+// UnmarshalAssertionArgs unmarshals the assertion request args into the
+// provided type, like UnmarshalArgs.
+func UnmarshalAssertionArgs[T any](req *AssertionRequest) (T, error) {
+	return unmarshalArgs[T](req.Args)
+}
+
+func unmarshalArgs[T any](args any) (T, error) {
 	var result T
 
-	if req.Args == nil {
+	if args == nil {
 		return result, nil
 	}
 
 	// Re-marshal to JSON then unmarshal to the target type
-	data, err := json.Marshal(req.Args)
+	data, err := json.Marshal(args)
 	if err != nil {
 		return result, fmt.Errorf("failed to marshal args: %w", err)
 	}
@@ -0,0 +1,93 @@
+package llmjudge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// EnvCacheDir overrides the directory judge verdicts are cached in
+const EnvCacheDir = "MCPCHECKER_CACHE_DIR"
+
+// DefaultCacheDir returns the directory judge verdicts are cached in by
+// default: judge in $MCPCHECKER_CACHE_DIR if it is set, or mcpchecker/judge
+// in the user cache directory
+func DefaultCacheDir() (string, error) {
+	if dir := os.Getenv(EnvCacheDir); dir != "" {
+		return filepath.Join(dir, "judge"), nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "mcpchecker", "judge"), nil
+}
+
+// verdictCache stores the results of a judge as JSON files named by the hash
+// of the inputs of the judge call
+type verdictCache struct {
+	dir string
+}
+
+// cacheKey returns the key of a judge call. The prompts hold the task prompt,
+// the agent output, and the expected answer, so a change to any of them or to
+// the prompt templates is a different key.
+func cacheKey(baseURL, model, systemPrompt, userPrompt string) string {
+	h := sha256.New()
+	for _, part := range []string{baseURL, model, systemPrompt, userPrompt} {
+		sum := sha256.Sum256([]byte(part))
+		h.Write(sum[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *verdictCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// get returns the cached result for key, if there is one
+func (c *verdictCache) get(key string) (*LLMJudgeResult, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	result := &LLMJudgeResult{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+// put caches the result for key. The file is written under a temporary name
+// and renamed, so that concurrent runs never read a partial result.
+func (c *verdictCache) put(key string, result *LLMJudgeResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
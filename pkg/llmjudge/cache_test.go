@@ -0,0 +1,138 @@
+package llmjudge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startJudgeModel starts a chat completions server that passes every request
+// and counts them
+func startJudgeModel(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion",
+			"created": 0,
+			"model":   "judge",
+			"choices": []map[string]any{{
+				"index":         0,
+				"finish_reason": "tool_calls",
+				"message": map[string]any{
+					"role": "assistant",
+					"tool_calls": []map[string]any{{
+						"id":   "call_1",
+						"type": "function",
+						"function": map[string]any{
+							"name":      "submit_judgement",
+							"arguments": `{"passed":true,"reason":"looks right","failureCategory":"n/a"}`,
+						},
+					}},
+				},
+			}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &requests
+}
+
+func newTestJudge(t *testing.T, url string, opts ...Option) LLMJudge {
+	t.Helper()
+
+	t.Setenv("TEST_JUDGE_BASE_URL", url)
+	t.Setenv("TEST_JUDGE_API_KEY", "key")
+	t.Setenv("TEST_JUDGE_MODEL", "judge")
+
+	judge, err := NewLLMJudge(&LLMJudgeEvalConfig{Env: &LLMJudgeEnvConfig{
+		BaseUrlKey:   "TEST_JUDGE_BASE_URL",
+		ApiKeyKey:    "TEST_JUDGE_API_KEY",
+		ModelNameKey: "TEST_JUDGE_MODEL",
+	}}, opts...)
+	require.NoError(t, err)
+	return judge
+}
+
+func TestJudgeCache(t *testing.T) {
+	step := &LLMJudgeStepConfig{Contains: "nginx-web"}
+
+	tests := map[string]struct {
+		cache            bool
+		outputs          []string
+		expectedRequests int32
+	}{
+		"identical calls are cached": {
+			cache:            true,
+			outputs:          []string{"created nginx-web", "created nginx-web"},
+			expectedRequests: 1,
+		},
+		"different outputs are not": {
+			cache:            true,
+			outputs:          []string{"created nginx-web", "created nginx-web again"},
+			expectedRequests: 2,
+		},
+		"no cache": {
+			outputs:          []string{"created nginx-web", "created nginx-web"},
+			expectedRequests: 2,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			url, requests := startJudgeModel(t)
+
+			var opts []Option
+			if tc.cache {
+				opts = append(opts, WithCacheDir(t.TempDir()))
+			}
+			judge := newTestJudge(t, url, opts...)
+
+			for _, output := range tc.outputs {
+				result, err := judge.EvaluateText(context.Background(), step, "Create a pod", output)
+				require.NoError(t, err)
+				assert.True(t, result.Passed)
+				assert.Equal(t, "looks right", result.Reason)
+			}
+			assert.Equal(t, tc.expectedRequests, requests.Load())
+		})
+	}
+}
+
+func TestJudgeCacheIsSharedBetweenJudges(t *testing.T) {
+	url, requests := startJudgeModel(t)
+	dir := t.TempDir()
+	step := &LLMJudgeStepConfig{Exact: "nginx-web"}
+
+	// A new judge with the same cache directory, like a re-run, reuses the verdict
+	for range 2 {
+		judge := newTestJudge(t, url, WithCacheDir(dir))
+		_, err := judge.EvaluateText(context.Background(), step, "Create a pod", "nginx-web")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	// A different expectation is a different judge call
+	judge := newTestJudge(t, url, WithCacheDir(dir))
+	_, err := judge.EvaluateText(context.Background(), &LLMJudgeStepConfig{Contains: "nginx-web"}, "Create a pod", "nginx-web")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestDefaultCacheDir(t *testing.T) {
+	t.Setenv(EnvCacheDir, "/tmp/mcpchecker-cache")
+
+	dir, err := DefaultCacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/mcpchecker-cache/judge", dir)
+}
@@ -12,6 +12,22 @@ const (
 
 type LLMJudgeEvalConfig struct {
 	Env *LLMJudgeEnvConfig `json:"env,omitempty"`
+
+	// Endpoints lists judge endpoints in priority order, instead of Env. A
+	// judge call goes to the first healthy endpoint, and fails over to the
+	// next one when the call fails.
+	Endpoints []LLMJudgeEndpointConfig `json:"endpoints,omitempty"`
+
+	// Prompts replaces the built-in prompts of the judge
+	Prompts *LLMJudgePromptsConfig `json:"prompts,omitempty"`
+}
+
+// LLMJudgePromptsConfig holds Go templates for the prompts of the judge. A
+// template that is not set keeps the built-in prompt. Templates can use the
+// fields of PromptData, such as {{ .UserPrompt }} and {{ .ModelResponse }}.
+type LLMJudgePromptsConfig struct {
+	System string `json:"system,omitempty"`
+	User   string `json:"user,omitempty"`
 }
 
 type LLMJudgeEnvConfig struct {
@@ -20,11 +36,53 @@ type LLMJudgeEnvConfig struct {
 	ModelNameKey string `json:"modelNameKey"`
 }
 
+// LLMJudgeEndpointConfig is an OpenAI-compatible API the judge can call, such
+// as a local llama.cpp or vLLM server or a hosted API. ApiKeyKey can be left
+// out for local servers that do not check API keys.
+type LLMJudgeEndpointConfig struct {
+	Name string `json:"name,omitempty"`
+	LLMJudgeEnvConfig
+}
+
 type LLMJudgeStepConfig struct {
 	Contains string `json:"contains,omitempty"`
 	Exact    string `json:"exact,omitempty"`
 }
 
+// Validate checks that the judge is configured with either env or endpoints,
+// and that its prompt templates are valid
+func (cfg *LLMJudgeEvalConfig) Validate() error {
+	if cfg.Env != nil && len(cfg.Endpoints) > 0 {
+		return fmt.Errorf("llm judge: only one of env or endpoints can be specified, not both")
+	}
+
+	names := make(map[string]bool)
+	for i, endpoint := range cfg.Endpoints {
+		if endpoint.BaseUrlKey == "" || endpoint.ModelNameKey == "" {
+			return fmt.Errorf("llm judge endpoint %d: baseUrlKey and modelNameKey are required", i)
+		}
+		if endpoint.Name == "" {
+			continue
+		}
+		if names[endpoint.Name] {
+			return fmt.Errorf("llm judge endpoint %d: duplicate name %q", i, endpoint.Name)
+		}
+		names[endpoint.Name] = true
+	}
+
+	if cfg.Prompts != nil {
+		return cfg.Prompts.Validate()
+	}
+	return nil
+}
+
+// Validate checks that the templates parse and only use the fields of
+// PromptData
+func (cfg *LLMJudgePromptsConfig) Validate() error {
+	_, err := newPrompts(cfg)
+	return err
+}
+
 func (cfg *LLMJudgeEvalConfig) BaseUrl() string {
 	return os.Getenv(cfg.Env.BaseUrlKey)
 }
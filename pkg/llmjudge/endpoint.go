@@ -0,0 +1,138 @@
+package llmjudge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+const (
+	// HealthCheckTimeout bounds the health check of a judge endpoint
+	HealthCheckTimeout = 10 * time.Second
+	// EndpointRetryInterval is how long an endpoint that failed is skipped
+	// before it is checked again
+	EndpointRetryInterval = 30 * time.Second
+)
+
+// endpoint is an OpenAI-compatible API the judge can call. Endpoints are
+// shared by tasks running in parallel.
+type endpoint struct {
+	name    string
+	client  openai.Client
+	baseURL string
+	model   string
+
+	mu sync.Mutex
+	// healthy is true once a health check passed, until the endpoint fails
+	healthy bool
+	// downUntil is when an endpoint that failed is tried again
+	downUntil time.Time
+}
+
+// newEndpoint reads the settings of an endpoint from the environment.
+// requireKey is false for endpoints of local servers, which may not check
+// API keys.
+func newEndpoint(name string, env *LLMJudgeEnvConfig, requireKey bool) (*endpoint, error) {
+	baseUrl := os.Getenv(env.BaseUrlKey)
+	apiKey := os.Getenv(env.ApiKeyKey)
+	model := os.Getenv(env.ModelNameKey)
+
+	var missingVars []string
+	if baseUrl == "" {
+		missingVars = append(missingVars, fmt.Sprintf("%s (base URL)", env.BaseUrlKey))
+	}
+	if apiKey == "" && (requireKey || env.ApiKeyKey != "") {
+		missingVars = append(missingVars, fmt.Sprintf("%s (API key)", env.ApiKeyKey))
+	}
+	if model == "" {
+		missingVars = append(missingVars, fmt.Sprintf("%s (model name)", env.ModelNameKey))
+	}
+
+	if len(missingVars) > 0 {
+		if name != "" {
+			return nil, fmt.Errorf("missing required environment variables for LLM judge endpoint %s: %v", name, missingVars)
+		}
+		return nil, fmt.Errorf("missing required environment variables for LLM judge: %v", missingVars)
+	}
+
+	if name == "" {
+		name = baseUrl
+	}
+
+	return &endpoint{
+		name: name,
+		client: openai.NewClient(
+			option.WithBaseURL(baseUrl),
+			option.WithAPIKey(apiKey),
+		),
+		baseURL: baseUrl,
+		model:   model,
+	}, nil
+}
+
+// available reports whether the endpoint is not skipped after a failure
+func (e *endpoint) available(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.downUntil)
+}
+
+// markDown skips the endpoint for EndpointRetryInterval, after which it is
+// checked again before it is used
+func (e *endpoint) markDown() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.healthy = false
+	e.downUntil = time.Now().Add(EndpointRetryInterval)
+}
+
+// ensureHealthy checks the health of the endpoint, unless a check already
+// passed since it last failed
+func (e *endpoint) ensureHealthy(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.healthy {
+		return nil
+	}
+
+	if err := e.checkHealth(ctx); err != nil {
+		e.downUntil = time.Now().Add(EndpointRetryInterval)
+		return err
+	}
+	e.healthy = true
+	return nil
+}
+
+// checkHealth lists the models of the endpoint, which local servers such as
+// llama.cpp and vLLM serve as well as hosted APIs
+func (e *endpoint) checkHealth(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	// A health check that fails is not retried, the endpoint is skipped instead
+	_, err := e.client.Models.List(ctx, option.WithMaxRetries(0))
+
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			return fmt.Errorf("API key was rejected: %w", err)
+		case apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError:
+			return fmt.Errorf("endpoint is unhealthy: %w", err)
+		}
+		// Other errors, such as a gateway that does not list models, do not
+		// tell that judge calls would fail
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reach endpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package llmjudge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEndpoint is an OpenAI-compatible server whose model list and chat
+// completions answer with the given statuses. Chat completions that succeed
+// pass with the reason set to the name of the endpoint.
+type fakeEndpoint struct {
+	url          string
+	modelsStatus int
+	chatStatus   int
+	modelsCalls  atomic.Int32
+	chatCalls    atomic.Int32
+}
+
+func startEndpoint(t *testing.T, name string, modelsStatus, chatStatus int) *fakeEndpoint {
+	t.Helper()
+
+	e := &fakeEndpoint{modelsStatus: modelsStatus, chatStatus: chatStatus}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/models":
+			e.modelsCalls.Add(1)
+			w.WriteHeader(e.modelsStatus)
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"judge","object":"model"}]}`))
+		case "/chat/completions":
+			e.chatCalls.Add(1)
+			if e.chatStatus != http.StatusOK {
+				w.WriteHeader(e.chatStatus)
+				_, _ = w.Write([]byte(`{"error":{"message":"unavailable"}}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":     "chatcmpl-test",
+				"object": "chat.completion",
+				"model":  "judge",
+				"choices": []map[string]any{{
+					"index":         0,
+					"finish_reason": "tool_calls",
+					"message": map[string]any{
+						"role": "assistant",
+						"tool_calls": []map[string]any{{
+							"id":   "call_1",
+							"type": "function",
+							"function": map[string]any{
+								"name":      "submit_judgement",
+								"arguments": `{"passed":true,"reason":"` + name + `","failureCategory":"n/a"}`,
+							},
+						}},
+					},
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	e.url = srv.URL
+	return e
+}
+
+// newFailoverJudge creates a judge with an endpoint named local, without an
+// API key, and an endpoint named hosted
+func newFailoverJudge(t *testing.T, local, hosted string) LLMJudge {
+	t.Helper()
+
+	t.Setenv("LOCAL_JUDGE_URL", local)
+	t.Setenv("HOSTED_JUDGE_URL", hosted)
+	t.Setenv("HOSTED_JUDGE_KEY", "key")
+	t.Setenv("JUDGE_MODEL", "judge")
+
+	judge, err := NewLLMJudge(&LLMJudgeEvalConfig{Endpoints: []LLMJudgeEndpointConfig{
+		{Name: "local", LLMJudgeEnvConfig: LLMJudgeEnvConfig{BaseUrlKey: "LOCAL_JUDGE_URL", ModelNameKey: "JUDGE_MODEL"}},
+		{Name: "hosted", LLMJudgeEnvConfig: LLMJudgeEnvConfig{BaseUrlKey: "HOSTED_JUDGE_URL", ApiKeyKey: "HOSTED_JUDGE_KEY", ModelNameKey: "JUDGE_MODEL"}},
+	}})
+	require.NoError(t, err)
+	return judge
+}
+
+func TestEndpointFailover(t *testing.T) {
+	step := &LLMJudgeStepConfig{Contains: "nginx-web"}
+
+	tests := map[string]struct {
+		localModels     int
+		localChat       int
+		expectedReason  string
+		localChatCalls  int32
+		hostedChatCalls int32
+	}{
+		"healthy local endpoint is used": {
+			localModels:    http.StatusOK,
+			localChat:      http.StatusOK,
+			expectedReason: "local",
+			localChatCalls: 2,
+		},
+		"unhealthy local endpoint is skipped": {
+			localModels:     http.StatusServiceUnavailable,
+			localChat:       http.StatusOK,
+			expectedReason:  "hosted",
+			hostedChatCalls: 2,
+		},
+		"local endpoint that does not list models is used": {
+			localModels:    http.StatusNotFound,
+			localChat:      http.StatusOK,
+			expectedReason: "local",
+			localChatCalls: 2,
+		},
+		"failed call falls over and skips the endpoint": {
+			localModels:    http.StatusOK,
+			localChat:      http.StatusInternalServerError,
+			expectedReason: "hosted",
+			// The client retries the call twice before it falls over
+			localChatCalls:  3,
+			hostedChatCalls: 2,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			local := startEndpoint(t, "local", tc.localModels, tc.localChat)
+			hosted := startEndpoint(t, "hosted", http.StatusOK, http.StatusOK)
+			judge := newFailoverJudge(t, local.url, hosted.url)
+
+			for range 2 {
+				result, err := judge.EvaluateText(context.Background(), step, "Create a pod", "created nginx-web")
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedReason, result.Reason)
+			}
+			assert.Equal(t, tc.localChatCalls, local.chatCalls.Load())
+			assert.Equal(t, tc.hostedChatCalls, hosted.chatCalls.Load())
+
+			// Each endpoint is health checked once
+			assert.Equal(t, int32(1), local.modelsCalls.Load())
+		})
+	}
+}
+
+func TestEndpointFailoverAllFailed(t *testing.T) {
+	local := startEndpoint(t, "local", http.StatusOK, http.StatusInternalServerError)
+
+	// The hosted endpoint is not running
+	srv := httptest.NewServer(http.NotFoundHandler())
+	hosted := srv.URL
+	srv.Close()
+
+	judge := newFailoverJudge(t, local.url, hosted)
+
+	_, err := judge.EvaluateText(context.Background(), &LLMJudgeStepConfig{Contains: "nginx-web"}, "Create a pod", "created nginx-web")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "failed to call llm judge")
+	assert.ErrorContains(t, err, "local: ")
+	assert.ErrorContains(t, err, "hosted: failed to reach endpoint")
+}
+
+func TestEndpointMissingEnv(t *testing.T) {
+	t.Setenv("LOCAL_JUDGE_URL", "http://localhost:8080/v1")
+	t.Setenv("JUDGE_MODEL", "judge")
+
+	_, err := NewLLMJudge(&LLMJudgeEvalConfig{Endpoints: []LLMJudgeEndpointConfig{
+		{Name: "local", LLMJudgeEnvConfig: LLMJudgeEnvConfig{BaseUrlKey: "LOCAL_JUDGE_URL", ModelNameKey: "JUDGE_MODEL"}},
+		{Name: "hosted", LLMJudgeEnvConfig: LLMJudgeEnvConfig{BaseUrlKey: "UNSET_JUDGE_URL", ApiKeyKey: "UNSET_JUDGE_KEY", ModelNameKey: "JUDGE_MODEL"}},
+	}})
+	assert.ErrorContains(t, err, "missing required environment variables for LLM judge endpoint hosted: [UNSET_JUDGE_URL (base URL) UNSET_JUDGE_KEY (API key)]")
+}
+
+func TestLLMJudgeEvalConfigValidate(t *testing.T) {
+	endpoint := LLMJudgeEndpointConfig{Name: "local", LLMJudgeEnvConfig: LLMJudgeEnvConfig{BaseUrlKey: "URL", ModelNameKey: "MODEL"}}
+
+	tests := map[string]struct {
+		cfg         LLMJudgeEvalConfig
+		errContains string
+	}{
+		"env": {
+			cfg: LLMJudgeEvalConfig{Env: &LLMJudgeEnvConfig{BaseUrlKey: "URL", ApiKeyKey: "KEY", ModelNameKey: "MODEL"}},
+		},
+		"endpoints": {
+			cfg: LLMJudgeEvalConfig{Endpoints: []LLMJudgeEndpointConfig{endpoint, {LLMJudgeEnvConfig: endpoint.LLMJudgeEnvConfig}}},
+		},
+		"env and endpoints": {
+			cfg:         LLMJudgeEvalConfig{Env: &LLMJudgeEnvConfig{}, Endpoints: []LLMJudgeEndpointConfig{endpoint}},
+			errContains: "only one of env or endpoints",
+		},
+		"endpoint without model": {
+			cfg:         LLMJudgeEvalConfig{Endpoints: []LLMJudgeEndpointConfig{{Name: "local", LLMJudgeEnvConfig: LLMJudgeEnvConfig{BaseUrlKey: "URL"}}}},
+			errContains: "llm judge endpoint 0: baseUrlKey and modelNameKey are required",
+		},
+		"duplicate names": {
+			cfg:         LLMJudgeEvalConfig{Endpoints: []LLMJudgeEndpointConfig{endpoint, endpoint}},
+			errContains: `llm judge endpoint 1: duplicate name "local"`,
+		},
+		"invalid prompt": {
+			cfg:         LLMJudgeEvalConfig{Endpoints: []LLMJudgeEndpointConfig{endpoint}, Prompts: &LLMJudgePromptsConfig{User: "{{ .Unknown }}"}},
+			errContains: "Unknown",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errContains)
+			}
+		})
+	}
+}
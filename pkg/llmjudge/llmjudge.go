@@ -3,16 +3,32 @@ package llmjudge
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/openai/openai-go/v2"
-	"github.com/openai/openai-go/v2/option"
 )
 
 const (
 	openaiSeed = 0 // allows for consistent eval results
 )
 
+// Failure categories reported by the judge when a response fails
+const (
+	FailureCategorySemanticMismatch   = "semantic_mismatch"
+	FailureCategoryMissingInformation = "missing_information"
+	FailureCategoryContainsExtraInfo  = "contains_extra_info"
+)
+
+// FailureCategories lists the failure categories in a stable order for reporting
+var FailureCategories = []string{
+	FailureCategorySemanticMismatch,
+	FailureCategoryMissingInformation,
+	FailureCategoryContainsExtraInfo,
+}
+
 var (
 	submitJudgementFunction = openai.FunctionDefinitionParam{
 		Name:        "submit_judgement",
@@ -31,12 +47,7 @@ var (
 				"failureCategory": map[string]any{
 					"type":        "string",
 					"description": "If passed is false, specify the reason. Use 'n/a' if passing",
-					"enum": []string{
-						"semantic_mismatch",
-						"missing_information",
-						"contains_extra_info",
-						"n/a",
-					},
+					"enum":        append(slices.Clone(FailureCategories), "n/a"),
 				},
 			},
 			"required": []string{"passed", "reason", "failureCategory"},
@@ -56,8 +67,22 @@ type LLMJudgeResult struct {
 }
 
 type llmJudge struct {
-	client openai.Client
-	model  string
+	// endpoints are tried in order. They are health checked before they are
+	// used only when there is more than one, so there is a fallback.
+	endpoints []*endpoint
+	prompts   *prompts
+	cache     *verdictCache
+}
+
+// Option customizes a judge created by NewLLMJudge
+type Option func(*llmJudge)
+
+// WithCacheDir caches the verdicts of the judge in dir, so that a judge call
+// with the same model and prompts is answered without calling the model again
+func WithCacheDir(dir string) Option {
+	return func(j *llmJudge) {
+		j.cache = &verdictCache{dir: dir}
+	}
 }
 
 type noopLLMJudge struct{}
@@ -74,60 +99,111 @@ func (n *noopLLMJudge) ModelName() string {
 	return "noop"
 }
 
-func NewLLMJudge(cfg *LLMJudgeEvalConfig) (LLMJudge, error) {
+func NewLLMJudge(cfg *LLMJudgeEvalConfig, opts ...Option) (LLMJudge, error) {
 	if cfg == nil {
 		return &noopLLMJudge{}, nil
 	}
-	if cfg.Env == nil {
+	if cfg.Env == nil && len(cfg.Endpoints) == 0 {
 		return nil, fmt.Errorf("llm judge env config is required to create an llm judge")
 	}
-	baseUrl := cfg.BaseUrl()
-	apiKey := cfg.ApiKey()
-	model := cfg.ModelName()
 
-	var missingVars []string
-	if baseUrl == "" {
-		missingVars = append(missingVars, fmt.Sprintf("%s (base URL)", cfg.Env.BaseUrlKey))
-	}
-	if apiKey == "" {
-		missingVars = append(missingVars, fmt.Sprintf("%s (API key)", cfg.Env.ApiKeyKey))
+	var endpoints []*endpoint
+	if cfg.Env != nil {
+		e, err := newEndpoint("", cfg.Env, true)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
 	}
-	if model == "" {
-		missingVars = append(missingVars, fmt.Sprintf("%s (model name)", cfg.Env.ModelNameKey))
+	for _, endpointCfg := range cfg.Endpoints {
+		e, err := newEndpoint(endpointCfg.Name, &endpointCfg.LLMJudgeEnvConfig, false)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
 	}
 
-	if len(missingVars) > 0 {
-		return nil, fmt.Errorf("missing required environment variables for LLM judge: %v", missingVars)
+	prompts, err := newPrompts(cfg.Prompts)
+	if err != nil {
+		return nil, err
 	}
 
-	client := openai.NewClient(
-		option.WithBaseURL(baseUrl),
-		option.WithAPIKey(apiKey),
-	)
+	j := &llmJudge{
+		endpoints: endpoints,
+		prompts:   prompts,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
 
-	return &llmJudge{
-		client: client,
-		model:  model,
-	}, nil
+	return j, nil
 }
 
 func (j *llmJudge) EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepConfig, prompt, output string) (*LLMJudgeResult, error) {
-	systemPrompt, err := BuildSystemPrompt(SystemPromptData{
+	systemPrompt, userPrompt, err := j.prompts.build(PromptData{
 		EvaluationMode:  judgeConfig.EvaluationMode(),
 		ReferenceAnswer: judgeConfig.ReferenceAnswer(),
+		UserPrompt:      prompt,
+		ModelResponse:   output,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	userPrompt, err := BuildUserPrompt(UserPromptData{
-		UserPrompt:    prompt,
-		ModelResponse: output,
-	})
+	transcript := &Transcript{SystemPrompt: systemPrompt, UserPrompt: userPrompt}
+	if recorder, ok := TranscriptRecorderFromContext(ctx); ok {
+		defer recorder.add(transcript)
+	}
+
+	// A verdict cached from any of the endpoints is reused, so that a run
+	// that fell back to another endpoint does not pay again when resumed
+	if j.cache != nil {
+		for _, e := range j.endpoints {
+			if result, ok := j.cache.get(cacheKey(e.baseURL, e.model, systemPrompt, userPrompt)); ok {
+				transcript.Endpoint, transcript.Model = e.name, e.model
+				transcript.Verdict = result
+				transcript.Cached = true
+				return result, nil
+			}
+		}
+	}
+
+	result, e, err := j.evaluate(ctx, systemPrompt, userPrompt, transcript)
 	if err != nil {
+		transcript.Error = err.Error()
 		return nil, err
 	}
+	transcript.Verdict = result
+
+	// A verdict that cannot be cached is still a verdict
+	if j.cache != nil {
+		_ = j.cache.put(cacheKey(e.baseURL, e.model, systemPrompt, userPrompt), result)
+	}
+
+	return result, nil
+}
+
+// candidates returns the endpoints a judge call tries, in order. Endpoints
+// that recently failed are skipped, unless all of them did.
+func (j *llmJudge) candidates() []*endpoint {
+	now := time.Now()
+
+	var candidates []*endpoint
+	for _, e := range j.endpoints {
+		if e.available(now) {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		return j.endpoints
+	}
+	return candidates
+}
 
+// evaluate calls the model with the prompts and returns its verdict, with the
+// endpoint that gave it. A failed call falls over to the next endpoint. The
+// endpoint and the response are recorded in transcript.
+func (j *llmJudge) evaluate(ctx context.Context, systemPrompt, userPrompt string, transcript *Transcript) (*LLMJudgeResult, *endpoint, error) {
 	params := openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage(systemPrompt),
@@ -142,14 +218,51 @@ func (j *llmJudge) EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepCo
 		},
 		ToolChoice: openai.ToolChoiceOptionFunctionToolChoice(openai.ChatCompletionNamedToolChoiceFunctionParam{Name: submitJudgementFunction.Name}),
 		Seed:       openai.Int(openaiSeed),
-		Model:      j.model,
 	}
 
-	completion, err := j.client.Chat.Completions.New(ctx, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call llm judge: %w", err)
+	failover := len(j.endpoints) > 1
+
+	var errs []error
+	for _, e := range j.candidates() {
+		if failover {
+			if err := e.ensureHealthy(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+				continue
+			}
+		}
+
+		params.Model = e.model
+		completion, err := e.client.Chat.Completions.New(ctx, params)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, fmt.Errorf("failed to call llm judge: %w", err)
+			}
+			if failover {
+				e.markDown()
+				err = fmt.Errorf("%s: %w", e.name, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		transcript.Endpoint, transcript.Model = e.name, e.model
+		transcript.setResponse(completion)
+
+		// A response that is not a verdict is the answer of the model, not a
+		// failure of the endpoint, so it does not fall over
+		result, err := parseVerdict(completion)
+		if err != nil {
+			return nil, nil, err
+		}
+		return result, e, nil
 	}
 
+	return nil, nil, fmt.Errorf("failed to call llm judge: %w", errors.Join(errs...))
+}
+
+// parseVerdict returns the arguments of the submit_judgement tool call of a
+// completion
+func parseVerdict(completion *openai.ChatCompletion) (*LLMJudgeResult, error) {
 	if len(completion.Choices) == 0 {
 		return nil, fmt.Errorf("no completion choices returned from LLM")
 	}
@@ -168,7 +281,7 @@ func (j *llmJudge) EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepCo
 
 	result := &LLMJudgeResult{}
 
-	err = json.Unmarshal([]byte(toolCall.Function.Arguments), result)
+	err := json.Unmarshal([]byte(toolCall.Function.Arguments), result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshall '%s' tool call arguments: %w", submitJudgementFunction.Name, err)
 	}
@@ -176,6 +289,7 @@ func (j *llmJudge) EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepCo
 	return result, nil
 }
 
+// ModelName returns the model of the endpoint judge calls currently go to
 func (j *llmJudge) ModelName() string {
-	return j.model
+	return j.candidates()[0].model
 }
@@ -2,12 +2,12 @@ package llmjudge
 
 import (
 	"bytes"
+	"fmt"
 	"text/template"
 )
 
-var (
-	systemPromptTemplate = template.Must(template.New("systemPrompt").Parse(
-		`You are a specialized LLM evaluator. Your **one and only job** is to perform a semantic comparison between a [MODEL_RESPONSE] and a [REFERENCE_ANSWER] based on the **{{.EvaluationMode}}** criterion.
+const (
+	defaultSystemPrompt = `You are a specialized LLM evaluator. Your **one and only job** is to perform a semantic comparison between a [MODEL_RESPONSE] and a [REFERENCE_ANSWER] based on the **{{.EvaluationMode}}** criterion.
 
 ### Your Single Criterion: {{.EvaluationMode}}
 
@@ -43,10 +43,9 @@ You MUST always respond by calling the ` + "`submit_judgement`" + ` tool with:
 - failureCategory: one of the categories listed above
 
 Do not add any conversational text.
-`))
+`
 
-	userPromptTemplate = template.Must(template.New("userPrompt").Parse(
-		`<user_prompt_context>
+	defaultUserPrompt = `<user_prompt_context>
 {{.UserPrompt}}
 </user_prompt_context>
 
@@ -55,36 +54,75 @@ Do not add any conversational text.
 </model_output_to_evaluate>
 
 Evaluate whether the content in <model_output_to_evaluate> contains all the core information from <ground_truth_reference>. Remember to focus on semantic meaning, not exact wording or format.
-`))
+`
+)
+
+var (
+	systemPromptTemplate = template.Must(template.New("systemPrompt").Parse(defaultSystemPrompt))
+	userPromptTemplate   = template.Must(template.New("userPrompt").Parse(defaultUserPrompt))
 )
 
-type SystemPromptData struct {
-	// EvaluationMode should be "CONTAINS" or "EXACT"
-	EvaluationMode  string
+// PromptData is the data the prompt templates of the judge are executed with,
+// including the templates set in the prompts of the eval config
+type PromptData struct {
+	// EvaluationMode is "CONTAINS" or "EXACT"
+	EvaluationMode string
+	// ReferenceAnswer is the expected content, from contains or exact
 	ReferenceAnswer string
+	// UserPrompt is the prompt of the task
+	UserPrompt string
+	// ModelResponse is the output of the agent
+	ModelResponse string
 }
 
-type UserPromptData struct {
-	UserPrompt    string
-	ModelResponse string
+// prompts are the templates of the system and user prompts of a judge
+type prompts struct {
+	system *template.Template
+	user   *template.Template
 }
 
-func BuildSystemPrompt(data SystemPromptData) (string, error) {
-	var out bytes.Buffer
-	err := systemPromptTemplate.Execute(&out, data)
-	if err != nil {
-		return "", err
+// newPrompts parses the templates set in cfg, and uses the built-in template
+// for each one that is not set. Templates are executed once with empty data so
+// that unknown fields are reported before any task runs.
+func newPrompts(cfg *LLMJudgePromptsConfig) (*prompts, error) {
+	p := &prompts{system: systemPromptTemplate, user: userPromptTemplate}
+	if cfg == nil {
+		return p, nil
 	}
 
-	return out.String(), nil
+	var err error
+	if cfg.System != "" {
+		if p.system, err = parsePrompt("system", cfg.System); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.User != "" {
+		if p.user, err = parsePrompt("user", cfg.User); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
 }
 
-func BuildUserPrompt(data UserPromptData) (string, error) {
-	var out bytes.Buffer
-	err := userPromptTemplate.Execute(&out, data)
+func parsePrompt(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Parse(text)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to parse llm judge prompts.%s: %w", name, err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, PromptData{}); err != nil {
+		return nil, fmt.Errorf("invalid llm judge prompts.%s: %w", name, err)
 	}
+	return tmpl, nil
+}
 
-	return out.String(), nil
+// build returns the system and user prompts for data
+func (p *prompts) build(data PromptData) (string, string, error) {
+	var system, user bytes.Buffer
+	if err := p.system.Execute(&system, data); err != nil {
+		return "", "", fmt.Errorf("failed to build llm judge system prompt: %w", err)
+	}
+	if err := p.user.Execute(&user, data); err != nil {
+		return "", "", fmt.Errorf("failed to build llm judge user prompt: %w", err)
+	}
+	return system.String(), user.String(), nil
 }
@@ -0,0 +1,68 @@
+package llmjudge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrompts(t *testing.T) {
+	data := PromptData{
+		EvaluationMode:  EvaluationModeContains,
+		ReferenceAnswer: "nginx-web",
+		UserPrompt:      "Create a pod",
+		ModelResponse:   "Created pod nginx-web",
+	}
+
+	tests := map[string]struct {
+		cfg            *LLMJudgePromptsConfig
+		expectedSystem string
+		expectedUser   string
+		errContains    string
+	}{
+		"custom system prompt": {
+			cfg:            &LLMJudgePromptsConfig{System: "Be lenient. Expect {{ .ReferenceAnswer }} ({{ .EvaluationMode }})."},
+			expectedSystem: "Be lenient. Expect nginx-web (CONTAINS).",
+		},
+		"custom user prompt": {
+			cfg:          &LLMJudgePromptsConfig{User: "Task: {{ .UserPrompt }}\nOutput: {{ .ModelResponse }}"},
+			expectedUser: "Task: Create a pod\nOutput: Created pod nginx-web",
+		},
+		"parse error": {
+			cfg:         &LLMJudgePromptsConfig{User: "{{ .UserPrompt }"},
+			errContains: "failed to parse llm judge prompts.user",
+		},
+		"unknown field": {
+			cfg:         &LLMJudgePromptsConfig{System: "{{ .Expected }}"},
+			errContains: "invalid llm judge prompts.system",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := newPrompts(tc.cfg)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			system, user, err := p.build(data)
+			require.NoError(t, err)
+
+			// Prompts that are not set keep the built-in templates
+			if tc.expectedSystem != "" {
+				assert.Equal(t, tc.expectedSystem, system)
+			} else {
+				assert.Contains(t, system, "<ground_truth_reference>\nnginx-web\n</ground_truth_reference>")
+			}
+			if tc.expectedUser != "" {
+				assert.Equal(t, tc.expectedUser, user)
+			} else {
+				assert.Contains(t, user, "Created pod nginx-web")
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package llmjudge
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Transcript is the conversation of one judge call: the prompts sent to the
+// model, its raw response, and the tool call the verdict was parsed from
+type Transcript struct {
+	// Endpoint is the name of the endpoint that answered, if the judge has
+	// more than one
+	Endpoint     string `json:"endpoint,omitempty"`
+	Model        string `json:"model,omitempty"`
+	SystemPrompt string `json:"systemPrompt"`
+	UserPrompt   string `json:"userPrompt"`
+	// Response is the chat completion as returned by the model
+	Response json.RawMessage `json:"response,omitempty"`
+	// ToolCall is the first tool call of the response
+	ToolCall *TranscriptToolCall `json:"toolCall,omitempty"`
+	Verdict  *LLMJudgeResult     `json:"verdict,omitempty"`
+	// Cached is true if the verdict was read from the verdict cache, in which
+	// case the model was not called
+	Cached bool   `json:"cached,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// TranscriptToolCall is a tool call made by the judge model
+type TranscriptToolCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// setResponse records the response of the model and its first tool call
+func (t *Transcript) setResponse(completion *openai.ChatCompletion) {
+	if raw := completion.RawJSON(); raw != "" && json.Valid([]byte(raw)) {
+		t.Response = json.RawMessage(raw)
+	}
+	if len(completion.Choices) == 0 || len(completion.Choices[0].Message.ToolCalls) == 0 {
+		return
+	}
+	call := completion.Choices[0].Message.ToolCalls[0]
+	t.ToolCall = &TranscriptToolCall{Name: call.Function.Name, Arguments: call.Function.Arguments}
+}
+
+// TranscriptRecorder collects the transcripts of the judge calls made with a
+// context that carries it
+type TranscriptRecorder struct {
+	mu          sync.Mutex
+	transcripts []*Transcript
+}
+
+// NewTranscriptRecorder returns an empty recorder
+func NewTranscriptRecorder() *TranscriptRecorder {
+	return &TranscriptRecorder{}
+}
+
+type transcriptRecorderKey struct{}
+
+// TranscriptRecorderToContext returns a context that carries the given
+// recorder. Judge calls made with this context are recorded in it.
+func TranscriptRecorderToContext(ctx context.Context, recorder *TranscriptRecorder) context.Context {
+	return context.WithValue(ctx, transcriptRecorderKey{}, recorder)
+}
+
+// TranscriptRecorderFromContext returns the recorder stored in ctx, if any
+func TranscriptRecorderFromContext(ctx context.Context) (*TranscriptRecorder, bool) {
+	recorder, ok := ctx.Value(transcriptRecorderKey{}).(*TranscriptRecorder)
+	return recorder, ok && recorder != nil
+}
+
+func (r *TranscriptRecorder) add(t *Transcript) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transcripts = append(r.transcripts, t)
+}
+
+// Transcripts returns the recorded transcripts, in the order of the calls
+func (r *TranscriptRecorder) Transcripts() []*Transcript {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Transcript(nil), r.transcripts...)
+}
+
+// Len returns the number of recorded judge calls
+func (r *TranscriptRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.transcripts)
+}
+
+// WriteFile writes the transcripts to path as a JSON array
+func (r *TranscriptRecorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r.Transcripts(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
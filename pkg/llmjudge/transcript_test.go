@@ -0,0 +1,79 @@
+package llmjudge
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptRecorder(t *testing.T) {
+	url, requests := startJudgeModel(t)
+	judge := newTestJudge(t, url, WithCacheDir(t.TempDir()))
+	step := &LLMJudgeStepConfig{Contains: "nginx-web"}
+
+	recorder := NewTranscriptRecorder()
+	ctx := TranscriptRecorderToContext(context.Background(), recorder)
+
+	// The second call is answered from the cache
+	for range 2 {
+		_, err := judge.EvaluateText(ctx, step, "Create a pod", "created nginx-web")
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), requests.Load())
+
+	transcripts := recorder.Transcripts()
+	require.Len(t, transcripts, 2)
+
+	called := transcripts[0]
+	assert.Equal(t, "judge", called.Model)
+	assert.Contains(t, called.UserPrompt, "created nginx-web")
+	assert.NotEmpty(t, called.SystemPrompt)
+	assert.Contains(t, string(called.Response), `"chatcmpl-test"`)
+	require.NotNil(t, called.ToolCall)
+	assert.Equal(t, "submit_judgement", called.ToolCall.Name)
+	assert.JSONEq(t, `{"passed":true,"reason":"looks right","failureCategory":"n/a"}`, called.ToolCall.Arguments)
+	assert.Equal(t, &LLMJudgeResult{Passed: true, Reason: "looks right", FailureCategory: "n/a"}, called.Verdict)
+	assert.False(t, called.Cached)
+
+	cached := transcripts[1]
+	assert.True(t, cached.Cached)
+	assert.Empty(t, cached.Response)
+	assert.Equal(t, called.Verdict, cached.Verdict)
+
+	path := filepath.Join(t.TempDir(), "judge.json")
+	require.NoError(t, recorder.WriteFile(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var written []*Transcript
+	require.NoError(t, json.Unmarshal(data, &written))
+	require.Len(t, written, 2)
+	assert.JSONEq(t, string(called.Response), string(written[0].Response))
+	assert.Equal(t, called.Verdict, written[0].Verdict)
+	assert.True(t, written[1].Cached)
+}
+
+func TestTranscriptRecorderError(t *testing.T) {
+	endpoint := startEndpoint(t, "local", 200, 500)
+	judge := newTestJudge(t, endpoint.url)
+
+	recorder := NewTranscriptRecorder()
+	ctx := TranscriptRecorderToContext(context.Background(), recorder)
+
+	_, err := judge.EvaluateText(ctx, &LLMJudgeStepConfig{Contains: "nginx-web"}, "Create a pod", "created nginx-web")
+	require.Error(t, err)
+
+	transcripts := recorder.Transcripts()
+	require.Len(t, transcripts, 1)
+	assert.Contains(t, transcripts[0].Error, "failed to call llm judge")
+	assert.Nil(t, transcripts[0].Verdict)
+}
+
+func TestTranscriptRecorderNotInContext(t *testing.T) {
+	_, ok := TranscriptRecorderFromContext(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,220 @@
+// Package mcpchecker runs evals from Go programs and tests, without shelling
+// out to the mcpchecker CLI.
+//
+// An eval is configured with options, either on top of an eval file or from
+// scratch:
+//
+//	res, err := mcpchecker.Run(ctx,
+//		mcpchecker.WithEvalFile("eval.yaml"),
+//		mcpchecker.WithTaskFilter("create-pod"),
+//		mcpchecker.WithProgress(func(e mcpchecker.ProgressEvent) {
+//			log.Println(e.Type, e.Message)
+//		}),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	if !res.Passed() {
+//		...
+//	}
+package mcpchecker
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// Result is the result of a single task
+type Result = eval.EvalResult
+
+// ProgressEvent reports the progress of a run to WithProgress
+type ProgressEvent = eval.ProgressEvent
+
+// TaskSet selects tasks by path or glob, with the assertions checked for them
+type TaskSet = eval.TaskSet
+
+// TaskAssertions are checked against the MCP calls the agent made in a task
+type TaskAssertions = eval.TaskAssertions
+
+// Sample selects a subset of the tasks to run, see WithSample
+type Sample = eval.Sample
+
+// Stats summarizes the results of a run
+type Stats = results.Stats
+
+// Results are the results of a run.
+type Results struct {
+	// Name is the name of the eval
+	Name  string
+	Tasks []*Result
+	Stats Stats
+}
+
+// Passed returns whether every task that is counted passed, along with its
+// assertions. Skipped, quarantined, and expected to fail tasks are not
+// counted.
+func (r *Results) Passed() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the tasks that are counted and failed or did not pass their
+// assertions.
+func (r *Results) Failed() []*Result {
+	var failed []*Result
+	for _, t := range r.Tasks {
+		if t.Counted() && (!t.TaskPassed || !t.AllAssertionsPassed) {
+			failed = append(failed, t)
+		}
+	}
+	return failed
+}
+
+// Save writes the results to path in a layout (results.LayoutFile,
+// results.LayoutGzip, or results.LayoutDir), for the other mcpchecker commands
+// to read.
+func (r *Results) Save(path, layout string) error {
+	return results.Save(r.Tasks, path, layout)
+}
+
+// Run runs an eval configured by opts. Errors in the configuration are
+// returned as *eval.ConfigError. When some tasks could not be run, the results
+// of the others are returned along with the error.
+func Run(ctx context.Context, opts ...Option) (*Results, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	spec, err := o.buildSpec()
+	if err != nil {
+		return nil, &eval.ConfigError{Err: err}
+	}
+
+	runner, err := eval.NewRunner(spec, o.runnerOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := o.progress
+	if progress == nil {
+		progress = eval.NoopProgressCallback
+	}
+
+	ctx = util.WithVerbose(ctx, o.verbose)
+	tasks, err := runner.RunWithProgress(ctx, o.taskFilter, progress)
+	if tasks == nil {
+		return nil, err
+	}
+
+	return &Results{
+		Name:  spec.Metadata.Name,
+		Tasks: tasks,
+		Stats: results.CalculateStats("", tasks),
+	}, err
+}
+
+// buildSpec loads the eval file or spec and applies the options on top of it
+func (o *options) buildSpec() (*eval.EvalSpec, error) {
+	var spec *eval.EvalSpec
+	switch {
+	case o.evalFile != "" && o.spec != nil:
+		return nil, fmt.Errorf("WithEvalFile and WithEvalSpec cannot be used together")
+	case o.evalFile != "":
+		s, err := eval.FromFile(o.evalFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load eval config: %w", err)
+		}
+		spec = s
+	case o.spec != nil:
+		// Options must not change the caller's spec
+		s := *o.spec
+		s.Config.TaskSets = append([]eval.TaskSet(nil), o.spec.Config.TaskSets...)
+		s.Config.McpConfigFiles = append([]string(nil), o.spec.Config.McpConfigFiles...)
+		spec = &s
+	default:
+		spec = &eval.EvalSpec{
+			TypeMeta: util.TypeMeta{Kind: eval.KindEval},
+			Metadata: eval.EvalMetadata{Name: "mcpchecker"},
+		}
+	}
+
+	if o.name != "" {
+		spec.Metadata.Name = o.name
+	}
+	if o.agent != nil {
+		spec.Config.Agent = o.agent
+	}
+	if o.judge != nil {
+		spec.Config.LLMJudge = o.judge
+	}
+	if o.mcpProfile != "" {
+		spec.Config.McpProfile = o.mcpProfile
+	}
+	if o.maxAgentOutput != nil {
+		agentOutput := eval.AgentOutputConfig{}
+		if spec.Config.AgentOutput != nil {
+			agentOutput = *spec.Config.AgentOutput
+		}
+		agentOutput.MaxBytes = *o.maxAgentOutput
+		spec.Config.AgentOutput = &agentOutput
+	}
+
+	// Paths given in options are relative to the working directory
+	for _, f := range o.mcpConfigFiles {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve mcp config file path %s: %w", f, err)
+		}
+		spec.Config.McpConfigFiles = append(spec.Config.McpConfigFiles, abs)
+	}
+	for _, ts := range o.taskSets {
+		var err error
+		if ts.Path != "" {
+			ts.Path, err = filepath.Abs(ts.Path)
+		} else if ts.Glob != "" {
+			ts.Glob, err = filepath.Abs(ts.Glob)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve task set path: %w", err)
+		}
+		spec.Config.TaskSets = append(spec.Config.TaskSets, ts)
+	}
+
+	if len(spec.Config.TaskSets) == 0 {
+		return nil, fmt.Errorf("no tasks: use WithTaskFile, WithTaskGlob, or WithTaskSet, or an eval file with taskSets")
+	}
+	if spec.Config.Agent == nil && o.agentRunner == nil {
+		return nil, fmt.Errorf("no agent: use WithBuiltinAgent, WithAgentFile, or WithAgent, or an eval file with an agent")
+	}
+
+	if err := eval.ApplyLabelSelectorFilter(spec, o.labelSelector); err != nil {
+		return nil, fmt.Errorf("failed to apply label selector: %w", err)
+	}
+
+	return spec, nil
+}
+
+func (o *options) runnerOptions() []eval.RunnerOption {
+	var opts []eval.RunnerOption
+	if o.agentRunner != nil {
+		opts = append(opts, eval.WithAgentRunner(o.agentRunner))
+	}
+	if o.judgeImpl != nil {
+		opts = append(opts, eval.WithJudge(o.judgeImpl))
+	}
+	if o.judgeCache != "" {
+		opts = append(opts, eval.WithJudgeCacheDir(o.judgeCache))
+	}
+	if o.sample != nil {
+		opts = append(opts, eval.WithSample(o.sample))
+	}
+	if o.shard != nil {
+		opts = append(opts, eval.WithShard(o.shard))
+	}
+	return opts
+}
@@ -0,0 +1,247 @@
+package mcpchecker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAgent struct {
+	output    string
+	healthErr error
+	ran       bool
+}
+
+type fakeAgentResult string
+
+func (r fakeAgentResult) GetOutput() string { return string(r) }
+
+func (a *fakeAgent) RunTask(ctx context.Context, prompt string) (agent.AgentResult, error) {
+	a.ran = true
+	return fakeAgentResult(a.output), nil
+}
+
+func (a *fakeAgent) CheckHealth(ctx context.Context) error {
+	return a.healthErr
+}
+
+func (a *fakeAgent) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) agent.Runner {
+	return a
+}
+
+func (a *fakeAgent) AgentName() string { return "fake" }
+
+// containsJudge passes output that contains the expected text
+type containsJudge struct{}
+
+func (containsJudge) EvaluateText(ctx context.Context, cfg *llmjudge.LLMJudgeStepConfig, prompt, output string) (*llmjudge.LLMJudgeResult, error) {
+	if strings.Contains(output, cfg.Contains) {
+		return &llmjudge.LLMJudgeResult{Passed: true, Reason: "found"}, nil
+	}
+	return &llmjudge.LLMJudgeResult{Passed: false, Reason: fmt.Sprintf("missing %q", cfg.Contains)}, nil
+}
+
+func (containsJudge) ModelName() string { return "contains" }
+
+// writeFixtures writes an MCP config for an in-process MCP server and two
+// tasks, and returns the directory they are in
+func writeFixtures(t *testing.T) string {
+	t.Helper()
+
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+	mcp.AddTool(s, &mcp.Tool{Name: "ping"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s }, nil))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"mcp-config.json": fmt.Sprintf(`{"mcpServers": {"test": {"type": "http", "url": %q}}}`, srv.URL),
+		"tasks/greet.yaml": `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: greet
+  labels:
+    suite: basic
+spec:
+  prompt:
+    inline: Say hello
+  verify:
+    - llmJudge:
+        contains: hello
+`,
+		"tasks/farewell.yaml": `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: farewell
+  labels:
+    suite: extra
+spec:
+  prompt:
+    inline: Say goodbye
+  verify:
+    - llmJudge:
+        contains: goodbye
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := writeFixtures(t)
+
+	var events []eval.ProgressEventType
+	res, err := Run(context.Background(),
+		WithName("embedded"),
+		WithAgent(&fakeAgent{output: "hello there"}),
+		WithJudge(containsJudge{}),
+		WithMcpConfigFiles(filepath.Join(dir, "mcp-config.json")),
+		WithTaskGlob(filepath.Join(dir, "tasks", "*.yaml"), &TaskAssertions{MaxToolCalls: new(int)}),
+		WithProgress(func(e ProgressEvent) {
+			events = append(events, e.Type)
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "embedded", res.Name)
+	require.Len(t, res.Tasks, 2)
+	assert.Equal(t, 2, res.Stats.TasksTotal)
+	assert.Equal(t, 1, res.Stats.TasksPassed)
+	assert.False(t, res.Passed())
+
+	failed := res.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, "farewell", failed[0].TaskName)
+
+	assert.Equal(t, eval.EventEvalStart, events[0])
+	assert.Equal(t, eval.EventEvalComplete, events[len(events)-1])
+
+	path := filepath.Join(t.TempDir(), "results.json")
+	require.NoError(t, res.Save(path, "file"))
+	assert.FileExists(t, path)
+}
+
+func TestRunFilters(t *testing.T) {
+	dir := writeFixtures(t)
+
+	base := []Option{
+		WithAgent(&fakeAgent{output: "hello"}),
+		WithJudge(containsJudge{}),
+		WithMcpConfigFiles(filepath.Join(dir, "mcp-config.json")),
+		WithTaskGlob(filepath.Join(dir, "tasks", "*.yaml"), nil),
+	}
+
+	res, err := Run(context.Background(), append(base, WithTaskFilter("^greet$"))...)
+	require.NoError(t, err)
+	require.Len(t, res.Tasks, 1)
+	assert.True(t, res.Passed())
+
+	res, err = Run(context.Background(), append(base, WithLabelSelector("suite=extra"))...)
+	require.NoError(t, err)
+	require.Len(t, res.Tasks, 1)
+	assert.Equal(t, "farewell", res.Tasks[0].TaskName)
+}
+
+func TestRunConfigErrors(t *testing.T) {
+	tests := map[string]struct {
+		opts        []Option
+		errContains string
+	}{
+		"no tasks": {
+			opts:        []Option{WithAgent(&fakeAgent{})},
+			errContains: "no tasks",
+		},
+		"no agent": {
+			opts:        []Option{WithTaskFile("task.yaml", nil)},
+			errContains: "no agent",
+		},
+		"eval file and spec": {
+			opts:        []Option{WithEvalFile("eval.yaml"), WithEvalSpec(&eval.EvalSpec{})},
+			errContains: "cannot be used together",
+		},
+		"missing eval file": {
+			opts:        []Option{WithEvalFile("/nonexistent/eval.yaml")},
+			errContains: "failed to load eval config",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Run(context.Background(), tc.opts...)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tc.errContains)
+
+			var configErr *eval.ConfigError
+			assert.ErrorAs(t, err, &configErr)
+		})
+	}
+}
+
+func TestRunAgentHealthCheck(t *testing.T) {
+	dir := writeFixtures(t)
+
+	fake := &fakeAgent{output: "hello", healthErr: fmt.Errorf("API key was rejected")}
+	_, err := Run(context.Background(),
+		WithAgent(fake),
+		WithJudge(containsJudge{}),
+		WithMcpConfigFiles(filepath.Join(dir, "mcp-config.json")),
+		WithTaskGlob(filepath.Join(dir, "tasks", "*.yaml"), nil),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "agent fake is not runnable: API key was rejected")
+	assert.False(t, fake.ran, "no task should run when the agent is not runnable")
+}
+
+func TestBuildSpecDoesNotChangeSpec(t *testing.T) {
+	spec := &eval.EvalSpec{
+		Metadata: eval.EvalMetadata{Name: "original"},
+		Config: eval.EvalConfig{
+			Agent:       &eval.AgentRef{Type: "builtin.claude-code"},
+			TaskSets:    []eval.TaskSet{{Path: "/tasks/a.yaml"}},
+			AgentOutput: &eval.AgentOutputConfig{ArtifactDir: "/artifacts"},
+		},
+	}
+
+	o := &options{}
+	for _, opt := range []Option{
+		WithEvalSpec(spec),
+		WithName("changed"),
+		WithBuiltinAgent("builtin.openai-agent", "gpt-4o"),
+		WithTaskFile("/tasks/b.yaml", nil),
+		WithMaxAgentOutput(-1),
+	} {
+		opt(o)
+	}
+
+	built, err := o.buildSpec()
+	require.NoError(t, err)
+
+	assert.Equal(t, "changed", built.Metadata.Name)
+	assert.Equal(t, &eval.AgentRef{Type: "builtin.openai-agent", Model: "gpt-4o"}, built.Config.Agent)
+	assert.Len(t, built.Config.TaskSets, 2)
+	assert.Equal(t, &eval.AgentOutputConfig{MaxBytes: -1, ArtifactDir: "/artifacts"}, built.Config.AgentOutput)
+
+	assert.Equal(t, "original", spec.Metadata.Name)
+	assert.Len(t, spec.Config.TaskSets, 1)
+	assert.Equal(t, 0, spec.Config.AgentOutput.MaxBytes)
+}
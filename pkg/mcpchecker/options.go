@@ -0,0 +1,201 @@
+package mcpchecker
+
+import (
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+)
+
+// Option configures a run
+type Option func(*options)
+
+type options struct {
+	evalFile string
+	spec     *eval.EvalSpec
+	name     string
+
+	agent       *eval.AgentRef
+	agentRunner agent.Runner
+	judge       *llmjudge.LLMJudgeEvalConfig
+	judgeImpl   llmjudge.LLMJudge
+	judgeCache  string
+
+	taskSets       []eval.TaskSet
+	mcpConfigFiles []string
+	mcpProfile     string
+	maxAgentOutput *int
+
+	taskFilter    string
+	labelSelector string
+	sample        *eval.Sample
+	shard         *eval.Shard
+	progress      eval.ProgressCallback
+	verbose       bool
+}
+
+// WithEvalFile loads the eval from an eval file. Other options are applied on
+// top of it, and task sets and MCP config files are added to the ones in the
+// file.
+func WithEvalFile(path string) Option {
+	return func(o *options) {
+		o.evalFile = path
+	}
+}
+
+// WithEvalSpec runs an eval spec built in Go. Other options are applied on top
+// of a copy of it.
+func WithEvalSpec(spec *eval.EvalSpec) Option {
+	return func(o *options) {
+		o.spec = spec
+	}
+}
+
+// WithName sets the name of the eval
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithBuiltinAgent runs the tasks with a builtin agent, such as "claude-code"
+// or "openai-agent". Some builtin agents require a model.
+func WithBuiltinAgent(name, model string) Option {
+	return func(o *options) {
+		o.agent = &eval.AgentRef{Type: "builtin." + strings.TrimPrefix(name, "builtin."), Model: model}
+	}
+}
+
+// WithAgentFile runs the tasks with the agent defined in an agent file
+func WithAgentFile(path string) Option {
+	return func(o *options) {
+		o.agent = &eval.AgentRef{Type: "file", Path: path}
+	}
+}
+
+// WithAgent runs the tasks with an agent implemented in Go, e.g. a fake agent
+// in tests, instead of the agent in the eval config.
+func WithAgent(runner agent.Runner) Option {
+	return func(o *options) {
+		o.agentRunner = runner
+	}
+}
+
+// WithJudgeEnv configures the LLM judge from the environment variables with
+// the given names, like llmJudge.env in an eval file.
+func WithJudgeEnv(baseURLKey, apiKeyKey, modelNameKey string) Option {
+	return func(o *options) {
+		o.judge = &llmjudge.LLMJudgeEvalConfig{Env: &llmjudge.LLMJudgeEnvConfig{
+			BaseUrlKey:   baseURLKey,
+			ApiKeyKey:    apiKeyKey,
+			ModelNameKey: modelNameKey,
+		}}
+	}
+}
+
+// WithJudge evaluates llmJudge steps with a judge implemented in Go instead
+// of the LLM judge in the eval config.
+func WithJudge(judge llmjudge.LLMJudge) Option {
+	return func(o *options) {
+		o.judgeImpl = judge
+	}
+}
+
+// WithJudgeCacheDir caches the verdicts of the LLM judge in dir, so that
+// identical judge calls are only paid for once. llmjudge.DefaultCacheDir
+// returns the directory the CLI uses.
+func WithJudgeCacheDir(dir string) Option {
+	return func(o *options) {
+		o.judgeCache = dir
+	}
+}
+
+// WithTaskFile adds a task file, checked with assertions, which may be nil
+func WithTaskFile(path string, assertions *TaskAssertions) Option {
+	return WithTaskSet(TaskSet{Path: path, Assertions: assertions})
+}
+
+// WithTaskGlob adds the task files matching a glob, checked with assertions,
+// which may be nil
+func WithTaskGlob(glob string, assertions *TaskAssertions) Option {
+	return WithTaskSet(TaskSet{Glob: glob, Assertions: assertions})
+}
+
+// WithTaskSet adds a task set. Relative paths are resolved against the
+// working directory.
+func WithTaskSet(ts TaskSet) Option {
+	return func(o *options) {
+		o.taskSets = append(o.taskSets, ts)
+	}
+}
+
+// WithMcpConfigFiles adds MCP config files, layered on top of the ones in the
+// eval config in order
+func WithMcpConfigFiles(paths ...string) Option {
+	return func(o *options) {
+		o.mcpConfigFiles = append(o.mcpConfigFiles, paths...)
+	}
+}
+
+// WithMcpProfile selects a named profile from the MCP config files
+func WithMcpProfile(profile string) Option {
+	return func(o *options) {
+		o.mcpProfile = profile
+	}
+}
+
+// WithMaxAgentOutput limits the bytes of agent output kept in the results of
+// each task, like --max-agent-output. -1 keeps all output.
+func WithMaxAgentOutput(maxBytes int) Option {
+	return func(o *options) {
+		o.maxAgentOutput = &maxBytes
+	}
+}
+
+// WithTaskFilter only runs the tasks whose name matches a regular expression,
+// like --run
+func WithTaskFilter(pattern string) Option {
+	return func(o *options) {
+		o.taskFilter = pattern
+	}
+}
+
+// WithLabelSelector only runs the task sets matching a label selector of the
+// form key=value, like --label-selector
+func WithLabelSelector(selector string) Option {
+	return func(o *options) {
+		o.labelSelector = selector
+	}
+}
+
+// WithSample only runs a sample of the tasks that match the filters, like
+// --sample and --sample-percent
+func WithSample(sample Sample) Option {
+	return func(o *options) {
+		o.sample = &sample
+	}
+}
+
+// WithShard only runs one shard of the tasks that match the filters, like
+// --shard
+func WithShard(index, count int) Option {
+	return func(o *options) {
+		o.shard = &eval.Shard{Index: index, Count: count}
+	}
+}
+
+// WithProgress calls fn with the progress of the run
+func WithProgress(fn func(ProgressEvent)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// WithVerbose prints what the agent, the judge, and cleanup steps are doing to
+// stdout, like --verbose
+func WithVerbose(verbose bool) Option {
+	return func(o *options) {
+		o.verbose = verbose
+	}
+}
@@ -0,0 +1,320 @@
+// Package mcpimport converts MCP server configurations from other MCP clients
+// (Claude Desktop, Cursor, VS Code) into the mcpchecker MCP config format.
+package mcpimport
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	SourceClaudeDesktop = "claude-desktop"
+	SourceCursor        = "cursor"
+	SourceVSCode        = "vscode"
+)
+
+// Sources lists all supported import sources.
+var Sources = []string{SourceClaudeDesktop, SourceCursor, SourceVSCode}
+
+// Result holds an imported config along with any warnings about servers that
+// were skipped or may need manual changes.
+type Result struct {
+	// Path is the file the config was imported from
+	Path     string
+	Config   *mcpproxy.MCPConfig
+	Warnings []string
+}
+
+// clientServer is the superset of the server fields used by the supported clients.
+type clientServer struct {
+	Type     string            `json:"type,omitempty"`
+	Command  string            `json:"command,omitempty"`
+	Args     []string          `json:"args,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Disabled bool              `json:"disabled,omitempty"`
+}
+
+// clientConfig covers the top-level layouts used by the supported clients:
+// "mcpServers" (Claude Desktop, Cursor), "servers" (VS Code mcp.json), and
+// "mcp.servers" (VS Code settings.json).
+type clientConfig struct {
+	MCPServers map[string]*clientServer `json:"mcpServers,omitempty"`
+	Servers    map[string]*clientServer `json:"servers,omitempty"`
+	MCP        *struct {
+		Servers map[string]*clientServer `json:"servers,omitempty"`
+	} `json:"mcp,omitempty"`
+}
+
+// ConfigPaths returns the candidate config file locations for source, in the
+// order they are searched. Project-level files in workdir come before user-level ones.
+func ConfigPaths(source, workdir string) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine home directory: %w", err)
+	}
+
+	switch source {
+	case SourceClaudeDesktop:
+		return []string{filepath.Join(appConfigDir(home), "Claude", "claude_desktop_config.json")}, nil
+	case SourceCursor:
+		return []string{
+			filepath.Join(workdir, ".cursor", "mcp.json"),
+			filepath.Join(home, ".cursor", "mcp.json"),
+		}, nil
+	case SourceVSCode:
+		userDir := filepath.Join(appConfigDir(home), "Code", "User")
+		return []string{
+			filepath.Join(workdir, ".vscode", "mcp.json"),
+			filepath.Join(userDir, "mcp.json"),
+			filepath.Join(userDir, "settings.json"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown import source %q: must be one of %s", source, strings.Join(Sources, ", "))
+	}
+}
+
+// appConfigDir returns the per-user application config directory for the current OS.
+func appConfigDir(home string) string {
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support")
+	case "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return appData
+		}
+		return filepath.Join(home, "AppData", "Roaming")
+	default:
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			return xdg
+		}
+		return filepath.Join(home, ".config")
+	}
+}
+
+// Import reads the MCP config for source and converts it. If path is empty, the
+// first existing file from ConfigPaths is used.
+func Import(source, path, workdir string) (*Result, error) {
+	if path == "" {
+		candidates, err := ConfigPaths(source, workdir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range candidates {
+			if _, err := os.Stat(c); err == nil {
+				path = c
+				break
+			}
+		}
+
+		if path == "" {
+			return nil, fmt.Errorf("no %s MCP config found, looked in: %s", source, strings.Join(candidates, ", "))
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s config '%s': %w", source, path, err)
+	}
+
+	res, err := Convert(source, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s config '%s': %w", source, path, err)
+	}
+	res.Path = path
+
+	return res, nil
+}
+
+// Convert converts the raw config of an MCP client into an MCPConfig.
+// Servers that cannot be represented are skipped with a warning.
+func Convert(source string, data []byte) (*Result, error) {
+	if !slices.Contains(Sources, source) {
+		return nil, fmt.Errorf("unknown import source %q: must be one of %s", source, strings.Join(Sources, ", "))
+	}
+
+	data = stripJSONComments(data)
+
+	var cc clientConfig
+	if err := yaml.Unmarshal(data, &cc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	servers := cc.MCPServers
+	if source == SourceVSCode {
+		servers = cc.Servers
+		if servers == nil && cc.MCP != nil {
+			servers = cc.MCP.Servers
+		}
+	}
+
+	res := &Result{
+		Config: &mcpproxy.MCPConfig{
+			MCPServers: make(map[string]*mcpproxy.ServerConfig, len(servers)),
+		},
+	}
+
+	// Sort names so warnings are reported in a stable order
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cs := servers[name]
+		if cs == nil {
+			continue
+		}
+
+		server, warnings := convertServer(source, cs)
+		for _, w := range warnings {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("server %q: %s", name, w))
+		}
+		if server == nil {
+			continue
+		}
+
+		if err := server.Validate(); err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("server %q: skipped: %s", name, err))
+			continue
+		}
+
+		res.Config.MCPServers[name] = server
+	}
+
+	if len(res.Config.MCPServers) == 0 {
+		return nil, fmt.Errorf("no importable MCP servers found")
+	}
+
+	return res, nil
+}
+
+func convertServer(source string, cs *clientServer) (*mcpproxy.ServerConfig, []string) {
+	var warnings []string
+
+	server := &mcpproxy.ServerConfig{
+		Command:        cs.Command,
+		Args:           cs.Args,
+		Env:            cs.Env,
+		URL:            cs.URL,
+		Headers:        cs.Headers,
+		Disabled:       cs.Disabled,
+		EnableAllTools: true,
+	}
+
+	switch cs.Type {
+	case "":
+	case mcpproxy.TransportTypeStdio, mcpproxy.TransportTypeHttp:
+		server.Type = cs.Type
+	case "streamable-http", "streamableHttp":
+		server.Type = mcpproxy.TransportTypeHttp
+	case "sse":
+		return nil, []string{"skipped: SSE servers are not supported, only streamable HTTP"}
+	default:
+		return nil, []string{fmt.Sprintf("skipped: unsupported server type %q", cs.Type)}
+	}
+
+	if source == SourceVSCode {
+		convert := func(s string) string {
+			converted, w := convertVSCodeVariables(s)
+			warnings = append(warnings, w...)
+			return converted
+		}
+
+		server.Command = convert(server.Command)
+		server.URL = convert(server.URL)
+		for i, arg := range server.Args {
+			server.Args[i] = convert(arg)
+		}
+		for k, v := range server.Env {
+			server.Env[k] = convert(v)
+		}
+		for k, v := range server.Headers {
+			server.Headers[k] = convert(v)
+		}
+	}
+
+	return server, warnings
+}
+
+var (
+	vscodeVariablePattern = regexp.MustCompile(`\$\{(env|input):([^}]+)\}`)
+	nonEnvNameChars       = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// convertVSCodeVariables rewrites VS Code's ${env:VAR} references to ${VAR}.
+// ${input:id} prompts have no equivalent, so they are turned into environment
+// variable references derived from the input id and reported as warnings.
+func convertVSCodeVariables(s string) (string, []string) {
+	var warnings []string
+
+	converted := vscodeVariablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := vscodeVariablePattern.FindStringSubmatch(ref)
+		if m[1] == "env" {
+			return "${" + m[2] + "}"
+		}
+
+		envName := strings.ToUpper(nonEnvNameChars.ReplaceAllString(m[2], "_"))
+		warnings = append(warnings, fmt.Sprintf("input %q was replaced with ${%s}, set this environment variable before running", m[2], envName))
+		return "${" + envName + "}"
+	})
+
+	return converted, warnings
+}
+
+// stripJSONComments removes // and /* */ comments, which VS Code allows in its
+// JSON config files. Comment markers inside strings are left untouched.
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		default:
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
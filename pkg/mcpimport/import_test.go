@@ -0,0 +1,184 @@
+package mcpimport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	tt := map[string]struct {
+		source           string
+		data             string
+		expected         map[string]*mcpproxy.ServerConfig
+		expectedWarnings []string
+		expectErr        bool
+	}{
+		"claude desktop stdio server": {
+			source: SourceClaudeDesktop,
+			data: `{
+  "mcpServers": {
+    "filesystem": {
+      "command": "npx",
+      "args": ["-y", "@modelcontextprotocol/server-filesystem", "/tmp"],
+      "env": {"DEBUG": "1"}
+    }
+  }
+}`,
+			expected: map[string]*mcpproxy.ServerConfig{
+				"filesystem": {
+					Command:        "npx",
+					Args:           []string{"-y", "@modelcontextprotocol/server-filesystem", "/tmp"},
+					Env:            map[string]string{"DEBUG": "1"},
+					EnableAllTools: true,
+				},
+			},
+		},
+		"cursor http server with headers": {
+			source: SourceCursor,
+			data: `{
+  "mcpServers": {
+    "remote": {
+      "url": "https://example.com/mcp",
+      "headers": {"Authorization": "Bearer ${TOKEN}"}
+    }
+  }
+}`,
+			expected: map[string]*mcpproxy.ServerConfig{
+				"remote": {
+					URL:            "https://example.com/mcp",
+					Headers:        map[string]string{"Authorization": "Bearer ${TOKEN}"},
+					EnableAllTools: true,
+				},
+			},
+		},
+		"vscode mcp.json with comments and variables": {
+			source: SourceVSCode,
+			data: `{
+  // Project servers
+  "inputs": [{"type": "promptString", "id": "api-key", "password": true}],
+  "servers": {
+    "github": {
+      "type": "http",
+      "url": "https://api.example.com/mcp", /* remote */
+      "headers": {"Authorization": "Bearer ${input:api-key}"}
+    },
+    "local": {
+      "type": "stdio",
+      "command": "server",
+      "env": {"HOME_DIR": "${env:HOME}"}
+    }
+  }
+}`,
+			expected: map[string]*mcpproxy.ServerConfig{
+				"github": {
+					Type:           mcpproxy.TransportTypeHttp,
+					URL:            "https://api.example.com/mcp",
+					Headers:        map[string]string{"Authorization": "Bearer ${API_KEY}"},
+					EnableAllTools: true,
+				},
+				"local": {
+					Type:           mcpproxy.TransportTypeStdio,
+					Command:        "server",
+					Env:            map[string]string{"HOME_DIR": "${HOME}"},
+					EnableAllTools: true,
+				},
+			},
+			expectedWarnings: []string{
+				`server "github": input "api-key" was replaced with ${API_KEY}, set this environment variable before running`,
+			},
+		},
+		"vscode settings.json": {
+			source: SourceVSCode,
+			data: `{
+  "editor.fontSize": 14,
+  "mcp": {
+    "servers": {
+      "local": {"command": "server"}
+    }
+  }
+}`,
+			expected: map[string]*mcpproxy.ServerConfig{
+				"local": {
+					Command:        "server",
+					EnableAllTools: true,
+				},
+			},
+		},
+		"comment markers inside strings are kept": {
+			source: SourceCursor,
+			data:   `{"mcpServers": {"remote": {"url": "http://localhost:8080/mcp"}}}`,
+			expected: map[string]*mcpproxy.ServerConfig{
+				"remote": {
+					URL:            "http://localhost:8080/mcp",
+					EnableAllTools: true,
+				},
+			},
+		},
+		"unsupported servers are skipped with warnings": {
+			source: SourceCursor,
+			data: `{
+  "mcpServers": {
+    "legacy": {"type": "sse", "url": "https://example.com/sse"},
+    "broken": {"type": "stdio"},
+    "ok": {"command": "server"}
+  }
+}`,
+			expected: map[string]*mcpproxy.ServerConfig{
+				"ok": {
+					Command:        "server",
+					EnableAllTools: true,
+				},
+			},
+			expectedWarnings: []string{
+				`server "broken": skipped: command is required for stdio servers`,
+				`server "legacy": skipped: SSE servers are not supported, only streamable HTTP`,
+			},
+		},
+		"no importable servers": {
+			source:    SourceClaudeDesktop,
+			data:      `{"mcpServers": {}}`,
+			expectErr: true,
+		},
+		"unknown source": {
+			source:    "zed",
+			data:      `{"mcpServers": {"ok": {"command": "server"}}}`,
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			res, err := Convert(tc.source, []byte(tc.data))
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res.Config.MCPServers)
+			assert.Equal(t, tc.expectedWarnings, res.Warnings)
+		})
+	}
+}
+
+func TestImportSearchesProjectConfig(t *testing.T) {
+	workdir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := Import(SourceCursor, "", workdir)
+	assert.Error(t, err, "expected an error when no config exists")
+
+	path := filepath.Join(workdir, ".cursor", "mcp.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(`{"mcpServers": {"local": {"command": "server"}}}`), 0644))
+
+	res, err := Import(SourceCursor, "", workdir)
+	require.NoError(t, err)
+	assert.Equal(t, path, res.Path)
+	assert.Contains(t, res.Config.MCPServers, "local")
+}
@@ -0,0 +1,138 @@
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	AuthTypeBearer = "bearer"
+	AuthTypeOAuth2 = "oauth2"
+)
+
+// AuthConfig configures how the proxy authenticates to an upstream HTTP MCP server.
+// All string values may contain environment variable references like ${VAR} or
+// ${VAR:-default}, which are expanded when the server is started.
+type AuthConfig struct {
+	// Type is the auth scheme: "bearer" or "oauth2"
+	Type string `json:"type"`
+
+	// Token is the static token sent as "Authorization: Bearer <token>"
+	// Used when type is "bearer"
+	Token string `json:"token,omitempty"`
+
+	// TokenURL is the OAuth2 token endpoint
+	// Used when type is "oauth2"
+	TokenURL string `json:"tokenUrl,omitempty"`
+
+	// ClientID and ClientSecret are the OAuth2 client credentials
+	// Used when type is "oauth2"
+	ClientID     string `json:"clientId,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+
+	// Scopes are the OAuth2 scopes to request
+	// Used when type is "oauth2"
+	Scopes []string `json:"scopes,omitempty"`
+
+	// EndpointParams are additional parameters sent to the token endpoint (e.g. audience)
+	// Used when type is "oauth2"
+	EndpointParams map[string]string `json:"endpointParams,omitempty"`
+}
+
+// Validate checks that the fields required by the auth type are set.
+func (a *AuthConfig) Validate() error {
+	switch a.Type {
+	case AuthTypeBearer:
+		if a.Token == "" {
+			return fmt.Errorf("token is required for bearer auth")
+		}
+	case AuthTypeOAuth2:
+		if a.TokenURL == "" {
+			return fmt.Errorf("tokenUrl is required for oauth2 auth")
+		}
+		if a.ClientID == "" {
+			return fmt.Errorf("clientId is required for oauth2 auth")
+		}
+	default:
+		return fmt.Errorf("unknown auth type %q: must be one of %q, %q", a.Type, AuthTypeBearer, AuthTypeOAuth2)
+	}
+
+	return nil
+}
+
+// newAuthRoundTripper wraps base with a transport that authenticates every request.
+// For oauth2, tokens are fetched with the client credentials flow through
+// tokenTransport, and refreshed automatically once they expire.
+func newAuthRoundTripper(ctx context.Context, auth *AuthConfig, base, tokenTransport http.RoundTripper) (http.RoundTripper, error) {
+	if auth == nil {
+		return base, nil
+	}
+
+	if err := auth.Validate(); err != nil {
+		return nil, err
+	}
+
+	var source oauth2.TokenSource
+	switch auth.Type {
+	case AuthTypeBearer:
+		source = oauth2.StaticTokenSource(&oauth2.Token{
+			AccessToken: expandEnv(auth.Token),
+			TokenType:   "Bearer",
+		})
+	case AuthTypeOAuth2:
+		cfg := &clientcredentials.Config{
+			ClientID:     expandEnv(auth.ClientID),
+			ClientSecret: expandEnv(auth.ClientSecret),
+			TokenURL:     expandEnv(auth.TokenURL),
+			Scopes:       auth.Scopes,
+		}
+		if len(auth.EndpointParams) > 0 {
+			cfg.EndpointParams = make(map[string][]string, len(auth.EndpointParams))
+			for k, v := range auth.EndpointParams {
+				cfg.EndpointParams.Set(k, expandEnv(v))
+			}
+		}
+		// Token requests must not use the authenticated transport itself
+		tokenCtx := context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: tokenTransport})
+		source = cfg.TokenSource(tokenCtx)
+	}
+
+	return &oauth2.Transport{
+		Source: source,
+		Base:   base,
+	}, nil
+}
+
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv replaces ${VAR} and ${VAR:-default} references in s with values
+// from the environment. Other uses of "$" are left untouched.
+func expandEnv(s string) string {
+	return envRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envRefPattern.FindStringSubmatch(ref)
+		if v, ok := os.LookupEnv(m[1]); ok && v != "" {
+			return v
+		}
+		return m[2]
+	})
+}
+
+// expandEnvMap returns a copy of m with expandEnv applied to every value.
+func expandEnvMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	expanded := make(map[string]string, len(m))
+	for k, v := range m {
+		expanded[k] = expandEnv(v)
+	}
+
+	return expanded
+}
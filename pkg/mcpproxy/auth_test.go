@@ -0,0 +1,143 @@
+package mcpproxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("MCP_TEST_TOKEN", "secret")
+	t.Setenv("MCP_TEST_EMPTY", "")
+
+	tt := map[string]struct {
+		input    string
+		expected string
+	}{
+		"no references":          {input: "plain", expected: "plain"},
+		"set variable":           {input: "Bearer ${MCP_TEST_TOKEN}", expected: "Bearer secret"},
+		"unset variable":         {input: "${MCP_TEST_UNSET}", expected: ""},
+		"default when unset":     {input: "${MCP_TEST_UNSET:-fallback}", expected: "fallback"},
+		"default when empty":     {input: "${MCP_TEST_EMPTY:-fallback}", expected: "fallback"},
+		"default ignored if set": {input: "${MCP_TEST_TOKEN:-fallback}", expected: "secret"},
+		"bare dollar untouched":  {input: "pa$$word $MCP_TEST_TOKEN", expected: "pa$$word $MCP_TEST_TOKEN"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, expandEnv(tc.input))
+		})
+	}
+}
+
+func TestAuthConfigValidate(t *testing.T) {
+	tt := map[string]struct {
+		auth      *AuthConfig
+		expectErr bool
+	}{
+		"bearer":               {auth: &AuthConfig{Type: AuthTypeBearer, Token: "${TOKEN}"}},
+		"bearer without token": {auth: &AuthConfig{Type: AuthTypeBearer}, expectErr: true},
+		"oauth2": {auth: &AuthConfig{
+			Type:     AuthTypeOAuth2,
+			TokenURL: "https://auth.example.com/token",
+			ClientID: "client",
+		}},
+		"oauth2 without token url": {auth: &AuthConfig{Type: AuthTypeOAuth2, ClientID: "client"}, expectErr: true},
+		"oauth2 without client id": {auth: &AuthConfig{Type: AuthTypeOAuth2, TokenURL: "https://auth.example.com/token"}, expectErr: true},
+		"unknown type":             {auth: &AuthConfig{Type: "basic"}, expectErr: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			err := tc.auth.Validate()
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthOnlyAllowedForHttpServers(t *testing.T) {
+	cfg := &ServerConfig{
+		Command: "my-server",
+		Auth:    &AuthConfig{Type: AuthTypeBearer, Token: "token"},
+	}
+
+	assert.Error(t, cfg.Validate())
+}
+
+func TestBearerAuthRoundTripper(t *testing.T) {
+	t.Setenv("MCP_TEST_TOKEN", "secret")
+
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	rt, err := newAuthRoundTripper(context.Background(), &AuthConfig{
+		Type:  AuthTypeBearer,
+		Token: "${MCP_TEST_TOKEN}",
+	}, http.DefaultTransport, http.DefaultTransport)
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: rt}).Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "Bearer secret", gotAuth)
+}
+
+func TestOAuth2AuthRoundTripperRefreshesTokens(t *testing.T) {
+	var tokenRequests atomic.Int32
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := tokenRequests.Add(1)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		assert.Equal(t, "mcp-api", r.Form.Get("audience"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "Bearer",
+			// Expires within the refresh window, so every request fetches a new token
+			"expires_in": 1,
+		})
+	}))
+	defer tokenSrv.Close()
+
+	var gotAuth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	rt, err := newAuthRoundTripper(context.Background(), &AuthConfig{
+		Type:           AuthTypeOAuth2,
+		TokenURL:       tokenSrv.URL,
+		ClientID:       "client",
+		ClientSecret:   "secret",
+		EndpointParams: map[string]string{"audience": "mcp-api"},
+	}, http.DefaultTransport, http.DefaultTransport)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: rt}
+	for range 2 {
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	assert.Equal(t, int32(2), tokenRequests.Load())
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, gotAuth)
+}
@@ -0,0 +1,274 @@
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Triggers of chaos mode disruptions
+const (
+	ChaosTriggerCalls = "calls"
+	ChaosTriggerTime  = "time"
+)
+
+// ChaosConfig kills the process of a stdio server at a point of a task and
+// restarts it, to evaluate how the agent recovers
+type ChaosConfig struct {
+	// AfterCalls kills the server after this many tool calls
+	AfterCalls int `json:"afterCalls,omitempty"`
+
+	// After kills the server this long after it started, as a Go duration
+	// string (e.g. "30s")
+	After string `json:"after,omitempty"`
+
+	// RestartDelay is how long the server stays down before it is restarted,
+	// as a Go duration string. Calls made in the meantime fail
+	RestartDelay string `json:"restartDelay,omitempty"`
+
+	// Times is how often the server is killed, counting calls or time again
+	// from each restart. Defaults to 1
+	Times int `json:"times,omitempty"`
+}
+
+// Validate checks that the chaos config has exactly one trigger and valid
+// durations
+func (c *ChaosConfig) Validate() error {
+	if (c.AfterCalls > 0) == (c.After != "") {
+		return fmt.Errorf("exactly one of afterCalls and after must be set")
+	}
+	if c.AfterCalls < 0 {
+		return fmt.Errorf("afterCalls must not be negative, got %d", c.AfterCalls)
+	}
+	if c.Times < 0 {
+		return fmt.Errorf("times must not be negative, got %d", c.Times)
+	}
+	if _, err := c.afterDuration(); err != nil {
+		return err
+	}
+	if _, err := c.restartDelay(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *ChaosConfig) afterDuration() (time.Duration, error) {
+	return parseChaosDuration("after", c.After)
+}
+
+func (c *ChaosConfig) restartDelay() (time.Duration, error) {
+	return parseChaosDuration("restartDelay", c.RestartDelay)
+}
+
+func (c *ChaosConfig) times() int {
+	if c.Times == 0 {
+		return 1
+	}
+	return c.Times
+}
+
+func parseChaosDuration(field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", field, value, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s must not be negative, got %s", field, value)
+	}
+	return d, nil
+}
+
+// upstream holds the client session of a server, which chaos mode replaces
+// when it restarts the server
+type upstream struct {
+	mu sync.RWMutex
+	cs *mcp.ClientSession
+	// cmd is the process of a stdio server, if the session started it
+	cmd *exec.Cmd
+	// release returns a pooled session to its ServerPool. Sessions that are
+	// not pooled are closed instead.
+	release func() error
+	closed  bool
+}
+
+// session returns the current client session
+func (u *upstream) session() *mcp.ClientSession {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.cs
+}
+
+// kill kills the process of the server and closes its session
+func (u *upstream) kill() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.cmd == nil || u.cmd.Process == nil {
+		return fmt.Errorf("server has no process to kill")
+	}
+	err := u.cmd.Process.Kill()
+	_ = u.cs.Close()
+	return err
+}
+
+// replace replaces the session with the one of a restarted server. It closes
+// the new session instead if the upstream was closed in the meantime.
+func (u *upstream) replace(cs *mcp.ClientSession, cmd *exec.Cmd) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.closed {
+		_ = cs.Close()
+		return fmt.Errorf("server was closed before it restarted")
+	}
+	u.cs, u.cmd = cs, cmd
+	return nil
+}
+
+// close releases the current session
+func (u *upstream) close() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.closed = true
+	if u.release != nil {
+		return u.release()
+	}
+	return u.cs.Close()
+}
+
+// chaosMonitor kills and restarts the server of a proxy following its chaos
+// config, and records the disruptions
+type chaosMonitor struct {
+	name     string
+	config   *ServerConfig
+	upstream *upstream
+	recorder Recorder
+
+	mu         sync.Mutex
+	ctx        context.Context
+	calls      int
+	total      int
+	disrupted  int
+	disrupting bool
+}
+
+// newChaosMonitor returns the monitor of a proxy, or nil if the server has no
+// chaos config. A nil monitor does nothing.
+func newChaosMonitor(name string, config *ServerConfig, u *upstream, r Recorder) *chaosMonitor {
+	if config.Chaos == nil {
+		return nil
+	}
+	return &chaosMonitor{
+		name:     name,
+		config:   config,
+		upstream: u,
+		recorder: r,
+		ctx:      context.Background(),
+	}
+}
+
+// start starts the timer of an after trigger. Disruptions stop with ctx.
+func (m *chaosMonitor) start(ctx context.Context) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	after, _ := m.config.Chaos.afterDuration()
+	if after == 0 {
+		return
+	}
+
+	go func() {
+		for range m.config.Chaos.times() {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(after):
+			}
+			if !m.begin() {
+				return
+			}
+			m.disrupt(ctx, ChaosTriggerTime)
+		}
+	}()
+}
+
+// toolCalled counts a tool call, and kills the server once afterCalls calls
+// were made since it last started
+func (m *chaosMonitor) toolCalled() {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.total++
+	m.calls++
+	trigger := m.config.Chaos.AfterCalls > 0 && m.calls >= m.config.Chaos.AfterCalls
+	ctx := m.ctx
+	m.mu.Unlock()
+
+	if trigger && m.begin() {
+		go m.disrupt(ctx, ChaosTriggerCalls)
+	}
+}
+
+// begin claims the next disruption, unless one is in progress or all were
+// made
+func (m *chaosMonitor) begin() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.disrupting || m.disrupted >= m.config.Chaos.times() {
+		return false
+	}
+	m.disrupting = true
+	m.disrupted++
+	return true
+}
+
+// disrupt kills the server, waits for the restart delay, and restarts it
+func (m *chaosMonitor) disrupt(ctx context.Context, trigger string) {
+	m.mu.Lock()
+	d := &Disruption{Timestamp: time.Now(), Trigger: trigger, Calls: m.total}
+	m.mu.Unlock()
+
+	err := m.upstream.kill()
+	if err == nil {
+		delay, _ := m.config.Chaos.restartDelay()
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	if err == nil {
+		var cs *mcp.ClientSession
+		var cmd *exec.Cmd
+		cs, cmd, err = startProxyClient(ctx, m.name, m.config)
+		if err == nil {
+			err = m.upstream.replace(cs, cmd)
+		}
+	}
+
+	d.Restarted = err == nil
+	d.Error = errorToString(err)
+	d.Downtime = time.Since(d.Timestamp)
+	m.recorder.RecordDisruption(d)
+
+	m.mu.Lock()
+	m.calls = 0
+	m.disrupting = false
+	m.mu.Unlock()
+}
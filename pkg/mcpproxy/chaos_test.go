@@ -0,0 +1,119 @@
+package mcpproxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		chaos       ChaosConfig
+		errContains string
+	}{
+		"after calls": {
+			chaos: ChaosConfig{AfterCalls: 3, RestartDelay: "2s", Times: 2},
+		},
+		"after time": {
+			chaos: ChaosConfig{After: "30s"},
+		},
+		"no trigger": {
+			chaos:       ChaosConfig{RestartDelay: "2s"},
+			errContains: "exactly one of afterCalls and after must be set",
+		},
+		"both triggers": {
+			chaos:       ChaosConfig{AfterCalls: 3, After: "30s"},
+			errContains: "exactly one of afterCalls and after must be set",
+		},
+		"invalid after": {
+			chaos:       ChaosConfig{After: "soon"},
+			errContains: `invalid after "soon"`,
+		},
+		"negative restart delay": {
+			chaos:       ChaosConfig{AfterCalls: 1, RestartDelay: "-1s"},
+			errContains: "restartDelay must not be negative",
+		},
+		"negative times": {
+			chaos:       ChaosConfig{AfterCalls: 1, Times: -1},
+			errContains: "times must not be negative",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.chaos.Validate()
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
+
+func TestChaosOnlyForStdioServers(t *testing.T) {
+	cfg := &ServerConfig{URL: "http://localhost:8080/mcp", Chaos: &ChaosConfig{AfterCalls: 1}}
+	assert.EqualError(t, cfg.Validate(), "chaos is only supported for stdio servers")
+}
+
+func TestChaosMonitorAfterCalls(t *testing.T) {
+	r := NewRecorder("k8s")
+	cfg := &ServerConfig{Command: "k8s", Chaos: &ChaosConfig{AfterCalls: 2, Times: 2}}
+	// The upstream has no process, so the kill fails and is recorded
+	m := newChaosMonitor("k8s", cfg, &upstream{}, r)
+
+	m.toolCalled()
+	assert.Empty(t, r.GetHistory().Disruptions)
+
+	m.toolCalled()
+	require.Eventually(t, func() bool { return len(r.GetHistory().Disruptions) == 1 }, time.Second, 10*time.Millisecond)
+
+	d := r.GetHistory().Disruptions[0]
+	assert.Equal(t, "k8s", d.ServerName)
+	assert.Equal(t, ChaosTriggerCalls, d.Trigger)
+	assert.Equal(t, 2, d.Calls)
+	assert.False(t, d.Restarted)
+	assert.Equal(t, "server has no process to kill", d.Error)
+
+	// Calls are counted again from the disruption, until times is reached
+	for range 3 {
+		waitForDisruption(t, m)
+		m.toolCalled()
+		m.toolCalled()
+	}
+	waitForDisruption(t, m)
+	assert.Len(t, r.GetHistory().Disruptions, 2)
+}
+
+// waitForDisruption waits until the monitor has no disruption in progress
+func waitForDisruption(t *testing.T, m *chaosMonitor) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return !m.disrupting
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestChaosMonitorAfterTime(t *testing.T) {
+	r := NewRecorder("k8s")
+	cfg := &ServerConfig{Command: "k8s", Chaos: &ChaosConfig{After: "10ms"}}
+	m := newChaosMonitor("k8s", cfg, &upstream{}, r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.start(ctx)
+
+	require.Eventually(t, func() bool { return len(r.GetHistory().Disruptions) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, ChaosTriggerTime, r.GetHistory().Disruptions[0].Trigger)
+}
+
+func TestNoChaosMonitor(t *testing.T) {
+	m := newChaosMonitor("k8s", &ServerConfig{Command: "k8s"}, &upstream{}, NewRecorder("k8s"))
+	assert.Nil(t, m)
+	m.start(context.Background())
+	m.toolCalled()
+}
@@ -3,29 +3,52 @@ package mcpproxy
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/yaml"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 const (
-	TransportTypeHttp  = "http"
-	TransportTypeStdio = "stdio"
+	TransportTypeHttp      = "http"
+	TransportTypeStdio     = "stdio"
+	TransportTypeWebSocket = "websocket"
 )
 
+// DefaultStartupTimeout is how long a server may take to initialize and pass its
+// readiness check when no startupTimeout is configured.
+const DefaultStartupTimeout = 60 * time.Second
+
 // MCPConfig represents the top-level MCP configuration file structure
 // used by Claude Code, Cursor, and other MCP clients.
 type MCPConfig struct {
 	MCPServers map[string]*ServerConfig `json:"mcpServers" yaml:"mcpServers"`
+
+	// Profiles are named sets of servers that can be layered on top of
+	// MCPServers when the profile is selected
+	Profiles map[string]*MCPProfile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+}
+
+// MCPProfile is a named set of servers in an MCP config file. When the profile
+// is selected, its servers are merged on top of the file's mcpServers.
+type MCPProfile struct {
+	MCPServers map[string]*ServerConfig `json:"mcpServers" yaml:"mcpServers"`
 }
 
 // ServerConfig represents the configuration for a single MCP server.
-// Supports both stdio (command-based) and HTTP-based servers.
+// Supports stdio (command-based), HTTP-based, and WebSocket-based servers.
 type ServerConfig struct {
-	// Type specifies the server type: "stdio" or "http"
-	// If not specified, will be inferred from URL (http) or Command (stdio)
+	// Type specifies the server type: "stdio", "http", or "websocket"
+	// If not specified, will be inferred from URL (http, or websocket for
+	// ws:// and wss:// URLs) or Command (stdio)
 	Type string `json:"type,omitempty"`
 
 	// Command is the executable to run (e.g., "node", "python", "npx")
@@ -40,15 +63,23 @@ type ServerConfig struct {
 	// Used for stdio servers
 	Env map[string]string `json:"env,omitempty"`
 
-	// URL is the HTTP endpoint for the MCP server
-	// Used for http servers. May contain environment variable references
+	// URL is the HTTP or WebSocket endpoint for the MCP server
+	// Used for http and websocket servers. May contain environment variable references
 	// like ${VAR} or ${VAR:-default}
 	URL string `json:"url,omitempty"`
 
 	// Headers are HTTP headers to send with requests
-	// Used for http servers. Values may contain environment variable references
+	// Used for http and websocket servers. Values may contain environment variable references
 	Headers map[string]string `json:"headers,omitempty"`
 
+	// Auth configures authentication to the upstream server
+	// Used for http and websocket servers
+	Auth *AuthConfig `json:"auth,omitempty"`
+
+	// TLS configures certificate verification and client certificates
+	// Used for http and websocket servers
+	TLS *TLSConfig `json:"tls,omitempty"`
+
 	// Disabled indicates whether this server should be skipped
 	Disabled bool `json:"disabled,omitempty"`
 
@@ -57,6 +88,41 @@ type ServerConfig struct {
 
 	// EnableAllTools sets all tools to be allowed
 	EnableAllTools bool `json:"enableAllTools"`
+
+	// StartupTimeout is the maximum time the server may take to initialize and
+	// respond to the readiness probe, as a Go duration string (e.g. "30s").
+	// Defaults to DefaultStartupTimeout
+	StartupTimeout string `json:"startupTimeout,omitempty"`
+
+	// ResultLimit truncates or paginates tool results above a size, as the
+	// agent sees them
+	ResultLimit *ResultLimitConfig `json:"resultLimit,omitempty"`
+
+	// ToolNaming is how the tools of the server are named for the agent:
+	// "collisions" (default) prefixes the tools whose names another server
+	// exposes too, "prefix" prefixes all tools, and "none" keeps their names
+	ToolNaming string `json:"toolNaming,omitempty"`
+
+	// ToolPrefix is the prefix of renamed tools. Defaults to the server name
+	// followed by an underscore
+	ToolPrefix string `json:"toolPrefix,omitempty"`
+
+	// ProtocolVersions are the MCP protocol versions the server may negotiate,
+	// e.g. "2025-06-18". Any version is accepted if empty
+	ProtocolVersions []string `json:"protocolVersions,omitempty"`
+
+	// ProtocolVersionMismatch is what happens if the server negotiates a
+	// version outside ProtocolVersions: "fail" (default) fails to start it,
+	// and "warn" starts it and warns
+	ProtocolVersionMismatch string `json:"protocolVersionMismatch,omitempty"`
+
+	// Chaos kills and restarts the server at a point of each task, to
+	// evaluate how the agent recovers. Only supported for stdio servers
+	Chaos *ChaosConfig `json:"chaos,omitempty"`
+
+	// Latency delays the requests the proxy forwards to the server by a
+	// duration drawn from a distribution, per server or tool
+	Latency *LatencyConfig `json:"latency,omitempty"`
 }
 
 // ParseConfigFile reads and parses an MCP config file from the given path.
@@ -67,7 +133,90 @@ func ParseConfigFile(path string) (*MCPConfig, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	return ParseConfig(data)
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for '%s': %w", path, err)
+	}
+
+	resolveTLSPaths := func(servers map[string]*ServerConfig) {
+		for _, server := range servers {
+			if server != nil && server.TLS != nil {
+				server.TLS.resolvePaths(filepath.Dir(absPath))
+			}
+		}
+	}
+
+	resolveTLSPaths(config.MCPServers)
+	for _, profile := range config.Profiles {
+		resolveTLSPaths(profile.MCPServers)
+	}
+
+	return config, nil
+}
+
+// ResolvePaths makes the relative paths of a server defined outside an MCP
+// config file, such as in a task, absolute, relative to basePath. A command
+// is resolved if it is a path like ./server, while a command without a path
+// separator is looked up in PATH when the server starts.
+func (s *ServerConfig) ResolvePaths(basePath string) {
+	if isRelativeCommandPath(s.Command) {
+		s.Command = filepath.Join(basePath, filepath.FromSlash(s.Command))
+	}
+	if s.TLS != nil {
+		s.TLS.resolvePaths(basePath)
+	}
+}
+
+// isRelativeCommandPath reports whether a command is a relative path rather
+// than a name looked up in PATH
+func isRelativeCommandPath(command string) bool {
+	if command == "" || filepath.IsAbs(command) || strings.HasPrefix(command, "${") {
+		return false
+	}
+	return strings.ContainsRune(command, '/') || strings.ContainsRune(command, filepath.Separator)
+}
+
+// LoadConfigFiles parses and merges multiple MCP config files. Files are applied
+// in order, so servers in later files replace servers with the same name in
+// earlier files, and a server marked as disabled removes it.
+//
+// If profile is set, the servers from that profile are merged on top of each
+// file's mcpServers, before the next file is applied. It is an error if no file
+// defines the profile.
+func LoadConfigFiles(paths []string, profile string) (*MCPConfig, error) {
+	merged := &MCPConfig{
+		MCPServers: make(map[string]*ServerConfig),
+	}
+
+	profileFound := false
+	for _, path := range paths {
+		cfg, err := ParseConfigFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MCP config '%s': %w", path, err)
+		}
+
+		merged = merged.WithOverrides(cfg.MCPServers)
+
+		if profile == "" {
+			continue
+		}
+
+		if p, ok := cfg.Profiles[profile]; ok {
+			profileFound = true
+			merged = merged.WithOverrides(p.MCPServers)
+		}
+	}
+
+	if profile != "" && !profileFound {
+		return nil, fmt.Errorf("MCP config profile %q not found in any of: %s", profile, strings.Join(paths, ", "))
+	}
+
+	return merged, nil
 }
 
 // ParseConfig parses MCP config data from bytes.
@@ -89,25 +238,288 @@ func ParseConfig(data []byte) (*MCPConfig, error) {
 
 // validateConfig validates the parsed configuration.
 func validateConfig(config *MCPConfig) error {
-	if config.MCPServers == nil {
+	if config.MCPServers == nil && len(config.Profiles) == 0 {
 		return fmt.Errorf("mcpServers field is required")
 	}
 
-	for name, server := range config.MCPServers {
-		if server.IsHttp() {
-			if server.URL == "" {
-				return fmt.Errorf("server %q: url is required for http servers", name)
-			}
-		} else if server.IsStdio() {
-			if server.Command == "" {
-				return fmt.Errorf("server %q: command is required for stdio servers", name)
+	if err := validateServers(config.MCPServers); err != nil {
+		return err
+	}
+
+	for name, profile := range config.Profiles {
+		if profile == nil {
+			return fmt.Errorf("profile %q: mcpServers field is required", name)
+		}
+		if err := validateServers(profile.MCPServers); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateServers(servers map[string]*ServerConfig) error {
+	for name, server := range servers {
+		if server == nil {
+			return fmt.Errorf("server %q: config must not be empty", name)
+		}
+		// Disabled entries may be partial, e.g. when used to remove a server
+		// defined in another file
+		if server.Disabled {
+			continue
+		}
+		if err := server.Validate(); err != nil {
+			return fmt.Errorf("server %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the server config is complete for its transport type.
+func (s *ServerConfig) Validate() error {
+	if s.IsHttp() {
+		if s.URL == "" {
+			return fmt.Errorf("url is required for http servers")
+		}
+	} else if s.IsWebSocket() {
+		if s.URL == "" {
+			return fmt.Errorf("url is required for websocket servers")
+		}
+	} else if s.IsStdio() {
+		if s.Command == "" {
+			return fmt.Errorf("command is required for stdio servers")
+		}
+	} else {
+		return fmt.Errorf("must specify either command or url")
+	}
+
+	if _, err := s.GetStartupTimeout(); err != nil {
+		return err
+	}
+
+	if s.Auth != nil {
+		if s.IsStdio() {
+			return fmt.Errorf("auth is only supported for http and websocket servers")
+		}
+		if err := s.Auth.Validate(); err != nil {
+			return fmt.Errorf("invalid auth: %w", err)
+		}
+	}
+
+	if s.TLS != nil {
+		if s.IsStdio() {
+			return fmt.Errorf("tls is only supported for http and websocket servers")
+		}
+		if err := s.TLS.Validate(); err != nil {
+			return fmt.Errorf("invalid tls: %w", err)
+		}
+	}
+
+	if s.ResultLimit != nil {
+		if err := s.ResultLimit.Validate(); err != nil {
+			return fmt.Errorf("invalid resultLimit: %w", err)
+		}
+	}
+
+	if err := validateToolNaming(s.ToolNaming); err != nil {
+		return err
+	}
+
+	if err := validateProtocolVersionMismatch(s.ProtocolVersionMismatch); err != nil {
+		return err
+	}
+
+	if s.Chaos != nil {
+		if !s.IsStdio() {
+			return fmt.Errorf("chaos is only supported for stdio servers")
+		}
+		if err := s.Chaos.Validate(); err != nil {
+			return fmt.Errorf("invalid chaos: %w", err)
+		}
+	}
+
+	if s.Latency != nil {
+		if err := s.Latency.Validate(); err != nil {
+			return fmt.Errorf("invalid latency: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Redacted returns a copy of the config as the server is started, with
+// environment variable references expanded, for recording with results.
+// Auth secrets, URL passwords, and the values of headers, environment
+// variables, endpoint params, and URL query params whose names look like
+// secrets are redacted, as are references to variables whose names do.
+func (s *ServerConfig) Redacted() *ServerConfig {
+	redacted := *s
+	redacted.URL = redactURL(redactedExpandEnv(s.URL))
+	redacted.Headers = redactedExpandEnvMap(s.Headers)
+	redacted.Env = redactedExpandEnvMap(s.Env)
+
+	if s.Auth != nil {
+		auth := *s.Auth
+		auth.TokenURL = redactURL(redactedExpandEnv(auth.TokenURL))
+		auth.ClientID = redactedExpandEnv(auth.ClientID)
+		if auth.Token != "" {
+			auth.Token = util.Redacted
+		}
+		if auth.ClientSecret != "" {
+			auth.ClientSecret = util.Redacted
+		}
+		auth.EndpointParams = redactedExpandEnvMap(auth.EndpointParams)
+		redacted.Auth = &auth
+	}
+
+	return &redacted
+}
+
+// redactedExpandEnv is expandEnv with references to variables whose names
+// look like secrets replaced by util.Redacted
+func redactedExpandEnv(s string) string {
+	return envRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if util.IsSecretName(envRefPattern.FindStringSubmatch(ref)[1]) {
+			return util.Redacted
+		}
+		return expandEnv(ref)
+	})
+}
+
+// redactedExpandEnvMap returns a copy of m with redactedExpandEnv applied to
+// every value, and the values of secret names redacted
+func redactedExpandEnvMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(m))
+	for k, v := range util.RedactSecretValues(m) {
+		redacted[k] = redactedExpandEnv(v)
+	}
+	return redacted
+}
+
+// redactURL redacts the password and the values of secret-named query params
+// of a URL
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.User == nil && u.RawQuery == "") {
+		return rawURL
+	}
+
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		for i, param := range params {
+			name, _, ok := strings.Cut(param, "=")
+			if ok && util.IsSecretName(name) {
+				params[i] = name + "=" + util.Redacted
 			}
+		}
+		u.RawQuery = strings.Join(params, "&")
+	}
+
+	if u.User != nil {
+		return u.Redacted()
+	}
+	return u.String()
+}
+
+// WithOverrides returns a copy of the config with the given servers added,
+// replacing any existing servers with the same name. An override marked as
+// disabled removes the server of that name. The receiver is not modified.
+func (c *MCPConfig) WithOverrides(overrides map[string]*ServerConfig) *MCPConfig {
+	merged := &MCPConfig{
+		MCPServers: make(map[string]*ServerConfig),
+	}
+
+	if c != nil {
+		maps.Copy(merged.MCPServers, c.MCPServers)
+	}
+
+	for name, server := range overrides {
+		if server.Disabled {
+			delete(merged.MCPServers, name)
+			continue
+		}
+		merged.MCPServers[name] = server
+	}
+
+	return merged
+}
+
+// WithOnly returns a copy of the config with only the named servers. It
+// returns an error naming the servers that the config does not have, or that
+// are disabled. The receiver is not modified.
+func (c *MCPConfig) WithOnly(names []string) (*MCPConfig, error) {
+	only := &MCPConfig{
+		MCPServers: make(map[string]*ServerConfig, len(names)),
+	}
+
+	var missing []string
+	for _, name := range names {
+		server, ok := c.GetServer(name)
+		if !ok || server.Disabled {
+			missing = append(missing, name)
+			continue
+		}
+		only.MCPServers[name] = server
+	}
+
+	if len(missing) > 0 {
+		configured := slices.Sorted(maps.Keys(c.GetEnabledServers()))
+		if len(configured) == 0 {
+			configured = []string{"none"}
+		}
+		return nil, fmt.Errorf("MCP config is missing required servers: %s (it has: %s)",
+			strings.Join(missing, ", "), strings.Join(configured, ", "))
+	}
+
+	return only, nil
+}
+
+// WithMetadata returns a copy of the config with the given headers added to
+// its http and websocket servers, and the given environment variables added
+// to its stdio servers. Headers and variables a server sets itself are kept.
+// The receiver is not modified.
+func (c *MCPConfig) WithMetadata(headers, env map[string]string) *MCPConfig {
+	merged := &MCPConfig{
+		MCPServers: make(map[string]*ServerConfig, len(c.MCPServers)),
+	}
+
+	for name, server := range c.MCPServers {
+		s := *server
+		if s.IsStdio() {
+			s.Env = withDefaults(server.Env, env, func(key string) string { return key })
 		} else {
-			return fmt.Errorf("server %q: must specify either command or url", name)
+			s.Headers = withDefaults(server.Headers, headers, http.CanonicalHeaderKey)
 		}
+		merged.MCPServers[name] = &s
 	}
 
-	return nil
+	return merged
+}
+
+// withDefaults returns a copy of values with the defaults added, unless a key
+// that is the same after normalize is already set
+func withDefaults(values, defaults map[string]string, normalize func(string) string) map[string]string {
+	if len(defaults) == 0 {
+		return values
+	}
+
+	set := make(map[string]bool, len(values))
+	merged := make(map[string]string, len(values)+len(defaults))
+	for key, value := range values {
+		set[normalize(key)] = true
+		merged[key] = value
+	}
+	for key, value := range defaults {
+		if !set[normalize(key)] {
+			merged[key] = value
+		}
+	}
+	return merged
 }
 
 // GetEnabledServers returns a map of server names to their configurations,
@@ -145,10 +557,10 @@ func (c *MCPConfig) ToFile(path string) error {
 
 // IsStdio returns true if this is a stdio-based (command) server.
 func (s *ServerConfig) IsStdio() bool {
-	if s.Type == "stdio" {
+	if s.Type == TransportTypeStdio {
 		return true
 	}
-	if s.Type == "http" {
+	if s.Type == TransportTypeHttp || s.Type == TransportTypeWebSocket {
 		return false
 	}
 	// Type not specified - infer from fields
@@ -157,14 +569,44 @@ func (s *ServerConfig) IsStdio() bool {
 
 // IsHttp returns true if this is an HTTP-based server.
 func (s *ServerConfig) IsHttp() bool {
-	if s.Type == "http" {
+	if s.Type == TransportTypeHttp {
 		return true
 	}
-	if s.Type == "stdio" {
+	if s.Type == TransportTypeStdio || s.Type == TransportTypeWebSocket {
 		return false
 	}
 	// Type not specified - infer from fields
-	return s.URL != ""
+	return s.URL != "" && !isWebSocketURL(s.URL)
+}
+
+// IsWebSocket returns true if this is a WebSocket-based server.
+func (s *ServerConfig) IsWebSocket() bool {
+	if s.Type == TransportTypeWebSocket {
+		return true
+	}
+	if s.Type == TransportTypeStdio || s.Type == TransportTypeHttp {
+		return false
+	}
+	// Type not specified - infer from fields
+	return s.URL != "" && isWebSocketURL(s.URL)
+}
+
+// GetStartupTimeout returns the parsed startup timeout for the server, falling
+// back to DefaultStartupTimeout if none is set.
+func (s *ServerConfig) GetStartupTimeout() (time.Duration, error) {
+	if s.StartupTimeout == "" {
+		return DefaultStartupTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(s.StartupTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid startupTimeout %q: %w", s.StartupTimeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid startupTimeout %q: must be positive", s.StartupTimeout)
+	}
+
+	return timeout, nil
 }
 
 // Environment variable names for MCP configuration
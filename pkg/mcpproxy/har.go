@@ -0,0 +1,430 @@
+package mcpproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// maxCapturedBody is the largest request or response body kept in a HAR
+// entry. Longer bodies are truncated.
+const maxCapturedBody = 1 << 20
+
+// redacted replaces the credentials in captured headers
+const redacted = "[REDACTED]"
+
+// TrafficRecorder captures the HTTP requests and responses between the proxy
+// and upstream http and websocket MCP servers, to debug transport-level or
+// auth issues that the MCP-level call history hides. The traffic is written in
+// HAR format. Credentials in Authorization, cookie, and other headers whose
+// names look like secrets are redacted, and so are the values of secret-named
+// form fields and top-level JSON keys in bodies, such as those of OAuth2 token
+// requests and responses.
+type TrafficRecorder struct {
+	mu      sync.Mutex
+	entries []*harEntry
+}
+
+// NewTrafficRecorder creates an empty TrafficRecorder
+func NewTrafficRecorder() *TrafficRecorder {
+	return &TrafficRecorder{}
+}
+
+type trafficRecorderKey struct{}
+
+// TrafficRecorderToContext returns a context that carries the given recorder.
+// Proxy servers created with this context capture their upstream traffic in it.
+func TrafficRecorderToContext(ctx context.Context, recorder *TrafficRecorder) context.Context {
+	return context.WithValue(ctx, trafficRecorderKey{}, recorder)
+}
+
+// TrafficRecorderFromContext returns the recorder stored in ctx, if any
+func TrafficRecorderFromContext(ctx context.Context) (*TrafficRecorder, bool) {
+	recorder, ok := ctx.Value(trafficRecorderKey{}).(*TrafficRecorder)
+	return recorder, ok && recorder != nil
+}
+
+// Len returns the number of captured requests
+func (t *TrafficRecorder) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.entries)
+}
+
+// WriteHAR writes the captured traffic to path as a HAR 1.2 file, ordered by
+// the time the requests were sent. Responses that are still streaming are
+// written as received so far.
+func (t *TrafficRecorder) WriteHAR(path string) error {
+	t.mu.Lock()
+	entries := slices.Clone(t.entries)
+	slices.SortStableFunc(entries, func(a, b *harEntry) int {
+		return a.started.Compare(b.started)
+	})
+	for _, entry := range entries {
+		entry.Response.Content.Text = redactBody(entry.Response.Content.MimeType, string(entry.body))
+	}
+	data, err := json.MarshalIndent(harFile{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "mcpchecker", Version: buildVersion()},
+		Entries: entries,
+	}}, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// roundTripper wraps base with a transport that captures the traffic to the
+// named server
+func (t *TrafficRecorder) roundTripper(server string, base http.RoundTripper) http.RoundTripper {
+	return &harRoundTripper{recorder: t, server: server, base: base}
+}
+
+type harRoundTripper struct {
+	recorder *TrafficRecorder
+	server   string
+	base     http.RoundTripper
+}
+
+func (h *harRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	entry := &harEntry{
+		started:         start,
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		Server:          h.server,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []struct{}{},
+			Headers:     harHeaders(req.Header),
+			QueryString: harQuery(req),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Cache: struct{}{},
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		// The request must not be modified, so the body is sent from a clone
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(data))
+
+		text, comment := truncateBody(data)
+		entry.Request.BodySize = len(data)
+		entry.Request.PostData = &harPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     redactBody(req.Header.Get("Content-Type"), text),
+			Comment:  comment,
+		}
+	}
+
+	resp, err := h.base.RoundTrip(req)
+	wait := time.Since(start)
+	entry.Time = durationMillis(wait)
+	entry.Timings = harTimings{Send: 0, Wait: durationMillis(wait), Receive: 0}
+
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Response = harResponse{
+			Cookies:     []struct{}{},
+			Headers:     []harNameValue{},
+			Content:     harContent{Size: 0},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+		h.recorder.add(entry)
+		return nil, err
+	}
+
+	entry.Response = harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []struct{}{},
+		Headers:     harHeaders(resp.Header),
+		Content:     harContent{MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	h.recorder.add(entry)
+
+	// The body of a switched protocol is the websocket connection itself,
+	// which must be left alone
+	if resp.StatusCode == http.StatusSwitchingProtocols || resp.Body == nil {
+		return resp, nil
+	}
+
+	resp.Body = &harBody{
+		ReadCloser: resp.Body,
+		recorder:   h.recorder,
+		entry:      entry,
+		start:      start,
+	}
+	return resp, nil
+}
+
+func (t *TrafficRecorder) add(entry *harEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.entries = append(t.entries, entry)
+}
+
+// harBody captures a response body as it is read, which for streamed
+// responses may go on for the whole session
+type harBody struct {
+	io.ReadCloser
+	recorder *TrafficRecorder
+	entry    *harEntry
+	start    time.Time
+	size     int
+	done     bool
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.recorder.mu.Lock()
+	b.size += n
+	if room := maxCapturedBody - len(b.entry.body); room > 0 {
+		b.entry.body = append(b.entry.body, p[:min(n, room)]...)
+	}
+	if b.size > maxCapturedBody {
+		b.entry.Response.Content.Comment = "truncated"
+	}
+	b.entry.Response.Content.Size = b.size
+	if err != nil {
+		b.finish()
+	}
+	b.recorder.mu.Unlock()
+
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	b.recorder.mu.Lock()
+	b.finish()
+	b.recorder.mu.Unlock()
+
+	return b.ReadCloser.Close()
+}
+
+// finish records the time the body took. The caller must hold the lock of the
+// recorder.
+func (b *harBody) finish() {
+	if b.done {
+		return
+	}
+	b.done = true
+
+	b.entry.Response.BodySize = b.size
+	b.entry.Time = durationMillis(time.Since(b.start))
+	b.entry.Timings.Receive = b.entry.Time - b.entry.Timings.Wait
+}
+
+// harHeaders converts headers to HAR, redacting credentials
+func harHeaders(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[name] {
+			headers = append(headers, harNameValue{Name: name, Value: redactHeader(name, value)})
+		}
+	}
+	return headers
+}
+
+func harQuery(req *http.Request) []harNameValue {
+	query := []harNameValue{}
+	values := req.URL.Query()
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		for _, value := range values[name] {
+			query = append(query, harNameValue{Name: name, Value: value})
+		}
+	}
+	return query
+}
+
+// redactHeader hides the credentials in a header value, keeping the scheme of
+// authorization headers
+func redactHeader(name, value string) string {
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Proxy-Authorization":
+		if scheme, _, ok := strings.Cut(value, " "); ok {
+			return scheme + " " + redacted
+		}
+		return redacted
+	case "Cookie", "Set-Cookie":
+		return redacted
+	}
+	if util.IsSecretName(name) {
+		return redacted
+	}
+	return value
+}
+
+// redactBody hides the values of the secret-named fields of a form body, or
+// of the secret-named top-level keys of a JSON object body, such as the
+// client secret and tokens of OAuth2 token requests. Other bodies are kept.
+func redactBody(mimeType, text string) string {
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(text)
+		if err != nil {
+			return text
+		}
+		changed := false
+		for name := range values {
+			if util.IsSecretName(name) {
+				values[name] = []string{redacted}
+				changed = true
+			}
+		}
+		if changed {
+			return values.Encode()
+		}
+	case "application/json":
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			return text
+		}
+		changed := false
+		for name := range fields {
+			if util.IsSecretName(name) {
+				fields[name] = json.RawMessage(`"` + redacted + `"`)
+				changed = true
+			}
+		}
+		if changed {
+			if data, err := json.Marshal(fields); err == nil {
+				return string(data)
+			}
+		}
+	}
+	return text
+}
+
+// truncateBody returns the body as text, and a comment if it was truncated
+func truncateBody(data []byte) (string, string) {
+	if len(data) > maxCapturedBody {
+		return string(data[:maxCapturedBody]), "truncated"
+	}
+	return string(data), ""
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// buildVersion returns the module version of the binary, if known
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string      `json:"version"`
+	Creator harCreator  `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	started time.Time
+	// body is the response body read so far
+	body []byte
+
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	// Server is the name of the MCP server, and Error the transport error
+	// of a request that got no response
+	Server string `json:"_server"`
+	Error  string `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []struct{}     `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []struct{}     `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
@@ -0,0 +1,236 @@
+package mcpproxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readHAR writes the traffic of the recorder to a file and reads it back
+func readHAR(t *testing.T, recorder *TrafficRecorder) harFile {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "traffic.har")
+	require.NoError(t, recorder.WriteHAR(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var har harFile
+	require.NoError(t, json.Unmarshal(data, &har))
+	return har
+}
+
+func harHeader(headers []harNameValue, name string) string {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+func TestTrafficRecorderHTTP(t *testing.T) {
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "echo", Version: "0.0.1"}, nil)
+	mcp.AddTool(upstream, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, in echoInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: in.Text}}}, nil, nil
+	})
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return upstream }, nil))
+	defer srv.Close()
+
+	recorder := NewTrafficRecorder()
+	ctx := TrafficRecorderToContext(context.Background(), recorder)
+
+	cfg := &ServerConfig{
+		URL:  srv.URL,
+		Auth: &AuthConfig{Type: AuthTypeBearer, Token: "secret-token"},
+	}
+	cs, err := createProxyClient(ctx, "echo", cfg)
+	require.NoError(t, err)
+
+	_, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: echoInput{Text: "captured text"}})
+	require.NoError(t, err)
+	require.NoError(t, cs.Close())
+
+	har := readHAR(t, recorder)
+	assert.Equal(t, "1.2", har.Log.Version)
+	assert.Equal(t, "mcpchecker", har.Log.Creator.Name)
+	require.NotEmpty(t, har.Log.Entries)
+
+	first := har.Log.Entries[0]
+	assert.Equal(t, "echo", first.Server)
+	assert.Equal(t, http.MethodPost, first.Request.Method)
+	assert.Equal(t, srv.URL, first.Request.URL)
+	require.NotNil(t, first.Request.PostData)
+	assert.Contains(t, first.Request.PostData.Text, `"initialize"`)
+	assert.Equal(t, http.StatusOK, first.Response.Status)
+
+	var call *harEntry
+	for _, entry := range har.Log.Entries {
+		assert.Equal(t, "Bearer [REDACTED]", harHeader(entry.Request.Headers, "Authorization"))
+		assert.NotContains(t, entry.Response.Content.Text, "secret-token")
+		if entry.Request.PostData != nil && strings.Contains(entry.Request.PostData.Text, "tools/call") {
+			call = entry
+		}
+	}
+	require.NotNil(t, call, "tool call not captured")
+	assert.Contains(t, call.Response.Content.Text, "captured text")
+	assert.Equal(t, len(call.Response.Content.Text), call.Response.Content.Size)
+}
+
+func TestTrafficRecorderWebSocket(t *testing.T) {
+	gotHeaders := make(chan http.Header, 1)
+	srv := newWebSocketTestServer(t, gotHeaders)
+	defer srv.Close()
+
+	recorder := NewTrafficRecorder()
+	ctx := TrafficRecorderToContext(context.Background(), recorder)
+
+	cs, err := createProxyClient(ctx, "ws", &ServerConfig{URL: "ws" + strings.TrimPrefix(srv.URL, "http")})
+	require.NoError(t, err)
+	defer cs.Close()
+	<-gotHeaders
+
+	// The websocket still works with its upgrade captured
+	_, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: echoInput{Text: "hello"}})
+	require.NoError(t, err)
+
+	har := readHAR(t, recorder)
+	require.Len(t, har.Log.Entries, 1)
+	assert.Equal(t, http.StatusSwitchingProtocols, har.Log.Entries[0].Response.Status)
+	assert.Equal(t, "websocket", strings.ToLower(harHeader(har.Log.Entries[0].Response.Headers, "Upgrade")))
+}
+
+func TestTrafficRecorderTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	recorder := NewTrafficRecorder()
+	ctx := TrafficRecorderToContext(context.Background(), recorder)
+
+	_, err := createProxyClient(ctx, "down", &ServerConfig{URL: url, StartupTimeout: "2s"})
+	require.Error(t, err)
+
+	har := readHAR(t, recorder)
+	require.NotEmpty(t, har.Log.Entries)
+	assert.Equal(t, 0, har.Log.Entries[0].Response.Status)
+	assert.Contains(t, har.Log.Entries[0].Error, "connect")
+}
+
+func TestRedactHeader(t *testing.T) {
+	tests := map[string]struct {
+		name  string
+		value string
+		want  string
+	}{
+		"bearer":         {name: "Authorization", value: "Bearer abc", want: "Bearer [REDACTED]"},
+		"no scheme":      {name: "authorization", value: "abc", want: "[REDACTED]"},
+		"proxy":          {name: "Proxy-Authorization", value: "Basic abc", want: "Basic [REDACTED]"},
+		"cookie":         {name: "Cookie", value: "session=abc", want: "[REDACTED]"},
+		"set cookie":     {name: "Set-Cookie", value: "session=abc", want: "[REDACTED]"},
+		"api key":        {name: "X-Api-Key", value: "abc", want: "[REDACTED]"},
+		"other":          {name: "X-Api-Version", value: "2", want: "2"},
+		"mcp session id": {name: "Mcp-Session-Id", value: "abc", want: "abc"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, redactHeader(tc.name, tc.value))
+		})
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := map[string]struct {
+		mimeType string
+		text     string
+		want     string
+	}{
+		"token request": {
+			mimeType: "application/x-www-form-urlencoded",
+			text:     "client_id=mcp&client_secret=abc&grant_type=client_credentials",
+			want:     "client_id=mcp&client_secret=%5BREDACTED%5D&grant_type=client_credentials",
+		},
+		"token response": {
+			mimeType: "application/json; charset=utf-8",
+			text:     `{"access_token":"abc","expires_in":3600,"token_type":"Bearer"}`,
+			want:     `{"access_token":"[REDACTED]","expires_in":3600,"token_type":"[REDACTED]"}`,
+		},
+		"json-rpc": {
+			mimeType: "application/json",
+			text:     `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+			want:     `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		},
+		"event stream": {
+			mimeType: "text/event-stream",
+			text:     `data: {"access_token":"abc"}`,
+			want:     `data: {"access_token":"abc"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, redactBody(tc.mimeType, tc.text))
+		})
+	}
+}
+
+func TestTrafficRecorderOAuth2(t *testing.T) {
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "issued-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer tokenSrv.Close()
+
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "echo", Version: "0.0.1"}, nil)
+	mcp.AddTool(upstream, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, in echoInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: in.Text}}}, nil, nil
+	})
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return upstream }, nil))
+	defer srv.Close()
+
+	t.Setenv("MCP_TEST_API_KEY", "configured-api-key")
+	recorder := NewTrafficRecorder()
+	ctx := TrafficRecorderToContext(context.Background(), recorder)
+
+	cs, err := createProxyClient(ctx, "echo", &ServerConfig{
+		URL:     srv.URL,
+		Headers: map[string]string{"X-Api-Key": "${MCP_TEST_API_KEY}"},
+		Auth: &AuthConfig{
+			Type:         AuthTypeOAuth2,
+			TokenURL:     tokenSrv.URL,
+			ClientID:     "client",
+			ClientSecret: "client-secret",
+		},
+	})
+	require.NoError(t, err)
+	_, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: echoInput{Text: "hello"}})
+	require.NoError(t, err)
+	require.NoError(t, cs.Close())
+
+	path := filepath.Join(t.TempDir(), "traffic.har")
+	require.NoError(t, recorder.WriteHAR(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	har := string(data)
+	assert.NotContains(t, har, tokenSrv.URL, "token requests are not captured")
+	assert.NotContains(t, har, "issued-access-token")
+	assert.NotContains(t, har, "client-secret")
+	assert.NotContains(t, har, "configured-api-key")
+	assert.Contains(t, har, "tools/call")
+}
@@ -0,0 +1,223 @@
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Distributions of injected latency
+const (
+	LatencyFixed   = "fixed"
+	LatencyUniform = "uniform"
+	LatencyNormal  = "normal"
+	LatencyPareto  = "pareto"
+)
+
+// LatencyConfig delays the requests the proxy forwards to a server by a
+// random duration, to evaluate how agents cope with slow backends
+type LatencyConfig struct {
+	LatencyDistribution `json:",inline"`
+
+	// Tools overrides the distribution for some tools, by name on the server
+	Tools map[string]*LatencyDistribution `json:"tools,omitempty"`
+}
+
+// LatencyDistribution is a distribution of delays. Durations are Go duration
+// strings (e.g. "250ms").
+type LatencyDistribution struct {
+	// Distribution is "fixed" (default), "uniform", "normal", or "pareto"
+	Distribution string `json:"distribution,omitempty"`
+
+	// Delay is the delay of the fixed distribution
+	Delay string `json:"delay,omitempty"`
+
+	// Min is the smallest delay of the uniform distribution. Defaults to 0
+	Min string `json:"min,omitempty"`
+
+	// Max is the largest delay of the uniform distribution. It caps the
+	// delays of the normal and pareto distributions if set
+	Max string `json:"max,omitempty"`
+
+	// Mean and StdDev are the mean and standard deviation of the normal
+	// distribution. Negative delays are treated as 0
+	Mean   string `json:"mean,omitempty"`
+	StdDev string `json:"stddev,omitempty"`
+
+	// Scale is the smallest delay of the pareto distribution, and Shape is its
+	// shape. Smaller shapes give a longer tail. Shape defaults to 1.16, for
+	// which 20% of the requests take 80% of the delay
+	Scale string  `json:"scale,omitempty"`
+	Shape float64 `json:"shape,omitempty"`
+}
+
+// defaultParetoShape is the shape of the 80/20 pareto distribution
+const defaultParetoShape = 1.16
+
+// Validate checks that the distribution of the server and of each tool has
+// the parameters it needs
+func (c *LatencyConfig) Validate() error {
+	if !c.LatencyDistribution.isZero() {
+		if err := c.LatencyDistribution.Validate(); err != nil {
+			return err
+		}
+	}
+	for name, tool := range c.Tools {
+		if tool == nil {
+			return fmt.Errorf("tool %q: latency must not be empty", name)
+		}
+		if err := tool.Validate(); err != nil {
+			return fmt.Errorf("tool %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the distribution has the parameters it needs
+func (d *LatencyDistribution) Validate() error {
+	p, err := d.parse()
+	if err != nil {
+		return err
+	}
+
+	switch d.Distribution {
+	case "", LatencyFixed:
+		if d.Delay == "" {
+			return fmt.Errorf("delay is required for the fixed distribution")
+		}
+	case LatencyUniform:
+		if d.Max == "" {
+			return fmt.Errorf("max is required for the uniform distribution")
+		}
+		if p.min > p.max {
+			return fmt.Errorf("min must not be greater than max")
+		}
+	case LatencyNormal:
+		if d.Mean == "" {
+			return fmt.Errorf("mean is required for the normal distribution")
+		}
+	case LatencyPareto:
+		if d.Scale == "" {
+			return fmt.Errorf("scale is required for the pareto distribution")
+		}
+		if d.Shape < 0 {
+			return fmt.Errorf("shape must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown distribution %q, must be %q, %q, %q, or %q", d.Distribution, LatencyFixed, LatencyUniform, LatencyNormal, LatencyPareto)
+	}
+	return nil
+}
+
+func (d *LatencyDistribution) isZero() bool {
+	return *d == LatencyDistribution{}
+}
+
+// latencyParams are the parsed durations of a distribution
+type latencyParams struct {
+	delay, min, max, mean, stdDev, scale time.Duration
+}
+
+func (d *LatencyDistribution) parse() (latencyParams, error) {
+	var p latencyParams
+	for _, f := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"delay", d.Delay, &p.delay},
+		{"min", d.Min, &p.min},
+		{"max", d.Max, &p.max},
+		{"mean", d.Mean, &p.mean},
+		{"stddev", d.StdDev, &p.stdDev},
+		{"scale", d.Scale, &p.scale},
+	} {
+		if f.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(f.value)
+		if err != nil {
+			return p, fmt.Errorf("invalid %s %q: %w", f.name, f.value, err)
+		}
+		if v < 0 {
+			return p, fmt.Errorf("%s must not be negative, got %s", f.name, f.value)
+		}
+		*f.dst = v
+	}
+	return p, nil
+}
+
+// sample draws a delay from the distribution
+func (d *LatencyDistribution) sample() time.Duration {
+	p, err := d.parse()
+	if err != nil {
+		return 0
+	}
+
+	var delay time.Duration
+	switch d.Distribution {
+	case "", LatencyFixed:
+		return p.delay
+	case LatencyUniform:
+		return p.min + time.Duration(rand.Float64()*float64(p.max-p.min))
+	case LatencyNormal:
+		delay = max(0, p.mean+time.Duration(rand.NormFloat64()*float64(p.stdDev)))
+	case LatencyPareto:
+		shape := d.Shape
+		if shape == 0 {
+			shape = defaultParetoShape
+		}
+		// Inverse transform sampling, with 1-U in (0, 1] to avoid dividing
+		// by zero
+		delay = time.Duration(min(float64(p.scale)/math.Pow(1-rand.Float64(), 1/shape), math.MaxInt64))
+	}
+
+	if d.Max != "" {
+		delay = min(delay, p.max)
+	}
+	return delay
+}
+
+// forTool returns the distribution of a tool, or nil if its calls are not
+// delayed
+func (c *LatencyConfig) forTool(name string) *LatencyDistribution {
+	if c == nil {
+		return nil
+	}
+	if tool, ok := c.Tools[name]; ok {
+		return tool
+	}
+	return c.forServer()
+}
+
+// forServer returns the distribution of the requests of the server that are
+// not tool calls, or nil if they are not delayed
+func (c *LatencyConfig) forServer() *LatencyDistribution {
+	if c == nil || c.LatencyDistribution.isZero() {
+		return nil
+	}
+	return &c.LatencyDistribution
+}
+
+// injectLatency waits for a delay drawn from d, or until ctx is done. A nil d
+// does not wait.
+func injectLatency(ctx context.Context, d *LatencyDistribution) error {
+	if d == nil {
+		return nil
+	}
+	delay := d.sample()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
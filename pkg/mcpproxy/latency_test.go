@@ -0,0 +1,190 @@
+package mcpproxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		latency     LatencyConfig
+		errContains string
+	}{
+		"fixed": {
+			latency: LatencyConfig{LatencyDistribution: LatencyDistribution{Delay: "100ms"}},
+		},
+		"uniform": {
+			latency: LatencyConfig{LatencyDistribution: LatencyDistribution{Distribution: LatencyUniform, Min: "10ms", Max: "1s"}},
+		},
+		"normal": {
+			latency: LatencyConfig{LatencyDistribution: LatencyDistribution{Distribution: LatencyNormal, Mean: "200ms", StdDev: "50ms"}},
+		},
+		"pareto": {
+			latency: LatencyConfig{LatencyDistribution: LatencyDistribution{Distribution: LatencyPareto, Scale: "50ms", Shape: 2, Max: "5s"}},
+		},
+		"tools only": {
+			latency: LatencyConfig{Tools: map[string]*LatencyDistribution{"pods_log": {Delay: "1s"}}},
+		},
+		"fixed without delay": {
+			latency:     LatencyConfig{LatencyDistribution: LatencyDistribution{Distribution: LatencyFixed}},
+			errContains: "delay is required",
+		},
+		"uniform min above max": {
+			latency:     LatencyConfig{LatencyDistribution: LatencyDistribution{Distribution: LatencyUniform, Min: "2s", Max: "1s"}},
+			errContains: "min must not be greater than max",
+		},
+		"invalid duration": {
+			latency:     LatencyConfig{LatencyDistribution: LatencyDistribution{Distribution: LatencyNormal, Mean: "slow"}},
+			errContains: `invalid mean "slow"`,
+		},
+		"negative shape": {
+			latency:     LatencyConfig{LatencyDistribution: LatencyDistribution{Distribution: LatencyPareto, Scale: "1s", Shape: -1}},
+			errContains: "shape must not be negative",
+		},
+		"unknown distribution": {
+			latency:     LatencyConfig{LatencyDistribution: LatencyDistribution{Distribution: "poisson"}},
+			errContains: `unknown distribution "poisson"`,
+		},
+		"invalid tool": {
+			latency:     LatencyConfig{Tools: map[string]*LatencyDistribution{"pods_log": {Distribution: LatencyUniform}}},
+			errContains: `tool "pods_log": max is required`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.latency.Validate()
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
+
+func TestLatencySample(t *testing.T) {
+	tests := map[string]struct {
+		distribution LatencyDistribution
+		min, max     time.Duration
+	}{
+		"fixed": {
+			distribution: LatencyDistribution{Delay: "100ms"},
+			min:          100 * time.Millisecond,
+			max:          100 * time.Millisecond,
+		},
+		"uniform": {
+			distribution: LatencyDistribution{Distribution: LatencyUniform, Min: "10ms", Max: "20ms"},
+			min:          10 * time.Millisecond,
+			max:          20 * time.Millisecond,
+		},
+		"normal is not negative": {
+			distribution: LatencyDistribution{Distribution: LatencyNormal, Mean: "1ms", StdDev: "1s", Max: "2s"},
+			min:          0,
+			max:          2 * time.Second,
+		},
+		"pareto is capped": {
+			distribution: LatencyDistribution{Distribution: LatencyPareto, Scale: "10ms", Shape: 0.5, Max: "50ms"},
+			min:          10 * time.Millisecond,
+			max:          50 * time.Millisecond,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for range 1000 {
+				delay := tc.distribution.sample()
+				assert.GreaterOrEqual(t, delay, tc.min)
+				assert.LessOrEqual(t, delay, tc.max)
+			}
+		})
+	}
+}
+
+func TestLatencyForTool(t *testing.T) {
+	var none *LatencyConfig
+	assert.Nil(t, none.forTool("pods_list"))
+	assert.Nil(t, none.forServer())
+
+	toolsOnly := &LatencyConfig{Tools: map[string]*LatencyDistribution{"pods_log": {Delay: "1s"}}}
+	assert.Equal(t, "1s", toolsOnly.forTool("pods_log").Delay)
+	assert.Nil(t, toolsOnly.forTool("pods_list"))
+	assert.Nil(t, toolsOnly.forServer())
+
+	server := &LatencyConfig{LatencyDistribution: LatencyDistribution{Delay: "100ms"}}
+	assert.Equal(t, "100ms", server.forTool("pods_list").Delay)
+	assert.Equal(t, "100ms", server.forServer().Delay)
+}
+
+func TestInjectLatencyStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := injectLatency(ctx, &LatencyDistribution{Delay: "1m"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestProxyLatency(t *testing.T) {
+	ctx := context.Background()
+
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "echo", Version: "0.0.1"}, nil)
+	mcp.AddTool(mcpServer, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, in echoInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: in.Text}}}, nil, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := mcpServer.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ss.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	latency := &LatencyConfig{Tools: map[string]*LatencyDistribution{"echo": {Delay: "100ms"}}}
+	srv, err := newServer(ctx, "echo", &ServerConfig{Command: "echo", EnableAllTools: true, Latency: latency}, &upstream{cs: cs}, nil)
+	require.NoError(t, err)
+
+	manager := &serverManager{servers: map[string]Server{"echo": srv}}
+	require.NoError(t, manager.Start(ctx))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	cfg, err := srv.GetConfig()
+	require.NoError(t, err)
+	agent, err := mcp.NewClient(&mcp.Implementation{Name: "agent", Version: "0.0.1"}, nil).Connect(ctx, &mcp.StreamableClientTransport{Endpoint: cfg.URL}, nil)
+	require.NoError(t, err)
+	defer agent.Close()
+
+	start := time.Now()
+	res, err := agent.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: echoInput{Text: "hello"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello"}, texts(res))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestParseLatencyConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+mcpServers:
+  kubernetes:
+    command: kubernetes-mcp-server
+    latency:
+      distribution: normal
+      mean: 200ms
+      stddev: 50ms
+      tools:
+        pods_log:
+          distribution: pareto
+          scale: 100ms
+`))
+	require.NoError(t, err)
+
+	latency := cfg.MCPServers["kubernetes"].Latency
+	require.NotNil(t, latency)
+	assert.Equal(t, LatencyDistribution{Distribution: LatencyNormal, Mean: "200ms", StdDev: "50ms"}, latency.LatencyDistribution)
+	assert.Equal(t, &LatencyDistribution{Distribution: LatencyPareto, Scale: "100ms"}, latency.Tools["pods_log"])
+}
@@ -0,0 +1,364 @@
+package mcpproxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	ResultLimitModeTruncate = "truncate"
+	ResultLimitModePaginate = "paginate"
+)
+
+// PageArgument is the argument the proxy adds to the tools of servers that
+// paginate results, to ask for the pages after the first
+const PageArgument = "mcpcheckerPage"
+
+// ResultLimitConfig bounds the size of the tool results of a server that the
+// agent sees, to evaluate agents with a bounded context and to cap the cost of
+// servers that return unpaginated responses. The size of a result is the
+// length of its text content plus the JSON of its other content.
+type ResultLimitConfig struct {
+	// MaxBytes is the largest result that is passed through unchanged. Zero
+	// leaves the results of the server unlimited, except for Tools
+	MaxBytes int `json:"maxBytes,omitempty"`
+
+	// Mode is "truncate" (default), which cuts results at MaxBytes and drops
+	// the rest, or "paginate", which returns the first MaxBytes of the text and
+	// lets the agent ask for the next pages with the mcpcheckerPage argument
+	Mode string `json:"mode,omitempty"`
+
+	// Tools overrides MaxBytes and Mode for some tools, by name. Unset
+	// fields are taken from the server
+	Tools map[string]*ToolResultLimit `json:"tools,omitempty"`
+}
+
+// ToolResultLimit overrides the result limit of a server for one tool
+type ToolResultLimit struct {
+	MaxBytes int    `json:"maxBytes,omitempty"`
+	Mode     string `json:"mode,omitempty"`
+}
+
+// Validate checks that the result limit is consistent.
+func (c *ResultLimitConfig) Validate() error {
+	if err := validateResultLimit(c.MaxBytes, c.Mode); err != nil {
+		return err
+	}
+	for name, tool := range c.Tools {
+		if tool == nil {
+			return fmt.Errorf("tool %q: limit must not be empty", name)
+		}
+		if err := validateResultLimit(tool.MaxBytes, tool.Mode); err != nil {
+			return fmt.Errorf("tool %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func validateResultLimit(maxBytes int, mode string) error {
+	if maxBytes < 0 {
+		return fmt.Errorf("maxBytes must not be negative")
+	}
+	switch mode {
+	case "", ResultLimitModeTruncate, ResultLimitModePaginate:
+		return nil
+	default:
+		return fmt.Errorf("unknown mode %q, must be %q or %q", mode, ResultLimitModeTruncate, ResultLimitModePaginate)
+	}
+}
+
+// forTool returns the limit and mode of a tool, with a limit of 0 if its
+// results are not limited
+func (c *ResultLimitConfig) forTool(name string) (int, string) {
+	if c == nil {
+		return 0, ""
+	}
+
+	maxBytes, mode := c.MaxBytes, c.Mode
+	if tool, ok := c.Tools[name]; ok {
+		if tool.MaxBytes != 0 {
+			maxBytes = tool.MaxBytes
+		}
+		if tool.Mode != "" {
+			mode = tool.Mode
+		}
+	}
+	if mode == "" {
+		mode = ResultLimitModeTruncate
+	}
+	return maxBytes, mode
+}
+
+// resultLimiter applies the result limit of a server to the tools of one
+// proxy. It keeps the pages of paginated results, so that the agent reading
+// the next pages does not call the upstream server again.
+type resultLimiter struct {
+	cfg *ResultLimitConfig
+
+	mu    sync.Mutex
+	pages map[string]*mcp.CallToolResult
+}
+
+func newResultLimiter(cfg *ResultLimitConfig) *resultLimiter {
+	return &resultLimiter{
+		cfg:   cfg,
+		pages: make(map[string]*mcp.CallToolResult),
+	}
+}
+
+// tool returns the tool as the proxy lists it. Tools with a limit have no
+// output schema, since limited results have no structured content, and tools
+// that paginate take the page argument.
+func (l *resultLimiter) tool(t *mcp.Tool) *mcp.Tool {
+	maxBytes, mode := l.cfg.forTool(t.Name)
+	if maxBytes == 0 {
+		return t
+	}
+
+	limited := *t
+	limited.OutputSchema = nil
+	if mode != ResultLimitModePaginate {
+		return &limited
+	}
+
+	schema := map[string]any{}
+	if data, err := json.Marshal(t.InputSchema); err == nil {
+		_ = json.Unmarshal(data, &schema)
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	properties = maps.Clone(properties)
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	properties[PageArgument] = map[string]any{
+		"type":        "integer",
+		"minimum":     1,
+		"description": "Page of a result that was too large to return at once. Call the tool again with the same arguments and the next page number to read on.",
+	}
+	schema["type"] = "object"
+	schema["properties"] = properties
+	limited.InputSchema = schema
+
+	return &limited
+}
+
+// callTool calls the tool with call and limits its result. The page argument
+// is removed from the arguments before they are passed to call. exposed is
+// the name the agent calls the tool by, which the page markers refer to.
+func (l *resultLimiter) callTool(ctx context.Context, req *mcp.CallToolRequest, exposed string, call func(ctx context.Context, args json.RawMessage) (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+	name := req.Params.Name
+	maxBytes, mode := l.cfg.forTool(name)
+	if maxBytes == 0 {
+		return call(ctx, req.Params.Arguments)
+	}
+	if mode == ResultLimitModeTruncate {
+		res, err := call(ctx, req.Params.Arguments)
+		if err != nil || res == nil {
+			return res, err
+		}
+		return truncateResult(res, maxBytes), nil
+	}
+
+	args, page, err := splitPageArgument(req.Params.Arguments)
+	if err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	key := name + "\x00" + string(args)
+	l.mu.Lock()
+	res, ok := l.pages[key]
+	l.mu.Unlock()
+
+	// A first page is read from the server again, since the result may
+	// have changed since the last call
+	if !ok || page == 1 {
+		res, err = call(ctx, args)
+		if err != nil || res == nil {
+			return res, err
+		}
+		if resultSize(res) <= maxBytes {
+			return res, nil
+		}
+
+		l.mu.Lock()
+		l.pages[key] = res
+		l.mu.Unlock()
+	}
+
+	return paginateResult(res, exposed, maxBytes, page), nil
+}
+
+// splitPageArgument removes the page argument from the arguments of a call and
+// returns the page, which defaults to 1. The arguments are normalized, so that
+// calls with the same arguments in another order read the same pages.
+func splitPageArgument(raw json.RawMessage) (json.RawMessage, int, error) {
+	if len(raw) == 0 {
+		return raw, 1, nil
+	}
+
+	var args map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return raw, 1, nil
+	}
+
+	page := 1
+	if value, ok := args[PageArgument]; ok {
+		if err := json.Unmarshal(value, &page); err != nil || page < 1 {
+			return nil, 0, fmt.Errorf("%s must be a positive integer, got %s", PageArgument, value)
+		}
+		delete(args, PageArgument)
+	}
+
+	normalized, err := json.Marshal(args)
+	if err != nil {
+		return nil, 0, err
+	}
+	return normalized, page, nil
+}
+
+// contentSize returns the size of a content block: the length of its text, or
+// of its JSON for content that is not text
+func contentSize(c mcp.Content) int {
+	if text, ok := c.(*mcp.TextContent); ok {
+		return len(text.Text)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// resultContent returns the content of a result. A result with only
+// structured content has its JSON as text content.
+func resultContent(res *mcp.CallToolResult) []mcp.Content {
+	if len(res.Content) > 0 || res.StructuredContent == nil {
+		return res.Content
+	}
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		return nil
+	}
+	return []mcp.Content{&mcp.TextContent{Text: string(data)}}
+}
+
+// resultSize returns the size of a result, as the sum of its content blocks
+func resultSize(res *mcp.CallToolResult) int {
+	size := 0
+	for _, c := range resultContent(res) {
+		size += contentSize(c)
+	}
+	return size
+}
+
+// truncateResult keeps the content of a result up to maxBytes and appends a
+// marker. Text content is cut at a character boundary; other content is kept
+// only if it fits whole.
+func truncateResult(res *mcp.CallToolResult, maxBytes int) *mcp.CallToolResult {
+	size := resultSize(res)
+	if size <= maxBytes {
+		return res
+	}
+
+	truncated := &mcp.CallToolResult{Meta: res.Meta, IsError: res.IsError}
+	budget := maxBytes
+	for _, c := range resultContent(res) {
+		n := contentSize(c)
+		if n <= budget {
+			truncated.Content = append(truncated.Content, c)
+			budget -= n
+			continue
+		}
+		if text, ok := c.(*mcp.TextContent); ok && budget > 0 {
+			truncated.Content = append(truncated.Content, &mcp.TextContent{Text: cutText(text.Text, budget)})
+		}
+		break
+	}
+
+	truncated.Content = append(truncated.Content, &mcp.TextContent{
+		Text: fmt.Sprintf("[mcpchecker: result truncated to %d of %d bytes]", maxBytes, size),
+	})
+	return truncated
+}
+
+// paginateResult returns a page of maxBytes of the text of a result, with a
+// marker that tells the agent how to read the next page. Content that is not
+// text is returned with the first page.
+func paginateResult(res *mcp.CallToolResult, tool string, maxBytes, page int) *mcp.CallToolResult {
+	var texts []string
+	var other []mcp.Content
+	for _, c := range resultContent(res) {
+		if text, ok := c.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		} else {
+			other = append(other, c)
+		}
+	}
+
+	pages := splitText(strings.Join(texts, "\n"), maxBytes)
+	if page > len(pages) {
+		return errorResult(fmt.Sprintf("page %d does not exist, the result has %d pages", page, len(pages)))
+	}
+
+	paged := &mcp.CallToolResult{Meta: res.Meta, IsError: res.IsError}
+	if page == 1 {
+		paged.Content = append(paged.Content, other...)
+	}
+	paged.Content = append(paged.Content, &mcp.TextContent{Text: pages[page-1]})
+
+	marker := fmt.Sprintf("[mcpchecker: page %d of %d of a %d byte result]", page, len(pages), resultSize(res))
+	if page < len(pages) {
+		marker = fmt.Sprintf("[mcpchecker: page %d of %d of a %d byte result. Call %s again with the same arguments and \"%s\": %d for the next page]",
+			page, len(pages), resultSize(res), tool, PageArgument, page+1)
+	}
+	paged.Content = append(paged.Content, &mcp.TextContent{Text: marker})
+
+	return paged
+}
+
+// splitText splits text into pages of at most maxBytes, at character
+// boundaries
+func splitText(text string, maxBytes int) []string {
+	var pages []string
+	for len(text) > maxBytes {
+		page := cutText(text, maxBytes)
+		if page == "" {
+			// A character longer than a page is kept whole
+			_, n := utf8.DecodeRuneInString(text)
+			page = text[:n]
+		}
+		pages = append(pages, page)
+		text = text[len(page):]
+	}
+	if text == "" && len(pages) > 0 {
+		return pages
+	}
+	return append(pages, text)
+}
+
+// cutText returns the longest prefix of text of at most maxBytes that ends at
+// a character boundary
+func cutText(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
+func errorResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+		IsError: true,
+	}
+}
@@ -0,0 +1,231 @@
+package mcpproxy
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultLimitConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		config      ResultLimitConfig
+		errContains string
+	}{
+		"truncate": {
+			config: ResultLimitConfig{MaxBytes: 1000},
+		},
+		"paginate some tools": {
+			config: ResultLimitConfig{Tools: map[string]*ToolResultLimit{"pods_list": {MaxBytes: 1000, Mode: ResultLimitModePaginate}}},
+		},
+		"negative maxBytes": {
+			config:      ResultLimitConfig{MaxBytes: -1},
+			errContains: "maxBytes must not be negative",
+		},
+		"unknown mode": {
+			config:      ResultLimitConfig{MaxBytes: 1000, Mode: "summarize"},
+			errContains: `unknown mode "summarize"`,
+		},
+		"invalid tool": {
+			config:      ResultLimitConfig{Tools: map[string]*ToolResultLimit{"pods_list": {Mode: "summarize"}}},
+			errContains: `tool "pods_list": unknown mode`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errContains)
+		})
+	}
+}
+
+func TestResultLimitForTool(t *testing.T) {
+	cfg := &ResultLimitConfig{
+		MaxBytes: 1000,
+		Tools: map[string]*ToolResultLimit{
+			"pods_list": {Mode: ResultLimitModePaginate},
+			"pods_log":  {MaxBytes: 200},
+		},
+	}
+
+	maxBytes, mode := cfg.forTool("pods_get")
+	assert.Equal(t, 1000, maxBytes)
+	assert.Equal(t, ResultLimitModeTruncate, mode)
+
+	maxBytes, mode = cfg.forTool("pods_list")
+	assert.Equal(t, 1000, maxBytes)
+	assert.Equal(t, ResultLimitModePaginate, mode)
+
+	maxBytes, _ = cfg.forTool("pods_log")
+	assert.Equal(t, 200, maxBytes)
+
+	maxBytes, _ = (*ResultLimitConfig)(nil).forTool("pods_get")
+	assert.Zero(t, maxBytes)
+}
+
+func TestTruncateResult(t *testing.T) {
+	image := &mcp.ImageContent{MIMEType: "image/png", Data: []byte("png")}
+	tests := map[string]struct {
+		result   *mcp.CallToolResult
+		maxBytes int
+		expected []string
+	}{
+		"under the limit": {
+			result:   &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "0123456789"}}},
+			maxBytes: 10,
+			expected: []string{"0123456789"},
+		},
+		"cut text": {
+			result:   &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "0123456789"}}},
+			maxBytes: 4,
+			expected: []string{"0123", "[mcpchecker: result truncated to 4 of 10 bytes]"},
+		},
+		"cut at a character boundary": {
+			result:   &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "aéé"}}},
+			maxBytes: 4,
+			expected: []string{"aé", "[mcpchecker: result truncated to 4 of 5 bytes]"},
+		},
+		"drop content that does not fit": {
+			result:   &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "0123"}, image, &mcp.TextContent{Text: "4567"}}},
+			maxBytes: 10,
+			expected: []string{"0123", "[mcpchecker: result truncated to 10 of 61 bytes]"},
+		},
+		"structured content only": {
+			result:   &mcp.CallToolResult{StructuredContent: map[string]any{"pods": []string{"nginx", "redis"}}},
+			maxBytes: 10,
+			expected: []string{`{"pods":["`, "[mcpchecker: result truncated to 10 of 26 bytes]"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, texts(truncateResult(tc.result, tc.maxBytes)))
+		})
+	}
+}
+
+func TestPaginateResult(t *testing.T) {
+	res := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "0123456789"}}}
+
+	assert.Equal(t, []string{"0123", `[mcpchecker: page 1 of 3 of a 10 byte result. Call pods_list again with the same arguments and "mcpcheckerPage": 2 for the next page]`},
+		texts(paginateResult(res, "pods_list", 4, 1)))
+	assert.Equal(t, []string{"89", "[mcpchecker: page 3 of 3 of a 10 byte result]"},
+		texts(paginateResult(res, "pods_list", 4, 3)))
+
+	missing := paginateResult(res, "pods_list", 4, 4)
+	assert.True(t, missing.IsError)
+	assert.Equal(t, []string{"page 4 does not exist, the result has 3 pages"}, texts(missing))
+}
+
+func TestSplitText(t *testing.T) {
+	assert.Equal(t, []string{"0123", "4567", "89"}, splitText("0123456789", 4))
+	assert.Equal(t, []string{"aé", "é"}, splitText("aéé", 3))
+	assert.Equal(t, []string{"é", "é"}, splitText("éé", 1))
+}
+
+func TestSplitPageArgument(t *testing.T) {
+	args, page, err := splitPageArgument(json.RawMessage(`{"namespace":"default","mcpcheckerPage":3,"all":true}`))
+	require.NoError(t, err)
+	assert.Equal(t, 3, page)
+	assert.JSONEq(t, `{"all":true,"namespace":"default"}`, string(args))
+
+	normalized, page, err := splitPageArgument(json.RawMessage(`{"all":true,"namespace":"default"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, page)
+	assert.Equal(t, string(args), string(normalized))
+
+	_, _, err = splitPageArgument(json.RawMessage(`{"mcpcheckerPage":0}`))
+	assert.Error(t, err)
+}
+
+func TestProxyResultLimit(t *testing.T) {
+	ctx := context.Background()
+
+	upstreamCalls := 0
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "pods", Version: "0.0.1"}, nil)
+	mcpServer.AddTool(&mcp.Tool{Name: "pods_list", InputSchema: map[string]any{"type": "object"}}, func(_ context.Context, _ *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		upstreamCalls++
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("x", 250)}}}, nil
+	})
+	mcpServer.AddTool(&mcp.Tool{Name: "pods_log", InputSchema: map[string]any{"type": "object"}}, func(_ context.Context, _ *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("y", 250)}}}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := mcpServer.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ss.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	limit := &ResultLimitConfig{
+		MaxBytes: 100,
+		Tools:    map[string]*ToolResultLimit{"pods_list": {Mode: ResultLimitModePaginate}},
+	}
+	srv, err := newServer(ctx, "pods", &ServerConfig{Command: "pods", EnableAllTools: true, ResultLimit: limit}, &upstream{cs: cs}, nil)
+	require.NoError(t, err)
+
+	manager := &serverManager{servers: map[string]Server{"pods": srv}}
+	require.NoError(t, manager.Start(ctx))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	cfg, err := srv.GetConfig()
+	require.NoError(t, err)
+	agent, err := mcp.NewClient(&mcp.Implementation{Name: "agent", Version: "0.0.1"}, nil).Connect(ctx, &mcp.StreamableClientTransport{Endpoint: cfg.URL}, nil)
+	require.NoError(t, err)
+	defer agent.Close()
+
+	tools, err := agent.ListTools(ctx, nil)
+	require.NoError(t, err)
+	for _, tool := range tools.Tools {
+		properties := tool.InputSchema.(map[string]any)["properties"]
+		if tool.Name == "pods_list" {
+			assert.Contains(t, properties, PageArgument)
+		} else {
+			assert.Nil(t, properties)
+		}
+	}
+
+	res, err := agent.CallTool(ctx, &mcp.CallToolParams{Name: "pods_log"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{strings.Repeat("y", 100), "[mcpchecker: result truncated to 100 of 250 bytes]"}, texts(res))
+
+	res, err = agent.CallTool(ctx, &mcp.CallToolParams{Name: "pods_list"})
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", 100), texts(res)[0])
+	assert.Contains(t, texts(res)[1], "page 1 of 3")
+
+	res, err = agent.CallTool(ctx, &mcp.CallToolParams{Name: "pods_list", Arguments: map[string]any{PageArgument: 3}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{strings.Repeat("x", 50), "[mcpchecker: page 3 of 3 of a 250 byte result]"}, texts(res))
+	assert.Equal(t, 1, upstreamCalls, "later pages are not read from the server again")
+
+	// The history records what the agent saw
+	history := srv.GetCallHistory()
+	require.Len(t, history.ToolCalls, 3)
+	assert.Contains(t, texts(history.ToolCalls[0].Result)[1], "result truncated")
+	assert.Contains(t, texts(history.ToolCalls[2].Result)[1], "page 3 of 3")
+}
+
+// texts returns the text of the text content of a result
+func texts(res *mcp.CallToolResult) []string {
+	var texts []string
+	for _, c := range res.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return texts
+}
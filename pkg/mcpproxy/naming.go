@@ -0,0 +1,109 @@
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Strategies for naming the tools the proxy exposes to the agent
+const (
+	// ToolNamingCollisions prefixes the tools whose names another server
+	// exposes too. It is the default.
+	ToolNamingCollisions = "collisions"
+	// ToolNamingPrefix prefixes all tools of the server
+	ToolNamingPrefix = "prefix"
+	// ToolNamingNone exposes the tools under their own names, even if another
+	// server exposes a tool of the same name
+	ToolNamingNone = "none"
+)
+
+// validateToolNaming checks the tool naming strategy of a server
+func validateToolNaming(naming string) error {
+	switch naming {
+	case "", ToolNamingCollisions, ToolNamingPrefix, ToolNamingNone:
+		return nil
+	}
+	return fmt.Errorf("toolNaming must be %q, %q, or %q, got %q", ToolNamingCollisions, ToolNamingPrefix, ToolNamingNone, naming)
+}
+
+// GetToolPrefix returns the prefix of the renamed tools of the server with the
+// given name, which defaults to the name followed by an underscore
+func (s *ServerConfig) GetToolPrefix(name string) string {
+	if s.ToolPrefix != "" {
+		return s.ToolPrefix
+	}
+	return name + "_"
+}
+
+// exposedToolNames returns the names the tools of each server are exposed
+// under, by server and tool name on the server, following the toolNaming of
+// each server. Only renamed tools are included.
+func exposedToolNames(configs map[string]*ServerConfig, tools map[string][]string) map[string]map[string]string {
+	exposers := make(map[string]int)
+	for name, toolNames := range tools {
+		if configs[name].ToolNaming == ToolNamingPrefix {
+			continue
+		}
+		for _, tool := range toolNames {
+			exposers[tool]++
+		}
+	}
+
+	renamed := make(map[string]map[string]string, len(tools))
+	for name, toolNames := range tools {
+		cfg := configs[name]
+		for _, tool := range toolNames {
+			switch cfg.ToolNaming {
+			case ToolNamingNone:
+				continue
+			case ToolNamingPrefix:
+			default:
+				if exposers[tool] < 2 {
+					continue
+				}
+			}
+
+			if renamed[name] == nil {
+				renamed[name] = make(map[string]string)
+			}
+			renamed[name][tool] = cfg.GetToolPrefix(name) + tool
+		}
+	}
+
+	return renamed
+}
+
+// renameTool returns a copy of a tool with another name
+func renameTool(t *mcp.Tool, name string) *mcp.Tool {
+	renamed := *t
+	renamed.Name = name
+	return &renamed
+}
+
+// renameToolRequest returns a copy of a tool call with another tool name
+func renameToolRequest(req *mcp.CallToolRequest, name string) *mcp.CallToolRequest {
+	params := *req.Params
+	params.Name = name
+	renamed := *req
+	renamed.Params = &params
+	return &renamed
+}
+
+// listToolNames returns the names of the tools of an upstream session, or none
+// if the server does not have tools
+func listToolNames(ctx context.Context, cs *mcp.ClientSession) []string {
+	if cs.InitializeResult().Capabilities.Tools == nil {
+		return nil
+	}
+
+	var names []string
+	for t, err := range cs.Tools(ctx, &mcp.ListToolsParams{}) {
+		if err != nil {
+			continue
+		}
+		names = append(names, t.Name)
+	}
+	return names
+}
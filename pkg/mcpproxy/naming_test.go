@@ -0,0 +1,168 @@
+package mcpproxy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExposedToolNames(t *testing.T) {
+	tests := map[string]struct {
+		configs map[string]*ServerConfig
+		want    map[string]map[string]string
+	}{
+		"collisions by default": {
+			configs: map[string]*ServerConfig{
+				"k8s":    {},
+				"github": {},
+			},
+			want: map[string]map[string]string{
+				"k8s":    {"search": "k8s_search"},
+				"github": {"search": "github_search"},
+			},
+		},
+		"custom prefix": {
+			configs: map[string]*ServerConfig{
+				"k8s":    {ToolPrefix: "kube."},
+				"github": {ToolNaming: ToolNamingCollisions},
+			},
+			want: map[string]map[string]string{
+				"k8s":    {"search": "kube.search"},
+				"github": {"search": "github_search"},
+			},
+		},
+		"prefix all tools": {
+			configs: map[string]*ServerConfig{
+				"k8s":    {ToolNaming: ToolNamingPrefix},
+				"github": {},
+			},
+			want: map[string]map[string]string{
+				"k8s": {"search": "k8s_search", "pods_list": "k8s_pods_list"},
+			},
+		},
+		"none keeps names": {
+			configs: map[string]*ServerConfig{
+				"k8s":    {ToolNaming: ToolNamingNone},
+				"github": {},
+			},
+			want: map[string]map[string]string{
+				"github": {"search": "github_search"},
+			},
+		},
+	}
+
+	tools := map[string][]string{
+		"k8s":    {"search", "pods_list"},
+		"github": {"search", "issues_list"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, exposedToolNames(tc.configs, tools))
+		})
+	}
+}
+
+func TestValidateToolNaming(t *testing.T) {
+	for _, naming := range []string{"", ToolNamingCollisions, ToolNamingPrefix, ToolNamingNone} {
+		assert.NoError(t, validateToolNaming(naming))
+	}
+	assert.EqualError(t, validateToolNaming("suffix"), `toolNaming must be "collisions", "prefix", or "none", got "suffix"`)
+}
+
+func TestRenamedTool(t *testing.T) {
+	ctx := context.Background()
+
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "echo", Version: "0.0.1"}, nil)
+	mcp.AddTool(mcpServer, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, in echoInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: in.Text}}}, nil, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := mcpServer.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ss.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	srv, err := newServer(ctx, "echo", &ServerConfig{Command: "echo", AlwaysAllow: []string{"echo"}}, &upstream{cs: cs}, map[string]string{"echo": "echo_echo"})
+	require.NoError(t, err)
+
+	manager := &serverManager{servers: map[string]Server{"echo": srv}}
+	require.NoError(t, manager.Start(ctx))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	allowed := srv.GetAllowedTools()
+	require.Len(t, allowed, 1)
+	assert.Equal(t, "echo_echo", allowed[0].Name)
+
+	cfg, err := srv.GetConfig()
+	require.NoError(t, err)
+	agent := mcp.NewClient(&mcp.Implementation{Name: "agent", Version: "0.0.1"}, nil)
+	session, err := agent.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: cfg.URL}, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "echo_echo", Arguments: echoInput{Text: "hello"}})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", res.Content[0].(*mcp.TextContent).Text)
+
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: echoInput{Text: "hello"}})
+	assert.Error(t, err)
+
+	history := srv.GetCallHistory()
+	require.Len(t, history.ToolCalls, 1)
+	assert.Equal(t, "echo", history.ToolCalls[0].ToolName)
+	assert.Equal(t, "echo_echo", history.ToolCalls[0].ExposedName)
+}
+
+func TestRenamedToolPagination(t *testing.T) {
+	ctx := context.Background()
+
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "pods", Version: "0.0.1"}, nil)
+	mcpServer.AddTool(&mcp.Tool{Name: "pods_list", InputSchema: map[string]any{"type": "object"}}, func(_ context.Context, _ *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("x", 250)}}}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := mcpServer.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ss.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	cfg := &ServerConfig{
+		Command:        "pods",
+		EnableAllTools: true,
+		ResultLimit:    &ResultLimitConfig{MaxBytes: 100, Mode: ResultLimitModePaginate},
+	}
+	srv, err := newServer(ctx, "k8s", cfg, &upstream{cs: cs}, map[string]string{"pods_list": "k8s_pods_list"})
+	require.NoError(t, err)
+
+	manager := &serverManager{servers: map[string]Server{"k8s": srv}}
+	require.NoError(t, manager.Start(ctx))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	proxyCfg, err := srv.GetConfig()
+	require.NoError(t, err)
+	agent, err := mcp.NewClient(&mcp.Implementation{Name: "agent", Version: "0.0.1"}, nil).Connect(ctx, &mcp.StreamableClientTransport{Endpoint: proxyCfg.URL}, nil)
+	require.NoError(t, err)
+	defer agent.Close()
+
+	res, err := agent.CallTool(ctx, &mcp.CallToolParams{Name: "k8s_pods_list"})
+	require.NoError(t, err)
+	assert.Contains(t, texts(res)[1], "Call k8s_pods_list again")
+
+	// The page the marker points to can be read by the exposed name
+	res, err = agent.CallTool(ctx, &mcp.CallToolParams{Name: "k8s_pods_list", Arguments: map[string]any{PageArgument: 2}})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	assert.Contains(t, texts(res)[1], "page 2 of 3")
+}
@@ -0,0 +1,136 @@
+package mcpproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ServerPool keeps client sessions to stdio MCP servers alive across multiple
+// ServerManagers, so that expensive server processes are spawned once per eval
+// run instead of once per task.
+//
+// Only the upstream session is shared: every ServerManager still creates its own
+// proxy server and recorder, so call history is always scoped to a single task.
+// Sessions are reference counted, so that tasks running concurrently never
+// lose a session another task replaced.
+type ServerPool struct {
+	mu       sync.Mutex
+	sessions map[string]*pooledSession
+}
+
+type pooledSession struct {
+	cfg     *ServerConfig
+	session *mcp.ClientSession
+	// refs is the number of servers using the session
+	refs int
+}
+
+type serverPoolKey struct{}
+
+// NewServerPool creates an empty ServerPool. Callers must call Close once the
+// pool is no longer needed to terminate the pooled server processes.
+func NewServerPool() *ServerPool {
+	return &ServerPool{
+		sessions: make(map[string]*pooledSession),
+	}
+}
+
+// ServerPoolToContext returns a context that carries the given pool. Proxy servers
+// created with this context will reuse stdio sessions from the pool.
+func ServerPoolToContext(ctx context.Context, pool *ServerPool) context.Context {
+	return context.WithValue(ctx, serverPoolKey{}, pool)
+}
+
+// ServerPoolFromContext returns the pool stored in ctx, if any.
+func ServerPoolFromContext(ctx context.Context) (*ServerPool, bool) {
+	pool, ok := ctx.Value(serverPoolKey{}).(*ServerPool)
+	return pool, ok && pool != nil
+}
+
+// acquire returns the pooled session for the named server, calling connect to
+// create one if no usable session exists yet, and a func that releases it once
+// the caller is done with it. Sessions are only reused when the server config
+// matches the one they were created with and the server still responds to a
+// ping. If the pooled session has a different config and is still in use, the
+// caller gets a session of its own that release closes.
+func (p *ServerPool) acquire(ctx context.Context, name string, cfg *ServerConfig, connect func() (*mcp.ClientSession, error)) (*mcp.ClientSession, func() error, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, ok := p.sessions[name]; ok {
+		sameConfig := reflect.DeepEqual(existing.cfg, cfg)
+		if sameConfig && existing.session.Ping(ctx, nil) == nil {
+			existing.refs++
+			return existing.session, p.releaseFunc(name, existing.session), nil
+		}
+
+		if !sameConfig && existing.refs > 0 {
+			cs, err := connect()
+			if err != nil {
+				return nil, nil, err
+			}
+			return cs, cs.Close, nil
+		}
+
+		// The config changed or the server went away, replace the session.
+		// A session still in use is closed when it is released.
+		if existing.refs == 0 {
+			_ = existing.session.Close()
+		}
+		delete(p.sessions, name)
+	}
+
+	cs, err := connect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.sessions[name] = &pooledSession{
+		cfg:     cfg,
+		session: cs,
+		refs:    1,
+	}
+
+	return cs, p.releaseFunc(name, cs), nil
+}
+
+// releaseFunc returns a func that releases a session returned by acquire. A
+// session that is no longer pooled is closed once released.
+func (p *ServerPool) releaseFunc(name string, cs *mcp.ClientSession) func() error {
+	var once sync.Once
+	return func() error {
+		var err error
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			if existing, ok := p.sessions[name]; ok && existing.session == cs {
+				existing.refs--
+				return
+			}
+			err = cs.Close()
+		})
+		return err
+	}
+}
+
+// Close closes all pooled sessions, terminating the underlying server processes.
+func (p *ServerPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for name, s := range p.sessions {
+		if err := s.session.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close pooled server %s: %w", name, err))
+		}
+	}
+	p.sessions = make(map[string]*pooledSession)
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package mcpproxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectInMemory returns a connect func that starts a fresh in-memory MCP server
+// for every call and counts how many times it was invoked.
+func connectInMemory(t *testing.T, ctx context.Context, calls *int) func() (*mcp.ClientSession, error) {
+	return func() (*mcp.ClientSession, error) {
+		*calls++
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		srv := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "0.0.1"}, nil)
+		ss, err := srv.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = ss.Close() })
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+		return client.Connect(ctx, clientTransport, nil)
+	}
+}
+
+func TestServerPoolReusesSessions(t *testing.T) {
+	ctx := context.Background()
+	pool := NewServerPool()
+	defer pool.Close()
+
+	cfg := &ServerConfig{Command: "my-server", Args: []string{"--stdio"}}
+
+	var calls int
+	first, _, err := pool.acquire(ctx, "server", cfg, connectInMemory(t, ctx, &calls))
+	require.NoError(t, err)
+
+	second, _, err := pool.acquire(ctx, "server", cfg, connectInMemory(t, ctx, &calls))
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, calls)
+}
+
+func TestServerPoolReplacesSessionOnConfigChange(t *testing.T) {
+	ctx := context.Background()
+	pool := NewServerPool()
+	defer pool.Close()
+
+	var calls int
+	first, release, err := pool.acquire(ctx, "server", &ServerConfig{Command: "my-server"}, connectInMemory(t, ctx, &calls))
+	require.NoError(t, err)
+	require.NoError(t, release())
+
+	second, _, err := pool.acquire(ctx, "server", &ServerConfig{Command: "my-server", Args: []string{"--debug"}}, connectInMemory(t, ctx, &calls))
+	require.NoError(t, err)
+
+	assert.NotSame(t, first, second)
+	assert.Equal(t, 2, calls)
+	assert.Error(t, first.Ping(ctx, nil))
+}
+
+func TestServerPoolKeepsSessionInUse(t *testing.T) {
+	ctx := context.Background()
+	pool := NewServerPool()
+	defer pool.Close()
+
+	cfg := &ServerConfig{Command: "my-server"}
+
+	var calls int
+	first, releaseFirst, err := pool.acquire(ctx, "server", cfg, connectInMemory(t, ctx, &calls))
+	require.NoError(t, err)
+
+	// A task with a different config gets its own session while the pooled
+	// one is in use
+	other, releaseOther, err := pool.acquire(ctx, "server", &ServerConfig{Command: "my-server", Args: []string{"--debug"}}, connectInMemory(t, ctx, &calls))
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+	require.NoError(t, first.Ping(ctx, nil))
+
+	require.NoError(t, releaseOther())
+	assert.Error(t, other.Ping(ctx, nil))
+
+	// The pooled session is still reused
+	second, releaseSecond, err := pool.acquire(ctx, "server", cfg, connectInMemory(t, ctx, &calls))
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, 2, calls)
+
+	// Releasing a session that is still pooled keeps it open
+	require.NoError(t, releaseFirst())
+	require.NoError(t, releaseSecond())
+	require.NoError(t, first.Ping(ctx, nil))
+}
+
+func TestServerPoolReplacesClosedSession(t *testing.T) {
+	ctx := context.Background()
+	pool := NewServerPool()
+	defer pool.Close()
+
+	cfg := &ServerConfig{Command: "my-server"}
+
+	var calls int
+	first, _, err := pool.acquire(ctx, "server", cfg, connectInMemory(t, ctx, &calls))
+	require.NoError(t, err)
+	require.NoError(t, first.Close())
+
+	second, _, err := pool.acquire(ctx, "server", cfg, connectInMemory(t, ctx, &calls))
+	require.NoError(t, err)
+
+	assert.NotSame(t, first, second)
+	assert.Equal(t, 2, calls)
+}
+
+func TestServerPoolFromContext(t *testing.T) {
+	_, ok := ServerPoolFromContext(context.Background())
+	assert.False(t, ok)
+
+	pool := NewServerPool()
+	got, ok := ServerPoolFromContext(ServerPoolToContext(context.Background(), pool))
+	assert.True(t, ok)
+	assert.Same(t, pool, got)
+}
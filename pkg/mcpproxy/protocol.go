@@ -0,0 +1,93 @@
+package mcpproxy
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// How a server that negotiates a protocol version outside its
+// protocolVersions is handled
+const (
+	// ProtocolVersionMismatchFail fails to start the server. It is the default.
+	ProtocolVersionMismatchFail = "fail"
+	// ProtocolVersionMismatchWarn starts the server, and the run warns about
+	// the mismatch
+	ProtocolVersionMismatchWarn = "warn"
+)
+
+// Compatibility is the MCP protocol version a server negotiated with the
+// proxy, and whether it is one its config accepts
+type Compatibility struct {
+	Server          string   `json:"server"`
+	ServerInfo      string   `json:"serverInfo,omitempty"` // Name and version the server reported
+	ProtocolVersion string   `json:"protocolVersion"`
+	Accepted        []string `json:"accepted,omitempty"` // protocolVersions of the server config
+	Compatible      bool     `json:"compatible"`
+}
+
+// validateProtocolVersionMismatch checks the protocolVersionMismatch of a
+// server
+func validateProtocolVersionMismatch(mismatch string) error {
+	switch mismatch {
+	case "", ProtocolVersionMismatchFail, ProtocolVersionMismatchWarn:
+		return nil
+	}
+	return fmt.Errorf("protocolVersionMismatch must be %q or %q, got %q", ProtocolVersionMismatchFail, ProtocolVersionMismatchWarn, mismatch)
+}
+
+// compatibility returns the compatibility of a server with the protocol
+// version it negotiated in res
+func compatibility(name string, config *ServerConfig, res *mcp.InitializeResult) Compatibility {
+	c := Compatibility{
+		Server:     name,
+		Accepted:   config.ProtocolVersions,
+		Compatible: true,
+	}
+	if res == nil {
+		return c
+	}
+
+	c.ProtocolVersion = res.ProtocolVersion
+	if res.ServerInfo != nil {
+		c.ServerInfo = strings.TrimSpace(res.ServerInfo.Name + " " + res.ServerInfo.Version)
+	}
+	if len(config.ProtocolVersions) > 0 {
+		c.Compatible = slices.Contains(config.ProtocolVersions, res.ProtocolVersion)
+	}
+	return c
+}
+
+// Err returns an error describing the mismatch, if the server is not
+// compatible
+func (c Compatibility) Err() error {
+	if c.Compatible {
+		return nil
+	}
+	return fmt.Errorf("server %s negotiated MCP protocol version %s, which is not one of its protocolVersions: %s",
+		c.Server, c.ProtocolVersion, strings.Join(c.Accepted, ", "))
+}
+
+// compatibilityReporter is implemented by servers that know the protocol
+// version they negotiated
+type compatibilityReporter interface {
+	GetCompatibility() Compatibility
+}
+
+// CompatibilityOf returns the compatibility of each server that knows the
+// protocol version it negotiated, sorted by server name
+func CompatibilityOf(servers []Server) []Compatibility {
+	var report []Compatibility
+	for _, s := range servers {
+		if r, ok := s.(compatibilityReporter); ok {
+			report = append(report, r.GetCompatibility())
+		}
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Server < report[j].Server
+	})
+	return report
+}
@@ -0,0 +1,58 @@
+package mcpproxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompatibility(t *testing.T) {
+	res := &mcp.InitializeResult{
+		ProtocolVersion: "2025-03-26",
+		ServerInfo:      &mcp.Implementation{Name: "kubernetes-mcp-server", Version: "0.1.0"},
+	}
+
+	c := compatibility("k8s", &ServerConfig{}, res)
+	assert.Equal(t, Compatibility{
+		Server:          "k8s",
+		ServerInfo:      "kubernetes-mcp-server 0.1.0",
+		ProtocolVersion: "2025-03-26",
+		Compatible:      true,
+	}, c)
+	assert.NoError(t, c.Err())
+
+	c = compatibility("k8s", &ServerConfig{ProtocolVersions: []string{"2025-03-26", "2025-06-18"}}, res)
+	assert.True(t, c.Compatible)
+
+	c = compatibility("k8s", &ServerConfig{ProtocolVersions: []string{"2025-06-18"}}, res)
+	assert.False(t, c.Compatible)
+	assert.EqualError(t, c.Err(), "server k8s negotiated MCP protocol version 2025-03-26, which is not one of its protocolVersions: 2025-06-18")
+}
+
+func TestValidateProtocolVersionMismatch(t *testing.T) {
+	for _, mismatch := range []string{"", ProtocolVersionMismatchFail, ProtocolVersionMismatchWarn} {
+		assert.NoError(t, validateProtocolVersionMismatch(mismatch))
+	}
+	assert.EqualError(t, validateProtocolVersionMismatch("ignore"), `protocolVersionMismatch must be "fail" or "warn", got "ignore"`)
+}
+
+func TestCompatibilityOf(t *testing.T) {
+	manager := startEchoManager(t, context.Background())
+
+	view, err := manager.ForTask(context.Background(), "task")
+	require.NoError(t, err)
+	require.NoError(t, view.Start(context.Background()))
+	t.Cleanup(func() { _ = view.Close() })
+
+	for _, servers := range [][]Server{manager.GetMcpServers(), view.GetMcpServers()} {
+		report := CompatibilityOf(servers)
+		require.Len(t, report, 1)
+		assert.Equal(t, "echo", report[0].Server)
+		assert.Equal(t, "echo 0.0.1", report[0].ServerInfo)
+		assert.NotEmpty(t, report[0].ProtocolVersion)
+		assert.True(t, report[0].Compatible)
+	}
+}
@@ -2,19 +2,31 @@ package mcpproxy
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/auth"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
 )
 
 type Recorder interface {
 	RecordToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, start time.Time)
+	// RecordRenamedToolCall records a call of a tool the agent knows under
+	// another name than toolName, the name of the tool on the server
+	RecordRenamedToolCall(toolName string, req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, start time.Time)
 	RecordResourceRead(req *mcp.ReadResourceRequest, res *mcp.ReadResourceResult, err error, start time.Time)
+	RecordResourceTemplateRead(uriTemplate string, req *mcp.ReadResourceRequest, res *mcp.ReadResourceResult, err error, start time.Time)
 	RecordPromptGet(req *mcp.GetPromptRequest, res *mcp.GetPromptResult, err error, start time.Time)
+	// RecordDisruption records a kill and restart of the server by chaos mode
+	RecordDisruption(d *Disruption)
 	GetHistory() CallHistory
+	// Close releases the storage of spilled calls. The history must not be
+	// read after Close.
+	Close() error
 }
 
 // CallRecord is the base for all MCP interaction types
@@ -23,6 +35,14 @@ type CallRecord struct {
 	Timestamp  time.Time `json:"timestamp"`
 	Success    bool      `json:"success"`
 	Error      string    `json:"error,omitempty"`
+	// Spilled locates the request and result of a call that were moved out of
+	// the history to a calls file. They are nil until loaded with
+	// LoadSpilled.
+	Spilled *SpilledCall `json:"spilled,omitempty"`
+
+	// fromSpill is set on calls whose request and result were read back from
+	// the spill file of the recorder
+	fromSpill bool
 }
 
 type SafeServerRequest[P mcp.Params] struct {
@@ -59,9 +79,16 @@ type SafeRequestExtra struct {
 // ToolCall records a tool invocation
 type ToolCall struct {
 	CallRecord
-	ToolName string               `json:"name"` // this is copied to the top level struct for convenience
-	Request  *mcp.CallToolRequest `json:"request,omitempty"`
-	Result   *mcp.CallToolResult  `json:"result,omitempty"`
+	ToolName string `json:"name"` // this is copied to the top level struct for convenience
+	// ExposedName is the name the agent called the tool by, if the proxy
+	// exposed it under another name than ToolName, its name on the server
+	ExposedName string               `json:"exposedName,omitempty"`
+	Request     *mcp.CallToolRequest `json:"request,omitempty"`
+	Result      *mcp.CallToolResult  `json:"result,omitempty"`
+	// RequestBytes and ResponseBytes are the sizes of the JSON arguments and
+	// result, which are kept when the call is spilled
+	RequestBytes  int64 `json:"requestBytes,omitempty"`
+	ResponseBytes int64 `json:"responseBytes,omitempty"`
 }
 
 func (c *ToolCall) MarshalJSON() ([]byte, error) {
@@ -79,9 +106,13 @@ func (c *ToolCall) MarshalJSON() ([]byte, error) {
 // ResourceRead records a resource read
 type ResourceRead struct {
 	CallRecord
-	URI     string                   `json:"uri"` // this is copied to the top level struct for convenience
-	Request *mcp.ReadResourceRequest `json:"request"`
-	Result  *mcp.ReadResourceResult  `json:"result"`
+	URI string `json:"uri"` // this is copied to the top level struct for convenience
+	// Template is the URI template of the resource, if it was read through a
+	// resource template, and TemplateParams are the values of its variables
+	Template       string                   `json:"template,omitempty"`
+	TemplateParams map[string]string        `json:"templateParams,omitempty"`
+	Request        *mcp.ReadResourceRequest `json:"request"`
+	Result         *mcp.ReadResourceResult  `json:"result"`
 }
 
 func (r *ResourceRead) MarshalJSON() ([]byte, error) {
@@ -116,55 +147,101 @@ func (p *PromptGet) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// Disruption records a kill and restart of a stdio server by chaos mode
+type Disruption struct {
+	ServerName string    `json:"serverName"`
+	Timestamp  time.Time `json:"timestamp"` // When the server was killed
+	// Trigger is what caused the disruption: "calls" or "time"
+	Trigger string `json:"trigger"`
+	// Calls is the number of tool calls made before the server was killed
+	Calls int `json:"calls"`
+	// Restarted is true if the server was restarted, otherwise Error says why
+	// it was not
+	Restarted bool   `json:"restarted"`
+	Error     string `json:"error,omitempty"`
+	// Downtime is how long the server was unavailable
+	Downtime time.Duration `json:"downtime"`
+}
+
 // CallHistory contains a complete call history for a server
 type CallHistory struct {
 	ToolCalls     []*ToolCall
 	ResourceReads []*ResourceRead
 	PromptGets    []*PromptGet
+	Disruptions   []*Disruption `json:",omitempty"`
 }
 
 type recorder struct {
 	serverName string
+	limits     *CallHistoryLimits
 
 	mu      sync.RWMutex
 	history *CallHistory
+
+	// resident are the calls whose request and result are still in memory,
+	// oldest first. Calls past the limits are spilled to spill, with their
+	// locations in spilled.
+	resident []any
+	spill    spillFile
+	spilled  map[any]spillRef
 }
 
 var _ Recorder = &recorder{}
 
 func NewRecorder(serverName string) Recorder {
+	return NewRecorderWithLimits(serverName, nil)
+}
+
+// NewRecorderWithLimits creates a recorder that keeps its history within the
+// limits, or the default limits if limits is nil
+func NewRecorderWithLimits(serverName string, limits *CallHistoryLimits) Recorder {
 	return &recorder{
 		serverName: serverName,
+		limits:     limits,
 		history: &CallHistory{
 			ToolCalls:     make([]*ToolCall, 0),
 			ResourceReads: make([]*ResourceRead, 0),
 			PromptGets:    make([]*PromptGet, 0),
 		},
+		spilled: make(map[any]spillRef),
 	}
 }
 
 func (r *recorder) RecordToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, start time.Time) {
+	r.RecordRenamedToolCall(req.Params.Name, req, res, err, start)
+}
+
+func (r *recorder) RecordRenamedToolCall(toolName string, req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, start time.Time) {
+	requestBytes, responseBytes := callSizes(req, res)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.history.ToolCalls = append(r.history.ToolCalls, &ToolCall{
+	call := &ToolCall{
 		CallRecord: CallRecord{
 			ServerName: r.serverName,
 			Timestamp:  start,
 			Success:    err == nil,
 			Error:      errorToString(err),
 		},
-		ToolName: req.Params.Name,
-		Request:  req,
-		Result:   res,
-	})
+		ToolName:      toolName,
+		Request:       req,
+		Result:        res,
+		RequestBytes:  requestBytes,
+		ResponseBytes: responseBytes,
+	}
+	if req.Params.Name != toolName {
+		call.ExposedName = req.Params.Name
+	}
+	r.history.ToolCalls = append(r.history.ToolCalls, call)
+	r.track(call)
 }
 
 func (r *recorder) RecordResourceRead(req *mcp.ReadResourceRequest, res *mcp.ReadResourceResult, err error, start time.Time) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.history.ResourceReads = append(r.history.ResourceReads, &ResourceRead{
+	call := &ResourceRead{
 		CallRecord: CallRecord{
 			ServerName: r.serverName,
 			Timestamp:  start,
@@ -174,14 +251,37 @@ func (r *recorder) RecordResourceRead(req *mcp.ReadResourceRequest, res *mcp.Rea
 		URI:     req.Params.URI,
 		Request: req,
 		Result:  res,
-	})
+	}
+	r.history.ResourceReads = append(r.history.ResourceReads, call)
+	r.track(call)
+}
+
+func (r *recorder) RecordResourceTemplateRead(uriTemplate string, req *mcp.ReadResourceRequest, res *mcp.ReadResourceResult, err error, start time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	call := &ResourceRead{
+		CallRecord: CallRecord{
+			ServerName: r.serverName,
+			Timestamp:  start,
+			Success:    err == nil,
+			Error:      errorToString(err),
+		},
+		URI:            req.Params.URI,
+		Template:       uriTemplate,
+		TemplateParams: templateParams(uriTemplate, req.Params.URI),
+		Request:        req,
+		Result:         res,
+	}
+	r.history.ResourceReads = append(r.history.ResourceReads, call)
+	r.track(call)
 }
 
 func (r *recorder) RecordPromptGet(req *mcp.GetPromptRequest, res *mcp.GetPromptResult, err error, start time.Time) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.history.PromptGets = append(r.history.PromptGets, &PromptGet{
+	call := &PromptGet{
 		CallRecord: CallRecord{
 			ServerName: r.serverName,
 			Timestamp:  start,
@@ -191,14 +291,181 @@ func (r *recorder) RecordPromptGet(req *mcp.GetPromptRequest, res *mcp.GetPrompt
 		Name:    req.Params.Name,
 		Request: req,
 		Result:  res,
-	})
+	}
+	r.history.PromptGets = append(r.history.PromptGets, call)
+	r.track(call)
 }
 
+func (r *recorder) RecordDisruption(d *Disruption) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d.ServerName = r.serverName
+	r.history.Disruptions = append(r.history.Disruptions, d)
+}
+
+// GetHistory returns copies of the recorded calls, with the requests and
+// results of spilled calls read back
 func (r *recorder) GetHistory() CallHistory {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return *r.history
+	history := CallHistory{
+		ToolCalls:     make([]*ToolCall, 0, len(r.history.ToolCalls)),
+		ResourceReads: make([]*ResourceRead, 0, len(r.history.ResourceReads)),
+		PromptGets:    make([]*PromptGet, 0, len(r.history.PromptGets)),
+	}
+
+	for _, c := range r.history.ToolCalls {
+		call := *c
+		if ref, ok := r.spilled[c]; ok {
+			call.Request, call.Result = readCall[*mcp.CallToolParamsRaw, *mcp.CallToolResult](&r.spill, ref, &call.CallRecord)
+			call.fromSpill = true
+		}
+		history.ToolCalls = append(history.ToolCalls, &call)
+	}
+	for _, c := range r.history.ResourceReads {
+		call := *c
+		if ref, ok := r.spilled[c]; ok {
+			call.Request, call.Result = readCall[*mcp.ReadResourceParams, *mcp.ReadResourceResult](&r.spill, ref, &call.CallRecord)
+			call.fromSpill = true
+		}
+		history.ResourceReads = append(history.ResourceReads, &call)
+	}
+	for _, c := range r.history.PromptGets {
+		call := *c
+		if ref, ok := r.spilled[c]; ok {
+			call.Request, call.Result = readCall[*mcp.GetPromptParams, *mcp.GetPromptResult](&r.spill, ref, &call.CallRecord)
+			call.fromSpill = true
+		}
+		history.PromptGets = append(history.PromptGets, &call)
+	}
+	for _, d := range r.history.Disruptions {
+		disruption := *d
+		history.Disruptions = append(history.Disruptions, &disruption)
+	}
+
+	return history
+}
+
+func (r *recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.spill.close()
+}
+
+// track spills the request and result of a new call if they are too large,
+// and spills the oldest calls in memory past the limit. A call that cannot be
+// spilled is kept in memory. The caller must hold r.mu.
+func (r *recorder) track(call any) {
+	if maxPayload := r.limits.maxPayload(); maxPayload > 0 {
+		data, err := encodeRecorded(call)
+		if err == nil && len(data) > maxPayload && r.spillCall(call, data) == nil {
+			return
+		}
+	}
+
+	r.resident = append(r.resident, call)
+
+	maxCalls := r.limits.maxCalls()
+	if maxCalls == 0 {
+		return
+	}
+	for len(r.resident) > maxCalls {
+		oldest := r.resident[0]
+		r.resident = r.resident[1:]
+
+		data, err := encodeRecorded(oldest)
+		if err == nil {
+			err = r.spillCall(oldest, data)
+		}
+		if err != nil {
+			// Keep the remaining calls in memory rather than lose them
+			r.resident = append([]any{oldest}, r.resident...)
+			return
+		}
+	}
+}
+
+// spillCall writes the encoded request and result of a call to the spill
+// file, and drops them from memory
+func (r *recorder) spillCall(call any, data []byte) error {
+	ref, err := r.spill.write(data)
+	if err != nil {
+		return err
+	}
+
+	switch c := call.(type) {
+	case *ToolCall:
+		c.Request, c.Result = nil, nil
+	case *ResourceRead:
+		c.Request, c.Result = nil, nil
+	case *PromptGet:
+		c.Request, c.Result = nil, nil
+	}
+	r.spilled[call] = ref
+	return nil
+}
+
+// encodeRecorded encodes the request and result of a recorded call for the
+// spill file
+func encodeRecorded(call any) ([]byte, error) {
+	switch c := call.(type) {
+	case *ToolCall:
+		return encodeCall(c.Request, c.Result)
+	case *ResourceRead:
+		return encodeCall(c.Request, c.Result)
+	case *PromptGet:
+		return encodeCall(c.Request, c.Result)
+	default:
+		return nil, fmt.Errorf("unknown call type %T", call)
+	}
+}
+
+// readCall reads the request and result of a spilled call. If they cannot be
+// read, the error is recorded on the call instead.
+func readCall[P mcp.Params, R any](f *spillFile, ref spillRef, record *CallRecord) (*mcp.ServerRequest[P], R) {
+	var req *mcp.ServerRequest[P]
+	var res R
+
+	data, err := f.read(ref)
+	if err == nil {
+		req, res, err = decodeCall[P, R](data)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("failed to read spilled call: %s", err)
+		if record.Error != "" {
+			msg = record.Error + "; " + msg
+		}
+		record.Error = msg
+	}
+	return req, res
+}
+
+// templateParams returns the values of the variables of a URI template in a
+// URI. List values are joined with commas.
+func templateParams(uriTemplate, uri string) map[string]string {
+	tmpl, err := uritemplate.New(uriTemplate)
+	if err != nil {
+		return nil
+	}
+
+	values := tmpl.Match(uri)
+	if len(values) == 0 {
+		return nil
+	}
+
+	params := make(map[string]string, len(values))
+	for name, value := range values {
+		switch value.T {
+		case uritemplate.ValueTypeString:
+			params[name] = value.String()
+		default:
+			params[name] = strings.Join(value.V, ",")
+		}
+	}
+	return params
 }
 
 func errorToString(err error) string {
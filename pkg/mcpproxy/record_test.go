@@ -0,0 +1,190 @@
+package mcpproxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateParams(t *testing.T) {
+	tests := map[string]struct {
+		template string
+		uri      string
+		want     map[string]string
+	}{
+		"simple": {
+			template: "k8s://namespaces/{namespace}/pods/{name}",
+			uri:      "k8s://namespaces/default/pods/nginx",
+			want:     map[string]string{"namespace": "default", "name": "nginx"},
+		},
+		"reserved expansion": {
+			template: "file:///{+path}",
+			uri:      "file:///data/config.json",
+			want:     map[string]string{"path": "data/config.json"},
+		},
+		"no match": {
+			template: "k8s://namespaces/{namespace}",
+			uri:      "file:///data/config.json",
+		},
+		"invalid template": {
+			template: "k8s://namespaces/{namespace",
+			uri:      "k8s://namespaces/default",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, templateParams(tc.template, tc.uri))
+		})
+	}
+}
+
+// recordCalls records n tool calls whose results are text of the given size,
+// a resource read, and a prompt get
+func recordCalls(r Recorder, n, size int) {
+	for i := range n {
+		r.RecordToolCall(&mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "get_pod", Arguments: json.RawMessage(fmt.Sprintf(`{"n":%d}`, i))},
+			Extra:  &mcp.RequestExtra{Header: http.Header{"X-Call": {fmt.Sprint(i)}}},
+		}, &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("x", size)}},
+		}, nil, time.Now())
+	}
+	r.RecordResourceRead(&mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: "file:///log.txt"},
+	}, &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: "file:///log.txt", Text: strings.Repeat("y", size)}},
+	}, nil, time.Now())
+	r.RecordPromptGet(&mcp.GetPromptRequest{
+		Params: &mcp.GetPromptParams{Name: "debug", Arguments: map[string]string{"pod": "nginx"}},
+	}, nil, fmt.Errorf("prompt not found"), time.Now())
+}
+
+func TestRecorderLimits(t *testing.T) {
+	tests := map[string]struct {
+		limits          *CallHistoryLimits
+		size            int
+		expectedSpilled int
+	}{
+		"defaults": {
+			size: 10,
+		},
+		"calls past the limit are spilled": {
+			limits:          &CallHistoryLimits{MaxCallsInMemory: 2},
+			size:            10,
+			expectedSpilled: 5,
+		},
+		"large payloads are spilled": {
+			limits:          &CallHistoryLimits{MaxPayloadBytes: 100},
+			size:            200,
+			expectedSpilled: 6,
+		},
+		"limits disabled": {
+			limits: &CallHistoryLimits{MaxCallsInMemory: -1, MaxPayloadBytes: -1},
+			size:   DefaultMaxPayloadBytes + 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewRecorderWithLimits("kubernetes", tc.limits).(*recorder)
+			recordCalls(r, 5, tc.size)
+			assert.Len(t, r.spilled, tc.expectedSpilled)
+
+			history := r.GetHistory()
+			require.Len(t, history.ToolCalls, 5)
+			for i, call := range history.ToolCalls {
+				require.NotNil(t, call.Request)
+				assert.JSONEq(t, fmt.Sprintf(`{"n":%d}`, i), string(call.Request.Params.Arguments))
+				assert.Equal(t, fmt.Sprint(i), call.Request.Extra.Header.Get("X-Call"))
+				require.NotNil(t, call.Result)
+				assert.Equal(t, strings.Repeat("x", tc.size), call.Result.Content[0].(*mcp.TextContent).Text)
+				assert.Empty(t, call.Error)
+			}
+
+			require.Len(t, history.ResourceReads, 1)
+			assert.Equal(t, "file:///log.txt", history.ResourceReads[0].Request.Params.URI)
+			assert.Equal(t, strings.Repeat("y", tc.size), history.ResourceReads[0].Result.Contents[0].Text)
+
+			require.Len(t, history.PromptGets, 1)
+			assert.Equal(t, "nginx", history.PromptGets[0].Request.Params.Arguments["pod"])
+			assert.Nil(t, history.PromptGets[0].Result)
+			assert.Equal(t, "prompt not found", history.PromptGets[0].Error)
+
+			require.NoError(t, r.Close())
+		})
+	}
+}
+
+func TestRecorderHistoryIsCopied(t *testing.T) {
+	r := NewRecorderWithLimits("kubernetes", &CallHistoryLimits{MaxCallsInMemory: 1})
+	defer r.Close()
+
+	recordCalls(r, 1, 10)
+	history := r.GetHistory()
+
+	// Spilling the call later does not change the history already read
+	recordCalls(r, 1, 10)
+	require.NotNil(t, history.ToolCalls[0].Result)
+	assert.Equal(t, "xxxxxxxxxx", history.ToolCalls[0].Result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestRecorderSpillFileRemoved(t *testing.T) {
+	r := NewRecorderWithLimits("kubernetes", &CallHistoryLimits{MaxCallsInMemory: 1}).(*recorder)
+	recordCalls(r, 2, 10)
+	require.NotNil(t, r.spill.file)
+
+	name := r.spill.file.Name()
+	require.NoError(t, r.Close())
+	_, err := os.Stat(name)
+	assert.True(t, os.IsNotExist(err))
+
+	// Reading after Close records the failure instead of the payload
+	history := r.GetHistory()
+	assert.Nil(t, history.ToolCalls[0].Result)
+	assert.Contains(t, history.ToolCalls[0].Error, "failed to read spilled call")
+}
+
+func TestCallHistoryMoveSpilled(t *testing.T) {
+	r := NewRecorderWithLimits("kubernetes", &CallHistoryLimits{MaxCallsInMemory: 2})
+	defer r.Close()
+
+	recordCalls(r, 3, 10)
+	history := r.GetHistory()
+	require.True(t, history.HasSpilled())
+
+	path := filepath.Join(t.TempDir(), "calls.jsonl")
+	require.NoError(t, history.MoveSpilled(path))
+	assert.False(t, history.HasSpilled())
+
+	// The three oldest calls were spilled, and only refer to the calls file
+	for _, call := range history.ToolCalls {
+		require.NotNil(t, call.Spilled)
+		assert.Nil(t, call.Request)
+		assert.Nil(t, call.Result)
+	}
+	assert.Nil(t, history.ResourceReads[0].Spilled)
+	assert.NotNil(t, history.ResourceReads[0].Result)
+
+	// The references survive the results file, and load the calls back
+	data, err := json.Marshal(history.ToolCalls[1])
+	require.NoError(t, err)
+	call := &ToolCall{}
+	require.NoError(t, json.Unmarshal(data, call))
+	require.NoError(t, call.LoadSpilled())
+	require.NotNil(t, call.Request)
+	assert.JSONEq(t, `{"n":1}`, string(call.Request.Params.Arguments))
+	assert.Equal(t, "xxxxxxxxxx", call.Result.Content[0].(*mcp.TextContent).Text)
+
+	require.NoError(t, os.Remove(path))
+	assert.ErrorContains(t, history.ToolCalls[0].LoadSpilled(), "failed to open calls file")
+}
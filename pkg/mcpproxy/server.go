@@ -2,11 +2,16 @@ package mcpproxy
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"net/http"
+	"os"
 	"os/exec"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -26,12 +31,25 @@ type Server interface {
 type server struct {
 	name        string
 	proxyServer *mcp.Server
-	proxyClient *mcp.ClientSession
+	upstream    *upstream
 	cfg         *ServerConfig // TODO(Cali0707): see if we actually need this
 	url         string
 
+	// toolNames are the names renamed tools are exposed under, by their name
+	// on the server
+	toolNames map[string]string
+
 	// Call tracking
 	recorder Recorder
+	limits   *CallHistoryLimits
+
+	// chaos kills and restarts the server, with a chaos config
+	chaos *chaosMonitor
+
+	// tasks are the views of the server scoped to a task, served under
+	// /mcp/tasks/<task>
+	mu    sync.Mutex
+	tasks map[string]*taskServer
 
 	// Ready signaling
 	ready    chan struct{}
@@ -41,14 +59,58 @@ type server struct {
 var _ Server = &server{}
 
 func NewProxyServerForConfig(ctx context.Context, name string, config *ServerConfig) (Server, error) {
-	cs, err := createProxyClient(ctx, config)
+	u, err := connectUpstream(ctx, name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := newServer(ctx, name, config, u, nil)
+	if err != nil {
+		_ = u.close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// connectUpstream creates the upstream client session of a server, or
+// acquires it from the ServerPool of ctx. Servers in chaos mode are not
+// pooled, since their process is killed.
+func connectUpstream(ctx context.Context, name string, config *ServerConfig) (*upstream, error) {
+	u := &upstream{}
+	var err error
+
+	pool, pooled := ServerPoolFromContext(ctx)
+	if pooled && config.IsStdio() && config.Chaos == nil {
+		u.cs, u.release, err = pool.acquire(ctx, name, config, func() (*mcp.ClientSession, error) {
+			return createProxyClient(ctx, name, config)
+		})
+	} else {
+		u.cs, u.cmd, err = startProxyClient(ctx, name, config)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proxy client for %+v: %w", config, err)
 	}
 
-	r := NewRecorder(name)
+	if config.ProtocolVersionMismatch != ProtocolVersionMismatchWarn {
+		if err := compatibility(name, config, u.cs.InitializeResult()).Err(); err != nil {
+			_ = u.close()
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
 
-	s, err := createProxyServer(ctx, cs, r)
+// newServer creates a proxy server for an upstream, exposing the tools in
+// toolNames under the names they map to. The upstream is closed when the
+// server is closed.
+func newServer(ctx context.Context, name string, config *ServerConfig, u *upstream, toolNames map[string]string) (*server, error) {
+	limits := CallHistoryLimitsFromContext(ctx)
+	r := NewRecorderWithLimits(name, limits)
+
+	chaos := newChaosMonitor(name, config, u, r)
+	s, err := createProxyServer(ctx, u, r, config, toolNames, chaos)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proxy server for %+v: %w", config, err)
 	}
@@ -56,26 +118,65 @@ func NewProxyServerForConfig(ctx context.Context, name string, config *ServerCon
 	return &server{
 		name:        name,
 		proxyServer: s,
-		proxyClient: cs,
+		upstream:    u,
 		cfg:         config,
+		toolNames:   toolNames,
 		recorder:    r,
+		limits:      limits,
+		chaos:       chaos,
+		tasks:       make(map[string]*taskServer),
 		ready:       make(chan struct{}),
 	}, nil
 }
 
-func createProxyClient(ctx context.Context, config *ServerConfig) (*mcp.ClientSession, error) {
+// Connect starts or connects to the MCP server described by config and returns
+// an initialized client session. The caller is responsible for closing it.
+func Connect(ctx context.Context, config *ServerConfig) (*mcp.ClientSession, error) {
+	return createProxyClient(ctx, "", config)
+}
+
+func createProxyClient(ctx context.Context, name string, config *ServerConfig) (*mcp.ClientSession, error) {
+	cs, _, err := startProxyClient(ctx, name, config)
+	return cs, err
+}
+
+// startProxyClient starts or connects to an MCP server and returns an
+// initialized client session, and the process of a stdio server
+func startProxyClient(ctx context.Context, name string, config *ServerConfig) (*mcp.ClientSession, *exec.Cmd, error) {
+	timeout, err := config.GetStartupTimeout()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var transport mcp.Transport
-	if config.IsHttp() {
-		client := &http.Client{
-			Transport: NewHeaderRoundTripper(config.Headers, nil),
+	var stderr *stderrBuffer
+	var cmd *exec.Cmd
+	switch {
+	case config.IsHttp():
+		client, err := newUpstreamHTTPClient(ctx, name, config)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		transport = &mcp.StreamableClientTransport{
-			Endpoint:   config.URL,
+			Endpoint:   expandEnv(config.URL),
 			HTTPClient: client,
 		}
-	} else {
-		cmd := exec.Command(config.Command, config.Args...)
+	case config.IsWebSocket():
+		client, err := newUpstreamHTTPClient(ctx, name, config)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		transport = &WebSocketClientTransport{
+			Endpoint:   expandEnv(config.URL),
+			HTTPClient: client,
+		}
+	default:
+		cmd = exec.Command(config.Command, config.Args...)
+		cmd.Env = commandEnv(config)
+		stderr = newStderrBuffer(maxCapturedStderr)
+		cmd.Stderr = stderr
 		transport = &mcp.CommandTransport{Command: cmd}
 	}
 
@@ -84,15 +185,72 @@ func createProxyClient(ctx context.Context, config *ServerConfig) (*mcp.ClientSe
 		Version: "0.0.0",
 	}, nil)
 
-	cs, err := client.Connect(ctx, transport, nil)
+	startCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cs, err := client.Connect(startCtx, transport, nil)
 	if err != nil {
-		return nil, err
+		if errors.Is(startCtx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("server did not initialize within startup timeout of %s: %w", timeout, err)
+		}
+		return nil, nil, withStderr(err, stderr)
+	}
+
+	if err := probeServer(startCtx, cs); err != nil {
+		_ = cs.Close()
+		return nil, nil, withStderr(fmt.Errorf("server failed readiness check: %w", err), stderr)
+	}
+
+	return cs, cmd, nil
+}
+
+// commandEnv returns the environment of a stdio server: the environment of
+// mcpchecker with the env of the config added, and ${VAR} references in its
+// values expanded. It is nil when the config has no env, so that the server
+// inherits the environment as is.
+func commandEnv(config *ServerConfig) []string {
+	if len(config.Env) == 0 {
+		return nil
+	}
+
+	env := os.Environ()
+	for _, k := range slices.Sorted(maps.Keys(config.Env)) {
+		env = append(env, k+"="+expandEnv(config.Env[k]))
+	}
+	return env
+}
+
+// newUpstreamHTTPClient creates the HTTP client used to reach a remote server,
+// applying the configured TLS options, auth, and headers.
+func newUpstreamHTTPClient(ctx context.Context, name string, config *ServerConfig) (*http.Client, error) {
+	base, err := newTLSTransport(config.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure tls: %w", err)
 	}
 
-	return cs, nil
+	// Traffic is captured below auth, so that the headers as sent are seen.
+	// Requests for OAuth2 tokens are not captured, since they carry the
+	// client secret and the tokens.
+	tokenTransport := base
+	if recorder, ok := TrafficRecorderFromContext(ctx); ok {
+		base = recorder.roundTripper(name, base)
+	}
+
+	rt, err := newAuthRoundTripper(ctx, config.Auth, base, tokenTransport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure auth: %w", err)
+	}
+
+	return &http.Client{
+		Transport: NewHeaderRoundTripper(expandEnvMap(config.Headers), rt),
+	}, nil
 }
 
-func createProxyServer(ctx context.Context, cs *mcp.ClientSession, r Recorder) (*mcp.Server, error) {
+// createProxyServer creates a proxy server for an upstream, recording calls
+// to r. Tool results are limited by limit, if set, the tools in toolNames are
+// exposed under the names they map to, and tool calls are counted by chaos.
+func createProxyServer(ctx context.Context, u *upstream, r Recorder, config *ServerConfig, toolNames map[string]string, chaos *chaosMonitor) (*mcp.Server, error) {
+	cs := u.session()
 	opts := &mcp.ServerOptions{
 		Instructions: cs.InitializeResult().Instructions,
 		HasPrompts:   cs.InitializeResult().Capabilities.Prompts != nil,
@@ -111,7 +269,11 @@ func createProxyServer(ctx context.Context, cs *mcp.ClientSession, r Recorder) (
 			}
 			s.AddPrompt(p, func(ctx context.Context, gpr *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
 				start := time.Now()
-				res, err := cs.GetPrompt(ctx, gpr.Params)
+				if err := injectLatency(ctx, config.Latency.forServer()); err != nil {
+					r.RecordPromptGet(gpr, nil, err, start)
+					return nil, err
+				}
+				res, err := u.session().GetPrompt(ctx, gpr.Params)
 				r.RecordPromptGet(gpr, res, err, start)
 				return res, err
 			})
@@ -125,7 +287,11 @@ func createProxyServer(ctx context.Context, cs *mcp.ClientSession, r Recorder) (
 			}
 			s.AddResource(rr, func(ctx context.Context, rrr *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 				start := time.Now()
-				res, err := cs.ReadResource(ctx, rrr.Params)
+				if err := injectLatency(ctx, config.Latency.forServer()); err != nil {
+					r.RecordResourceRead(rrr, nil, err, start)
+					return nil, err
+				}
+				res, err := u.session().ReadResource(ctx, rrr.Params)
 				r.RecordResourceRead(rrr, res, err, start)
 				return res, err
 			})
@@ -137,26 +303,50 @@ func createProxyServer(ctx context.Context, cs *mcp.ClientSession, r Recorder) (
 			}
 			s.AddResourceTemplate(rt, func(ctx context.Context, rrr *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 				start := time.Now()
-				res, err := cs.ReadResource(ctx, rrr.Params)
-				r.RecordResourceRead(rrr, res, err, start)
+				if err := injectLatency(ctx, config.Latency.forServer()); err != nil {
+					r.RecordResourceTemplateRead(rt.URITemplate, rrr, nil, err, start)
+					return nil, err
+				}
+				res, err := u.session().ReadResource(ctx, rrr.Params)
+				r.RecordResourceTemplateRead(rt.URITemplate, rrr, res, err, start)
 				return res, err
 			})
 		}
 	}
 
 	if opts.HasTools {
+		limiter := newResultLimiter(config.ResultLimit)
 		for t, err := range cs.Tools(ctx, &mcp.ListToolsParams{}) {
 			if err != nil {
 				continue
 			}
-			s.AddTool(t, func(ctx context.Context, ctr *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			exposed, renamed := toolNames[t.Name]
+			tool := limiter.tool(t)
+			if renamed {
+				tool = renameTool(tool, exposed)
+			}
+			s.AddTool(tool, func(ctx context.Context, ctr *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 				start := time.Now()
-				res, err := cs.CallTool(ctx, &mcp.CallToolParams{
-					Meta:      ctr.Params.Meta,
-					Name:      ctr.Params.Name,
-					Arguments: ctr.Params.Arguments,
+				req := ctr
+				if renamed {
+					req = renameToolRequest(ctr, t.Name)
+				}
+				// The agent's view of the call is recorded: its arguments
+				// and the limited result
+				res, err := limiter.callTool(ctx, req, ctr.Params.Name, func(ctx context.Context, args json.RawMessage) (*mcp.CallToolResult, error) {
+					// Pages of paginated results are not delayed, since
+					// they are served without calling the server
+					if err := injectLatency(ctx, config.Latency.forTool(t.Name)); err != nil {
+						return nil, err
+					}
+					return u.session().CallTool(ctx, &mcp.CallToolParams{
+						Meta:      req.Params.Meta,
+						Name:      req.Params.Name,
+						Arguments: args,
+					})
 				})
-				r.RecordToolCall(ctr, res, err, start)
+				r.RecordRenamedToolCall(t.Name, ctr, res, err, start)
+				chaos.toolCalled()
 				return res, err
 			})
 		}
@@ -176,6 +366,16 @@ func (s *server) Run(ctx context.Context) error {
 	}, &mcp.StreamableHTTPOptions{})
 
 	mux.Handle("/mcp", handler)
+	mux.Handle("/mcp/tasks/{task}", mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if ts, ok := s.tasks[r.PathValue("task")]; ok {
+			return ts.proxyServer
+		}
+		// The handler answers 400 for a task without a view
+		return nil
+	}, &mcp.StreamableHTTPOptions{}))
 
 	listener, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
@@ -188,6 +388,7 @@ func (s *server) Run(ctx context.Context) error {
 
 	// Signal that the server is ready (URL is set and listener is ready)
 	close(s.ready)
+	s.chaos.start(ctx)
 
 	httpServer := &http.Server{
 		Handler: mux,
@@ -233,25 +434,41 @@ func (s *server) GetName() string {
 	return s.name
 }
 
+// GetCompatibility returns the protocol version the server negotiated, and
+// whether its config accepts it
+func (s *server) GetCompatibility() Compatibility {
+	return compatibility(s.name, s.cfg, s.upstream.session().InitializeResult())
+}
+
+// GetAllowedTools returns the tools the agent may call, under the names they
+// are exposed under. alwaysAllow lists tools by their names on the server.
 func (s *server) GetAllowedTools() []*mcp.Tool {
 	allowed := []*mcp.Tool{}
-	for t, err := range s.proxyClient.Tools(context.Background(), &mcp.ListToolsParams{}) {
+	for t, err := range s.upstream.session().Tools(context.Background(), &mcp.ListToolsParams{}) {
 		if err != nil {
 			continue
 		}
 
-		if s.cfg.EnableAllTools {
-			allowed = append(allowed, t)
-		} else if slices.Contains(s.cfg.AlwaysAllow, t.Name) {
-			allowed = append(allowed, t)
+		if !s.cfg.EnableAllTools && !slices.Contains(s.cfg.AlwaysAllow, t.Name) {
+			continue
+		}
+		if exposed, ok := s.toolNames[t.Name]; ok {
+			t = renameTool(t, exposed)
 		}
+		allowed = append(allowed, t)
 	}
 
 	return allowed
 }
 
+// AllowsAllTools returns whether all tools of the server are allowed, rather
+// than only those listed in alwaysAllow
+func (s *server) AllowsAllTools() bool {
+	return s.cfg.EnableAllTools
+}
+
 func (s *server) Close() error {
-	return s.proxyClient.Close()
+	return errors.Join(s.recorder.Close(), s.upstream.close())
 }
 
 func (s *server) GetCallHistory() CallHistory {
@@ -8,6 +8,7 @@ import (
 	"os"
 	"slices"
 	"sort"
+	"sync"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -27,21 +28,54 @@ type ServerManager interface {
 	// aggregate call tracking
 	GetAllCallHistory() *CallHistory
 	GetCallHistoryForServer(serverName string) (CallHistory, bool)
+
+	// ForTask returns a view of the servers scoped to a task, which shares
+	// their upstream sessions but serves and records the calls of the task
+	// separately, so that tasks can run concurrently against the same servers.
+	// The view must be started and closed like a ServerManager, and closed
+	// before the manager it was created from.
+	ForTask(ctx context.Context, task string) (ServerManager, error)
 }
 
 type serverManager struct {
 	servers map[string]Server
-	tmpDir  string
+
+	mu     sync.Mutex
+	tmpDir string
 
 	cancel context.CancelFunc
 	eg     *errgroup.Group
 }
 
+// NewServerManger creates the proxy servers of a config. Tools are renamed
+// following the toolNaming of their servers, which needs the tools of all
+// servers, so all upstream sessions are connected first.
 func NewServerManger(ctx context.Context, cfg *MCPConfig) (ServerManager, error) {
+	upstreams := make(map[string]*upstream, len(cfg.MCPServers))
+	releaseAll := func() {
+		for _, u := range upstreams {
+			_ = u.close()
+		}
+	}
+
+	tools := make(map[string][]string, len(cfg.MCPServers))
+	for n, serverCfg := range cfg.MCPServers {
+		u, err := connectUpstream(ctx, n, serverCfg)
+		if err != nil {
+			releaseAll()
+			return nil, err
+		}
+
+		upstreams[n] = u
+		tools[n] = listToolNames(ctx, u.session())
+	}
+
+	toolNames := exposedToolNames(cfg.MCPServers, tools)
 	servers := make(map[string]Server, len(cfg.MCPServers))
-	for n, cfg := range cfg.MCPServers {
-		s, err := NewProxyServerForConfig(ctx, n, cfg)
+	for n, serverCfg := range cfg.MCPServers {
+		s, err := newServer(ctx, n, serverCfg, upstreams[n], toolNames[n])
 		if err != nil {
+			releaseAll()
 			return nil, err
 		}
 
@@ -54,6 +88,9 @@ func NewServerManger(ctx context.Context, cfg *MCPConfig) (ServerManager, error)
 }
 
 func (m *serverManager) GetMcpServerFiles() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.tmpDir != "" {
 		return []string{fmt.Sprintf("%s/%s", m.tmpDir, mcpServerFileName)}, nil
 	}
@@ -128,10 +165,8 @@ func (m *serverManager) Close() error {
 	}
 
 	// Close all servers (cleanup connections, etc.)
-	for name, srv := range m.servers {
-		if err := srv.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to close server %s: %w", name, err))
-		}
+	if err := m.closeServers(); err != nil {
+		errs = append(errs, err)
 	}
 
 	// Clean up temp directory
@@ -152,6 +187,7 @@ func (m *serverManager) GetAllCallHistory() *CallHistory {
 		combined.PromptGets = append(combined.PromptGets, history.PromptGets...)
 		combined.ResourceReads = append(combined.ResourceReads, history.ResourceReads...)
 		combined.ToolCalls = append(combined.ToolCalls, history.ToolCalls...)
+		combined.Disruptions = append(combined.Disruptions, history.Disruptions...)
 	}
 
 	// sort all by timestamp for chronological order
@@ -164,6 +200,9 @@ func (m *serverManager) GetAllCallHistory() *CallHistory {
 	sort.Slice(combined.PromptGets, func(i, j int) bool {
 		return combined.PromptGets[i].Timestamp.Before(combined.PromptGets[j].Timestamp)
 	})
+	sort.Slice(combined.Disruptions, func(i, j int) bool {
+		return combined.Disruptions[i].Timestamp.Before(combined.Disruptions[j].Timestamp)
+	})
 
 	return &combined
 }
@@ -177,6 +216,42 @@ func (m *serverManager) GetCallHistoryForServer(serverName string) (CallHistory,
 	return srv.GetCallHistory(), true
 }
 
+func (m *serverManager) ForTask(ctx context.Context, task string) (ServerManager, error) {
+	if task == "" {
+		return nil, fmt.Errorf("task must not be empty")
+	}
+
+	view := &serverManager{
+		servers: make(map[string]Server, len(m.servers)),
+	}
+	for name, srv := range m.servers {
+		s, ok := srv.(*server)
+		if !ok {
+			err := fmt.Errorf("server %s cannot be scoped to a task", name)
+			return nil, errors.Join(err, view.closeServers())
+		}
+
+		ts, err := s.forTask(ctx, task)
+		if err != nil {
+			return nil, errors.Join(err, view.closeServers())
+		}
+		view.servers[name] = ts
+	}
+
+	return view, nil
+}
+
+// closeServers closes the servers of a manager that was never started
+func (m *serverManager) closeServers() error {
+	var errs []error
+	for name, srv := range m.servers {
+		if err := srv.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close server %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (m *serverManager) getMcpServers() (*MCPConfig, error) {
 	cfg := &MCPConfig{
 		MCPServers: make(map[string]*ServerConfig),
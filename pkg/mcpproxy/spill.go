@@ -0,0 +1,303 @@
+package mcpproxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultMaxCallsInMemory is the number of recent calls of a server whose
+	// requests and results are kept in memory if no limit is configured
+	DefaultMaxCallsInMemory = 1000
+	// DefaultMaxPayloadBytes is the size of the request and result of a call
+	// above which they are spilled if no limit is configured
+	DefaultMaxPayloadBytes = 1 << 20
+)
+
+// CallHistoryLimits bounds the memory the call history of a server takes, so
+// that an agent making thousands of calls, or reading huge resources, does not
+// run the runner out of memory. Requests and results beyond the limits are
+// spilled to a temporary file and read back when the history is read.
+type CallHistoryLimits struct {
+	// MaxCallsInMemory is the number of recent calls whose requests and
+	// results are kept in memory. Defaults to DefaultMaxCallsInMemory; a
+	// negative value disables the limit.
+	MaxCallsInMemory int `json:"maxCallsInMemory,omitempty"`
+
+	// MaxPayloadBytes is the size of the JSON request and result of a call
+	// above which they are spilled right away. Defaults to
+	// DefaultMaxPayloadBytes; a negative value disables the limit.
+	MaxPayloadBytes int `json:"maxPayloadBytes,omitempty"`
+}
+
+// maxCalls returns the limit on calls in memory, or 0 if it is disabled
+func (l *CallHistoryLimits) maxCalls() int {
+	switch {
+	case l == nil || l.MaxCallsInMemory == 0:
+		return DefaultMaxCallsInMemory
+	case l.MaxCallsInMemory < 0:
+		return 0
+	default:
+		return l.MaxCallsInMemory
+	}
+}
+
+// maxPayload returns the limit on payload size, or 0 if it is disabled
+func (l *CallHistoryLimits) maxPayload() int {
+	switch {
+	case l == nil || l.MaxPayloadBytes == 0:
+		return DefaultMaxPayloadBytes
+	case l.MaxPayloadBytes < 0:
+		return 0
+	default:
+		return l.MaxPayloadBytes
+	}
+}
+
+type callHistoryLimitsKey struct{}
+
+// CallHistoryLimitsToContext returns a context that carries the given limits.
+// Proxy servers created with this context record their calls within them.
+func CallHistoryLimitsToContext(ctx context.Context, limits *CallHistoryLimits) context.Context {
+	return context.WithValue(ctx, callHistoryLimitsKey{}, limits)
+}
+
+// CallHistoryLimitsFromContext returns the limits stored in ctx, or nil for
+// the defaults
+func CallHistoryLimitsFromContext(ctx context.Context) *CallHistoryLimits {
+	limits, _ := ctx.Value(callHistoryLimitsKey{}).(*CallHistoryLimits)
+	return limits
+}
+
+// spillRef locates a spilled payload in the spill file
+type spillRef struct {
+	offset int64
+	size   int
+}
+
+// spillFile is an append-only temporary file of spilled payloads, created on
+// the first write. The recorder that owns it serializes writes.
+type spillFile struct {
+	file   *os.File
+	offset int64
+}
+
+func (f *spillFile) write(data []byte) (spillRef, error) {
+	if f.file == nil {
+		file, err := os.CreateTemp("", "mcpchecker-calls-*.jsonl")
+		if err != nil {
+			return spillRef{}, fmt.Errorf("failed to create spill file: %w", err)
+		}
+		f.file = file
+	}
+
+	n, err := f.file.WriteAt(data, f.offset)
+	if err != nil {
+		return spillRef{}, fmt.Errorf("failed to write spill file: %w", err)
+	}
+
+	ref := spillRef{offset: f.offset, size: n}
+	f.offset += int64(n)
+	return ref, nil
+}
+
+func (f *spillFile) read(ref spillRef) ([]byte, error) {
+	if f.file == nil {
+		return nil, fmt.Errorf("spill file is closed")
+	}
+
+	data := make([]byte, ref.size)
+	if _, err := f.file.ReadAt(data, ref.offset); err != nil {
+		return nil, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	return data, nil
+}
+
+// close removes the spill file
+func (f *spillFile) close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	name := f.file.Name()
+	err := f.file.Close()
+	f.file = nil
+	if rmErr := os.Remove(name); rmErr != nil {
+		return fmt.Errorf("failed to remove spill file: %w", rmErr)
+	}
+	return err
+}
+
+// spilledCall is the request and result of a call as written to the spill
+// file. The session of the request is not kept.
+type spilledCall[P mcp.Params, R any] struct {
+	Params P                 `json:"params"`
+	Extra  *SafeRequestExtra `json:"extra,omitempty"`
+	Result R                 `json:"result,omitempty"`
+}
+
+func encodeCall[P mcp.Params, R any](req *mcp.ServerRequest[P], res R) ([]byte, error) {
+	call := spilledCall[P, R]{Result: res}
+	if safe := SafeServerRequestFromUnsafe(req); safe != nil {
+		call.Params = safe.Params
+		call.Extra = safe.Extra
+	}
+	return json.Marshal(call)
+}
+
+func decodeCall[P mcp.Params, R any](data []byte) (*mcp.ServerRequest[P], R, error) {
+	var call spilledCall[P, R]
+	if err := json.Unmarshal(data, &call); err != nil {
+		return nil, call.Result, fmt.Errorf("failed to decode spilled call: %w", err)
+	}
+
+	req := &mcp.ServerRequest[P]{Params: call.Params}
+	if call.Extra != nil {
+		req.Extra = &mcp.RequestExtra{
+			TokenInfo: call.Extra.TokenInfo,
+			Header:    call.Extra.Header,
+		}
+	}
+	return req, call.Result, nil
+}
+
+// SpilledCall locates the request and result of a call in a calls file
+type SpilledCall struct {
+	File   string `json:"file"`
+	Offset int64  `json:"offset"`
+	Size   int    `json:"size"`
+}
+
+// HasSpilled reports whether the requests and results of any calls of the
+// history were spilled while it was recorded
+func (h *CallHistory) HasSpilled() bool {
+	if h == nil {
+		return false
+	}
+	for _, c := range h.ToolCalls {
+		if c.fromSpill {
+			return true
+		}
+	}
+	for _, c := range h.ResourceReads {
+		if c.fromSpill {
+			return true
+		}
+	}
+	for _, c := range h.PromptGets {
+		if c.fromSpill {
+			return true
+		}
+	}
+	return false
+}
+
+// MoveSpilled writes the requests and results of the calls that were spilled
+// while the history was recorded to a calls file at path, and drops them from
+// the history, which refers to them instead. Results of long runs then do not
+// hold every call in memory.
+func (h *CallHistory) MoveSpilled(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create calls file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var offset int64
+	move := func(call any, record *CallRecord) error {
+		if !record.fromSpill {
+			return nil
+		}
+		data, err := encodeRecorded(call)
+		if err != nil {
+			return err
+		}
+		n, err := file.Write(append(data, '\n'))
+		if err != nil {
+			return fmt.Errorf("failed to write calls file: %w", err)
+		}
+		record.Spilled = &SpilledCall{File: path, Offset: offset, Size: len(data)}
+		record.fromSpill = false
+		offset += int64(n)
+		return nil
+	}
+
+	for _, c := range h.ToolCalls {
+		if err := move(c, &c.CallRecord); err != nil {
+			return err
+		}
+		if c.Spilled != nil {
+			c.Request, c.Result = nil, nil
+		}
+	}
+	for _, c := range h.ResourceReads {
+		if err := move(c, &c.CallRecord); err != nil {
+			return err
+		}
+		if c.Spilled != nil {
+			c.Request, c.Result = nil, nil
+		}
+	}
+	for _, c := range h.PromptGets {
+		if err := move(c, &c.CallRecord); err != nil {
+			return err
+		}
+		if c.Spilled != nil {
+			c.Request, c.Result = nil, nil
+		}
+	}
+	return file.Close()
+}
+
+// LoadSpilled reads the request and result of a call from its calls file, if
+// they were moved there
+func (c *ToolCall) LoadSpilled() error {
+	if c.Spilled == nil || c.Request != nil || c.Result != nil {
+		return nil
+	}
+	req, res, err := loadSpilled[*mcp.CallToolParamsRaw, *mcp.CallToolResult](c.Spilled)
+	c.Request, c.Result = req, res
+	return err
+}
+
+// LoadSpilled reads the request and result of a resource read from its calls
+// file, if they were moved there
+func (r *ResourceRead) LoadSpilled() error {
+	if r.Spilled == nil || r.Request != nil || r.Result != nil {
+		return nil
+	}
+	req, res, err := loadSpilled[*mcp.ReadResourceParams, *mcp.ReadResourceResult](r.Spilled)
+	r.Request, r.Result = req, res
+	return err
+}
+
+// LoadSpilled reads the request and result of a prompt get from its calls
+// file, if they were moved there
+func (p *PromptGet) LoadSpilled() error {
+	if p.Spilled == nil || p.Request != nil || p.Result != nil {
+		return nil
+	}
+	req, res, err := loadSpilled[*mcp.GetPromptParams, *mcp.GetPromptResult](p.Spilled)
+	p.Request, p.Result = req, res
+	return err
+}
+
+func loadSpilled[P mcp.Params, R any](spilled *SpilledCall) (*mcp.ServerRequest[P], R, error) {
+	var res R
+
+	file, err := os.Open(spilled.File)
+	if err != nil {
+		return nil, res, fmt.Errorf("failed to open calls file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	data := make([]byte, spilled.Size)
+	if _, err := file.ReadAt(data, spilled.Offset); err != nil {
+		return nil, res, fmt.Errorf("failed to read calls file %s: %w", spilled.File, err)
+	}
+	return decodeCall[P, R](data)
+}
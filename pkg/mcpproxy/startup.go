@@ -0,0 +1,77 @@
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// maxCapturedStderr is the number of trailing bytes of a stdio server's
+	// stderr that are kept for error reporting
+	maxCapturedStderr = 8 * 1024
+)
+
+// probeServer checks that an initialized session is able to serve requests by
+// sending a ping and, if the server advertises tools, listing them.
+func probeServer(ctx context.Context, cs *mcp.ClientSession) error {
+	if err := cs.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+
+	if cs.InitializeResult().Capabilities.Tools != nil {
+		if _, err := cs.ListTools(ctx, &mcp.ListToolsParams{}); err != nil {
+			return fmt.Errorf("tools/list failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// stderrBuffer is an io.Writer that keeps the last limit bytes written to it.
+// It is safe for concurrent use, as the process writes to it from its own goroutine.
+type stderrBuffer struct {
+	mu    sync.Mutex
+	buf   []byte
+	limit int
+}
+
+func newStderrBuffer(limit int) *stderrBuffer {
+	return &stderrBuffer{limit: limit}
+}
+
+func (b *stderrBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.limit {
+		b.buf = b.buf[len(b.buf)-b.limit:]
+	}
+
+	return len(p), nil
+}
+
+func (b *stderrBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return strings.TrimSpace(string(b.buf))
+}
+
+// withStderr appends the captured stderr of a server process to err, if any was captured.
+func withStderr(err error, stderr *stderrBuffer) error {
+	if stderr == nil {
+		return err
+	}
+
+	output := stderr.String()
+	if output == "" {
+		return err
+	}
+
+	return fmt.Errorf("%w\nserver stderr:\n%s", err, output)
+}
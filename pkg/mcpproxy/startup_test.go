@@ -0,0 +1,116 @@
+package mcpproxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProxyClientStartupFailures(t *testing.T) {
+	tt := map[string]struct {
+		config      *ServerConfig
+		errContains []string
+	}{
+		"process exits with stderr output": {
+			config: &ServerConfig{
+				Command: "sh",
+				Args:    []string{"-c", "echo 'missing API token' >&2; exit 1"},
+			},
+			errContains: []string{"server stderr:", "missing API token"},
+		},
+		"server never initializes": {
+			config: &ServerConfig{
+				Command:        "sh",
+				Args:           []string{"-c", "echo 'still starting' >&2; cat >/dev/null"},
+				StartupTimeout: "200ms",
+			},
+			errContains: []string{"startup timeout of 200ms", "still starting"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			_, err := createProxyClient(context.Background(), "test", tc.config)
+			require.Error(t, err)
+			for _, s := range tc.errContains {
+				assert.Contains(t, err.Error(), s)
+			}
+			assert.Less(t, time.Since(start), 10*time.Second)
+		})
+	}
+}
+
+func TestCreateProxyClientEnv(t *testing.T) {
+	t.Setenv("MCP_TEST_SOURCE", "from-env")
+	t.Setenv("MCP_TEST_INHERITED", "inherited")
+
+	// The server reports the environment it sees on stderr, which is included
+	// in the startup error
+	config := &ServerConfig{
+		Command: "sh",
+		Args:    []string{"-c", `echo "token=$MCP_TEST_TOKEN level=$LOG_LEVEL inherited=$MCP_TEST_INHERITED" >&2; exit 1`},
+		Env: map[string]string{
+			"MCP_TEST_TOKEN": "${MCP_TEST_SOURCE}",
+			"LOG_LEVEL":      "debug",
+		},
+	}
+
+	_, err := createProxyClient(context.Background(), "test", config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token=from-env level=debug inherited=inherited")
+}
+
+func TestCommandEnv(t *testing.T) {
+	t.Setenv("MCP_TEST_SOURCE", "from-env")
+
+	assert.Nil(t, commandEnv(&ServerConfig{Command: "server"}), "expected servers without env to inherit the environment")
+
+	env := commandEnv(&ServerConfig{
+		Command: "server",
+		Env: map[string]string{
+			"MCP_TEST_TOKEN":  "${MCP_TEST_SOURCE}",
+			"MCP_TEST_SOURCE": "overridden",
+		},
+	})
+	assert.Contains(t, env, "MCP_TEST_SOURCE=from-env", "expected the environment to be inherited")
+	// Later entries take precedence, so the env of the config comes last
+	assert.Equal(t, []string{"MCP_TEST_SOURCE=overridden", "MCP_TEST_TOKEN=from-env"}, env[len(env)-2:])
+}
+
+func TestGetStartupTimeout(t *testing.T) {
+	tt := map[string]struct {
+		value     string
+		expected  time.Duration
+		expectErr bool
+	}{
+		"default":  {value: "", expected: DefaultStartupTimeout},
+		"custom":   {value: "90s", expected: 90 * time.Second},
+		"invalid":  {value: "soon", expectErr: true},
+		"negative": {value: "-1s", expectErr: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			cfg := &ServerConfig{StartupTimeout: tc.value}
+			timeout, err := cfg.GetStartupTimeout()
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, timeout)
+		})
+	}
+}
+
+func TestStderrBufferKeepsTail(t *testing.T) {
+	b := newStderrBuffer(5)
+	_, _ = b.Write([]byte("hello "))
+	_, _ = b.Write([]byte("world"))
+
+	assert.Equal(t, "world", b.String())
+}
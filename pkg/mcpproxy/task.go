@@ -0,0 +1,103 @@
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// taskServer is the view of a server scoped to one task. It shares the
+// upstream session and the listener of its server, but has its own proxy and
+// records its calls separately, so that tasks running concurrently on the same
+// server each see only their own call history.
+type taskServer struct {
+	parent      *server
+	task        string
+	proxyServer *mcp.Server
+	recorder    Recorder
+	chaos       *chaosMonitor
+}
+
+var _ Server = &taskServer{}
+
+// forTask creates the view of the server for a task
+func (s *server) forTask(ctx context.Context, task string) (*taskServer, error) {
+	r := NewRecorderWithLimits(s.name, s.limits)
+	chaos := newChaosMonitor(s.name, s.cfg, s.upstream, r)
+	proxy, err := createProxyServer(ctx, s.upstream, r, s.cfg, s.toolNames, chaos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proxy server for task %s: %w", task, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[task]; ok {
+		return nil, fmt.Errorf("server %s is already scoped to task %s", s.name, task)
+	}
+
+	ts := &taskServer{
+		parent:      s,
+		task:        task,
+		proxyServer: proxy,
+		recorder:    r,
+		chaos:       chaos,
+	}
+	s.tasks[task] = ts
+
+	return ts, nil
+}
+
+// Run blocks until ctx is cancelled. The view is served by the listener of
+// its server.
+func (ts *taskServer) Run(ctx context.Context) error {
+	ts.chaos.start(ctx)
+	<-ctx.Done()
+	return nil
+}
+
+func (ts *taskServer) GetConfig() (*ServerConfig, error) {
+	cfg, err := ts.parent.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.URL += "/tasks/" + url.PathEscape(ts.task)
+	return cfg, nil
+}
+
+func (ts *taskServer) GetName() string {
+	return ts.parent.GetName()
+}
+
+func (ts *taskServer) GetAllowedTools() []*mcp.Tool {
+	return ts.parent.GetAllowedTools()
+}
+
+func (ts *taskServer) AllowsAllTools() bool {
+	return ts.parent.AllowsAllTools()
+}
+
+func (ts *taskServer) GetCompatibility() Compatibility {
+	return ts.parent.GetCompatibility()
+}
+
+// Close removes the view from its server. The upstream session stays open
+// until the server itself is closed.
+func (ts *taskServer) Close() error {
+	ts.parent.mu.Lock()
+	delete(ts.parent.tasks, ts.task)
+	ts.parent.mu.Unlock()
+
+	return ts.recorder.Close()
+}
+
+func (ts *taskServer) GetCallHistory() CallHistory {
+	return ts.recorder.GetHistory()
+}
+
+func (ts *taskServer) WaitReady(ctx context.Context) error {
+	return ts.parent.WaitReady(ctx)
+}
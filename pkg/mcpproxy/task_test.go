@@ -0,0 +1,164 @@
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type echoInput struct {
+	Text string `json:"text"`
+}
+
+// startEchoManager starts a manager with one proxy server, named echo, for an
+// in-memory server with an echo tool
+func startEchoManager(t *testing.T, ctx context.Context) ServerManager {
+	t.Helper()
+
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "echo", Version: "0.0.1"}, nil)
+	mcp.AddTool(mcpServer, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, in echoInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: in.Text}}}, nil, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := mcpServer.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ss.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+
+	srv, err := newServer(ctx, "echo", &ServerConfig{Command: "echo", EnableAllTools: true}, &upstream{cs: cs}, nil)
+	require.NoError(t, err)
+
+	manager := &serverManager{servers: map[string]Server{"echo": srv}}
+	require.NoError(t, manager.Start(ctx))
+	t.Cleanup(func() { _ = manager.Close() })
+
+	return manager
+}
+
+// callEcho calls the echo tool of the first server of the manager for each text
+func callEcho(ctx context.Context, manager ServerManager, texts ...string) error {
+	cfg, err := manager.GetMcpServers()[0].GetConfig()
+	if err != nil {
+		return err
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "agent", Version: "0.0.1"}, nil)
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: cfg.URL}, nil)
+	if err != nil {
+		return err
+	}
+	defer cs.Close()
+
+	for _, text := range texts {
+		if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: echoInput{Text: text}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// echoed returns the texts of the echo calls in a history
+func echoed(history *CallHistory) []string {
+	var texts []string
+	for _, call := range history.ToolCalls {
+		texts = append(texts, call.Result.Content[0].(*mcp.TextContent).Text)
+	}
+	return texts
+}
+
+func TestServerManagerForTask(t *testing.T) {
+	ctx := context.Background()
+	manager := startEchoManager(t, ctx)
+
+	tasks := []string{"create-pod", "scale deployment"}
+	views := make([]ServerManager, len(tasks))
+	for i, task := range tasks {
+		view, err := manager.ForTask(ctx, task)
+		require.NoError(t, err)
+		require.NoError(t, view.Start(ctx))
+		views[i] = view
+	}
+
+	// The tasks call the shared server concurrently
+	var wg sync.WaitGroup
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		wg.Go(func() {
+			var texts []string
+			for n := range 5 {
+				texts = append(texts, fmt.Sprintf("%s-%d", task, n))
+			}
+			errs[i] = callEcho(ctx, views[i], texts...)
+		})
+	}
+	wg.Wait()
+
+	for i, task := range tasks {
+		require.NoError(t, errs[i])
+
+		history := views[i].GetAllCallHistory()
+		assert.Equal(t, []string{task + "-0", task + "-1", task + "-2", task + "-3", task + "-4"}, echoed(history))
+
+		serverHistory, ok := views[i].GetCallHistoryForServer("echo")
+		require.True(t, ok)
+		assert.Len(t, serverHistory.ToolCalls, 5)
+	}
+
+	// Calls made through the manager itself are not in the task views
+	require.NoError(t, callEcho(ctx, manager, "untracked"))
+	assert.Equal(t, []string{"untracked"}, echoed(manager.GetAllCallHistory()))
+	assert.Len(t, views[0].GetAllCallHistory().ToolCalls, 5)
+
+	// The views are given to agents like a manager
+	files, err := views[1].GetMcpServerFiles()
+	require.NoError(t, err)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "/mcp/tasks/scale%20deployment")
+	assert.Len(t, views[1].GetMcpServers()[0].GetAllowedTools(), 1)
+
+	for _, view := range views {
+		require.NoError(t, view.Close())
+	}
+
+	// A closed view is no longer served, and the task can be scoped again
+	assert.Error(t, callEcho(ctx, views[0], "closed"))
+	view, err := manager.ForTask(ctx, tasks[0])
+	require.NoError(t, err)
+	require.NoError(t, view.Start(ctx))
+	require.NoError(t, callEcho(ctx, view, "again"))
+	assert.Equal(t, []string{"again"}, echoed(view.GetAllCallHistory()))
+	require.NoError(t, view.Close())
+
+	// The upstream session is still open for the manager
+	require.NoError(t, callEcho(ctx, manager, "still open"))
+}
+
+func TestServerManagerForTaskInvalid(t *testing.T) {
+	ctx := context.Background()
+	manager := startEchoManager(t, ctx)
+
+	_, err := manager.ForTask(ctx, "")
+	assert.ErrorContains(t, err, "task must not be empty")
+
+	view, err := manager.ForTask(ctx, "create-pod")
+	require.NoError(t, err)
+	require.NoError(t, view.Start(ctx))
+	defer view.Close()
+
+	_, err = manager.ForTask(ctx, "create-pod")
+	assert.ErrorContains(t, err, "server echo is already scoped to task create-pod")
+
+	_, err = view.ForTask(ctx, "nested")
+	assert.ErrorContains(t, err, "server echo cannot be scoped to a task")
+}
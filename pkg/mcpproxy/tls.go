@@ -0,0 +1,113 @@
+package mcpproxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TLSConfig configures TLS for connections to an upstream HTTP MCP server.
+// File paths may contain environment variable references like ${VAR}. Relative
+// paths are resolved against the directory of the MCP config file, or of the
+// task for the servers of a task.
+type TLSConfig struct {
+	// CAFile is a PEM bundle of CA certificates used to verify the server,
+	// in addition to the system roots
+	CAFile string `json:"caFile,omitempty"`
+
+	// CertFile and KeyFile are the PEM client certificate and private key used
+	// for mutual TLS. Both must be set together
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+
+	// ServerName overrides the server name used for certificate verification
+	ServerName string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify disables server certificate verification.
+	// Only use this for testing
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// Validate checks that the TLS config is consistent.
+func (t *TLSConfig) Validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("certFile and keyFile must be set together")
+	}
+
+	return nil
+}
+
+// resolvePaths makes relative file paths absolute, relative to basePath.
+func (t *TLSConfig) resolvePaths(basePath string) {
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "${") {
+			return path
+		}
+		return filepath.Join(basePath, path)
+	}
+
+	t.CAFile = resolve(t.CAFile)
+	t.CertFile = resolve(t.CertFile)
+	t.KeyFile = resolve(t.KeyFile)
+}
+
+// build creates a crypto/tls config from the TLS options.
+func (t *TLSConfig) build() (*tls.Config, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" {
+		caFile := expandEnv(t.CAFile)
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read caFile '%s': %w", caFile, err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in caFile '%s'", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(expandEnv(t.CertFile), expandEnv(t.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// newTLSTransport returns an http.RoundTripper using the given TLS options.
+// If t is nil, http.DefaultTransport is returned.
+func newTLSTransport(t *TLSConfig) (http.RoundTripper, error) {
+	if t == nil {
+		return http.DefaultTransport, nil
+	}
+
+	tlsConfig, err := t.build()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
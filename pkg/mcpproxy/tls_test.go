@@ -0,0 +1,99 @@
+package mcpproxy
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0644))
+
+	tt := map[string]struct {
+		tls       *TLSConfig
+		expectErr bool
+	}{
+		"system roots reject self-signed server": {
+			tls:       nil,
+			expectErr: true,
+		},
+		"custom ca bundle": {
+			tls: &TLSConfig{CAFile: caFile},
+		},
+		"insecure skip verify": {
+			tls: &TLSConfig{InsecureSkipVerify: true},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			transport, err := newTLSTransport(tc.tls)
+			require.NoError(t, err)
+
+			resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+		})
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	emptyFile := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(emptyFile, []byte("not a certificate"), 0644))
+
+	tt := map[string]*TLSConfig{
+		"cert without key":   {CertFile: "client.pem"},
+		"key without cert":   {KeyFile: "client-key.pem"},
+		"missing ca file":    {CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"ca file has no pem": {CAFile: emptyFile},
+		"missing client cert": {
+			CertFile: filepath.Join(t.TempDir(), "client.pem"),
+			KeyFile:  filepath.Join(t.TempDir(), "client-key.pem"),
+		},
+	}
+
+	for name, cfg := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := newTLSTransport(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseConfigFileResolvesTLSPaths(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "mcp.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+mcpServers:
+  secure:
+    url: https://mcp.internal.example.com/mcp
+    tls:
+      caFile: certs/ca.pem
+      certFile: /etc/mcp/client.pem
+      keyFile: ${MCP_CLIENT_KEY}
+`), 0644))
+
+	cfg, err := ParseConfigFile(configFile)
+	require.NoError(t, err)
+
+	tls := cfg.MCPServers["secure"].TLS
+	require.NotNil(t, tls)
+	assert.Equal(t, filepath.Join(dir, "certs/ca.pem"), tls.CAFile)
+	assert.Equal(t, "/etc/mcp/client.pem", tls.CertFile)
+	assert.Equal(t, "${MCP_CLIENT_KEY}", tls.KeyFile)
+}
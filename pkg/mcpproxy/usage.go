@@ -0,0 +1,88 @@
+package mcpproxy
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolUsage sums the calls of a task to one tool of a server, to find the
+// tools whose payloads take up most of the context window of the agent
+type ToolUsage struct {
+	Server string `json:"server"`
+	Tool   string `json:"tool"`
+	Calls  int    `json:"calls"`
+	Errors int    `json:"errors,omitempty"`
+	// RequestBytes and ResponseBytes are the total sizes of the JSON
+	// arguments and results of the calls
+	RequestBytes     int64 `json:"requestBytes"`
+	ResponseBytes    int64 `json:"responseBytes"`
+	MaxResponseBytes int64 `json:"maxResponseBytes"`
+	// CallsPerMinute is the rate of calls over the time the agent ran, if it
+	// is known
+	CallsPerMinute float64 `json:"callsPerMinute,omitempty"`
+	// Tokens is the estimated number of tokens of the results, which is
+	// counted by the eval runner with its tokenizer
+	Tokens int `json:"tokens,omitempty"`
+}
+
+// callSizes returns the sizes of the JSON arguments and result of a call
+func callSizes(req *mcp.CallToolRequest, res *mcp.CallToolResult) (int64, int64) {
+	var requestBytes, responseBytes int64
+	if req != nil && req.Params != nil {
+		requestBytes = int64(len(req.Params.Arguments))
+	}
+	if res != nil {
+		if data, err := json.Marshal(res); err == nil {
+			responseBytes = int64(len(data))
+		}
+	}
+	return requestBytes, responseBytes
+}
+
+// SummarizeToolUsage sums the tool calls of a history by server and tool.
+// Rates are computed over elapsed, and left out if it is zero. The tools are
+// ordered by the bytes they returned, largest first.
+func SummarizeToolUsage(history *CallHistory, elapsed time.Duration) []ToolUsage {
+	if history == nil || len(history.ToolCalls) == 0 {
+		return nil
+	}
+
+	type key struct{ server, tool string }
+	byTool := map[key]*ToolUsage{}
+	for _, call := range history.ToolCalls {
+		k := key{call.ServerName, call.ToolName}
+		usage, ok := byTool[k]
+		if !ok {
+			usage = &ToolUsage{Server: call.ServerName, Tool: call.ToolName}
+			byTool[k] = usage
+		}
+
+		usage.Calls++
+		if !call.Success || (call.Result != nil && call.Result.IsError) {
+			usage.Errors++
+		}
+		usage.RequestBytes += call.RequestBytes
+		usage.ResponseBytes += call.ResponseBytes
+		usage.MaxResponseBytes = max(usage.MaxResponseBytes, call.ResponseBytes)
+	}
+
+	summary := make([]ToolUsage, 0, len(byTool))
+	for _, usage := range byTool {
+		if elapsed > 0 {
+			usage.CallsPerMinute = float64(usage.Calls) / elapsed.Minutes()
+		}
+		summary = append(summary, *usage)
+	}
+	slices.SortFunc(summary, func(a, b ToolUsage) int {
+		return cmp.Or(
+			cmp.Compare(b.ResponseBytes, a.ResponseBytes),
+			cmp.Compare(a.Server, b.Server),
+			cmp.Compare(a.Tool, b.Tool),
+		)
+	})
+	return summary
+}
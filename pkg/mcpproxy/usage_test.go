@@ -0,0 +1,79 @@
+package mcpproxy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderCallSizes(t *testing.T) {
+	// A limit of one call in memory spills the first call
+	r := NewRecorderWithLimits("k8s", &CallHistoryLimits{MaxCallsInMemory: 1})
+	defer r.Close()
+
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("x", 100)}}}
+	resultJSON, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	for range 2 {
+		r.RecordToolCall(&mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "pods_list", Arguments: json.RawMessage(`{"namespace":"default"}`)},
+		}, result, nil, time.Now())
+	}
+
+	history := r.GetHistory()
+	require.Len(t, history.ToolCalls, 2)
+	for _, call := range history.ToolCalls {
+		assert.Equal(t, int64(len(`{"namespace":"default"}`)), call.RequestBytes)
+		assert.Equal(t, int64(len(resultJSON)), call.ResponseBytes)
+	}
+}
+
+func TestSummarizeToolUsage(t *testing.T) {
+	call := func(server, tool string, requestBytes, responseBytes int64, success bool) *ToolCall {
+		return &ToolCall{
+			CallRecord:    CallRecord{ServerName: server, Success: success},
+			ToolName:      tool,
+			RequestBytes:  requestBytes,
+			ResponseBytes: responseBytes,
+		}
+	}
+
+	history := &CallHistory{ToolCalls: []*ToolCall{
+		call("k8s", "pods_get", 10, 100, true),
+		call("k8s", "pods_list", 5, 5000, true),
+		call("k8s", "pods_get", 20, 300, false),
+		call("github", "search", 30, 100, true),
+		{
+			CallRecord: CallRecord{ServerName: "github", Success: true},
+			ToolName:   "search",
+			Result:     &mcp.CallToolResult{IsError: true},
+		},
+	}}
+
+	usage := SummarizeToolUsage(history, 2*time.Minute)
+	assert.Equal(t, []ToolUsage{
+		{Server: "k8s", Tool: "pods_list", Calls: 1, RequestBytes: 5, ResponseBytes: 5000, MaxResponseBytes: 5000, CallsPerMinute: 0.5},
+		{Server: "k8s", Tool: "pods_get", Calls: 2, Errors: 1, RequestBytes: 30, ResponseBytes: 400, MaxResponseBytes: 300, CallsPerMinute: 1},
+		{Server: "github", Tool: "search", Calls: 2, Errors: 1, RequestBytes: 30, ResponseBytes: 100, MaxResponseBytes: 100, CallsPerMinute: 1},
+	}, usage)
+
+	// Without the elapsed time there are no rates
+	for _, u := range SummarizeToolUsage(history, 0) {
+		assert.Zero(t, u.CallsPerMinute)
+	}
+
+	assert.Nil(t, SummarizeToolUsage(&CallHistory{}, time.Minute))
+	assert.Nil(t, SummarizeToolUsage(nil, time.Minute))
+}
+
+func TestCallSizesWithoutResult(t *testing.T) {
+	requestBytes, responseBytes := callSizes(&mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "pods_get"}}, nil)
+	assert.Zero(t, requestBytes)
+	assert.Zero(t, responseBytes)
+}
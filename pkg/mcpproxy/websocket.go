@@ -0,0 +1,101 @@
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// webSocketSubprotocol is the subprotocol requested during the handshake
+	webSocketSubprotocol = "mcp"
+
+	// maxWebSocketMessageSize is the largest message accepted from a server.
+	// The websocket library defaults to 32KiB, which is too small for many tool results
+	maxWebSocketMessageSize = 32 * 1024 * 1024
+)
+
+// WebSocketClientTransport is an mcp.Transport that connects to an MCP server
+// over a WebSocket, exchanging one JSON-RPC message per text frame.
+type WebSocketClientTransport struct {
+	// Endpoint is the ws:// or wss:// URL of the server
+	Endpoint string
+	// HTTPClient is used for the opening handshake. If nil, http.DefaultClient is used
+	HTTPClient *http.Client
+}
+
+var _ mcp.Transport = &WebSocketClientTransport{}
+
+// Connect implements mcp.Transport.
+func (t *WebSocketClientTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, _, err := websocket.Dial(ctx, t.Endpoint, &websocket.DialOptions{
+		HTTPClient:   t.HTTPClient,
+		Subprotocols: []string{webSocketSubprotocol},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to websocket %s: %w", t.Endpoint, err)
+	}
+
+	return newWebSocketConn(conn), nil
+}
+
+// webSocketConn adapts a websocket connection to mcp.Connection.
+type webSocketConn struct {
+	conn *websocket.Conn
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+var _ mcp.Connection = &webSocketConn{}
+
+func newWebSocketConn(conn *websocket.Conn) *webSocketConn {
+	conn.SetReadLimit(maxWebSocketMessageSize)
+	return &webSocketConn{conn: conn}
+}
+
+func (c *webSocketConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	typ, data, err := c.conn.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if typ != websocket.MessageText {
+		return nil, fmt.Errorf("unexpected binary websocket message")
+	}
+
+	return jsonrpc.DecodeMessage(data)
+}
+
+func (c *webSocketConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	// The websocket library closes the connection if a write is cancelled, so
+	// a single cancelled request must not be able to tear down the session
+	return c.conn.Write(context.WithoutCancel(ctx), websocket.MessageText, data)
+}
+
+func (c *webSocketConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.closeErr = c.conn.Close(websocket.StatusNormalClosure, "")
+	})
+	return c.closeErr
+}
+
+func (c *webSocketConn) SessionID() string {
+	return ""
+}
+
+// isWebSocketURL reports whether rawURL uses the ws or wss scheme.
+func isWebSocketURL(rawURL string) bool {
+	lower := strings.ToLower(rawURL)
+	return strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://")
+}
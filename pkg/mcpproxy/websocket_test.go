@@ -0,0 +1,108 @@
+package mcpproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webSocketServerTransport serves an MCP session over an accepted websocket
+type webSocketServerTransport struct {
+	conn *webSocketConn
+}
+
+func (t *webSocketServerTransport) Connect(context.Context) (mcp.Connection, error) {
+	return t.conn, nil
+}
+
+func newWebSocketTestServer(t *testing.T, gotHeaders chan<- http.Header) *httptest.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: "ws-server", Version: "0.0.1"}, nil)
+	mcp.AddTool(srv, &mcp.Tool{Name: "echo", Description: "Echo the input"}, func(ctx context.Context, req *mcp.CallToolRequest, in struct {
+		Text string `json:"text"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: in.Text}}}, nil, nil
+	})
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders <- r.Header.Clone()
+
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+			Subprotocols: []string{webSocketSubprotocol},
+		})
+		if err != nil {
+			t.Errorf("failed to accept websocket: %v", err)
+			return
+		}
+
+		ss, err := srv.Connect(r.Context(), &webSocketServerTransport{conn: newWebSocketConn(conn)}, nil)
+		if err != nil {
+			t.Errorf("failed to connect server session: %v", err)
+			return
+		}
+		_ = ss.Wait()
+	}))
+}
+
+func TestWebSocketProxyClient(t *testing.T) {
+	t.Setenv("MCP_TEST_WS_TOKEN", "secret")
+
+	gotHeaders := make(chan http.Header, 1)
+	srv := newWebSocketTestServer(t, gotHeaders)
+	defer srv.Close()
+
+	cfg := &ServerConfig{
+		URL:     "ws" + strings.TrimPrefix(srv.URL, "http"),
+		Headers: map[string]string{"X-Test": "yes"},
+		Auth:    &AuthConfig{Type: AuthTypeBearer, Token: "${MCP_TEST_WS_TOKEN}"},
+	}
+	require.True(t, cfg.IsWebSocket())
+	require.False(t, cfg.IsHttp())
+	require.NoError(t, cfg.Validate())
+
+	cs, err := createProxyClient(context.Background(), "test", cfg)
+	require.NoError(t, err)
+	defer cs.Close()
+
+	headers := <-gotHeaders
+	assert.Equal(t, "Bearer secret", headers.Get("Authorization"))
+	assert.Equal(t, "yes", headers.Get("X-Test"))
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "echo",
+		Arguments: map[string]any{"text": "hello over websocket"},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Content, 1)
+	assert.Equal(t, "hello over websocket", res.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestServerConfigTransportInference(t *testing.T) {
+	tt := map[string]struct {
+		cfg       ServerConfig
+		stdio     bool
+		http      bool
+		websocket bool
+	}{
+		"command":          {cfg: ServerConfig{Command: "server"}, stdio: true},
+		"http url":         {cfg: ServerConfig{URL: "https://example.com/mcp"}, http: true},
+		"ws url":           {cfg: ServerConfig{URL: "ws://localhost:9000/mcp"}, websocket: true},
+		"wss url":          {cfg: ServerConfig{URL: "WSS://example.com/mcp"}, websocket: true},
+		"explicit type":    {cfg: ServerConfig{Type: TransportTypeWebSocket, URL: "${MCP_WS_URL}"}, websocket: true},
+		"explicit http ws": {cfg: ServerConfig{Type: TransportTypeHttp, URL: "ws://localhost/mcp"}, http: true},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.stdio, tc.cfg.IsStdio(), "IsStdio")
+			assert.Equal(t, tc.http, tc.cfg.IsHttp(), "IsHttp")
+			assert.Equal(t, tc.websocket, tc.cfg.IsWebSocket(), "IsWebSocket")
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Package ollamaagent is an agent that calls the chat API of Ollama directly,
+// with the tools of MCP servers.
+package ollamaagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/openaiagent"
+)
+
+// DefaultBaseURL is the URL Ollama serves on by default
+const DefaultBaseURL = "http://localhost:11434"
+
+type aiAgent struct {
+	*openaiagent.Toolbox
+	httpClient   *http.Client
+	baseURL      string
+	model        string
+	systemPrompt string
+}
+
+func NewAIAgent(url, model, systemPrompt string) (*aiAgent, error) {
+	if model == "" {
+		return nil, fmt.Errorf("model name must be provided to create an ollama agent")
+	}
+	if url == "" {
+		url = DefaultBaseURL
+	}
+
+	return &aiAgent{
+		Toolbox:      &openaiagent.Toolbox{},
+		httpClient:   http.DefaultClient,
+		baseURL:      strings.TrimSuffix(url, "/"),
+		model:        model,
+		systemPrompt: systemPrompt,
+	}, nil
+}
+
+// BaseURL returns the URL of an Ollama server given as in OLLAMA_HOST, which
+// may leave out the scheme, or DefaultBaseURL if host is empty
+func BaseURL(host string) string {
+	if host == "" {
+		return DefaultBaseURL
+	}
+	if !strings.Contains(host, "://") {
+		return "http://" + host
+	}
+	return host
+}
+
+type chatRequest struct {
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+	Tools    []tool    `json:"tools,omitempty"`
+	Stream   bool      `json:"stream"`
+}
+
+type message struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+	// ToolName is the tool that returned the content of a tool message
+	ToolName string `json:"tool_name,omitempty"`
+}
+
+type toolCall struct {
+	Function struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	} `json:"function"`
+}
+
+type tool struct {
+	Type     string       `json:"type"`
+	Function toolFunction `json:"function"`
+}
+
+type toolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+type chatResponse struct {
+	Message message `json:"message"`
+}
+
+func (o *aiAgent) Run(ctx context.Context, prompt string) (string, error) {
+	// Start conversation with system prompt (if provided) and user's prompt
+	var messages []message
+
+	if o.systemPrompt != "" {
+		messages = append(messages, message{Role: "system", Content: o.systemPrompt})
+	}
+
+	messages = append(messages, message{Role: "user", Content: prompt})
+
+	// Get available tools from all MCP clients
+	var tools []tool
+	for _, t := range o.Tools() {
+		tools = append(tools, tool{
+			Type: "function",
+			Function: toolFunction{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  openaiagent.ToolInputSchema(t),
+			},
+		})
+	}
+
+	// Agent loop - continue until we get a final response without tool calls
+	for iteration := 0; ; iteration++ {
+		if err := o.CheckIteration(iteration); err != nil {
+			return "", err
+		}
+
+		response, err := o.chat(ctx, chatRequest{
+			Model:    o.model,
+			Messages: messages,
+			Tools:    tools,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create chat completion: %w", err)
+		}
+
+		reply := response.Message
+		messages = append(messages, reply)
+
+		// If there are no tool calls, we're done
+		if len(reply.ToolCalls) == 0 {
+			o.Emit(openaiagent.Event{Type: openaiagent.EventMessage, Text: reply.Content, Final: true})
+			return reply.Content, nil
+		}
+
+		if reply.Content != "" {
+			o.Emit(openaiagent.Event{Type: openaiagent.EventMessage, Text: reply.Content})
+		}
+
+		// Ollama does not give tool calls IDs, so they are numbered to tell
+		// apart the events of calls executed in parallel
+		var calls []openaiagent.ToolCallRequest
+		for i, toolCall := range reply.ToolCalls {
+			if toolCall.Function.Name == "" {
+				continue
+			}
+			calls = append(calls, openaiagent.ToolCallRequest{
+				ID:        fmt.Sprintf("call_%d_%d", iteration, i),
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			})
+		}
+
+		// Execute tool calls and add results to conversation, in the order
+		// the model requested them
+		for i, result := range o.ExecuteToolCalls(ctx, calls) {
+			messages = append(messages, message{Role: "tool", Content: result, ToolName: calls[i].Name})
+		}
+	}
+}
+
+// CheckHealth checks that Ollama is reachable and has the model, so that it
+// does not need to be pulled
+func (o *aiAgent) CheckHealth(ctx context.Context) error {
+	status, err := o.post(ctx, "/api/show", map[string]string{"model": o.model}, nil)
+	switch {
+	case status == http.StatusNotFound:
+		return fmt.Errorf("model %s not found, pull it with `ollama pull %s`", o.model, o.model)
+	case err != nil && status == 0:
+		return fmt.Errorf("failed to reach Ollama at %s: %w", o.baseURL, err)
+	}
+	return err
+}
+
+// chat sends a request to the chat API, without streaming the response
+func (o *aiAgent) chat(ctx context.Context, request chatRequest) (*chatResponse, error) {
+	var response chatResponse
+	if _, err := o.post(ctx, "/api/chat", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// post sends a request to the API and decodes the response into response,
+// unless it is nil. The status is zero if no response was received.
+func (o *aiAgent) post(ctx context.Context, path string, request, response any) (int, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Ollama reports errors as {"error": "..."}, with or without an error status
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Error != "" {
+		return resp.StatusCode, fmt.Errorf("%s (status %d)", apiErr.Error, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if response != nil {
+		if err := json.Unmarshal(data, response); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,194 @@
+package ollamaagent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startMCPServer starts an MCP server with an echo tool that returns its text
+// argument
+func startMCPServer(t *testing.T) string {
+	t.Helper()
+
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(s, &mcp.Tool{Name: "echo", Description: "Echoes text"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Text string `json:"text"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: args.Text}}}, nil, nil
+	})
+
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s }, nil))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// startModel starts a chat API server that first calls the echo tool, then
+// answers with the content of the tool message. It records the requests it got.
+func startModel(t *testing.T) (string, *[]map[string]any) {
+	t.Helper()
+
+	var requests []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+
+		var request map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+
+		message := map[string]any{
+			"role":    "assistant",
+			"content": "",
+			"tool_calls": []map[string]any{
+				{"function": map[string]any{"name": "echo", "arguments": map[string]any{"text": "hello"}}},
+			},
+		}
+		if len(requests) > 1 {
+			messages := request["messages"].([]any)
+			last := messages[len(messages)-1].(map[string]any)
+			message = map[string]any{"role": "assistant", "content": "echoed " + last["content"].(string)}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"model":   request["model"],
+			"message": message,
+			"done":    true,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &requests
+}
+
+func TestRunToolCall(t *testing.T) {
+	modelURL, requests := startModel(t)
+	mcpURL := startMCPServer(t)
+
+	agent, err := NewAIAgent(modelURL, "qwen3", "Be brief.")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = agent.Close() })
+	require.NoError(t, agent.AddMCPServer(context.Background(), mcpURL))
+
+	out, err := agent.Run(context.Background(), "echo hello")
+	require.NoError(t, err)
+	assert.Contains(t, out, "echoed")
+	assert.Contains(t, out, "hello")
+
+	require.Len(t, *requests, 2)
+	first := (*requests)[0]
+	assert.Equal(t, "qwen3", first["model"])
+	assert.Equal(t, false, first["stream"])
+
+	// The tool is translated to the chat API format
+	tools := first["tools"].([]any)
+	require.Len(t, tools, 1)
+	tool := tools[0].(map[string]any)
+	assert.Equal(t, "function", tool["type"])
+	function := tool["function"].(map[string]any)
+	assert.Equal(t, "echo", function["name"])
+	assert.Equal(t, "Echoes text", function["description"])
+	assert.Contains(t, function["parameters"].(map[string]any)["properties"], "text")
+
+	// The system prompt comes first, and the tool result follows the tool call
+	messages := (*requests)[1]["messages"].([]any)
+	require.Len(t, messages, 4)
+	assert.Equal(t, "system", messages[0].(map[string]any)["role"])
+	result := messages[3].(map[string]any)
+	assert.Equal(t, "tool", result["role"])
+	assert.Equal(t, "echo", result["tool_name"])
+}
+
+func TestRunAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"model \"missing\" not found, try pulling it first"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	agent, err := NewAIAgent(srv.URL, "missing", "")
+	require.NoError(t, err)
+
+	_, err = agent.Run(context.Background(), "hello")
+	assert.ErrorContains(t, err, `model "missing" not found, try pulling it first (status 404)`)
+}
+
+func TestBaseURL(t *testing.T) {
+	tests := map[string]struct {
+		host     string
+		expected string
+	}{
+		"empty host": {
+			host:     "",
+			expected: DefaultBaseURL,
+		},
+		"host and port": {
+			host:     "0.0.0.0:11434",
+			expected: "http://0.0.0.0:11434",
+		},
+		"url": {
+			host:     "https://ollama.example.com",
+			expected: "https://ollama.example.com",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, BaseURL(tc.host))
+		})
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := map[string]struct {
+		status      int
+		body        string
+		errContains string
+	}{
+		"model is pulled": {
+			status: http.StatusOK,
+			body:   `{"details":{"family":"qwen3"}}`,
+		},
+		"model is missing": {
+			status:      http.StatusNotFound,
+			body:        `{"error":"model 'qwen3' not found"}`,
+			errContains: "model qwen3 not found, pull it with `ollama pull qwen3`",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/show", r.URL.Path)
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			agent, err := NewAIAgent(srv.URL, "qwen3", "")
+			require.NoError(t, err)
+
+			err = agent.CheckHealth(context.Background())
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errContains)
+			}
+		})
+	}
+
+	t.Run("server is not running", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		url := srv.URL
+		srv.Close()
+
+		agent, err := NewAIAgent(url, "qwen3", "")
+		require.NoError(t, err)
+		assert.ErrorContains(t, agent.CheckHealth(context.Background()), "failed to reach Ollama at "+url)
+	})
+}
@@ -3,8 +3,11 @@ package openaiagent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
 	"github.com/openai/openai-go/v2/shared"
@@ -15,8 +18,8 @@ type Agent interface {
 }
 
 type aiAgent struct {
+	*Toolbox
 	client       *openai.Client
-	mcpClients   []*McpClient
 	model        shared.ChatModel
 	systemPrompt string
 }
@@ -32,31 +35,40 @@ func NewAIAgent(url, apiKey, model, systemPrompt string) (*aiAgent, error) {
 	)
 
 	return &aiAgent{
+		Toolbox:      &Toolbox{},
 		client:       &client,
-		mcpClients:   make([]*McpClient, 0),
 		model:        shared.ChatModel(model),
 		systemPrompt: systemPrompt,
 	}, nil
 }
 
-// AddMCPServer adds an MCP server to the agent
-func (o *aiAgent) AddMCPServer(ctx context.Context, serverURL string) error {
-	mcpClient, err := NewMcpClient(ctx, serverURL)
-	if err != nil {
-		return fmt.Errorf("failed to create MCP client for %s: %w", serverURL, err)
-	}
+// CheckHealth checks that the API is reachable and accepts the API key, by
+// listing the models
+func (o *aiAgent) CheckHealth(ctx context.Context) error {
+	_, err := o.client.Models.List(ctx)
 
-	// Load available tools from the MCP server
-	if err := mcpClient.LoadTools(ctx); err != nil {
-		mcpClient.Close()
-		return fmt.Errorf("failed to load MCP tools from %s: %w", serverURL, err)
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("API key was rejected: %w", err)
+		}
+		// Other errors, such as a gateway that does not list models, do not
+		// tell that tasks would fail
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reach model API: %w", err)
 	}
-
-	o.mcpClients = append(o.mcpClients, mcpClient)
 	return nil
 }
 
 func (o *aiAgent) Run(ctx context.Context, prompt string) (string, error) {
+	return o.RunWithImages(ctx, prompt, nil)
+}
+
+// RunWithImages runs the agent like Run, with images sent after the prompt in
+// the user message
+func (o *aiAgent) RunWithImages(ctx context.Context, prompt string, images []util.Image) (string, error) {
 	// Start conversation with system prompt (if provided) and user's prompt
 	var messages []openai.ChatCompletionMessageParamUnion
 
@@ -64,17 +76,30 @@ func (o *aiAgent) Run(ctx context.Context, prompt string) (string, error) {
 		messages = append(messages, openai.SystemMessage(o.systemPrompt))
 	}
 
-	messages = append(messages, openai.UserMessage(prompt))
+	if len(images) == 0 {
+		messages = append(messages, openai.UserMessage(prompt))
+	} else {
+		parts := []openai.ChatCompletionContentPartUnionParam{openai.TextContentPart(prompt)}
+		for _, image := range images {
+			parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+				URL: image.DataURL(),
+			}))
+		}
+		messages = append(messages, openai.UserMessage(parts))
+	}
 
 	// Get available tools from all MCP clients
 	var tools []openai.ChatCompletionToolUnionParam
-	for _, mcpClient := range o.mcpClients {
-		clientTools := mcpClient.GetTools()
-		tools = append(tools, clientTools...)
+	for _, tool := range o.Tools() {
+		tools = append(tools, convertMCPToolToOpenAI(tool))
 	}
 
 	// Agent loop - continue until we get a final response without tool calls
-	for {
+	for iteration := 0; ; iteration++ {
+		if err := o.CheckIteration(iteration); err != nil {
+			return "", err
+		}
+
 		params := openai.ChatCompletionNewParams{
 			Model:    o.model,
 			Messages: messages,
@@ -104,10 +129,16 @@ func (o *aiAgent) Run(ctx context.Context, prompt string) (string, error) {
 
 		// If there are no tool calls, we're done
 		if len(message.ToolCalls) == 0 {
+			o.Emit(Event{Type: EventMessage, Text: message.Content, Final: true})
 			return message.Content, nil
 		}
 
-		// Execute tool calls and add results to conversation
+		if message.Content != "" {
+			o.Emit(Event{Type: EventMessage, Text: message.Content})
+		}
+
+		// Parse the arguments of all tool calls before executing any of them
+		var calls []ToolCallRequest
 		for _, toolCall := range message.ToolCalls {
 			if toolCall.Function.Name == "" {
 				continue
@@ -119,47 +150,13 @@ func (o *aiAgent) Run(ctx context.Context, prompt string) (string, error) {
 				return "", fmt.Errorf("failed to parse tool arguments: %w", err)
 			}
 
-			// Find which MCP client has this tool and execute it
-			result, err := o.callToolOnAnyClient(ctx, toolCall.Function.Name, args)
-			if err != nil {
-				result = fmt.Sprintf("Error calling tool: %v", err)
-			}
-
-			// Add tool result to conversation
-			messages = append(messages, openai.ToolMessage(result, toolCall.ID))
-		}
-	}
-}
-
-// callToolOnAnyClient finds the MCP client that has the specified tool and calls it
-func (o *aiAgent) callToolOnAnyClient(ctx context.Context, toolName string, arguments map[string]any) (string, error) {
-	// Search through all MCP clients to find one that has this tool
-	for _, mcpClient := range o.mcpClients {
-		tools := mcpClient.GetTools()
-		for _, tool := range tools {
-			// Check if this is a function tool with the matching name
-			if funcDef := tool.GetFunction(); funcDef != nil && funcDef.Name == toolName {
-				// Found the tool, call it on this client
-				return mcpClient.CallTool(ctx, toolName, arguments)
-			}
+			calls = append(calls, ToolCallRequest{ID: toolCall.ID, Name: toolCall.Function.Name, Arguments: args})
 		}
-	}
-
-	return "", fmt.Errorf("tool %s not found in any MCP client", toolName)
-}
 
-// Close closes the agent and any associated resources
-func (o *aiAgent) Close() error {
-	var errs []error
-	for _, mcpClient := range o.mcpClients {
-		if err := mcpClient.Close(); err != nil {
-			errs = append(errs, err)
+		// Execute tool calls and add results to conversation, in the order
+		// the model requested them
+		for i, result := range o.ExecuteToolCalls(ctx, calls) {
+			messages = append(messages, openai.ToolMessage(result, calls[i].ID))
 		}
 	}
-
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to close %d MCP clients: %v", len(errs), errs)
-	}
-
-	return nil
 }
@@ -0,0 +1,260 @@
+package openaiagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startMCPServer starts an MCP server with a sleep tool that waits for the
+// given number of milliseconds
+func startMCPServer(t *testing.T) string {
+	t.Helper()
+
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(s, &mcp.Tool{Name: "sleep"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Ms int `json:"ms"`
+	}) (*mcp.CallToolResult, any, error) {
+		select {
+		case <-time.After(time.Duration(args.Ms) * time.Millisecond):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s }, nil))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// startModel starts a chat completions server whose first responses call the
+// sleep tool with the given durations in milliseconds, one response per
+// element, before it answers "finished"
+func startModel(t *testing.T, turns ...[]int) (string, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1)) - 1
+
+		message := map[string]any{"role": "assistant", "content": "finished"}
+		finishReason := "stop"
+		if n < len(turns) {
+			var calls []map[string]any
+			for i, ms := range turns[n] {
+				calls = append(calls, map[string]any{
+					"id":       fmt.Sprintf("call_%d_%d", n, i),
+					"type":     "function",
+					"function": map[string]any{"name": "sleep", "arguments": fmt.Sprintf(`{"ms":%d}`, ms)},
+				})
+			}
+			message = map[string]any{"role": "assistant", "content": "", "tool_calls": calls}
+			finishReason = "tool_calls"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion",
+			"created": 0,
+			"model":   "test",
+			"choices": []map[string]any{{"index": 0, "message": message, "finish_reason": finishReason}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &requests
+}
+
+func newTestAgent(t *testing.T, modelURL string, options Options) *aiAgent {
+	t.Helper()
+
+	// Started first so that it is stopped after the agent closed its session
+	mcpURL := startMCPServer(t)
+
+	agent, err := NewAIAgent(modelURL, "key", "test", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = agent.Close() })
+	require.NoError(t, agent.AddMCPServer(context.Background(), mcpURL))
+	agent.SetOptions(options)
+	return agent
+}
+
+func TestRunMaxIterations(t *testing.T) {
+	tests := map[string]struct {
+		turns         int
+		maxIterations int
+		expectErr     bool
+		expectCalls   int32
+	}{
+		"stops at the limit": {
+			turns:         5,
+			maxIterations: 3,
+			expectErr:     true,
+			expectCalls:   3,
+		},
+		"finishes within the limit": {
+			turns:         2,
+			maxIterations: 3,
+			expectCalls:   3,
+		},
+		"negative limit is unlimited": {
+			turns:         DefaultMaxIterations,
+			maxIterations: -1,
+			expectCalls:   DefaultMaxIterations + 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			turns := make([][]int, tc.turns)
+			for i := range turns {
+				turns[i] = []int{0}
+			}
+			modelURL, requests := startModel(t, turns...)
+			agent := newTestAgent(t, modelURL, Options{MaxIterations: tc.maxIterations})
+
+			out, err := agent.Run(context.Background(), "loop")
+			if tc.expectErr {
+				assert.ErrorIs(t, err, ErrMaxIterations)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "finished", out)
+			}
+			assert.Equal(t, tc.expectCalls, requests.Load())
+		})
+	}
+}
+
+func TestRunParallelToolCalls(t *testing.T) {
+	modelURL, _ := startModel(t, []int{300, 300, 300})
+	agent := newTestAgent(t, modelURL, Options{ParallelToolCalls: true})
+
+	var mu sync.Mutex
+	var completed []string
+	agent.OnEvent(func(e Event) {
+		if e.Type == EventToolCallCompleted {
+			mu.Lock()
+			completed = append(completed, e.CallID)
+			mu.Unlock()
+		}
+	})
+
+	start := time.Now()
+	out, err := agent.Run(context.Background(), "sleep")
+	require.NoError(t, err)
+	assert.Equal(t, "finished", out)
+	assert.Less(t, time.Since(start), 800*time.Millisecond, "tool calls should run concurrently")
+	assert.ElementsMatch(t, []string{"call_0_0", "call_0_1", "call_0_2"}, completed)
+}
+
+func TestRunToolCallTimeout(t *testing.T) {
+	modelURL, _ := startModel(t, []int{5000})
+	agent := newTestAgent(t, modelURL, Options{ToolCallTimeout: 100 * time.Millisecond})
+
+	var callErr error
+	agent.OnEvent(func(e Event) {
+		if e.Type == EventToolCallCompleted {
+			callErr = e.Error
+		}
+	})
+
+	start := time.Now()
+	out, err := agent.Run(context.Background(), "sleep")
+	require.NoError(t, err)
+	assert.Equal(t, "finished", out)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.ErrorContains(t, callErr, "timed out after 100ms")
+}
+
+func TestRunWithImages(t *testing.T) {
+	var body struct {
+		Messages []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion",
+			"created": 0,
+			"model":   "test",
+			"choices": []map[string]any{{"index": 0, "message": map[string]any{"role": "assistant", "content": "a button"}, "finish_reason": "stop"}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	agent, err := NewAIAgent(srv.URL, "key", "test", "")
+	require.NoError(t, err)
+
+	out, err := agent.RunWithImages(context.Background(), "What is on the screen?", []util.Image{
+		{Name: "screen.png", MediaType: "image/png", Data: []byte("png")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a button", out)
+
+	require.Len(t, body.Messages, 1)
+	assert.Equal(t, "user", body.Messages[0].Role)
+	assert.JSONEq(t, `[
+		{"type": "text", "text": "What is on the screen?"},
+		{"type": "image_url", "image_url": {"url": "data:image/png;base64,cG5n"}}
+	]`, string(body.Messages[0].Content))
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := map[string]struct {
+		status      int
+		body        string
+		errContains string
+	}{
+		"valid key": {
+			status: http.StatusOK,
+			body:   `{"object":"list","data":[{"id":"test","object":"model"}]}`,
+		},
+		"rejected key": {
+			status:      http.StatusUnauthorized,
+			body:        `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error"}}`,
+			errContains: "API key was rejected",
+		},
+		"models not listed by gateway": {
+			status: http.StatusNotFound,
+			body:   `{"error":{"message":"not found"}}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/models", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			agent, err := NewAIAgent(srv.URL, "key", "test", "")
+			require.NoError(t, err)
+
+			err = agent.CheckHealth(context.Background())
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.errContains)
+			}
+		})
+	}
+}
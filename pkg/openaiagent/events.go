@@ -0,0 +1,43 @@
+package openaiagent
+
+import (
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// EventType is the kind of step of a run an Event reports
+type EventType string
+
+const (
+	// EventMessage is text returned by the model, either alongside tool calls
+	// or as the final answer
+	EventMessage EventType = "message"
+	// EventToolCallStarted is reported before a tool is called
+	EventToolCallStarted EventType = "tool_call_started"
+	// EventToolCallCompleted is reported when a tool call returns or fails
+	EventToolCallCompleted EventType = "tool_call_completed"
+)
+
+// Event is a step of a run, reported as it happens
+type Event struct {
+	Type EventType
+
+	// Text and Final are set for messages. Final is true for the answer that
+	// ends the run.
+	Text  string
+	Final bool
+
+	// CallID, Server, Tool, and Arguments are set for tool calls. Result or
+	// Error are set when the call completed. CallID is the ID the model gave
+	// the call, which tells apart calls executed in parallel.
+	CallID    string
+	Server    string
+	Tool      string
+	Arguments map[string]any
+	Result    *mcpsdk.CallToolResult
+	Error     error
+}
+
+// EventHandler is called with the events of a run, in order. It is never
+// called concurrently, but the events of tool calls executed in parallel are
+// interleaved.
+type EventHandler func(Event)
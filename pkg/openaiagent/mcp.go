@@ -12,6 +12,7 @@ import (
 
 // McpClient wraps MCP SDK functionality for tool calling
 type McpClient struct {
+	url     string
 	session *mcpsdk.ClientSession
 	tools   []mcpsdk.Tool
 }
@@ -36,6 +37,7 @@ func NewMcpClient(ctx context.Context, serverURL string) (*McpClient, error) {
 	}
 
 	return &McpClient{
+		url:     serverURL,
 		session: session,
 	}, nil
 }
@@ -69,17 +71,47 @@ func (c *McpClient) GetTools() []openai.ChatCompletionToolUnionParam {
 	return openaiTools
 }
 
+// Tools returns the available tools as MCP tool definitions
+func (c *McpClient) Tools() []mcpsdk.Tool {
+	return c.tools
+}
+
+// Name returns the name the server reported when connecting, or its URL if
+// it reported none
+func (c *McpClient) Name() string {
+	if res := c.session.InitializeResult(); res != nil && res.ServerInfo != nil && res.ServerInfo.Name != "" {
+		return res.ServerInfo.Name
+	}
+	return c.url
+}
+
 // CallTool executes a tool call through the MCP server
 func (c *McpClient) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (string, error) {
+	result, err := c.CallToolResult(ctx, name, arguments)
+	if err != nil {
+		return "", err
+	}
+
+	return marshalToolResult(result)
+}
+
+// CallToolResult executes a tool call through the MCP server and returns its
+// result as is
+func (c *McpClient) CallToolResult(ctx context.Context, name string, arguments map[string]interface{}) (*mcpsdk.CallToolResult, error) {
 	result, err := c.session.CallTool(ctx, &mcpsdk.CallToolParams{
 		Name:      name,
 		Arguments: arguments,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to call tool %s: %w", name, err)
+		return nil, fmt.Errorf("failed to call tool %s: %w", name, err)
 	}
 
-	// Convert result to string representation
+	return result, nil
+}
+
+// marshalToolResult converts a tool result to the string representation
+// passed to the model
+func marshalToolResult(result *mcpsdk.CallToolResult) (string, error) {
 	resultBytes, err := json.Marshal(result)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal tool result: %w", err)
@@ -105,25 +137,28 @@ func convertMCPToolToOpenAI(tool mcpsdk.Tool) openai.ChatCompletionToolUnionPara
 		function.Description = openai.String(tool.Description)
 	}
 
-	// If the tool has input schema, convert it to OpenAI parameters format
-	if tool.InputSchema != nil {
-		// The MCP tool schema should be compatible with JSON Schema
-		// which OpenAI function calling expects
-		if params, ok := tool.InputSchema.(map[string]interface{}); ok {
-			// Ensure properties field exists (OpenAI requires it)
-			if _, hasProps := params["properties"]; !hasProps {
-				params["properties"] = map[string]interface{}{}
-			}
-			function.Parameters = shared.FunctionParameters(params)
-		}
-	} else {
-		// OpenAI requires parameters with at least an empty properties object
-		function.Parameters = shared.FunctionParameters(map[string]interface{}{
-			"type":       "object",
-			"properties": map[string]interface{}{},
-		})
-	}
+	function.Parameters = shared.FunctionParameters(ToolInputSchema(tool))
 
 	// Use the helper function to create the tool
 	return openai.ChatCompletionFunctionTool(function)
 }
+
+// ToolInputSchema returns the JSON schema of the arguments of an MCP tool, as
+// model APIs expect it: an object schema that always has properties
+func ToolInputSchema(tool mcpsdk.Tool) map[string]any {
+	// The MCP tool schema should be compatible with JSON Schema
+	// which model function calling expects
+	if params, ok := tool.InputSchema.(map[string]interface{}); ok {
+		// Ensure properties field exists (OpenAI requires it)
+		if _, hasProps := params["properties"]; !hasProps {
+			params["properties"] = map[string]interface{}{}
+		}
+		return params
+	}
+
+	// APIs require parameters with at least an empty properties object
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+}
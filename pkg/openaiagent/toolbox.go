@@ -0,0 +1,207 @@
+package openaiagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultMaxIterations is the number of model requests a run may make unless
+// Options.MaxIterations is set
+const DefaultMaxIterations = 50
+
+// ErrMaxIterations is returned by Run when the model keeps calling tools after
+// the maximum number of iterations
+var ErrMaxIterations = errors.New("agent reached the maximum number of iterations")
+
+// Options control the tool-call loop of an agent
+type Options struct {
+	// MaxIterations is the number of model requests a run may make. Zero
+	// means DefaultMaxIterations and a negative value means no limit.
+	MaxIterations int
+	// ParallelToolCalls executes the tool calls the model requests in one
+	// response concurrently instead of one after the other
+	ParallelToolCalls bool
+	// ToolCallTimeout limits the time of each tool call. Zero means no limit.
+	ToolCallTimeout time.Duration
+}
+
+// ToolCallRequest is a tool call requested by the model
+type ToolCallRequest struct {
+	ID        string
+	Name      string
+	Arguments map[string]any
+}
+
+// Toolbox holds the MCP servers of an agent and executes the tool calls its
+// model requests, following the options of the tool-call loop. It does not
+// depend on the model API, so agents for other APIs embed it too.
+type Toolbox struct {
+	clients []*McpClient
+	options Options
+
+	eventMu sync.Mutex
+	onEvent EventHandler
+}
+
+// SetOptions sets the options of the tool-call loop
+func (t *Toolbox) SetOptions(options Options) {
+	t.options = options
+}
+
+// MaxIterations returns the number of model requests a run may make, or a
+// negative value if there is no limit
+func (t *Toolbox) MaxIterations() int {
+	if t.options.MaxIterations == 0 {
+		return DefaultMaxIterations
+	}
+	return t.options.MaxIterations
+}
+
+// CheckIteration returns ErrMaxIterations if a run may not make the model
+// request with the given 0-based index
+func (t *Toolbox) CheckIteration(iteration int) error {
+	if max := t.MaxIterations(); max > 0 && iteration >= max {
+		return fmt.Errorf("%w (%d)", ErrMaxIterations, max)
+	}
+	return nil
+}
+
+// OnEvent sets the handler called with the steps of each run as they happen
+func (t *Toolbox) OnEvent(handler EventHandler) {
+	t.onEvent = handler
+}
+
+// Emit reports an event to the handler, if one is set
+func (t *Toolbox) Emit(e Event) {
+	t.eventMu.Lock()
+	defer t.eventMu.Unlock()
+
+	if t.onEvent != nil {
+		t.onEvent(e)
+	}
+}
+
+// AddMCPServer adds an MCP server to the agent
+func (t *Toolbox) AddMCPServer(ctx context.Context, serverURL string) error {
+	mcpClient, err := NewMcpClient(ctx, serverURL)
+	if err != nil {
+		return fmt.Errorf("failed to create MCP client for %s: %w", serverURL, err)
+	}
+
+	// Load available tools from the MCP server
+	if err := mcpClient.LoadTools(ctx); err != nil {
+		mcpClient.Close()
+		return fmt.Errorf("failed to load MCP tools from %s: %w", serverURL, err)
+	}
+
+	t.clients = append(t.clients, mcpClient)
+	return nil
+}
+
+// Tools returns the tools of all MCP servers
+func (t *Toolbox) Tools() []mcpsdk.Tool {
+	var tools []mcpsdk.Tool
+	for _, mcpClient := range t.clients {
+		tools = append(tools, mcpClient.Tools()...)
+	}
+	return tools
+}
+
+// ExecuteToolCalls executes tool calls, concurrently if ParallelToolCalls is
+// set, and returns the result to pass to the model for each of them
+func (t *Toolbox) ExecuteToolCalls(ctx context.Context, calls []ToolCallRequest) []string {
+	results := make([]string, len(calls))
+
+	execute := func(i int) {
+		// Find which MCP client has this tool and execute it
+		result, err := t.callToolOnAnyClient(ctx, calls[i].ID, calls[i].Name, calls[i].Arguments)
+		if err != nil {
+			result = fmt.Sprintf("Error calling tool: %v", err)
+		}
+		results[i] = result
+	}
+
+	if !t.options.ParallelToolCalls || len(calls) < 2 {
+		for i := range calls {
+			execute(i)
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for i := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			execute(i)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// callToolOnAnyClient finds the MCP client that has the specified tool and calls it
+func (t *Toolbox) callToolOnAnyClient(ctx context.Context, callID, toolName string, arguments map[string]any) (string, error) {
+	// Search through all MCP clients to find one that has this tool
+	for _, mcpClient := range t.clients {
+		for _, tool := range mcpClient.Tools() {
+			if tool.Name != toolName {
+				continue
+			}
+
+			// Found the tool, call it on this client
+			event := Event{CallID: callID, Server: mcpClient.Name(), Tool: toolName, Arguments: arguments}
+			event.Type = EventToolCallStarted
+			t.Emit(event)
+
+			result, err := t.callTool(ctx, mcpClient, toolName, arguments)
+
+			event.Type, event.Result, event.Error = EventToolCallCompleted, result, err
+			t.Emit(event)
+			if err != nil {
+				return "", err
+			}
+			return marshalToolResult(result)
+		}
+	}
+
+	return "", fmt.Errorf("tool %s not found in any MCP client", toolName)
+}
+
+// callTool calls a tool on a client, within ToolCallTimeout if it is set
+func (t *Toolbox) callTool(ctx context.Context, mcpClient *McpClient, toolName string, arguments map[string]any) (*mcpsdk.CallToolResult, error) {
+	if t.options.ToolCallTimeout <= 0 {
+		return mcpClient.CallToolResult(ctx, toolName, arguments)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, t.options.ToolCallTimeout)
+	defer cancel()
+
+	result, err := mcpClient.CallToolResult(callCtx, toolName, arguments)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("tool %s timed out after %s", toolName, t.options.ToolCallTimeout)
+	}
+	return result, err
+}
+
+// Close closes the connections to the MCP servers
+func (t *Toolbox) Close() error {
+	var errs []error
+	for _, mcpClient := range t.clients {
+		if err := mcpClient.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close %d MCP clients: %v", len(errs), errs)
+	}
+
+	return nil
+}
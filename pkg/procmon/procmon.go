@@ -0,0 +1,271 @@
+// Package procmon samples the CPU and memory use of agent processes and kills
+// agents that exceed their limits.
+package procmon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// DefaultSampleInterval is how often processes are sampled if the config does
+// not say otherwise
+const DefaultSampleInterval = 500 * time.Millisecond
+
+// Config limits the resources of the agent process of a task. Processes are
+// sampled on Linux; on other platforms only the CPU time of the agent is
+// recorded once it exits, and the limits are not enforced.
+type Config struct {
+	// MaxMemoryMB kills the agent once the resident memory of its process
+	// tree exceeds it
+	MaxMemoryMB int `json:"maxMemoryMB,omitempty"`
+
+	// MaxCPUSeconds kills the agent once the CPU time of its process tree
+	// exceeds it
+	MaxCPUSeconds float64 `json:"maxCpuSeconds,omitempty"`
+
+	// SampleInterval is how often the process tree is sampled. Defaults to
+	// 500ms
+	SampleInterval util.Duration `json:"sampleInterval,omitempty"`
+}
+
+// Validate checks that the limits are not negative
+func (c *Config) Validate() error {
+	if c.MaxMemoryMB < 0 {
+		return fmt.Errorf("maxMemoryMB must not be negative")
+	}
+	if c.MaxCPUSeconds < 0 {
+		return fmt.Errorf("maxCpuSeconds must not be negative")
+	}
+	if c.SampleInterval < 0 {
+		return fmt.Errorf("sampleInterval must not be negative")
+	}
+	return nil
+}
+
+// Usage is the CPU and memory used by the process tree of an agent while it
+// ran a task
+type Usage struct {
+	PeakMemoryBytes    int64   `json:"peakMemoryBytes"`
+	AverageMemoryBytes int64   `json:"averageMemoryBytes"`
+	CPUSeconds         float64 `json:"cpuSeconds"`
+	PeakCPUPercent     float64 `json:"peakCpuPercent"`
+	AverageCPUPercent  float64 `json:"averageCpuPercent"`
+	Samples            int     `json:"samples"`
+	// LimitExceeded is set if the agent was killed for exceeding a limit
+	LimitExceeded string `json:"limitExceeded,omitempty"`
+}
+
+// sample is the resource use of a process tree at one point in time
+type sample struct {
+	memoryBytes int64
+	cpuSeconds  float64
+	pids        []int
+}
+
+// Monitor records the resource use of the agent processes of one task
+type Monitor struct {
+	cfg Config
+
+	mu          sync.Mutex
+	usage       Usage
+	memoryTotal int64
+	wall        time.Duration
+}
+
+// NewMonitor returns a monitor that enforces the limits of cfg, which may be
+// nil for no limits
+func NewMonitor(cfg *Config) *Monitor {
+	m := &Monitor{}
+	if cfg != nil {
+		m.cfg = *cfg
+	}
+	if m.cfg.SampleInterval == 0 {
+		m.cfg.SampleInterval = util.Duration(DefaultSampleInterval)
+	}
+	return m
+}
+
+// Usage returns the recorded resource use, or nil if no agent process was
+// watched
+func (m *Monitor) Usage() *Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.usage.Samples == 0 && m.usage.CPUSeconds == 0 {
+		return nil
+	}
+
+	usage := m.usage
+	if usage.Samples > 0 {
+		usage.AverageMemoryBytes = m.memoryTotal / int64(usage.Samples)
+	}
+	if m.wall > 0 {
+		usage.AverageCPUPercent = usage.CPUSeconds / m.wall.Seconds() * 100
+	}
+	return &usage
+}
+
+type monitorKey struct{}
+
+// ToContext returns a context that carries the monitor. Agent processes
+// started with this context are watched by it.
+func ToContext(ctx context.Context, m *Monitor) context.Context {
+	return context.WithValue(ctx, monitorKey{}, m)
+}
+
+// FromContext returns the monitor stored in ctx, if any
+func FromContext(ctx context.Context) (*Monitor, bool) {
+	m, ok := ctx.Value(monitorKey{}).(*Monitor)
+	return m, ok && m != nil
+}
+
+// Watcher samples one agent process and its descendants until it is stopped
+type Watcher struct {
+	monitor *Monitor
+	start   time.Time
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	// Written by the sampling goroutine, read after it is done
+	cpuSeconds float64
+	exceeded   string
+}
+
+// Watch starts sampling the process with pid and its descendants, if ctx
+// carries a monitor. The returned watcher is nil otherwise, which is safe to
+// stop.
+func Watch(ctx context.Context, pid int) *Watcher {
+	m, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	w := &Watcher{
+		monitor: m,
+		start:   time.Now(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run(pid)
+	return w
+}
+
+func (w *Watcher) run(pid int) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(time.Duration(w.monitor.cfg.SampleInterval))
+	defer ticker.Stop()
+
+	last, lastTime := 0.0, w.start
+	for {
+		s, err := sampleTree(pid)
+		if err != nil {
+			// The process exited, or it cannot be sampled on this platform
+			return
+		}
+
+		now := time.Now()
+		percent := 0.0
+		if elapsed := now.Sub(lastTime).Seconds(); elapsed > 0 && s.cpuSeconds >= last {
+			percent = (s.cpuSeconds - last) / elapsed * 100
+		}
+		last, lastTime = s.cpuSeconds, now
+		w.cpuSeconds = s.cpuSeconds
+		w.monitor.record(s, percent)
+
+		if exceeded := w.monitor.cfg.exceeded(s); exceeded != "" {
+			w.exceeded = exceeded
+			killProcesses(s.pids)
+			return
+		}
+
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops sampling and adds the CPU time of the exited process from state,
+// which may be nil, to the usage. It returns an error if the process was
+// killed for exceeding a limit. Only the first call records the usage.
+func (w *Watcher) Stop(state *os.ProcessState) error {
+	if w == nil {
+		return nil
+	}
+
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.done
+
+		cpuSeconds := w.cpuSeconds
+		var peakMemory int64
+		if state != nil {
+			// The CPU time of the exited process includes its children that it
+			// waited for, which may have exited between samples
+			cpuSeconds = max(cpuSeconds, (state.UserTime() + state.SystemTime()).Seconds())
+			peakMemory = maxRSS(state)
+		}
+		w.monitor.finish(cpuSeconds, peakMemory, time.Since(w.start), w.exceeded)
+	})
+
+	return w.Err()
+}
+
+// Err returns an error if the process was killed for exceeding a limit
+func (w *Watcher) Err() error {
+	if w == nil {
+		return nil
+	}
+
+	select {
+	case <-w.done:
+	default:
+		return nil
+	}
+	if w.exceeded != "" {
+		return fmt.Errorf("agent was killed for exceeding %s", w.exceeded)
+	}
+	return nil
+}
+
+// exceeded returns the limit the sample exceeds, or "" if it is within the
+// limits
+func (c *Config) exceeded(s *sample) string {
+	if c.MaxMemoryMB > 0 && s.memoryBytes > int64(c.MaxMemoryMB)<<20 {
+		return fmt.Sprintf("the memory limit of %d MB with %d MB", c.MaxMemoryMB, s.memoryBytes>>20)
+	}
+	if c.MaxCPUSeconds > 0 && s.cpuSeconds > c.MaxCPUSeconds {
+		return fmt.Sprintf("the CPU limit of %gs with %.1fs", c.MaxCPUSeconds, s.cpuSeconds)
+	}
+	return ""
+}
+
+func (m *Monitor) record(s *sample, cpuPercent float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.usage.Samples++
+	m.memoryTotal += s.memoryBytes
+	m.usage.PeakMemoryBytes = max(m.usage.PeakMemoryBytes, s.memoryBytes)
+	m.usage.PeakCPUPercent = max(m.usage.PeakCPUPercent, cpuPercent)
+}
+
+func (m *Monitor) finish(cpuSeconds float64, peakMemory int64, wall time.Duration, exceeded string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.usage.CPUSeconds += cpuSeconds
+	m.usage.PeakMemoryBytes = max(m.usage.PeakMemoryBytes, peakMemory)
+	m.wall += wall
+	if exceeded != "" {
+		m.usage.LimitExceeded = exceeded
+	}
+}
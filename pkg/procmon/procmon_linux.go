@@ -0,0 +1,124 @@
+package procmon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// clockTicks is the unit of the CPU times in /proc, which is 100 per second
+// on every Linux platform Go supports
+const clockTicks = 100
+
+// procStat is the part of /proc/<pid>/stat the monitor uses
+type procStat struct {
+	ppid int
+	// cpuTicks is the CPU time of the process and of its children that it
+	// waited for
+	cpuTicks int64
+	rssPages int64
+}
+
+// sampleTree returns the resource use of the process with pid and all its
+// descendants
+func sampleTree(pid int) (*sample, error) {
+	root, err := readStat(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[int]*procStat{pid: root}
+	children := map[int][]int{}
+	for _, entry := range entries {
+		child, err := strconv.Atoi(entry.Name())
+		if err != nil || child == pid {
+			continue
+		}
+		stat, err := readStat(child)
+		if err != nil {
+			// The process exited since the directory was read
+			continue
+		}
+		stats[child] = stat
+		children[stat.ppid] = append(children[stat.ppid], child)
+	}
+
+	s := &sample{}
+	var cpuTicks int64
+	pageSize := int64(os.Getpagesize())
+	for queue := []int{pid}; len(queue) > 0; queue = queue[1:] {
+		p := queue[0]
+		stat := stats[p]
+		s.pids = append(s.pids, p)
+		s.memoryBytes += stat.rssPages * pageSize
+		cpuTicks += stat.cpuTicks
+		queue = append(queue, children[p]...)
+	}
+	s.cpuSeconds = float64(cpuTicks) / clockTicks
+
+	return s, nil
+}
+
+// readStat reads /proc/<pid>/stat. The command name in it may contain spaces
+// and parentheses, so the fields are counted from the last parenthesis.
+func readStat(pid int) (*procStat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return nil, fmt.Errorf("invalid stat of process %d", pid)
+	}
+	// Fields from the state, which is field 3 of the stat
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("invalid stat of process %d", pid)
+	}
+	if fields[0] == "Z" {
+		return nil, fmt.Errorf("process %d exited", pid)
+	}
+
+	stat := &procStat{}
+	values := make([]int64, 0, 6)
+	// ppid, utime, stime, cutime, cstime, and rss are fields 4, 14-17, and 24
+	for _, i := range []int{1, 11, 12, 13, 14, 21} {
+		v, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stat of process %d: %w", pid, err)
+		}
+		values = append(values, v)
+	}
+	stat.ppid = int(values[0])
+	stat.cpuTicks = values[1] + values[2] + values[3] + values[4]
+	stat.rssPages = values[5]
+
+	return stat, nil
+}
+
+// killProcesses kills the processes of a tree, parents first so that they do
+// not start new children
+func killProcesses(pids []int) {
+	for _, pid := range pids {
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+	}
+}
+
+// maxRSS returns the largest resident memory of the exited process and the
+// children it waited for
+func maxRSS(state *os.ProcessState) int64 {
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// ru_maxrss is in kilobytes on Linux
+	return rusage.Maxrss << 10
+}
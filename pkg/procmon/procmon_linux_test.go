@@ -0,0 +1,91 @@
+package procmon
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleTree(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 5 & sleep 5 & wait")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	// The children of the shell are started asynchronously
+	var s *sample
+	require.Eventually(t, func() bool {
+		var err error
+		s, err = sampleTree(cmd.Process.Pid)
+		return err == nil && len(s.pids) == 3
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, cmd.Process.Pid, s.pids[0])
+	assert.Greater(t, s.memoryBytes, int64(0))
+}
+
+func TestSampleTreeOfMissingProcess(t *testing.T) {
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Run())
+
+	_, err := sampleTree(cmd.Process.Pid)
+	assert.Error(t, err)
+}
+
+func TestWatch(t *testing.T) {
+	m := NewMonitor(&Config{SampleInterval: util.Duration(20 * time.Millisecond)})
+	ctx := ToContext(context.Background(), m)
+
+	cmd := exec.Command("sh", "-c", "sleep 0.2")
+	require.NoError(t, cmd.Start())
+	w := Watch(ctx, cmd.Process.Pid)
+	require.NotNil(t, w)
+	require.NoError(t, cmd.Wait())
+	require.NoError(t, w.Stop(cmd.ProcessState))
+
+	usage := m.Usage()
+	require.NotNil(t, usage)
+	assert.Greater(t, usage.Samples, 1)
+	assert.Greater(t, usage.PeakMemoryBytes, int64(0))
+	assert.Greater(t, usage.AverageMemoryBytes, int64(0))
+	assert.Empty(t, usage.LimitExceeded)
+}
+
+func TestWatchKillsProcessOverLimit(t *testing.T) {
+	m := NewMonitor(&Config{MaxCPUSeconds: 0.1, SampleInterval: util.Duration(20 * time.Millisecond)})
+	ctx := ToContext(context.Background(), m)
+
+	cmd := exec.Command("sh", "-c", "while :; do :; done")
+	require.NoError(t, cmd.Start())
+	w := Watch(ctx, cmd.Process.Pid)
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(10 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatal("process over the CPU limit was not killed")
+	}
+
+	err := w.Stop(cmd.ProcessState)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "agent was killed for exceeding the CPU limit of 0.1s")
+	assert.Contains(t, m.Usage().LimitExceeded, "the CPU limit of 0.1s")
+}
+
+func TestReadStat(t *testing.T) {
+	stat, err := readStat(os.Getpid())
+	require.NoError(t, err)
+	assert.Equal(t, os.Getppid(), stat.ppid)
+	assert.Greater(t, stat.rssPages, int64(0))
+}
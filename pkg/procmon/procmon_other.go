@@ -0,0 +1,20 @@
+//go:build !linux
+
+package procmon
+
+import (
+	"errors"
+	"os"
+)
+
+// sampleTree is not supported outside Linux, where only the CPU time of the
+// exited process is recorded
+func sampleTree(pid int) (*sample, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func killProcesses(pids []int) {}
+
+func maxRSS(state *os.ProcessState) int64 {
+	return 0
+}
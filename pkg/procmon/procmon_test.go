@@ -0,0 +1,81 @@
+package procmon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		cfg         Config
+		errContains string
+	}{
+		"valid": {
+			cfg: Config{MaxMemoryMB: 512, MaxCPUSeconds: 30, SampleInterval: util.Duration(time.Second)},
+		},
+		"negative memory": {
+			cfg:         Config{MaxMemoryMB: -1},
+			errContains: "maxMemoryMB must not be negative",
+		},
+		"negative cpu": {
+			cfg:         Config{MaxCPUSeconds: -1},
+			errContains: "maxCpuSeconds must not be negative",
+		},
+		"negative interval": {
+			cfg:         Config{SampleInterval: util.Duration(-time.Second)},
+			errContains: "sampleInterval must not be negative",
+		},
+	}
+
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConfigExceeded(t *testing.T) {
+	cfg := &Config{MaxMemoryMB: 100, MaxCPUSeconds: 2}
+
+	assert.Empty(t, cfg.exceeded(&sample{memoryBytes: 100 << 20, cpuSeconds: 2}))
+	assert.Equal(t, "the memory limit of 100 MB with 150 MB", cfg.exceeded(&sample{memoryBytes: 150 << 20}))
+	assert.Equal(t, "the CPU limit of 2s with 2.5s", cfg.exceeded(&sample{cpuSeconds: 2.5}))
+	assert.Empty(t, (&Config{}).exceeded(&sample{memoryBytes: 1 << 40, cpuSeconds: 1000}))
+}
+
+func TestMonitorUsage(t *testing.T) {
+	m := NewMonitor(nil)
+	assert.Nil(t, m.Usage())
+	assert.Equal(t, util.Duration(DefaultSampleInterval), m.cfg.SampleInterval)
+
+	m.record(&sample{memoryBytes: 100}, 50)
+	m.record(&sample{memoryBytes: 300}, 150)
+	m.finish(2, 200, 4*time.Second, "")
+
+	assert.Equal(t, &Usage{
+		PeakMemoryBytes:    300,
+		AverageMemoryBytes: 200,
+		CPUSeconds:         2,
+		PeakCPUPercent:     150,
+		AverageCPUPercent:  50,
+		Samples:            2,
+	}, m.Usage())
+}
+
+func TestWatchWithoutMonitor(t *testing.T) {
+	w := Watch(context.Background(), 1)
+	assert.Nil(t, w)
+	assert.NoError(t, w.Stop(nil))
+	assert.NoError(t, w.Err())
+}
@@ -0,0 +1,370 @@
+package results
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// BundleVersion is the format version of result bundles
+const BundleVersion = "mcpchecker.bundle/v1"
+
+// SignatureAlgorithm is the algorithm bundles are signed with
+const SignatureAlgorithm = "ed25519"
+
+// Bundle is a results file with the digests of the configuration and tasks
+// they were produced with, signed so that published results can be checked by
+// anyone with the public key
+type Bundle struct {
+	Manifest  BundleManifest   `json:"manifest"`
+	Results   json.RawMessage  `json:"results"`
+	Signature *BundleSignature `json:"signature,omitempty"`
+}
+
+// BundleManifest is the signed part of a bundle. It covers the results
+// through their digest.
+type BundleManifest struct {
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ToolVersion is the version of mcpchecker that ran the eval
+	ToolVersion string `json:"toolVersion,omitempty"`
+	Eval        string `json:"eval"`
+	// Files are the digests of the eval config and the files it refers to,
+	// such as the MCP and agent configs. Paths are relative to the directory
+	// of the eval config.
+	Files []FileDigest `json:"files"`
+	// Tasks are the digests of the task files of the results and of the
+	// files they refer to
+	Tasks []TaskDigest `json:"tasks"`
+	// ResultsDigest is the digest of the results of the bundle
+	ResultsDigest string `json:"resultsDigest"`
+}
+
+// FileDigest is the sha256 digest of a file, as sha256:<hex>
+type FileDigest struct {
+	Path   string `json:"path"`
+	Digest string `json:"digest"`
+}
+
+// TaskDigest is the digest of the file of a task
+type TaskDigest struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Digest string `json:"digest"`
+	// Files are the digests of the files the task refers to, such as prompt
+	// files, images, and snapshot golden files
+	Files []FileDigest `json:"files,omitempty"`
+}
+
+// BundleSignature is a signature of the manifest of a bundle
+type BundleSignature struct {
+	Algorithm string `json:"algorithm"`
+	// PublicKey is the base64 encoded public key of the signer
+	PublicKey string `json:"publicKey"`
+	// Value is the base64 encoded signature of the JSON encoded manifest
+	Value string `json:"value"`
+}
+
+// NewBundle creates an unsigned bundle of the results of an eval. Digests are
+// taken of the config files, of the task files of the results, and of the
+// files the tasks refer to, and their paths are recorded relative to baseDir,
+// the directory of the eval config.
+func NewBundle(results []*eval.EvalResult, evalName, baseDir string, configFiles []string) (*Bundle, error) {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	b := &Bundle{
+		Manifest: BundleManifest{
+			Version:       BundleVersion,
+			CreatedAt:     time.Now().UTC(),
+			ToolVersion:   toolVersion(),
+			Eval:          evalName,
+			Files:         []FileDigest{},
+			Tasks:         []TaskDigest{},
+			ResultsDigest: digest(data),
+		},
+		Results: data,
+	}
+
+	for _, path := range configFiles {
+		d, err := fileDigest(path)
+		if err != nil {
+			return nil, err
+		}
+		b.Manifest.Files = append(b.Manifest.Files, FileDigest{Path: relativePath(baseDir, path), Digest: d})
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range results {
+		key := r.TaskName + "\x00" + r.TaskPath
+		if r.TaskPath == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		d, err := fileDigest(r.TaskPath)
+		if err != nil {
+			return nil, err
+		}
+		taskDigest := TaskDigest{Name: r.TaskName, Path: relativePath(baseDir, r.TaskPath), Digest: d}
+		if r.Inputs != nil && r.Inputs.Task != nil {
+			for _, path := range r.Inputs.Task.ReferencedFiles() {
+				d, err := fileDigest(path)
+				if errors.Is(err, fs.ErrNotExist) {
+					// A task missing a file it refers to failed without it
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				taskDigest.Files = append(taskDigest.Files, FileDigest{Path: relativePath(baseDir, path), Digest: d})
+			}
+		}
+		b.Manifest.Tasks = append(b.Manifest.Tasks, taskDigest)
+	}
+
+	return b, nil
+}
+
+// Sign signs the manifest of the bundle with key
+func (b *Bundle) Sign(key ed25519.PrivateKey) error {
+	data, err := json.Marshal(b.Manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	b.Signature = &BundleSignature{
+		Algorithm: SignatureAlgorithm,
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	}
+	return nil
+}
+
+// Verify checks that the results match the digest of the manifest and that
+// the manifest is signed. If trusted is not nil, the bundle must be signed
+// with that key, otherwise any key is accepted and the caller should check
+// the key returned.
+func (b *Bundle) Verify(trusted ed25519.PublicKey) (ed25519.PublicKey, error) {
+	if b.Manifest.Version != BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %q (must be %s)", b.Manifest.Version, BundleVersion)
+	}
+	d, err := resultsDigest(b.Results)
+	if err != nil {
+		return nil, err
+	}
+	if d != b.Manifest.ResultsDigest {
+		return nil, fmt.Errorf("results do not match the digest of the manifest: got %s, want %s", d, b.Manifest.ResultsDigest)
+	}
+
+	if b.Signature == nil {
+		return nil, fmt.Errorf("bundle is not signed")
+	}
+	if b.Signature.Algorithm != SignatureAlgorithm {
+		return nil, fmt.Errorf("unsupported signature algorithm %q (must be %s)", b.Signature.Algorithm, SignatureAlgorithm)
+	}
+	key, err := base64.StdEncoding.DecodeString(b.Signature.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key in signature")
+	}
+	if trusted != nil && !bytes.Equal(key, trusted) {
+		return nil, fmt.Errorf("bundle is signed with key %s, not with the trusted key %s", KeyFingerprint(key), KeyFingerprint(trusted))
+	}
+	sig, err := base64.StdEncoding.DecodeString(b.Signature.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	data, err := json.Marshal(b.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return nil, fmt.Errorf("signature does not match the manifest")
+	}
+	return key, nil
+}
+
+// CheckFiles compares the digests of the manifest with the files in baseDir,
+// such as a checkout of the suite, and returns the paths of the files that
+// are missing or differ
+func (b *Bundle) CheckFiles(baseDir string) []string {
+	var mismatches []string
+	check := func(path, want string) {
+		got, err := fileDigest(filepath.Join(baseDir, filepath.FromSlash(path)))
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: missing", path))
+		} else if got != want {
+			mismatches = append(mismatches, fmt.Sprintf("%s: digest %s, want %s", path, got, want))
+		}
+	}
+
+	for _, f := range b.Manifest.Files {
+		check(f.Path, f.Digest)
+	}
+	checked := make(map[string]bool)
+	for _, t := range b.Manifest.Tasks {
+		for _, f := range append([]FileDigest{{Path: t.Path, Digest: t.Digest}}, t.Files...) {
+			if !checked[f.Path] {
+				checked[f.Path] = true
+				check(f.Path, f.Digest)
+			}
+		}
+	}
+	return mismatches
+}
+
+// EvalResults decodes the results of the bundle
+func (b *Bundle) EvalResults() ([]*eval.EvalResult, error) {
+	var results []*eval.EvalResult
+	if err := json.Unmarshal(b.Results, &results); err != nil {
+		return nil, fmt.Errorf("invalid results in bundle: %w", err)
+	}
+	return results, nil
+}
+
+// SaveBundle writes a bundle to a JSON file
+func SaveBundle(b *Bundle, path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return writeJSONFile(path, b, false)
+}
+
+// LoadBundle reads a bundle from a JSON file
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// LoadSigningKey reads an ed25519 private key from a PEM encoded PKCS #8
+// file, as written by openssl genpkey -algorithm ed25519
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// LoadPublicKey reads an ed25519 public key from a PEM encoded PKIX file, as
+// written by openssl pkey -pubout
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// KeyFingerprint identifies a public key by the digest of its bytes, as
+// SHA256:<base64>
+func KeyFingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+	return block, nil
+}
+
+// resultsDigest returns the digest of the compacted results, which do not
+// depend on the indentation of the bundle file
+func resultsDigest(data json.RawMessage) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid results in bundle: %w", err)
+	}
+	return digest(buf.Bytes()), nil
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func fileDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return digest(data), nil
+}
+
+// relativePath returns path relative to baseDir with forward slashes, or path
+// itself if it cannot be made relative
+func relativePath(baseDir, path string) string {
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// toolVersion returns the version of the mcpchecker module the binary was
+// built from, if known
+func toolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Path == "github.com/mcpchecker/mcpchecker" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/mcpchecker/mcpchecker" {
+			return dep.Version
+		}
+	}
+	return ""
+}
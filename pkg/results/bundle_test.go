@@ -0,0 +1,264 @@
+package results
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// writeBundleSuite writes an eval config and a task file to a directory and
+// returns the results of a run of the task
+func writeBundleSuite(t *testing.T) (string, []*eval.EvalResult) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "tasks"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		"eval.yaml":         "kind: Eval\n",
+		"tasks/create.yaml": "kind: Task\n",
+		"mcp-config.yaml":   "mcpServers: {}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, []*eval.EvalResult{{
+		TaskName:   "create-pod",
+		TaskPath:   filepath.Join(dir, "tasks", "create.yaml"),
+		TaskPassed: true,
+		TaskOutput: "created <pod> & done",
+	}}
+}
+
+func TestBundleSignAndVerify(t *testing.T) {
+	dir, res := writeBundleSuite(t)
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := NewBundle(res, "suite", dir, []string{filepath.Join(dir, "eval.yaml"), filepath.Join(dir, "mcp-config.yaml")})
+	if err != nil {
+		t.Fatalf("NewBundle() error = %v", err)
+	}
+	if err := b.Sign(priv); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if len(b.Manifest.Files) != 2 || b.Manifest.Files[0].Path != "eval.yaml" {
+		t.Errorf("files = %v, want eval.yaml and mcp-config.yaml", b.Manifest.Files)
+	}
+	if len(b.Manifest.Tasks) != 1 || b.Manifest.Tasks[0].Path != "tasks/create.yaml" || !strings.HasPrefix(b.Manifest.Tasks[0].Digest, "sha256:") {
+		t.Errorf("tasks = %v, want the digest of tasks/create.yaml", b.Manifest.Tasks)
+	}
+
+	path := filepath.Join(dir, "out", "bundle.json")
+	if err := SaveBundle(b, path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBundle(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := loaded.Verify(pub)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !key.Equal(pub) {
+		t.Errorf("Verify() key = %s, want %s", KeyFingerprint(key), KeyFingerprint(pub))
+	}
+	if mismatches := loaded.CheckFiles(dir); len(mismatches) != 0 {
+		t.Errorf("CheckFiles() = %v, want no mismatches", mismatches)
+	}
+
+	decoded, err := loaded.EvalResults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0].TaskOutput != "created <pod> & done" {
+		t.Errorf("EvalResults() = %v, want the results of the bundle", decoded)
+	}
+}
+
+func TestBundleTaskFiles(t *testing.T) {
+	dir, res := writeBundleSuite(t)
+	for path, content := range map[string]string{
+		"tasks/create.yaml": `apiVersion: mcpchecker/v1alpha2
+kind: Task
+metadata:
+  name: create-pod
+spec:
+  prompt:
+    file: prompt.md
+    files: [data/pods.csv]
+    images:
+      - file: screen.png
+  verify:
+    - snapshot:
+        step:
+          script:
+            inline: kubectl get pods
+        file: golden/pods.txt
+`,
+		"tasks/prompt.md":       "Create a pod",
+		"tasks/data/pods.csv":   "name\nweb\n",
+		"tasks/screen.png":      "\x89PNG",
+		"tasks/golden/pods.txt": "web Running\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spec, err := task.FromFile(res[0].TaskPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res[0].Inputs = &eval.RunInputs{Task: spec}
+
+	b, err := NewBundle(res, "suite", dir, []string{filepath.Join(dir, "eval.yaml")})
+	if err != nil {
+		t.Fatalf("NewBundle() error = %v", err)
+	}
+	var paths []string
+	for _, f := range b.Manifest.Tasks[0].Files {
+		paths = append(paths, f.Path)
+	}
+	want := []string{"tasks/prompt.md", "tasks/data/pods.csv", "tasks/screen.png", "tasks/golden/pods.txt"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("task files = %v, want %v", paths, want)
+	}
+
+	// A changed golden file is reported
+	if err := os.WriteFile(filepath.Join(dir, "tasks", "golden", "pods.txt"), []byte("web Pending\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mismatches := b.CheckFiles(dir)
+	if len(mismatches) != 1 || !strings.HasPrefix(mismatches[0], "tasks/golden/pods.txt: digest") {
+		t.Errorf("CheckFiles() = %v, want a mismatch of the golden file", mismatches)
+	}
+}
+
+func TestBundleVerifyFailures(t *testing.T) {
+	dir, res := writeBundleSuite(t)
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newSigned := func() *Bundle {
+		b, err := NewBundle(res, "suite", dir, []string{filepath.Join(dir, "eval.yaml")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Sign(priv); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	tests := map[string]struct {
+		modify  func(b *Bundle)
+		trusted ed25519.PublicKey
+		want    string
+	}{
+		"changed results": {
+			modify: func(b *Bundle) { b.Results = json.RawMessage(`[{"taskName":"create-pod","taskPassed":false}]`) },
+			want:   "results do not match the digest",
+		},
+		"changed manifest": {
+			modify: func(b *Bundle) { b.Manifest.Tasks[0].Digest = "sha256:0" },
+			want:   "signature does not match",
+		},
+		"unsigned": {
+			modify: func(b *Bundle) { b.Signature = nil },
+			want:   "not signed",
+		},
+		"untrusted key": {
+			modify:  func(b *Bundle) {},
+			trusted: other,
+			want:    "not with the trusted key",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := newSigned()
+			tt.modify(b)
+			if _, err := b.Verify(tt.trusted); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Verify() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	b := newSigned()
+	if err := os.WriteFile(filepath.Join(dir, "tasks", "create.yaml"), []byte("kind: Task\nchanged: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "eval.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	mismatches := b.CheckFiles(dir)
+	if len(mismatches) != 2 || mismatches[0] != "eval.yaml: missing" || !strings.HasPrefix(mismatches[1], "tasks/create.yaml: digest") {
+		t.Errorf("CheckFiles() = %v, want eval.yaml missing and tasks/create.yaml changed", mismatches)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	dir := t.TempDir()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPath := filepath.Join(dir, "key.pem")
+	pubPath := filepath.Join(dir, "key.pub.pem")
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loadedPriv, err := LoadSigningKey(privPath)
+	if err != nil {
+		t.Fatalf("LoadSigningKey() error = %v", err)
+	}
+	if !loadedPriv.Equal(priv) {
+		t.Errorf("LoadSigningKey() returned a different key")
+	}
+	loadedPub, err := LoadPublicKey(pubPath)
+	if err != nil {
+		t.Fatalf("LoadPublicKey() error = %v", err)
+	}
+	if !loadedPub.Equal(pub) {
+		t.Errorf("LoadPublicKey() returned a different key")
+	}
+
+	if _, err := LoadSigningKey(pubPath); err == nil {
+		t.Errorf("LoadSigningKey() of a public key succeeded, want an error")
+	}
+}
@@ -0,0 +1,73 @@
+package results
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// ServerCompatibility summarizes the MCP protocol versions a server negotiated
+// across the tasks of a run
+type ServerCompatibility struct {
+	Server string `json:"server"`
+	// ServerInfo are the names and versions the server reported
+	ServerInfo []string `json:"serverInfo,omitempty"`
+	// ProtocolVersions are the protocol versions the server negotiated
+	ProtocolVersions []string `json:"protocolVersions"`
+	// Accepted are the protocolVersions of the server config
+	Accepted []string `json:"accepted,omitempty"`
+	// Tasks is the number of tasks that ran with the server
+	Tasks int `json:"tasks"`
+	// IncompatibleTasks are the tasks in which the server negotiated a
+	// version outside Accepted
+	IncompatibleTasks []string `json:"incompatibleTasks,omitempty"`
+}
+
+// Compatible reports whether the server negotiated an accepted version in all
+// tasks
+func (c ServerCompatibility) Compatible() bool {
+	return len(c.IncompatibleTasks) == 0
+}
+
+// CompatibilityMatrix collects the protocol versions each server negotiated in
+// the results, sorted by server name
+func CompatibilityMatrix(results []*eval.EvalResult) []ServerCompatibility {
+	byServer := make(map[string]*ServerCompatibility)
+	for _, r := range results {
+		for _, c := range r.McpServers {
+			s, ok := byServer[c.Server]
+			if !ok {
+				s = &ServerCompatibility{Server: c.Server, ProtocolVersions: []string{}}
+				byServer[c.Server] = s
+			}
+
+			s.Tasks++
+			if c.ServerInfo != "" && !slices.Contains(s.ServerInfo, c.ServerInfo) {
+				s.ServerInfo = append(s.ServerInfo, c.ServerInfo)
+			}
+			if c.ProtocolVersion != "" && !slices.Contains(s.ProtocolVersions, c.ProtocolVersion) {
+				s.ProtocolVersions = append(s.ProtocolVersions, c.ProtocolVersion)
+			}
+			for _, v := range c.Accepted {
+				if !slices.Contains(s.Accepted, v) {
+					s.Accepted = append(s.Accepted, v)
+				}
+			}
+			if !c.Compatible {
+				s.IncompatibleTasks = append(s.IncompatibleTasks, r.TaskName)
+			}
+		}
+	}
+
+	matrix := make([]ServerCompatibility, 0, len(byServer))
+	for _, s := range byServer {
+		slices.Sort(s.ServerInfo)
+		slices.Sort(s.ProtocolVersions)
+		matrix = append(matrix, *s)
+	}
+	sort.Slice(matrix, func(i, j int) bool {
+		return matrix[i].Server < matrix[j].Server
+	})
+	return matrix
+}
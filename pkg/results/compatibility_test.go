@@ -0,0 +1,52 @@
+package results
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+)
+
+func TestCompatibilityMatrix(t *testing.T) {
+	evalResults := []*eval.EvalResult{
+		{
+			TaskName: "list-pods",
+			McpServers: []mcpproxy.Compatibility{
+				{Server: "kubernetes", ServerInfo: "kubernetes-mcp-server 0.1.0", ProtocolVersion: "2025-06-18", Accepted: []string{"2025-06-18"}, Compatible: true},
+				{Server: "github", ProtocolVersion: "2025-03-26", Compatible: true},
+			},
+		},
+		{
+			TaskName: "delete-pod",
+			McpServers: []mcpproxy.Compatibility{
+				{Server: "kubernetes", ServerInfo: "kubernetes-mcp-server 0.2.0", ProtocolVersion: "2025-03-26", Accepted: []string{"2025-06-18"}, Compatible: false},
+			},
+		},
+		{TaskName: "skipped"},
+	}
+
+	want := []ServerCompatibility{
+		{
+			Server:           "github",
+			ProtocolVersions: []string{"2025-03-26"},
+			Tasks:            1,
+		},
+		{
+			Server:            "kubernetes",
+			ServerInfo:        []string{"kubernetes-mcp-server 0.1.0", "kubernetes-mcp-server 0.2.0"},
+			ProtocolVersions:  []string{"2025-03-26", "2025-06-18"},
+			Accepted:          []string{"2025-06-18"},
+			Tasks:             2,
+			IncompatibleTasks: []string{"delete-pod"},
+		},
+	}
+
+	got := CompatibilityMatrix(evalResults)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompatibilityMatrix() = %+v, want %+v", got, want)
+	}
+	if !got[0].Compatible() || got[1].Compatible() {
+		t.Errorf("Compatible() = %v, %v, want true, false", got[0].Compatible(), got[1].Compatible())
+	}
+}
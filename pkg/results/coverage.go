@@ -0,0 +1,198 @@
+package results
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// ServerSurface lists the tools, resources, and prompts exposed by a server.
+type ServerSurface struct {
+	Tools     []string
+	Resources []string
+	// ResourceTemplates are URI templates like "file:///{path}". A read of any
+	// matching URI counts towards the template
+	ResourceTemplates []string
+	Prompts           []string
+}
+
+// Coverage reports which parts of each server were exercised by a suite.
+type Coverage struct {
+	Servers []ServerCoverage `json:"servers"`
+	Covered int              `json:"covered"`
+	Total   int              `json:"total"`
+}
+
+// ServerCoverage reports the coverage of a single server.
+type ServerCoverage struct {
+	Server    string         `json:"server"`
+	Tools     []ItemCoverage `json:"tools"`
+	Resources []ItemCoverage `json:"resources"`
+	Prompts   []ItemCoverage `json:"prompts"`
+	Covered   int            `json:"covered"`
+	Total     int            `json:"total"`
+}
+
+// ItemCoverage lists the tasks that used a single tool, resource, or prompt.
+type ItemCoverage struct {
+	Name string `json:"name"`
+	// PassingTasks are the passing tasks that successfully used the item
+	PassingTasks []string `json:"passingTasks,omitempty"`
+	// FailingTasks are the tasks that used the item but failed, or whose call
+	// to the item returned an error
+	FailingTasks []string `json:"failingTasks,omitempty"`
+}
+
+// Covered reports whether the item was used by at least one passing task.
+func (i ItemCoverage) Covered() bool {
+	return len(i.PassingTasks) > 0
+}
+
+// CalculateCoverage matches the call history of each result against the
+// surface of each server. An item is covered when a passing task called it
+// successfully; calls to items missing from surfaces are ignored.
+func CalculateCoverage(results []*eval.EvalResult, surfaces map[string]*ServerSurface) *Coverage {
+	names := make([]string, 0, len(surfaces))
+	for name := range surfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cov := &Coverage{Servers: make([]ServerCoverage, 0, len(names))}
+	for _, name := range names {
+		sc := calculateServerCoverage(name, surfaces[name], results)
+		cov.Covered += sc.Covered
+		cov.Total += sc.Total
+		cov.Servers = append(cov.Servers, sc)
+	}
+
+	return cov
+}
+
+func calculateServerCoverage(server string, surface *ServerSurface, results []*eval.EvalResult) ServerCoverage {
+	tools := newItemSet(surface.Tools, nil)
+	resources := newItemSet(surface.Resources, surface.ResourceTemplates)
+	prompts := newItemSet(surface.Prompts, nil)
+
+	for _, r := range results {
+		if r.CallHistory == nil {
+			continue
+		}
+		passed := r.TaskPassed && r.AllAssertionsPassed
+
+		for _, c := range r.CallHistory.ToolCalls {
+			if c != nil && c.ServerName == server {
+				tools.record(c.ToolName, r.TaskName, passed && c.Success)
+			}
+		}
+		for _, c := range r.CallHistory.ResourceReads {
+			if c != nil && c.ServerName == server {
+				resources.record(c.URI, r.TaskName, passed && c.Success)
+			}
+		}
+		for _, c := range r.CallHistory.PromptGets {
+			if c != nil && c.ServerName == server {
+				prompts.record(c.Name, r.TaskName, passed && c.Success)
+			}
+		}
+	}
+
+	sc := ServerCoverage{
+		Server:    server,
+		Tools:     tools.items(),
+		Resources: resources.items(),
+		Prompts:   prompts.items(),
+	}
+	for _, items := range [][]ItemCoverage{sc.Tools, sc.Resources, sc.Prompts} {
+		for _, item := range items {
+			sc.Total++
+			if item.Covered() {
+				sc.Covered++
+			}
+		}
+	}
+
+	return sc
+}
+
+// itemSet accumulates the tasks that used each item of one kind.
+type itemSet struct {
+	byName    map[string]*ItemCoverage
+	templates []*uriTemplate
+}
+
+type uriTemplate struct {
+	pattern *regexp.Regexp
+	item    *ItemCoverage
+}
+
+var templateVarPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+func newItemSet(names, templates []string) *itemSet {
+	s := &itemSet{byName: make(map[string]*ItemCoverage, len(names)+len(templates))}
+	for _, name := range names {
+		s.byName[name] = &ItemCoverage{Name: name}
+	}
+
+	for _, tmpl := range templates {
+		item := &ItemCoverage{Name: tmpl}
+		s.byName[tmpl] = item
+
+		// Each {var} matches any non-empty text, everything else literally
+		var sb strings.Builder
+		sb.WriteString("^")
+		last := 0
+		for _, loc := range templateVarPattern.FindAllStringIndex(tmpl, -1) {
+			sb.WriteString(regexp.QuoteMeta(tmpl[last:loc[0]]))
+			sb.WriteString(".+")
+			last = loc[1]
+		}
+		sb.WriteString(regexp.QuoteMeta(tmpl[last:]))
+		sb.WriteString("$")
+
+		s.templates = append(s.templates, &uriTemplate{
+			pattern: regexp.MustCompile(sb.String()),
+			item:    item,
+		})
+	}
+
+	return s
+}
+
+func (s *itemSet) record(name, taskName string, passed bool) {
+	item, ok := s.byName[name]
+	if !ok {
+		for _, t := range s.templates {
+			if t.pattern.MatchString(name) {
+				item, ok = t.item, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return
+	}
+
+	list := &item.FailingTasks
+	if passed {
+		list = &item.PassingTasks
+	}
+	if !slices.Contains(*list, taskName) {
+		*list = append(*list, taskName)
+	}
+}
+
+func (s *itemSet) items() []ItemCoverage {
+	items := make([]ItemCoverage, 0, len(s.byName))
+	for _, item := range s.byName {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items
+}
@@ -0,0 +1,94 @@
+package results
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+)
+
+func toolCall(server, name string, success bool) *mcpproxy.ToolCall {
+	return &mcpproxy.ToolCall{
+		CallRecord: mcpproxy.CallRecord{ServerName: server, Success: success},
+		ToolName:   name,
+	}
+}
+
+func TestCalculateCoverage(t *testing.T) {
+	evalResults := []*eval.EvalResult{
+		{
+			TaskName:            "list-pods",
+			TaskPassed:          true,
+			AllAssertionsPassed: true,
+			CallHistory: &mcpproxy.CallHistory{
+				ToolCalls: []*mcpproxy.ToolCall{
+					toolCall("kubernetes", "pods_list", true),
+					toolCall("kubernetes", "pods_get", false),
+					toolCall("other", "pods_delete", true),
+				},
+				ResourceReads: []*mcpproxy.ResourceRead{{
+					CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes", Success: true},
+					URI:        "k8s://namespaces/default/pods/web",
+				}},
+			},
+		},
+		{
+			TaskName:   "delete-pod",
+			TaskPassed: false,
+			CallHistory: &mcpproxy.CallHistory{
+				ToolCalls: []*mcpproxy.ToolCall{
+					toolCall("kubernetes", "pods_delete", true),
+					toolCall("kubernetes", "pods_list", true),
+				},
+			},
+		},
+		{
+			TaskName: "no-history",
+		},
+	}
+
+	surfaces := map[string]*ServerSurface{
+		"kubernetes": {
+			Tools:             []string{"pods_list", "pods_get", "pods_delete", "pods_exec"},
+			ResourceTemplates: []string{"k8s://namespaces/{namespace}/pods/{name}"},
+			Prompts:           []string{"debug_pod"},
+		},
+	}
+
+	cov := CalculateCoverage(evalResults, surfaces)
+
+	if len(cov.Servers) != 1 {
+		t.Fatalf("expected 1 server, got %d", len(cov.Servers))
+	}
+	sc := cov.Servers[0]
+
+	wantTools := []ItemCoverage{
+		{Name: "pods_delete", FailingTasks: []string{"delete-pod"}},
+		{Name: "pods_exec"},
+		{Name: "pods_get", FailingTasks: []string{"list-pods"}},
+		{Name: "pods_list", PassingTasks: []string{"list-pods"}, FailingTasks: []string{"delete-pod"}},
+	}
+	if !reflect.DeepEqual(sc.Tools, wantTools) {
+		t.Errorf("Tools = %+v, want %+v", sc.Tools, wantTools)
+	}
+
+	wantResources := []ItemCoverage{
+		{Name: "k8s://namespaces/{namespace}/pods/{name}", PassingTasks: []string{"list-pods"}},
+	}
+	if !reflect.DeepEqual(sc.Resources, wantResources) {
+		t.Errorf("Resources = %+v, want %+v", sc.Resources, wantResources)
+	}
+
+	wantPrompts := []ItemCoverage{{Name: "debug_pod"}}
+	if !reflect.DeepEqual(sc.Prompts, wantPrompts) {
+		t.Errorf("Prompts = %+v, want %+v", sc.Prompts, wantPrompts)
+	}
+
+	if sc.Covered != 2 || sc.Total != 6 {
+		t.Errorf("server coverage = %d/%d, want 2/6", sc.Covered, sc.Total)
+	}
+	if cov.Covered != 2 || cov.Total != 6 {
+		t.Errorf("total coverage = %d/%d, want 2/6", cov.Covered, cov.Total)
+	}
+}
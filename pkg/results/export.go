@@ -0,0 +1,189 @@
+package results
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatParquet = "parquet"
+)
+
+// ExportRow is the flattened, per-task view of a result used for exports.
+type ExportRow struct {
+	Task       string `parquet:"task"`
+	Path       string `parquet:"path"`
+	Difficulty string `parquet:"difficulty"`
+
+	// Passed is true when the task passed and all of its assertions passed
+	Passed bool `parquet:"passed"`
+	// Status also tells skipped tasks and expected failures apart
+	Status           string `parquet:"status"`
+	TaskPassed       bool   `parquet:"task_passed"`
+	AssertionsPassed int    `parquet:"assertions_passed"`
+	AssertionsTotal  int    `parquet:"assertions_total"`
+	// Score is the fraction of the assertions that passed, and nil for tasks
+	// without assertions
+	Score *float64 `parquet:"score,optional"`
+
+	// Durations are in seconds, and zero for results that predate timing
+	DurationSeconds      float64 `parquet:"duration_seconds"`
+	AgentDurationSeconds float64 `parquet:"agent_duration_seconds"`
+
+	ToolCalls      int `parquet:"tool_calls"`
+	ToolCallErrors int `parquet:"tool_call_errors"`
+	ResourceReads  int `parquet:"resource_reads"`
+	PromptGets     int `parquet:"prompt_gets"`
+	// ContextTokens is the estimated number of tokens the tool results added
+	// to the context of the agent
+	ContextTokens int `parquet:"context_tokens"`
+
+	AgentError     bool   `parquet:"agent_error"`
+	JudgeCategory  string `parquet:"judge_category"`
+	FailureCode    string `parquet:"failure_code"`
+	FailureReason  string `parquet:"failure_reason"`
+	CleanupFailure string `parquet:"cleanup_failure"`
+
+	Labels map[string]string `parquet:"labels"`
+}
+
+// ExportRows flattens results into one row per task.
+func ExportRows(results []*eval.EvalResult) []ExportRow {
+	rows := make([]ExportRow, 0, len(results))
+	for _, r := range results {
+		row := ExportRow{
+			Task:             r.TaskName,
+			Path:             r.TaskPath,
+			Difficulty:       r.Difficulty,
+			Passed:           r.TaskPassed && r.AllAssertionsPassed,
+			Status:           string(r.Status()),
+			TaskPassed:       r.TaskPassed,
+			AssertionsPassed: PassedAssertions(r),
+			AssertionsTotal:  TotalAssertions(r),
+			AgentError:       r.AgentExecutionError,
+			JudgeCategory:    JudgeFailureCategory(r),
+			FailureCode:      string(FailureCode(r)),
+			FailureReason:    FailureReason(r),
+			CleanupFailure:   CleanupFailure(r),
+			Labels:           r.Labels,
+		}
+
+		if score, ok := Score(r); ok {
+			row.Score = &score
+		}
+		if r.ContextUsage != nil {
+			row.ContextTokens = r.ContextUsage.TotalTokens
+		}
+
+		if r.Timing != nil {
+			row.DurationSeconds = time.Duration(r.Timing.Total).Seconds()
+			row.AgentDurationSeconds = time.Duration(r.Timing.Agent).Seconds()
+		}
+
+		if r.CallHistory != nil {
+			row.ToolCalls = len(r.CallHistory.ToolCalls)
+			for _, c := range r.CallHistory.ToolCalls {
+				if c != nil && !c.Success {
+					row.ToolCallErrors++
+				}
+			}
+			row.ResourceReads = len(r.CallHistory.ResourceReads)
+			row.PromptGets = len(r.CallHistory.PromptGets)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// Export writes results to w in the given format.
+func Export(w io.Writer, format string, results []*eval.EvalResult) error {
+	rows := ExportRows(results)
+
+	switch format {
+	case ExportFormatCSV:
+		return writeCSV(w, rows)
+	case ExportFormatParquet:
+		return parquet.Write(w, rows)
+	default:
+		return fmt.Errorf("unknown export format %q: must be one of %s, %s", format, ExportFormatCSV, ExportFormatParquet)
+	}
+}
+
+// writeCSV writes rows with one "label.<key>" column per label key seen in any row.
+func writeCSV(w io.Writer, rows []ExportRow) error {
+	labelKeys := []string{}
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for k := range row.Labels {
+			if !seen[k] {
+				seen[k] = true
+				labelKeys = append(labelKeys, k)
+			}
+		}
+	}
+	sort.Strings(labelKeys)
+
+	header := []string{
+		"task", "path", "difficulty",
+		"passed", "status", "task_passed", "assertions_passed", "assertions_total", "score",
+		"duration_seconds", "agent_duration_seconds",
+		"tool_calls", "tool_call_errors", "resource_reads", "prompt_gets", "context_tokens",
+		"agent_error", "judge_category", "failure_code", "failure_reason", "cleanup_failure",
+	}
+	for _, k := range labelKeys {
+		header = append(header, "label."+k)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		score := ""
+		if row.Score != nil {
+			score = strconv.FormatFloat(*row.Score, 'f', -1, 64)
+		}
+		record := []string{
+			row.Task, row.Path, row.Difficulty,
+			strconv.FormatBool(row.Passed),
+			row.Status,
+			strconv.FormatBool(row.TaskPassed),
+			strconv.Itoa(row.AssertionsPassed),
+			strconv.Itoa(row.AssertionsTotal),
+			score,
+			strconv.FormatFloat(row.DurationSeconds, 'f', -1, 64),
+			strconv.FormatFloat(row.AgentDurationSeconds, 'f', -1, 64),
+			strconv.Itoa(row.ToolCalls),
+			strconv.Itoa(row.ToolCallErrors),
+			strconv.Itoa(row.ResourceReads),
+			strconv.Itoa(row.PromptGets),
+			strconv.Itoa(row.ContextTokens),
+			strconv.FormatBool(row.AgentError),
+			row.JudgeCategory,
+			row.FailureCode,
+			row.FailureReason,
+			row.CleanupFailure,
+		}
+		for _, k := range labelKeys {
+			record = append(record, row.Labels[k])
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,132 @@
+package results
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestExportRows(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[0].Labels = map[string]string{"suite": "kubernetes"}
+	evalResults[0].Timing = &eval.TaskTiming{Total: util.Duration(90 * time.Second), Agent: util.Duration(1500 * time.Millisecond)}
+	evalResults[0].CallHistory = &mcpproxy.CallHistory{
+		ToolCalls: []*mcpproxy.ToolCall{
+			{CallRecord: mcpproxy.CallRecord{Success: true}},
+			{CallRecord: mcpproxy.CallRecord{Success: false}},
+		},
+		ResourceReads: []*mcpproxy.ResourceRead{{}},
+	}
+	evalResults[0].ContextUsage = &eval.ContextUsage{TotalTokens: 1200}
+
+	rows := ExportRows(evalResults)
+
+	if len(rows) != 3 {
+		t.Fatalf("len(rows) = %d, want 3", len(rows))
+	}
+
+	want := ExportRow{
+		Task:                 "task-1",
+		Path:                 "/path/to/task-1",
+		Difficulty:           "easy",
+		Passed:               true,
+		Status:               "passed",
+		TaskPassed:           true,
+		AssertionsPassed:     2,
+		AssertionsTotal:      2,
+		DurationSeconds:      90,
+		AgentDurationSeconds: 1.5,
+		ToolCalls:            2,
+		ToolCallErrors:       1,
+		ResourceReads:        1,
+		ContextTokens:        1200,
+		Labels:               map[string]string{"suite": "kubernetes"},
+	}
+	score := 1.0
+	want.Score = &score
+	if !reflect.DeepEqual(rows[0], want) {
+		t.Errorf("rows[0] = %+v, want %+v", rows[0], want)
+	}
+
+	if rows[2].Passed || rows[2].Status != "failed" || rows[2].FailureReason != "verification failed" {
+		t.Errorf("rows[2] = %+v, want failed task with reason", rows[2])
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[1].Labels = map[string]string{"suite": "kubernetes", "area": "pods"}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, ExportFormatCSV, evalResults); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+
+	if len(records) != 4 {
+		t.Fatalf("len(records) = %d, want 4", len(records))
+	}
+
+	header := records[0]
+	if header[0] != "task" || header[len(header)-2] != "label.area" || header[len(header)-1] != "label.suite" {
+		t.Errorf("unexpected header: %v", header)
+	}
+	if records[2][0] != "task-2" || records[2][len(header)-1] != "kubernetes" {
+		t.Errorf("unexpected row: %v", records[2])
+	}
+	column := func(name string) int {
+		for i, h := range header {
+			if h == name {
+				return i
+			}
+		}
+		t.Fatalf("no column %q in header %v", name, header)
+		return -1
+	}
+	if records[1][column("score")] != "1" || records[1][column("context_tokens")] != "0" {
+		t.Errorf("unexpected score and context tokens: %v", records[1])
+	}
+	if records[1][len(header)-1] != "" {
+		t.Errorf("expected empty label for task without labels, got %q", records[1][len(header)-1])
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[0].Labels = map[string]string{"suite": "kubernetes"}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, ExportFormatParquet, evalResults); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	rows, err := parquet.Read[ExportRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to read parquet: %v", err)
+	}
+
+	if !reflect.DeepEqual(rows[0], ExportRows(evalResults)[0]) {
+		t.Errorf("rows[0] = %+v, want %+v", rows[0], ExportRows(evalResults)[0])
+	}
+	if len(rows) != 3 {
+		t.Errorf("len(rows) = %d, want 3", len(rows))
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, "xlsx", sampleResults()); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
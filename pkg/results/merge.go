@@ -0,0 +1,113 @@
+package results
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// Policies for a task that is in more than one of the merged results files
+const (
+	// DuplicateError fails the merge
+	DuplicateError = "error"
+	// DuplicateKeepLatest keeps the task of the file given last
+	DuplicateKeepLatest = "keep-latest"
+	// DuplicateKeepBest keeps the task with the best outcome, preferring
+	// the file given last among equally good outcomes
+	DuplicateKeepBest = "keep-best"
+)
+
+// DuplicatePolicies lists the supported policies for duplicate tasks
+var DuplicatePolicies = []string{DuplicateError, DuplicateKeepLatest, DuplicateKeepBest}
+
+// mergedTask holds the results of a task name from one file. A single run may
+// have several tasks with the same name, which are kept or replaced together.
+type mergedTask struct {
+	file    int
+	results []*eval.EvalResult
+}
+
+// Merge reads the results of several runs of a suite, such as the shards of a
+// sharded run or the runs of a matrix, and combines them into one set of
+// results. Tasks are kept in the order they first appear in. A task that is
+// in more than one file is handled according to policy, and the names of
+// such tasks are returned.
+func Merge(policy string, paths ...string) ([]*eval.EvalResult, []string, error) {
+	switch policy {
+	case DuplicateError, DuplicateKeepLatest, DuplicateKeepBest:
+	default:
+		return nil, nil, fmt.Errorf("unknown duplicate policy %q: must be one of %s", policy, strings.Join(DuplicatePolicies, ", "))
+	}
+
+	var order []string
+	var duplicates []string
+	tasks := make(map[string]*mergedTask)
+
+	for i, path := range paths {
+		results, err := Load(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+
+		var names []string
+		byName := make(map[string][]*eval.EvalResult)
+		for _, r := range results {
+			if _, ok := byName[r.TaskName]; !ok {
+				names = append(names, r.TaskName)
+			}
+			byName[r.TaskName] = append(byName[r.TaskName], r)
+		}
+
+		for _, name := range names {
+			task := &mergedTask{file: i, results: byName[name]}
+
+			prev, ok := tasks[name]
+			if !ok {
+				order = append(order, name)
+				tasks[name] = task
+				continue
+			}
+
+			switch policy {
+			case DuplicateError:
+				return nil, nil, fmt.Errorf("task %s is in both %s and %s", name, paths[prev.file], path)
+			case DuplicateKeepLatest:
+				tasks[name] = task
+			case DuplicateKeepBest:
+				if outcomeRank(task.results) >= outcomeRank(prev.results) {
+					tasks[name] = task
+				}
+			}
+			if !slices.Contains(duplicates, name) {
+				duplicates = append(duplicates, name)
+			}
+		}
+	}
+
+	var merged []*eval.EvalResult
+	for _, name := range order {
+		merged = append(merged, tasks[name].results...)
+	}
+
+	return merged, duplicates, nil
+}
+
+// outcomeRank ranks the outcome of the results of a task from 0 (skipped) to
+// 3 (passed with all assertions). Of several tasks with the same name, the
+// worst one counts.
+func outcomeRank(results []*eval.EvalResult) int {
+	rank := 3
+	for _, r := range results {
+		switch {
+		case r.SkipReason != "":
+			rank = min(rank, 0)
+		case !r.TaskPassed:
+			rank = min(rank, 1)
+		case !r.AllAssertionsPassed:
+			rank = min(rank, 2)
+		}
+	}
+	return rank
+}
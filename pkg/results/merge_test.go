@@ -0,0 +1,128 @@
+package results
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// writeRuns saves each run to a results file and returns their paths
+func writeRuns(t *testing.T, runs ...[]*eval.EvalResult) []string {
+	t.Helper()
+
+	var paths []string
+	for i, run := range runs {
+		path := filepath.Join(t.TempDir(), OutputPath(string(rune('a'+i)), LayoutFile))
+		if err := Save(run, path, LayoutFile); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestMerge(t *testing.T) {
+	passed := func(name string) *eval.EvalResult {
+		return &eval.EvalResult{TaskName: name, TaskPassed: true, AllAssertionsPassed: true}
+	}
+	failed := func(name string) *eval.EvalResult {
+		return &eval.EvalResult{TaskName: name}
+	}
+
+	tests := map[string]struct {
+		runs               [][]*eval.EvalResult
+		policy             string
+		expected           []string
+		expectedPassed     []bool
+		expectedDuplicates []string
+		errContains        string
+	}{
+		"disjoint shards": {
+			runs:           [][]*eval.EvalResult{{passed("task-1"), failed("task-3")}, {passed("task-2")}},
+			policy:         DuplicateError,
+			expected:       []string{"task-1", "task-3", "task-2"},
+			expectedPassed: []bool{true, false, true},
+		},
+		"duplicate names in one run": {
+			runs:           [][]*eval.EvalResult{{passed("task-1"), failed("task-1")}, {passed("task-2")}},
+			policy:         DuplicateError,
+			expected:       []string{"task-1", "task-1", "task-2"},
+			expectedPassed: []bool{true, false, true},
+		},
+		"duplicate is an error": {
+			runs:        [][]*eval.EvalResult{{failed("task-1")}, {passed("task-1")}},
+			policy:      DuplicateError,
+			errContains: "task task-1 is in both",
+		},
+		"keep latest": {
+			runs:               [][]*eval.EvalResult{{passed("task-1"), passed("task-2")}, {failed("task-1")}},
+			policy:             DuplicateKeepLatest,
+			expected:           []string{"task-1", "task-2"},
+			expectedPassed:     []bool{false, true},
+			expectedDuplicates: []string{"task-1"},
+		},
+		"keep best": {
+			runs:               [][]*eval.EvalResult{{failed("task-1"), passed("task-2")}, {passed("task-1")}, {failed("task-1"), failed("task-2")}},
+			policy:             DuplicateKeepBest,
+			expected:           []string{"task-1", "task-2"},
+			expectedPassed:     []bool{true, true},
+			expectedDuplicates: []string{"task-1", "task-2"},
+		},
+		"unknown policy": {
+			runs:        [][]*eval.EvalResult{{passed("task-1")}},
+			policy:      "keep-first",
+			errContains: `unknown duplicate policy "keep-first"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			merged, duplicates, err := Merge(tc.policy, writeRuns(t, tc.runs...)...)
+			if tc.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+					t.Fatalf("Merge() error = %v, want %s", err, tc.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+
+			var names []string
+			var passed []bool
+			for _, r := range merged {
+				names = append(names, r.TaskName)
+				passed = append(passed, r.TaskPassed)
+			}
+			if !slices.Equal(names, tc.expected) || !slices.Equal(passed, tc.expectedPassed) {
+				t.Errorf("merged = %v (passed %v), want %v (passed %v)", names, passed, tc.expected, tc.expectedPassed)
+			}
+			if !slices.Equal(duplicates, tc.expectedDuplicates) {
+				t.Errorf("duplicates = %v, want %v", duplicates, tc.expectedDuplicates)
+			}
+		})
+	}
+}
+
+func TestMergeLayouts(t *testing.T) {
+	dir := t.TempDir()
+	shard1 := filepath.Join(dir, "shard-1.json.gz")
+	shard2 := filepath.Join(dir, "shard-2")
+	if err := Save([]*eval.EvalResult{{TaskName: "task-1"}}, shard1, LayoutGzip); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := Save([]*eval.EvalResult{{TaskName: "task-2"}}, shard2, LayoutDir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	merged, _, err := Merge(DuplicateError, shard1, shard2)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(merged) != 2 {
+		t.Errorf("merged %d tasks, want 2", len(merged))
+	}
+}
@@ -0,0 +1,196 @@
+package results
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// Unspecified is the group of results without a value for the grouping key,
+// such as tasks without a difficulty
+const Unspecified = "unspecified"
+
+// Statuses lists the statuses a query can select
+var Statuses = []eval.TaskStatus{
+	eval.TaskStatusPassed,
+	eval.TaskStatusFailed,
+	eval.TaskStatusSkipped,
+	eval.TaskStatusExpectedFailure,
+	eval.TaskStatusUnexpectedPass,
+	eval.TaskStatusNotRun,
+}
+
+// Query selects results by task name, labels, difficulty, and status. The
+// zero value selects every result, and each field that is set narrows the
+// selection down.
+type Query struct {
+	// Task selects the tasks whose name contains it, ignoring case
+	Task string
+	// Labels selects the tasks that have all of these labels
+	Labels map[string]string
+	// Difficulties selects the tasks of any of these difficulties
+	Difficulties []string
+	// Statuses selects the tasks with any of these statuses
+	Statuses []eval.TaskStatus
+}
+
+// ParseLabels parses label selectors of the form key=value
+func ParseLabels(selectors []string) (map[string]string, error) {
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(selectors))
+	for _, selector := range selectors {
+		key, value, ok := strings.Cut(selector, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid label selector %q, expected key=value", selector)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// ParseStatuses parses task statuses, such as failed or notRun
+func ParseStatuses(values []string) ([]eval.TaskStatus, error) {
+	statuses := make([]eval.TaskStatus, 0, len(values))
+	for _, v := range values {
+		status := eval.TaskStatus(strings.TrimSpace(v))
+		if !slices.Contains(Statuses, status) {
+			names := make([]string, len(Statuses))
+			for i, s := range Statuses {
+				names[i] = string(s)
+			}
+			return nil, fmt.Errorf("unknown status %q (must be one of %s)", v, strings.Join(names, ", "))
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Match returns whether the query selects a result
+func (q Query) Match(r *eval.EvalResult) bool {
+	if q.Task != "" && !strings.Contains(strings.ToLower(r.TaskName), strings.ToLower(q.Task)) {
+		return false
+	}
+	for key, value := range q.Labels {
+		if r.Labels[key] != value {
+			return false
+		}
+	}
+	if len(q.Difficulties) > 0 && !slices.Contains(q.Difficulties, r.Difficulty) {
+		return false
+	}
+	if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, r.Status()) {
+		return false
+	}
+	return true
+}
+
+// Select returns the results the query selects, in order
+func Select(results []*eval.EvalResult, q Query) []*eval.EvalResult {
+	selected := make([]*eval.EvalResult, 0, len(results))
+	for _, r := range results {
+		if q.Match(r) {
+			selected = append(selected, r)
+		}
+	}
+	return selected
+}
+
+// LoadQuery reads the results the query selects, like Load followed by
+// Select. Of a results directory, only the files of the tasks whose name
+// matches are read.
+func LoadQuery(path string, q Query) ([]*eval.EvalResult, error) {
+	results, err := LoadFiltered(path, q.Task)
+	if err != nil {
+		return nil, err
+	}
+	return Select(results, q), nil
+}
+
+// Group is the results that share the value of a grouping key
+type Group struct {
+	Key     string
+	Results []*eval.EvalResult
+}
+
+// GroupBy groups the results by the value key returns for them, keeping the
+// order of the results within a group. Groups are sorted by key.
+func GroupBy(results []*eval.EvalResult, key func(*eval.EvalResult) string) []Group {
+	byKey := make(map[string][]*eval.EvalResult)
+	for _, r := range results {
+		k := key(r)
+		byKey[k] = append(byKey[k], r)
+	}
+
+	groups := make([]Group, 0, len(byKey))
+	for _, k := range slices.Sorted(maps.Keys(byKey)) {
+		groups = append(groups, Group{Key: k, Results: byKey[k]})
+	}
+	return groups
+}
+
+// ByDifficulty is a grouping key of the task difficulty, or Unspecified
+func ByDifficulty(r *eval.EvalResult) string {
+	if r.Difficulty == "" {
+		return Unspecified
+	}
+	return r.Difficulty
+}
+
+// ByStatus is a grouping key of the task status
+func ByStatus(r *eval.EvalResult) string {
+	return string(r.Status())
+}
+
+// ByAgent is a grouping key of the agent that ran the task, or Unspecified
+func ByAgent(r *eval.EvalResult) string {
+	if r.Agent == "" {
+		return Unspecified
+	}
+	return r.Agent
+}
+
+// ByFailureCode is a grouping key of the reason code of the first failed
+// assertion of a task, or Unspecified for tasks without one
+func ByFailureCode(r *eval.EvalResult) string {
+	if code := FailureCode(r); code != "" {
+		return string(code)
+	}
+	return Unspecified
+}
+
+// ByLabel returns a grouping key of the value of a label, or Unspecified for
+// tasks without the label
+func ByLabel(label string) func(*eval.EvalResult) string {
+	return func(r *eval.EvalResult) string {
+		if v, ok := r.Labels[label]; ok && v != "" {
+			return v
+		}
+		return Unspecified
+	}
+}
+
+// GroupStats computes the statistics of each group
+func GroupStats(resultsFile string, groups []Group) map[string]Stats {
+	stats := make(map[string]Stats, len(groups))
+	for _, g := range groups {
+		stats[g.Key] = CalculateStats(resultsFile, g.Results)
+	}
+	return stats
+}
+
+// IndexByTaskName indexes results by task name. Of several results with the
+// same name, the last one is kept.
+func IndexByTaskName(results []*eval.EvalResult) map[string]*eval.EvalResult {
+	byName := make(map[string]*eval.EvalResult, len(results))
+	for _, r := range results {
+		byName[r.TaskName] = r
+	}
+	return byName
+}
@@ -0,0 +1,116 @@
+package results
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// taskNames returns the task names of results
+func taskNames(results []*eval.EvalResult) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.TaskName
+	}
+	return names
+}
+
+func labeledResults() []*eval.EvalResult {
+	results := sampleResults()
+	results[0].Labels = map[string]string{"suite": "kubernetes", "area": "pods"}
+	results[1].Labels = map[string]string{"suite": "kubernetes"}
+	results[2].Labels = map[string]string{"suite": "helm"}
+	return results
+}
+
+func TestSelect(t *testing.T) {
+	tests := map[string]struct {
+		query Query
+		want  []string
+	}{
+		"zero query":       {query: Query{}, want: []string{"task-1", "task-2", "task-3"}},
+		"task name":        {query: Query{Task: "TASK-2"}, want: []string{"task-2"}},
+		"label":            {query: Query{Labels: map[string]string{"suite": "kubernetes"}}, want: []string{"task-1", "task-2"}},
+		"all labels":       {query: Query{Labels: map[string]string{"suite": "kubernetes", "area": "pods"}}, want: []string{"task-1"}},
+		"difficulties":     {query: Query{Difficulties: []string{"easy", "hard"}}, want: []string{"task-1", "task-3"}},
+		"status":           {query: Query{Statuses: []eval.TaskStatus{eval.TaskStatusFailed}}, want: []string{"task-2", "task-3"}},
+		"label and status": {query: Query{Labels: map[string]string{"suite": "kubernetes"}, Statuses: []eval.TaskStatus{eval.TaskStatusFailed}}, want: []string{"task-2"}},
+		"nothing matches":  {query: Query{Labels: map[string]string{"suite": "none"}}, want: []string{}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := taskNames(Select(labeledResults(), tt.query))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadQueryDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	if err := Save(labeledResults(), dir, LayoutDir); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadQuery(dir, Query{Task: "task", Labels: map[string]string{"suite": "helm"}})
+	if err != nil {
+		t.Fatalf("LoadQuery() error = %v", err)
+	}
+	if names := taskNames(got); !reflect.DeepEqual(names, []string{"task-3"}) {
+		t.Errorf("LoadQuery() = %v, want task-3", names)
+	}
+}
+
+func TestParseLabelsAndStatuses(t *testing.T) {
+	labels, err := ParseLabels([]string{"suite=kubernetes", " area = pods "})
+	if err != nil {
+		t.Fatalf("ParseLabels() error = %v", err)
+	}
+	if want := map[string]string{"suite": "kubernetes", "area": "pods"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("ParseLabels() = %v, want %v", labels, want)
+	}
+	if _, err := ParseLabels([]string{"suite"}); err == nil || !strings.Contains(err.Error(), "expected key=value") {
+		t.Errorf("ParseLabels(suite) error = %v, want expected key=value", err)
+	}
+
+	statuses, err := ParseStatuses([]string{"failed", "notRun"})
+	if err != nil {
+		t.Fatalf("ParseStatuses() error = %v", err)
+	}
+	if want := []eval.TaskStatus{eval.TaskStatusFailed, eval.TaskStatusNotRun}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("ParseStatuses() = %v, want %v", statuses, want)
+	}
+	if _, err := ParseStatuses([]string{"broken"}); err == nil || !strings.Contains(err.Error(), "unknown status") {
+		t.Errorf("ParseStatuses(broken) error = %v, want unknown status", err)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	results := labeledResults()
+	results = append(results, &eval.EvalResult{TaskName: "task-4", Labels: map[string]string{"suite": "helm"}})
+
+	groups := GroupBy(results, ByLabel("area"))
+	if len(groups) != 2 || groups[0].Key != "pods" || groups[1].Key != Unspecified {
+		t.Fatalf("GroupBy(area) keys = %v, want pods and %s", groups, Unspecified)
+	}
+	if names := taskNames(groups[1].Results); !reflect.DeepEqual(names, []string{"task-2", "task-3", "task-4"}) {
+		t.Errorf("unspecified group = %v, want task-2, task-3, task-4 in order", names)
+	}
+
+	stats := GroupStats("results.json", GroupBy(results, ByLabel("suite")))
+	if stats["helm"].TasksTotal != 2 || stats["kubernetes"].TasksTotal != 2 || stats["kubernetes"].TasksPassed != 2 {
+		t.Errorf("GroupStats(suite) = %+v, want 2 helm tasks and 2 passed kubernetes tasks", stats)
+	}
+
+	var keys []string
+	for _, g := range GroupBy(results, ByDifficulty) {
+		keys = append(keys, g.Key)
+	}
+	if want := []string{"easy", "hard", "medium", Unspecified}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("GroupBy(difficulty) keys = %v, want %v", keys, want)
+	}
+}
@@ -0,0 +1,210 @@
+package results
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"sigs.k8s.io/yaml"
+)
+
+// RedactRules say what to scrub from results before they are shared
+type RedactRules struct {
+	// Rules are regular expressions whose matches are replaced, after the
+	// default safety rules
+	Rules []RedactRule `json:"rules,omitempty"`
+
+	// DisableDefaultRules redacts with Rules only
+	DisableDefaultRules bool `json:"disableDefaultRules,omitempty"`
+
+	// Fields are the names of object keys, such as password or token, whose
+	// values are replaced entirely wherever they appear. Names are matched
+	// case-insensitively.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// RedactRule replaces the matches of a regular expression
+type RedactRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	// Replace is the replacement, which may refer to groups of the pattern
+	// as $1. Defaults to [REDACTED:<name>].
+	Replace string `json:"replace,omitempty"`
+}
+
+// redactor is a compiled set of redact rules
+type redactor struct {
+	rules   []compiledRedactRule
+	fields  map[string]bool
+	matches map[string]int
+}
+
+type compiledRedactRule struct {
+	name    string
+	re      *regexp.Regexp
+	replace string
+}
+
+// LoadRedactRules reads redact rules from a YAML or JSON file
+func LoadRedactRules(path string) (*RedactRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &RedactRules{}
+	if err := yaml.UnmarshalStrict(data, rules); err != nil {
+		return nil, fmt.Errorf("invalid redact rules %s: %w", path, err)
+	}
+	if _, err := rules.compile(); err != nil {
+		return nil, fmt.Errorf("invalid redact rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+func (r *RedactRules) compile() (*redactor, error) {
+	red := &redactor{
+		fields:  make(map[string]bool),
+		matches: make(map[string]int),
+	}
+	if !r.DisableDefaultRules {
+		for _, rule := range eval.DefaultSafetyRules {
+			red.rules = append(red.rules, compiledRedactRule{
+				name:    rule.Name,
+				re:      regexp.MustCompile(rule.Pattern),
+				replace: fmt.Sprintf("[REDACTED:%s]", rule.Name),
+			})
+		}
+	}
+	for i, rule := range r.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rules[%d]: name is required", i)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: invalid pattern: %w", i, err)
+		}
+		replace := rule.Replace
+		if replace == "" {
+			replace = fmt.Sprintf("[REDACTED:%s]", rule.Name)
+		}
+		red.rules = append(red.rules, compiledRedactRule{name: rule.Name, re: re, replace: replace})
+	}
+	for _, field := range r.Fields {
+		red.fields[strings.ToLower(field)] = true
+	}
+	return red, nil
+}
+
+// Redact returns a copy of the results with the matches of the rules, or of
+// the default safety rules if rules is nil, replaced in every string,
+// including the agent output, the tool call arguments and results, the step
+// outputs, and the judge reasons, and the number of replacements of each rule.
+// Values of the redacted fields are counted under the field name. References
+// to artifact files are dropped, since the files themselves are not redacted.
+func Redact(results []*eval.EvalResult, rules *RedactRules) ([]*eval.EvalResult, map[string]int, error) {
+	if rules == nil {
+		rules = &RedactRules{}
+	}
+	red, err := rules.compile()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	redacted := make([]*eval.EvalResult, 0, len(results))
+	for _, r := range results {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode results of task %s: %w", r.TaskName, err)
+		}
+
+		value, err := decodeJSONValue(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode results of task %s: %w", r.TaskName, err)
+		}
+		value = red.value(value)
+
+		data, err = json.Marshal(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode redacted results of task %s: %w", r.TaskName, err)
+		}
+		out := &eval.EvalResult{}
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode redacted results of task %s: %w", r.TaskName, err)
+		}
+
+		out.TaskOutputFile = ""
+		out.TrafficFile = ""
+		out.JudgeTranscriptFile = ""
+		redacted = append(redacted, out)
+	}
+
+	return redacted, red.matches, nil
+}
+
+// value redacts the strings in a decoded JSON value
+func (red *redactor) value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return red.text(v)
+	case []any:
+		for i := range v {
+			v[i] = red.value(v[i])
+		}
+		return v
+	case map[string]any:
+		for k, item := range v {
+			if _, ok := item.(string); ok && red.fields[strings.ToLower(k)] {
+				v[k] = fmt.Sprintf("[REDACTED:%s]", k)
+				red.matches[k]++
+				continue
+			}
+			v[k] = red.value(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// text redacts a string. A string that holds a JSON object or array, such as
+// the text content of a tool result, is redacted field by field.
+func (red *redactor) text(s string) string {
+	if trimmed := strings.TrimSpace(s); len(red.fields) > 0 && (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) {
+		if nested, err := decodeJSONValue([]byte(s)); err == nil {
+			if data, err := json.Marshal(red.value(nested)); err == nil {
+				return string(data)
+			}
+		}
+	}
+
+	for _, rule := range red.rules {
+		n := len(rule.re.FindAllStringIndex(s, -1))
+		if n == 0 {
+			continue
+		}
+		red.matches[rule.name] += n
+		s = rule.re.ReplaceAllString(s, rule.replace)
+	}
+	return s
+}
+
+// decodeJSONValue decodes JSON keeping numbers as they are written, so that
+// large integers survive the round trip
+func decodeJSONValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return value, nil
+}
@@ -0,0 +1,130 @@
+package results
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRedact(t *testing.T) {
+	original := []*eval.EvalResult{{
+		TaskName:        "create-pod",
+		TaskOutput:      "Created the pod on api.corp.example.com, contact admin@example.com",
+		TaskOutputFile:  "/tmp/artifacts/create-pod/output.txt",
+		TaskJudgeReason: "The answer names Acme Corp",
+		TrafficFile:     "/tmp/artifacts/create-pod/traffic.har",
+		CallHistory: &mcpproxy.CallHistory{
+			ToolCalls: []*mcpproxy.ToolCall{{
+				ToolName: "pods_create",
+				Request: &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{
+					Name:      "pods_create",
+					Arguments: json.RawMessage(`{"host":"db.corp.example.com","password":"hunter2","replicas":12345678901234567}`),
+				}},
+				Result: &mcp.CallToolResult{Content: []mcp.Content{
+					&mcp.TextContent{Text: `{"token":"abc","owner":"Acme Corp"}`},
+				}},
+			}},
+		},
+		VerifyOutput: &task.PhaseOutput{Steps: []*steps.StepOutput{{
+			Type:    "script",
+			Message: "reached db.corp.example.com",
+		}}},
+	}}
+
+	rules := &RedactRules{
+		Rules: []RedactRule{
+			{Name: "internal-host", Pattern: `\b[a-z0-9-]+\.corp\.example\.com\b`},
+			{Name: "customer", Pattern: `Acme (Corp|Inc)`, Replace: "CUSTOMER"},
+		},
+		Fields: []string{"Password", "token"},
+	}
+
+	redacted, matches, err := Redact(original, rules)
+	if err != nil {
+		t.Fatalf("Redact() error = %v", err)
+	}
+
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, secret := range []string{"corp.example.com", "admin@example.com", "hunter2", `\"abc\"`, "Acme"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("redacted results contain %q: %s", secret, text)
+		}
+	}
+	for _, want := range []string{"[REDACTED:internal-host]", "[REDACTED:email]", "[REDACTED:password]", "CUSTOMER", "12345678901234567"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("redacted results do not contain %q: %s", want, text)
+		}
+	}
+
+	wantMatches := map[string]int{"internal-host": 3, "email": 1, "customer": 2, "password": 1, "token": 1}
+	for name, want := range wantMatches {
+		if matches[name] != want {
+			t.Errorf("matches[%s] = %d, want %d", name, matches[name], want)
+		}
+	}
+
+	if redacted[0].TaskOutputFile != "" || redacted[0].TrafficFile != "" {
+		t.Errorf("artifact files = %q, %q, want them dropped", redacted[0].TaskOutputFile, redacted[0].TrafficFile)
+	}
+	if redacted[0].TaskName != "create-pod" || redacted[0].CallHistory.ToolCalls[0].ToolName != "pods_create" {
+		t.Errorf("redacted results lost fields: %s", text)
+	}
+	if !strings.Contains(original[0].TaskOutput, "admin@example.com") {
+		t.Errorf("Redact() changed the original results")
+	}
+}
+
+func TestRedactDisableDefaultRules(t *testing.T) {
+	original := []*eval.EvalResult{{TaskName: "task-1", TaskOutput: "mail admin@example.com"}}
+
+	redacted, matches, err := Redact(original, &RedactRules{DisableDefaultRules: true})
+	if err != nil {
+		t.Fatalf("Redact() error = %v", err)
+	}
+	if redacted[0].TaskOutput != "mail admin@example.com" || len(matches) != 0 {
+		t.Errorf("TaskOutput = %q, matches = %v, want nothing redacted", redacted[0].TaskOutput, matches)
+	}
+}
+
+func TestLoadRedactRules(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	if err := os.WriteFile(valid, []byte("rules:\n  - name: host\n    pattern: 'example\\.com'\nfields: [token]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRedactRules(valid)
+	if err != nil {
+		t.Fatalf("LoadRedactRules() error = %v", err)
+	}
+	if len(rules.Rules) != 1 || rules.Rules[0].Name != "host" || len(rules.Fields) != 1 {
+		t.Errorf("rules = %+v, want the host rule and the token field", rules)
+	}
+
+	tests := map[string]string{
+		"rules:\n  - pattern: 'x'\n":                    "rules[0]: name is required",
+		"rules:\n  - name: bad\n    pattern: '('\n":     "rules[0]: invalid pattern",
+		"rules:\n  - name: x\n    pattern: x\nextra: 1": "unknown field",
+	}
+	for content, want := range tests {
+		path := filepath.Join(dir, "invalid.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRedactRules(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadRedactRules(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
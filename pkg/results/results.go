@@ -1,13 +1,40 @@
-// Package results provides utilities for loading, filtering, and analyzing evaluation results.
+// Package results loads, queries, and analyzes the results of evaluation runs.
+// It is the API the view, summary, diff, and verify commands are built on,
+// and can be used the same way by Go programs that build their own reports:
+//
+//	all, err := results.Load("mcpchecker-suite-out.json")
+//	if err != nil {
+//		return err
+//	}
+//	failed := results.Select(all, results.Query{
+//		Labels:   map[string]string{"suite": "kubernetes"},
+//		Statuses: []eval.TaskStatus{eval.TaskStatusFailed},
+//	})
+//	fmt.Printf("%d kubernetes tasks failed\n", len(failed))
+//	for _, g := range results.GroupBy(all, results.ByLabel("area")) {
+//		stats := results.CalculateStats("", g.Results)
+//		fmt.Printf("%s: %d/%d passed\n", g.Key, stats.TasksPassed, stats.TasksTotal)
+//	}
+//
+// Load reads results in any of the Layouts written by Save. Query selects
+// results by task name, labels, difficulty, and status, GroupBy groups them
+// by a key such as ByDifficulty or ByLabel, and CalculateStats computes the
+// pass rates of a set of results the way check and verify count them.
 package results
 
 import (
-	"encoding/json"
 	"fmt"
+	"maps"
+	"math"
 	"os"
-	"strings"
+	"regexp"
+	"slices"
+	"sort"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 // Stats holds computed statistics from evaluation results.
@@ -19,47 +46,108 @@ type Stats struct {
 	AssertionsTotal   int     `json:"assertionsTotal"`
 	AssertionsPassed  int     `json:"assertionsPassed"`
 	AssertionPassRate float64 `json:"assertionPassRate"`
+	CleanupFailures   int     `json:"cleanupFailures"`
+
+	// JudgeFailures counts the tasks failed by the LLM judge, by failure category
+	JudgeFailures map[string]int `json:"judgeFailures,omitempty"`
+
+	// Quarantined tasks are not included in any of the counts above
+	TasksQuarantined  int `json:"tasksQuarantined,omitempty"`
+	QuarantinedFailed int `json:"quarantinedFailed,omitempty"`
+
+	// Skipped tasks and tasks expected to fail are not included in any of
+	// the counts above either
+	TasksSkipped          int `json:"tasksSkipped,omitempty"`
+	TasksExpectedFailed   int `json:"tasksExpectedFailed,omitempty"`
+	TasksUnexpectedPassed int `json:"tasksUnexpectedPassed,omitempty"`
+	// TasksNotRun are the tasks left when the run stopped after too many
+	// failures
+	TasksNotRun int `json:"tasksNotRun,omitempty"`
+
+	// DurationP95 is the 95th percentile of the total duration of the counted
+	// tasks. Results that predate timing are left out.
+	DurationP95 util.Duration `json:"durationP95,omitempty"`
+
+	// MeanScore is the mean score of the counted tasks with assertions, of
+	// which there are ScoredTasks
+	MeanScore   float64 `json:"meanScore,omitempty"`
+	ScoredTasks int     `json:"scoredTasks,omitempty"`
+
+	// ContextTokens is the estimated number of tokens the tool results of the
+	// counted tasks added to the context of the agent
+	ContextTokens int `json:"contextTokens,omitempty"`
 }
 
-// Load reads a JSON results file and returns the parsed evaluations.
+// Load reads results written in any layout: a JSON file, a gzip-compressed
+// JSON file, or a directory with an index and one file per task.
 func Load(path string) ([]*eval.EvalResult, error) {
-	data, err := os.ReadFile(path)
+	info, err := os.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read results file: %w", err)
 	}
 
+	if info.IsDir() {
+		return loadDir(path, "")
+	}
+
 	var results []*eval.EvalResult
-	if err := json.Unmarshal(data, &results); err != nil {
-		return nil, fmt.Errorf("failed to parse results JSON: %w", err)
+	if err := readJSONFile(path, &results); err != nil {
+		return nil, err
 	}
 
 	return results, nil
 }
 
-// Filter returns the subset of results whose task names contain the filter substring.
+// ApplyQuarantine marks the results of quarantined tasks.
+func ApplyQuarantine(results []*eval.EvalResult, quarantine *eval.Quarantine) {
+	for _, r := range results {
+		if quarantine.Contains(r.TaskName) {
+			r.Quarantined = true
+		}
+	}
+}
+
+// Filter returns the subset of results whose task names contain the filter
+// substring, ignoring case. It is Select with a query of the task name only.
 func Filter(results []*eval.EvalResult, filter string) []*eval.EvalResult {
 	if filter == "" {
 		return results
 	}
-
-	filter = strings.ToLower(filter)
-	filtered := make([]*eval.EvalResult, 0, len(results))
-	for _, r := range results {
-		if strings.Contains(strings.ToLower(r.TaskName), filter) {
-			filtered = append(filtered, r)
-		}
-	}
-	return filtered
+	return Select(results, Query{Task: filter})
 }
 
 // CalculateStats computes statistics from evaluation results.
 func CalculateStats(resultsFile string, results []*eval.EvalResult) Stats {
 	stats := Stats{
 		ResultsFile: resultsFile,
-		TasksTotal:  len(results),
 	}
 
+	var durations []util.Duration
 	for _, result := range results {
+		switch result.Status() {
+		case eval.TaskStatusSkipped:
+			stats.TasksSkipped++
+			continue
+		case eval.TaskStatusNotRun:
+			stats.TasksNotRun++
+			continue
+		case eval.TaskStatusExpectedFailure:
+			stats.TasksExpectedFailed++
+			continue
+		case eval.TaskStatusUnexpectedPass:
+			stats.TasksUnexpectedPassed++
+			continue
+		}
+
+		if result.Quarantined {
+			stats.TasksQuarantined++
+			if !result.TaskPassed || !result.AllAssertionsPassed {
+				stats.QuarantinedFailed++
+			}
+			continue
+		}
+
+		stats.TasksTotal++
 		if result.TaskPassed {
 			stats.TasksPassed++
 		}
@@ -68,6 +156,30 @@ func CalculateStats(resultsFile string, results []*eval.EvalResult) Stats {
 			stats.AssertionsTotal += result.AssertionResults.TotalAssertions()
 			stats.AssertionsPassed += result.AssertionResults.PassedAssertions()
 		}
+
+		if CleanupFailure(result) != "" {
+			stats.CleanupFailures++
+		}
+
+		if result.Timing != nil && result.Timing.Total > 0 {
+			durations = append(durations, result.Timing.Total)
+		}
+
+		if score, ok := Score(result); ok {
+			stats.MeanScore += score
+			stats.ScoredTasks++
+		}
+
+		if result.ContextUsage != nil {
+			stats.ContextTokens += result.ContextUsage.TotalTokens
+		}
+
+		if category := JudgeFailureCategory(result); category != "" {
+			if stats.JudgeFailures == nil {
+				stats.JudgeFailures = make(map[string]int)
+			}
+			stats.JudgeFailures[category]++
+		}
 	}
 
 	// Calculate pass rates
@@ -77,10 +189,54 @@ func CalculateStats(resultsFile string, results []*eval.EvalResult) Stats {
 	if stats.AssertionsTotal > 0 {
 		stats.AssertionPassRate = float64(stats.AssertionsPassed) / float64(stats.AssertionsTotal)
 	}
+	if stats.ScoredTasks > 0 {
+		stats.MeanScore /= float64(stats.ScoredTasks)
+	}
+	stats.DurationP95 = percentile(durations, 0.95)
 
 	return stats
 }
 
+// percentile returns the nearest-rank percentile p (0.0-1.0) of durations, or
+// zero when there are none. durations is sorted in place.
+func percentile(durations []util.Duration, p float64) util.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	slices.Sort(durations)
+	rank := int(math.Ceil(p * float64(len(durations))))
+	return durations[max(rank, 1)-1]
+}
+
+// CalculateDifficultyStats computes statistics for the results of each task
+// difficulty. Results without a difficulty are grouped as Unspecified.
+func CalculateDifficultyStats(resultsFile string, results []*eval.EvalResult) map[string]Stats {
+	return GroupStats(resultsFile, GroupBy(results, ByDifficulty))
+}
+
+// CriticalTasks returns the number of critical tasks and the names of those
+// that failed or were not run. Quarantined tasks, skipped tasks, and tasks
+// expected to fail are left out.
+func CriticalTasks(results []*eval.EvalResult) (int, []string) {
+	total := 0
+	var failed []string
+	for _, result := range results {
+		if result.Priority != task.PriorityCritical {
+			continue
+		}
+		status := result.Status()
+		if status != eval.TaskStatusNotRun && !result.Counted() {
+			continue
+		}
+		total++
+		if status == eval.TaskStatusNotRun || status == eval.TaskStatusFailed {
+			failed = append(failed, result.TaskName)
+		}
+	}
+	return total, failed
+}
+
 // PassedAssertions returns the number of passed assertions for a result.
 func PassedAssertions(r *eval.EvalResult) int {
 	if r.AssertionResults == nil {
@@ -97,58 +253,210 @@ func TotalAssertions(r *eval.EvalResult) int {
 	return r.AssertionResults.TotalAssertions()
 }
 
+// Score returns the fraction of the assertions of a result that passed, and
+// false if it has no assertions
+func Score(r *eval.EvalResult) (float64, bool) {
+	total := TotalAssertions(r)
+	if total == 0 {
+		return 0, false
+	}
+	return float64(PassedAssertions(r)) / float64(total), true
+}
+
 // FailureReason returns the first failure reason from a result's assertions.
 func FailureReason(r *eval.EvalResult) string {
 	if r.TaskError != "" {
 		return r.TaskError
 	}
-	if r.AssertionResults == nil {
+	if res := FirstFailedAssertion(r); res != nil {
+		return res.Reason
+	}
+	return ""
+}
+
+// FailureCode returns the reason code of the first failed assertion of a
+// result, or "" if no assertion failed or the failure has no code, like the
+// results of older versions
+func FailureCode(r *eval.EvalResult) eval.ReasonCode {
+	if r.TaskError != "" {
 		return ""
 	}
+	if res := FirstFailedAssertion(r); res != nil {
+		return res.Code
+	}
+	return ""
+}
+
+// FirstFailedAssertion returns the result of the first failed assertion of a
+// result, or nil if none failed
+func FirstFailedAssertion(r *eval.EvalResult) *eval.SingleAssertionResult {
+	if r.AssertionResults == nil {
+		return nil
+	}
 	a := r.AssertionResults
-	if a.ToolsUsed != nil && !a.ToolsUsed.Passed {
-		return a.ToolsUsed.Reason
+	for _, res := range []*eval.SingleAssertionResult{
+		a.ToolsUsed,
+		a.RequireAny,
+		a.ToolsNotUsed,
+		a.MinToolCalls,
+		a.MaxToolCalls,
+		a.ToolCallCounts,
+		a.ResourcesRead,
+		a.ResourcesNotRead,
+		a.PromptsUsed,
+		a.PromptsNotUsed,
+		a.CallOrder,
+		a.Phases,
+		a.NoDuplicateCalls,
+		a.MaxAgentDuration,
+		a.MaxContextTokens,
+		a.GroundedOutput,
+		a.Expr,
+	} {
+		if res != nil && !res.Passed {
+			return res
+		}
 	}
-	if a.RequireAny != nil && !a.RequireAny.Passed {
-		return a.RequireAny.Reason
+	for _, name := range slices.Sorted(maps.Keys(a.Custom)) {
+		if res := a.Custom[name]; res != nil && !res.Passed {
+			return res
+		}
 	}
-	if a.ToolsNotUsed != nil && !a.ToolsNotUsed.Passed {
-		return a.ToolsNotUsed.Reason
+	for _, name := range slices.Sorted(maps.Keys(a.Expectations)) {
+		if res := a.Expectations[name]; res != nil && !res.Passed {
+			return res
+		}
 	}
-	if a.MinToolCalls != nil && !a.MinToolCalls.Passed {
-		return a.MinToolCalls.Reason
+	return nil
+}
+
+// judgeErrorPattern extracts the failure category from llmJudge step errors in
+// results that predate the taskJudgeCategory field
+var judgeErrorPattern = regexp.MustCompile(`^llm judge failed for reason '([^']+)'`)
+
+// JudgeFailureCategory returns the LLM judge failure category if the judge
+// failed the task, or an empty string otherwise.
+func JudgeFailureCategory(r *eval.EvalResult) string {
+	if r.TaskJudgeCategory != "" {
+		return r.TaskJudgeCategory
 	}
-	if a.MaxToolCalls != nil && !a.MaxToolCalls.Passed {
-		return a.MaxToolCalls.Reason
+	if r.VerifyOutput == nil {
+		return ""
 	}
-	if a.ResourcesRead != nil && !a.ResourcesRead.Passed {
-		return a.ResourcesRead.Reason
+
+	for _, step := range r.VerifyOutput.Steps {
+		if step == nil || step.Type != "llmJudge" || step.Success {
+			continue
+		}
+		if category := step.Outputs["failureCategory"]; category != "" {
+			return category
+		}
+		if m := judgeErrorPattern.FindStringSubmatch(step.Error); m != nil {
+			return m[1]
+		}
 	}
-	if a.ResourcesNotRead != nil && !a.ResourcesNotRead.Passed {
-		return a.ResourcesNotRead.Reason
+
+	return ""
+}
+
+// JudgeCategories returns the categories in counts, with the known judge
+// categories first and any others sorted after them.
+func JudgeCategories(counts ...map[string]int) []string {
+	var categories []string
+	for _, c := range llmjudge.FailureCategories {
+		for _, m := range counts {
+			if m[c] > 0 {
+				categories = append(categories, c)
+				break
+			}
+		}
 	}
-	if a.PromptsUsed != nil && !a.PromptsUsed.Passed {
-		return a.PromptsUsed.Reason
+
+	var others []string
+	for _, m := range counts {
+		for c, n := range m {
+			if n > 0 && !slices.Contains(categories, c) && !slices.Contains(others, c) {
+				others = append(others, c)
+			}
+		}
 	}
-	if a.PromptsNotUsed != nil && !a.PromptsNotUsed.Passed {
-		return a.PromptsNotUsed.Reason
+	sort.Strings(others)
+
+	return append(categories, others...)
+}
+
+// SetupFailure returns a description of why the setup phase failed, or an
+// empty string if setup succeeded or did not run.
+func SetupFailure(r *eval.EvalResult) string {
+	return phaseFailure(r.SetupOutput)
+}
+
+// CleanupFailure returns a description of why the cleanup phase failed, or an
+// empty string if cleanup succeeded or did not run.
+func CleanupFailure(r *eval.EvalResult) string {
+	return phaseFailure(r.CleanupOutput)
+}
+
+// Environment returns the environment a run's results were recorded in, or
+// nil if no extension reported any. Results merged from several runs are
+// described by the environment of the first result that has one.
+func Environment(results []*eval.EvalResult) eval.Environment {
+	for _, r := range results {
+		if r.Environment != nil {
+			return r.Environment
+		}
 	}
-	if a.CallOrder != nil && !a.CallOrder.Passed {
-		return a.CallOrder.Reason
+	return nil
+}
+
+func phaseFailure(output *task.PhaseOutput) string {
+	if output == nil || output.Success {
+		return ""
 	}
-	if a.NoDuplicateCalls != nil && !a.NoDuplicateCalls.Passed {
-		return a.NoDuplicateCalls.Reason
+	if output.Error != "" {
+		return output.Error
 	}
-	return ""
+	for i, step := range output.Steps {
+		if step == nil || step.Success {
+			continue
+		}
+		if step.Error != "" {
+			return fmt.Sprintf("step %d (%s): %s", i, step.Type, step.Error)
+		}
+		if step.Message != "" {
+			return fmt.Sprintf("step %d (%s): %s", i, step.Type, step.Message)
+		}
+		return fmt.Sprintf("step %d (%s) failed", i, step.Type)
+	}
+	return "phase failed"
 }
 
 // CollectFailedAssertions returns a list of formatted failure messages.
 func CollectFailedAssertions(results *eval.CompositeAssertionResult) []string {
 	var failures []string
+	for _, f := range failedAssertions(results) {
+		failures = append(failures, fmt.Sprintf("%s: %s", f.name, f.reason))
+	}
+
+	return failures
+}
+
+type failedAssertion struct {
+	name   string
+	reason string
+}
+
+// failedAssertions returns the failed assertions in a fixed order.
+func failedAssertions(results *eval.CompositeAssertionResult) []failedAssertion {
+	if results == nil {
+		return nil
+	}
+
+	var failures []failedAssertion
 
 	addFailure := func(name string, result *eval.SingleAssertionResult) {
 		if result != nil && !result.Passed {
-			failures = append(failures, fmt.Sprintf("%s: %s", name, result.Reason))
+			failures = append(failures, failedAssertion{name: name, reason: result.Reason})
 		}
 	}
 
@@ -157,12 +465,24 @@ func CollectFailedAssertions(results *eval.CompositeAssertionResult) []string {
 	addFailure("ToolsNotUsed", results.ToolsNotUsed)
 	addFailure("MinToolCalls", results.MinToolCalls)
 	addFailure("MaxToolCalls", results.MaxToolCalls)
+	addFailure("ToolCallCounts", results.ToolCallCounts)
 	addFailure("ResourcesRead", results.ResourcesRead)
 	addFailure("ResourcesNotRead", results.ResourcesNotRead)
 	addFailure("PromptsUsed", results.PromptsUsed)
 	addFailure("PromptsNotUsed", results.PromptsNotUsed)
 	addFailure("CallOrder", results.CallOrder)
+	addFailure("Phases", results.Phases)
 	addFailure("NoDuplicateCalls", results.NoDuplicateCalls)
+	addFailure("MaxAgentDuration", results.MaxAgentDuration)
+	addFailure("MaxContextTokens", results.MaxContextTokens)
+	addFailure("GroundedOutput", results.GroundedOutput)
+	addFailure("Expr", results.Expr)
+	for _, name := range slices.Sorted(maps.Keys(results.Custom)) {
+		addFailure(name, results.Custom[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(results.Expectations)) {
+		addFailure("Expectation "+name, results.Expectations[name])
+	}
 
 	return failures
 }
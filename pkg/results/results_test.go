@@ -2,11 +2,17 @@ package results
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 // createTestResultsFile creates a temporary results file for testing.
@@ -97,6 +103,65 @@ func TestCalculateStats(t *testing.T) {
 	if stats.AssertionPassRate != expectedAssertionRate {
 		t.Errorf("AssertionPassRate = %f, want %f", stats.AssertionPassRate, expectedAssertionRate)
 	}
+
+	if stats.ScoredTasks != 3 {
+		t.Errorf("ScoredTasks = %d, want 3", stats.ScoredTasks)
+	}
+	if expectedScore := (1.0 + 0.5 + 0.0) / 3.0; stats.MeanScore != expectedScore {
+		t.Errorf("MeanScore = %f, want %f", stats.MeanScore, expectedScore)
+	}
+}
+
+func TestCalculateDifficultyStats(t *testing.T) {
+	evalResults := append(sampleResults(), &eval.EvalResult{TaskName: "task-4", TaskPassed: true})
+
+	stats := CalculateDifficultyStats("test.json", evalResults)
+
+	want := map[string]struct{ total, passed int }{
+		"easy":        {1, 1},
+		"medium":      {1, 1},
+		"hard":        {1, 0},
+		"unspecified": {1, 1},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("got %d difficulties, want %d", len(stats), len(want))
+	}
+	for difficulty, w := range want {
+		s, ok := stats[difficulty]
+		if !ok {
+			t.Errorf("missing stats for difficulty %q", difficulty)
+			continue
+		}
+		if s.TasksTotal != w.total || s.TasksPassed != w.passed {
+			t.Errorf("%s: tasks %d/%d, want %d/%d", difficulty, s.TasksPassed, s.TasksTotal, w.passed, w.total)
+		}
+	}
+}
+
+func TestCalculateStatsDurationP95(t *testing.T) {
+	var evalResults []*eval.EvalResult
+	for i := 1; i <= 20; i++ {
+		evalResults = append(evalResults, &eval.EvalResult{
+			TaskName:   fmt.Sprintf("task-%d", i),
+			TaskPassed: true,
+			Timing:     &eval.TaskTiming{Total: util.Duration(time.Duration(i) * time.Second)},
+		})
+	}
+	// Results without timing and quarantined tasks are left out
+	evalResults = append(evalResults,
+		&eval.EvalResult{TaskName: "untimed", TaskPassed: true},
+		&eval.EvalResult{TaskName: "quarantined", Quarantined: true, Timing: &eval.TaskTiming{Total: util.Duration(time.Hour)}},
+	)
+
+	stats := CalculateStats("test.json", evalResults)
+	if want := util.Duration(19 * time.Second); stats.DurationP95 != want {
+		t.Errorf("DurationP95 = %s, want %s", stats.DurationP95, want)
+	}
+
+	stats = CalculateStats("test.json", evalResults[20:21])
+	if stats.DurationP95 != 0 {
+		t.Errorf("DurationP95 = %s, want 0 without timing", stats.DurationP95)
+	}
 }
 
 func TestCalculateStatsEmptyResults(t *testing.T) {
@@ -195,3 +260,197 @@ func TestCollectFailedAssertions(t *testing.T) {
 		t.Errorf("failures[0] = %s, want 'ToolsUsed: Tool not called'", failures[0])
 	}
 }
+
+func TestFailureCode(t *testing.T) {
+	r := &eval.EvalResult{
+		TaskName:   "t",
+		TaskPassed: true,
+		AssertionResults: &eval.CompositeAssertionResult{
+			ToolsUsed:    &eval.SingleAssertionResult{Passed: true},
+			MinToolCalls: &eval.SingleAssertionResult{Passed: false, Reason: "Too few tool calls: expected >= 2, got 1", Code: eval.ReasonTooFewToolCalls},
+			Custom: map[string]*eval.SingleAssertionResult{
+				"kube.check": {Passed: false, Reason: "tool was not called", Code: eval.ReasonCustomAssertionFailed},
+			},
+		},
+	}
+
+	if got := FailureCode(r); got != eval.ReasonTooFewToolCalls {
+		t.Errorf("FailureCode() = %q, want %q", got, eval.ReasonTooFewToolCalls)
+	}
+	if got := FailureReason(r); got != "Too few tool calls: expected >= 2, got 1" {
+		t.Errorf("FailureReason() = %q", got)
+	}
+	if got := ByFailureCode(r); got != "tooFewToolCalls" {
+		t.Errorf("ByFailureCode() = %q, want tooFewToolCalls", got)
+	}
+
+	// Task errors and results of older versions have no code
+	r.TaskError = "agent crashed"
+	if got := FailureCode(r); got != "" {
+		t.Errorf("FailureCode() of a task error = %q, want empty", got)
+	}
+	if got := ByFailureCode(&eval.EvalResult{TaskPassed: true}); got != Unspecified {
+		t.Errorf("ByFailureCode() of a passed task = %q, want %q", got, Unspecified)
+	}
+}
+
+func TestCleanupFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		output *task.PhaseOutput
+		want   string
+	}{
+		{
+			name:   "no cleanup phase",
+			output: nil,
+			want:   "",
+		},
+		{
+			name:   "cleanup succeeded",
+			output: &task.PhaseOutput{Success: true},
+			want:   "",
+		},
+		{
+			name:   "cleanup returned an error",
+			output: &task.PhaseOutput{Success: false, Error: "cleanup[0] failed: exit status 1"},
+			want:   "cleanup[0] failed: exit status 1",
+		},
+		{
+			name: "cleanup step reported failure",
+			output: &task.PhaseOutput{
+				Success: false,
+				Steps: []*steps.StepOutput{
+					{Type: "script", Success: true},
+					{Type: "http", Success: false, Message: "unexpected status 500"},
+				},
+			},
+			want: "step 1 (http): unexpected status 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CleanupFailure(&eval.EvalResult{CleanupOutput: tt.output})
+			if got != tt.want {
+				t.Errorf("CleanupFailure() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateStatsCleanupFailures(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[0].CleanupOutput = &task.PhaseOutput{Success: false, Error: "namespace still terminating"}
+
+	stats := CalculateStats("test.json", evalResults)
+
+	if stats.CleanupFailures != 1 {
+		t.Errorf("CleanupFailures = %d, want 1", stats.CleanupFailures)
+	}
+}
+
+func TestJudgeFailureCategory(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *eval.EvalResult
+		want   string
+	}{
+		{
+			name:   "no judge",
+			result: &eval.EvalResult{},
+			want:   "",
+		},
+		{
+			name:   "category recorded on result",
+			result: &eval.EvalResult{TaskJudgeCategory: "semantic_mismatch"},
+			want:   "semantic_mismatch",
+		},
+		{
+			name: "category in step outputs",
+			result: &eval.EvalResult{VerifyOutput: &task.PhaseOutput{Steps: []*steps.StepOutput{
+				{Type: "llmJudge", Outputs: map[string]string{"failureCategory": "contains_extra_info"}},
+			}}},
+			want: "contains_extra_info",
+		},
+		{
+			name: "category parsed from older step error",
+			result: &eval.EvalResult{VerifyOutput: &task.PhaseOutput{Steps: []*steps.StepOutput{
+				{Type: "llmJudge", Error: "llm judge failed for reason 'missing_information': no pod names"},
+			}}},
+			want: "missing_information",
+		},
+		{
+			name: "judge passed",
+			result: &eval.EvalResult{VerifyOutput: &task.PhaseOutput{Steps: []*steps.StepOutput{
+				{Type: "llmJudge", Success: true},
+			}}},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JudgeFailureCategory(tt.result); got != tt.want {
+				t.Errorf("JudgeFailureCategory() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateStatsJudgeFailures(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[0].TaskJudgeCategory = "semantic_mismatch"
+	evalResults[1].TaskJudgeCategory = "semantic_mismatch"
+	evalResults[2].TaskJudgeCategory = "missing_information"
+
+	stats := CalculateStats("test.json", evalResults)
+
+	want := map[string]int{"semantic_mismatch": 2, "missing_information": 1}
+	if !reflect.DeepEqual(stats.JudgeFailures, want) {
+		t.Errorf("JudgeFailures = %v, want %v", stats.JudgeFailures, want)
+	}
+
+	categories := JudgeCategories(stats.JudgeFailures, map[string]int{"custom": 1})
+	wantCategories := []string{"semantic_mismatch", "missing_information", "custom"}
+	if !reflect.DeepEqual(categories, wantCategories) {
+		t.Errorf("JudgeCategories() = %v, want %v", categories, wantCategories)
+	}
+}
+
+func TestCalculateStatsQuarantine(t *testing.T) {
+	evalResults := sampleResults()
+	ApplyQuarantine(evalResults, &eval.Quarantine{Tasks: []eval.QuarantinedTask{{Name: "task-3"}}})
+
+	if !evalResults[2].Quarantined || evalResults[0].Quarantined {
+		t.Fatalf("ApplyQuarantine() marked the wrong tasks")
+	}
+
+	stats := CalculateStats("test.json", evalResults)
+
+	if stats.TasksTotal != 2 || stats.TasksPassed != 2 {
+		t.Errorf("tasks = %d/%d, want 2/2", stats.TasksPassed, stats.TasksTotal)
+	}
+	if stats.AssertionsTotal != 4 || stats.AssertionsPassed != 3 {
+		t.Errorf("assertions = %d/%d, want 3/4", stats.AssertionsPassed, stats.AssertionsTotal)
+	}
+	if stats.TasksQuarantined != 1 || stats.QuarantinedFailed != 1 {
+		t.Errorf("quarantined = %d (%d failed), want 1 (1 failed)", stats.TasksQuarantined, stats.QuarantinedFailed)
+	}
+}
+
+func TestCalculateStatsSkipAndExpectedFailure(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[0].ExpectedFailure = true
+	evalResults[2].ExpectedFailure = true
+	evalResults = append(evalResults, &eval.EvalResult{TaskName: "task-4", SkipReason: "needs a GPU node"})
+
+	stats := CalculateStats("test.json", evalResults)
+
+	if stats.TasksTotal != 1 || stats.TasksPassed != 1 {
+		t.Errorf("tasks = %d/%d, want 1/1", stats.TasksPassed, stats.TasksTotal)
+	}
+	if stats.TasksSkipped != 1 || stats.TasksExpectedFailed != 1 || stats.TasksUnexpectedPassed != 1 {
+		t.Errorf("skipped, expected failed, unexpected passed = %d, %d, %d, want 1, 1, 1",
+			stats.TasksSkipped, stats.TasksExpectedFailed, stats.TasksUnexpectedPassed)
+	}
+}
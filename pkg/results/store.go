@@ -0,0 +1,212 @@
+package results
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// Output layouts of a results file
+const (
+	// LayoutFile writes all results to a single JSON file
+	LayoutFile = "file"
+	// LayoutGzip writes all results to a single gzip-compressed JSON file
+	LayoutGzip = "gzip"
+	// LayoutDir writes one JSON file per task to a directory, with an index
+	LayoutDir = "dir"
+)
+
+// Layouts lists the supported output layouts
+var Layouts = []string{LayoutFile, LayoutGzip, LayoutDir}
+
+// IndexFile is the name of the index of a results directory
+const IndexFile = "index.json"
+
+// Index lists the tasks of a results directory in the order they ran.
+type Index struct {
+	Tasks []IndexEntry `json:"tasks"`
+}
+
+// IndexEntry is a task in a results directory, with its outcome so that a
+// run can be summarized without loading every task.
+type IndexEntry struct {
+	TaskName            string `json:"taskName"`
+	File                string `json:"file"`
+	TaskPassed          bool   `json:"taskPassed"`
+	AllAssertionsPassed bool   `json:"allAssertionsPassed"`
+	Quarantined         bool   `json:"quarantined,omitempty"`
+	SkipReason          string `json:"skipReason,omitempty"`
+	ExpectedFailure     bool   `json:"expectedFailure,omitempty"`
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// OutputPath returns the default path of the results of an eval in a layout
+func OutputPath(evalName, layout string) string {
+	base := fmt.Sprintf("mcpchecker-%s-out", evalName)
+	switch layout {
+	case LayoutGzip:
+		return base + ".json.gz"
+	case LayoutDir:
+		return base
+	default:
+		return base + ".json"
+	}
+}
+
+// Save writes results to path in a layout.
+func Save(results []*eval.EvalResult, path, layout string) error {
+	switch layout {
+	case LayoutFile, "":
+		return writeJSONFile(path, results, false)
+	case LayoutGzip:
+		return writeJSONFile(path, results, true)
+	case LayoutDir:
+		return saveDir(results, path)
+	default:
+		return fmt.Errorf("unknown output layout %q: must be one of %s", layout, strings.Join(Layouts, ", "))
+	}
+}
+
+func saveDir(results []*eval.EvalResult, dir string) error {
+	tasksDir := filepath.Join(dir, "tasks")
+	// Remove the tasks of a previous run, so that the directory only holds
+	// the tasks in the index
+	if err := os.RemoveAll(tasksDir); err != nil {
+		return fmt.Errorf("failed to clear results directory: %w", err)
+	}
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create results directory: %w", err)
+	}
+
+	index := Index{Tasks: make([]IndexEntry, 0, len(results))}
+	for i, r := range results {
+		// Files are numbered to keep the run order and tell tasks with the
+		// same name apart
+		file := filepath.ToSlash(filepath.Join("tasks", fmt.Sprintf("%04d-%s.json", i+1, safeFileName(r.TaskName))))
+		if err := writeJSONFile(filepath.Join(dir, file), r, false); err != nil {
+			return err
+		}
+
+		index.Tasks = append(index.Tasks, IndexEntry{
+			TaskName:            r.TaskName,
+			File:                file,
+			TaskPassed:          r.TaskPassed,
+			AllAssertionsPassed: r.AllAssertionsPassed,
+			Quarantined:         r.Quarantined,
+			SkipReason:          r.SkipReason,
+			ExpectedFailure:     r.ExpectedFailure,
+		})
+	}
+
+	return writeJSONFile(filepath.Join(dir, IndexFile), index, false)
+}
+
+func writeJSONFile(path string, v any, compress bool) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	var w io.Writer = file
+	if compress {
+		gz := gzip.NewWriter(file)
+		defer gz.Close()
+		w = gz
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	return nil
+}
+
+// readJSONFile decodes a JSON file, decompressing it if it is gzipped
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read results file: %w", err)
+	}
+
+	if bytes.HasPrefix(data, gzipMagic) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to decompress results file: %w", err)
+		}
+		defer gz.Close()
+
+		if data, err = io.ReadAll(gz); err != nil {
+			return fmt.Errorf("failed to decompress results file: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse results JSON: %w", err)
+	}
+
+	return nil
+}
+
+// LoadFiltered reads the results of the tasks whose name contains filter, like
+// Filter. Of a results directory, only the files of the matching tasks are read.
+func LoadFiltered(path, filter string) ([]*eval.EvalResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results file: %w", err)
+	}
+
+	if info.IsDir() {
+		return loadDir(path, filter)
+	}
+
+	results, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Filter(results, filter), nil
+}
+
+func loadDir(dir, filter string) ([]*eval.EvalResult, error) {
+	var index Index
+	if err := readJSONFile(filepath.Join(dir, IndexFile), &index); err != nil {
+		return nil, fmt.Errorf("failed to load results index: %w", err)
+	}
+
+	filter = strings.ToLower(filter)
+	results := make([]*eval.EvalResult, 0, len(index.Tasks))
+	for _, entry := range index.Tasks {
+		if !strings.Contains(strings.ToLower(entry.TaskName), filter) {
+			continue
+		}
+
+		r := &eval.EvalResult{}
+		if err := readJSONFile(filepath.Join(dir, filepath.FromSlash(entry.File)), r); err != nil {
+			return nil, fmt.Errorf("failed to load results of task %s: %w", entry.TaskName, err)
+		}
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// isResultsDir returns whether dir is a results directory written with LayoutDir
+func isResultsDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, IndexFile))
+	return err == nil
+}
+
+func safeFileName(name string) string {
+	return strings.NewReplacer("/", "-", `\`, "-", " ", "-", ":", "-").Replace(name)
+}
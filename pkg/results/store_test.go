@@ -0,0 +1,180 @@
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func TestSaveAndLoadLayouts(t *testing.T) {
+	evalResults := sampleResults()
+	// Tasks with the same name are kept apart in a results directory
+	evalResults = append(evalResults, &eval.EvalResult{TaskName: "task-1", TaskPassed: true, AllAssertionsPassed: true})
+
+	for _, layout := range Layouts {
+		t.Run(layout, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), OutputPath("suite", layout))
+
+			if err := Save(evalResults, path, layout); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if len(loaded) != len(evalResults) {
+				t.Fatalf("loaded %d results, want %d", len(loaded), len(evalResults))
+			}
+			for i := range evalResults {
+				if loaded[i].TaskName != evalResults[i].TaskName || loaded[i].TaskPassed != evalResults[i].TaskPassed {
+					t.Errorf("result %d = %s (passed %v), want %s (passed %v)", i,
+						loaded[i].TaskName, loaded[i].TaskPassed, evalResults[i].TaskName, evalResults[i].TaskPassed)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputPath(t *testing.T) {
+	tests := map[string]string{
+		LayoutFile: "mcpchecker-suite-out.json",
+		LayoutGzip: "mcpchecker-suite-out.json.gz",
+		LayoutDir:  "mcpchecker-suite-out",
+	}
+
+	for layout, want := range tests {
+		if got := OutputPath("suite", layout); got != want {
+			t.Errorf("OutputPath(%q) = %s, want %s", layout, got, want)
+		}
+	}
+}
+
+func TestSaveDirIndex(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+
+	if err := Save(sampleResults(), dir, LayoutDir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	var index Index
+	if err := readJSONFile(filepath.Join(dir, IndexFile), &index); err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+
+	if len(index.Tasks) != 3 {
+		t.Fatalf("index has %d tasks, want 3", len(index.Tasks))
+	}
+	if index.Tasks[0].File != "tasks/0001-task-1.json" || !index.Tasks[0].TaskPassed {
+		t.Errorf("first index entry = %+v", index.Tasks[0])
+	}
+
+	// Saving fewer tasks to the same directory drops the stale task files
+	if err := Save(sampleResults()[:1], dir, LayoutDir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "tasks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("tasks directory has %d files, want 1", len(entries))
+	}
+}
+
+func TestSaveUnknownLayout(t *testing.T) {
+	if err := Save(sampleResults(), filepath.Join(t.TempDir(), "out"), "parquet"); err == nil {
+		t.Error("expected error for unknown layout")
+	}
+}
+
+func TestLoadRunsLayouts(t *testing.T) {
+	dir := t.TempDir()
+
+	now := time.Now()
+	layouts := map[string]string{"runa": LayoutDir, "runb": LayoutGzip, "runc": LayoutFile}
+	for i, name := range []string{"runa", "runb", "runc"} {
+		layout := layouts[name]
+		path := filepath.Join(dir, OutputPath(name, layout))
+		if err := Save(sampleResults(), path, layout); err != nil {
+			t.Fatal(err)
+		}
+
+		stamp := path
+		if layout == LayoutDir {
+			stamp = filepath.Join(path, IndexFile)
+		}
+		mtime := now.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(stamp, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Directories without an index are not runs
+	if err := os.Mkdir(filepath.Join(dir, "other"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := LoadRuns([]string{dir})
+	if err != nil {
+		t.Fatalf("LoadRuns() error = %v", err)
+	}
+
+	want := []string{"mcpchecker-runa-out", "mcpchecker-runb-out.json.gz", "mcpchecker-runc-out.json"}
+	if len(runs) != len(want) {
+		t.Fatalf("loaded %d runs, want %d", len(runs), len(want))
+	}
+	for i, r := range runs {
+		if filepath.Base(r.File) != want[i] {
+			t.Errorf("run %d = %s, want %s", i, filepath.Base(r.File), want[i])
+		}
+		if len(r.Results) != 3 {
+			t.Errorf("run %s has %d results, want 3", r.File, len(r.Results))
+		}
+	}
+
+	// A results directory given directly is a single run
+	runs, err = LoadRuns([]string{filepath.Join(dir, "mcpchecker-runa-out")})
+	if err != nil {
+		t.Fatalf("LoadRuns() error = %v", err)
+	}
+	if len(runs) != 1 {
+		t.Errorf("loaded %d runs, want 1", len(runs))
+	}
+}
+
+func TestLoadFiltered(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, layout := range []string{LayoutFile, LayoutDir} {
+		t.Run(layout, func(t *testing.T) {
+			path := filepath.Join(dir, OutputPath("suite", layout))
+			if err := Save(sampleResults(), path, layout); err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, err := LoadFiltered(path, "TASK-2")
+			if err != nil {
+				t.Fatalf("LoadFiltered() error = %v", err)
+			}
+			if len(loaded) != 1 || loaded[0].TaskName != "task-2" {
+				t.Errorf("LoadFiltered() loaded %d results, want task-2", len(loaded))
+			}
+		})
+	}
+
+	// Files of tasks that do not match are not read
+	path := filepath.Join(dir, OutputPath("suite", LayoutDir))
+	if err := os.WriteFile(filepath.Join(path, "tasks", "0001-task-1.json"), []byte("corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFiltered(path, "task-2"); err != nil {
+		t.Errorf("LoadFiltered() read a task that did not match: %v", err)
+	}
+	if _, err := LoadFiltered(path, ""); err == nil {
+		t.Error("expected error for corrupt task file")
+	}
+}
@@ -0,0 +1,279 @@
+package results
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// DefaultTrendWindow is the number of most recent runs checked for flaky tasks.
+const DefaultTrendWindow = 5
+
+// minFlakyFlips is the number of pass/fail changes within the window for a
+// task to count as flaky. A single change is a regression or a fix.
+const minFlakyFlips = 2
+
+// Run is one results file in a series of runs.
+type Run struct {
+	File    string
+	Time    time.Time
+	Results []*eval.EvalResult
+}
+
+// Trend holds pass rates across a series of runs, oldest first.
+type Trend struct {
+	Window int         `json:"window"`
+	Runs   []TrendRun  `json:"runs"`
+	Tasks  []TaskTrend `json:"tasks"`
+}
+
+// TrendRun holds the aggregate pass rate of a single run.
+type TrendRun struct {
+	File        string    `json:"file"`
+	Time        time.Time `json:"time"`
+	TasksTotal  int       `json:"tasksTotal"`
+	TasksPassed int       `json:"tasksPassed"`
+	PassRate    float64   `json:"passRate"`
+}
+
+// TaskTrend holds the outcomes of a single task across runs.
+type TaskTrend struct {
+	Name string `json:"name"`
+	// Outcomes has one entry per run: whether the task passed, or nil if it
+	// was not part of the run
+	Outcomes []*bool `json:"outcomes"`
+	PassRate float64 `json:"passRate"`
+	// Flips is the number of times the outcome changed within the window
+	Flips int  `json:"flips"`
+	Flaky bool `json:"flaky"`
+	// NewlyFlaky is set when the task is flaky within the window but its
+	// outcome never changed in the runs before it
+	NewlyFlaky bool `json:"newlyFlaky"`
+}
+
+// LoadRuns loads results files, and the results files in any directories, as
+// a series of runs ordered by when they started. Results directories written
+// with LayoutDir are a single run, whether given directly or found in a
+// directory.
+func LoadRuns(paths []string) ([]Run, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", p, err)
+		}
+		if !info.IsDir() || isResultsDir(p) {
+			files = append(files, p)
+			continue
+		}
+
+		matches, err := findRuns(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list results in %s: %w", p, err)
+		}
+		files = append(files, matches...)
+	}
+
+	runs := make([]Run, 0, len(files))
+	for _, f := range files {
+		results, err := Load(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", f, err)
+		}
+
+		t, ok := runStart(results)
+		if !ok {
+			// Results written before start times were recorded fall back to
+			// when the file was written
+			if t, err = modTime(f); err != nil {
+				return nil, err
+			}
+		}
+
+		runs = append(runs, Run{File: f, Time: t, Results: results})
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].Time.Equal(runs[j].Time) {
+			return runs[i].File < runs[j].File
+		}
+		return runs[i].Time.Before(runs[j].Time)
+	})
+
+	return runs, nil
+}
+
+// runStart returns the earliest start time recorded in results, so that
+// copied or restored files keep their order.
+func runStart(results []*eval.EvalResult) (time.Time, bool) {
+	var start time.Time
+	for _, r := range results {
+		if r.Timing == nil || r.Timing.Started.IsZero() {
+			continue
+		}
+		if start.IsZero() || r.Timing.Started.Before(start) {
+			start = r.Timing.Started
+		}
+	}
+	return start, !start.IsZero()
+}
+
+// modTime returns when a results file or directory was written
+func modTime(path string) (time.Time, error) {
+	stamp := path
+	if isResultsDir(path) {
+		// The directory's own modification time changes when files are
+		// added to it, so the index tells when the run was written
+		stamp = filepath.Join(path, IndexFile)
+	}
+	info, err := os.Stat(stamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return info.ModTime(), nil
+}
+
+// findRuns returns the results files and results directories in dir
+func findRuns(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []string
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		switch {
+		case e.IsDir():
+			if isResultsDir(path) {
+				runs = append(runs, path)
+			}
+		case strings.HasSuffix(e.Name(), ".json"), strings.HasSuffix(e.Name(), ".json.gz"):
+			runs = append(runs, path)
+		}
+	}
+
+	return runs, nil
+}
+
+// CalculateTrend computes aggregate and per-task pass rates across runs,
+// which must be ordered oldest first. Tasks whose outcome alternates within
+// the last window runs are marked flaky.
+func CalculateTrend(runs []Run, window int) *Trend {
+	trend := &Trend{
+		Window: window,
+		Runs:   make([]TrendRun, 0, len(runs)),
+		Tasks:  []TaskTrend{},
+	}
+
+	tasks := make(map[string]*TaskTrend)
+	var names []string
+	for i, run := range runs {
+		tr := TrendRun{
+			File: run.File,
+			Time: run.Time,
+		}
+
+		for _, r := range run.Results {
+			// Skipped tasks did not run, so they have no outcome
+			if r.SkipReason != "" {
+				continue
+			}
+
+			tr.TasksTotal++
+			passed := r.TaskPassed && r.AllAssertionsPassed
+			if passed {
+				tr.TasksPassed++
+			}
+
+			t, ok := tasks[r.TaskName]
+			if !ok {
+				t = &TaskTrend{Name: r.TaskName, Outcomes: make([]*bool, len(runs))}
+				tasks[r.TaskName] = t
+				names = append(names, r.TaskName)
+			}
+			t.Outcomes[i] = &passed
+		}
+
+		if tr.TasksTotal > 0 {
+			tr.PassRate = float64(tr.TasksPassed) / float64(tr.TasksTotal)
+		}
+		trend.Runs = append(trend.Runs, tr)
+	}
+
+	windowStart := max(len(runs)-window, 0)
+
+	sort.Strings(names)
+	for _, name := range names {
+		t := tasks[name]
+
+		ran, passed := 0, 0
+		for _, o := range t.Outcomes {
+			if o == nil {
+				continue
+			}
+			ran++
+			if *o {
+				passed++
+			}
+		}
+		if ran > 0 {
+			t.PassRate = float64(passed) / float64(ran)
+		}
+
+		t.Flips = countFlips(t.Outcomes[windowStart:])
+		t.Flaky = t.Flips >= minFlakyFlips
+		t.NewlyFlaky = t.Flaky && countFlips(t.Outcomes[:windowStart]) == 0
+
+		trend.Tasks = append(trend.Tasks, *t)
+	}
+
+	return trend
+}
+
+// FlakyTasks returns the tasks that are flaky within the window.
+func (t *Trend) FlakyTasks() []TaskTrend {
+	var flaky []TaskTrend
+	for _, task := range t.Tasks {
+		if task.Flaky {
+			flaky = append(flaky, task)
+		}
+	}
+	return flaky
+}
+
+// SuggestQuarantine returns the flaky tasks that are not already quarantined.
+func (t *Trend) SuggestQuarantine(quarantine *eval.Quarantine) []eval.QuarantinedTask {
+	var suggestions []eval.QuarantinedTask
+	for _, task := range t.FlakyTasks() {
+		if quarantine.Contains(task.Name) {
+			continue
+		}
+		suggestions = append(suggestions, eval.QuarantinedTask{
+			Name:   task.Name,
+			Reason: fmt.Sprintf("flaky: outcome changed %d times in the last %d runs", task.Flips, min(t.Window, len(t.Runs))),
+		})
+	}
+	return suggestions
+}
+
+// countFlips counts outcome changes, skipping runs the task was not part of.
+func countFlips(outcomes []*bool) int {
+	flips := 0
+	var last *bool
+	for _, o := range outcomes {
+		if o == nil {
+			continue
+		}
+		if last != nil && *last != *o {
+			flips++
+		}
+		last = o
+	}
+	return flips
+}
@@ -0,0 +1,204 @@
+package results
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func trendRun(outcomes map[string]bool) Run {
+	var results []*eval.EvalResult
+	for name, passed := range outcomes {
+		results = append(results, &eval.EvalResult{TaskName: name, TaskPassed: passed, AllAssertionsPassed: true})
+	}
+	return Run{Results: results}
+}
+
+func TestCalculateTrend(t *testing.T) {
+	runs := []Run{
+		trendRun(map[string]bool{"stable": true, "alternating": true, "old-flaky": true, "regressed": true}),
+		trendRun(map[string]bool{"stable": true, "alternating": true, "old-flaky": false, "regressed": true}),
+		trendRun(map[string]bool{"stable": true, "alternating": true, "old-flaky": true, "regressed": true}),
+		trendRun(map[string]bool{"stable": true, "alternating": false, "old-flaky": false, "regressed": false}),
+		trendRun(map[string]bool{"stable": true, "alternating": true, "old-flaky": true, "regressed": false, "added": true}),
+		trendRun(map[string]bool{"stable": true, "alternating": false, "old-flaky": false, "regressed": false, "added": false}),
+	}
+
+	trend := CalculateTrend(runs, 3)
+
+	if len(trend.Runs) != 6 {
+		t.Fatalf("len(Runs) = %d, want 6", len(trend.Runs))
+	}
+	if trend.Runs[0].PassRate != 1 || trend.Runs[5].TasksPassed != 1 || trend.Runs[5].TasksTotal != 5 {
+		t.Errorf("unexpected run stats: first %+v, last %+v", trend.Runs[0], trend.Runs[5])
+	}
+
+	tasks := make(map[string]TaskTrend)
+	for _, task := range trend.Tasks {
+		tasks[task.Name] = task
+	}
+
+	tests := []struct {
+		name       string
+		flips      int
+		flaky      bool
+		newlyFlaky bool
+		passRate   float64
+	}{
+		{name: "stable", passRate: 1},
+		{name: "alternating", flips: 2, flaky: true, newlyFlaky: true, passRate: 4.0 / 6},
+		{name: "old-flaky", flips: 2, flaky: true, passRate: 0.5},
+		{name: "regressed", flips: 0, passRate: 0.5},
+		{name: "added", flips: 1, passRate: 0.5},
+	}
+
+	for _, tt := range tests {
+		got, ok := tasks[tt.name]
+		if !ok {
+			t.Errorf("task %s missing from trend", tt.name)
+			continue
+		}
+		if got.Flips != tt.flips || got.Flaky != tt.flaky || got.NewlyFlaky != tt.newlyFlaky || got.PassRate != tt.passRate {
+			t.Errorf("%s: got flips=%d flaky=%v newlyFlaky=%v passRate=%v, want flips=%d flaky=%v newlyFlaky=%v passRate=%v",
+				tt.name, got.Flips, got.Flaky, got.NewlyFlaky, got.PassRate,
+				tt.flips, tt.flaky, tt.newlyFlaky, tt.passRate)
+		}
+	}
+
+	if tasks["added"].Outcomes[0] != nil {
+		t.Errorf("expected nil outcome for run before the task was added")
+	}
+
+	flaky := trend.FlakyTasks()
+	if len(flaky) != 2 || flaky[0].Name != "alternating" || flaky[1].Name != "old-flaky" {
+		t.Errorf("unexpected flaky tasks: %+v", flaky)
+	}
+
+	quarantine := &eval.Quarantine{Tasks: []eval.QuarantinedTask{{Name: "old-flaky"}}}
+	suggestions := trend.SuggestQuarantine(quarantine)
+	if len(suggestions) != 1 || suggestions[0].Name != "alternating" {
+		t.Errorf("unexpected quarantine suggestions: %+v", suggestions)
+	}
+	if suggestions[0].Reason != "flaky: outcome changed 2 times in the last 3 runs" {
+		t.Errorf("unexpected suggestion reason: %q", suggestions[0].Reason)
+	}
+}
+
+func TestCalculateTrendSkipped(t *testing.T) {
+	runs := []Run{
+		trendRun(map[string]bool{"stable": true, "gpu": true}),
+		trendRun(map[string]bool{"stable": true}),
+		trendRun(map[string]bool{"stable": true, "gpu": true}),
+	}
+	runs[1].Results = append(runs[1].Results, &eval.EvalResult{TaskName: "gpu", SkipReason: "no GPU nodes"})
+
+	trend := CalculateTrend(runs, 3)
+
+	if trend.Runs[1].TasksTotal != 1 || trend.Runs[1].PassRate != 1 {
+		t.Errorf("run with skipped task = %+v, want 1 task counted", trend.Runs[1])
+	}
+	for _, task := range trend.Tasks {
+		if task.Name == "gpu" && (task.Outcomes[1] != nil || task.Flaky) {
+			t.Errorf("skipped run of gpu = %v (flaky %v), want no outcome", task.Outcomes[1], task.Flaky)
+		}
+	}
+}
+
+func TestLoadRuns(t *testing.T) {
+	dir := t.TempDir()
+
+	now := time.Now()
+	for i, name := range []string{"b.json", "a.json", "c.json"} {
+		path := filepath.Join(dir, name)
+		data, err := json.Marshal([]*eval.EvalResult{{TaskName: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := LoadRuns([]string{dir})
+	if err != nil {
+		t.Fatalf("LoadRuns() error = %v", err)
+	}
+
+	var got []string
+	for _, r := range runs {
+		got = append(got, filepath.Base(r.File))
+	}
+	want := []string{"b.json", "a.json", "c.json"}
+	if len(got) != len(want) {
+		t.Fatalf("runs = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("runs = %v, want %v", got, want)
+			break
+		}
+	}
+
+	if _, err := LoadRuns([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("expected error for missing path")
+	}
+}
+
+func TestLoadRunsStartTime(t *testing.T) {
+	dir := t.TempDir()
+
+	// Files copied out of order keep the order in which the runs started
+	now := time.Now()
+	for i, name := range []string{"b.json", "a.json", "c.json"} {
+		path := filepath.Join(dir, name)
+		started := now.Add(-time.Duration(i) * time.Hour)
+		data, err := json.Marshal([]*eval.EvalResult{
+			{TaskName: "late", Timing: &eval.TaskTiming{Started: started.Add(time.Minute)}},
+			{TaskName: "early", Timing: &eval.TaskTiming{Started: started}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := LoadRuns([]string{dir})
+	if err != nil {
+		t.Fatalf("LoadRuns() error = %v", err)
+	}
+
+	var got []string
+	for _, r := range runs {
+		got = append(got, filepath.Base(r.File))
+	}
+	want := []string{"c.json", "a.json", "b.json"}
+	if len(got) != len(want) {
+		t.Fatalf("runs = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("runs = %v, want %v", got, want)
+			break
+		}
+	}
+	if !runs[0].Time.Equal(now.Add(-2 * time.Hour)) {
+		t.Errorf("runs[0].Time = %v, want the earliest task start %v", runs[0].Time, now.Add(-2*time.Hour))
+	}
+}
@@ -0,0 +1,210 @@
+package results
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// Failure kinds, in the order a task reaches them
+const (
+	FailureKindSetup      = "setup"
+	FailureKindAgent      = "agent"
+	FailureKindVerify     = "verify"
+	FailureKindJudge      = "judge"
+	FailureKindAssertions = "assertions"
+)
+
+// DefaultSimilarity is the minimum similarity for two error messages to be
+// grouped into the same cluster.
+const DefaultSimilarity = 0.6
+
+// Triage groups the failed tasks of a run into clusters with a common cause.
+type Triage struct {
+	ResultsFile string           `json:"resultsFile"`
+	TasksTotal  int              `json:"tasksTotal"`
+	TasksFailed int              `json:"tasksFailed"`
+	Clusters    []FailureCluster `json:"clusters"`
+}
+
+// FailureCluster is a group of tasks that failed in the same way.
+type FailureCluster struct {
+	// Kind is where the tasks failed: setup, agent, verify, judge, or assertions
+	Kind string `json:"kind"`
+	// Signature identifies the cluster: the normalized error message, the
+	// judge failure category, or the failed assertion types
+	Signature string `json:"signature"`
+	// Example is the unnormalized failure message of the first task
+	Example string   `json:"example"`
+	Tasks   []string `json:"tasks"`
+
+	tokens map[string]bool
+}
+
+type taskFailure struct {
+	kind      string
+	signature string
+	message   string
+	// fuzzy failures are merged with similar signatures rather than only exact ones
+	fuzzy bool
+}
+
+// TriageFailures clusters failed tasks by where they failed and by the
+// similarity of their failure messages. Clusters are sorted by size.
+// similarity is the minimum Jaccard similarity between the words of two
+// normalized error messages for them to share a cluster.
+func TriageFailures(resultsFile string, results []*eval.EvalResult, similarity float64) *Triage {
+	triage := &Triage{
+		ResultsFile: resultsFile,
+		TasksTotal:  len(results),
+		Clusters:    []FailureCluster{},
+	}
+
+	var clusters []*FailureCluster
+	for _, r := range results {
+		f := classifyFailure(r)
+		if f == nil {
+			continue
+		}
+		triage.TasksFailed++
+
+		tokens := tokenize(f.signature)
+		var match *FailureCluster
+		for _, c := range clusters {
+			if c.Kind != f.kind {
+				continue
+			}
+			if c.Signature == f.signature || (f.fuzzy && jaccard(c.tokens, tokens) >= similarity) {
+				match = c
+				break
+			}
+		}
+
+		if match == nil {
+			match = &FailureCluster{
+				Kind:      f.kind,
+				Signature: f.signature,
+				Example:   f.message,
+				tokens:    tokens,
+			}
+			clusters = append(clusters, match)
+		}
+		match.Tasks = append(match.Tasks, r.TaskName)
+	}
+
+	// Stable sort keeps clusters of equal size in the order they were first seen
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Tasks) > len(clusters[j].Tasks)
+	})
+	for _, c := range clusters {
+		triage.Clusters = append(triage.Clusters, *c)
+	}
+
+	return triage
+}
+
+// classifyFailure returns why a task failed, or nil if it passed or was
+// skipped.
+func classifyFailure(r *eval.EvalResult) *taskFailure {
+	if (r.TaskPassed && r.AllAssertionsPassed) || r.SkipReason != "" {
+		return nil
+	}
+
+	if r.TaskPassed {
+		failures := failedAssertions(r.AssertionResults)
+		names := make([]string, 0, len(failures))
+		for _, f := range failures {
+			names = append(names, f.name)
+		}
+
+		f := &taskFailure{
+			kind:      FailureKindAssertions,
+			signature: strings.Join(names, ", "),
+			message:   FailureReason(r),
+		}
+		if f.signature == "" {
+			f.signature = "unknown"
+		}
+		return f
+	}
+
+	if category := JudgeFailureCategory(r); category != "" {
+		return &taskFailure{
+			kind:      FailureKindJudge,
+			signature: category,
+			message:   r.TaskJudgeReason,
+		}
+	}
+
+	var kind, message string
+	switch {
+	case r.AgentExecutionError:
+		kind, message = FailureKindAgent, r.TaskError
+	case r.VerifyOutput != nil:
+		kind, message = FailureKindVerify, phaseFailure(r.VerifyOutput)
+	default:
+		// Anything before the agent ran, including MCP servers failing to start
+		kind, message = FailureKindSetup, SetupFailure(r)
+	}
+	if message == "" {
+		message = r.TaskError
+	}
+
+	return &taskFailure{
+		kind:      kind,
+		signature: normalizeMessage(message),
+		message:   message,
+		fuzzy:     true,
+	}
+}
+
+var normalizers = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`https?://\S+`), "<url>"},
+	{regexp.MustCompile(`\d{1,3}(\.\d{1,3}){3}(:\d+)?`), "<ip>"},
+	{regexp.MustCompile(`(/[\w.\-]+){2,}/?`), "<path>"},
+	{regexp.MustCompile(`"[^"]*"|'[^']*'`), "<str>"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-f]{12,}\b`), "<hex>"},
+	{regexp.MustCompile(`\d+(\.\d+)?`), "<n>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+// normalizeMessage replaces the parts of an error message that differ between
+// otherwise identical failures, like names, paths, and numbers.
+func normalizeMessage(msg string) string {
+	msg = strings.ToLower(strings.TrimSpace(msg))
+	for _, n := range normalizers {
+		msg = n.pattern.ReplaceAllString(msg, n.replacement)
+	}
+	return strings.TrimSpace(msg)
+}
+
+var wordPattern = regexp.MustCompile(`<\w+>|\w+`)
+
+func tokenize(s string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, w := range wordPattern.FindAllString(s, -1) {
+		tokens[w] = true
+	}
+	return tokens
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	intersection := 0
+	for t := range a {
+		if b[t] {
+			intersection++
+		}
+	}
+
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
@@ -0,0 +1,122 @@
+package results
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+func TestTriageFailures(t *testing.T) {
+	setupFailure := func(name, msg string) *eval.EvalResult {
+		return &eval.EvalResult{
+			TaskName:  name,
+			TaskError: msg,
+			SetupOutput: &task.PhaseOutput{
+				Steps: []*steps.StepOutput{{Type: "script", Error: msg}},
+			},
+		}
+	}
+
+	evalResults := []*eval.EvalResult{
+		{TaskName: "passing", TaskPassed: true, AllAssertionsPassed: true},
+		setupFailure("create-pod", `error: failed to load kubeconfig "/home/a/.kube/config": unauthorized (401)`),
+		setupFailure("delete-pod", `error: failed to load kubeconfig "/home/b/.kube/config": unauthorized (401)`),
+		{
+			TaskName:            "agent-crash",
+			TaskError:           "agent exited with status 137",
+			AgentExecutionError: true,
+		},
+		{
+			TaskName:            "scale-deployment",
+			TaskError:           "agent exited with status 1",
+			AgentExecutionError: true,
+		},
+		{
+			TaskName:          "summarize-logs",
+			TaskError:         "one or more verification steps failed",
+			TaskJudgeCategory: "missing_information",
+			TaskJudgeReason:   "The response does not mention the failing pod",
+			VerifyOutput:      &task.PhaseOutput{},
+		},
+		{
+			TaskName:   "list-pods",
+			TaskPassed: true,
+			AssertionResults: &eval.CompositeAssertionResult{
+				ToolsUsed:    &eval.SingleAssertionResult{Passed: false, Reason: "pods_list not called"},
+				MinToolCalls: &eval.SingleAssertionResult{Passed: true},
+			},
+		},
+		{
+			TaskName:   "list-nodes",
+			TaskPassed: true,
+			AssertionResults: &eval.CompositeAssertionResult{
+				ToolsUsed: &eval.SingleAssertionResult{Passed: false, Reason: "nodes_list not called"},
+			},
+		},
+	}
+
+	triage := TriageFailures("results.json", evalResults, DefaultSimilarity)
+
+	if triage.TasksTotal != 8 || triage.TasksFailed != 7 {
+		t.Errorf("tasks = %d failed of %d, want 7 of 8", triage.TasksFailed, triage.TasksTotal)
+	}
+
+	type cluster struct {
+		Kind  string
+		Tasks []string
+	}
+	var got []cluster
+	for _, c := range triage.Clusters {
+		got = append(got, cluster{Kind: c.Kind, Tasks: c.Tasks})
+	}
+
+	want := []cluster{
+		{Kind: FailureKindSetup, Tasks: []string{"create-pod", "delete-pod"}},
+		{Kind: FailureKindAgent, Tasks: []string{"agent-crash", "scale-deployment"}},
+		{Kind: FailureKindAssertions, Tasks: []string{"list-pods", "list-nodes"}},
+		{Kind: FailureKindJudge, Tasks: []string{"summarize-logs"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clusters = %+v, want %+v", got, want)
+	}
+
+	if triage.Clusters[0].Example != `step 0 (script): error: failed to load kubeconfig "/home/a/.kube/config": unauthorized (401)` {
+		t.Errorf("unexpected example: %q", triage.Clusters[0].Example)
+	}
+	if triage.Clusters[3].Signature != "missing_information" {
+		t.Errorf("judge signature = %q, want missing_information", triage.Clusters[3].Signature)
+	}
+}
+
+func TestNormalizeMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{
+			msg:  `Pod "web-7f9c" not found in namespace 'default'`,
+			want: "pod <str> not found in namespace <str>",
+		},
+		{
+			msg:  "dial tcp 10.0.0.12:6443: connect: connection refused",
+			want: "dial tcp <ip>: connect: connection refused",
+		},
+		{
+			msg:  "open /tmp/run-42/output.json: no such file",
+			want: "open <path>: no such file",
+		},
+		{
+			msg:  "timed out after 30s waiting for https://example.com/healthz",
+			want: "timed out after <n>s waiting for <url>",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeMessage(tt.msg); got != tt.want {
+			t.Errorf("normalizeMessage(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
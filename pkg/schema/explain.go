@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+const defsPrefix = "#/$defs/"
+
+// Explanation documents a kind or one of its fields.
+type Explanation struct {
+	Kind string `json:"kind"`
+	// Field is the path of the field below the kind, empty for the kind itself
+	Field       string  `json:"field,omitempty"`
+	Type        string  `json:"type"`
+	Description string  `json:"description,omitempty"`
+	Fields      []Field `json:"fields,omitempty"`
+}
+
+// Field documents a field of an object.
+type Field struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+}
+
+// Explain documents the field at a dotted path such as "task.spec.verify",
+// where the first element is the kind. Lists and maps are transparent, so the
+// fields of a list of steps are the fields of a step.
+func Explain(path string) (*Explanation, error) {
+	parts := strings.Split(path, ".")
+
+	root, err := Load(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{
+		Kind:        root.Title,
+		Field:       strings.Join(parts[1:], "."),
+		Type:        "object",
+		Description: root.Description,
+	}
+
+	current := root
+	for i, name := range parts[1:] {
+		obj, err := element(root, current)
+		if err != nil {
+			return nil, err
+		}
+
+		field, ok := obj.Properties[name]
+		if !ok {
+			return nil, fmt.Errorf("field %q does not exist in %s", name, strings.Join(parts[:i+1], "."))
+		}
+
+		resolved, err := deref(root, field)
+		if err != nil {
+			return nil, err
+		}
+
+		explanation.Type = typeName(field)
+		// A description next to a $ref takes precedence over the referenced one
+		explanation.Description = field.Description
+		if explanation.Description == "" {
+			explanation.Description = resolved.Description
+		}
+		current = field
+	}
+
+	obj, err := element(root, current)
+	if err != nil {
+		return nil, err
+	}
+
+	required := make(map[string]bool, len(obj.Required))
+	for _, name := range obj.Required {
+		required[name] = true
+	}
+
+	for name, prop := range obj.Properties {
+		resolved, err := deref(root, prop)
+		if err != nil {
+			return nil, err
+		}
+
+		description := prop.Description
+		if description == "" {
+			description = resolved.Description
+		}
+
+		explanation.Fields = append(explanation.Fields, Field{
+			Name:        name,
+			Type:        typeName(prop),
+			Description: description,
+			Required:    required[name],
+			Deprecated:  prop.Deprecated || resolved.Deprecated,
+		})
+	}
+	sort.Slice(explanation.Fields, func(i, j int) bool {
+		return explanation.Fields[i].Name < explanation.Fields[j].Name
+	})
+
+	return explanation, nil
+}
+
+// deref follows a $ref to the schema's definitions
+func deref(root, s *jsonschema.Schema) (*jsonschema.Schema, error) {
+	for s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, defsPrefix)
+		if !ok {
+			return nil, fmt.Errorf("unsupported $ref %q", s.Ref)
+		}
+
+		def, ok := root.Defs[name]
+		if !ok {
+			return nil, fmt.Errorf("undefined $ref %q", s.Ref)
+		}
+		s = def
+	}
+
+	return s, nil
+}
+
+// element returns the object schema whose fields are listed for s, looking
+// through lists and maps.
+func element(root, s *jsonschema.Schema) (*jsonschema.Schema, error) {
+	s, err := deref(root, s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case s.Items != nil:
+		return element(root, s.Items)
+	case len(s.Properties) == 0 && s.AdditionalProperties != nil:
+		return element(root, s.AdditionalProperties)
+	default:
+		return s, nil
+	}
+}
+
+// typeName returns a Go-like name for the type of s, such as "[]Step" or
+// "map[string]string"
+func typeName(s *jsonschema.Schema) string {
+	if name, ok := strings.CutPrefix(s.Ref, defsPrefix); ok {
+		return name
+	}
+
+	switch s.Type {
+	case "array":
+		if s.Items == nil {
+			return "[]any"
+		}
+		return "[]" + typeName(s.Items)
+	case "object":
+		if len(s.Properties) == 0 && s.AdditionalProperties != nil {
+			return "map[string]" + typeName(s.AdditionalProperties)
+		}
+		return "object"
+	case "":
+		if len(s.Types) > 0 {
+			return strings.Join(s.Types, "|")
+		}
+		return "any"
+	default:
+		return s.Type
+	}
+}
@@ -0,0 +1,59 @@
+// Package schema provides the JSON schemas of the Eval, Task, and Agent config
+// files and documentation lookups for their fields.
+package schema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+//go:embed *.json
+var files embed.FS
+
+// kindFiles maps each kind to its schema file
+var kindFiles = map[string]string{
+	"Agent": "agent.json",
+	"Eval":  "eval.json",
+	"Task":  "task.json",
+}
+
+// Kinds returns the kinds that have a schema, in alphabetical order.
+func Kinds() []string {
+	kinds := make([]string, 0, len(kindFiles))
+	for kind := range kindFiles {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Raw returns the JSON schema of a kind. Kind names are case-insensitive.
+func Raw(kind string) ([]byte, error) {
+	for k, file := range kindFiles {
+		if strings.EqualFold(k, kind) {
+			return files.ReadFile(file)
+		}
+	}
+
+	return nil, fmt.Errorf("unknown kind %q: must be one of %s", kind, strings.Join(Kinds(), ", "))
+}
+
+// Load returns the parsed JSON schema of a kind.
+func Load(kind string) (*jsonschema.Schema, error) {
+	data, err := Raw(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &jsonschema.Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema for %s: %w", kind, err)
+	}
+
+	return s, nil
+}
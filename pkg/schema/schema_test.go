@@ -0,0 +1,262 @@
+package schema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKinds(t *testing.T) {
+	assert.Equal(t, []string{"Agent", "Eval", "Task"}, Kinds())
+}
+
+func TestLoad(t *testing.T) {
+	for _, kind := range Kinds() {
+		t.Run(kind, func(t *testing.T) {
+			s, err := Load(strings.ToLower(kind))
+			require.NoError(t, err)
+			assert.Equal(t, kind, s.Title)
+
+			_, err = s.Resolve(nil)
+			assert.NoError(t, err, "schema should be valid")
+		})
+	}
+
+	_, err := Load("Pipeline")
+	assert.ErrorContains(t, err, "unknown kind")
+}
+
+// TestSchemasMatchTypes checks that the schemas document exactly the fields of
+// the Go types the config files are decoded into.
+func TestSchemasMatchTypes(t *testing.T) {
+	tests := map[string]struct {
+		kind  string
+		def   string
+		typ   reflect.Type
+		extra []string
+	}{
+		// include is resolved before the eval file is decoded
+		"eval":  {kind: "Eval", typ: reflect.TypeFor[eval.EvalSpec](), extra: []string{"include"}},
+		"agent": {kind: "Agent", typ: reflect.TypeFor[agent.AgentSpec]()},
+		// steps is only decoded for apiVersion mcpchecker/v1alpha1
+		"task":              {kind: "Task", typ: reflect.TypeFor[task.TaskConfig](), extra: []string{"steps"}},
+		"task v1alpha1":     {kind: "Task", def: "TaskStepsV1Alpha1", typ: reflect.TypeFor[task.TaskStepsV1Alpha1]()},
+		"task file":         {kind: "Task", def: "File", typ: reflect.TypeFor[task.File]()},
+		"prompt image":      {kind: "Task", def: "PromptImage", typ: reflect.TypeFor[task.PromptImage]()},
+		"precondition":      {kind: "Task", def: "Precondition", typ: reflect.TypeFor[task.Precondition]()},
+		"script step":       {kind: "Task", def: "ScriptStep", typ: reflect.TypeFor[steps.ScriptStepConfig]()},
+		"http step":         {kind: "Task", def: "HttpStep", typ: reflect.TypeFor[steps.HttpStepConfig]()},
+		"llm judge step":    {kind: "Task", def: "LLMJudgeStep", typ: reflect.TypeFor[llmjudge.LLMJudgeStepConfig]()},
+		"snapshot step":     {kind: "Task", def: "SnapshotStep", typ: reflect.TypeFor[steps.SnapshotStepConfig]()},
+		"wait step":         {kind: "Task", def: "WaitStep", typ: reflect.TypeFor[steps.WaitStepConfig]()},
+		"quarantine entry":  {kind: "Eval", def: "QuarantinedTask", typ: reflect.TypeFor[eval.QuarantinedTask]()},
+		"task assertions":   {kind: "Eval", def: "TaskAssertions", typ: reflect.TypeFor[eval.TaskAssertions]()},
+		"call order assert": {kind: "Eval", def: "CallOrderAssertion", typ: reflect.TypeFor[eval.CallOrderAssertion]()},
+		"phase assert":      {kind: "Eval", def: "PhaseAssertion", typ: reflect.TypeFor[eval.PhaseAssertion]()},
+		"tool call count":   {kind: "Eval", def: "ToolCallCountAssertion", typ: reflect.TypeFor[eval.ToolCallCountAssertion]()},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			root, err := Load(tc.kind)
+			require.NoError(t, err)
+
+			s := root
+			if tc.def != "" {
+				s = root.Defs[tc.def]
+				require.NotNil(t, s, "missing definition %s", tc.def)
+			}
+
+			checkFields(t, root, s, tc.typ, tc.kind, tc.extra...)
+		})
+	}
+}
+
+func TestStepSchemaCoversRegisteredTypes(t *testing.T) {
+	root, err := Load("Task")
+	require.NoError(t, err)
+
+	for _, stepType := range steps.DefaultRegistry.Types() {
+		assert.Contains(t, root.Defs["Step"].Properties, stepType)
+	}
+}
+
+// checkFields compares the JSON fields of typ with the properties of s, and
+// recurses into fields that are structs
+func checkFields(t *testing.T, root, s *jsonschema.Schema, typ reflect.Type, path string, extra ...string) {
+	t.Helper()
+
+	obj, err := element(root, s)
+	require.NoError(t, err, path)
+
+	fields := jsonFields(typ)
+
+	want := make([]string, 0, len(fields)+len(extra))
+	for name := range fields {
+		want = append(want, name)
+	}
+	want = append(want, extra...)
+	sort.Strings(want)
+
+	got := make([]string, 0, len(obj.Properties))
+	for name := range obj.Properties {
+		got = append(got, name)
+	}
+	sort.Strings(got)
+
+	if !assert.Equal(t, want, got, "fields of %s", path) {
+		return
+	}
+
+	for name, fieldType := range fields {
+		if elem := structType(fieldType); elem != nil {
+			checkFields(t, root, obj.Properties[name], elem, path+"."+name)
+		}
+	}
+}
+
+// jsonFields returns the JSON field names of a struct and their types,
+// including the fields of inlined and embedded structs
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			for k, v := range jsonFields(derefType(f.Type)) {
+				fields[k] = v
+			}
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+
+	return fields
+}
+
+// structType returns the struct type of a field, looking through pointers,
+// slices, and map values, or nil if it is not a struct
+func structType(typ reflect.Type) reflect.Type {
+	for {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			typ = typ.Elem()
+		case reflect.Struct:
+			return typ
+		default:
+			return nil
+		}
+	}
+}
+
+func derefType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Pointer {
+		return typ.Elem()
+	}
+	return typ
+}
+
+func TestExplain(t *testing.T) {
+	tests := map[string]struct {
+		path       string
+		kind       string
+		field      string
+		typ        string
+		fields     []string
+		required   []string
+		descPrefix string
+	}{
+		"kind": {
+			path:       "eval",
+			kind:       "Eval",
+			typ:        "object",
+			fields:     []string{"apiVersion", "config", "include", "kind", "metadata"},
+			required:   []string{"kind", "metadata"},
+			descPrefix: "An Eval runs an agent",
+		},
+		"list of steps": {
+			path:       "task.spec.verify",
+			kind:       "Task",
+			field:      "spec.verify",
+			typ:        "[]Step",
+			fields:     []string{"extract", "http", "llmJudge", "script", "snapshot", "wait"},
+			descPrefix: "Steps run after the agent",
+		},
+		"map values": {
+			path:     "Task.spec.mcpServers.auth",
+			kind:     "Task",
+			field:    "spec.mcpServers.auth",
+			typ:      "AuthConfig",
+			fields:   []string{"clientId", "clientSecret", "endpointParams", "scopes", "token", "tokenUrl", "type"},
+			required: []string{"type"},
+		},
+		"description next to ref": {
+			path:       "task.spec.prompt",
+			kind:       "Task",
+			field:      "spec.prompt",
+			typ:        "Prompt",
+			fields:     []string{"file", "files", "images", "inline"},
+			descPrefix: "The prompt given to the agent.",
+		},
+		"scalar": {
+			path:       "agent.commands.runPrompt",
+			kind:       "Agent",
+			field:      "commands.runPrompt",
+			typ:        "string",
+			descPrefix: "Template for the command",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := Explain(tc.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.kind, e.Kind)
+			assert.Equal(t, tc.field, e.Field)
+			assert.Equal(t, tc.typ, e.Type)
+			assert.True(t, strings.HasPrefix(e.Description, tc.descPrefix), "description %q", e.Description)
+
+			var names, required []string
+			for _, f := range e.Fields {
+				names = append(names, f.Name)
+				if f.Required {
+					required = append(required, f.Name)
+				}
+			}
+			assert.Equal(t, tc.fields, names)
+			assert.Equal(t, tc.required, required)
+		})
+	}
+}
+
+func TestExplainErrors(t *testing.T) {
+	_, err := Explain("pipeline.spec")
+	assert.ErrorContains(t, err, "unknown kind")
+
+	_, err = Explain("task.spec.verfy")
+	assert.ErrorContains(t, err, `field "verfy" does not exist in task.spec`)
+}
@@ -0,0 +1,169 @@
+// Package shell runs agent commands and scripts with a command interpreter,
+// so that eval suites run on Unix with a POSIX shell and on Windows with
+// PowerShell or cmd.
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// EnvShell selects the shell commands and scripts are run with. It takes
+// precedence over SHELL on Unix and over the PowerShell and cmd defaults on
+// Windows.
+const EnvShell = "MCPCHECKER_SHELL"
+
+// Kind is the syntax family of a shell
+type Kind string
+
+const (
+	KindPosix      Kind = "posix"
+	KindPowerShell Kind = "powershell"
+	KindCmd        Kind = "cmd"
+)
+
+// Shell is a command interpreter
+type Shell struct {
+	// Path is the executable of the shell, either a path or a name looked up in PATH
+	Path string
+	Kind Kind
+}
+
+// New returns the shell for an executable, inferring its kind from its name
+func New(path string) *Shell {
+	// Windows paths are split on both separators, so that they are recognized
+	// in config files written on other platforms
+	name := path[strings.LastIndexAny(path, `/\`)+1:]
+	name = strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+
+	kind := KindPosix
+	switch name {
+	case "pwsh", "powershell":
+		kind = KindPowerShell
+	case "cmd":
+		kind = KindCmd
+	}
+
+	return &Shell{Path: path, Kind: kind}
+}
+
+// Default returns the shell set in MCPCHECKER_SHELL, or the default shell of
+// the platform
+func Default() *Shell {
+	if path := os.Getenv(EnvShell); path != "" {
+		return New(path)
+	}
+
+	return New(defaultPath())
+}
+
+// Command returns a command that runs a single command line
+func (s *Shell) Command(ctx context.Context, line string) *exec.Cmd {
+	switch s.Kind {
+	case KindPowerShell:
+		return exec.CommandContext(ctx, s.Path, "-NoProfile", "-NonInteractive", "-Command", line)
+	case KindCmd:
+		return cmdCommand(ctx, s.Path, line)
+	default:
+		return exec.CommandContext(ctx, s.Path, "-c", line)
+	}
+}
+
+// Script returns a command that runs an inline script of several lines. cmd
+// cannot read scripts from its arguments or stdin, so they are written to a
+// temporary batch file that is removed once ctx is done.
+func (s *Shell) Script(ctx context.Context, script string) (*exec.Cmd, error) {
+	switch s.Kind {
+	case KindPowerShell:
+		return s.Command(ctx, script), nil
+	case KindCmd:
+		tmpPath, err := WriteTemp("", "mcpchecker-step-*.cmd", script)
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			<-ctx.Done()
+			os.Remove(tmpPath)
+		}()
+		return cmdCommand(ctx, s.Path, tmpPath), nil
+	default:
+		cmd := exec.CommandContext(ctx, s.Path)
+		cmd.Stdin = strings.NewReader(script)
+		return cmd, nil
+	}
+}
+
+// File returns a command that runs a script file. PowerShell and batch
+// scripts are run with their interpreter based on their extension, other
+// files are run as executables on Unix so that their shebang is respected,
+// and with the shell on Windows if it is a POSIX shell.
+func (s *Shell) File(ctx context.Context, path string) (*exec.Cmd, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ps1":
+		interpreter := s.Path
+		if s.Kind != KindPowerShell {
+			interpreter = defaultPowerShell()
+		}
+		return exec.CommandContext(ctx, interpreter, "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", path), nil
+	case ".cmd", ".bat":
+		interpreter := s.Path
+		if s.Kind != KindCmd {
+			interpreter = defaultCmd()
+		}
+		return cmdCommand(ctx, interpreter, path), nil
+	}
+
+	return s.fileCommand(ctx, path)
+}
+
+// Quote quotes a string as a single word in the shell's syntax
+func (s *Shell) Quote(value string) string {
+	switch s.Kind {
+	case KindPowerShell:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case KindCmd:
+		return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	default:
+		return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+	}
+}
+
+// WriteTemp writes content to a new temporary file in dir, or in the default
+// temporary directory if dir is empty, and returns its path
+func WriteTemp(dir, pattern, content string) (string, error) {
+	tmpFile, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp script file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to write temp script: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to write temp script: %w", err)
+	}
+
+	return tmpPath, nil
+}
+
+func defaultPowerShell() string {
+	if _, err := exec.LookPath("pwsh"); err == nil {
+		return "pwsh"
+	}
+	return "powershell"
+}
+
+func defaultCmd() string {
+	if comspec := os.Getenv("ComSpec"); comspec != "" {
+		return comspec
+	}
+	return "cmd.exe"
+}
@@ -0,0 +1,83 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := map[string]Kind{
+		"/bin/bash":    KindPosix,
+		"/usr/bin/zsh": KindPosix,
+		"pwsh":         KindPowerShell,
+		`C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`: KindPowerShell,
+		"cmd.exe": KindCmd,
+		"CMD":     KindCmd,
+	}
+
+	for path, kind := range tests {
+		t.Run(path, func(t *testing.T) {
+			assert.Equal(t, kind, New(path).Kind)
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Setenv(EnvShell, "/bin/zsh")
+	assert.Equal(t, &Shell{Path: "/bin/zsh", Kind: KindPosix}, Default())
+}
+
+func TestQuote(t *testing.T) {
+	value := `it's "done"`
+
+	assert.Equal(t, `'it'\''s "done"'`, New("bash").Quote(value))
+	assert.Equal(t, `'it''s "done"'`, New("pwsh").Quote(value))
+	assert.Equal(t, `"it's ""done"""`, New("cmd").Quote(value))
+}
+
+func TestPosixShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	sh := New("/bin/sh")
+	ctx := context.Background()
+
+	t.Run("command", func(t *testing.T) {
+		out, err := sh.Command(ctx, "echo hello "+sh.Quote("it's")).CombinedOutput()
+		require.NoError(t, err)
+		assert.Equal(t, "hello it's\n", string(out))
+	})
+
+	t.Run("script", func(t *testing.T) {
+		cmd, err := sh.Script(ctx, "greeting=hello\necho $greeting\n")
+		require.NoError(t, err)
+
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err)
+		assert.Equal(t, "hello\n", string(out))
+	})
+
+	t.Run("file with shebang", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "script.sh")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho from file\n"), 0644))
+
+		cmd, err := sh.File(ctx, path)
+		require.NoError(t, err)
+
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err)
+		assert.Equal(t, "from file\n", string(out))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := sh.File(ctx, filepath.Join(t.TempDir(), "missing.sh"))
+		assert.ErrorContains(t, err, "failed to stat file")
+	})
+}
@@ -0,0 +1,47 @@
+//go:build !windows
+
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// defaultPath returns SHELL, falling back to bash
+func defaultPath() string {
+	if shell, ok := os.LookupEnv("SHELL"); ok {
+		return shell
+	}
+	return "/usr/bin/bash"
+}
+
+func cmdCommand(ctx context.Context, path, line string) *exec.Cmd {
+	return exec.CommandContext(ctx, path, "/C", line)
+}
+
+// fileCommand runs the file directly to respect its shebang
+func (s *Shell) fileCommand(ctx context.Context, path string) (*exec.Cmd, error) {
+	if err := ensureExecutable(path); err != nil {
+		return nil, err
+	}
+	return exec.CommandContext(ctx, path), nil
+}
+
+func ensureExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if info.Mode()&0100 != 0 {
+		return nil
+	}
+
+	if err := os.Chmod(path, info.Mode()|0111); err != nil {
+		return fmt.Errorf("failed to make script executable: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// defaultPath returns PowerShell, or cmd if it is not installed. SHELL is
+// ignored, as shells such as Git Bash set it to paths that only they resolve.
+func defaultPath() string {
+	for _, name := range []string{"pwsh", "powershell"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return defaultCmd()
+}
+
+// cmdCommand passes the command line to cmd verbatim, as cmd does not parse
+// its arguments with the quoting rules exec uses
+func cmdCommand(ctx context.Context, path, line string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, path)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: fmt.Sprintf(`%s /S /C "%s"`, syscall.EscapeArg(path), line)}
+	return cmd
+}
+
+// fileCommand runs the file with the shell if it is a POSIX shell, e.g. Git
+// Bash, as Windows does not support shebangs
+func (s *Shell) fileCommand(ctx context.Context, path string) (*exec.Cmd, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if s.Kind != KindPosix {
+		return nil, fmt.Errorf("cannot run script %s with %s: use a .ps1, .cmd, or .bat script, or set %s to a POSIX shell such as bash", path, s.Path, EnvShell)
+	}
+
+	return exec.CommandContext(ctx, s.Path, path), nil
+}
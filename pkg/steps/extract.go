@@ -0,0 +1,344 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultAnswerName is the output an extract step stores the answer in, if it
+// is not given a name
+const DefaultAnswerName = "answer"
+
+type ExtractStepConfig struct {
+	// Regex is matched against the agent output. The answer is the first
+	// group of the match if the regex has groups, or the whole match.
+	Regex string `json:"regex,omitempty"`
+	// JSONPointer selects the answer from the agent output parsed as JSON, as
+	// an RFC 6901 pointer like /result/count. If the output is not JSON, the
+	// first JSON object or array in it is used.
+	JSONPointer string `json:"jsonPointer,omitempty"`
+	// Name is the output the answer is stored in
+	Name string `json:"name,omitempty"`
+	// Expect compares the answer to the ground truth. Without it, the answer
+	// is only recorded.
+	Expect *AnswerExpect `json:"expect,omitempty"`
+}
+
+// AnswerExpect is the ground truth an extracted answer is compared to.
+// Exactly one of Equals, OneOf, Number, or Match must be set.
+type AnswerExpect struct {
+	// Equals is the exact answer, compared after trimming whitespace
+	Equals *string `json:"equals,omitempty"`
+	// OneOf lists the accepted answers, compared like Equals
+	OneOf []string `json:"oneOf,omitempty"`
+	// Number is the numeric answer. The answer may use thousands separators,
+	// and may differ by up to Tolerance.
+	Number    *float64 `json:"number,omitempty"`
+	Tolerance float64  `json:"tolerance,omitempty"`
+	// Match is a regular expression the whole answer must match
+	Match *string `json:"match,omitempty"`
+	// IgnoreCase compares Equals, OneOf, and Match case-insensitively
+	IgnoreCase bool `json:"ignoreCase,omitempty"`
+}
+
+type ExtractStep struct {
+	regex       *regexp.Regexp
+	jsonPointer []string
+	name        string
+	expect      *AnswerExpect
+	match       *regexp.Regexp
+}
+
+var _ StepRunner = &ExtractStep{}
+
+func ParseExtractStep(raw json.RawMessage) (StepRunner, error) {
+	cfg := &ExtractStepConfig{}
+
+	err := json.Unmarshal(raw, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewExtractStep(cfg)
+}
+
+func NewExtractStep(cfg *ExtractStepConfig) (*ExtractStep, error) {
+	if (cfg.Regex == "") == (cfg.JSONPointer == "") {
+		return nil, fmt.Errorf("exactly one of regex or jsonPointer must be specified")
+	}
+
+	step := &ExtractStep{
+		name:   cfg.Name,
+		expect: cfg.Expect,
+	}
+	if step.name == "" {
+		step.name = DefaultAnswerName
+	}
+
+	if cfg.Regex != "" {
+		re, err := regexp.Compile(cfg.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse regex: %w", err)
+		}
+		step.regex = re
+	}
+
+	if cfg.JSONPointer != "" {
+		tokens, err := parseJSONPointer(cfg.JSONPointer)
+		if err != nil {
+			return nil, err
+		}
+		step.jsonPointer = tokens
+	}
+
+	if cfg.Expect != nil {
+		match, err := cfg.Expect.validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid expect for extract step: %w", err)
+		}
+		step.match = match
+	}
+
+	return step, nil
+}
+
+func (s *ExtractStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
+	if input.Agent == nil {
+		return nil, fmt.Errorf("cannot run extract step before agent (must be in verification)")
+	}
+
+	answer, err := s.extract(input.Agent.Output)
+	if err != nil {
+		return &StepOutput{
+			Type:    "extract",
+			Success: false,
+			Error:   fmt.Sprintf("no answer found in agent output: %s", err),
+		}, nil
+	}
+
+	out := &StepOutput{
+		Type:    "extract",
+		Success: true,
+		Message: fmt.Sprintf("extracted %s %q", s.name, answer),
+		Outputs: map[string]string{
+			s.name: answer,
+		},
+	}
+
+	if s.expect != nil {
+		if err := s.expect.compare(answer, s.match); err != nil {
+			out.Success = false
+			out.Error = fmt.Sprintf("%s %q: %s", s.name, answer, err)
+		}
+	}
+
+	return out, nil
+}
+
+// extract returns the answer in the output
+func (s *ExtractStep) extract(output string) (string, error) {
+	if s.regex != nil {
+		match := s.regex.FindStringSubmatch(output)
+		if match == nil {
+			return "", fmt.Errorf("regex %q did not match", s.regex)
+		}
+		if len(match) > 1 {
+			return strings.TrimSpace(match[1]), nil
+		}
+		return strings.TrimSpace(match[0]), nil
+	}
+
+	doc, err := findJSON(output)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := resolveJSONPointer(doc, s.jsonPointer)
+	if err != nil {
+		return "", err
+	}
+
+	return jsonAnswer(value)
+}
+
+// validate checks that exactly one comparison is set, and returns the
+// compiled Match regex if it is set
+func (e *AnswerExpect) validate() (*regexp.Regexp, error) {
+	set := 0
+	if e.Equals != nil {
+		set++
+	}
+	if len(e.OneOf) > 0 {
+		set++
+	}
+	if e.Number != nil {
+		set++
+	}
+	if e.Match != nil {
+		set++
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("exactly one of equals, oneOf, number, or match must be specified")
+	}
+
+	if e.Tolerance < 0 {
+		return nil, fmt.Errorf("tolerance must not be negative")
+	}
+
+	if e.Match == nil {
+		return nil, nil
+	}
+
+	pattern := "^(?:" + *e.Match + ")$"
+	if e.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	match, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse match: %w", err)
+	}
+	return match, nil
+}
+
+// compare returns an error describing how the answer differs from the ground
+// truth, or nil if it is correct
+func (e *AnswerExpect) compare(answer string, match *regexp.Regexp) error {
+	answer = strings.TrimSpace(answer)
+
+	switch {
+	case e.Equals != nil:
+		if !e.equal(answer, *e.Equals) {
+			return fmt.Errorf("expected %q", *e.Equals)
+		}
+	case len(e.OneOf) > 0:
+		if !slices.ContainsFunc(e.OneOf, func(expected string) bool { return e.equal(answer, expected) }) {
+			return fmt.Errorf("expected one of %q", e.OneOf)
+		}
+	case e.Number != nil:
+		actual, err := strconv.ParseFloat(strings.ReplaceAll(answer, ",", ""), 64)
+		if err != nil {
+			return fmt.Errorf("expected a number")
+		}
+		if math.Abs(actual-*e.Number) > e.Tolerance {
+			if e.Tolerance > 0 {
+				return fmt.Errorf("expected %v ± %v", *e.Number, e.Tolerance)
+			}
+			return fmt.Errorf("expected %v", *e.Number)
+		}
+	case match != nil:
+		if !match.MatchString(answer) {
+			return fmt.Errorf("expected to match %q", *e.Match)
+		}
+	}
+
+	return nil
+}
+
+func (e *AnswerExpect) equal(answer, expected string) bool {
+	expected = strings.TrimSpace(expected)
+	if e.IgnoreCase {
+		return strings.EqualFold(answer, expected)
+	}
+	return answer == expected
+}
+
+// parseJSONPointer splits an RFC 6901 pointer into its unescaped reference
+// tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("jsonPointer %q must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// resolveJSONPointer returns the value the reference tokens point to in doc
+func resolveJSONPointer(doc any, tokens []string) (any, error) {
+	value := doc
+	for i, token := range tokens {
+		path := "/" + strings.Join(tokens[:i+1], "/")
+
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("no value at %s", path)
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("no value at %s", path)
+			}
+			value = v[index]
+		default:
+			return nil, fmt.Errorf("no value at %s", path)
+		}
+	}
+	return value, nil
+}
+
+// findJSON parses the output as JSON, or else the first JSON object or array
+// in it, such as one in a code block of a text answer
+func findJSON(output string) (any, error) {
+	if doc, err := decodeJSON(output, true); err == nil {
+		return doc, nil
+	}
+
+	for i, c := range output {
+		if c != '{' && c != '[' {
+			continue
+		}
+		if doc, err := decodeJSON(output[i:], false); err == nil {
+			return doc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("agent output contains no JSON")
+}
+
+// decodeJSON decodes the JSON value at the start of s, keeping numbers as
+// written. If whole is true, s must hold nothing else.
+func decodeJSON(s string, whole bool) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if whole {
+		if _, err := dec.Token(); err != io.EOF {
+			return nil, fmt.Errorf("unexpected content after JSON value")
+		}
+	}
+	return doc, nil
+}
+
+// jsonAnswer formats a JSON value as an answer: strings and numbers as
+// written, other values as compact JSON
+func jsonAnswer(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v), nil
+	case json.Number:
+		return v.String(), nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to format answer: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,198 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractStep(t *testing.T) {
+	tests := map[string]struct {
+		config          string
+		output          string
+		expectedSuccess bool
+		expectedOutputs map[string]string
+		errContains     string
+	}{
+		"regex group": {
+			config:          `{"regex": "Answer:\\s*(\\S+)"}`,
+			output:          "The pods are listed below.\nAnswer: 3",
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": "3"},
+		},
+		"regex without group": {
+			config:          `{"regex": "nginx-[a-z0-9]+", "name": "pod"}`,
+			output:          "The pod nginx-7f9c is running",
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"pod": "nginx-7f9c"},
+		},
+		"regex does not match": {
+			config:      `{"regex": "Answer: (\\d+)"}`,
+			output:      "I could not count the pods",
+			errContains: `no answer found in agent output: regex "Answer: (\\d+)" did not match`,
+		},
+		"json pointer in whole output": {
+			config:          `{"jsonPointer": "/pods/1/name"}`,
+			output:          `{"pods": [{"name": "web"}, {"name": "db"}]}`,
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": "db"},
+		},
+		"json pointer in text": {
+			config:          `{"jsonPointer": "/count"}`,
+			output:          "Here is the result {as requested}:\n```json\n{\"count\": 1.50}\n```",
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": "1.50"},
+		},
+		"json pointer to object": {
+			config:          `{"jsonPointer": "/labels"}`,
+			output:          `{"labels": {"app": "web"}}`,
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": `{"app":"web"}`},
+		},
+		"json pointer with escapes": {
+			config:          `{"jsonPointer": "/app.kubernetes.io~1name"}`,
+			output:          `{"app.kubernetes.io/name": "web"}`,
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": "web"},
+		},
+		"json pointer to missing value": {
+			config:      `{"jsonPointer": "/pods/2"}`,
+			output:      `{"pods": []}`,
+			errContains: "no value at /pods/2",
+		},
+		"no json": {
+			config:      `{"jsonPointer": "/count"}`,
+			output:      "There are 3 pods",
+			errContains: "agent output contains no JSON",
+		},
+		"equals": {
+			config:          `{"regex": "Answer: (.+)", "expect": {"equals": "Paris"}}`,
+			output:          "Answer: Paris ",
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": "Paris"},
+		},
+		"equals wrong answer": {
+			config:          `{"regex": "Answer: (.+)", "expect": {"equals": "Paris"}}`,
+			output:          "Answer: Lyon",
+			expectedOutputs: map[string]string{"answer": "Lyon"},
+			errContains:     `answer "Lyon": expected "Paris"`,
+		},
+		"equals ignoring case": {
+			config:          `{"regex": "Answer: (.+)", "expect": {"equals": "Paris", "ignoreCase": true}}`,
+			output:          "Answer: PARIS",
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": "PARIS"},
+		},
+		"one of": {
+			config:          `{"regex": "Status: (\\w+)", "expect": {"oneOf": ["Running", "Succeeded"]}}`,
+			output:          "Status: Succeeded",
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": "Succeeded"},
+		},
+		"number with separators": {
+			config:          `{"regex": "Total: ([\\d,.]+)", "expect": {"number": 1234.5}}`,
+			output:          "Total: 1,234.50",
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": "1,234.50"},
+		},
+		"number within tolerance": {
+			config:          `{"jsonPointer": "/ratio", "expect": {"number": 0.33, "tolerance": 0.01}}`,
+			output:          `{"ratio": 0.333}`,
+			expectedSuccess: true,
+			expectedOutputs: map[string]string{"answer": "0.333"},
+		},
+		"number out of tolerance": {
+			config:          `{"jsonPointer": "/ratio", "expect": {"number": 0.5, "tolerance": 0.01}}`,
+			output:          `{"ratio": 0.333}`,
+			expectedOutputs: map[string]string{"answer": "0.333"},
+			errContains:     `answer "0.333": expected 0.5 ± 0.01`,
+		},
+		"not a number": {
+			config:          `{"regex": "Answer: (.+)", "expect": {"number": 3}}`,
+			output:          "Answer: three",
+			expectedOutputs: map[string]string{"answer": "three"},
+			errContains:     "expected a number",
+		},
+		"match is anchored": {
+			config:          `{"regex": "Image: (\\S+)", "expect": {"match": "nginx:1\\.\\d+"}}`,
+			output:          "Image: nginx:1.25-alpine",
+			expectedOutputs: map[string]string{"answer": "nginx:1.25-alpine"},
+			errContains:     `expected to match "nginx:1\\.\\d+"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			step, err := ParseExtractStep(json.RawMessage(tc.config))
+			require.NoError(t, err)
+
+			out, err := step.Execute(context.Background(), &StepInput{
+				Agent: &AgentContext{Prompt: "prompt", Output: tc.output},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, "extract", out.Type)
+			assert.Equal(t, tc.expectedSuccess, out.Success)
+			assert.Equal(t, tc.expectedOutputs, out.Outputs)
+			if tc.errContains != "" {
+				assert.Contains(t, out.Error, tc.errContains)
+			} else {
+				assert.Empty(t, out.Error)
+			}
+		})
+	}
+}
+
+func TestParseExtractStepInvalid(t *testing.T) {
+	tests := map[string]struct {
+		config      string
+		errContains string
+	}{
+		"neither regex nor json pointer": {
+			config:      `{}`,
+			errContains: "exactly one of regex or jsonPointer must be specified",
+		},
+		"both regex and json pointer": {
+			config:      `{"regex": ".*", "jsonPointer": "/a"}`,
+			errContains: "exactly one of regex or jsonPointer must be specified",
+		},
+		"invalid regex": {
+			config:      `{"regex": "("}`,
+			errContains: "failed to parse regex",
+		},
+		"relative json pointer": {
+			config:      `{"jsonPointer": "count"}`,
+			errContains: `jsonPointer "count" must start with /`,
+		},
+		"no comparison": {
+			config:      `{"regex": ".*", "expect": {}}`,
+			errContains: "exactly one of equals, oneOf, number, or match must be specified",
+		},
+		"two comparisons": {
+			config:      `{"regex": ".*", "expect": {"equals": "a", "number": 1}}`,
+			errContains: "exactly one of equals, oneOf, number, or match must be specified",
+		},
+		"negative tolerance": {
+			config:      `{"regex": ".*", "expect": {"number": 1, "tolerance": -1}}`,
+			errContains: "tolerance must not be negative",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseExtractStep(json.RawMessage(tc.config))
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
+
+func TestExtractStepRequiresAgent(t *testing.T) {
+	step, err := ParseExtractStep(json.RawMessage(`{"regex": ".*"}`))
+	require.NoError(t, err)
+
+	_, err = step.Execute(context.Background(), &StepInput{})
+	assert.ErrorContains(t, err, "must be in verification")
+}
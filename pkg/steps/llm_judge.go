@@ -61,6 +61,9 @@ func (s *LLMJudgeStep) Execute(ctx context.Context, input *StepInput) (*StepOutp
 
 	if !res.Passed {
 		out.Error = fmt.Sprintf("llm judge failed for reason '%s': %s", res.FailureCategory, res.Reason)
+		out.Outputs = map[string]string{
+			"failureCategory": res.FailureCategory,
+		}
 	}
 
 	return out, nil
@@ -156,6 +156,9 @@ func TestLLMJudgeStep_Execute(t *testing.T) {
 				Success: false,
 				Message: "output does not match exactly",
 				Error:   "llm judge failed for reason 'semantic_mismatch': output does not match exactly",
+				Outputs: map[string]string{
+					"failureCategory": "semantic_mismatch",
+				},
 			},
 			expectErr: false,
 		},
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -13,10 +14,15 @@ type Parser func(raw json.RawMessage) (StepRunner, error)
 
 type PrefixParser func(suffix string, raw json.RawMessage) (StepRunner, error)
 
+// NestedParser parses a step that wraps other steps, which it parses with the
+// registry the step itself is parsed with, so that they can use its extensions
+type NestedParser func(registry *Registry, raw json.RawMessage) (StepRunner, error)
+
 type Registry struct {
 	mu            sync.RWMutex
 	parsers       map[string]Parser
 	prefixParsers map[string]PrefixParser
+	nestedParsers map[string]NestedParser
 }
 
 func (r *Registry) Register(stepType string, parser Parser) error {
@@ -24,7 +30,7 @@ func (r *Registry) Register(stepType string, parser Parser) error {
 	defer r.mu.Unlock()
 
 	_, exists := r.parsers[stepType]
-	if exists {
+	if _, nested := r.nestedParsers[stepType]; exists || nested {
 		return fmt.Errorf("a parser already exists for type '%s'", stepType)
 	}
 
@@ -33,6 +39,21 @@ func (r *Registry) Register(stepType string, parser Parser) error {
 	return nil
 }
 
+// RegisterNested registers the parser of a step type that wraps other steps
+func (r *Registry) RegisterNested(stepType string, parser NestedParser) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.parsers[stepType]
+	if _, nested := r.nestedParsers[stepType]; exists || nested {
+		return fmt.Errorf("a parser already exists for type '%s'", stepType)
+	}
+
+	r.nestedParsers[stepType] = parser
+
+	return nil
+}
+
 func (r *Registry) RegisterPrefix(prefix string, parser PrefixParser) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -47,14 +68,26 @@ func (r *Registry) RegisterPrefix(prefix string, parser PrefixParser) error {
 	return nil
 }
 
+// Types returns the registered step types in alphabetical order
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := slices.AppendSeq(slices.Collect(maps.Keys(r.parsers)), maps.Keys(r.nestedParsers))
+	slices.Sort(types)
+	return types
+}
+
 func (r *Registry) WithExtensions(ctx context.Context, aliases map[string]string) *Registry {
 	r.mu.RLock()
 	reg := &Registry{
 		parsers:       make(map[string]Parser, len(r.parsers)),
 		prefixParsers: make(map[string]PrefixParser, len(r.prefixParsers)+len(aliases)),
+		nestedParsers: make(map[string]NestedParser, len(r.nestedParsers)),
 	}
 	maps.Copy(reg.parsers, r.parsers)
 	maps.Copy(reg.prefixParsers, r.prefixParsers)
+	maps.Copy(reg.nestedParsers, r.nestedParsers)
 	r.mu.RUnlock()
 
 	for alias, extension := range aliases {
@@ -82,14 +115,20 @@ func (r *Registry) Parse(cfg StepConfig) (StepRunner, error) {
 
 func (r *Registry) parse(stepType string, stepCfg json.RawMessage) (StepRunner, error) {
 	r.mu.RLock()
-	defer r.mu.RUnlock()
-
 	parser, ok := r.parsers[stepType]
-	if !ok {
+	nestedParser, nested := r.nestedParsers[stepType]
+	r.mu.RUnlock()
+
+	var runner StepRunner
+	var err error
+	switch {
+	case ok:
+		runner, err = parser(stepCfg)
+	case nested:
+		runner, err = nestedParser(r, stepCfg)
+	default:
 		return nil, fmt.Errorf("unknown step type '%s'", stepType)
 	}
-
-	runner, err := parser(stepCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse step: %w", err)
 	}
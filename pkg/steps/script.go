@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
 )
 
 // TODO: Add template support for File and Inline fields once we figure out
@@ -86,7 +88,7 @@ func (s *ScriptStep) Execute(ctx context.Context, input *StepInput) (*StepOutput
 	if s.Inline != "" {
 		cmd, err = s.createInlineCommand(ctx, input.Workdir)
 	} else {
-		cmd, err = s.createFileCommand(ctx, input.Workdir)
+		cmd, err = s.createFileCommand(ctx, input.Workdir, input.TaskDir)
 	}
 	if err != nil {
 		return s.handleError(err)
@@ -107,26 +109,19 @@ func (s *ScriptStep) Execute(ctx context.Context, input *StepInput) (*StepOutput
 // createInlineCommand executes inline scripts with shebang support.
 // Scripts with shebangs are written to temp files in the current directory to preserve relative paths.
 func (s *ScriptStep) createInlineCommand(ctx context.Context, workdir string) (*exec.Cmd, error) {
+	sh := shell.Default()
+
 	if strings.HasPrefix(strings.TrimSpace(s.Inline), "#!") {
-		tmpFile, err := os.CreateTemp(workdir, ".mcpchecker-step-*.sh")
+		tmpPath, err := shell.WriteTemp(workdir, ".mcpchecker-step-*.sh", s.Inline)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create temp script file: %w", err)
-		}
-		tmpPath := tmpFile.Name()
-
-		if _, err := tmpFile.WriteString(s.Inline); err != nil {
-			tmpFile.Close()
-			os.Remove(tmpPath)
-			return nil, fmt.Errorf("failed to write temp script: %w", err)
+			return nil, err
 		}
-		tmpFile.Close()
 
-		if err := ensureExecutable(tmpPath); err != nil {
+		cmd, err := sh.File(ctx, tmpPath)
+		if err != nil {
 			os.Remove(tmpPath)
 			return nil, err
 		}
-
-		cmd := exec.CommandContext(ctx, tmpPath)
 		cmd.Dir = workdir
 		go func() {
 			<-ctx.Done()
@@ -135,29 +130,40 @@ func (s *ScriptStep) createInlineCommand(ctx context.Context, workdir string) (*
 		return cmd, nil
 	}
 
-	shell := getShell()
-	cmd := exec.CommandContext(ctx, shell)
-	cmd.Stdin = strings.NewReader(s.Inline)
+	cmd, err := sh.Script(ctx, s.Inline)
+	if err != nil {
+		return nil, err
+	}
 	cmd.Dir = workdir
 	return cmd, nil
 }
 
 // createFileCommand executes a script file directly to respect its shebang.
-func (s *ScriptStep) createFileCommand(ctx context.Context, workdir string) (*exec.Cmd, error) {
-	file := s.File
+// Scripts run in their own directory, or in the task's working directory if
+// it has one.
+func (s *ScriptStep) createFileCommand(ctx context.Context, workdir, taskDir string) (*exec.Cmd, error) {
+	// Task files use forward slashes, so that they work on all platforms
+	file := filepath.FromSlash(s.File)
 
-	// If workdir is set and file is relative, resolve it
-	if workdir != "" && !filepath.IsAbs(file) {
-		file = filepath.Join(workdir, file)
+	baseDir := workdir
+	if taskDir != "" {
+		baseDir = taskDir
 	}
 
-	if err := ensureExecutable(file); err != nil {
-		return nil, err
+	// If a base directory is set and file is relative, resolve it
+	if baseDir != "" && !filepath.IsAbs(file) {
+		file = filepath.Join(baseDir, file)
 	}
 
-	cmd := exec.CommandContext(ctx, file)
+	cmd, err := shell.Default().File(ctx, file)
+	if err != nil {
+		return nil, err
+	}
 	// Set working directory to the script's directory so relative paths work
 	cmd.Dir = filepath.Dir(file)
+	if taskDir != "" {
+		cmd.Dir = workdir
+	}
 	return cmd, nil
 }
 
@@ -172,23 +178,6 @@ func (s *ScriptStep) handleError(err error) (*StepOutput, error) {
 	return nil, err
 }
 
-func ensureExecutable(path string) error {
-	info, err := os.Stat(path)
-	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
-	}
-
-	if info.Mode()&0100 != 0 {
-		return nil
-	}
-
-	if err := os.Chmod(path, info.Mode()|0111); err != nil {
-		return fmt.Errorf("failed to make script executable: %w", err)
-	}
-
-	return nil
-}
-
 func (cfg *ScriptStepConfig) Validate() error {
 	numDefined := 0
 	if cfg.File != "" {
@@ -204,12 +193,3 @@ func (cfg *ScriptStepConfig) Validate() error {
 
 	return nil
 }
-
-func getShell() string {
-	shell, ok := os.LookupEnv("SHELL")
-	if !ok {
-		shell = "/usr/bin/bash"
-	}
-
-	return shell
-}
@@ -185,6 +185,14 @@ func TestScriptStep_Execute_File(t *testing.T) {
 	err = os.WriteFile(scriptPath, []byte("#!/bin/sh\necho file_script"), 0755)
 	require.NoError(t, err)
 
+	// Create a script that prints its working directory
+	pwdPath := filepath.Join(tmpDir, "pwd.sh")
+	err = os.WriteFile(pwdPath, []byte("#!/bin/sh\npwd"), 0755)
+	require.NoError(t, err)
+
+	workdir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
 	tt := map[string]struct {
 		config    *ScriptStepConfig
 		input     *StepInput
@@ -216,6 +224,21 @@ func TestScriptStep_Execute_File(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		"file script in task working directory": {
+			config: &ScriptStepConfig{
+				File: "pwd.sh",
+			},
+			input: &StepInput{
+				Env:     map[string]string{},
+				Workdir: workdir,
+				TaskDir: tmpDir,
+			},
+			expected: &StepOutput{
+				Success: true,
+				Message: workdir + "\n",
+			},
+			expectErr: false,
+		},
 		"file script not found": {
 			config: &ScriptStepConfig{
 				File: "/nonexistent/script.sh",
@@ -0,0 +1,260 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// SnapshotStepConfig compares the output of a step with a golden file. The
+// file is written on the first run, and rewritten when snapshots are updated.
+type SnapshotStepConfig struct {
+	// Step is the step whose output is captured, such as a script or an
+	// extension operation
+	Step StepConfig `json:"step"`
+	// Output is the output of the step that is captured: its message, or one
+	// of its named outputs. Defaults to the message.
+	Output string `json:"output,omitempty"`
+	// File is the golden file, relative to the task directory
+	File string `json:"file"`
+	// Normalize rules are applied in order to the output before it is compared
+	// or written, to remove the parts that change between runs
+	Normalize []SnapshotNormalizer `json:"normalize,omitempty"`
+}
+
+// SnapshotNormalizer is a rule that normalizes snapshot output. Exactly one of
+// Regex, SortLines, or TrimSpace must be set.
+type SnapshotNormalizer struct {
+	// Regex is replaced with Replace wherever it matches. Replace may refer
+	// to groups of the match like $1.
+	Regex   string `json:"regex,omitempty"`
+	Replace string `json:"replace,omitempty"`
+	// SortLines sorts the lines, for output in no stable order
+	SortLines bool `json:"sortLines,omitempty"`
+	// TrimSpace removes whitespace at the end of each line, and blank lines
+	// at the start and end
+	TrimSpace bool `json:"trimSpace,omitempty"`
+}
+
+type SnapshotStep struct {
+	step      StepRunner
+	output    string
+	file      string
+	normalize []func(string) string
+}
+
+var _ StepRunner = &SnapshotStep{}
+
+func ParseSnapshotStep(registry *Registry, raw json.RawMessage) (StepRunner, error) {
+	cfg := &SnapshotStepConfig{}
+
+	err := json.Unmarshal(raw, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSnapshotStep(registry, cfg)
+}
+
+func NewSnapshotStep(registry *Registry, cfg *SnapshotStepConfig) (*SnapshotStep, error) {
+	if cfg.File == "" {
+		return nil, fmt.Errorf("file must be specified on snapshot step")
+	}
+	if len(cfg.Step) == 0 {
+		return nil, fmt.Errorf("step must be specified on snapshot step")
+	}
+
+	step, err := registry.Parse(cfg.Step)
+	if err != nil {
+		return nil, fmt.Errorf("invalid step of snapshot: %w", err)
+	}
+
+	s := &SnapshotStep{
+		step:   step,
+		output: cfg.Output,
+		file:   cfg.File,
+	}
+
+	for i, rule := range cfg.Normalize {
+		normalize, err := rule.compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid normalize[%d]: %w", i, err)
+		}
+		s.normalize = append(s.normalize, normalize)
+	}
+
+	return s, nil
+}
+
+// SnapshotFiles returns the golden files of a step if it is a snapshot step,
+// as written in its config, including those of snapshot steps it wraps
+func SnapshotFiles(cfg StepConfig) []string {
+	raw, ok := cfg["snapshot"]
+	if !ok {
+		return nil
+	}
+
+	snapshot := &SnapshotStepConfig{}
+	if err := json.Unmarshal(raw, snapshot); err != nil || snapshot.File == "" {
+		return nil
+	}
+	return append([]string{snapshot.File}, SnapshotFiles(snapshot.Step)...)
+}
+
+// compile returns the function that applies the rule
+func (n SnapshotNormalizer) compile() (func(string) string, error) {
+	set := 0
+	for _, isSet := range []bool{n.Regex != "", n.SortLines, n.TrimSpace} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("exactly one of regex, sortLines, or trimSpace must be set")
+	}
+	if n.Replace != "" && n.Regex == "" {
+		return nil, fmt.Errorf("replace can only be set with regex")
+	}
+
+	switch {
+	case n.Regex != "":
+		re, err := regexp.Compile(n.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile regex: %w", err)
+		}
+		return func(s string) string {
+			return re.ReplaceAllString(s, n.Replace)
+		}, nil
+	case n.SortLines:
+		return func(s string) string {
+			lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+			slices.Sort(lines)
+			return strings.Join(lines, "\n") + "\n"
+		}, nil
+	default:
+		return func(s string) string {
+			lines := strings.Split(s, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight(line, " \t")
+			}
+			return strings.Trim(strings.Join(lines, "\n"), "\n") + "\n"
+		}, nil
+	}
+}
+
+func (s *SnapshotStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
+	res, err := s.step.Execute(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || !res.Success {
+		out := &StepOutput{Type: "snapshot", Success: false, Error: "step failed, output was not compared to the snapshot"}
+		if res != nil && res.Error != "" {
+			out.Error = fmt.Sprintf("step failed, output was not compared to the snapshot: %s", res.Error)
+		}
+		return out, nil
+	}
+
+	actual := res.Message
+	if s.output != "" {
+		value, ok := res.Outputs[s.output]
+		if !ok {
+			return nil, fmt.Errorf("step has no output %q to snapshot", s.output)
+		}
+		actual = value
+	}
+
+	// Line endings depend on the platform the output was captured on
+	actual = strings.ReplaceAll(actual, "\r\n", "\n")
+	for _, normalize := range s.normalize {
+		actual = normalize(actual)
+	}
+
+	path := s.path(input)
+	out := &StepOutput{
+		Type:    "snapshot",
+		Success: true,
+		Outputs: map[string]string{"file": path},
+	}
+
+	expected, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) || (err == nil && util.UpdateSnapshots(ctx)):
+		if err := writeSnapshot(path, actual); err != nil {
+			return nil, err
+		}
+		out.Message = fmt.Sprintf("snapshot written to %s", path)
+		return out, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	if diff := snapshotDiff(strings.ReplaceAll(string(expected), "\r\n", "\n"), actual); diff != "" {
+		out.Success = false
+		out.Message = actual
+		out.Error = fmt.Sprintf("output does not match snapshot %s: %s", path, diff)
+		return out, nil
+	}
+
+	out.Message = fmt.Sprintf("output matches snapshot %s", path)
+	return out, nil
+}
+
+// path returns the path of the snapshot file, which is relative to the task
+// directory
+func (s *SnapshotStep) path(input *StepInput) string {
+	file := filepath.FromSlash(s.file)
+	if filepath.IsAbs(file) {
+		return file
+	}
+
+	baseDir := input.Workdir
+	if input.TaskDir != "" {
+		baseDir = input.TaskDir
+	}
+	return filepath.Join(baseDir, file)
+}
+
+func writeSnapshot(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// snapshotDiff describes the first line where actual differs from expected,
+// or returns "" if they are equal
+func snapshotDiff(expected, actual string) string {
+	if expected == actual {
+		return ""
+	}
+
+	expectedLines := strings.Split(expected, "\n")
+	actualLines := strings.Split(actual, "\n")
+	for i := 0; i < max(len(expectedLines), len(actualLines)); i++ {
+		var want, got string
+		if i < len(expectedLines) {
+			want = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			got = actualLines[i]
+		}
+		if i >= len(expectedLines) || i >= len(actualLines) || want != got {
+			return fmt.Sprintf("line %d differs (expected %d lines, got %d)\n  expected: %q\n  actual:   %q",
+				i+1, len(expectedLines), len(actualLines), want, got)
+		}
+	}
+	return ""
+}
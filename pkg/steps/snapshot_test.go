@@ -0,0 +1,198 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSnapshotTestRegistry returns a registry with a "fixed" step type that
+// returns the StepOutput it is configured with
+func newSnapshotTestRegistry(t *testing.T) *Registry {
+	reg := &Registry{
+		parsers:       make(map[string]Parser),
+		prefixParsers: make(map[string]PrefixParser),
+		nestedParsers: make(map[string]NestedParser),
+	}
+	require.NoError(t, reg.Register("fixed", func(raw json.RawMessage) (StepRunner, error) {
+		out := &StepOutput{}
+		if err := json.Unmarshal(raw, out); err != nil {
+			return nil, err
+		}
+		return &fixedRunner{out: out}, nil
+	}))
+	require.NoError(t, reg.RegisterNested("snapshot", ParseSnapshotStep))
+	return reg
+}
+
+type fixedRunner struct {
+	out *StepOutput
+}
+
+func (f *fixedRunner) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
+	return f.out, nil
+}
+
+func TestSnapshotStep(t *testing.T) {
+	tests := map[string]struct {
+		config          string
+		existing        string
+		update          bool
+		expectedSuccess bool
+		expectedFile    string
+		messageContains string
+		errContains     string
+	}{
+		"first run writes snapshot": {
+			config:          `{"step": {"fixed": {"success": true, "message": "pod-a\npod-b\n"}}, "file": "golden/pods.txt"}`,
+			expectedSuccess: true,
+			expectedFile:    "pod-a\npod-b\n",
+			messageContains: "snapshot written to",
+		},
+		"matching output passes": {
+			config:          `{"step": {"fixed": {"success": true, "message": "pod-a\r\npod-b\r\n"}}, "file": "golden/pods.txt"}`,
+			existing:        "pod-a\npod-b\n",
+			expectedSuccess: true,
+			expectedFile:    "pod-a\npod-b\n",
+			messageContains: "output matches snapshot",
+		},
+		"changed output fails": {
+			config:          `{"step": {"fixed": {"success": true, "message": "pod-a\npod-c\n"}}, "file": "golden/pods.txt"}`,
+			existing:        "pod-a\npod-b\n",
+			expectedSuccess: false,
+			expectedFile:    "pod-a\npod-b\n",
+			messageContains: "pod-a\npod-c\n",
+			errContains:     "line 2 differs (expected 3 lines, got 3)\n  expected: \"pod-b\"\n  actual:   \"pod-c\"",
+		},
+		"update rewrites snapshot": {
+			config:          `{"step": {"fixed": {"success": true, "message": "pod-a\npod-c\n"}}, "file": "golden/pods.txt"}`,
+			existing:        "pod-a\npod-b\n",
+			update:          true,
+			expectedSuccess: true,
+			expectedFile:    "pod-a\npod-c\n",
+			messageContains: "snapshot written to",
+		},
+		"named output": {
+			config:          `{"step": {"fixed": {"success": true, "message": "ignored", "outputs": {"body": "{\"ok\": true}"}}}, "output": "body", "file": "body.json"}`,
+			expectedSuccess: true,
+			expectedFile:    `{"ok": true}`,
+		},
+		"normalize rules": {
+			config: `{
+				"step": {"fixed": {"success": true, "message": "\nnginx-7f9c   Running  \nredis-1b2d Running\n\n"}},
+				"file": "pods.txt",
+				"normalize": [
+					{"regex": "-[0-9a-f]{4}\\b", "replace": "-<id>"},
+					{"trimSpace": true},
+					{"sortLines": true}
+				]
+			}`,
+			existing:        "nginx-<id>   Running\nredis-<id> Running\n",
+			expectedSuccess: true,
+			expectedFile:    "nginx-<id>   Running\nredis-<id> Running\n",
+		},
+		"failed step is not compared": {
+			config:          `{"step": {"fixed": {"success": false, "error": "exit status 1"}}, "file": "pods.txt"}`,
+			existing:        "pod-a\n",
+			expectedSuccess: false,
+			expectedFile:    "pod-a\n",
+			errContains:     "step failed, output was not compared to the snapshot: exit status 1",
+		},
+	}
+
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			taskDir := t.TempDir()
+			step, err := newSnapshotTestRegistry(t).Parse(StepConfig{"snapshot": json.RawMessage(tc.config)})
+			require.NoError(t, err)
+
+			cfg := &SnapshotStepConfig{}
+			require.NoError(t, json.Unmarshal([]byte(tc.config), cfg))
+			path := filepath.Join(taskDir, filepath.FromSlash(cfg.File))
+			if tc.existing != "" {
+				require.NoError(t, writeSnapshot(path, tc.existing))
+			}
+
+			ctx := util.WithUpdateSnapshots(context.Background(), tc.update)
+			out, err := step.Execute(ctx, &StepInput{TaskDir: taskDir, Workdir: t.TempDir()})
+			require.NoError(t, err)
+
+			assert.Equal(t, "snapshot", out.Type)
+			assert.Equal(t, tc.expectedSuccess, out.Success)
+			assert.Contains(t, out.Message, tc.messageContains)
+			if tc.errContains != "" {
+				assert.Contains(t, out.Error, tc.errContains)
+			} else {
+				assert.Empty(t, out.Error)
+			}
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFile, string(data))
+		})
+	}
+}
+
+func TestSnapshotStepMissingOutput(t *testing.T) {
+	step, err := newSnapshotTestRegistry(t).Parse(StepConfig{
+		"snapshot": json.RawMessage(`{"step": {"fixed": {"success": true, "message": "ok"}}, "output": "body", "file": "body.json"}`),
+	})
+	require.NoError(t, err)
+
+	_, err = step.Execute(context.Background(), &StepInput{TaskDir: t.TempDir()})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `step has no output "body" to snapshot`)
+}
+
+func TestParseSnapshotStep(t *testing.T) {
+	tests := map[string]struct {
+		config      string
+		errContains string
+	}{
+		"valid": {
+			config: `{"step": {"fixed": {"success": true}}, "file": "out.txt", "normalize": [{"trimSpace": true}]}`,
+		},
+		"missing file": {
+			config:      `{"step": {"fixed": {"success": true}}}`,
+			errContains: "file must be specified on snapshot step",
+		},
+		"missing step": {
+			config:      `{"file": "out.txt"}`,
+			errContains: "step must be specified on snapshot step",
+		},
+		"unknown step type": {
+			config:      `{"step": {"unknown": {}}, "file": "out.txt"}`,
+			errContains: "invalid step of snapshot: unknown step type 'unknown'",
+		},
+		"normalizer with two rules": {
+			config:      `{"step": {"fixed": {}}, "file": "out.txt", "normalize": [{"sortLines": true, "trimSpace": true}]}`,
+			errContains: "invalid normalize[0]: exactly one of regex, sortLines, or trimSpace must be set",
+		},
+		"replace without regex": {
+			config:      `{"step": {"fixed": {}}, "file": "out.txt", "normalize": [{"sortLines": true, "replace": "x"}]}`,
+			errContains: "invalid normalize[0]: replace can only be set with regex",
+		},
+		"invalid regex": {
+			config:      `{"step": {"fixed": {}}, "file": "out.txt", "normalize": [{"regex": "("}]}`,
+			errContains: "invalid normalize[0]: failed to compile regex",
+		},
+	}
+
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			_, err := ParseSnapshotStep(newSnapshotTestRegistry(t), json.RawMessage(tc.config))
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 const (
@@ -14,6 +16,7 @@ var (
 	DefaultRegistry = &Registry{
 		parsers:       make(map[string]Parser),
 		prefixParsers: make(map[string]PrefixParser),
+		nestedParsers: make(map[string]NestedParser),
 	}
 )
 
@@ -24,6 +27,9 @@ type StepRunner interface {
 type StepInput struct {
 	Env     map[string]string
 	Workdir string
+	// TaskDir is the directory of the task file, if the task has a working
+	// directory of its own. Relative script files are resolved against it.
+	TaskDir string
 	Agent   *AgentContext
 }
 
@@ -33,6 +39,8 @@ type StepOutput struct {
 	Message string            `json:"message,omitempty"`
 	Outputs map[string]string `json:"outputs,omitempty"`
 	Error   string            `json:"error,omitempty"`
+	// Duration is set by the task runner, not by the step itself
+	Duration util.Duration `json:"duration,omitempty"`
 }
 
 type AgentContext struct {
@@ -46,4 +54,7 @@ func init() {
 	DefaultRegistry.Register("http", ParseHttpStep)
 	DefaultRegistry.Register("script", ParseScriptStep)
 	DefaultRegistry.Register("llmJudge", ParseLLMJudgeStep)
+	DefaultRegistry.Register("extract", ParseExtractStep)
+	DefaultRegistry.Register("wait", ParseWaitStep)
+	DefaultRegistry.RegisterNested("snapshot", ParseSnapshotStep)
 }
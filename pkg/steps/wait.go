@@ -0,0 +1,275 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
+)
+
+const (
+	// DefaultWaitInterval is how often a wait step polls if it does not say
+	// otherwise
+	DefaultWaitInterval = time.Second
+
+	// DefaultWaitTimeout is how long a wait step polls if it does not say
+	// otherwise
+	DefaultWaitTimeout = time.Minute
+)
+
+// WaitStepConfig waits for a fixed duration, or polls a URL or a command until
+// it is ready. Exactly one of Duration, URL, or Command must be set. Like a
+// failed script, a wait that times out stops the phase with an error.
+type WaitStepConfig struct {
+	// Duration sleeps for a fixed time, like 10s
+	Duration string `json:"duration,omitempty"`
+
+	// URL is polled with GET requests until it answers with Status. Like the
+	// url of an http step, it may refer to env vars as {env.NAME} or ${NAME}.
+	URL string `json:"url,omitempty"`
+	// Status is the status code the URL must answer with. Defaults to any 2xx
+	// status.
+	Status int `json:"status,omitempty"`
+
+	// Command is run until it exits with code 0
+	Command string `json:"command,omitempty"`
+
+	// Match is a regex that the response body or the command output must also
+	// match
+	Match string `json:"match,omitempty"`
+
+	// Interval is the time between attempts. Defaults to 1s.
+	Interval string `json:"interval,omitempty"`
+	// Timeout is how long to poll before the step fails. Defaults to 1m.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+type WaitStep struct {
+	Duration time.Duration
+	URL      *template.TemplateBuilder
+	Status   int
+	Command  string
+	Match    *regexp.Regexp
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+var _ StepRunner = &WaitStep{}
+
+func ParseWaitStep(raw json.RawMessage) (StepRunner, error) {
+	cfg := &WaitStepConfig{}
+
+	err := json.Unmarshal(raw, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWaitStep(cfg)
+}
+
+func NewWaitStep(cfg *WaitStepConfig) (*WaitStep, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	step := &WaitStep{
+		Status:   cfg.Status,
+		Command:  cfg.Command,
+		Interval: DefaultWaitInterval,
+		Timeout:  DefaultWaitTimeout,
+	}
+
+	var err error
+	if cfg.URL != "" {
+		url, err := template.ParseTemplate(cfg.URL, template.TemplateParserOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse url: %w", err)
+		}
+
+		step.URL, err = template.NewTemplateBuilder(url, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create builder for url: %w", err)
+		}
+	}
+	if cfg.Duration != "" {
+		if step.Duration, err = parsePositiveDuration(cfg.Duration); err != nil {
+			return nil, fmt.Errorf("failed to parse duration: %w", err)
+		}
+	}
+	if cfg.Interval != "" {
+		if step.Interval, err = parsePositiveDuration(cfg.Interval); err != nil {
+			return nil, fmt.Errorf("failed to parse interval: %w", err)
+		}
+	}
+	if cfg.Timeout != "" {
+		if step.Timeout, err = parsePositiveDuration(cfg.Timeout); err != nil {
+			return nil, fmt.Errorf("failed to parse timeout: %w", err)
+		}
+	}
+	if cfg.Match != "" {
+		if step.Match, err = regexp.Compile(cfg.Match); err != nil {
+			return nil, fmt.Errorf("failed to compile match: %w", err)
+		}
+	}
+
+	return step, nil
+}
+
+func parsePositiveDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive", s)
+	}
+	return d, nil
+}
+
+func (cfg *WaitStepConfig) Validate() error {
+	numDefined := 0
+	for _, v := range []string{cfg.Duration, cfg.URL, cfg.Command} {
+		if v != "" {
+			numDefined++
+		}
+	}
+	if numDefined != 1 {
+		return fmt.Errorf("exactly one of 'duration', 'url', or 'command' must be defined on wait step")
+	}
+
+	if cfg.Duration != "" && (cfg.Match != "" || cfg.Interval != "" || cfg.Timeout != "") {
+		return fmt.Errorf("match, interval, and timeout cannot be set with duration on wait step")
+	}
+	if cfg.Status != 0 && cfg.URL == "" {
+		return fmt.Errorf("status can only be set with url on wait step")
+	}
+
+	return nil
+}
+
+func (s *WaitStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
+	if s.Duration > 0 {
+		select {
+		case <-time.After(s.Duration):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return &StepOutput{
+			Type:    "wait",
+			Success: true,
+			Message: fmt.Sprintf("waited %s", s.Duration),
+		}, nil
+	}
+
+	for k, v := range input.Env {
+		err := os.Setenv(k, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set env var '%s' to value '%s': %w", k, v, err)
+		}
+	}
+	defer func() {
+		for k := range input.Env {
+			_ = os.Unsetenv(k)
+		}
+	}()
+
+	var url string
+	if s.URL != nil {
+		result, err := s.URL.GetResult()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build url from template: %w", err)
+		}
+		url = result.(string)
+	}
+
+	target := url
+	if s.Command != "" {
+		target = fmt.Sprintf("command %q", s.Command)
+	}
+
+	start := time.Now()
+	deadline := start.Add(s.Timeout)
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithDeadline(ctx, deadline)
+		output, err := s.poll(attemptCtx, url, input.Workdir)
+		cancel()
+
+		if err == nil {
+			return &StepOutput{
+				Type:    "wait",
+				Success: true,
+				Message: fmt.Sprintf("%s was ready after %d attempts in %s", target, attempt, time.Since(start).Round(time.Millisecond)),
+				Outputs: map[string]string{"attempts": strconv.Itoa(attempt)},
+			}, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !time.Now().Add(s.Interval).Before(deadline) {
+			err = fmt.Errorf("timed out after %s waiting for %s (%d attempts), last attempt: %w", s.Timeout, target, attempt, err)
+			if output != "" {
+				err = fmt.Errorf("%w\noutput: %s", err, output)
+			}
+			return nil, err
+		}
+
+		select {
+		case <-time.After(s.Interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// poll makes one attempt against url, or the command if url is empty, and
+// returns the response body or command output and an error if the target is
+// not ready
+func (s *WaitStep) poll(ctx context.Context, url, workdir string) (string, error) {
+	var output string
+	if url != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to create http request: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read response body: %w", err)
+		}
+		output = string(body)
+
+		if s.Status != 0 && resp.StatusCode != s.Status {
+			return output, fmt.Errorf("expected status code %d, got %d", s.Status, resp.StatusCode)
+		}
+		if s.Status == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+			return output, fmt.Errorf("expected a 2xx status code, got %d", resp.StatusCode)
+		}
+	} else {
+		cmd := shell.Default().Command(ctx, s.Command)
+		cmd.Dir = workdir
+		out, err := cmd.CombinedOutput()
+		output = string(out)
+		if err != nil {
+			return output, fmt.Errorf("command failed: %w", err)
+		}
+	}
+
+	if s.Match != nil && !s.Match.MatchString(output) {
+		return output, fmt.Errorf("output did not match pattern %q", s.Match)
+	}
+	return output, nil
+}
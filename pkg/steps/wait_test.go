@@ -0,0 +1,167 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitStep(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server becomes ready on the third request
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("starting"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "ready"}`))
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		config            string
+		expectedAttempts  string
+		messageContains   string
+		errContains       string
+		resetRequestCount bool
+	}{
+		"duration": {
+			config:          `{"duration": "10ms"}`,
+			messageContains: "waited 10ms",
+		},
+		"url becomes ready": {
+			config:            `{"url": "` + server.URL + `", "match": "ready", "interval": "10ms"}`,
+			expectedAttempts:  "3",
+			messageContains:   "was ready after 3 attempts",
+			resetRequestCount: true,
+		},
+		"url with unexpected status times out": {
+			config:            `{"url": "` + server.URL + `", "status": 201, "interval": "10ms", "timeout": "200ms"}`,
+			errContains:       "last attempt: expected status code 201, got 200\noutput: {\"status\": \"ready\"}",
+			resetRequestCount: true,
+		},
+		"command succeeds": {
+			config:           `{"command": "echo Running"}`,
+			expectedAttempts: "1",
+		},
+		"command output does not match": {
+			config:      `{"command": "echo Pending", "match": "^Running", "interval": "10ms", "timeout": "100ms"}`,
+			errContains: `waiting for command "echo Pending" (`,
+		},
+		"command fails": {
+			config:      `{"command": "exit 1", "interval": "10ms", "timeout": "100ms"}`,
+			errContains: "last attempt: command failed: exit status 1",
+		},
+	}
+
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			if tc.resetRequestCount {
+				requests.Store(0)
+			}
+			step, err := ParseWaitStep(json.RawMessage(tc.config))
+			require.NoError(t, err)
+
+			out, err := step.Execute(context.Background(), &StepInput{Workdir: t.TempDir()})
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "timed out after")
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, "wait", out.Type)
+			assert.True(t, out.Success)
+			assert.Contains(t, out.Message, tc.messageContains)
+			if tc.expectedAttempts != "" {
+				assert.Equal(t, tc.expectedAttempts, out.Outputs["attempts"])
+			}
+		})
+	}
+}
+
+func TestParseWaitStep(t *testing.T) {
+	tests := map[string]struct {
+		config      string
+		errContains string
+	}{
+		"valid url": {
+			config: `{"url": "http://localhost:8080/healthz", "status": 200, "interval": "2s", "timeout": "2m"}`,
+		},
+		"nothing to wait for": {
+			config:      `{"timeout": "1m"}`,
+			errContains: "exactly one of 'duration', 'url', or 'command' must be defined on wait step",
+		},
+		"url and command": {
+			config:      `{"url": "http://localhost", "command": "true"}`,
+			errContains: "exactly one of",
+		},
+		"duration with timeout": {
+			config:      `{"duration": "5s", "timeout": "1m"}`,
+			errContains: "match, interval, and timeout cannot be set with duration on wait step",
+		},
+		"status with command": {
+			config:      `{"command": "true", "status": 200}`,
+			errContains: "status can only be set with url on wait step",
+		},
+		"invalid interval": {
+			config:      `{"command": "true", "interval": "0s"}`,
+			errContains: "failed to parse interval: 0s must be positive",
+		},
+		"invalid match": {
+			config:      `{"command": "true", "match": "("}`,
+			errContains: "failed to compile match",
+		},
+		"invalid url template": {
+			config:      `{"url": "http://${HOST"}`,
+			errContains: "failed to parse url",
+		},
+	}
+
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			_, err := ParseWaitStep(json.RawMessage(tc.config))
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWaitStepURLFromEnv(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	step, err := ParseWaitStep(json.RawMessage(`{"url": "{env.WAIT_TEST_URL}/healthz"}`))
+	require.NoError(t, err)
+
+	out, err := step.Execute(context.Background(), &StepInput{Env: map[string]string{"WAIT_TEST_URL": server.URL}})
+	require.NoError(t, err)
+	assert.Contains(t, out.Message, server.URL+"/healthz was ready after 1 attempts")
+
+	_, err = step.Execute(context.Background(), &StepInput{})
+	assert.ErrorContains(t, err, "failed to build url from template")
+}
+
+func TestWaitStepCanceled(t *testing.T) {
+	step, err := ParseWaitStep(json.RawMessage(`{"duration": "1m"}`))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = step.Execute(ctx, &StepInput{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,155 @@
+package stubserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config describes the tools of a stub MCP server and how they respond
+type Config struct {
+	// Name and Version are reported to clients. Name defaults to "stub"
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	// Instructions are sent to clients when they connect
+	Instructions string `json:"instructions,omitempty"`
+
+	// State is the initial state of the server. Responses can match on the
+	// state and move the server to another one
+	State string `json:"state,omitempty"`
+
+	Tools []*Tool `json:"tools"`
+}
+
+// Tool is a tool of the stub server
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// InputSchema is the JSON schema of the arguments. Defaults to an object
+	// without properties
+	InputSchema map[string]any `json:"inputSchema,omitempty"`
+
+	// Responses are tried in order, and the first one whose when matches
+	// answers the call. A call no response matches fails.
+	Responses []*Response `json:"responses"`
+}
+
+// Response is a response of a tool
+type Response struct {
+	When *When `json:"when,omitempty"`
+
+	// Text is a Go template of the text of the result, with the data .Args
+	// (the arguments of the call), .State, .Tool, and .Call (the number of
+	// calls of the tool so far, counting this one)
+	Text string `json:"text,omitempty"`
+
+	// IsError marks the result as a tool error
+	IsError bool `json:"isError,omitempty"`
+
+	// SetState moves the server to another state after responding
+	SetState string `json:"setState,omitempty"`
+
+	text *template.Template
+}
+
+// When is the condition of a response. All set fields must match
+type When struct {
+	// State matches the current state of the server
+	State string `json:"state,omitempty"`
+
+	// Args matches calls whose arguments have these values
+	Args map[string]any `json:"args,omitempty"`
+
+	// Call matches the call of the tool with this number, counting from 1
+	Call int `json:"call,omitempty"`
+}
+
+// Load reads a stub server config from a YAML or JSON file
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stub server config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse parses a stub server config and its templates
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.compile(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// compile validates the config and parses the templates of the responses
+func (c *Config) compile() error {
+	if len(c.Tools) == 0 {
+		return fmt.Errorf("at least one tool is required")
+	}
+
+	names := make(map[string]bool, len(c.Tools))
+	for i, t := range c.Tools {
+		if t == nil || t.Name == "" {
+			return fmt.Errorf("tools[%d]: name is required", i)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("tool %q is defined more than once", t.Name)
+		}
+		names[t.Name] = true
+
+		if len(t.Responses) == 0 {
+			return fmt.Errorf("tool %q: at least one response is required", t.Name)
+		}
+		for j, r := range t.Responses {
+			if r == nil {
+				return fmt.Errorf("tool %q: responses[%d] must not be empty", t.Name, j)
+			}
+			tmpl, err := template.New(t.Name).Funcs(templateFuncs).Parse(r.Text)
+			if err != nil {
+				return fmt.Errorf("tool %q: responses[%d]: invalid text: %w", t.Name, j, err)
+			}
+			r.text = tmpl
+		}
+	}
+	return nil
+}
+
+// templateFuncs are the functions available to response templates
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// templateData is the data of a response template
+type templateData struct {
+	Args  map[string]any
+	State string
+	Tool  string
+	Call  int
+}
+
+// render executes the text template of the response
+func (r *Response) render(data templateData) (string, error) {
+	var buf bytes.Buffer
+	if err := r.text.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
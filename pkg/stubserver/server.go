@@ -0,0 +1,144 @@
+// Package stubserver implements an MCP server whose tools answer with
+// responses configured in YAML, for task fixtures and demos
+package stubserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Server answers the tool calls of a stub server config. The state and call
+// counts are shared by all sessions of the server.
+type Server struct {
+	cfg *Config
+
+	mu    sync.Mutex
+	state string
+	calls map[string]int
+}
+
+// New creates the server of a config returned by Load or Parse
+func New(cfg *Config) *Server {
+	return &Server{
+		cfg:   cfg,
+		state: cfg.State,
+		calls: make(map[string]int),
+	}
+}
+
+// MCPServer returns an MCP server exposing the tools of the config
+func (s *Server) MCPServer() *mcp.Server {
+	name := s.cfg.Name
+	if name == "" {
+		name = "stub"
+	}
+	server := mcp.NewServer(
+		&mcp.Implementation{Name: name, Version: s.cfg.Version},
+		&mcp.ServerOptions{Instructions: s.cfg.Instructions},
+	)
+
+	for _, t := range s.cfg.Tools {
+		schema := t.InputSchema
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		server.AddTool(&mcp.Tool{
+			Name:        t.Name,
+			Description: t.Description,
+			InputSchema: schema,
+		}, func(_ context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return s.callTool(t, req.Params.Arguments)
+		})
+	}
+
+	return server
+}
+
+// State returns the current state of the server
+func (s *Server) State() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// callTool answers a call of a tool with its first matching response
+func (s *Server) callTool(t *Tool, rawArgs json.RawMessage) (*mcp.CallToolResult, error) {
+	args := map[string]any{}
+	if len(rawArgs) > 0 {
+		if err := json.Unmarshal(rawArgs, &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	// Calls are answered one at a time, so that state changes apply in the
+	// order of the calls
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls[t.Name]++
+	data := templateData{
+		Args:  args,
+		State: s.state,
+		Tool:  t.Name,
+		Call:  s.calls[t.Name],
+	}
+
+	for _, r := range t.Responses {
+		if !r.When.matches(data) {
+			continue
+		}
+
+		text, err := r.render(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render response of tool %s: %w", t.Name, err)
+		}
+		if r.SetState != "" {
+			s.state = r.SetState
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: text}},
+			IsError: r.IsError,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no response of tool %s matches the call in state %q", t.Name, s.state)
+}
+
+// matches reports whether a call matches the condition. A nil condition
+// matches all calls.
+func (w *When) matches(data templateData) bool {
+	if w == nil {
+		return true
+	}
+	if w.State != "" && w.State != data.State {
+		return false
+	}
+	if w.Call != 0 && w.Call != data.Call {
+		return false
+	}
+	for name, want := range w.Args {
+		if !sameValue(want, data.Args[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameValue compares a value of the config with an argument, both in the form
+// JSON decodes them to
+func sameValue(want, got any) bool {
+	data, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(normalized, got)
+}
@@ -0,0 +1,150 @@
+package stubserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inventory = `
+name: inventory
+state: empty
+tools:
+  - name: add_item
+    inputSchema:
+      type: object
+      properties:
+        name: {type: string}
+        count: {type: integer}
+      required: [name]
+    responses:
+      - when: {args: {name: forbidden}}
+        text: "Cannot add {{ .Args.name }}"
+        isError: true
+      - when: {args: {count: 2}}
+        text: "Added 2 {{ .Args.name }}s"
+        setState: stocked
+      - text: "Added {{ .Args.name }} (call {{ .Call }})"
+        setState: stocked
+  - name: list_items
+    responses:
+      - when: {state: empty}
+        text: "[]"
+      - when: {call: 3}
+        text: "busy"
+        isError: true
+      - text: '{{ json .State }}'
+`
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		config      string
+		errContains string
+	}{
+		"valid": {
+			config: inventory,
+		},
+		"no tools": {
+			config:      "name: empty",
+			errContains: "at least one tool is required",
+		},
+		"no name": {
+			config:      "tools: [{responses: [{text: ok}]}]",
+			errContains: "tools[0]: name is required",
+		},
+		"duplicate tool": {
+			config:      "tools: [{name: a, responses: [{text: ok}]}, {name: a, responses: [{text: ok}]}]",
+			errContains: `tool "a" is defined more than once`,
+		},
+		"no responses": {
+			config:      "tools: [{name: a}]",
+			errContains: `tool "a": at least one response is required`,
+		},
+		"invalid template": {
+			config:      "tools: [{name: a, responses: [{text: '{{ .Args'}]}]",
+			errContains: `tool "a": responses[0]: invalid text`,
+		},
+		"unknown field": {
+			config:      "tools: [{name: a, responses: [{txt: ok}]}]",
+			errContains: `unknown field "txt"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.config))
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	cfg, err := Parse([]byte(inventory))
+	require.NoError(t, err)
+	stub := New(cfg)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := stub.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ss.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cs.Close() })
+
+	assert.Equal(t, "inventory", cs.InitializeResult().ServerInfo.Name)
+
+	tools, err := cs.ListTools(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 2)
+
+	call := func(name string, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+		require.NoError(t, err)
+		return res
+	}
+	text := func(res *mcp.CallToolResult) string {
+		return res.Content[0].(*mcp.TextContent).Text
+	}
+
+	assert.Equal(t, "[]", text(call("list_items", nil)))
+
+	res := call("add_item", map[string]any{"name": "forbidden"})
+	assert.True(t, res.IsError)
+	assert.Equal(t, "Cannot add forbidden", text(res))
+	assert.Equal(t, "empty", stub.State())
+
+	assert.Equal(t, "Added widget (call 2)", text(call("add_item", map[string]any{"name": "widget"})))
+	assert.Equal(t, "stocked", stub.State())
+	assert.Equal(t, "Added 2 bolts", text(call("add_item", map[string]any{"name": "bolt", "count": 2})))
+
+	assert.Equal(t, `"stocked"`, text(call("list_items", nil)))
+	res = call("list_items", nil)
+	assert.True(t, res.IsError, "the third call is matched by number")
+	assert.Equal(t, `"stocked"`, text(call("list_items", nil)))
+}
+
+func TestServerNoMatchingResponse(t *testing.T) {
+	cfg, err := Parse([]byte(`
+tools:
+  - name: get
+    responses:
+      - when: {state: ready}
+        text: ok
+`))
+	require.NoError(t, err)
+
+	_, err = New(cfg).callTool(cfg.Tools[0], nil)
+	assert.EqualError(t, err, `no response of tool get matches the call in state ""`)
+}
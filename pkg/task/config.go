@@ -1,14 +1,20 @@
 package task
 
 import (
+	"cmp"
+	"encoding/base64"
 	"fmt"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
 	"github.com/mcpchecker/mcpchecker/pkg/steps"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
-	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -18,6 +24,27 @@ const (
 	DifficultyHard   = "hard"
 )
 
+// Priorities of tasks. Tasks run in order of priority, and a task without a
+// priority has PriorityNormal.
+const (
+	PriorityCritical = "critical"
+	PriorityHigh     = "high"
+	PriorityNormal   = "normal"
+	PriorityLow      = "low"
+)
+
+// Priorities are the priorities of tasks, from the first to run to the last
+var Priorities = []string{PriorityCritical, PriorityHigh, PriorityNormal, PriorityLow}
+
+// PriorityRank returns the position of a priority in Priorities, treating an
+// empty priority as PriorityNormal
+func PriorityRank(priority string) int {
+	if priority == "" {
+		priority = PriorityNormal
+	}
+	return slices.Index(Priorities, priority)
+}
+
 type TaskConfig struct {
 	util.TypeMeta `json:",inline"`
 	Metadata      TaskMetadata `json:"metadata"`
@@ -30,6 +57,38 @@ type TaskMetadata struct {
 	Name       string            `json:"name"`
 	Difficulty string            `json:"difficulty"`
 	Labels     map[string]string `json:"labels,omitempty"`
+
+	// Priority is one of Priorities. Critical tasks run first, and verify
+	// --require-critical fails when any of them fails.
+	Priority string `json:"priority,omitempty"`
+
+	// Skip is the reason the task is not run. Skipped tasks are reported,
+	// but not counted.
+	Skip string `json:"skip,omitempty"`
+	// ExpectedFailure marks a task that is known to fail. Its failures are
+	// not counted, and it is reported as an unexpected pass if it passes.
+	ExpectedFailure bool `json:"expectedFailure,omitempty"`
+
+	// Description, Owner, and Links are carried into the results, so that
+	// reports of failing tasks show what the task checks, who to ask about
+	// it, and where to look
+	Description string     `json:"description,omitempty"`
+	Owner       string     `json:"owner,omitempty"`
+	Links       []TaskLink `json:"links,omitempty"`
+}
+
+// TaskLink is a link to a page about a task, such as a runbook or an issue
+type TaskLink struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+}
+
+// String returns the link as "name: url", or the URL if it has no name
+func (l TaskLink) String() string {
+	if l.Name == "" {
+		return l.URL
+	}
+	return l.Name + ": " + l.URL
 }
 
 type TaskSpec struct {
@@ -37,12 +96,151 @@ type TaskSpec struct {
 	Setup    []steps.StepConfig `json:"setup,omitempty"`
 	Cleanup  []steps.StepConfig `json:"cleanup,omitempty"`
 	Verify   []steps.StepConfig `json:"verify,omitempty"`
-	Prompt   *util.Step         `json:"prompt,omitempty"`
+	Prompt   *Prompt            `json:"prompt,omitempty"`
+
+	// Preconditions are checked before setup. If one is not met, the task is
+	// skipped with the reason instead of failed.
+	Preconditions []Precondition `json:"preconditions,omitempty"`
+
+	// Files are written to a working directory created for the task before
+	// setup. Setup, verify, and cleanup scripts and the agent run in it.
+	Files []File `json:"files,omitempty"`
+
+	// McpServers adds MCP servers for this task only, merged on top of the
+	// eval-level MCP config. A server with the same name as an eval-level
+	// server replaces it, and a server marked as disabled removes it.
+	// When set, the servers of the task are started after the setup steps
+	// have run, instead of before. A command that is a relative path and TLS
+	// files are resolved against the task directory.
+	McpServers map[string]*mcpproxy.ServerConfig `json:"mcpServers,omitempty"`
+
+	// Dataset instantiates the task once for each of its rows. See Expand.
+	Dataset *Dataset `json:"dataset,omitempty"`
+}
+
+// Prompt is the prompt of a task, given inline or read from a file
+type Prompt struct {
+	util.Step `json:",inline"`
+
+	// Files are attached to the prompt, for tasks about analyzing provided
+	// data. They are copied from paths relative to the task file to the same
+	// paths in the working directory of the task, and passed to the agent the
+	// way it takes files.
+	Files []string `json:"files,omitempty"`
+
+	// Images are attached to the prompt for multimodal agents, such as
+	// screenshots of a UI to act on
+	Images []PromptImage `json:"images,omitempty"`
+}
+
+// PromptImage is an image attached to the prompt, read from a file or given as
+// base64 data
+type PromptImage struct {
+	// File is the path of the image, relative to the task file
+	File string `json:"file,omitempty"`
+	// Data is the image encoded as standard base64
+	Data string `json:"data,omitempty"`
+	// MediaType is the type of the image, like image/png. Defaults to the type
+	// of the file extension or content.
+	MediaType string `json:"mediaType,omitempty"`
+}
+
+// resolve validates the attached files and images, and makes the paths of
+// images absolute, relative to basePath
+func (p *Prompt) resolve(basePath string) error {
+	if p == nil {
+		return nil
+	}
+	for i, file := range p.Files {
+		if !filepath.IsLocal(filepath.FromSlash(file)) {
+			return fmt.Errorf("files[%d]: path %q must be relative and inside the task directory", i, file)
+		}
+	}
+	for i := range p.Images {
+		if err := p.Images[i].resolve(basePath); err != nil {
+			return fmt.Errorf("images[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (i *PromptImage) resolve(basePath string) error {
+	if (i.File == "") == (i.Data == "") {
+		return fmt.Errorf("exactly one of 'file' or 'data' must be set")
+	}
+	if i.MediaType != "" && !strings.HasPrefix(i.MediaType, "image/") {
+		return fmt.Errorf("mediaType %q must be an image type", i.MediaType)
+	}
+
+	if i.Data != "" {
+		if _, err := base64.StdEncoding.DecodeString(i.Data); err != nil {
+			return fmt.Errorf("data must be base64: %w", err)
+		}
+		return nil
+	}
+
+	if !filepath.IsAbs(i.File) {
+		i.File = filepath.Join(basePath, filepath.FromSlash(i.File))
+	}
+	return nil
+}
+
+// load reads the image. Images given as data are named by their position n,
+// counting from 0.
+func (i *PromptImage) load(n int) (util.Image, error) {
+	image := util.Image{MediaType: i.MediaType}
+
+	if i.File != "" {
+		data, err := os.ReadFile(i.File)
+		if err != nil {
+			return image, fmt.Errorf("failed to read image: %w", err)
+		}
+		image.Name = filepath.Base(i.File)
+		image.Data = data
+		if image.MediaType == "" {
+			image.MediaType = mime.TypeByExtension(filepath.Ext(i.File))
+		}
+	} else {
+		image.Data, _ = base64.StdEncoding.DecodeString(i.Data)
+	}
+
+	if !strings.HasPrefix(image.MediaType, "image/") {
+		image.MediaType = http.DetectContentType(image.Data)
+	}
+	// The media type may have parameters, which data URLs do not take
+	image.MediaType, _, _ = strings.Cut(image.MediaType, ";")
+	if !strings.HasPrefix(image.MediaType, "image/") {
+		return image, fmt.Errorf("%s is not an image, its content is %s", cmp.Or(image.Name, "data"), image.MediaType)
+	}
+
+	if image.Name == "" {
+		// image/svg+xml is named image-1.svg
+		ext, _, _ := strings.Cut(strings.TrimPrefix(image.MediaType, "image/"), "+")
+		image.Name = fmt.Sprintf("image-%d.%s", n+1, ext)
+	}
+	return image, nil
 }
 
 type Requirements struct {
 	Extension *string `json:"extension,omitempty"`
 	As        *string `json:"as,omitempty"`
+	// Servers are the MCP servers the task needs. If any requirement lists
+	// servers, only those are started for the task.
+	Servers []string `json:"servers,omitempty"`
+}
+
+// RequiredServers returns the MCP servers listed in the requirements of the
+// task, or nil if it does not list any
+func (s *TaskSpec) RequiredServers() []string {
+	var servers []string
+	for _, req := range s.Requires {
+		for _, server := range req.Servers {
+			if !slices.Contains(servers, server) {
+				servers = append(servers, server)
+			}
+		}
+	}
+	return servers
 }
 
 type TaskStepsV1Alpha1 struct {
@@ -95,54 +293,120 @@ func (v *VerifyStep) Validate() error {
 	return nil
 }
 
+// Read parses a task in any supported apiVersion, converted to LatestAPIVersion.
+// Relative file paths are resolved against basePath.
 func Read(data []byte, basePath string) (*TaskConfig, error) {
-	type Wrapper struct {
-		*TaskConfig `json:",inline"`
-		Steps       *TaskStepsV1Alpha1 `json:"steps,omitempty"`
-	}
-
-	spec := &TaskConfig{}
-	wrapper := &Wrapper{TaskConfig: spec}
-
-	err := yaml.Unmarshal(data, wrapper)
+	spec, err := Decode(data)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := wrapper.TypeMeta.Validate(KindTask); err != nil {
-		return nil, err
+	spec.basePath = basePath
+
+	if spec.Metadata.Priority != "" && PriorityRank(spec.Metadata.Priority) < 0 {
+		return nil, fmt.Errorf("invalid priority %q: must be one of %s", spec.Metadata.Priority, strings.Join(Priorities, ", "))
 	}
 
-	spec.basePath = basePath
+	for i, req := range spec.Spec.Requires {
+		if len(req.Servers) > 0 && (req.Extension != nil || req.As != nil) {
+			return nil, fmt.Errorf("invalid requires[%d]: servers cannot be set with extension or as", i)
+		}
+		if slices.Contains(req.Servers, "") {
+			return nil, fmt.Errorf("invalid requires[%d]: server names must not be empty", i)
+		}
+	}
 
-	if wrapper.GetAPIVersion() == util.APIVersionV1Alpha1 {
-		if wrapper.Steps == nil {
-			return nil, fmt.Errorf("v1alpha1 requires steps field")
+	for i := range spec.Spec.Preconditions {
+		if err := spec.Spec.Preconditions[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid preconditions[%d]: %w", i, err)
 		}
+	}
 
-		if err := resolveStepPath(wrapper.Steps.SetupScript, basePath); err != nil {
-			return nil, fmt.Errorf("failed to resolve setup script path: %w", err)
+	// Script step files are resolved against the task directory when they run
+	if spec.Spec.Prompt != nil {
+		if err := resolveStepPath(&spec.Spec.Prompt.Step, basePath); err != nil {
+			return nil, fmt.Errorf("failed to resolve prompt path: %w", err)
 		}
-		if err := resolveStepPath(wrapper.Steps.CleanupScript, basePath); err != nil {
-			return nil, fmt.Errorf("failed to resolve cleanup script path: %w", err)
+		if err := spec.Spec.Prompt.resolve(basePath); err != nil {
+			return nil, fmt.Errorf("invalid prompt: %w", err)
 		}
-		if err := resolveStepPath(wrapper.Steps.VerifyScript.Step, basePath); err != nil {
-			return nil, fmt.Errorf("failed to resolve verify script path: %w", err)
+	}
+
+	for i := range spec.Spec.Files {
+		if err := spec.Spec.Files[i].resolve(basePath); err != nil {
+			return nil, fmt.Errorf("invalid files[%d]: %w", i, err)
 		}
+	}
 
-		spec.Spec, err = translateV1Alpha1ToSteps(wrapper.Steps)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert v1alpha1 format to v1alpha1: %w", err)
+	if spec.Spec.Dataset != nil {
+		if err := spec.Spec.Dataset.resolve(basePath); err != nil {
+			return nil, fmt.Errorf("invalid dataset: %w", err)
 		}
 	}
 
-	if err := resolveStepPath(spec.Spec.Prompt, basePath); err != nil {
-		return nil, fmt.Errorf("failed to resolve prompt path: %w", err)
+	for name, server := range spec.Spec.McpServers {
+		if server == nil {
+			return nil, fmt.Errorf("mcpServers[%q] must not be empty", name)
+		}
+		if server.Disabled {
+			continue
+		}
+		if err := server.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid mcpServers[%q]: %w", name, err)
+		}
+		server.ResolvePaths(basePath)
 	}
 
 	return spec, nil
 }
 
+// ReferencedFiles returns the paths of the files the task reads besides its
+// own file: the prompt file, the files and images attached to the prompt, the
+// files copied into its working directory, its dataset, and the golden files
+// of its snapshot steps. Relative paths are resolved against the task
+// directory.
+func (t *TaskConfig) ReferencedFiles() []string {
+	if t.Spec == nil {
+		return nil
+	}
+
+	var files []string
+	add := func(path string) {
+		if path == "" {
+			return
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(t.basePath, filepath.FromSlash(path))
+		}
+		files = append(files, path)
+	}
+
+	if prompt := t.Spec.Prompt; prompt != nil {
+		add(prompt.File)
+		for _, file := range prompt.Files {
+			add(file)
+		}
+		for _, image := range prompt.Images {
+			add(image.File)
+		}
+	}
+	for _, file := range t.Spec.Files {
+		add(file.From)
+	}
+	if t.Spec.Dataset != nil {
+		add(t.Spec.Dataset.File)
+	}
+	for _, phase := range [][]steps.StepConfig{t.Spec.Setup, t.Spec.Verify, t.Spec.Cleanup} {
+		for _, step := range phase {
+			for _, file := range steps.SnapshotFiles(step) {
+				add(file)
+			}
+		}
+	}
+
+	return files
+}
+
 func resolveStepPath(step *util.Step, basePath string) error {
 	if step == nil || step.File == "" {
 		return nil
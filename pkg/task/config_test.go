@@ -1,12 +1,14 @@
 package task
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
 	"github.com/mcpchecker/mcpchecker/pkg/steps"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/stretchr/testify/assert"
@@ -40,8 +42,10 @@ func TestFromFile(t *testing.T) {
 		"create pod inline": {
 			file: "create-pod-inline.yaml",
 			expected: &TaskConfig{
+				// v1alpha1 tasks are converted to the latest version
 				TypeMeta: util.TypeMeta{
-					Kind: KindTask,
+					Kind:       KindTask,
+					APIVersion: LatestAPIVersion,
 				},
 				Metadata: TaskMetadata{
 					Name:       "create pod inline",
@@ -72,13 +76,167 @@ kubectl delete pod web-server -n create-pod-test --ignore-not-found
 kubectl delete namespace create-pod-test --ignore-not-found`,
 						}),
 					}},
-					Prompt: &util.Step{
+					Prompt: &Prompt{Step: util.Step{
 						Inline: "Please create a nginx pod named web-server in the create-pod-test namespace",
+					}},
+				},
+				basePath: basePath,
+			},
+		},
+		"mcp servers": {
+			file: "mcp-servers.yaml",
+			expected: &TaskConfig{
+				TypeMeta: util.TypeMeta{
+					Kind:       KindTask,
+					APIVersion: util.APIVersionV1Alpha2,
+				},
+				Metadata: TaskMetadata{
+					Name:       "mcp servers",
+					Difficulty: DifficultyEasy,
+				},
+				Spec: &TaskSpec{
+					McpServers: map[string]*mcpproxy.ServerConfig{
+						"fixture": {
+							Command: filepath.Join(basePath, "fixture-server"),
+							Args:    []string{"--port", "0"},
+						},
+						"filesystem": {
+							Command: "npx",
+							Args:    []string{"-y", "@modelcontextprotocol/server-filesystem"},
+						},
+						"hosted": {
+							Type: mcpproxy.TransportTypeHttp,
+							URL:  "https://mcp.example.com/mcp",
+							TLS: &mcpproxy.TLSConfig{
+								CAFile:   filepath.Join(basePath, "certs", "ca.pem"),
+								CertFile: "/etc/mcp/client.pem",
+								KeyFile:  "${MCP_CLIENT_KEY}",
+							},
+						},
+						"kubernetes": {
+							Disabled: true,
+						},
+					},
+					Prompt: &Prompt{Step: util.Step{
+						Inline: "List the fixture records",
+					}},
+				},
+				basePath: basePath,
+			},
+		},
+		"files": {
+			file: "files.yaml",
+			expected: &TaskConfig{
+				TypeMeta: util.TypeMeta{
+					Kind:       KindTask,
+					APIVersion: util.APIVersionV1Alpha2,
+				},
+				Metadata: TaskMetadata{
+					Name:       "files",
+					Difficulty: DifficultyEasy,
+				},
+				Spec: &TaskSpec{
+					Files: []File{
+						{Path: "config/settings.json", Inline: "{\"debug\": true}\n"},
+						{Path: "manifests", From: filepath.Join(basePath, "fixtures")},
+					},
+					Prompt: &Prompt{Step: util.Step{
+						Inline: "Fix the deployment in manifests/deployment.yaml",
+					}},
+				},
+				basePath: basePath,
+			},
+		},
+		"prompt files": {
+			file: "prompt-files.yaml",
+			expected: &TaskConfig{
+				TypeMeta: util.TypeMeta{
+					Kind:       KindTask,
+					APIVersion: util.APIVersionV1Alpha2,
+				},
+				Metadata: TaskMetadata{
+					Name:       "prompt files",
+					Difficulty: DifficultyEasy,
+				},
+				Spec: &TaskSpec{
+					Prompt: &Prompt{
+						Step:  util.Step{Inline: "Summarize the failing rows of the attached report"},
+						Files: []string{"fixtures/report.csv"},
 					},
 				},
 				basePath: basePath,
 			},
 		},
+		"files invalid": {
+			file:      "files-invalid.yaml",
+			expectErr: true,
+		},
+		"prompt images": {
+			file: "prompt-images.yaml",
+			expected: &TaskConfig{
+				TypeMeta: util.TypeMeta{
+					Kind:       KindTask,
+					APIVersion: util.APIVersionV1Alpha2,
+				},
+				Metadata: TaskMetadata{
+					Name:       "prompt images",
+					Difficulty: DifficultyEasy,
+				},
+				Spec: &TaskSpec{
+					Prompt: &Prompt{
+						Step: util.Step{Inline: "Click the button that saves the form"},
+						Images: []PromptImage{
+							{File: filepath.Join(basePath, "fixtures", "screen.png")},
+							{Data: "iVBORw0KGgo=", MediaType: "image/png"},
+						},
+					},
+				},
+				basePath: basePath,
+			},
+		},
+		"prompt images invalid": {
+			file:      "prompt-images-invalid.yaml",
+			expectErr: true,
+		},
+		"requires servers": {
+			file: "requires-servers.yaml",
+			expected: &TaskConfig{
+				TypeMeta: util.TypeMeta{
+					Kind:       KindTask,
+					APIVersion: util.APIVersionV1Alpha2,
+				},
+				Metadata: TaskMetadata{
+					Name:       "requires servers",
+					Difficulty: DifficultyEasy,
+				},
+				Spec: &TaskSpec{
+					Requires: []Requirements{
+						{Servers: []string{"kubernetes"}},
+						{Servers: []string{"kubernetes", "prometheus"}},
+					},
+					Prompt: &Prompt{Step: util.Step{
+						Inline: "Which pods use the most memory?",
+					}},
+				},
+				basePath: basePath,
+			},
+		},
+		"requires servers invalid": {
+			file:      "requires-servers-invalid.yaml",
+			expectErr: true,
+		},
+		"preconditions invalid": {
+			file:      "preconditions-invalid.yaml",
+			expectErr: true,
+		},
+		"prompt files invalid": {
+			file:      "prompt-files-invalid.yaml",
+			expectErr: true,
+		},
+		"mcp servers invalid": {
+			file:      "mcp-servers-invalid.yaml",
+			expectErr: true,
+		},
 	}
 
 	for tn, tc := range tt {
@@ -94,3 +252,93 @@ kubectl delete namespace create-pod-test --ignore-not-found`,
 		})
 	}
 }
+
+func TestPromptImageLoad(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "screen.png"), []byte(png), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "screen.bin"), []byte(png), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an image"), 0644))
+
+	tests := map[string]struct {
+		image       PromptImage
+		expected    util.Image
+		errContains string
+	}{
+		"file": {
+			image:    PromptImage{File: "screen.png"},
+			expected: util.Image{Name: "screen.png", MediaType: "image/png", Data: []byte(png)},
+		},
+		"file type from content": {
+			image:    PromptImage{File: "screen.bin"},
+			expected: util.Image{Name: "screen.bin", MediaType: "image/png", Data: []byte(png)},
+		},
+		"data type from content": {
+			image:    PromptImage{Data: base64.StdEncoding.EncodeToString([]byte(png))},
+			expected: util.Image{Name: "image-2.png", MediaType: "image/png", Data: []byte(png)},
+		},
+		"data with media type": {
+			image:    PromptImage{Data: base64.StdEncoding.EncodeToString([]byte("<svg/>")), MediaType: "image/svg+xml"},
+			expected: util.Image{Name: "image-2.svg", MediaType: "image/svg+xml", Data: []byte("<svg/>")},
+		},
+		"not an image": {
+			image:       PromptImage{File: "notes.txt"},
+			errContains: "notes.txt is not an image, its content is text/plain",
+		},
+		"missing file": {
+			image:       PromptImage{File: "missing.png"},
+			errContains: "failed to read image",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, tc.image.resolve(dir))
+			got, err := tc.image.load(1)
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestPromptImageResolveInvalid(t *testing.T) {
+	tests := map[string]struct {
+		image       PromptImage
+		errContains string
+	}{
+		"neither file nor data": {
+			image:       PromptImage{MediaType: "image/png"},
+			errContains: "exactly one of 'file' or 'data' must be set",
+		},
+		"invalid base64": {
+			image:       PromptImage{Data: "not base64!"},
+			errContains: "data must be base64",
+		},
+		"not an image type": {
+			image:       PromptImage{File: "report.pdf", MediaType: "application/pdf"},
+			errContains: `mediaType "application/pdf" must be an image type`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, tc.image.resolve(t.TempDir()), tc.errContains)
+		})
+	}
+}
+
+func TestRequiredServers(t *testing.T) {
+	kube := "kube"
+	spec := &TaskSpec{Requires: []Requirements{
+		{Servers: []string{"kubernetes"}},
+		{Extension: &kube},
+		{Servers: []string{"prometheus", "kubernetes"}},
+	}}
+	assert.Equal(t, []string{"kubernetes", "prometheus"}, spec.RequiredServers())
+
+	assert.Nil(t, (&TaskSpec{Requires: []Requirements{{Extension: &kube}}}).RequiredServers())
+}
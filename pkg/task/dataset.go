@@ -0,0 +1,307 @@
+package task
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// Dataset is a file of rows that each instantiate the task, such as the
+// questions and answers of a benchmark
+type Dataset struct {
+	// File is a CSV file with a header row, or a JSONL file with an object
+	// per line, relative to the task file
+	File string `json:"file"`
+}
+
+// resolve validates the dataset and makes File absolute, relative to basePath
+func (d *Dataset) resolve(basePath string) error {
+	if d.File == "" {
+		return fmt.Errorf("file must be set")
+	}
+
+	switch strings.ToLower(filepath.Ext(d.File)) {
+	case ".csv", ".jsonl", ".ndjson":
+	default:
+		return fmt.Errorf("file %q must be a .csv or .jsonl file", d.File)
+	}
+
+	if !filepath.IsAbs(d.File) {
+		d.File = filepath.Join(basePath, filepath.FromSlash(d.File))
+	}
+
+	return nil
+}
+
+// Expand returns a task for each row of the dataset of the task, or the task
+// itself if it has no dataset. The name, labels, prompt, files, and steps of
+// the task are Go templates executed with the columns of the row, such as
+// {{ .question }}. Tasks whose name does not use a column are named
+// <name>-<row>, counting rows from 1.
+func (t *TaskConfig) Expand() ([]*TaskConfig, error) {
+	if t.Spec == nil || t.Spec.Dataset == nil {
+		return []*TaskConfig{t}, nil
+	}
+
+	rows, err := readDataset(t.Spec.Dataset.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset %s: %w", t.Spec.Dataset.File, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("dataset %s has no rows", t.Spec.Dataset.File)
+	}
+
+	// A prompt file is read once and templated like an inline prompt
+	var prompt *Prompt
+	if t.Spec.Prompt != nil && !t.Spec.Prompt.IsEmpty() {
+		text, err := t.Spec.Prompt.GetValue()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt: %w", err)
+		}
+		prompt = &Prompt{Step: util.Step{Inline: text}, Files: t.Spec.Prompt.Files, Images: t.Spec.Prompt.Images}
+	}
+
+	tasks := make([]*TaskConfig, 0, len(rows))
+	names := make(map[string]int, len(rows))
+	for i, row := range rows {
+		task, err := t.instantiate(i+1, row, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("dataset row %d: %w", i+1, err)
+		}
+
+		if prev, ok := names[task.Metadata.Name]; ok {
+			return nil, fmt.Errorf("dataset row %d: task name %q is already used by row %d", i+1, task.Metadata.Name, prev)
+		}
+		names[task.Metadata.Name] = i + 1
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// instantiate returns a copy of the task, with its templates executed with
+// the row
+func (t *TaskConfig) instantiate(n int, row map[string]any, prompt *Prompt) (*TaskConfig, error) {
+	task := *t
+	spec := *t.Spec
+	task.Spec = &spec
+	spec.Dataset = nil
+
+	var err error
+	task.Metadata.Name, err = render("metadata.name", t.Metadata.Name, row)
+	if err != nil {
+		return nil, err
+	}
+	if task.Metadata.Name == t.Metadata.Name {
+		task.Metadata.Name = fmt.Sprintf("%s-%d", t.Metadata.Name, n)
+	}
+
+	task.Metadata.Description, err = render("metadata.description", t.Metadata.Description, row)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Metadata.Labels != nil {
+		task.Metadata.Labels = maps.Clone(t.Metadata.Labels)
+		for key, value := range t.Metadata.Labels {
+			task.Metadata.Labels[key], err = render(fmt.Sprintf("metadata.labels[%q]", key), value, row)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if prompt != nil {
+		text, err := render("prompt", prompt.Inline, row)
+		if err != nil {
+			return nil, err
+		}
+		spec.Prompt = &Prompt{Step: util.Step{Inline: text}, Images: prompt.Images}
+		for i, file := range prompt.Files {
+			file, err = render(fmt.Sprintf("prompt.files[%d]", i), file, row)
+			if err != nil {
+				return nil, err
+			}
+			spec.Prompt.Files = append(spec.Prompt.Files, file)
+		}
+		if err := spec.Prompt.resolve(t.basePath); err != nil {
+			return nil, fmt.Errorf("invalid prompt: %w", err)
+		}
+	}
+
+	if t.Spec.Files != nil {
+		spec.Files = make([]File, len(t.Spec.Files))
+		for i, f := range t.Spec.Files {
+			f.Inline, err = render(fmt.Sprintf("files[%d]", i), f.Inline, row)
+			if err != nil {
+				return nil, err
+			}
+			spec.Files[i] = f
+		}
+	}
+
+	phases := []struct {
+		name  string
+		steps *[]steps.StepConfig
+	}{
+		{"setup", &spec.Setup},
+		{"verify", &spec.Verify},
+		{"cleanup", &spec.Cleanup},
+	}
+	for _, phase := range phases {
+		if *phase.steps, err = renderSteps(phase.name, *phase.steps, row); err != nil {
+			return nil, err
+		}
+	}
+
+	return &task, nil
+}
+
+// renderSteps returns copies of the steps with the templates in their string
+// values executed with the row
+func renderSteps(phase string, stepConfigs []steps.StepConfig, row map[string]any) ([]steps.StepConfig, error) {
+	if stepConfigs == nil {
+		return nil, nil
+	}
+
+	rendered := make([]steps.StepConfig, len(stepConfigs))
+	for i, cfg := range stepConfigs {
+		rendered[i] = make(steps.StepConfig, len(cfg))
+		for key, raw := range cfg {
+			name := fmt.Sprintf("%s[%d].%s", phase, i, key)
+			if !bytes.Contains(raw, []byte("{{")) {
+				rendered[i][key] = raw
+				continue
+			}
+
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.UseNumber()
+			var value any
+			if err := dec.Decode(&value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			value, err := renderValue(name, value, row)
+			if err != nil {
+				return nil, err
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			rendered[i][key] = data
+		}
+	}
+	return rendered, nil
+}
+
+// renderValue executes the templates in the strings of a JSON value
+func renderValue(name string, value any, row map[string]any) (any, error) {
+	var err error
+	switch v := value.(type) {
+	case string:
+		return render(name, v, row)
+	case map[string]any:
+		for key, item := range v {
+			if v[key], err = renderValue(name+"."+key, item, row); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if v[i], err = renderValue(fmt.Sprintf("%s[%d]", name, i), item, row); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return value, nil
+}
+
+// render executes text as a template with the row. A column the template uses
+// that the row does not have is an error.
+func render(name, text string, row map[string]any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template in %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, row); err != nil {
+		return "", fmt.Errorf("failed to execute template in %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// readDataset reads the rows of a CSV or JSONL dataset
+func readDataset(path string) ([]map[string]any, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readCSV(f)
+	}
+	return readJSONL(f)
+}
+
+// readCSV reads the rows of a CSV file, keyed by the columns of its header row
+func readCSV(r io.Reader) ([]map[string]any, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]any, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]any, len(header))
+		for i, column := range header {
+			row[strings.TrimSpace(column)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readJSONL reads the rows of a JSONL file, skipping blank lines. Numbers are
+// kept as written.
+func readJSONL(r io.Reader) ([]map[string]any, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var rows []map[string]any
+	for {
+		var row map[string]any
+		err := dec.Decode(&row)
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", len(rows)+1, err)
+		}
+		if row == nil {
+			return nil, fmt.Errorf("row %d: must be a JSON object", len(rows)+1)
+		}
+		rows = append(rows, row)
+	}
+}
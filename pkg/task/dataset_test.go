@@ -0,0 +1,196 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const datasetTask = `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: %s
+  difficulty: easy
+  description: Answers the capital of {{ .country }}
+  labels:
+    suite: capitals
+    country: "{{ .country }}"
+spec:
+  dataset:
+    file: %s
+  prompt:
+    inline: What is the capital of {{ .country }}?
+  verify:
+    - extract:
+        regex: "Answer: (.+)"
+        expect:
+          oneOf: ["{{ .capital }}", "{{ .capital }} City"]
+          ignoreCase: true
+    - script:
+        inline: echo done
+`
+
+// writeDatasetTask writes a task with the given name and dataset file, and
+// the dataset, and loads the task
+func writeDatasetTask(t *testing.T, name, file, data string) *TaskConfig {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(data), 0644))
+
+	path := filepath.Join(dir, "task.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(datasetTask, name, file)), 0644))
+
+	task, err := FromFile(path)
+	require.NoError(t, err)
+	return task
+}
+
+func TestExpand(t *testing.T) {
+	tests := map[string]struct {
+		name          string
+		file          string
+		data          string
+		expectedNames []string
+	}{
+		"csv": {
+			name:          "capital",
+			file:          "capitals.csv",
+			data:          "country,capital\nFrance,Paris\n\"Korea, South\",Seoul\n",
+			expectedNames: []string{"capital-1", "capital-2"},
+		},
+		"jsonl": {
+			name:          "capital",
+			file:          "capitals.jsonl",
+			data:          "{\"country\": \"France\", \"capital\": \"Paris\"}\n\n{\"country\": \"Korea, South\", \"capital\": \"Seoul\"}\n",
+			expectedNames: []string{"capital-1", "capital-2"},
+		},
+		"name from column": {
+			name:          `"capital-of-{{ .capital }}"`,
+			file:          "capitals.csv",
+			data:          "country,capital\nFrance,Paris\n\"Korea, South\",Seoul\n",
+			expectedNames: []string{"capital-of-Paris", "capital-of-Seoul"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			template := writeDatasetTask(t, tc.name, tc.file, tc.data)
+
+			tasks, err := template.Expand()
+			require.NoError(t, err)
+			require.Len(t, tasks, 2)
+
+			for i, task := range tasks {
+				assert.Equal(t, tc.expectedNames[i], task.Metadata.Name)
+				assert.Nil(t, task.Spec.Dataset)
+				assert.Equal(t, "capitals", task.Metadata.Labels["suite"])
+			}
+
+			korea := tasks[1]
+			assert.Equal(t, "Korea, South", korea.Metadata.Labels["country"])
+			assert.Equal(t, "Answers the capital of Korea, South", korea.Metadata.Description)
+			assert.Equal(t, "What is the capital of Korea, South?", korea.Spec.Prompt.Inline)
+
+			var extract steps.ExtractStepConfig
+			require.NoError(t, json.Unmarshal(korea.Spec.Verify[0]["extract"], &extract))
+			assert.Equal(t, []string{"Seoul", "Seoul City"}, extract.Expect.OneOf)
+			assert.True(t, extract.Expect.IgnoreCase)
+			assert.JSONEq(t, `{"inline": "echo done"}`, string(korea.Spec.Verify[1]["script"]))
+
+			// The template is not changed
+			assert.Equal(t, "{{ .country }}", template.Metadata.Labels["country"])
+			assert.Contains(t, string(template.Spec.Verify[0]["extract"]), "{{ .capital }}")
+		})
+	}
+}
+
+func TestExpandWithoutDataset(t *testing.T) {
+	task := &TaskConfig{Metadata: TaskMetadata{Name: "plain"}, Spec: &TaskSpec{}}
+
+	tasks, err := task.Expand()
+	require.NoError(t, err)
+	assert.Equal(t, []*TaskConfig{task}, tasks)
+}
+
+func TestExpandInvalid(t *testing.T) {
+	tests := map[string]struct {
+		name        string
+		file        string
+		data        string
+		errContains string
+	}{
+		"missing column": {
+			name:        "capital",
+			file:        "capitals.csv",
+			data:        "country\nFrance\n",
+			errContains: `dataset row 1: failed to execute template in verify[0].extract.expect.oneOf[0]`,
+		},
+		"no rows": {
+			name:        "capital",
+			file:        "capitals.csv",
+			data:        "country,capital\n",
+			errContains: "has no rows",
+		},
+		"duplicate names": {
+			name:        `"capital-{{ .capital }}"`,
+			file:        "capitals.csv",
+			data:        "country,capital\nFrance,Paris\nTexas,Paris\n",
+			errContains: `dataset row 2: task name "capital-Paris" is already used by row 1`,
+		},
+		"invalid jsonl": {
+			name:        "capital",
+			file:        "capitals.jsonl",
+			data:        "{\"country\": \"France\", \"capital\": \"Paris\"}\n[\"Germany\"]\n",
+			errContains: "row 2:",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := writeDatasetTask(t, tc.name, tc.file, tc.data).Expand()
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
+
+func TestDatasetFileType(t *testing.T) {
+	_, err := Read([]byte(fmt.Sprintf(datasetTask, "capital", "capitals.json")), t.TempDir())
+	assert.ErrorContains(t, err, `invalid dataset: file "capitals.json" must be a .csv or .jsonl file`)
+}
+
+func TestExpandPromptFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "reports.csv"), []byte("report\nweb\n../../outside\n"), 0644))
+
+	task, err := Read([]byte(`kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: report
+  difficulty: easy
+spec:
+  dataset:
+    file: reports.csv
+  prompt:
+    inline: Summarize the attached report
+    files:
+      - "reports/{{ .report }}.csv"
+`), dir)
+	require.NoError(t, err)
+
+	_, err = task.Expand()
+	assert.ErrorContains(t, err, `dataset row 2: invalid prompt: files[0]: path "reports/../../outside.csv" must be relative and inside the task directory`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "reports.csv"), []byte("report\nweb\n"), 0644))
+	tasks, err := task.Expand()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, []string{"reports/web.csv"}, tasks[0].Spec.Prompt.Files)
+	assert.Equal(t, []string{"reports/{{ .report }}.csv"}, task.Spec.Prompt.Files)
+}
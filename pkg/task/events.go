@@ -0,0 +1,58 @@
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+)
+
+// Phases that run steps
+const (
+	PhaseSetup   = "setup"
+	PhaseVerify  = "verify"
+	PhaseCleanup = "cleanup"
+)
+
+type StepEventType string
+
+const (
+	StepEventStart    StepEventType = "step_start"
+	StepEventComplete StepEventType = "step_complete"
+)
+
+// StepEvent reports a setup, verify, or cleanup step starting or completing.
+type StepEvent struct {
+	Type  StepEventType
+	Phase string
+	// Index is the position of the step within its phase
+	Index    int
+	StepType string
+
+	// Output and Err are only set when the step completed
+	Output *steps.StepOutput
+	Err    error
+}
+
+// ID identifies the step within its task, e.g. "verify[1]"
+func (e StepEvent) ID() string {
+	return fmt.Sprintf("%s[%d]", e.Phase, e.Index)
+}
+
+// StepObserver is called as each step of a task starts and completes
+type StepObserver func(event StepEvent)
+
+type stepObserverKey struct{}
+
+// StepObserverToContext returns a context whose task runners report step events to observer
+func StepObserverToContext(ctx context.Context, observer StepObserver) context.Context {
+	return context.WithValue(ctx, stepObserverKey{}, observer)
+}
+
+// StepObserverFromContext returns the step observer in ctx, or one that does nothing
+func StepObserverFromContext(ctx context.Context) StepObserver {
+	if observer, ok := ctx.Value(stepObserverKey{}).(StepObserver); ok && observer != nil {
+		return observer
+	}
+	return func(StepEvent) {}
+}
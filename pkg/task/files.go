@@ -0,0 +1,103 @@
+package task
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// File is a fixture file written to the task's working directory
+type File struct {
+	// Path is where the file is written, relative to the working directory
+	Path string `json:"path"`
+	// Inline is the content of the file
+	Inline string `json:"inline,omitempty"`
+	// From is a file or directory to copy, relative to the task file
+	From string `json:"from,omitempty"`
+}
+
+// resolve validates the file and makes From absolute, relative to basePath
+func (f *File) resolve(basePath string) error {
+	if f.Path == "" {
+		return fmt.Errorf("path must be set")
+	}
+	if !filepath.IsLocal(filepath.FromSlash(f.Path)) {
+		return fmt.Errorf("path %q must be relative and inside the working directory", f.Path)
+	}
+
+	if (f.Inline == "") == (f.From == "") {
+		return fmt.Errorf("exactly one of 'inline' or 'from' must be set on file %q", f.Path)
+	}
+
+	if f.From != "" && !filepath.IsAbs(f.From) {
+		f.From = filepath.Join(basePath, filepath.FromSlash(f.From))
+	}
+
+	return nil
+}
+
+// writeFiles materializes files into dir
+func writeFiles(dir string, files []File) error {
+	for _, f := range files {
+		dest := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for file %q: %w", f.Path, err)
+		}
+
+		if f.From == "" {
+			if err := os.WriteFile(dest, []byte(f.Inline), 0644); err != nil {
+				return fmt.Errorf("failed to write file %q: %w", f.Path, err)
+			}
+			continue
+		}
+
+		if err := copyPath(f.From, dest); err != nil {
+			return fmt.Errorf("failed to copy %q to file %q: %w", f.From, f.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// copyPath copies a file or a directory tree, keeping file permissions
+func copyPath(src, dest string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return copyFile(src, dest, info.Mode().Perm())
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(src, dest string, perm fs.FileMode) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, perm)
+}
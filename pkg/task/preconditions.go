@@ -0,0 +1,155 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultPreconditionTimeout is how long a precondition may take if it does
+// not say otherwise
+const DefaultPreconditionTimeout = 10 * time.Second
+
+// Precondition is a property of the environment a task needs. Preconditions
+// are checked before setup, and a task whose preconditions are not met is
+// skipped instead of failed. Exactly one of Command, URL, or KubeContext must
+// be set.
+type Precondition struct {
+	// Command must exit with code 0. It runs in the task directory.
+	Command string `json:"command,omitempty"`
+
+	// URL must answer a GET request with a status below 500
+	URL string `json:"url,omitempty"`
+
+	// KubeContext must be a context in the kubeconfig, read from $KUBECONFIG
+	// or ~/.kube/config
+	KubeContext string `json:"kubeContext,omitempty"`
+
+	// Timeout limits how long the check may take. Defaults to 10s.
+	Timeout util.Duration `json:"timeout,omitempty"`
+}
+
+func (p *Precondition) validate() error {
+	set := 0
+	for _, v := range []string{p.Command, p.URL, p.KubeContext} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of 'command', 'url', or 'kubeContext' must be set")
+	}
+	if p.URL != "" && !strings.HasPrefix(p.URL, "http://") && !strings.HasPrefix(p.URL, "https://") {
+		return fmt.Errorf("url %q must be an http or https URL", p.URL)
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	return nil
+}
+
+// UnmetPrecondition checks the preconditions of the task in order and returns
+// why the first one that is not met fails, or "" if all of them are met
+func (t *TaskConfig) UnmetPrecondition(ctx context.Context) string {
+	for _, p := range t.Spec.Preconditions {
+		if err := p.check(ctx, t.basePath); err != nil {
+			return fmt.Sprintf("precondition not met: %s", err)
+		}
+	}
+	return ""
+}
+
+// check returns an error if the precondition is not met. Commands run in dir.
+func (p *Precondition) check(ctx context.Context, dir string) error {
+	timeout := time.Duration(p.Timeout)
+	if timeout == 0 {
+		timeout = DefaultPreconditionTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch {
+	case p.Command != "":
+		cmd := shell.Default().Command(ctx, p.Command)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if output := strings.TrimSpace(string(out)); output != "" {
+				return fmt.Errorf("command %q failed: %w: %s", p.Command, err, output)
+			}
+			return fmt.Errorf("command %q failed: %w", p.Command, err)
+		}
+	case p.URL != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+		if err != nil {
+			return fmt.Errorf("url %s is not reachable: %w", p.URL, err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("url %s is not reachable: %w", p.URL, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("url %s is not reachable: %s", p.URL, resp.Status)
+		}
+	case p.KubeContext != "":
+		contexts, err := kubeContexts()
+		if err != nil {
+			return fmt.Errorf("failed to read kubeconfig: %w", err)
+		}
+		if !slices.Contains(contexts, p.KubeContext) {
+			return fmt.Errorf("kube context %q is not in the kubeconfig", p.KubeContext)
+		}
+	}
+	return nil
+}
+
+// kubeContexts returns the names of the contexts in the kubeconfig files
+// listed in $KUBECONFIG, or in ~/.kube/config. Missing files have no contexts.
+func kubeContexts() ([]string, error) {
+	paths := filepath.SplitList(os.Getenv("KUBECONFIG"))
+	if len(paths) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		paths = []string{filepath.Join(home, ".kube", "config")}
+	}
+
+	var contexts []string
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var config struct {
+			Contexts []struct {
+				Name string `json:"name"`
+			} `json:"contexts"`
+		}
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig %s: %w", path, err)
+		}
+		for _, c := range config.Contexts {
+			contexts = append(contexts, c.Name)
+		}
+	}
+	return contexts, nil
+}
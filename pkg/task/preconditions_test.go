@@ -0,0 +1,105 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmetPrecondition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+contexts:
+  - name: kind-kind
+    context:
+      cluster: kind-kind
+`), 0644))
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "missing")+string(os.PathListSeparator)+kubeconfig)
+
+	tests := map[string]struct {
+		preconditions  []Precondition
+		reasonContains string
+	}{
+		"none": {},
+		"all met": {
+			preconditions: []Precondition{
+				{Command: "exit 0"},
+				{URL: server.URL + "/missing"},
+				{KubeContext: "kind-kind"},
+			},
+		},
+		"command fails": {
+			preconditions:  []Precondition{{Command: "exit 0"}, {Command: "exit 3"}},
+			reasonContains: `precondition not met: command "exit 3" failed`,
+		},
+		"url answers with server error": {
+			preconditions:  []Precondition{{URL: server.URL + "/down"}},
+			reasonContains: "is not reachable: 503 Service Unavailable",
+		},
+		"url not reachable": {
+			preconditions:  []Precondition{{URL: "http://127.0.0.1:1/healthz"}},
+			reasonContains: "url http://127.0.0.1:1/healthz is not reachable",
+		},
+		"kube context missing": {
+			preconditions:  []Precondition{{KubeContext: "prod"}},
+			reasonContains: `precondition not met: kube context "prod" is not in the kubeconfig`,
+		},
+	}
+
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			cfg := &TaskConfig{Spec: &TaskSpec{Preconditions: tc.preconditions}, basePath: dir}
+			reason := cfg.UnmetPrecondition(context.Background())
+			if tc.reasonContains == "" {
+				assert.Empty(t, reason)
+				return
+			}
+			assert.Contains(t, reason, tc.reasonContains)
+		})
+	}
+}
+
+func TestPreconditionValidate(t *testing.T) {
+	tests := map[string]struct {
+		precondition Precondition
+		errContains  string
+	}{
+		"command":     {precondition: Precondition{Command: "true"}},
+		"none set":    {errContains: "exactly one of 'command', 'url', or 'kubeContext' must be set"},
+		"two set":     {precondition: Precondition{Command: "true", URL: "http://localhost"}, errContains: "exactly one of"},
+		"invalid url": {precondition: Precondition{URL: "localhost:9000"}, errContains: "must be an http or https URL"},
+		"negative timeout": {
+			precondition: Precondition{Command: "true", Timeout: -1},
+			errContains:  "timeout must not be negative",
+		},
+	}
+
+	for tn, tc := range tests {
+		t.Run(tn, func(t *testing.T) {
+			err := tc.precondition.validate()
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errContains)
+		})
+	}
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/agent"
 	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
 	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 // PhaseOutput represents the output from a task phase (setup, agent, verify, or cleanup).
@@ -33,12 +39,25 @@ type TaskRunner interface {
 }
 
 type taskRunner struct {
-	setup   []steps.StepRunner
-	verify  []steps.StepRunner
-	cleanup []steps.StepRunner
+	setup   []step
+	verify  []step
+	cleanup []step
 	prompt  string
 	output  string
 	baseDir string
+	files   []File
+	// workdir is the working directory created for the task's files, if it has any
+	workdir string
+	// promptFiles are the files attached to the prompt, relative to workdir
+	promptFiles []string
+	// images are the images attached to the prompt
+	images []util.Image
+}
+
+// step is a parsed step along with the type it was configured as
+type step struct {
+	runner   steps.StepRunner
+	stepType string
 }
 
 func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
@@ -48,10 +67,11 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 
 	var err error
 	r := &taskRunner{
-		setup:   make([]steps.StepRunner, len(cfg.Spec.Setup)),
-		verify:  make([]steps.StepRunner, len(cfg.Spec.Verify)),
-		cleanup: make([]steps.StepRunner, len(cfg.Spec.Cleanup)),
+		setup:   make([]step, len(cfg.Spec.Setup)),
+		verify:  make([]step, len(cfg.Spec.Verify)),
+		cleanup: make([]step, len(cfg.Spec.Cleanup)),
 		baseDir: cfg.basePath,
+		files:   cfg.Spec.Files,
 	}
 
 	extensionManager, ok := client.ManagerFromContext(ctx)
@@ -91,7 +111,7 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 
 	for i, stepCfg := range cfg.Spec.Setup {
 		var stepErr error
-		r.setup[i], stepErr = parser.Parse(stepCfg)
+		r.setup[i], stepErr = parseStep(parser, stepCfg)
 		if stepErr != nil {
 			err = errors.Join(err, fmt.Errorf("failed to parse setup[%d]: %w", i, stepErr))
 		}
@@ -99,7 +119,7 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 
 	for i, stepCfg := range cfg.Spec.Verify {
 		var stepErr error
-		r.verify[i], stepErr = parser.Parse(stepCfg)
+		r.verify[i], stepErr = parseStep(parser, stepCfg)
 		if stepErr != nil {
 			err = errors.Join(err, fmt.Errorf("failed to parse verify[%d]: %w", i, stepErr))
 		}
@@ -107,7 +127,7 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 
 	for i, stepCfg := range cfg.Spec.Cleanup {
 		var stepErr error
-		r.cleanup[i], stepErr = parser.Parse(stepCfg)
+		r.cleanup[i], stepErr = parseStep(parser, stepCfg)
 		if stepErr != nil {
 			err = errors.Join(err, fmt.Errorf("failed to parse cleanup[%d]: %w", i, stepErr))
 		}
@@ -122,73 +142,110 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 		return nil, fmt.Errorf("failed to get prompt for task: %w", err)
 	}
 
+	// Files attached to the prompt are copied to the working directory with
+	// the files of the task
+	if len(cfg.Spec.Prompt.Files) > 0 {
+		r.files = slices.Clone(r.files)
+		for _, file := range cfg.Spec.Prompt.Files {
+			r.files = append(r.files, File{
+				Path: file,
+				From: filepath.Join(r.baseDir, filepath.FromSlash(file)),
+			})
+		}
+		r.promptFiles = cfg.Spec.Prompt.Files
+	}
+
+	for i := range cfg.Spec.Prompt.Images {
+		image, err := cfg.Spec.Prompt.Images[i].load(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load prompt.images[%d]: %w", i, err)
+		}
+		r.images = append(r.images, image)
+	}
+
 	return r, nil
 }
 
 func (r *taskRunner) Setup(ctx context.Context) (*PhaseOutput, error) {
-	out := &PhaseOutput{
-		Steps:   make([]*steps.StepOutput, 0),
-		Success: true,
+	if len(r.files) > 0 {
+		if err := r.createWorkdir(); err != nil {
+			return &PhaseOutput{Success: false, Error: err.Error()}, err
+		}
 	}
 
-	for i, s := range r.setup {
-		res, err := s.Execute(ctx, &steps.StepInput{
-			Workdir: r.baseDir,
-		})
+	return r.runPhase(ctx, PhaseSetup, r.setup, r.stepInput())
+}
 
-		out.Steps = append(out.Steps, res)
-		if err != nil {
-			out.Success = false
-			out.Error = err.Error()
-			return out, fmt.Errorf("setup[%d] failed: %w", i, err)
-		}
-		if res != nil && !res.Success {
-			out.Success = false
-		}
+func (r *taskRunner) Cleanup(ctx context.Context) (*PhaseOutput, error) {
+	out, err := r.runPhase(ctx, PhaseCleanup, r.cleanup, r.stepInput())
+
+	if r.workdir != "" {
+		_ = os.RemoveAll(r.workdir)
+		r.workdir = ""
 	}
 
-	return out, nil
+	return out, err
 }
 
-func (r *taskRunner) Cleanup(ctx context.Context) (*PhaseOutput, error) {
-	out := &PhaseOutput{
-		Steps:   make([]*steps.StepOutput, 0),
-		Success: true,
+// createWorkdir creates the task's working directory and writes its files.
+// The directory is removed again if the files cannot be written.
+func (r *taskRunner) createWorkdir() error {
+	dir, err := os.MkdirTemp("", "mcpchecker-task-")
+	if err != nil {
+		return fmt.Errorf("failed to create working directory for task: %w", err)
 	}
 
-	for i, s := range r.cleanup {
-		res, err := s.Execute(ctx, &steps.StepInput{
-			Workdir: r.baseDir,
-		})
+	if err := writeFiles(dir, r.files); err != nil {
+		_ = os.RemoveAll(dir)
+		return fmt.Errorf("failed to write task files: %w", err)
+	}
 
-		out.Steps = append(out.Steps, res)
-		if err != nil {
-			out.Success = false
-			out.Error = err.Error()
-			return out, fmt.Errorf("cleanup[%d] failed: %w", i, err)
-		}
-		if res != nil && !res.Success {
-			out.Success = false
-		}
+	r.workdir = dir
+	return nil
+}
+
+// stepInput returns the input of the steps of a phase. Steps run in the task's
+// working directory if it has one, and in the task directory otherwise.
+func (r *taskRunner) stepInput() *steps.StepInput {
+	if r.workdir == "" {
+		return &steps.StepInput{Workdir: r.baseDir}
 	}
 
-	return out, nil
+	return &steps.StepInput{
+		Workdir: r.workdir,
+		TaskDir: r.baseDir,
+	}
 }
 
 func (r *taskRunner) RunAgent(ctx context.Context, agent agent.Runner) (*PhaseOutput, error) {
+	if r.workdir != "" {
+		ctx = util.WithWorkdir(ctx, r.workdir)
+	}
+	if len(r.promptFiles) > 0 {
+		files := make([]string, len(r.promptFiles))
+		for i, file := range r.promptFiles {
+			files[i] = filepath.Join(r.workdir, filepath.FromSlash(file))
+		}
+		ctx = util.WithPromptFiles(ctx, files)
+	}
+	if len(r.images) > 0 {
+		ctx = util.WithPromptImages(ctx, r.images)
+	}
+
+	start := time.Now()
 	result, err := agent.RunTask(ctx, r.prompt)
+	duration := util.Since(start)
 	if err != nil {
 		detailErr := fmt.Errorf("failed to run agent: %w", err)
 		return &PhaseOutput{
 			Success: false,
 			Error:   detailErr.Error(),
 			Steps: []*steps.StepOutput{{
-				Type:    "agent",
-				Success: false,
-				Error:   detailErr.Error(),
-				Outputs: map[string]string{
-					"output": err.Error(),
-				},
+				Type:     "agent",
+				Success:  false,
+				Error:    detailErr.Error(),
+				Duration: duration,
+				Outputs:  r.agentOutputs(err.Error()),
 			}},
 		}, detailErr
 	}
@@ -200,36 +257,147 @@ func (r *taskRunner) RunAgent(ctx context.Context, agent agent.Runner) (*PhaseOu
 	return &PhaseOutput{
 		Success: true,
 		Steps: []*steps.StepOutput{{
-			Type:    "agent",
-			Success: true,
-			Message: output,
-			Outputs: map[string]string{
-				"output": output,
-			},
+			Type:     "agent",
+			Success:  true,
+			Message:  output,
+			Duration: duration,
+			Outputs:  r.agentOutputs(output),
 		}},
 	}, nil
 }
 
+// agentOutputs returns the outputs of the agent step. The images attached to
+// the prompt are recorded by name, type, and size.
+func (r *taskRunner) agentOutputs(output string) map[string]string {
+	outputs := map[string]string{
+		"output": output,
+	}
+	if len(r.images) > 0 {
+		images := make([]string, len(r.images))
+		for i, image := range r.images {
+			images[i] = image.String()
+		}
+		outputs["images"] = strings.Join(images, "\n")
+	}
+	return outputs
+}
+
 func (r *taskRunner) Verify(ctx context.Context) (*PhaseOutput, error) {
+	input := r.stepInput()
+	input.Agent = &steps.AgentContext{
+		Prompt: r.prompt,
+		Output: r.output,
+	}
+
+	return r.runPhase(ctx, PhaseVerify, r.verify, input)
+}
+
+// RunStep runs one setup, verify, or cleanup step of a task on its own, so a
+// step can be debugged without running the rest of the task. The step is
+// identified like "verify[1]". Verify steps get agent as the prompt and output
+// of the agent, with the prompt of the task if agent has none; setup and
+// cleanup steps do not see the agent, as in a full run.
+func RunStep(ctx context.Context, cfg *TaskConfig, id string, agent *steps.AgentContext) (*steps.StepOutput, error) {
+	phase, index, err := ParseStepID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	runner, err := NewTaskRunner(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return runner.(*taskRunner).runStep(ctx, phase, index, agent)
+}
+
+// runStep runs the step at index of a phase, in a working directory of its own
+// if the task has files
+func (r *taskRunner) runStep(ctx context.Context, phase string, index int, agent *steps.AgentContext) (*steps.StepOutput, error) {
+	phaseSteps := map[string][]step{
+		PhaseSetup:   r.setup,
+		PhaseVerify:  r.verify,
+		PhaseCleanup: r.cleanup,
+	}[phase]
+	if index >= len(phaseSteps) {
+		return nil, fmt.Errorf("there is no %s[%d], the task has %d %s steps", phase, index, len(phaseSteps), phase)
+	}
+
+	if len(r.files) > 0 {
+		if err := r.createWorkdir(); err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = os.RemoveAll(r.workdir)
+			r.workdir = ""
+		}()
+	}
+
+	input := r.stepInput()
+	if phase == PhaseVerify {
+		input.Agent = &steps.AgentContext{Prompt: r.prompt}
+		if agent != nil {
+			input.Agent.Output = agent.Output
+			if agent.Prompt != "" {
+				input.Agent.Prompt = agent.Prompt
+			}
+		}
+	}
+
+	s := phaseSteps[index]
+	start := time.Now()
+	res, err := s.runner.Execute(ctx, input)
+	setDuration(res, start)
+	if res != nil && res.Type == "" {
+		res.Type = s.stepType
+	}
+
+	return res, err
+}
+
+// ParseStepID parses a step ID like "verify[1]" into its phase and index
+func ParseStepID(id string) (string, int, error) {
+	phase, rest, ok := strings.Cut(id, "[")
+	index, err := strconv.Atoi(strings.TrimSuffix(rest, "]"))
+	if !ok || !strings.HasSuffix(rest, "]") || err != nil || index < 0 {
+		return "", 0, fmt.Errorf("invalid step %q, must be like verify[0]", id)
+	}
+
+	switch phase {
+	case PhaseSetup, PhaseVerify, PhaseCleanup:
+		return phase, index, nil
+	default:
+		return "", 0, fmt.Errorf("invalid step %q, phase must be %s, %s, or %s", id, PhaseSetup, PhaseVerify, PhaseCleanup)
+	}
+}
+
+// runPhase runs the steps of a phase in order, stopping at the first step that
+// returns an error. Steps that fail without an error don't stop the phase.
+func (r *taskRunner) runPhase(ctx context.Context, phase string, phaseSteps []step, input *steps.StepInput) (*PhaseOutput, error) {
 	out := &PhaseOutput{
 		Steps:   make([]*steps.StepOutput, 0),
 		Success: true,
 	}
 
-	for i, s := range r.verify {
-		res, err := s.Execute(ctx, &steps.StepInput{
-			Agent: &steps.AgentContext{
-				Prompt: r.prompt,
-				Output: r.output,
-			},
-			Workdir: r.baseDir,
-		})
+	observer := StepObserverFromContext(ctx)
+
+	for i, s := range phaseSteps {
+		observer(StepEvent{Type: StepEventStart, Phase: phase, Index: i, StepType: s.stepType})
+
+		// Each step gets its own copy of the input
+		stepInput := *input
+
+		start := time.Now()
+		res, err := s.runner.Execute(ctx, &stepInput)
+		setDuration(res, start)
+
+		observer(StepEvent{Type: StepEventComplete, Phase: phase, Index: i, StepType: s.stepType, Output: res, Err: err})
 
 		out.Steps = append(out.Steps, res)
 		if err != nil {
 			out.Success = false
 			out.Error = err.Error()
-			return out, fmt.Errorf("verify[%d] failed: %w", i, err)
+			return out, fmt.Errorf("%s[%d] failed: %w", phase, i, err)
 		}
 		if res != nil && !res.Success {
 			out.Success = false
@@ -238,3 +406,26 @@ func (r *taskRunner) Verify(ctx context.Context) (*PhaseOutput, error) {
 
 	return out, nil
 }
+
+// parseStep parses a step config, keeping its type for progress reporting
+func parseStep(parser *steps.Registry, cfg steps.StepConfig) (step, error) {
+	runner, err := parser.Parse(cfg)
+	if err != nil {
+		return step{}, err
+	}
+
+	// Parse has already checked that the config has exactly one type
+	var stepType string
+	for t := range cfg {
+		stepType = t
+	}
+
+	return step{runner: runner, stepType: stepType}, nil
+}
+
+// setDuration records how long a step took on its output, if it returned one
+func setDuration(res *steps.StepOutput, start time.Time) {
+	if res != nil {
+		res.Duration = util.Since(start)
+	}
+}
@@ -0,0 +1,323 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStep struct {
+	out *steps.StepOutput
+	err error
+}
+
+func (f *fakeStep) Execute(ctx context.Context, input *steps.StepInput) (*steps.StepOutput, error) {
+	return f.out, f.err
+}
+
+// recordingStep records the input it was run with
+type recordingStep struct {
+	input *steps.StepInput
+}
+
+func (r *recordingStep) Execute(ctx context.Context, input *steps.StepInput) (*steps.StepOutput, error) {
+	r.input = input
+	return &steps.StepOutput{Success: true}, nil
+}
+
+// workdirAgent records the working directory it was run in
+type workdirAgent struct {
+	workdir string
+}
+
+type workdirAgentResult struct{}
+
+func (workdirAgentResult) GetOutput() string { return "done" }
+
+func (a *workdirAgent) RunTask(ctx context.Context, prompt string) (agent.AgentResult, error) {
+	a.workdir, _ = util.WorkdirFromContext(ctx)
+	return workdirAgentResult{}, nil
+}
+
+func (a *workdirAgent) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) agent.Runner {
+	return a
+}
+
+func (a *workdirAgent) AgentName() string { return "workdir" }
+
+func TestTaskFiles(t *testing.T) {
+	taskDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(taskDir, "fixtures", "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(taskDir, "fixtures", "nested", "run.sh"), []byte("echo hi\n"), 0755))
+
+	setup, verify, cleanup := &recordingStep{}, &recordingStep{}, &recordingStep{}
+	r := &taskRunner{
+		setup:   []step{{runner: setup, stepType: "script"}},
+		verify:  []step{{runner: verify, stepType: "script"}},
+		cleanup: []step{{runner: cleanup, stepType: "script"}},
+		baseDir: taskDir,
+		files: []File{
+			{Path: "config/settings.json", Inline: `{"debug": true}`},
+			{Path: "scripts", From: filepath.Join(taskDir, "fixtures")},
+		},
+	}
+
+	ctx := context.Background()
+
+	_, err := r.Setup(ctx)
+	require.NoError(t, err)
+
+	workdir := setup.input.Workdir
+	assert.NotEqual(t, taskDir, workdir)
+	assert.Equal(t, taskDir, setup.input.TaskDir)
+
+	data, err := os.ReadFile(filepath.Join(workdir, "config", "settings.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"debug": true}`, string(data))
+
+	info, err := os.Stat(filepath.Join(workdir, "scripts", "nested", "run.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	a := &workdirAgent{}
+	_, err = r.RunAgent(ctx, a)
+	require.NoError(t, err)
+	assert.Equal(t, workdir, a.workdir)
+
+	_, err = r.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, workdir, verify.input.Workdir)
+	assert.Equal(t, "done", verify.input.Agent.Output)
+
+	_, err = r.Cleanup(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, workdir, cleanup.input.Workdir)
+
+	_, err = os.Stat(workdir)
+	assert.True(t, os.IsNotExist(err), "working directory should be removed after cleanup")
+}
+
+func TestTaskWithoutFilesRunsInTaskDir(t *testing.T) {
+	setup := &recordingStep{}
+	r := &taskRunner{
+		setup:   []step{{runner: setup, stepType: "script"}},
+		baseDir: "/tasks/create-pod",
+	}
+
+	_, err := r.Setup(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &steps.StepInput{Workdir: "/tasks/create-pod"}, setup.input)
+
+	a := &workdirAgent{}
+	_, err = r.RunAgent(context.Background(), a)
+	require.NoError(t, err)
+	assert.Empty(t, a.workdir)
+}
+
+// promptFilesAgent records the files attached to the prompt it was run with
+type promptFilesAgent struct {
+	workdirAgent
+	files []string
+}
+
+func (a *promptFilesAgent) RunTask(ctx context.Context, prompt string) (agent.AgentResult, error) {
+	a.files = util.PromptFilesFromContext(ctx)
+	return a.workdirAgent.RunTask(ctx, prompt)
+}
+
+func TestPromptFiles(t *testing.T) {
+	cfg, err := FromFile(filepath.Join(testCasePath, "prompt-files.yaml"))
+	require.NoError(t, err)
+
+	ctx := client.ManagerToContext(context.Background(), client.NewManager(nil, client.ExtensionOptions{}))
+	r, err := NewTaskRunner(ctx, cfg)
+	require.NoError(t, err)
+
+	_, err = r.Setup(ctx)
+	require.NoError(t, err)
+
+	a := &promptFilesAgent{}
+	_, err = r.RunAgent(ctx, a)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, a.workdir)
+	path := filepath.Join(a.workdir, "fixtures", "report.csv")
+	assert.Equal(t, []string{path}, a.files)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "name,status\nweb,failed\ndb,ok\n", string(data))
+
+	_, err = r.Cleanup(ctx)
+	require.NoError(t, err)
+}
+
+// promptImagesAgent records the images attached to the prompt it was run with
+type promptImagesAgent struct {
+	workdirAgent
+	images []util.Image
+}
+
+func (a *promptImagesAgent) RunTask(ctx context.Context, prompt string) (agent.AgentResult, error) {
+	a.images = util.PromptImagesFromContext(ctx)
+	return a.workdirAgent.RunTask(ctx, prompt)
+}
+
+func TestPromptImages(t *testing.T) {
+	cfg, err := FromFile(filepath.Join(testCasePath, "prompt-images.yaml"))
+	require.NoError(t, err)
+
+	ctx := client.ManagerToContext(context.Background(), client.NewManager(nil, client.ExtensionOptions{}))
+	r, err := NewTaskRunner(ctx, cfg)
+	require.NoError(t, err)
+
+	a := &promptImagesAgent{}
+	out, err := r.RunAgent(ctx, a)
+	require.NoError(t, err)
+
+	require.Len(t, a.images, 2)
+	assert.Equal(t, "screen.png", a.images[0].Name)
+	assert.Equal(t, "image/png", a.images[0].MediaType)
+	assert.Equal(t, "image-2.png", a.images[1].Name)
+	assert.Equal(t, "screen.png (image/png, 16 bytes)\nimage-2.png (image/png, 8 bytes)", out.Steps[0].Outputs["images"])
+}
+
+func TestVerifyReportsStepEvents(t *testing.T) {
+	r := &taskRunner{
+		verify: []step{
+			{runner: &fakeStep{out: &steps.StepOutput{Type: "script", Success: true}}, stepType: "script"},
+			{runner: &fakeStep{out: &steps.StepOutput{Type: "llmJudge", Success: false}}, stepType: "llmJudge"},
+			{runner: &fakeStep{err: errors.New("connection refused")}, stepType: "http"},
+			{runner: &fakeStep{out: &steps.StepOutput{Success: true}}, stepType: "script"},
+		},
+	}
+
+	var events []StepEvent
+	ctx := StepObserverToContext(context.Background(), func(event StepEvent) {
+		events = append(events, event)
+	})
+
+	out, err := r.Verify(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "verify[2] failed")
+	assert.False(t, out.Success)
+	assert.Len(t, out.Steps, 3)
+
+	// The phase stops at the step that returned an error
+	require.Len(t, events, 6)
+
+	var got []string
+	for _, e := range events {
+		got = append(got, string(e.Type)+" "+e.ID()+" "+e.StepType)
+	}
+	assert.Equal(t, []string{
+		"step_start verify[0] script",
+		"step_complete verify[0] script",
+		"step_start verify[1] llmJudge",
+		"step_complete verify[1] llmJudge",
+		"step_start verify[2] http",
+		"step_complete verify[2] http",
+	}, got)
+
+	assert.Nil(t, events[0].Output)
+	assert.False(t, events[3].Output.Success)
+	assert.EqualError(t, events[5].Err, "connection refused")
+}
+
+func TestStepObserverFromContextDefault(t *testing.T) {
+	// Runners used without an observer must not panic
+	StepObserverFromContext(context.Background())(StepEvent{})
+}
+
+func TestParseStepID(t *testing.T) {
+	tests := map[string]struct {
+		id          string
+		phase       string
+		index       int
+		errContains string
+	}{
+		"verify":         {id: "verify[2]", phase: PhaseVerify, index: 2},
+		"setup":          {id: "setup[0]", phase: PhaseSetup, index: 0},
+		"cleanup":        {id: "cleanup[1]", phase: PhaseCleanup, index: 1},
+		"unknown phase":  {id: "agent[0]", errContains: "phase must be setup, verify, or cleanup"},
+		"missing index":  {id: "verify", errContains: "must be like verify[0]"},
+		"negative index": {id: "verify[-1]", errContains: "must be like verify[0]"},
+		"unclosed":       {id: "verify[1", errContains: "must be like verify[0]"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			phase, index, err := ParseStepID(tc.id)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.phase, phase)
+			assert.Equal(t, tc.index, index)
+		})
+	}
+}
+
+func TestRunStep(t *testing.T) {
+	taskDir := t.TempDir()
+	setup, verify := &recordingStep{}, &recordingStep{}
+	r := &taskRunner{
+		setup:   []step{{runner: setup, stepType: "script"}},
+		verify:  []step{{runner: &fakeStep{out: &steps.StepOutput{Success: true}}, stepType: "script"}, {runner: verify, stepType: "llmJudge"}},
+		prompt:  "List the pods",
+		baseDir: taskDir,
+		files:   []File{{Path: "settings.json", Inline: "{}"}},
+	}
+
+	ctx := context.Background()
+
+	out, err := r.runStep(ctx, PhaseVerify, 1, &steps.AgentContext{Output: "nginx is running"})
+	require.NoError(t, err)
+	assert.Equal(t, "llmJudge", out.Type)
+	require.NotNil(t, verify.input.Agent)
+	assert.Equal(t, "List the pods", verify.input.Agent.Prompt)
+	assert.Equal(t, "nginx is running", verify.input.Agent.Output)
+	assert.Equal(t, taskDir, verify.input.TaskDir)
+	assert.NoDirExists(t, verify.input.Workdir, "the working directory is removed after the step")
+
+	_, err = r.runStep(ctx, PhaseSetup, 0, &steps.AgentContext{Output: "nginx is running"})
+	require.NoError(t, err)
+	assert.Nil(t, setup.input.Agent, "setup steps do not see the agent")
+
+	_, err = r.runStep(ctx, PhaseVerify, 2, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "there is no verify[2], the task has 2 verify steps")
+}
+
+func TestRunStepRemovesWorkdirWhenFilesFail(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	taskDir := t.TempDir()
+	r := &taskRunner{
+		setup:   []step{{runner: &recordingStep{}, stepType: "script"}},
+		baseDir: taskDir,
+		files:   []File{{Path: "settings.json", From: filepath.Join(taskDir, "missing.json")}},
+	}
+
+	_, err := r.runStep(context.Background(), PhaseSetup, 0, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write task files")
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the working directory is removed")
+	assert.Empty(t, r.workdir)
+}
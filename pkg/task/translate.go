@@ -1,16 +1,147 @@
 package task
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 
 	"github.com/mcpchecker/mcpchecker/pkg/steps"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
+	yamlv3 "go.yaml.in/yaml/v3"
+	"sigs.k8s.io/yaml"
 )
 
+// LatestAPIVersion is the apiVersion tasks are converted to when they are loaded
+const LatestAPIVersion = util.APIVersionV1Alpha2
+
+// versionedTask is a task as written, in any supported apiVersion
+type versionedTask struct {
+	*TaskConfig `json:",inline"`
+	Steps       *TaskStepsV1Alpha1 `json:"steps,omitempty"`
+}
+
+// converters upgrade a task from the apiVersion they are keyed by to the next
+// version. Tasks are upgraded one version at a time until they reach
+// LatestAPIVersion, so each converter only has to know about two versions.
+var converters = map[string]func(t *versionedTask) error{
+	util.APIVersionV1Alpha1: convertV1Alpha1,
+}
+
+// Decode parses a task in any supported apiVersion and converts it to
+// LatestAPIVersion. Unlike Read, file paths are kept as written.
+func Decode(data []byte) (*TaskConfig, error) {
+	t := &versionedTask{TaskConfig: &TaskConfig{}}
+	if err := yaml.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+
+	if err := t.TypeMeta.Validate(KindTask); err != nil {
+		return nil, err
+	}
+
+	for t.GetAPIVersion() != LatestAPIVersion {
+		convert, ok := converters[t.GetAPIVersion()]
+		if !ok {
+			return nil, fmt.Errorf("cannot convert apiVersion %s to %s", t.GetAPIVersion(), LatestAPIVersion)
+		}
+		if err := convert(t); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.Spec == nil {
+		return nil, fmt.Errorf("spec must be set")
+	}
+
+	return t.TaskConfig, nil
+}
+
+// Migrate rewrites a task in LatestAPIVersion and returns the apiVersion it
+// was written in. Tasks that are already in LatestAPIVersion are returned
+// unchanged. Comments and formatting are not preserved when a task is converted.
+func Migrate(data []byte) ([]byte, string, error) {
+	meta := &util.TypeMeta{}
+	if err := yaml.Unmarshal(data, meta); err != nil {
+		return nil, "", err
+	}
+	from := meta.GetAPIVersion()
+
+	cfg, err := Decode(data)
+	if err != nil {
+		return nil, from, err
+	}
+
+	if from == LatestAPIVersion {
+		return data, from, nil
+	}
+
+	out, err := marshalOrdered(cfg)
+	if err != nil {
+		return nil, from, fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	return out, from, nil
+}
+
+// marshalOrdered encodes v as YAML with fields in struct order, where
+// sigs.k8s.io/yaml would sort them, so migrated tasks read top to bottom.
+func marshalOrdered(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is YAML, so it can be parsed into a node tree that keeps key order
+	node := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(data, node); err != nil {
+		return nil, err
+	}
+	clearStyle(node)
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// clearStyle drops the JSON flow and quoting styles, so the node is written as
+// block YAML with quotes only where needed
+func clearStyle(node *yamlv3.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+func convertV1Alpha1(t *versionedTask) error {
+	if t.Steps == nil {
+		return fmt.Errorf("v1alpha1 requires steps field")
+	}
+
+	spec, err := translateV1Alpha1ToSteps(t.Steps)
+	if err != nil {
+		return fmt.Errorf("failed to convert v1alpha1 format to v1alpha2: %w", err)
+	}
+
+	t.Spec = spec
+	t.Steps = nil
+	t.APIVersion = util.APIVersionV1Alpha2
+
+	return nil
+}
+
 func translateV1Alpha1ToSteps(legacy *TaskStepsV1Alpha1) (*TaskSpec, error) {
 	var err error
-	spec := &TaskSpec{
-		Prompt: legacy.Prompt,
+	spec := &TaskSpec{}
+	if legacy.Prompt != nil {
+		spec.Prompt = &Prompt{Step: *legacy.Prompt}
 	}
 
 	spec.Setup, err = translateLegacyStep(legacy.SetupScript)
@@ -47,12 +178,12 @@ func translateV1Alpha1ToSteps(legacy *TaskStepsV1Alpha1) (*TaskSpec, error) {
 
 func translateLegacyStep(step *util.Step) ([]steps.StepConfig, error) {
 	if step == nil || step.IsEmpty() {
-		return []steps.StepConfig{}, nil
+		return nil, nil
 	}
 
 	raw, err := json.Marshal(step)
 	if err != nil {
-		return []steps.StepConfig{}, err
+		return nil, err
 	}
 
 	return []steps.StepConfig{{
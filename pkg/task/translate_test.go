@@ -0,0 +1,158 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	tt := map[string]struct {
+		task      string
+		from      string
+		unchanged bool
+		expectErr string
+	}{
+		"v1alpha1 scripts": {
+			task: `kind: Task
+metadata:
+  name: scripts
+  difficulty: easy
+  labels:
+    retries: "3"
+    flaky: "true"
+steps:
+  setup:
+    file: ./setup.sh
+  verify:
+    inline: |-
+      #!/usr/bin/env bash
+      exit 0
+  prompt:
+    inline: Do the thing
+`,
+			from: util.APIVersionV1Alpha1,
+		},
+		"v1alpha1 llm judge": {
+			task: `apiVersion: mcpchecker/v1alpha1
+kind: Task
+metadata:
+  name: judge
+steps:
+  verify:
+    contains: a running pod
+  prompt:
+    file: prompt.md
+`,
+			from: util.APIVersionV1Alpha1,
+		},
+		"latest is unchanged": {
+			task: `# comments are kept
+kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: latest
+spec:
+  prompt:
+    inline: Do the thing
+`,
+			from:      LatestAPIVersion,
+			unchanged: true,
+		},
+		"v1alpha1 without steps": {
+			task: `kind: Task
+metadata:
+  name: empty
+`,
+			expectErr: "v1alpha1 requires steps field",
+		},
+		"v1alpha2 without spec": {
+			task: `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: empty
+`,
+			expectErr: "spec must be set",
+		},
+		"not a task": {
+			task: `kind: Eval
+metadata:
+  name: eval
+`,
+			expectErr: "invalid kind",
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			out, from, err := Migrate([]byte(tc.task))
+			if tc.expectErr != "" {
+				assert.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.from, from)
+
+			if tc.unchanged {
+				assert.Equal(t, tc.task, string(out))
+				return
+			}
+
+			// The migrated task is read as the same task, without conversion
+			original, err := Decode([]byte(tc.task))
+			require.NoError(t, err)
+			migrated, err := Decode(out)
+			require.NoError(t, err)
+			assert.Equal(t, original, migrated)
+
+			_, migratedFrom, err := Migrate(out)
+			require.NoError(t, err)
+			assert.Equal(t, LatestAPIVersion, migratedFrom)
+		})
+	}
+}
+
+func TestMigrateTestdata(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testCasePath, "create-pod-inline.yaml"))
+	require.NoError(t, err)
+
+	out, from, err := Migrate(data)
+	require.NoError(t, err)
+	assert.Equal(t, util.APIVersionV1Alpha1, from)
+
+	// Fields are written in the order they are declared, not sorted
+	assert.Equal(t, `apiVersion: mcpchecker/v1alpha2
+kind: Task
+metadata:
+  name: create pod inline
+  difficulty: easy
+spec:
+  setup:
+    - script:
+        inline: |-
+          #!/usr/bin/env bash
+          kubectl delete namespace create-pod-test --ignore-not-found
+          kubectl create namespace create-pod-test
+  cleanup:
+    - script:
+        inline: |-
+          #!/usr/bin/env bash
+          kubectl delete pod web-server -n create-pod-test --ignore-not-found
+          kubectl delete namespace create-pod-test --ignore-not-found
+  verify:
+    - script:
+        inline: |-
+          #!/usr/bin/env bash
+          if kubectl wait --for=condition=Ready pod/web-server -n create-pod-test --timeout=120s; then
+              exit 0
+          else
+              exit 1
+          fi
+  prompt:
+    inline: Please create a nginx pod named web-server in the create-pod-test namespace
+`, string(out))
+}
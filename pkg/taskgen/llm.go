@@ -0,0 +1,79 @@
+package taskgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+const draftSystemPrompt = `You write evaluation tasks for AI agents that use MCP servers.
+Given a tool's name, description, and input schema, write one realistic request
+from a user that an agent can only fulfil by calling that tool.
+
+Rules:
+- Write the request as the user would, without naming the tool
+- Use concrete, plausible values for the required arguments
+- Reply with the request text only, without quotes or explanations`
+
+type llmDrafter struct {
+	client openai.Client
+	model  string
+}
+
+// NewLLMDrafter creates a PromptDrafter that asks an OpenAI-compatible model
+// to write a prompt for each tool.
+func NewLLMDrafter(baseURL, apiKey, model string) (PromptDrafter, error) {
+	if baseURL == "" || apiKey == "" {
+		return nil, fmt.Errorf("base URL and API key are required to draft prompts with an LLM")
+	}
+	if model == "" {
+		return nil, fmt.Errorf("model is required to draft prompts with an LLM")
+	}
+
+	return &llmDrafter{
+		client: openai.NewClient(
+			option.WithBaseURL(baseURL),
+			option.WithAPIKey(apiKey),
+		),
+		model: model,
+	}, nil
+}
+
+func (d *llmDrafter) DraftPrompt(ctx context.Context, server string, tool *mcp.Tool) (string, error) {
+	toolJSON, err := json.MarshalIndent(map[string]any{
+		"server":      server,
+		"name":        tool.Name,
+		"description": tool.Description,
+		"inputSchema": tool.InputSchema,
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tool: %w", err)
+	}
+
+	completion, err := d.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(draftSystemPrompt),
+			openai.UserMessage(string(toolJSON)),
+		},
+		Model: d.model,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to call llm: %w", err)
+	}
+
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("no completion choices returned from LLM")
+	}
+
+	prompt := strings.TrimSpace(completion.Choices[0].Message.Content)
+	if prompt == "" {
+		return "", fmt.Errorf("LLM returned an empty prompt")
+	}
+
+	return prompt, nil
+}
@@ -0,0 +1,191 @@
+// Package taskgen scaffolds candidate tasks from the tools exposed by MCP servers.
+package taskgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GeneratedTask is a candidate task that exercises a single tool.
+type GeneratedTask struct {
+	Server string
+	Tool   string
+	Task   *task.TaskConfig
+}
+
+// PromptDrafter writes the prompt for a task that should exercise tool.
+type PromptDrafter interface {
+	DraftPrompt(ctx context.Context, server string, tool *mcp.Tool) (string, error)
+}
+
+type Options struct {
+	// Servers limits generation to the named servers. All enabled servers are used if empty
+	Servers []string
+
+	// Drafter writes task prompts. If nil, prompts are filled from a template
+	Drafter PromptDrafter
+}
+
+// Generate connects to the servers in cfg and creates one task per tool.
+// Tasks are returned sorted by server and tool name.
+func Generate(ctx context.Context, cfg *mcpproxy.MCPConfig, opts Options) ([]*GeneratedTask, error) {
+	servers := cfg.GetEnabledServers()
+
+	names := opts.Servers
+	if len(names) == 0 {
+		for name := range servers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var tasks []*GeneratedTask
+	for _, name := range names {
+		serverCfg, ok := servers[name]
+		if !ok {
+			return nil, fmt.Errorf("server %q not found in MCP config", name)
+		}
+
+		tools, err := listTools(ctx, serverCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tools for server %q: %w", name, err)
+		}
+
+		for _, tool := range tools {
+			prompt := TemplatePrompt(name, tool)
+			if opts.Drafter != nil {
+				prompt, err = opts.Drafter.DraftPrompt(ctx, name, tool)
+				if err != nil {
+					return nil, fmt.Errorf("failed to draft prompt for tool %q on server %q: %w", tool.Name, name, err)
+				}
+			}
+
+			tasks = append(tasks, &GeneratedTask{
+				Server: name,
+				Tool:   tool.Name,
+				Task:   NewTask(name, tool, prompt),
+			})
+		}
+	}
+
+	return tasks, nil
+}
+
+func listTools(ctx context.Context, cfg *mcpproxy.ServerConfig) ([]*mcp.Tool, error) {
+	cs, err := mcpproxy.Connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer cs.Close()
+
+	if cs.InitializeResult().Capabilities.Tools == nil {
+		return nil, nil
+	}
+
+	var tools []*mcp.Tool
+	for t, err := range cs.Tools(ctx, &mcp.ListToolsParams{}) {
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, t)
+	}
+
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+
+	return tools, nil
+}
+
+// NewTask creates a task for tool with the given prompt.
+func NewTask(server string, tool *mcp.Tool, prompt string) *task.TaskConfig {
+	return &task.TaskConfig{
+		TypeMeta: util.TypeMeta{
+			APIVersion: util.APIVersionV1Alpha2,
+			Kind:       task.KindTask,
+		},
+		Metadata: task.TaskMetadata{
+			Name:       TaskName(server, tool.Name),
+			Difficulty: task.DifficultyEasy,
+			Labels: map[string]string{
+				"server": server,
+				"tool":   tool.Name,
+			},
+		},
+		Spec: &task.TaskSpec{
+			Prompt: &task.Prompt{Step: util.Step{Inline: prompt}},
+		},
+	}
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// TaskName returns the task name used for a tool, e.g. "kubernetes-pods-list".
+func TaskName(server, tool string) string {
+	return strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(server+"-"+tool), "-"), "-")
+}
+
+// TemplatePrompt creates a prompt from the tool description, with a
+// placeholder for each required argument that should be replaced by hand.
+func TemplatePrompt(server string, tool *mcp.Tool) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Using the %s MCP server, call %s", server, tool.Name)
+	if desc := firstSentence(tool.Description); desc != "" {
+		fmt.Fprintf(&sb, " (%s)", desc)
+	}
+
+	args := requiredArgs(tool)
+	if len(args) == 0 {
+		sb.WriteString(".")
+		return sb.String()
+	}
+
+	sb.WriteString(" with:")
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "\n- %s: <%s>", arg, arg)
+	}
+
+	return sb.String()
+}
+
+func firstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, "\n"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSuffix(strings.TrimSpace(s), ".")
+}
+
+// requiredArgs returns the required top-level arguments of the tool's input schema.
+func requiredArgs(tool *mcp.Tool) []string {
+	if tool.InputSchema == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(tool.InputSchema)
+	if err != nil {
+		return nil
+	}
+
+	var schema struct {
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil
+	}
+
+	return schema.Required
+}
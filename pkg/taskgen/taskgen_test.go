@@ -0,0 +1,129 @@
+package taskgen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greetArgs struct {
+	Name string `json:"name"`
+}
+
+func newTestServer(t *testing.T) string {
+	t.Helper()
+
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "greet",
+		Description: "Greets a person by name. Returns a friendly message.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args greetArgs) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "ping",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return s }, nil))
+	t.Cleanup(srv.Close)
+
+	return srv.URL
+}
+
+type fakeDrafter struct{}
+
+func (fakeDrafter) DraftPrompt(ctx context.Context, server string, tool *mcp.Tool) (string, error) {
+	return "drafted " + server + "/" + tool.Name, nil
+}
+
+func TestGenerate(t *testing.T) {
+	cfg := &mcpproxy.MCPConfig{
+		MCPServers: map[string]*mcpproxy.ServerConfig{
+			"test": {Type: mcpproxy.TransportTypeHttp, URL: newTestServer(t)},
+		},
+	}
+
+	tasks, err := Generate(context.Background(), cfg, Options{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+
+	assert.Equal(t, "test-greet", tasks[0].Task.Metadata.Name)
+	assert.Equal(t, "Using the test MCP server, call greet (Greets a person by name) with:\n- name: <name>", tasks[0].Task.Spec.Prompt.Inline)
+	assert.Equal(t, "test-ping", tasks[1].Task.Metadata.Name)
+	assert.Equal(t, "Using the test MCP server, call ping.", tasks[1].Task.Spec.Prompt.Inline)
+
+	tasks, err = Generate(context.Background(), cfg, Options{Drafter: fakeDrafter{}})
+	require.NoError(t, err)
+	assert.Equal(t, "drafted test/greet", tasks[0].Task.Spec.Prompt.Inline)
+
+	_, err = Generate(context.Background(), cfg, Options{Servers: []string{"missing"}})
+	assert.Error(t, err)
+}
+
+func TestTaskName(t *testing.T) {
+	assert.Equal(t, "kubernetes-pods-list", TaskName("kubernetes", "pods_list"))
+	assert.Equal(t, "my-server-get-item", TaskName("My Server", "get.item"))
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	mcpConfigFile := filepath.Join(dir, "mcp-config.yaml")
+	tasks := []*GeneratedTask{{
+		Server: "test",
+		Tool:   "greet",
+		Task:   NewTask("test", &mcp.Tool{Name: "greet"}, "Say hello to Ada"),
+	}}
+	outDir := filepath.Join(dir, "generated")
+
+	require.NoError(t, Write(outDir, tasks, WriteOptions{McpConfigFiles: []string{mcpConfigFile}}))
+
+	spec, err := eval.FromFile(filepath.Join(outDir, EvalFileName))
+	require.NoError(t, err)
+	assert.Equal(t, mcpConfigFile, spec.Config.McpConfigFile)
+	require.Len(t, spec.Config.TaskSets, 1)
+	assert.Equal(t, []eval.ToolAssertion{{Server: "test", Tool: "greet"}}, spec.Config.TaskSets[0].Assertions.ToolsUsed)
+
+	tc, err := task.FromFile(spec.Config.TaskSets[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "test-greet", tc.Metadata.Name)
+	assert.Equal(t, "Say hello to Ada", tc.Spec.Prompt.Inline)
+
+	assert.Error(t, Write(outDir, tasks, WriteOptions{}), "expected existing files to be kept without force")
+	assert.NoError(t, Write(outDir, tasks, WriteOptions{Force: true}))
+}
+
+func TestWriteCollidingNames(t *testing.T) {
+	dir := t.TempDir()
+	tasks := []*GeneratedTask{
+		{Server: "test", Tool: "get_item", Task: NewTask("test", &mcp.Tool{Name: "get_item"}, "Get item one")},
+		{Server: "test", Tool: "get.item", Task: NewTask("test", &mcp.Tool{Name: "get.item"}, "Get item two")},
+	}
+
+	require.NoError(t, Write(dir, tasks, WriteOptions{}))
+
+	spec, err := eval.FromFile(filepath.Join(dir, EvalFileName))
+	require.NoError(t, err)
+	require.Len(t, spec.Config.TaskSets, 2)
+
+	first, err := task.FromFile(spec.Config.TaskSets[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "test-get-item", first.Metadata.Name)
+	assert.Equal(t, "Get item one", first.Spec.Prompt.Inline)
+
+	second, err := task.FromFile(spec.Config.TaskSets[1].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "test-get-item-2", second.Metadata.Name)
+	assert.Equal(t, "Get item two", second.Spec.Prompt.Inline)
+	assert.Equal(t, "get.item", spec.Config.TaskSets[1].Assertions.ToolsUsed[0].Tool)
+}
@@ -0,0 +1,150 @@
+package taskgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// EvalFileName is the name of the eval file written next to the tasks directory
+	EvalFileName = "eval.yaml"
+
+	// DefaultAgentType is the agent used in generated eval files
+	DefaultAgentType = "builtin.claude-code"
+)
+
+type WriteOptions struct {
+	// McpConfigFiles are referenced from the eval file, relative to the output
+	// directory when possible
+	McpConfigFiles []string
+
+	// McpProfile is the MCP config profile selected in the eval file
+	McpProfile string
+
+	// Force overwrites existing files
+	Force bool
+}
+
+// Write writes each task to <dir>/tasks/<name>/<name>.yaml along with an eval
+// file in dir that runs every task and asserts that its tool was used. Tasks
+// whose names collide, e.g. tools that only differ in punctuation, are renamed
+// with a numeric suffix so that neither overwrites the other.
+func Write(dir string, tasks []*GeneratedTask, opts WriteOptions) error {
+	spec := &eval.EvalSpec{
+		TypeMeta: util.TypeMeta{
+			APIVersion: util.APIVersionV1Alpha2,
+			Kind:       eval.KindEval,
+		},
+		Metadata: eval.EvalMetadata{
+			Name: "generated-tasks",
+		},
+		Config: eval.EvalConfig{
+			Agent: &eval.AgentRef{
+				Type: DefaultAgentType,
+			},
+			McpProfile: opts.McpProfile,
+		},
+	}
+
+	for i, path := range opts.McpConfigFiles {
+		if i == 0 {
+			spec.Config.McpConfigFile = relativePath(dir, path)
+		} else {
+			spec.Config.McpConfigFiles = append(spec.Config.McpConfigFiles, relativePath(dir, path))
+		}
+	}
+
+	files := map[string]any{}
+	for _, t := range tasks {
+		name := uniqueName(t.Task.Metadata.Name, files)
+		t.Task.Metadata.Name = name
+		taskPath := filepath.Join("tasks", name, name+".yaml")
+		files[taskPath] = t.Task
+
+		minCalls := 1
+		spec.Config.TaskSets = append(spec.Config.TaskSets, eval.TaskSet{
+			Path: taskPath,
+			Assertions: &eval.TaskAssertions{
+				ToolsUsed: []eval.ToolAssertion{{
+					Server: t.Server,
+					Tool:   t.Tool,
+				}},
+				MinToolCalls: &minCalls,
+			},
+		})
+	}
+	files[EvalFileName] = spec
+
+	if !opts.Force {
+		for path := range files {
+			if _, err := os.Stat(filepath.Join(dir, path)); err == nil {
+				return fmt.Errorf("'%s' already exists, use --force to overwrite", filepath.Join(dir, path))
+			}
+		}
+	}
+
+	for path, v := range files {
+		if err := writeYAML(filepath.Join(dir, path), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uniqueName returns name, or name with the first free numeric suffix when a
+// task of that name is already in files.
+func uniqueName(name string, files map[string]any) string {
+	candidate := name
+	for i := 2; ; i++ {
+		if _, ok := files[filepath.Join("tasks", candidate, candidate+".yaml")]; !ok {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+func writeYAML(path string, v any) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal '%s': %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for '%s': %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+func relativePath(dir, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return path
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return absPath
+	}
+
+	return rel
+}
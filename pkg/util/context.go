@@ -6,7 +6,15 @@ import (
 
 type contextKey string
 
-const verboseKey contextKey = "verbose"
+const (
+	verboseKey contextKey = "verbose"
+	workdirKey contextKey = "workdir"
+
+	promptFilesKey  contextKey = "promptFiles"
+	promptImagesKey contextKey = "promptImages"
+
+	updateSnapshotsKey contextKey = "updateSnapshots"
+)
 
 // WithVerbose adds the verbose flag to the context
 func WithVerbose(ctx context.Context, verbose bool) context.Context {
@@ -22,3 +30,64 @@ func IsVerbose(ctx context.Context) bool {
 	return ok && v
 }
 
+// WithWorkdir adds the working directory of the current task to the context
+func WithWorkdir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, workdirKey, dir)
+}
+
+// WorkdirFromContext returns the working directory of the current task, if
+// the task has one
+func WorkdirFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	dir, ok := ctx.Value(workdirKey).(string)
+	return dir, ok && dir != ""
+}
+
+// WithPromptFiles adds the paths of the files attached to the prompt of the
+// current task to the context
+func WithPromptFiles(ctx context.Context, files []string) context.Context {
+	return context.WithValue(ctx, promptFilesKey, files)
+}
+
+// PromptFilesFromContext returns the paths of the files attached to the prompt
+// of the current task
+func PromptFilesFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	files, _ := ctx.Value(promptFilesKey).([]string)
+	return files
+}
+
+// WithPromptImages adds the images attached to the prompt of the current task
+// to the context
+func WithPromptImages(ctx context.Context, images []Image) context.Context {
+	return context.WithValue(ctx, promptImagesKey, images)
+}
+
+// PromptImagesFromContext returns the images attached to the prompt of the
+// current task
+func PromptImagesFromContext(ctx context.Context) []Image {
+	if ctx == nil {
+		return nil
+	}
+	images, _ := ctx.Value(promptImagesKey).([]Image)
+	return images
+}
+
+// WithUpdateSnapshots adds the flag to rewrite snapshot files instead of
+// comparing against them to the context
+func WithUpdateSnapshots(ctx context.Context, update bool) context.Context {
+	return context.WithValue(ctx, updateSnapshotsKey, update)
+}
+
+// UpdateSnapshots returns true if snapshot steps should rewrite their files
+func UpdateSnapshots(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, ok := ctx.Value(updateSnapshotsKey).(bool)
+	return ok && v
+}
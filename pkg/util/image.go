@@ -0,0 +1,30 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// Image is an image attached to the prompt of a task
+type Image struct {
+	// Name is the file name of the image
+	Name      string
+	MediaType string
+	Data      []byte
+}
+
+// Base64 returns the data of the image encoded as standard base64
+func (i Image) Base64() string {
+	return base64.StdEncoding.EncodeToString(i.Data)
+}
+
+// DataURL returns the image as a data URL, the way model APIs take inline
+// images
+func (i Image) DataURL() string {
+	return fmt.Sprintf("data:%s;base64,%s", i.MediaType, i.Base64())
+}
+
+// String describes the image for transcripts, without its data
+func (i Image) String() string {
+	return fmt.Sprintf("%s (%s, %d bytes)", i.Name, i.MediaType, len(i.Data))
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Redacted replaces secrets in the configs recorded with results
+const Redacted = "[REDACTED]"
+
+// secretNameParts are parts of the names of environment variables, headers,
+// and config keys that usually hold secrets
+var secretNameParts = []string{"key", "token", "secret", "password", "passwd", "credential", "auth", "cookie"}
+
+// secretNameWords are words of names that hold secrets but are too short to
+// match inside other words, such as "pat" in "PATH"
+var secretNameWords = []string{"pat", "pwd", "pass"}
+
+// IsSecretName reports whether an environment variable, header, or config key
+// of this name likely holds a secret
+func IsSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range secretNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if slices.Contains(secretNameWords, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactSecretValues returns a copy of m with the values of secret names
+// replaced by Redacted
+func RedactSecretValues(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(m))
+	for k, v := range m {
+		if IsSecretName(k) {
+			v = Redacted
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
@@ -2,16 +2,17 @@ package util
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/shell"
 )
 
 type Step struct {
-	Inline string `json:"inline"`
-	File   string `json:"file"`
+	Inline string `json:"inline,omitempty"`
+	File   string `json:"file,omitempty"`
 }
 
 func (s *Step) IsEmpty() bool {
@@ -45,26 +46,19 @@ func (s *Step) Run(ctx context.Context) (string, error) {
 // createInlineCommand executes inline scripts with shebang support.
 // Scripts with shebangs are written to temp files in the current directory to preserve relative paths.
 func (s *Step) createInlineCommand(ctx context.Context) (*exec.Cmd, error) {
+	sh := shell.Default()
+
 	if strings.HasPrefix(strings.TrimSpace(s.Inline), "#!") {
-		tmpFile, err := os.CreateTemp(".", ".mcpchecker-step-*.sh")
+		tmpPath, err := shell.WriteTemp(".", ".mcpchecker-step-*.sh", s.Inline)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create temp script file: %w", err)
-		}
-		tmpPath := tmpFile.Name()
-
-		if _, err := tmpFile.WriteString(s.Inline); err != nil {
-			tmpFile.Close()
-			os.Remove(tmpPath)
-			return nil, fmt.Errorf("failed to write temp script: %w", err)
+			return nil, err
 		}
-		tmpFile.Close()
 
-		if err := ensureExecutable(tmpPath); err != nil {
+		cmd, err := sh.File(ctx, tmpPath)
+		if err != nil {
 			os.Remove(tmpPath)
 			return nil, err
 		}
-
-		cmd := exec.CommandContext(ctx, tmpPath)
 		go func() {
 			<-ctx.Done()
 			os.Remove(tmpPath)
@@ -72,40 +66,20 @@ func (s *Step) createInlineCommand(ctx context.Context) (*exec.Cmd, error) {
 		return cmd, nil
 	}
 
-	shell := GetShell()
-	cmd := exec.CommandContext(ctx, shell)
-	cmd.Stdin = strings.NewReader(s.Inline)
-	return cmd, nil
+	return sh.Script(ctx, s.Inline)
 }
 
 // createFileCommand executes a script file directly to respect its shebang.
 func (s *Step) createFileCommand(ctx context.Context) (*exec.Cmd, error) {
-	if err := ensureExecutable(s.File); err != nil {
+	cmd, err := shell.Default().File(ctx, s.File)
+	if err != nil {
 		return nil, err
 	}
-	cmd := exec.CommandContext(ctx, s.File)
 	// Set working directory to the script's directory so relative paths work
 	cmd.Dir = filepath.Dir(s.File)
 	return cmd, nil
 }
 
-func ensureExecutable(path string) error {
-	info, err := os.Stat(path)
-	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
-	}
-
-	if info.Mode()&0100 != 0 {
-		return nil
-	}
-
-	if err := os.Chmod(path, info.Mode()|0111); err != nil {
-		return fmt.Errorf("failed to make script executable: %w", err)
-	}
-
-	return nil
-}
-
 func (s *Step) GetValue() (string, error) {
 	if s.Inline != "" {
 		return s.Inline, nil
@@ -120,12 +94,8 @@ func (s *Step) GetValue() (string, error) {
 }
 
 // GetShell returns the shell to use for executing scripts.
-// It checks the SHELL environment variable and defaults to /usr/bin/bash if not set.
+//
+// Deprecated: use shell.Default, which also supports Windows.
 func GetShell() string {
-	shell, ok := os.LookupEnv("SHELL")
-	if !ok {
-		shell = "/usr/bin/bash"
-	}
-
-	return shell
+	return shell.Default().Path
 }